@@ -1,21 +1,38 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
 
 	httpDelivery "version-1-0/internal/delivery/http"
 	"version-1-0/internal/delivery/http/handler"
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/repository/postgres"
 	"version-1-0/internal/repository/sqlite"
 	"version-1-0/internal/usecase/analytics"
+	"version-1-0/internal/usecase/apikey"
 	"version-1-0/internal/usecase/appointment"
+	"version-1-0/internal/usecase/audit"
 	"version-1-0/internal/usecase/auth"
 	"version-1-0/internal/usecase/doctor"
+	"version-1-0/internal/usecase/location"
+	"version-1-0/internal/usecase/patient"
+	"version-1-0/internal/usecase/role"
 	"version-1-0/internal/usecase/schedule"
 	"version-1-0/internal/usecase/service"
 	"version-1-0/internal/usecase/user"
 	"version-1-0/pkg/email"
+	"version-1-0/pkg/fieldcrypt"
+	"version-1-0/pkg/purge"
 	"version-1-0/pkg/reminder"
 
 	"version-1-0/pkg/config"
@@ -52,12 +69,32 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key de una integración, creada en POST /api/api-keys. Se acepta en las rutas que exigen un permiso
+
 // @tag.name Authentication
 // @tag.description Endpoints de autenticación y login
 
 // @tag.name Users
 // @tag.description Gestión de usuarios (admin, doctor, patient)
 
+// @tag.name Invitations
+// @tag.description Invitaciones para crear cuentas de doctores y administradores
+
+// @tag.name Lockouts
+// @tag.description Bloqueos de cuentas e IPs por intentos fallidos de login
+
+// @tag.name TwoFactor
+// @tag.description Verificación en dos pasos (TOTP) de las cuentas del personal
+
+// @tag.name Roles
+// @tag.description Roles del personal y los permisos que cada clínica les da
+
+// @tag.name API Keys
+// @tag.description API keys para integraciones (kioscos, call center) con permisos limitados
+
 // @tag.name Appointments
 // @tag.description Sistema de citas médicas
 
@@ -68,40 +105,195 @@ import (
 // @tag.description Horarios personalizados de doctores
 
 // @tag.name Analytics
-// @tag.description Reportes y estadísticas (solo admin)
+// @tag.description Reportes y estadísticas
 
 // @tag.name Doctors
 // @tag.description Búsqueda de doctores por especialidad
 
+// Storage modes accepted by the --storage flag
+const (
+	storageDatabase = "database"
+	storageMemory   = "memory"
+)
+
 func main() {
+	storage := flag.String("storage", storageDatabase, "backend de almacenamiento: database (usa DATABASE_URL) o memory (datos volátiles para demos)")
+	adminEmail := flag.String("admin-email", "admin@clinica.local", "con --storage=memory, email invitado como primer administrador")
+	flag.Parse()
+
+	if *storage != storageDatabase && *storage != storageMemory {
+		log.Fatalf("Valor de --storage no soportado: %q (use %q o %q)", *storage, storageDatabase, storageMemory)
+	}
+
 	fmt.Println("🏥 Sistema de Reservas - Clínica Internacional - API Server")
 	fmt.Println("=============================================================")
 
 	// Load configuration
 	cfg := config.LoadConfig()
+
+	// Sensitive patient data is encrypted in the database; the memory store keeps nothing at rest
+	var cipher *fieldcrypt.Cipher
+	if *storage != storageMemory {
+		var err error
+		if cipher, err = cfg.FieldCipher(); err != nil {
+			log.Fatalf("Error en la configuración de cifrado: %v", err)
+		}
+	}
+
+	// Access tokens are signed with the key of JWT_SIGNING_KEY_ID, or with JWT_SECRET without JWT_KEYS_DIR
+	jwtKeys, err := cfg.JWTKeys()
+	if err != nil {
+		log.Fatalf("Error en las claves de los tokens: %v", err)
+	}
+
 	fmt.Printf("🔧 Configuración cargada:\n")
 	fmt.Printf("   Puerto: %s\n", cfg.ServerPort)
-	fmt.Printf("   Base de datos: %s\n", cfg.DatabaseURL)
-	fmt.Printf("   JWT Expiration: %d horas\n\n", cfg.JWTExpirationHrs)
+	if *storage == storageMemory {
+		fmt.Printf("   Base de datos: memoria (los datos se pierden al reiniciar)\n")
+	} else {
+		fmt.Printf("   Base de datos: %s\n", cfg.DatabaseDriver)
+		fmt.Printf("   Migraciones automáticas: %t\n", cfg.AutoMigrate)
+		fmt.Printf("   Clave de cifrado: %s\n", cipher.PrimaryKeyID())
+	}
+	if kid := jwtKeys.SigningKeyID(); kid != "" {
+		fmt.Printf("   Firma de tokens: clave %s (%d claves publicadas en /.well-known/jwks.json)\n", kid, len(jwtKeys.JWKS().Keys))
+	} else {
+		fmt.Printf("   Firma de tokens: HS256 con JWT_SECRET\n")
+	}
+	if cfg.OIDCIssuer != "" {
+		fmt.Printf("   Inicio de sesión único: %s\n", cfg.OIDCIssuer)
+	}
+	fmt.Printf("   Token de acceso: %d minutos, refresh token: %d días\n\n", cfg.AccessTokenMinutes, cfg.RefreshTokenDays)
 
-	// Initialize PostgreSQL database
+	// Initialize storage (in-memory, or SQLite/PostgreSQL depending on DATABASE_URL)
 	fmt.Println("📦 Inicializando base de datos...")
-	db, err := sqlite.InitDB(cfg.DatabaseURL)
+	var (
+		userRepo          repository.UserRepository
+		doctorRepo        repository.DoctorRepository
+		patientRepo       repository.PatientRepository
+		appointmentRepo   repository.AppointmentRepository
+		serviceRepo       repository.ServiceRepository
+		doctorServiceRepo repository.DoctorServiceRepository
+		scheduleRepo      repository.ScheduleRepository
+		auditRepo         repository.AuditRepository
+		clinicRepo        repository.ClinicRepository
+		refreshTokenRepo  repository.RefreshTokenRepository
+		sessionRepo       repository.SessionRepository
+		resetTokenRepo    repository.PasswordResetTokenRepository
+		usedTokenRepo     repository.UsedTokenRepository
+		invitationRepo    repository.InvitationRepository
+		lockoutRepo       repository.LoginLockoutRepository
+		twoFactorRepo     repository.TwoFactorRepository
+		roleRepo          repository.RoleRepository
+		apiKeyRepo        repository.APIKeyRepository
+		identityRepo      repository.ExternalIdentityRepository
+		txManager         repository.TxManager
+	)
+
+	switch {
+	case *storage == storageMemory:
+		store := memory.NewStore()
+
+		// Create repositories
+		userRepo = memory.NewMemoryUserRepository(store)
+		doctorRepo = memory.NewMemoryDoctorRepository(store)
+		patientRepo = memory.NewMemoryPatientRepository(store)
+		appointmentRepo = memory.NewMemoryAppointmentRepository(store)
+		serviceRepo = memory.NewMemoryServiceRepository(store)
+		doctorServiceRepo = memory.NewMemoryDoctorServiceRepository(store)
+		scheduleRepo = memory.NewMemoryScheduleRepository(store)
+		auditRepo = memory.NewMemoryAuditRepository(store)
+		clinicRepo = memory.NewMemoryClinicRepository(store)
+		refreshTokenRepo = memory.NewMemoryRefreshTokenRepository(store)
+		sessionRepo = memory.NewMemorySessionRepository(store)
+		resetTokenRepo = memory.NewMemoryPasswordResetTokenRepository(store)
+		usedTokenRepo = memory.NewMemoryUsedTokenRepository(store)
+		invitationRepo = memory.NewMemoryInvitationRepository(store)
+		lockoutRepo = memory.NewMemoryLoginLockoutRepository(store)
+		twoFactorRepo = memory.NewMemoryTwoFactorRepository(store)
+		roleRepo = memory.NewMemoryRoleRepository(store)
+		apiKeyRepo = memory.NewMemoryAPIKeyRepository(store)
+		identityRepo = memory.NewMemoryExternalIdentityRepository(store)
+		txManager = memory.NewMemoryTxManager(store)
+	case cfg.DatabaseDriver == config.DriverPostgres:
+		pool, err := postgres.InitDB(cfg.DatabaseDSN, cfg.AutoMigrate)
+		if err != nil {
+			log.Fatalf("Error al inicializar la base de datos: %v", err)
+		}
+		defer pool.Close()
+
+		// Create repositories
+		userRepo = postgres.NewPostgresUserRepository(pool)
+		doctorRepo = postgres.NewPostgresDoctorRepository(pool)
+		patientRepo = postgres.NewPostgresPatientRepository(pool, cipher)
+		appointmentRepo = postgres.NewPostgresAppointmentRepository(pool, cipher)
+		serviceRepo = postgres.NewPostgresServiceRepository(pool)
+		doctorServiceRepo = postgres.NewPostgresDoctorServiceRepository(pool)
+		scheduleRepo = postgres.NewPostgresScheduleRepository(pool)
+		auditRepo = postgres.NewPostgresAuditRepository(pool)
+		clinicRepo = postgres.NewPostgresClinicRepository(pool)
+		refreshTokenRepo = postgres.NewPostgresRefreshTokenRepository(pool)
+		sessionRepo = postgres.NewPostgresSessionRepository(pool)
+		resetTokenRepo = postgres.NewPostgresPasswordResetTokenRepository(pool)
+		usedTokenRepo = postgres.NewPostgresUsedTokenRepository(pool)
+		invitationRepo = postgres.NewPostgresInvitationRepository(pool)
+		lockoutRepo = postgres.NewPostgresLoginLockoutRepository(pool)
+		twoFactorRepo = postgres.NewPostgresTwoFactorRepository(pool, cipher)
+		roleRepo = postgres.NewPostgresRoleRepository(pool)
+		apiKeyRepo = postgres.NewPostgresAPIKeyRepository(pool)
+		identityRepo = postgres.NewPostgresExternalIdentityRepository(pool)
+		txManager = postgres.NewPostgresTxManager(pool)
+	default:
+		db, err := sqlite.InitDB(cfg.DatabaseDSN, cfg.AutoMigrate)
+		if err != nil {
+			log.Fatalf("Error al inicializar la base de datos: %v", err)
+		}
+		defer db.Close()
+
+		// Create repositories
+		userRepo = sqlite.NewSqliteUserRepository(db)
+		doctorRepo = sqlite.NewSqliteDoctorRepository(db)
+		patientRepo = sqlite.NewSqlitePatientRepository(db, cipher)
+		appointmentRepo = sqlite.NewSqliteAppointmentRepository(db, cipher)
+		serviceRepo = sqlite.NewSqliteServiceRepository(db)
+		doctorServiceRepo = sqlite.NewSqliteDoctorServiceRepository(db)
+		scheduleRepo = sqlite.NewSqliteScheduleRepository(db)
+		auditRepo = sqlite.NewSqliteAuditRepository(db)
+		clinicRepo = sqlite.NewSqliteClinicRepository(db)
+		refreshTokenRepo = sqlite.NewSqliteRefreshTokenRepository(db)
+		sessionRepo = sqlite.NewSqliteSessionRepository(db)
+		resetTokenRepo = sqlite.NewSqlitePasswordResetTokenRepository(db)
+		usedTokenRepo = sqlite.NewSqliteUsedTokenRepository(db)
+		invitationRepo = sqlite.NewSqliteInvitationRepository(db)
+		lockoutRepo = sqlite.NewSqliteLoginLockoutRepository(db)
+		twoFactorRepo = sqlite.NewSqliteTwoFactorRepository(db, cipher)
+		roleRepo = sqlite.NewSqliteRoleRepository(db)
+		apiKeyRepo = sqlite.NewSqliteAPIKeyRepository(db)
+		identityRepo = sqlite.NewSqliteExternalIdentityRepository(db)
+		txManager = sqlite.NewSqliteTxManager(db)
+	}
+
+	// Requests without X-Clinic-ID are served by the default clinic, so it must exist
+	defaultClinic, err := clinicRepo.FindClinicByID(context.Background(), cfg.DefaultClinicID)
 	if err != nil {
-		log.Fatalf("Error al inicializar la base de datos: %v", err)
+		log.Fatalf("Error al cargar la clínica por defecto: %v", err)
+	}
+	if defaultClinic == nil {
+		log.Fatalf("La clínica por defecto %s no existe (revise DEFAULT_CLINIC_ID)", cfg.DefaultClinicID)
+	}
+	fmt.Printf("🏢 Clínica por defecto: %s\n", defaultClinic.Name)
+
+	// The memory store starts without users and only admins can invite staff,
+	// so the first admin gets an invitation whose link is printed here
+	if *storage == storageMemory {
+		link, err := inviteFirstAdmin(invitationRepo, defaultClinic.ID, *adminEmail, cfg)
+		if err != nil {
+			log.Fatalf("Error al invitar al primer administrador: %v", err)
+		}
+		fmt.Printf("👤 Invitación para el administrador %s: %s\n", *adminEmail, link)
 	}
-	defer db.Close()
 	fmt.Println("⏰ Servicio de recordatorios iniciado")
 
-	// Create repositories
-	userRepo := sqlite.NewSqliteUserRepository(db)
-	doctorRepo := sqlite.NewSqliteDoctorRepository(db)
-	patientRepo := sqlite.NewSqlitePatientRepository(db)
-	appointmentRepo := sqlite.NewSqliteAppointmentRepository(db)
-	serviceRepo := sqlite.NewSqliteServiceRepository(db)
-	doctorServiceRepo := sqlite.NewSqliteDoctorServiceRepository(db)
-	scheduleRepo := sqlite.NewSqliteScheduleRepository(db)
-
 	// Create email service
 	emailService := email.NewEmailService(
 		cfg.SendGridAPIKey,
@@ -110,44 +302,110 @@ func main() {
 	)
 
 	// Create reminder service
-	reminderService := reminder.NewReminderService(appointmentRepo, userRepo, emailService)
+	reminderService := reminder.NewReminderService(appointmentRepo, userRepo, clinicRepo, emailService)
 
 	// Start reminder scheduler in background
 	reminderService.Start()
 
+	// Start purge of soft deleted records in background
+	if cfg.PurgeRetentionDays > 0 {
+		purgeService := purge.NewPurgeService(userRepo, serviceRepo, refreshTokenRepo, sessionRepo, resetTokenRepo, usedTokenRepo, lockoutRepo, cfg.PurgeRetentionDays, cfg.LoginLockMinutes)
+		purgeService.Start()
+		fmt.Printf("🗑️  Purga de registros eliminados: después de %d días\n", cfg.PurgeRetentionDays)
+	}
+
+	// Create audit recorder shared by every use case that changes data
+	auditRecorder := audit.NewRecorder(auditRepo)
+
+	// Create the authorizer that resolves the permissions of each role
+	authorizer := role.NewAuthorizer(roleRepo)
+
+	// Create the verification links sent to new accounts
+	emailVerification := auth.NewEmailVerification(userRepo, clinicRepo, emailService, cfg.JWTSecret, cfg.FrontendURL, cfg.VerifyLinkHours)
+
 	// Create use cases
-	createUserUC := user.NewCreateUserUseCase(userRepo, doctorRepo, patientRepo)
+	createUserUC := user.NewCreateUserUseCase(userRepo, doctorRepo, patientRepo, clinicRepo, txManager, auditRecorder, emailVerification)
 	getUserUC := user.NewGetUserUseCase(userRepo)
 	listUsersUC := user.NewListUsersUseCase(userRepo)
-	updateUserUC := user.NewUpdateUserUseCase(userRepo)
-	deleteUserUC := user.NewDeleteUserUseCase(userRepo)
+	updateUserUC := user.NewUpdateUserUseCase(userRepo, refreshTokenRepo, txManager, auditRecorder, authorizer)
+	deleteUserUC := user.NewDeleteUserUseCase(userRepo, doctorRepo, refreshTokenRepo, txManager, auditRecorder, authorizer)
+	listDeletedUsersUC := user.NewListDeletedUsersUseCase(userRepo)
+	restoreUserUC := user.NewRestoreUserUseCase(userRepo, doctorRepo, txManager, auditRecorder)
+	registerPatientUC := user.NewRegisterPatientUseCase(createUserUC)
+
+	// Create staff invitation use cases
+	createInvitationUC := user.NewCreateInvitationUseCase(userRepo, invitationRepo, clinicRepo, emailService, txManager, auditRecorder, cfg.FrontendURL, cfg.InvitationDays)
+	listInvitationsUC := user.NewListInvitationsUseCase(invitationRepo)
+	revokeInvitationUC := user.NewRevokeInvitationUseCase(invitationRepo, txManager, auditRecorder)
+	acceptInvitationUC := user.NewAcceptInvitationUseCase(invitationRepo, createUserUC, auditRecorder)
 
 	// Create appointment use cases
-	createAppointmentUC := appointment.NewCreateAppointmentUseCase(appointmentRepo, userRepo, serviceRepo, doctorServiceRepo, emailService)
+	createAppointmentUC := appointment.NewCreateAppointmentUseCase(appointmentRepo, userRepo, serviceRepo, doctorServiceRepo, scheduleRepo, clinicRepo, txManager, auditRecorder, authorizer, emailService)
 	getByPatientUC := appointment.NewGetAppointmentsByPatientUseCase(appointmentRepo, userRepo)
 	getByDoctorUC := appointment.NewGetAppointmentsByDoctorUseCase(appointmentRepo, userRepo)
-	cancelAppointmentUC := appointment.NewCancelAppointmentUseCase(appointmentRepo, userRepo, emailService)
-	confirmAppointmentUC := appointment.NewConfirmAppointmentUseCase(appointmentRepo, userRepo, emailService)
-	completeAppointmentUC := appointment.NewCompleteAppointmentUseCase(appointmentRepo, userRepo, emailService)
-	getHistoryUC := appointment.NewGetPatientHistoryUseCase(appointmentRepo, userRepo)
+	getAllAppointmentsUC := appointment.NewGetAllAppointmentsUseCase(appointmentRepo)
+	getAppointmentUC := appointment.NewGetAppointmentUseCase(appointmentRepo, userRepo, authorizer)
+	cancelAppointmentUC := appointment.NewCancelAppointmentUseCase(appointmentRepo, userRepo, clinicRepo, txManager, auditRecorder, authorizer, emailService)
+	confirmAppointmentUC := appointment.NewConfirmAppointmentUseCase(appointmentRepo, userRepo, clinicRepo, txManager, auditRecorder, authorizer, emailService)
+	completeAppointmentUC := appointment.NewCompleteAppointmentUseCase(appointmentRepo, userRepo, clinicRepo, txManager, auditRecorder, authorizer, emailService)
+	rescheduleAppointmentUC := appointment.NewRescheduleAppointmentUseCase(appointmentRepo, serviceRepo, scheduleRepo, clinicRepo, txManager, auditRecorder, authorizer)
+	getHistoryUC := appointment.NewGetPatientHistoryUseCase(appointmentRepo, userRepo, authorizer)
 	
 	// Create doctor use cases
-	searchDoctorsUC := doctor.NewSearchDoctorsUseCase(userRepo)
+	searchDoctorsUC := doctor.NewSearchDoctorsUseCase(doctorRepo, serviceRepo, scheduleRepo, appointmentRepo)
+	listDeletedDoctorsUC := doctor.NewListDeletedDoctorsUseCase(doctorRepo)
+	restoreDoctorUC := doctor.NewRestoreDoctorUseCase(doctorRepo, userRepo, txManager, auditRecorder)
 
 	// Create service use cases
-	createServiceUC := service.NewCreateServiceUseCase(serviceRepo)
-	listServicesUC := service.NewListServicesUseCase(serviceRepo)
-	assignServiceToDoctorUC := service.NewAssignServiceToDoctorUseCase(doctorServiceRepo, serviceRepo, userRepo)
+	createServiceUC := service.NewCreateServiceUseCase(serviceRepo, txManager, auditRecorder)
+	listServicesUC := service.NewListServicesUseCase(serviceRepo, clinicRepo)
+	getServiceUC := service.NewGetServiceUseCase(serviceRepo)
+	assignServiceToDoctorUC := service.NewAssignServiceToDoctorUseCase(doctorServiceRepo, serviceRepo, userRepo, txManager, auditRecorder)
 	getDoctorsByServiceUC := service.NewGetDoctorsByServiceUseCase(doctorServiceRepo, serviceRepo)
 	getAvailableSlotsUC := service.NewGetAvailableSlotsUseCase(serviceRepo, appointmentRepo, userRepo, scheduleRepo)
+	updateServiceUC := service.NewUpdateServiceUseCase(serviceRepo, txManager, auditRecorder)
+	deleteServiceUC := service.NewDeleteServiceUseCase(serviceRepo, doctorServiceRepo, txManager, auditRecorder)
+	listDeletedServicesUC := service.NewListDeletedServicesUseCase(serviceRepo)
+	restoreServiceUC := service.NewRestoreServiceUseCase(serviceRepo, txManager, auditRecorder)
 
 	// Create auth use cases
-	loginUC := auth.NewLoginUseCase(userRepo, cfg.JWTSecret, cfg.JWTExpirationHrs)
+	tokenService := auth.NewTokenService(refreshTokenRepo, sessionRepo, jwtKeys, cfg.AccessTokenMinutes, cfg.RefreshTokenDays)
+	loginGuard := auth.NewLoginGuard(lockoutRepo, clinicRepo, emailService, auditRecorder, cfg.FrontendURL, cfg.LoginMaxAttempts, cfg.LoginIPMaxAttempts, cfg.LoginLockMinutes)
+	twoFactorService := auth.NewTwoFactorService(twoFactorRepo, clinicRepo, usedTokenRepo, loginGuard, txManager, auditRecorder, cfg.JWTSecret, cfg.TwoFactorRoles)
+	ssoService := auth.NewSSOService(cfg.OIDCProvider(), identityRepo, usedTokenRepo, txManager, auditRecorder, cfg.JWTSecret, cfg.OIDCRoleClaim, cfg.OIDCRoleMapping, cfg.OIDCRequiredRoles)
+	loginUC := auth.NewLoginUseCase(userRepo, tokenService, loginGuard, twoFactorService, ssoService)
+	refreshUC := auth.NewRefreshUseCase(userRepo, refreshTokenRepo, tokenService, txManager)
+	logoutUC := auth.NewLogoutUseCase(refreshTokenRepo)
+	validateSessionUC := auth.NewValidateSessionUseCase(userRepo, refreshTokenRepo, sessionRepo)
+	forgotPasswordUC := auth.NewForgotPasswordUseCase(userRepo, resetTokenRepo, clinicRepo, emailService, cfg.FrontendURL, cfg.ResetTokenMinutes)
+	resetPasswordUC := auth.NewResetPasswordUseCase(userRepo, resetTokenRepo, refreshTokenRepo, txManager, auditRecorder)
+	listSessionsUC := auth.NewListSessionsUseCase(userRepo, sessionRepo)
+	endSessionUC := auth.NewEndSessionUseCase(sessionRepo, refreshTokenRepo)
+	endAllSessionsUC := auth.NewEndAllSessionsUseCase(sessionRepo, refreshTokenRepo, txManager)
+	endUserSessionsUC := auth.NewEndUserSessionsUseCase(userRepo, sessionRepo, refreshTokenRepo, txManager, auditRecorder)
+	verifyEmailUC := auth.NewVerifyEmailUseCase(userRepo, emailVerification, txManager, auditRecorder)
+	resendVerificationUC := auth.NewResendVerificationUseCase(userRepo, emailVerification)
+	unlockAccountUC := auth.NewUnlockAccountUseCase(lockoutRepo, txManager, auditRecorder)
+	listLockoutsUC := auth.NewListLockoutsUseCase(lockoutRepo)
+	clearLockoutUC := auth.NewClearLockoutUseCase(lockoutRepo, txManager, auditRecorder)
+	setupTwoFactorUC := auth.NewSetupTwoFactorUseCase(userRepo, twoFactorService)
+	verifyTwoFactorUC := auth.NewVerifyTwoFactorUseCase(userRepo, twoFactorService, tokenService, loginGuard)
+	getTwoFactorStatusUC := auth.NewGetTwoFactorStatusUseCase(userRepo, twoFactorRepo, twoFactorService)
+	enrollTwoFactorUC := auth.NewEnrollTwoFactorUseCase(userRepo, twoFactorService)
+	confirmTwoFactorUC := auth.NewConfirmTwoFactorUseCase(userRepo, twoFactorService)
+	regenerateRecoveryCodesUC := auth.NewRegenerateRecoveryCodesUseCase(userRepo, twoFactorService)
+	disableTwoFactorUC := auth.NewDisableTwoFactorUseCase(userRepo, twoFactorService)
+	resetTwoFactorUC := auth.NewResetTwoFactorUseCase(userRepo, twoFactorRepo, twoFactorService)
+	startSSOUC := auth.NewStartSSOUseCase(ssoService)
+	completeSSOUC := auth.NewCompleteSSOUseCase(userRepo, identityRepo, ssoService, tokenService)
+	unlinkSSOUC := auth.NewUnlinkSSOUseCase(userRepo, identityRepo, txManager, auditRecorder)
 
 	// Create schedule use cases
-	createScheduleUC := schedule.NewCreateScheduleUseCase(scheduleRepo, userRepo)
+	createScheduleUC := schedule.NewCreateScheduleUseCase(scheduleRepo, userRepo, clinicRepo, txManager, auditRecorder)
 	getSchedulesUC := schedule.NewGetDoctorSchedulesUseCase(scheduleRepo, userRepo)
-	deleteScheduleUC := schedule.NewDeleteScheduleUseCase(scheduleRepo)
+	getScheduleUC := schedule.NewGetScheduleUseCase(scheduleRepo)
+	updateScheduleUC := schedule.NewUpdateScheduleUseCase(scheduleRepo, userRepo, clinicRepo, txManager, auditRecorder)
+	deleteScheduleUC := schedule.NewDeleteScheduleUseCase(scheduleRepo, txManager, auditRecorder)
 
 	// Create analytics use cases
 	getDashboardSummaryUC := analytics.NewGetDashboardSummaryUseCase(appointmentRepo, userRepo)
@@ -155,17 +413,59 @@ func main() {
 	getTopDoctorsUC := analytics.NewGetTopDoctorsUseCase(appointmentRepo, userRepo)
 	getTopServicesUC := analytics.NewGetTopServicesUseCase(appointmentRepo)
 
+	// Create audit use cases
+	listAuditLogUC := audit.NewListAuditLogUseCase(auditRepo)
+
+	// Create role use cases
+	listRolesUC := role.NewListRolesUseCase(authorizer)
+	getRoleUC := role.NewGetRoleUseCase(authorizer)
+	updateRoleUC := role.NewUpdateRoleUseCase(roleRepo, authorizer, txManager, auditRecorder)
+	resetRoleUC := role.NewResetRoleUseCase(roleRepo, authorizer, txManager, auditRecorder)
+	listPermissionsUC := role.NewListPermissionsUseCase()
+
+	// Create API key use cases
+	createAPIKeyUC := apikey.NewCreateAPIKeyUseCase(apiKeyRepo, authorizer, txManager, auditRecorder)
+	listAPIKeysUC := apikey.NewListAPIKeysUseCase(apiKeyRepo)
+	revokeAPIKeyUC := apikey.NewRevokeAPIKeyUseCase(apiKeyRepo, txManager, auditRecorder)
+	getAPIKeyUsageUC := apikey.NewGetAPIKeyUsageUseCase(apiKeyRepo)
+	authenticateAPIKeyUC := apikey.NewAuthenticateAPIKeyUseCase(apiKeyRepo, userRepo, authorizer)
+
+	// Create patient use cases
+	findPatientsByDocumentUC := patient.NewFindByDocumentUseCase(patientRepo, userRepo)
+
+	// Create location use cases
+	getClinicUC := location.NewGetClinicUseCase(clinicRepo)
+	listLocationsUC := location.NewListLocationsUseCase(clinicRepo)
+	createLocationUC := location.NewCreateLocationUseCase(clinicRepo, txManager, auditRecorder)
+	updateLocationUC := location.NewUpdateLocationUseCase(clinicRepo, txManager, auditRecorder)
+	assignLocationDoctorUC := location.NewAssignDoctorUseCase(clinicRepo, userRepo, txManager, auditRecorder)
+	removeLocationDoctorUC := location.NewRemoveDoctorUseCase(clinicRepo, userRepo, scheduleRepo, txManager, auditRecorder)
+	listServicePricesUC := location.NewListServicePricesUseCase(clinicRepo)
+	setServicePriceUC := location.NewSetServicePriceUseCase(clinicRepo, serviceRepo, txManager, auditRecorder)
+	removeServicePriceUC := location.NewRemoveServicePriceUseCase(clinicRepo, txManager, auditRecorder)
+
 	// Create handlers
-	userHandler := handler.NewUserHandler(createUserUC, getUserUC, listUsersUC, updateUserUC, deleteUserUC)
-	authHandler := handler.NewAuthHandler(loginUC)
-	appointmentHandler := handler.NewAppointmentHandler(createAppointmentUC, getByPatientUC, getByDoctorUC, cancelAppointmentUC, confirmAppointmentUC, completeAppointmentUC, getHistoryUC)
-	doctorHandler := handler.NewDoctorHandler(searchDoctorsUC)
-	serviceHandler := handler.NewServiceHandler(createServiceUC, listServicesUC, assignServiceToDoctorUC, getDoctorsByServiceUC, getAvailableSlotsUC)
-	scheduleHandler := handler.NewScheduleHandler(createScheduleUC, getSchedulesUC, deleteScheduleUC)
+	userHandler := handler.NewUserHandler(registerPatientUC, getUserUC, listUsersUC, updateUserUC, deleteUserUC, listDeletedUsersUC, restoreUserUC)
+	authHandler := handler.NewAuthHandler(loginUC, refreshUC, logoutUC, forgotPasswordUC, resetPasswordUC, verifyEmailUC, resendVerificationUC, unlockAccountUC)
+	appointmentHandler := handler.NewAppointmentHandler(createAppointmentUC, getByPatientUC, getByDoctorUC, getAllAppointmentsUC, getAppointmentUC, cancelAppointmentUC, confirmAppointmentUC, completeAppointmentUC, rescheduleAppointmentUC, getHistoryUC)
+	doctorHandler := handler.NewDoctorHandler(searchDoctorsUC, listDeletedDoctorsUC, restoreDoctorUC)
+	serviceHandler := handler.NewServiceHandler(createServiceUC, listServicesUC, getServiceUC, assignServiceToDoctorUC, getDoctorsByServiceUC, getAvailableSlotsUC, updateServiceUC, deleteServiceUC, listDeletedServicesUC, restoreServiceUC)
+	scheduleHandler := handler.NewScheduleHandler(createScheduleUC, getSchedulesUC, getScheduleUC, updateScheduleUC, deleteScheduleUC)
 	analyticsHandler := handler.NewAnalyticsHandler(getDashboardSummaryUC, getRevenueStatsUC, getTopDoctorsUC, getTopServicesUC)
+	auditHandler := handler.NewAuditHandler(listAuditLogUC)
+	patientHandler := handler.NewPatientHandler(findPatientsByDocumentUC)
+	invitationHandler := handler.NewInvitationHandler(createInvitationUC, listInvitationsUC, revokeInvitationUC, acceptInvitationUC)
+	lockoutHandler := handler.NewLockoutHandler(listLockoutsUC, clearLockoutUC)
+	twoFactorHandler := handler.NewTwoFactorHandler(setupTwoFactorUC, verifyTwoFactorUC, getTwoFactorStatusUC, enrollTwoFactorUC, confirmTwoFactorUC, regenerateRecoveryCodesUC, disableTwoFactorUC, resetTwoFactorUC)
+	roleHandler := handler.NewRoleHandler(listRolesUC, getRoleUC, updateRoleUC, resetRoleUC, listPermissionsUC)
+	apiKeyHandler := handler.NewAPIKeyHandler(createAPIKeyUC, listAPIKeysUC, revokeAPIKeyUC, getAPIKeyUsageUC)
+	jwksHandler := handler.NewJWKSHandler(jwtKeys)
+	ssoHandler := handler.NewSSOHandler(startSSOUC, completeSSOUC, unlinkSSOUC)
+	sessionHandler := handler.NewSessionHandler(listSessionsUC, endSessionUC, endAllSessionsUC, endUserSessionsUC)
+	locationHandler := handler.NewLocationHandler(getClinicUC, listLocationsUC, createLocationUC, updateLocationUC, assignLocationDoctorUC, removeLocationDoctorUC, listServicePricesUC, setServicePriceUC, removeServicePriceUC)
 
 	// Configure router
-	router := httpDelivery.SetupRouter(userHandler, authHandler, appointmentHandler, doctorHandler, serviceHandler, scheduleHandler, analyticsHandler, cfg.JWTSecret, cfg.AllowedOrigins)
+	router := httpDelivery.SetupRouter(userHandler, authHandler, appointmentHandler, doctorHandler, serviceHandler, scheduleHandler, analyticsHandler, auditHandler, locationHandler, patientHandler, invitationHandler, lockoutHandler, twoFactorHandler, roleHandler, apiKeyHandler, jwksHandler, ssoHandler, sessionHandler, clinicRepo, cfg.DefaultClinicID, jwtKeys, validateSessionUC, authenticateAPIKeyUC, authorizer, cfg.AllowedOrigins, cfg.TrustedProxies)
 
 	// Configure HTTP server
 	port := ":" + cfg.ServerPort
@@ -173,37 +473,123 @@ func main() {
 	fmt.Println("📍 Endpoints disponibles:")
 	fmt.Println("   GET  /                      - Health check")
 	fmt.Println("   GET  /swagger/              - Swagger API Documentation")
-	fmt.Println("   POST /api/users             - Crear usuario")
+	fmt.Println("   GET  /.well-known/jwks.json - Claves públicas para verificar los tokens")
+	fmt.Println("   POST /api/users             - Registrar paciente (público)")
 	fmt.Println("   GET  /api/users?id=<uuid>   - Obtener usuario por ID")
 	fmt.Println("   POST /api/auth/login        - Login (obtener token)")
+	fmt.Println("   POST /api/auth/refresh      - Renovar token con el refresh token")
+	fmt.Println("   POST /api/auth/logout       - Cerrar sesión (revoca el refresh token)")
+	fmt.Println("   POST /api/auth/forgot-password - Enviar enlace para restablecer la contraseña")
+	fmt.Println("   POST /api/auth/reset-password  - Restablecer la contraseña con el token del enlace")
+	fmt.Println("   GET  /api/auth/verify-email    - Verificar el email con el token del enlace")
+	fmt.Println("   POST /api/auth/resend-verification - Reenviar el enlace de verificación (requiere token)")
+	fmt.Println("   POST /api/auth/unlock          - Desbloquear la cuenta con el token del enlace")
+	fmt.Println("   POST /api/auth/2fa/setup       - Configurar la verificación en dos pasos exigida al iniciar sesión")
+	fmt.Println("   POST /api/auth/2fa/verify      - Segundo paso del login con el código TOTP o de recuperación")
+	fmt.Println("   GET  /api/auth/sso/start       - Iniciar sesión con el proveedor de identidad (personal)")
+	fmt.Println("   POST /api/auth/sso/callback    - Completar el inicio de sesión único con el code del proveedor")
 	fmt.Println("   GET  /api/users/me          - Obtener perfil (requiere token)")
-	fmt.Println("   GET  /api/users/list        - Listar usuarios (solo admin)")
-	fmt.Println("   PUT    /api/users/{id}        - Actualizar usuario (admin o mismo user)")
-	fmt.Println("   DELETE /api/users/delete?id=    - Eliminar usuario (solo admin)")
-	fmt.Println("   POST   /api/appointments         - Crear cita (autenticado)")
+	fmt.Println("   GET  /api/users/list        - Listar usuarios (permiso users:read)")
+	fmt.Println("   PUT    /api/users/{id}        - Actualizar usuario (mismo user o permiso users:write)")
+	fmt.Println("   DELETE /api/users/delete?id=    - Eliminar usuario (permiso users:write)")
+	fmt.Println("   GET    /api/users/me/2fa         - Estado de mi verificación en dos pasos (requiere token)")
+	fmt.Println("   POST   /api/users/me/2fa         - Activar la verificación en dos pasos (personal)")
+	fmt.Println("   POST   /api/users/me/2fa/confirm - Confirmarla con un primer código")
+	fmt.Println("   POST   /api/users/me/2fa/recovery-codes - Regenerar los códigos de recuperación")
+	fmt.Println("   DELETE /api/users/me/2fa         - Desactivar la verificación en dos pasos")
+	fmt.Println("   DELETE /api/users/{id}/2fa       - Restablecer la verificación en dos pasos de un usuario (permiso users:write)")
+	fmt.Println("   DELETE /api/users/{id}/sso       - Desvincular la identidad del proveedor de un usuario (permiso users:write)")
+	fmt.Println("   GET    /api/users/me/sessions    - Mis sesiones activas y sus dispositivos (requiere token)")
+	fmt.Println("   DELETE /api/users/me/sessions/{id} - Cerrar la sesión de un dispositivo")
+	fmt.Println("   DELETE /api/users/me/sessions    - Cerrar sesión en todos los dispositivos (?keep_current=true conserva este)")
+	fmt.Println("   GET    /api/users/{id}/sessions  - Sesiones activas de un usuario (permiso users:read)")
+	fmt.Println("   DELETE /api/users/{id}/sessions  - Cerrar todas las sesiones de un usuario, también si está desactivado (permiso users:write)")
+	fmt.Println("   GET    /api/users/deleted        - Usuarios eliminados (permiso users:read)")
+	fmt.Println("   POST   /api/users/restore?id=    - Restaurar usuario (permiso users:write)")
+	fmt.Println("   POST   /api/invitations          - Invitar personal (permiso invitations:manage)")
+	fmt.Println("   GET    /api/invitations?status=  - Listar invitaciones (permiso invitations:manage)")
+	fmt.Println("   DELETE /api/invitations/{id}     - Revocar invitación (permiso invitations:manage)")
+	fmt.Println("   POST   /api/invitations/accept   - Crear cuenta con el token de la invitación")
+	fmt.Println("   GET    /api/roles                - Roles y sus permisos en la clínica (permiso roles:manage)")
+	fmt.Println("   GET    /api/roles/{role}         - Permisos de un rol (permiso roles:manage)")
+	fmt.Println("   PUT    /api/roles/{role}         - Cambiar los permisos de un rol (permiso roles:manage)")
+	fmt.Println("   DELETE /api/roles/{role}         - Restablecer los permisos predeterminados de un rol (permiso roles:manage)")
+	fmt.Println("   GET    /api/permissions          - Permisos que se pueden dar a un rol (permiso roles:manage)")
+	fmt.Println("   POST   /api/api-keys             - Crear API key para una integración (permiso api_keys:manage)")
+	fmt.Println("   GET    /api/api-keys             - Listar API keys (permiso api_keys:manage)")
+	fmt.Println("   DELETE /api/api-keys/{id}        - Revocar API key (permiso api_keys:manage)")
+	fmt.Println("   GET    /api/api-keys/{id}/usage?days= - Peticiones por día de una API key (permiso api_keys:manage)")
+	fmt.Println("   GET    /api/lockouts?scope=      - Cuentas e IPs bloqueadas por intentos fallidos (permiso lockouts:manage)")
+	fmt.Println("   DELETE /api/lockouts/{id}        - Levantar un bloqueo (permiso lockouts:manage)")
+	fmt.Println("   POST   /api/appointments         - Crear cita (autenticado; a nombre de otro paciente con appointments:create:any)")
 	fmt.Println("   GET    /api/appointments/my      - Mis citas (autenticado)")
 	fmt.Println("   GET    /api/appointments/doctor  - Citas doctor (solo doctor)")
+	fmt.Println("   GET    /api/appointments/all     - Todas las citas con filtros (permiso appointments:read:any)")
 	fmt.Println("   PUT    /api/appointments/cancel  - Cancelar cita (autenticado)")
-	fmt.Println("   GET    /api/doctors/search?specialty= - Buscar doctores (público)")
-	fmt.Println("   PUT    /api/appointments/confirm?id= - Confirmar cita (doctor/admin)")
-	fmt.Println("   PUT    /api/appointments/complete?id= - Completar cita (doctor/admin)")
+	fmt.Println("   GET    /api/doctors/search?q=&specialty= - Buscar doctores (público)")
+	fmt.Println("   GET    /api/doctors/deleted      - Doctores eliminados (permiso doctors:write)")
+	fmt.Println("   POST   /api/doctors/restore?id=  - Restaurar doctor (permiso doctors:write)")
+	fmt.Println("   PUT    /api/appointments/confirm?id= - Confirmar cita (su doctor o permiso appointments:update:any)")
+	fmt.Println("   PUT    /api/appointments/complete?id= - Completar cita (su doctor o permiso appointments:update:any)")
+	fmt.Println("   PUT    /api/appointments/reschedule?id= - Reprogramar cita (permiso appointments:reschedule:any)")
 	fmt.Println("   GET    /api/appointments/history?patient_id= - Historial médico (autenticado)")
-	fmt.Println("   POST   /api/services/create      - Crear servicio (solo admin)")
+	fmt.Println("   POST   /api/services/create      - Crear servicio (permiso services:write)")
 	fmt.Println("   GET    /api/services             - Listar servicios activos (público)")
-	fmt.Println("   POST   /api/services/assign      - Asignar servicio a doctor (solo admin)")
+	fmt.Println("   POST   /api/services/assign      - Asignar servicio a doctor (permiso services:write)")
 	fmt.Println("   GET    /api/services/doctors?service_id= - Obtener doctores por servicio (público)")
-	fmt.Println("   GET    /api/services/available-slots?doctor_id=&service_id=&date= - Obtener slots disponibles (público)")
-	fmt.Println("   POST   /api/schedules            - Crear horario (admin)")
+	fmt.Println("   GET    /api/services/available-slots?doctor_id=&service_id=&date=&location_id= - Obtener slots disponibles (público)")
+	fmt.Println("   PUT    /api/services/update?id=  - Actualizar servicio (permiso services:write)")
+	fmt.Println("   DELETE /api/services/delete?id=  - Eliminar servicio (permiso services:write)")
+	fmt.Println("   GET    /api/services/deleted     - Servicios eliminados (permiso services:write)")
+	fmt.Println("   POST   /api/services/restore?id= - Restaurar servicio (permiso services:write)")
+	fmt.Println("   POST   /api/schedules            - Crear horario (permiso schedules:write)")
 	fmt.Println("   GET    /api/schedules/doctor/{id} - Ver horarios de doctor (público)")
-	fmt.Println("   DELETE /api/schedules/{id}       - Eliminar horario (admin)")
-	fmt.Println("   GET    /api/analytics/dashboard  - Resumen del dashboard (solo admin)")
-	fmt.Println("   GET    /api/analytics/revenue    - Estadísticas de ingresos (solo admin)")
-	fmt.Println("   GET    /api/analytics/top-doctors?limit=10 - Top doctores (solo admin)")
-	fmt.Println("   GET    /api/analytics/top-services?limit=10 - Top servicios (solo admin)")
+	fmt.Println("   PUT    /api/schedules/{id}       - Actualizar horario (permiso schedules:write)")
+	fmt.Println("   DELETE /api/schedules/{id}       - Eliminar horario (permiso schedules:write)")
+	fmt.Println("   GET    /api/analytics/dashboard  - Resumen del dashboard (permiso analytics:read)")
+	fmt.Println("   GET    /api/analytics/revenue    - Estadísticas de ingresos (permiso analytics:read)")
+	fmt.Println("   GET    /api/analytics/top-doctors?limit=10 - Top doctores (permiso analytics:read)")
+	fmt.Println("   GET    /api/analytics/top-services?limit=10 - Top servicios (permiso analytics:read)")
+	fmt.Println("   GET    /api/audit?entity=&actor_id=&date_from=&date_to= - Registro de auditoría (permiso audit:read)")
+	fmt.Println("   GET    /api/patients/by-document?document_number=&document_type= - Buscar pacientes por documento (permiso patients:read)")
+	fmt.Println("   GET    /api/clinic               - Clínica y sus sedes activas (público, header X-Clinic-ID)")
+	fmt.Println("   GET    /api/locations            - Listar sedes (público)")
+	fmt.Println("   POST   /api/locations            - Crear sede (permiso locations:write)")
+	fmt.Println("   PUT    /api/locations/{id}       - Actualizar sede (permiso locations:write)")
+	fmt.Println("   POST   /api/locations/{id}/doctors - Asignar doctor a sede (permiso locations:write)")
+	fmt.Println("   DELETE /api/locations/{id}/doctors/{doctorId} - Quitar doctor de sede (permiso locations:write)")
+	fmt.Println("   GET    /api/locations/{id}/prices - Precios de la sede (público)")
+	fmt.Println("   PUT    /api/locations/{id}/prices/{serviceId} - Fijar precio en sede (permiso locations:write)")
+	fmt.Println("   DELETE /api/locations/{id}/prices/{serviceId} - Quitar precio de sede (permiso locations:write)")
 	fmt.Println("\n⏳ Presiona Ctrl+C para detener el servidor...")
 
 	// Start HTTP server
 	if err := http.ListenAndServe(port, router); err != nil {
 		log.Fatalf("Error al iniciar servidor: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// inviteFirstAdmin stores an admin invitation for a fresh memory store and
+// returns its link, the same one clinicctl invite prints for a database
+func inviteFirstAdmin(invitationRepo repository.InvitationRepository, clinicID, adminEmail string, cfg *config.Config) (string, error) {
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	invitation := &domain.Invitation{
+		ID:        uuid.New().String(),
+		ClinicID:  clinicID,
+		Email:     adminEmail,
+		Role:      domain.RoleAdmin,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: now.AddDate(0, 0, cfg.InvitationDays),
+		CreatedAt: now,
+	}
+	if err := invitationRepo.Create(context.Background(), invitation); err != nil {
+		return "", err
+	}
+
+	return cfg.FrontendURL + "/accept-invitation?token=" + url.QueryEscape(token), nil
+}
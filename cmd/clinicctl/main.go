@@ -0,0 +1,568 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/postgres"
+	"version-1-0/internal/repository/sqlite"
+	"version-1-0/internal/usecase/auth"
+	"version-1-0/pkg/archive"
+	"version-1-0/pkg/config"
+	"version-1-0/pkg/fieldcrypt"
+	"version-1-0/pkg/jwtkeys"
+)
+
+const usage = `Uso: clinicctl <comando> [flags] [archivo]
+
+Comandos:
+  export [--anonymize] [archivo]  Exporta todos los datos de la clínica a un archivo NDJSON
+                                  (a la salida estándar si no se indica archivo)
+  import <archivo>                Importa un archivo exportado, todo o nada
+  check <archivo>                 Verifica un archivo sin importarlo
+  create-clinic --name <nombre> [--currency S/] [--location <sede>] [--address <dirección>]
+                                  Crea una clínica con su primera sede e imprime sus IDs
+  invite --email <email> [--role admin] [--clinic <id>] [--days 7]
+                                  Invita a un administrador o doctor e imprime el enlace
+                                  para crear la cuenta; así se crea el primer admin
+  generate-key                    Imprime una clave nueva para ENCRYPTION_KEYS o BLIND_INDEX_KEY
+  rotate-keys                     Vuelve a cifrar con la clave principal los datos cifrados
+                                  con claves anteriores o guardados sin cifrar
+  generate-jwt-key --dir <directorio> [--alg EdDSA] [--id <kid>]
+                                  Crea una clave para firmar los tokens de acceso en
+                                  JWT_KEYS_DIR (EdDSA o RS256)
+
+Se exportan clínicas, sedes, usuarios, doctores, pacientes, servicios,
+asignaciones de servicios y de sedes, precios por sede, horarios y citas.
+Los archivos de la versión 1 se importan en la clínica y sede por defecto.
+La base de datos se toma de DATABASE_URL (igual que la API) y las claves
+de cifrado de ENCRYPTION_KEYS y BLIND_INDEX_KEY; import, create-clinic y
+rotate-keys aplican las migraciones pendientes si AUTO_MIGRATE=true.
+Los enlaces de invite apuntan a FRONTEND_URL.
+`
+
+func main() {
+	log.SetFlags(0)
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+
+	var err error
+	switch command {
+	case "export":
+		err = runExport(args)
+	case "import":
+		err = runImport(args)
+	case "check":
+		err = runCheck(args)
+	case "create-clinic":
+		err = runCreateClinic(args)
+	case "invite":
+		err = runInvite(args)
+	case "generate-key":
+		err = runGenerateKey(args)
+	case "rotate-keys":
+		err = runRotateKeys(args)
+	case "generate-jwt-key":
+		err = runGenerateJWTKey(args)
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		var integrityErr *archive.IntegrityError
+		if errors.As(err, &integrityErr) {
+			for _, problem := range integrityErr.Problems {
+				fmt.Fprintf(os.Stderr, "  ✗ %s\n", problem)
+			}
+			log.Fatalf("Error: el archivo tiene %d problema(s) de integridad, no se importó nada", len(integrityErr.Problems))
+		}
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// runExport writes every record of the database to a file or to stdout
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	anonymize := flags.Bool("anonymize", false, "reemplaza nombres, contactos, documentos y notas por datos ficticios")
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		return fmt.Errorf("export recibe como máximo un archivo")
+	}
+
+	repos, closeDB, err := openRepositories(false)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	exporter := archive.NewExporter(
+		repos.user,
+		repos.doctor,
+		repos.patient,
+		repos.service,
+		repos.doctorService,
+		repos.schedule,
+		repos.appointment,
+		repos.clinic,
+	)
+	data, err := exporter.Read(context.Background())
+	if err != nil {
+		return err
+	}
+	if *anonymize {
+		archive.Anonymize(data)
+	}
+
+	var out io.Writer = os.Stdout
+	path := flags.Arg(0)
+	if path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := archive.Write(out, data, *anonymize); err != nil {
+		return err
+	}
+	if file, ok := out.(*os.File); ok && file != os.Stdout {
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "✅ Exportación completa")
+	printCounts(data)
+	return nil
+}
+
+// runImport checks an archive and loads it into the database
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import requiere un archivo, por ejemplo: clinicctl import backup.ndjson")
+	}
+
+	header, data, err := readArchive(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	repos, closeDB, err := openRepositories(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	importer := archive.NewImporter(
+		repos.user,
+		repos.doctor,
+		repos.patient,
+		repos.service,
+		repos.doctorService,
+		repos.schedule,
+		repos.appointment,
+		repos.clinic,
+		repos.txManager,
+	)
+	if err := importer.Import(context.Background(), data); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Importación completa (archivo del %s)\n", header.ExportedAt.Format("2006-01-02 15:04:05"))
+	if header.Anonymized {
+		fmt.Fprintln(os.Stderr, "   Los datos están anonimizados: las cuentas importadas no pueden iniciar sesión")
+	}
+	printCounts(data)
+	return nil
+}
+
+// runCheck reads an archive and runs the import checks without touching a database
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("check requiere un archivo")
+	}
+
+	header, data, err := readArchive(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := archive.Check(data); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Archivo válido (versión %d, exportado el %s, anonimizado: %t)\n",
+		header.Version, header.ExportedAt.Format("2006-01-02 15:04:05"), header.Anonymized)
+	printCounts(data)
+	return nil
+}
+
+// runCreateClinic adds a clinic with its first location
+// The API serves it to requests that send its ID in the X-Clinic-ID header
+func runCreateClinic(args []string) error {
+	flags := flag.NewFlagSet("create-clinic", flag.ExitOnError)
+	name := flags.String("name", "", "nombre de la clínica, se muestra en los correos")
+	currency := flags.String("currency", "S/", "símbolo de la moneda de los precios")
+	locationName := flags.String("location", "Sede Principal", "nombre de la primera sede")
+	address := flags.String("address", "", "dirección de la primera sede")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("create-clinic no recibe argumentos, use los flags")
+	}
+
+	now := time.Now()
+	clinic := &domain.Clinic{
+		ID:             uuid.New().String(),
+		Name:           strings.TrimSpace(*name),
+		CurrencySymbol: strings.TrimSpace(*currency),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := clinic.Validate(); err != nil {
+		return err
+	}
+	location := &domain.Location{
+		ID:        uuid.New().String(),
+		ClinicID:  clinic.ID,
+		Name:      strings.TrimSpace(*locationName),
+		Address:   strings.TrimSpace(*address),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+	if err := location.Validate(); err != nil {
+		return err
+	}
+
+	repos, closeDB, err := openRepositories(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	err = repos.txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := repos.clinic.CreateClinic(ctx, clinic); err != nil {
+			return err
+		}
+		return repos.clinic.CreateLocation(ctx, location)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Clínica %q creada\n", clinic.Name)
+	fmt.Fprintf(os.Stderr, "   Sede: %s (%s)\n", location.Name, location.ID)
+	fmt.Fprintf(os.Stderr, "   Envíe el header X-Clinic-ID: %s para trabajar con ella\n", clinic.ID)
+	fmt.Println(clinic.ID)
+	return nil
+}
+
+// runInvite stores a staff invitation and prints its link instead of emailing it
+// The API only lets admins invite, so this is how a new clinic gets its first admin
+func runInvite(args []string) error {
+	flags := flag.NewFlagSet("invite", flag.ExitOnError)
+	emailAddress := flags.String("email", "", "email de la persona invitada")
+	role := flags.String("role", string(domain.RoleAdmin), "rol de la cuenta: admin, doctor, nurse o receptionist")
+	clinicID := flags.String("clinic", domain.DefaultClinicID, "clínica a la que se invita")
+	days := flags.Int("days", 7, "días de validez del enlace")
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("invite no recibe argumentos, use los flags")
+	}
+
+	if strings.TrimSpace(*emailAddress) == "" {
+		return fmt.Errorf("--email es obligatorio")
+	}
+	if !domain.UserRole(*role).IsStaff() {
+		return fmt.Errorf("--role debe ser admin, doctor, nurse o receptionist")
+	}
+	if *days <= 0 {
+		return fmt.Errorf("--days debe ser positivo")
+	}
+
+	repos, closeDB, err := openRepositories(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx := repository.WithClinic(context.Background(), *clinicID)
+	clinic, err := repos.clinic.FindClinicByID(ctx, *clinicID)
+	if err != nil {
+		return err
+	}
+	if clinic == nil {
+		return fmt.Errorf("la clínica %s no existe", *clinicID)
+	}
+	taken, err := repos.user.EmailExists(ctx, strings.TrimSpace(*emailAddress))
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("ya existe una cuenta con el email %s", strings.TrimSpace(*emailAddress))
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	invitation := &domain.Invitation{
+		ID:        uuid.New().String(),
+		ClinicID:  clinic.ID,
+		Email:     strings.TrimSpace(*emailAddress),
+		Role:      domain.UserRole(*role),
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: now.AddDate(0, 0, *days),
+		CreatedAt: now,
+	}
+
+	err = repos.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repos.invitation.RevokeByEmail(ctx, invitation.Email, now); err != nil {
+			return err
+		}
+		return repos.invitation.Create(ctx, invitation)
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Invitación para %s (%s) en %q creada\n", invitation.Email, invitation.Role, clinic.Name)
+	fmt.Fprintf(os.Stderr, "   Vence: %s\n", invitation.ExpiresAt.Format("2006-01-02 15:04"))
+	fmt.Fprintln(os.Stderr, "   Abra el enlace, o envíe el token a POST /api/invitations/accept, para crear la cuenta")
+	fmt.Println(config.LoadFrontendURL() + "/accept-invitation?token=" + url.QueryEscape(token))
+	return nil
+}
+
+// runGenerateKey prints a random key in the format ENCRYPTION_KEYS and BLIND_INDEX_KEY expect
+func runGenerateKey(args []string) error {
+	flags := flag.NewFlagSet("generate-key", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("generate-key no recibe argumentos")
+	}
+
+	key, err := fieldcrypt.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Use la clave como BLIND_INDEX_KEY o agréguela a ENCRYPTION_KEYS con un ID, por ejemplo 2025-01:<clave>")
+	fmt.Println(key)
+	return nil
+}
+
+// runGenerateJWTKey writes a new private key for signing access tokens to <dir>/<kid>.pem
+// The key verifies tokens as soon as the API restarts with it in JWT_KEYS_DIR,
+// and signs them once JWT_SIGNING_KEY_ID names it
+func runGenerateJWTKey(args []string) error {
+	flags := flag.NewFlagSet("generate-jwt-key", flag.ExitOnError)
+	dir := flags.String("dir", "", "directorio de las claves (JWT_KEYS_DIR)")
+	algorithm := flags.String("alg", jwtkeys.AlgorithmEdDSA, "algoritmo de firma: EdDSA o RS256")
+	kid := flags.String("id", time.Now().UTC().Format("20060102-150405"), "ID de la clave (kid), también el nombre del archivo")
+	flags.Parse(args)
+
+	if *dir == "" {
+		return fmt.Errorf("--dir es obligatorio")
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("generate-jwt-key no recibe argumentos")
+	}
+
+	data, err := jwtkeys.GenerateKey(*algorithm)
+	if err != nil {
+		return err
+	}
+	// Parsing it back checks the kid before anything is written
+	key, err := jwtkeys.ParseKey(*kid, data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(*dir, key.ID+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Clave %s guardada en %s. Reinicie la API para publicarla y, cuando los demás servicios la tengan, use JWT_SIGNING_KEY_ID=%s\n", key.Algorithm, path, key.ID)
+	fmt.Println(key.ID)
+	return nil
+}
+
+// runRotateKeys re-encrypts the sensitive columns with the primary key
+// Run it after putting a new key first in ENCRYPTION_KEYS, and remove the old
+// key only once it finishes; it is also how data stored before encryption
+// existed gets encrypted and indexed
+func runRotateKeys(args []string) error {
+	flags := flag.NewFlagSet("rotate-keys", flag.ExitOnError)
+	flags.Parse(args)
+	if flags.NArg() != 0 {
+		return fmt.Errorf("rotate-keys no recibe argumentos")
+	}
+
+	repos, closeDB, err := openRepositories(true)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	ctx := context.Background()
+	patients, err := repos.patient.Reencrypt(ctx)
+	if err != nil {
+		return fmt.Errorf("re-encrypting patients (%d done): %w", patients, err)
+	}
+	appointments, err := repos.appointment.Reencrypt(ctx)
+	if err != nil {
+		return fmt.Errorf("re-encrypting appointments (%d done): %w", appointments, err)
+	}
+	twoFactors, err := repos.twoFactor.Reencrypt(ctx)
+	if err != nil {
+		return fmt.Errorf("re-encrypting two-factor secrets (%d done): %w", twoFactors, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Datos cifrados con la clave %q\n", repos.cipher.PrimaryKeyID())
+	fmt.Fprintf(os.Stderr, "   Pacientes actualizados: %d\n", patients)
+	fmt.Fprintf(os.Stderr, "   Citas actualizadas: %d\n", appointments)
+	fmt.Fprintf(os.Stderr, "   Secretos de verificación en dos pasos actualizados: %d\n", twoFactors)
+	fmt.Fprintln(os.Stderr, "   Las claves anteriores ya se pueden quitar de ENCRYPTION_KEYS")
+	return nil
+}
+
+// readArchive opens and parses an archive file
+func readArchive(path string) (*archive.Header, *archive.Data, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return archive.Read(file)
+}
+
+// printCounts prints how many records of each type an archive holds
+func printCounts(data *archive.Data) {
+	counts := data.Counts()
+	fmt.Fprintf(os.Stderr, "   Clínicas: %d\n", counts[archive.TypeClinic])
+	fmt.Fprintf(os.Stderr, "   Sedes: %d\n", counts[archive.TypeLocation])
+	fmt.Fprintf(os.Stderr, "   Usuarios: %d\n", counts[archive.TypeUser])
+	fmt.Fprintf(os.Stderr, "   Servicios: %d\n", counts[archive.TypeService])
+	fmt.Fprintf(os.Stderr, "   Doctores: %d\n", counts[archive.TypeDoctor])
+	fmt.Fprintf(os.Stderr, "   Pacientes: %d\n", counts[archive.TypePatient])
+	fmt.Fprintf(os.Stderr, "   Servicios asignados: %d\n", counts[archive.TypeDoctorService])
+	fmt.Fprintf(os.Stderr, "   Doctores por sede: %d\n", counts[archive.TypeDoctorLocation])
+	fmt.Fprintf(os.Stderr, "   Precios por sede: %d\n", counts[archive.TypeServicePrice])
+	fmt.Fprintf(os.Stderr, "   Horarios: %d\n", counts[archive.TypeSchedule])
+	fmt.Fprintf(os.Stderr, "   Citas: %d\n", counts[archive.TypeAppointment])
+}
+
+// repositories are the repositories clinicctl reads and writes through
+type repositories struct {
+	user          repository.UserRepository
+	doctor        repository.DoctorRepository
+	patient       repository.PatientRepository
+	service       repository.ServiceRepository
+	doctorService repository.DoctorServiceRepository
+	schedule      repository.ScheduleRepository
+	appointment   repository.AppointmentRepository
+	clinic        repository.ClinicRepository
+	invitation    repository.InvitationRepository
+	twoFactor     repository.TwoFactorRepository
+	txManager     repository.TxManager
+	cipher        *fieldcrypt.Cipher
+}
+
+// openRepositories connects to the database selected by DATABASE_URL
+// Migrations run only when writing and AUTO_MIGRATE is set, like the API;
+// otherwise the schema must already be current
+func openRepositories(writing bool) (*repositories, func(), error) {
+	cfg := config.LoadDatabaseConfig()
+	autoMigrate := writing && cfg.AutoMigrate
+
+	cipher, err := cfg.FieldCipher()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.DatabaseDriver == config.DriverPostgres {
+		pool, err := postgres.InitDB(cfg.DatabaseDSN, autoMigrate)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &repositories{
+			user:          postgres.NewPostgresUserRepository(pool),
+			doctor:        postgres.NewPostgresDoctorRepository(pool),
+			patient:       postgres.NewPostgresPatientRepository(pool, cipher),
+			service:       postgres.NewPostgresServiceRepository(pool),
+			doctorService: postgres.NewPostgresDoctorServiceRepository(pool),
+			schedule:      postgres.NewPostgresScheduleRepository(pool),
+			appointment:   postgres.NewPostgresAppointmentRepository(pool, cipher),
+			clinic:        postgres.NewPostgresClinicRepository(pool),
+			invitation:    postgres.NewPostgresInvitationRepository(pool),
+			twoFactor:     postgres.NewPostgresTwoFactorRepository(pool, cipher),
+			txManager:     postgres.NewPostgresTxManager(pool),
+			cipher:        cipher,
+		}, pool.Close, nil
+	}
+
+	if cfg.DatabaseDSN == ":memory:" {
+		return nil, nil, fmt.Errorf("sqlite://:memory: vive dentro de la API, no hay datos que exportar o importar")
+	}
+
+	db, err := sqlite.InitDB(cfg.DatabaseDSN, autoMigrate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &repositories{
+		user:          sqlite.NewSqliteUserRepository(db),
+		doctor:        sqlite.NewSqliteDoctorRepository(db),
+		patient:       sqlite.NewSqlitePatientRepository(db, cipher),
+		service:       sqlite.NewSqliteServiceRepository(db),
+		doctorService: sqlite.NewSqliteDoctorServiceRepository(db),
+		schedule:      sqlite.NewSqliteScheduleRepository(db),
+		appointment:   sqlite.NewSqliteAppointmentRepository(db, cipher),
+		clinic:        sqlite.NewSqliteClinicRepository(db),
+		invitation:    sqlite.NewSqliteInvitationRepository(db),
+		twoFactor:     sqlite.NewSqliteTwoFactorRepository(db, cipher),
+		txManager:     sqlite.NewSqliteTxManager(db),
+		cipher:        cipher,
+	}, func() { db.Close() }, nil
+}
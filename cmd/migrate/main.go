@@ -0,0 +1,172 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"version-1-0/internal/repository/migration"
+	"version-1-0/internal/repository/postgres"
+	"version-1-0/internal/repository/sqlite"
+	"version-1-0/pkg/config"
+)
+
+const usage = `Uso: migrate [flags] <comando>
+
+Comandos:
+  status          Muestra las migraciones aplicadas y pendientes
+  up              Aplica todas las migraciones pendientes
+  down N          Revierte las últimas N migraciones (por defecto 1)
+  redo            Revierte y vuelve a aplicar la última migración
+  create <nombre> Crea un par de archivos up/down para cada base de datos
+
+La base de datos se toma de DATABASE_URL (igual que la API).
+
+Flags:
+`
+
+func main() {
+	dir := flag.String("dir", "migrations", "directorio de migraciones usado por create")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create only writes files, it does not need a database
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal("create requiere un nombre, por ejemplo: migrate create add_patient_notes")
+		}
+		paths, err := migration.Create(*dir, args[1])
+		if err != nil {
+			log.Fatalf("Error al crear la migración: %v", err)
+		}
+		for _, path := range paths {
+			fmt.Printf("✓ %s\n", path)
+		}
+		return
+	}
+
+	cfg := config.LoadDatabaseConfig()
+	db, dialect, closeDB, err := openDatabase(cfg)
+	if err != nil {
+		log.Fatalf("Error al conectar con la base de datos: %v", err)
+	}
+	defer closeDB()
+
+	migrator, err := migration.NewMigrator(db, dialect)
+	if err != nil {
+		log.Fatalf("Error al preparar las migraciones: %v", err)
+	}
+
+	switch args[0] {
+	case "status":
+		err = printStatus(migrator)
+	case "up":
+		var count int
+		count, err = migrator.Up()
+		if err == nil {
+			fmt.Printf("✅ %d migración(es) aplicada(s)\n", count)
+		}
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			n, err = strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("down requiere un número positivo, recibido %q", args[1])
+			}
+		}
+		err = migrator.Down(n)
+		if err == nil {
+			fmt.Printf("✅ %d migración(es) revertida(s)\n", n)
+		}
+	case "redo":
+		err = migrator.Redo()
+		if err == nil {
+			fmt.Println("✅ Última migración rehecha")
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		closeDB()
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// openDatabase opens the database selected by DATABASE_URL as a *sql.DB for the migrator
+func openDatabase(cfg *config.Config) (*sql.DB, migration.Dialect, func(), error) {
+	if cfg.DatabaseDriver == config.DriverPostgres {
+		pool, err := postgres.Open(cfg.DatabaseDSN)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		db := stdlib.OpenDBFromPool(pool)
+		return db, migration.Postgres, func() {
+			db.Close()
+			pool.Close()
+		}, nil
+	}
+
+	if cfg.DatabaseDSN == ":memory:" {
+		return nil, nil, nil, fmt.Errorf("sqlite://:memory: se migra al arrancar la API, no hay nada que gestionar")
+	}
+
+	db, err := sqlite.Open(cfg.DatabaseDSN)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return db, migration.SQLite, func() { db.Close() }, nil
+}
+
+// printStatus prints one line per migration with its state
+func printStatus(migrator *migration.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, s := range statuses {
+		state := "pendiente"
+		var notes []string
+		if s.Applied {
+			state = "aplicada"
+			if s.AppliedAt != nil {
+				state += " " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+		} else {
+			pending++
+		}
+		if s.Modified {
+			notes = append(notes, "MODIFICADA después de aplicarse")
+		}
+		if s.Missing {
+			notes = append(notes, "sin archivo en esta versión")
+		}
+
+		line := fmt.Sprintf("%04d  %-35s %s", s.Version, s.Name, state)
+		if len(notes) > 0 {
+			line += "  ⚠️  " + strings.Join(notes, ", ")
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("\n%d migración(es), %d pendiente(s)\n", len(statuses), pending)
+	return nil
+}
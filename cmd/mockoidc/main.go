@@ -0,0 +1,320 @@
+// Command mockoidc is an OpenID Connect provider for development and testing
+//
+// It signs in whoever types an email, with the groups they type, so the single
+// sign-on of staff can be tried without a real identity provider. It keeps
+// everything in memory and creates a new signing key each time it starts.
+// Never expose it outside a development machine
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"version-1-0/pkg/jwtkeys"
+	"version-1-0/pkg/oidc"
+)
+
+// keyID names the signing key in the kid header of ID tokens
+const keyID = "mock-1"
+
+// Lifetimes of the authorization codes and ID tokens
+const (
+	codeTTL    = time.Minute
+	idTokenTTL = 5 * time.Minute
+)
+
+// grant is what an authorization code stands for until it is exchanged
+type grant struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	subject       string
+	email         string
+	emailVerified bool
+	name          string
+	groups        []string
+	expiresAt     time.Time
+}
+
+// provider is the state of the mock provider
+type provider struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	keys         *jwtkeys.KeySet
+
+	mu     sync.Mutex
+	grants map[string]grant // Keyed by authorization code
+}
+
+func main() {
+	port := flag.String("port", "9400", "puerto HTTP")
+	issuer := flag.String("issuer", "", "issuer de los ID tokens (por defecto http://localhost:<puerto>)")
+	clientID := flag.String("client-id", "clinica-api", "client ID aceptado")
+	clientSecret := flag.String("client-secret", "", "client secret exigido en /token (vacío para clientes públicos)")
+	flag.Parse()
+
+	if *issuer == "" {
+		*issuer = "http://localhost:" + *port
+	}
+
+	pemKey, err := jwtkeys.GenerateKey(jwtkeys.AlgorithmRS256)
+	if err != nil {
+		log.Fatalf("Error generando la clave: %v", err)
+	}
+	key, err := jwtkeys.ParseKey(keyID, pemKey)
+	if err != nil {
+		log.Fatalf("Error leyendo la clave: %v", err)
+	}
+	keys, err := jwtkeys.New([]*jwtkeys.Key{key}, keyID)
+	if err != nil {
+		log.Fatalf("Error creando las claves: %v", err)
+	}
+
+	p := &provider{
+		issuer:       strings.TrimSuffix(*issuer, "/"),
+		clientID:     *clientID,
+		clientSecret: *clientSecret,
+		keys:         keys,
+		grants:       make(map[string]grant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("GET /authorize", p.authorize)
+	mux.HandleFunc("POST /token", p.token)
+	mux.HandleFunc("GET /jwks", p.jwks)
+
+	fmt.Printf("🔑 Proveedor OpenID Connect de prueba en %s\n", p.issuer)
+	fmt.Printf("   OIDC_ISSUER=%s OIDC_CLIENT_ID=%s\n", p.issuer, p.clientID)
+	fmt.Println("   Inicia sesión cualquier email, con los grupos que se escriban; solo para desarrollo")
+	log.Fatal(http.ListenAndServe(":"+*port, mux))
+}
+
+// discovery serves the provider metadata
+func (p *provider) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.issuer + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{jwtkeys.AlgorithmRS256},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+	})
+}
+
+// loginPage asks who signs in; the parameters of the request are kept hidden
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="es">
+<head><meta charset="utf-8"><title>Proveedor de prueba</title></head>
+<body>
+<h1>Proveedor OpenID Connect de prueba</h1>
+<p>Inicia sesión cualquier email. Solo para desarrollo.</p>
+<form method="get" action="/authorize">
+{{range $name, $values := .Params}}{{range $values}}<input type="hidden" name="{{$name}}" value="{{.}}">
+{{end}}{{end}}<p><label>Email <input type="email" name="login_hint" required autofocus></label></p>
+<p><label>Nombre <input type="text" name="name"></label></p>
+<p><label>Grupos (separados por comas) <input type="text" name="groups" value="{{.Groups}}"></label></p>
+<p><label><input type="checkbox" name="email_verified" value="true" checked> Email verificado</label></p>
+<p><button type="submit">Iniciar sesión</button></p>
+</form>
+</body>
+</html>
+`))
+
+// authorize signs a person in and sends them back with an authorization code
+// Without login_hint it shows a form asking for the email and groups; scripts
+// skip it by adding login_hint, groups, name, sub or email_verified=false
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if query.Get("client_id") != p.clientID || redirectURI == "" {
+		http.Error(w, "unknown client_id or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" {
+		redirectError(w, r, target, query.Get("state"), "unsupported_response_type")
+		return
+	}
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		redirectError(w, r, target, query.Get("state"), "invalid_request")
+		return
+	}
+
+	email := strings.TrimSpace(query.Get("login_hint"))
+	if email == "" {
+		params := url.Values{}
+		for name, values := range query {
+			if name != "groups" {
+				params[name] = values
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		loginPage.Execute(w, map[string]interface{}{"Params": params, "Groups": query.Get("groups")})
+		return
+	}
+
+	subject := query.Get("sub")
+	if subject == "" {
+		// The same email always gets the same subject, like a real account
+		sum := sha256.Sum256([]byte(strings.ToLower(email)))
+		subject = "mock-" + hex.EncodeToString(sum[:8])
+	}
+	var groups []string
+	for _, group := range strings.Split(query.Get("groups"), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+
+	code := randomString()
+	p.mu.Lock()
+	p.grants[code] = grant{
+		clientID:      p.clientID,
+		redirectURI:   redirectURI,
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		subject:       subject,
+		email:         email,
+		emailVerified: query.Get("email_verified") != "false",
+		name:          query.Get("name"),
+		groups:        groups,
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	p.mu.Unlock()
+
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// token exchanges an authorization code for an ID token
+// Codes work once and only with the verifier of their PKCE challenge
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	clientID, clientSecret, basic := r.BasicAuth()
+	if basic {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(p.clientSecret)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Basic")
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	g, ok := p.grants[code]
+	delete(p.grants, code)
+	p.mu.Unlock()
+
+	if !ok || time.Now().After(g.expiresAt) || g.redirectURI != r.PostForm.Get("redirect_uri") ||
+		oidc.Challenge(r.PostForm.Get("code_verifier")) != g.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.issuer,
+		"sub":            g.subject,
+		"aud":            g.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(idTokenTTL).Unix(),
+		"email":          g.email,
+		"email_verified": g.emailVerified,
+		"groups":         g.groups,
+	}
+	if g.nonce != "" {
+		claims["nonce"] = g.nonce
+	}
+	if g.name != "" {
+		claims["name"] = g.name
+	}
+	idToken, err := p.keys.Sign(claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   int(idTokenTTL.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+// jwks publishes the key that signs ID tokens
+func (p *provider) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, p.keys.JWKS())
+}
+
+// redirectError sends the person back with an OAuth error
+func redirectError(w http.ResponseWriter, r *http.Request, target *url.URL, state, code string) {
+	params := target.Query()
+	params.Set("error", code)
+	params.Set("state", state)
+	target.RawQuery = params.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// tokenError answers the token endpoint with an OAuth error
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// randomString returns 32 random bytes for codes and access tokens
+func randomString() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Error generando un valor aleatorio: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,131 @@
+package main
+
+// serviceSpec describes a service the demo clinic offers
+type serviceSpec struct {
+	Name        string
+	Description string
+	Duration    int // minutes
+	Price       float64
+}
+
+// specialtySpec describes a specialty with its services and typical visit reasons
+type specialtySpec struct {
+	Name     string
+	BaseFee  float64
+	Services []serviceSpec
+	Reasons  []string
+}
+
+// specialties is the catalog of the demo clinic
+var specialties = []specialtySpec{
+	{
+		Name:    "Medicina General",
+		BaseFee: 70,
+		Services: []serviceSpec{
+			{"Consulta General", "Evaluación clínica general y orientación diagnóstica", 20, 70},
+			{"Chequeo Preventivo", "Examen físico completo con revisión de análisis", 45, 180},
+		},
+		Reasons: []string{"Dolor de cabeza persistente", "Fiebre y malestar general", "Control anual", "Dolor de garganta", "Certificado médico"},
+	},
+	{
+		Name:    "Cardiología",
+		BaseFee: 150,
+		Services: []serviceSpec{
+			{"Consulta Cardiológica", "Evaluación del sistema cardiovascular", 30, 150},
+			{"Electrocardiograma", "Registro de la actividad eléctrica del corazón", 20, 80},
+			{"Ecocardiograma", "Ecografía del corazón con Doppler", 45, 250},
+		},
+		Reasons: []string{"Palpitaciones", "Control de presión arterial", "Dolor en el pecho al hacer ejercicio", "Evaluación prequirúrgica"},
+	},
+	{
+		Name:    "Pediatría",
+		BaseFee: 100,
+		Services: []serviceSpec{
+			{"Consulta Pediátrica", "Atención médica para niños y adolescentes", 30, 100},
+			{"Control de Niño Sano", "Control de crecimiento, desarrollo y vacunas", 30, 90},
+		},
+		Reasons: []string{"Control de crecimiento", "Tos y congestión", "Vacunación", "Fiebre en el niño"},
+	},
+	{
+		Name:    "Dermatología",
+		BaseFee: 120,
+		Services: []serviceSpec{
+			{"Consulta Dermatológica", "Evaluación de piel, cabello y uñas", 30, 120},
+			{"Crioterapia", "Tratamiento de lesiones cutáneas con frío", 20, 150},
+		},
+		Reasons: []string{"Acné", "Revisión de lunares", "Dermatitis", "Caída del cabello"},
+	},
+	{
+		Name:    "Ginecología",
+		BaseFee: 130,
+		Services: []serviceSpec{
+			{"Consulta Ginecológica", "Control ginecológico y salud reproductiva", 30, 130},
+			{"Ecografía Transvaginal", "Ecografía ginecológica", 30, 180},
+		},
+		Reasons: []string{"Control anual", "Dolor pélvico", "Control prenatal", "Planificación familiar"},
+	},
+	{
+		Name:    "Traumatología",
+		BaseFee: 130,
+		Services: []serviceSpec{
+			{"Consulta Traumatológica", "Evaluación de huesos, músculos y articulaciones", 30, 130},
+			{"Infiltración Articular", "Aplicación de medicamento en la articulación", 20, 160},
+		},
+		Reasons: []string{"Dolor de rodilla", "Esguince de tobillo", "Dolor lumbar", "Control post fractura"},
+	},
+	{
+		Name:    "Oftalmología",
+		BaseFee: 110,
+		Services: []serviceSpec{
+			{"Consulta Oftalmológica", "Evaluación de la salud visual", 30, 110},
+			{"Fondo de Ojo", "Examen de retina y nervio óptico", 20, 90},
+		},
+		Reasons: []string{"Visión borrosa", "Medida de lentes", "Ojo rojo", "Control de presión ocular"},
+	},
+}
+
+var firstNames = []string{
+	"Carlos", "María", "José", "Lucía", "Jorge", "Ana", "Luis", "Rosa", "Miguel", "Carmen",
+	"Juan", "Sofía", "Pedro", "Valeria", "Ricardo", "Daniela", "Fernando", "Camila", "Andrés", "Patricia",
+	"Diego", "Gabriela", "Raúl", "Elena", "Martín", "Isabel", "Javier", "Claudia", "Álvaro", "Mónica",
+}
+
+var lastNames = []string{
+	"García", "Rodríguez", "Quispe", "Flores", "Sánchez", "Ramírez", "Torres", "Mendoza", "Chávez", "Vargas",
+	"Castillo", "Rojas", "Huamán", "Díaz", "Gutiérrez", "Pérez", "Romero", "Núñez", "Salazar", "Córdova",
+}
+
+var universities = []string{
+	"Universidad Nacional Mayor de San Marcos",
+	"Universidad Peruana Cayetano Heredia",
+	"Universidad de San Martín de Porres",
+	"Pontificia Universidad Católica del Perú",
+	"Universidad Nacional de Trujillo",
+}
+
+var completionNotes = []string{
+	"Paciente estable. Se indica tratamiento y control en un mes.",
+	"Se solicitan análisis de laboratorio. Volver con resultados.",
+	"Evolución favorable, se da de alta.",
+	"Se ajusta la medicación. Control en dos semanas.",
+}
+
+var cancellationReasons = []string{
+	"El paciente no puede asistir",
+	"Viaje imprevisto",
+	"Reprogramará más adelante",
+}
+
+// branchSpec describes a location the demo clinic opens next to the default one
+type branchSpec struct {
+	Name    string
+	Address string
+	Phone   string
+	Markup  float64 // Multiplies the catalog price, 1 keeps it
+}
+
+// branches are the extra locations of the demo clinic
+var branches = []branchSpec{
+	{Name: "Sede San Borja", Address: "Av. Guardia Civil 385, San Borja", Phone: "014760000", Markup: 1},
+	{Name: "Sede Miraflores", Address: "Av. José Pardo 1020, Miraflores", Phone: "014450000", Markup: 1.15},
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/postgres"
+	"version-1-0/internal/repository/sqlite"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/pkg/config"
+)
+
+const usage = `Uso: seed [flags]
+
+Crea una clínica de demostración en una base de datos vacía: sedes con
+precios propios, especialidades, servicios con duración y precio, doctores
+con horarios semanales, pacientes y citas pasadas y futuras en todos los
+estados.
+
+La base de datos se toma de DATABASE_URL (igual que la API) y las
+migraciones pendientes se aplican si AUTO_MIGRATE=true. Las citas se
+reparten alrededor de --anchor-date. Con el mismo --seed y la misma
+--anchor-date se obtienen los mismos datos e IDs; solo cambian las fechas
+de creación de los registros y los hashes de las contraseñas. La fecha no
+puede ser anterior a hoy, porque las citas posteriores a ella se reservan
+con los casos de uso, que no aceptan fechas pasadas.
+
+Flags:
+`
+
+func main() {
+	log.SetFlags(0)
+
+	var opts Options
+	var anchorDate string
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flags.PrintDefaults()
+	}
+	flags.Int64Var(&opts.Seed, "seed", 1, "semilla del generador, el mismo valor produce los mismos datos")
+	flags.StringVar(&anchorDate, "anchor-date", time.Now().UTC().Format("2006-01-02"), "fecha (YYYY-MM-DD) alrededor de la que se reparten las citas")
+	flags.IntVar(&opts.Doctors, "doctors", 12, "cantidad de doctores")
+	flags.IntVar(&opts.Patients, "patients", 40, "cantidad de pacientes")
+	flags.IntVar(&opts.DaysBack, "days-back", 60, "días de historial de citas")
+	flags.IntVar(&opts.DaysAhead, "days-ahead", 30, "días de citas futuras")
+	flags.StringVar(&opts.Password, "password", "demo12345", "contraseña de todas las cuentas creadas")
+	flags.Parse(os.Args[1:])
+
+	date, err := time.Parse("2006-01-02", anchorDate)
+	if err != nil {
+		log.Fatalf("Error: --anchor-date debe tener el formato YYYY-MM-DD")
+	}
+	opts.AnchorDate = date
+
+	if err := run(opts); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// run validates the options, opens the database and seeds it
+func run(opts Options) error {
+	if opts.Doctors < 1 || opts.Patients < 0 || opts.DaysBack < 0 || opts.DaysAhead < 0 {
+		return fmt.Errorf("se necesita al menos un doctor y los demás valores no pueden ser negativos")
+	}
+	if len(opts.Password) < 8 {
+		return fmt.Errorf("la contraseña debe tener al menos 8 caracteres")
+	}
+	if opts.AnchorDate.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		return fmt.Errorf("--anchor-date no puede ser anterior a hoy")
+	}
+
+	repos, closeDB, err := openRepositories()
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	started := time.Now()
+	summary, err := NewSeeder(repos, opts).Run(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "✅ Clínica de demostración creada en %s (seed %d, fecha %s)\n", time.Since(started).Round(time.Millisecond), opts.Seed, opts.AnchorDate.Format("2006-01-02"))
+	fmt.Fprintf(os.Stderr, "   Sedes: %d\n", summary.Locations)
+	fmt.Fprintf(os.Stderr, "   Servicios: %d\n", summary.Services)
+	fmt.Fprintf(os.Stderr, "   Doctores: %d\n", summary.Doctors)
+	fmt.Fprintf(os.Stderr, "   Pacientes: %d\n", summary.Patients)
+	fmt.Fprintf(os.Stderr, "   Horarios: %d\n", summary.Schedules)
+	fmt.Fprintf(os.Stderr, "   Citas: %d pendientes, %d confirmadas, %d completadas, %d canceladas\n",
+		summary.Appointments[domain.StatusPending],
+		summary.Appointments[domain.StatusConfirmed],
+		summary.Appointments[domain.StatusCompleted],
+		summary.Appointments[domain.StatusCancelled])
+	fmt.Fprintf(os.Stderr, "   Administrador: admin.clinica@%s / %s\n", emailDomain, opts.Password)
+	return nil
+}
+
+// repositories are the repositories and shared services the seeder works through
+type repositories struct {
+	user          repository.UserRepository
+	doctor        repository.DoctorRepository
+	patient       repository.PatientRepository
+	service       repository.ServiceRepository
+	doctorService repository.DoctorServiceRepository
+	schedule      repository.ScheduleRepository
+	appointment   repository.AppointmentRepository
+	clinic        repository.ClinicRepository
+	role          repository.RoleRepository
+	txManager     repository.TxManager
+	recorder      *audit.Recorder
+}
+
+// openRepositories connects to the database selected by DATABASE_URL
+func openRepositories() (*repositories, func(), error) {
+	cfg := config.LoadDatabaseConfig()
+
+	cipher, err := cfg.FieldCipher()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.DatabaseDriver == config.DriverPostgres {
+		pool, err := postgres.InitDB(cfg.DatabaseDSN, cfg.AutoMigrate)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &repositories{
+			user:          postgres.NewPostgresUserRepository(pool),
+			doctor:        postgres.NewPostgresDoctorRepository(pool),
+			patient:       postgres.NewPostgresPatientRepository(pool, cipher),
+			service:       postgres.NewPostgresServiceRepository(pool),
+			doctorService: postgres.NewPostgresDoctorServiceRepository(pool),
+			schedule:      postgres.NewPostgresScheduleRepository(pool),
+			appointment:   postgres.NewPostgresAppointmentRepository(pool, cipher),
+			clinic:        postgres.NewPostgresClinicRepository(pool),
+			role:          postgres.NewPostgresRoleRepository(pool),
+			txManager:     postgres.NewPostgresTxManager(pool),
+			recorder:      audit.NewRecorder(postgres.NewPostgresAuditRepository(pool)),
+		}, pool.Close, nil
+	}
+
+	if cfg.DatabaseDSN == ":memory:" {
+		return nil, nil, fmt.Errorf("sqlite://:memory: vive dentro de la API, use un archivo o PostgreSQL")
+	}
+
+	db, err := sqlite.InitDB(cfg.DatabaseDSN, cfg.AutoMigrate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &repositories{
+		user:          sqlite.NewSqliteUserRepository(db),
+		doctor:        sqlite.NewSqliteDoctorRepository(db),
+		patient:       sqlite.NewSqlitePatientRepository(db, cipher),
+		service:       sqlite.NewSqliteServiceRepository(db),
+		doctorService: sqlite.NewSqliteDoctorServiceRepository(db),
+		schedule:      sqlite.NewSqliteScheduleRepository(db),
+		appointment:   sqlite.NewSqliteAppointmentRepository(db, cipher),
+		clinic:        sqlite.NewSqliteClinicRepository(db),
+		role:          sqlite.NewSqliteRoleRepository(db),
+		txManager:     sqlite.NewSqliteTxManager(db),
+		recorder:      audit.NewRecorder(sqlite.NewSqliteAuditRepository(db)),
+	}, func() { db.Close() }, nil
+}
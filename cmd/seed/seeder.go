@@ -0,0 +1,547 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/appointment"
+	"version-1-0/internal/usecase/location"
+	"version-1-0/internal/usecase/role"
+	"version-1-0/internal/usecase/schedule"
+	"version-1-0/internal/usecase/service"
+	"version-1-0/internal/usecase/user"
+	"version-1-0/pkg/textsearch"
+)
+
+// emailDomain is the domain of every demo account
+const emailDomain = "clinica.demo"
+
+// Options control the size and shape of the demo clinic
+type Options struct {
+	Seed       int64
+	AnchorDate time.Time // Day the appointments are spread around, at midnight UTC
+	Doctors    int
+	Patients   int
+	DaysBack   int
+	DaysAhead  int
+	Password   string
+}
+
+// Summary counts what a run created
+type Summary struct {
+	Locations    int
+	Services     int
+	Doctors      int
+	Patients     int
+	Schedules    int
+	Appointments map[domain.AppointmentStatus]int
+}
+
+// Seeder builds a demo clinic through the same use cases the API uses
+type Seeder struct {
+	repos *repositories
+	rng   *rand.Rand
+	opts  Options
+
+	createUser        *user.CreateUserUseCase
+	createLocation    *location.CreateLocationUseCase
+	assignLocation    *location.AssignDoctorUseCase
+	setPrice          *location.SetServicePriceUseCase
+	createService     *service.CreateServiceUseCase
+	assignService     *service.AssignServiceToDoctorUseCase
+	createSchedule    *schedule.CreateScheduleUseCase
+	availableSlots    *service.GetAvailableSlotsUseCase
+	createAppointment *appointment.CreateAppointmentUseCase
+	confirm           *appointment.ConfirmAppointmentUseCase
+	cancel            *appointment.CancelAppointmentUseCase
+
+	adminID   string
+	emails    map[string]int
+	summary   Summary
+	services  map[string][]*domain.Service // by specialty
+	locations []string                     // default location first
+}
+
+// demoDoctor is a doctor account created by the seeder
+type demoDoctor struct {
+	userID    string
+	specialty specialtySpec
+	services  []*domain.Service
+	locations []string // home location first
+}
+
+// NewSeeder creates a new instance of Seeder
+// Emails are never sent: the use cases skip them when no email service is given
+func NewSeeder(repos *repositories, opts Options) *Seeder {
+	authorizer := role.NewAuthorizer(repos.role)
+
+	return &Seeder{
+		repos: repos,
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+		opts:  opts,
+
+		createUser:        user.NewCreateUserUseCase(repos.user, repos.doctor, repos.patient, repos.clinic, repos.txManager, repos.recorder, nil),
+		createLocation:    location.NewCreateLocationUseCase(repos.clinic, repos.txManager, repos.recorder),
+		assignLocation:    location.NewAssignDoctorUseCase(repos.clinic, repos.user, repos.txManager, repos.recorder),
+		setPrice:          location.NewSetServicePriceUseCase(repos.clinic, repos.service, repos.txManager, repos.recorder),
+		createService:     service.NewCreateServiceUseCase(repos.service, repos.txManager, repos.recorder),
+		assignService:     service.NewAssignServiceToDoctorUseCase(repos.doctorService, repos.service, repos.user, repos.txManager, repos.recorder),
+		createSchedule:    schedule.NewCreateScheduleUseCase(repos.schedule, repos.user, repos.clinic, repos.txManager, repos.recorder),
+		availableSlots:    service.NewGetAvailableSlotsUseCase(repos.service, repos.appointment, repos.user, repos.schedule),
+		createAppointment: appointment.NewCreateAppointmentUseCase(repos.appointment, repos.user, repos.service, repos.doctorService, repos.schedule, repos.clinic, repos.txManager, repos.recorder, authorizer, nil),
+		confirm:           appointment.NewConfirmAppointmentUseCase(repos.appointment, repos.user, repos.clinic, repos.txManager, repos.recorder, authorizer, nil),
+		cancel:            appointment.NewCancelAppointmentUseCase(repos.appointment, repos.user, repos.clinic, repos.txManager, repos.recorder, authorizer, nil),
+
+		emails:   make(map[string]int),
+		summary:  Summary{Appointments: make(map[domain.AppointmentStatus]int)},
+		services: make(map[string][]*domain.Service),
+	}
+}
+
+// Run creates the whole clinic: admin, locations, services, doctors with
+// schedules, patients and appointments around the anchor date
+// While it runs, every ID, including those the use cases create, is drawn
+// from the seeded generator, so a seed gives the same IDs every time
+func (s *Seeder) Run(ctx context.Context) (*Summary, error) {
+	uuid.SetRand(s.rng)
+	defer uuid.SetRand(nil)
+
+	existing, err := s.repos.user.List(ctx, repository.PageRequest{Sort: repository.SortCreatedAt, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("la base de datos ya tiene usuarios, el seeder solo trabaja sobre una base vacía")
+	}
+
+	admin, err := s.newUser(ctx, "Admin", "Clínica", domain.RoleAdmin, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating admin: %w", err)
+	}
+	s.adminID = admin
+
+	if err := s.seedLocations(ctx); err != nil {
+		return nil, err
+	}
+	if err := s.seedServices(ctx); err != nil {
+		return nil, err
+	}
+	doctors, err := s.seedDoctors(ctx)
+	if err != nil {
+		return nil, err
+	}
+	patients, err := s.seedPatients(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.seedAppointments(ctx, doctors, patients); err != nil {
+		return nil, err
+	}
+
+	return &s.summary, nil
+}
+
+// seedLocations opens the branches next to the location every clinic starts with
+func (s *Seeder) seedLocations(ctx context.Context) error {
+	existing, err := s.repos.clinic.ListLocations(ctx, true)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("la clínica no tiene sedes activas")
+	}
+	s.locations = append(s.locations, existing[0].ID)
+
+	for _, branch := range branches {
+		created, err := s.createLocation.Execute(ctx, location.CreateLocationRequest{
+			Name:    branch.Name,
+			Address: branch.Address,
+			Phone:   branch.Phone,
+		})
+		if err != nil {
+			return fmt.Errorf("creating location %q: %w", branch.Name, err)
+		}
+		s.locations = append(s.locations, created.ID)
+	}
+
+	s.summary.Locations = len(s.locations)
+	return nil
+}
+
+// seedServices creates every service of the catalog and prices it at the
+// branches that charge more than the catalog
+func (s *Seeder) seedServices(ctx context.Context) error {
+	for _, specialty := range specialties {
+		for _, spec := range specialty.Services {
+			created, err := s.createService.Execute(ctx, service.CreateServiceRequest{
+				Name:            spec.Name,
+				Description:     spec.Description,
+				DurationMinutes: spec.Duration,
+				Price:           spec.Price,
+			})
+			if err != nil {
+				return fmt.Errorf("creating service %q: %w", spec.Name, err)
+			}
+			s.services[specialty.Name] = append(s.services[specialty.Name], &domain.Service{
+				ID:              created.ID,
+				Name:            spec.Name,
+				DurationMinutes: spec.Duration,
+				Price:           spec.Price,
+			})
+			s.summary.Services++
+
+			for i, branch := range branches {
+				if branch.Markup == 1 {
+					continue
+				}
+				price := location.SetServicePriceRequest{Price: math.Round(spec.Price*branch.Markup/5) * 5}
+				if _, err := s.setPrice.Execute(ctx, s.locations[i+1], created.ID, price); err != nil {
+					return fmt.Errorf("pricing service %q: %w", spec.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// seedDoctors creates the doctors, assigns them the services of their
+// specialty and gives them a weekly schedule
+// Specialties and home locations are dealt round robin so every one has at
+// least one doctor as soon as there are enough doctors; a third of the
+// doctors also see patients at a second location on Saturdays
+func (s *Seeder) seedDoctors(ctx context.Context) ([]*demoDoctor, error) {
+	doctors := make([]*demoDoctor, 0, s.opts.Doctors)
+
+	for i := 0; i < s.opts.Doctors; i++ {
+		specialty := specialties[i%len(specialties)]
+		years := 3 + s.rng.Intn(28)
+		home := s.locations[i%len(s.locations)]
+		profile := &user.CreateUserRequest{
+			LocationID:        home,
+			Specialty:         specialty.Name,
+			ConsultationFee:   specialty.BaseFee + float64(s.rng.Intn(5)*10),
+			YearsOfExperience: years,
+			Education:         "Médico Cirujano, " + universities[s.rng.Intn(len(universities))],
+			Bio:               fmt.Sprintf("Especialista en %s con %d años de experiencia.", specialty.Name, years),
+		}
+
+		firstName, lastName := s.randomName()
+		userID, err := s.newUser(ctx, firstName, lastName, domain.RoleDoctor, profile)
+		if err != nil {
+			return nil, fmt.Errorf("creating doctor: %w", err)
+		}
+
+		doctor := &demoDoctor{userID: userID, specialty: specialty, services: s.services[specialty.Name], locations: []string{home}}
+		if s.rng.Intn(3) == 0 {
+			other := s.locations[(i+1+s.rng.Intn(len(s.locations)-1))%len(s.locations)]
+			if _, err := s.assignLocation.Execute(ctx, other, userID); err != nil {
+				return nil, fmt.Errorf("assigning location: %w", err)
+			}
+			doctor.locations = append(doctor.locations, other)
+		}
+		for _, svc := range doctor.services {
+			if err := s.assignService.Execute(ctx, userID, svc.ID); err != nil {
+				return nil, fmt.Errorf("assigning service %q: %w", svc.Name, err)
+			}
+		}
+		if err := s.seedSchedule(ctx, doctor); err != nil {
+			return nil, err
+		}
+
+		doctors = append(doctors, doctor)
+		s.summary.Doctors++
+	}
+
+	return doctors, nil
+}
+
+// seedSchedule gives a doctor four or five weekdays at their home location,
+// each in the morning, the afternoon or both, and sometimes a Saturday
+// morning, at their second location when they have one
+func (s *Seeder) seedSchedule(ctx context.Context, doctor *demoDoctor) error {
+	weekdays := []string{domain.Monday, domain.Tuesday, domain.Wednesday, domain.Thursday, domain.Friday}
+	workdays := 4 + s.rng.Intn(2)
+
+	type block struct{ start, end string }
+	var days []string
+	var blocks [][]block
+	for _, i := range s.rng.Perm(len(weekdays))[:workdays] {
+		days = append(days, weekdays[i])
+		switch s.rng.Intn(3) {
+		case 0:
+			blocks = append(blocks, []block{{"08:00", "13:00"}})
+		case 1:
+			blocks = append(blocks, []block{{"14:00", "19:00"}})
+		default:
+			blocks = append(blocks, []block{{"09:00", "13:00"}, {"15:00", "18:00"}})
+		}
+	}
+	if len(doctor.locations) > 1 || s.rng.Intn(3) == 0 {
+		days = append(days, domain.Saturday)
+		blocks = append(blocks, []block{{"08:00", "12:00"}})
+	}
+
+	for i, day := range days {
+		locationID := doctor.locations[0]
+		if day == domain.Saturday {
+			locationID = doctor.locations[len(doctor.locations)-1]
+		}
+		for _, b := range blocks[i] {
+			if _, err := s.createSchedule.Execute(ctx, doctor.userID, locationID, day, b.start, b.end, 30); err != nil {
+				return fmt.Errorf("creating schedule: %w", err)
+			}
+			s.summary.Schedules++
+		}
+	}
+	return nil
+}
+
+// seedPatients creates the patients and fills in the profile the API
+// creates with placeholders
+// There is no use case for the patient profile yet, so it is updated
+// through the repository
+func (s *Seeder) seedPatients(ctx context.Context) ([]string, error) {
+	bloodTypes := []string{"O+", "O+", "O+", "A+", "A+", "B+", "O-", "AB+"}
+	allergies := []string{"Penicilina", "Polen", "Mariscos", "Ácaros"}
+	districts := []string{"Miraflores", "San Isidro", "Surco", "San Borja", "Jesús María", "Lince"}
+
+	patients := make([]string, 0, s.opts.Patients)
+	for i := 0; i < s.opts.Patients; i++ {
+		firstName, lastName := s.randomName()
+		userID, err := s.newUser(ctx, firstName, lastName, domain.RolePatient, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating patient: %w", err)
+		}
+
+		patient, err := s.repos.patient.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		contactFirst, contactLast := s.randomName()
+		patient.Birthdate = time.Date(1950+s.rng.Intn(60), time.Month(1+s.rng.Intn(12)), 1+s.rng.Intn(28), 0, 0, 0, 0, time.UTC)
+		patient.DocumentNumber = fmt.Sprintf("%08d", 10000000+s.rng.Intn(80000000))
+		patient.Address = fmt.Sprintf("Av. %s %d, %s", lastNames[s.rng.Intn(len(lastNames))], 100+s.rng.Intn(1900), districts[s.rng.Intn(len(districts))])
+		patient.EmergencyContactName = contactFirst + " " + contactLast
+		patient.EmergencyContactPhone = s.randomPhone()
+		patient.BloodType = bloodTypes[s.rng.Intn(len(bloodTypes))]
+		if s.rng.Intn(4) == 0 {
+			patient.Allergies = []string{allergies[s.rng.Intn(len(allergies))]}
+		}
+		patient.UpdatedAt = time.Now()
+		if err := patient.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.repos.patient.Update(ctx, patient); err != nil {
+			return nil, err
+		}
+
+		patients = append(patients, userID)
+		s.summary.Patients++
+	}
+
+	return patients, nil
+}
+
+// seedAppointments books up to three appointments per doctor and working
+// day between DaysBack days before the anchor date and DaysAhead days after it
+// The anchor date itself is skipped so the same seed gives the same data whatever the hour
+func (s *Seeder) seedAppointments(ctx context.Context, doctors []*demoDoctor, patients []string) error {
+	if len(patients) == 0 {
+		return nil
+	}
+
+	for offset := -s.opts.DaysBack; offset <= s.opts.DaysAhead; offset++ {
+		if offset == 0 {
+			continue
+		}
+		date := s.opts.AnchorDate.AddDate(0, 0, offset)
+
+		for _, doctor := range doctors {
+			for n := s.rng.Intn(4); n > 0; n-- {
+				svc := doctor.services[s.rng.Intn(len(doctor.services))]
+				slots, err := s.availableSlots.Execute(ctx, doctor.userID, svc.ID, "", date)
+				if err != nil {
+					return fmt.Errorf("getting available slots: %w", err)
+				}
+				var free []service.TimeSlot
+				for _, slot := range slots {
+					if slot.Available {
+						free = append(free, slot)
+					}
+				}
+				if len(free) == 0 {
+					break
+				}
+
+				slot := free[s.rng.Intn(len(free))]
+				slotTime, _ := time.Parse("15:04", slot.Time)
+				scheduledAt := date.Add(time.Duration(slotTime.Hour())*time.Hour + time.Duration(slotTime.Minute())*time.Minute)
+				patientID := patients[s.rng.Intn(len(patients))]
+				reason := doctor.specialty.Reasons[s.rng.Intn(len(doctor.specialty.Reasons))]
+
+				if offset < 0 {
+					err = s.pastAppointment(ctx, doctor, patientID, svc, slot.LocationID, scheduledAt, reason)
+				} else {
+					err = s.futureAppointment(ctx, doctor, patientID, svc, slot.LocationID, scheduledAt, reason, offset)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// futureAppointment books through the create use case and then leaves the
+// appointment pending, confirms it or, when the 24 hour rule allows it,
+// cancels it
+func (s *Seeder) futureAppointment(ctx context.Context, doctor *demoDoctor, patientID string, svc *domain.Service, locationID string, scheduledAt time.Time, reason string, offset int) error {
+	created, err := s.createAppointment.Execute(ctx, patientID, string(domain.RolePatient), "", doctor.userID, svc.ID, locationID, scheduledAt, reason)
+	if err != nil {
+		return fmt.Errorf("creating appointment: %w", err)
+	}
+
+	status := domain.StatusPending
+	switch roll := s.rng.Intn(100); {
+	case roll < 45:
+		if _, err := s.confirm.Execute(ctx, created.ID, s.adminID, string(domain.RoleAdmin), 0); err != nil {
+			return fmt.Errorf("confirming appointment: %w", err)
+		}
+		status = domain.StatusConfirmed
+	case roll < 60 && offset >= 2:
+		req := appointment.CancelAppointmentRequest{Reason: cancellationReasons[s.rng.Intn(len(cancellationReasons))]}
+		if err := s.cancel.Execute(ctx, created.ID, s.adminID, string(domain.RoleAdmin), req, 0); err != nil {
+			return fmt.Errorf("cancelling appointment: %w", err)
+		}
+		status = domain.StatusCancelled
+	}
+
+	s.summary.Appointments[status]++
+	return nil
+}
+
+// pastAppointment stores an appointment that already happened
+// The use cases refuse to book in the past, so history is written straight
+// to the repository, using the slot the available slots use case offered
+func (s *Seeder) pastAppointment(ctx context.Context, doctor *demoDoctor, patientUserID string, svc *domain.Service, locationID string, scheduledAt time.Time, reason string) error {
+	doctorID, err := s.repos.user.FindDoctorIDByUserID(ctx, doctor.userID)
+	if err != nil {
+		return err
+	}
+	patientID, err := s.repos.user.FindPatientIDByUserID(ctx, patientUserID)
+	if err != nil {
+		return err
+	}
+
+	id, err := uuid.NewRandomFromReader(s.rng)
+	if err != nil {
+		return err
+	}
+
+	createdAt := scheduledAt.AddDate(0, 0, -(1 + s.rng.Intn(14)))
+	apt := &domain.Appointment{
+		ID:          id.String(),
+		PatientID:   patientID,
+		DoctorID:    doctorID,
+		ServiceID:   svc.ID,
+		LocationID:  locationID,
+		ScheduledAt: scheduledAt,
+		Duration:    svc.DurationMinutes,
+		Reason:      reason,
+		CreatedAt:   createdAt,
+		UpdatedAt:   scheduledAt.Add(time.Duration(svc.DurationMinutes) * time.Minute),
+		// Reminders went out long ago, don't let the reminder service pick these up
+		Reminder24hSent: true,
+		Reminder1hSent:  true,
+	}
+
+	switch roll := s.rng.Intn(100); {
+	case roll < 75:
+		apt.Status = domain.StatusCompleted
+		apt.Notes = completionNotes[s.rng.Intn(len(completionNotes))]
+	case roll < 92:
+		cancelledAt := scheduledAt.AddDate(0, 0, -1)
+		if cancelledAt.Before(createdAt) {
+			cancelledAt = createdAt
+		}
+		apt.Status = domain.StatusCancelled
+		apt.CancelledAt = &cancelledAt
+		apt.CancellationReason = cancellationReasons[s.rng.Intn(len(cancellationReasons))]
+		apt.UpdatedAt = cancelledAt
+	default:
+		// Confirmed but never closed, like a patient who didn't show up
+		apt.Status = domain.StatusConfirmed
+	}
+
+	if err := s.repos.appointment.Create(ctx, apt); err != nil {
+		return fmt.Errorf("creating past appointment: %w", err)
+	}
+	s.summary.Appointments[apt.Status]++
+	return nil
+}
+
+// newUser creates an account through the create user use case
+// Emails are built from the name, numbered when two people share it
+// Demo addresses receive no mail, so the accounts are verified right away
+func (s *Seeder) newUser(ctx context.Context, firstName, lastName string, role domain.UserRole, profile *user.CreateUserRequest) (string, error) {
+	req := user.CreateUserRequest{}
+	if profile != nil {
+		req = *profile
+	}
+	req.Email = s.email(firstName, lastName)
+	req.Password = s.opts.Password
+	req.FirstName = firstName
+	req.LastName = lastName
+	req.Phone = s.randomPhone()
+	req.Role = string(role)
+
+	created, err := s.createUser.Execute(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	account, err := s.repos.user.FindByID(ctx, created.ID)
+	if err != nil {
+		return "", err
+	}
+	account.EmailVerifiedAt = &account.CreatedAt
+	if err := s.repos.user.Update(ctx, account); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+// email returns a unique address like maria.quispe@clinica.demo
+func (s *Seeder) email(firstName, lastName string) string {
+	first := strings.Join(textsearch.Words(firstName), "")
+	last := textsearch.Words(lastName)[0]
+	local := first + "." + last
+
+	s.emails[local]++
+	if n := s.emails[local]; n > 1 {
+		local = fmt.Sprintf("%s%d", local, n)
+	}
+	return local + "@" + emailDomain
+}
+
+// randomName returns a first name and two last names, as usual in Peru
+func (s *Seeder) randomName() (string, string) {
+	first := firstNames[s.rng.Intn(len(firstNames))]
+	last := lastNames[s.rng.Intn(len(lastNames))] + " " + lastNames[s.rng.Intn(len(lastNames))]
+	return first, last
+}
+
+// randomPhone returns a Peruvian mobile number
+func (s *Seeder) randomPhone() string {
+	return fmt.Sprintf("9%08d", s.rng.Intn(100000000))
+}
@@ -7,9 +7,81 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token     string       `json:"token"`
-	ExpiresAt string       `json:"expires_at"`
-	User      UserResponse `json:"user"`
+	Token            string       `json:"token"`
+	ExpiresAt        string       `json:"expires_at"`
+	RefreshToken     string       `json:"refresh_token"`
+	RefreshExpiresAt string       `json:"refresh_expires_at"`
+	User             UserResponse `json:"user"`
+	RecoveryCodes    []string     `json:"recovery_codes,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"q3Jd9x0mV1c2a8yq3K1t7w5RzE6pL0nB4sT2uY9hA1k"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"paciente@clinica.com"`
+}
+
+type VerifyEmailResponse struct {
+	Email           string `json:"email" example:"paciente@clinica.com"`
+	EmailVerifiedAt string `json:"email_verified_at" example:"2025-01-15T10:30:00Z"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" example:"Zk2w8QmR0pT5vX1yA7cE3gH9jL4nB6dF2sU8qW0eR5t"`
+	NewPassword string `json:"new_password" example:"nuevaClave123"`
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" example:"Zk2w8QmR0pT5vX1yA7cE3gH9jL4nB6dF2sU8qW0eR5t"`
+}
+
+type LockoutResponse struct {
+	ID            string `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Scope         string `json:"scope" example:"account"`
+	Subject       string `json:"subject" example:"paciente@clinica.com"`
+	Failures      int    `json:"failures" example:"5"`
+	LastFailureAt string `json:"last_failure_at" example:"2025-01-15T10:30:00Z"`
+	LockedUntil   string `json:"locked_until" example:"2025-01-15T10:45:00Z"`
+}
+
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	SetupRequired     bool   `json:"setup_required" example:"false"`
+	ChallengeToken    string `json:"challenge_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt         string `json:"expires_at" example:"2025-01-15T10:05:00Z"`
+}
+
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURI string `json:"otpauth_uri" example:"otpauth://totp/Cl%C3%ADnica%20Internacional:admin@clinica.com?algorithm=SHA1&digits=6&issuer=Cl%C3%ADnica+Internacional&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	Code           string `json:"code" example:"123456"`
+	RecoveryCode   string `json:"recovery_code,omitempty" example:"k7m2p-x9qrt"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code" example:"123456"`
+	RecoveryCode string `json:"recovery_code,omitempty" example:"k7m2p-x9qrt"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"k7m2p-x9qrt,a3bc4-def56"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool   `json:"enabled" example:"true"`
+	Required               bool   `json:"required" example:"false"`
+	EnabledAt              string `json:"enabled_at,omitempty" example:"2025-01-15T10:00:00Z"`
+	RecoveryCodesRemaining int    `json:"recovery_codes_remaining" example:"10"`
 }
 
 type UserResponse struct {
@@ -22,20 +94,157 @@ type UserResponse struct {
 
 // User DTOs
 type CreateUserRequest struct {
-	Email     string `json:"email" example:"doctor@clinica.com"`
+	Email     string `json:"email" example:"paciente@clinica.com"`
 	Password  string `json:"password" example:"password123"`
 	FirstName string `json:"first_name" example:"Juan"`
 	LastName  string `json:"last_name" example:"Pérez"`
 	Phone     string `json:"phone,omitempty" example:"+593999999999"`
-	Role      string `json:"role" example:"doctor"`
+	Role      string `json:"role,omitempty" example:"patient"`
+}
+
+// Invitation DTOs
+type CreateInvitationRequest struct {
+	Email string `json:"email" example:"doctor@clinica.com"`
+	Role  string `json:"role" example:"doctor"`
+}
+
+type InvitationResponse struct {
+	ID         string `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Email      string `json:"email" example:"doctor@clinica.com"`
+	Role       string `json:"role" example:"doctor"`
+	Status     string `json:"status" example:"pending"`
+	InvitedBy  string `json:"invited_by,omitempty"`
+	UserID     string `json:"user_id,omitempty"`
+	ExpiresAt  string `json:"expires_at" example:"2025-01-22T10:30:00Z"`
+	CreatedAt  string `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	AcceptedAt string `json:"accepted_at,omitempty"`
+	RevokedAt  string `json:"revoked_at,omitempty"`
+}
+
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" example:"Zk2w8QmR0pT5vX1yA7cE3gH9jL4nB6dF2sU8qW0eR5t"`
+	Password  string `json:"password" example:"password123"`
+	FirstName string `json:"first_name" example:"Juan"`
+	LastName  string `json:"last_name" example:"Pérez"`
+	Phone     string `json:"phone" example:"+593999999999"`
+
+	// Optional doctor profile, ignored for other roles
+	Specialty         string  `json:"specialty,omitempty" example:"Cardiología"`
+	ConsultationFee   float64 `json:"consultation_fee,omitempty" example:"150"`
+	YearsOfExperience int     `json:"years_of_experience,omitempty" example:"10"`
+	Education         string  `json:"education,omitempty" example:"Universidad Nacional Mayor de San Marcos"`
+	Bio               string  `json:"bio,omitempty" example:"Especialista en arritmias"`
+	LocationID        string  `json:"location_id,omitempty"`
+}
+
+// Role DTOs
+type RoleResponse struct {
+	Role        string   `json:"role" example:"receptionist"`
+	Description string   `json:"description" example:"Reserva, reprograma y cancela citas a nombre de los pacientes"`
+	Permissions []string `json:"permissions" example:"appointments:create:any,appointments:read:any"`
+	Default     bool     `json:"default" example:"false"`
+	UpdatedAt   string   `json:"updated_at,omitempty" example:"2025-01-15T10:30:00Z"`
+}
+
+type UpdateRoleRequest struct {
+	Permissions []string `json:"permissions" example:"appointments:create:any,analytics:read"`
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name" example:"analytics:read"`
+	Description string `json:"description" example:"Ver las estadísticas de la clínica"`
+}
+
+// JWKS DTOs
+type JWKSResponse struct {
+	Keys []JWKResponse `json:"keys"`
+}
+
+type JWKResponse struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"20250115-103000"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"`
+	X   string `json:"x,omitempty" example:"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"`
+}
+
+// SSO DTOs
+type SSOStartResponse struct {
+	AuthorizationURL string `json:"authorization_url" example:"https://login.example.com/authorize?response_type=code&client_id=clinica-api&state=eyJhbGc..."`
+	State            string `json:"state" example:"eyJhbGc..."`
+	ExpiresAt        string `json:"expires_at" example:"2025-01-15T10:40:00Z"`
+}
+
+type SSOCallbackRequest struct {
+	Code  string `json:"code" example:"SplxlOBeZQQYbYS6WxSbIA"`
+	State string `json:"state" example:"eyJhbGc..."`
+}
+
+// Session DTOs
+type SessionResponse struct {
+	ID         string `json:"id" example:"3f2b8c1e-4d5a-4b6c-8e7f-9a0b1c2d3e4f"`
+	UserAgent  string `json:"user_agent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15"`
+	IPAddress  string `json:"ip_address" example:"190.12.34.56"`
+	CreatedAt  string `json:"created_at" example:"2025-01-15T10:00:00Z"`
+	LastSeenAt string `json:"last_seen_at" example:"2025-01-15T12:30:00Z"`
+	ExpiresAt  string `json:"expires_at" example:"2025-02-14T12:30:00Z"`
+	Current    bool   `json:"current" example:"true"`
+}
+
+type EndSessionsResponse struct {
+	Message string `json:"message" example:"Sessions have been ended"`
+	Ended   int    `json:"ended" example:"3"`
+}
+
+// API key DTOs
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" example:"Kiosco sede norte"`
+	Scopes    []string `json:"scopes" example:"appointments:create:any,appointments:read:any"`
+	ExpiresAt string   `json:"expires_at,omitempty" example:"2026-01-15T00:00:00Z"`
+}
+
+type APIKeyResponse struct {
+	ID         string   `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Name       string   `json:"name" example:"Kiosco sede norte"`
+	Prefix     string   `json:"prefix" example:"clk_k3x9m2qa"`
+	Scopes     []string `json:"scopes" example:"appointments:create:any,appointments:read:any"`
+	Status     string   `json:"status" example:"active"`
+	CreatedBy  string   `json:"created_by,omitempty"`
+	ExpiresAt  string   `json:"expires_at,omitempty" example:"2026-01-15T00:00:00Z"`
+	LastUsedAt string   `json:"last_used_at,omitempty" example:"2025-01-16T08:12:00Z"`
+	LastUsedIP string   `json:"last_used_ip,omitempty" example:"203.0.113.7"`
+	CreatedAt  string   `json:"created_at" example:"2025-01-15T10:30:00Z"`
+	RevokedAt  string   `json:"revoked_at,omitempty"`
+}
+
+type CreateAPIKeyResponse struct {
+	Key string `json:"key" example:"clk_k3x9m2qa_Zk2w8QmR0pT5vX1yA7cE3gH9jL4nB6dF2sU8qW0eR5t"`
+	APIKeyResponse
+}
+
+type APIKeyUsageResponse struct {
+	KeyID         string           `json:"key_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Since         string           `json:"since" example:"2025-01-01T00:00:00Z"`
+	TotalRequests int              `json:"total_requests" example:"42"`
+	Days          []APIKeyUsageDay `json:"days"`
+}
+
+type APIKeyUsageDay struct {
+	Day      string `json:"day" example:"2025-01-15"`
+	Requests int    `json:"requests" example:"17"`
 }
 
 // Appointment DTOs
 type CreateAppointmentRequest struct {
+	PatientID       string `json:"patient_id,omitempty" example:"uuid"`
 	DoctorID        string `json:"doctor_id" example:"uuid"`
 	ServiceID       string `json:"service_id" example:"uuid"`
 	AppointmentDate string `json:"appointment_date" example:"2025-11-15"`
 	AppointmentTime string `json:"appointment_time" example:"10:00"`
+	LocationID      string `json:"location_id,omitempty" example:"uuid"`
 	Reason          string `json:"reason" example:"Consulta general"`
 }
 
@@ -45,6 +254,7 @@ type AppointmentResponse struct {
 	DoctorID        string `json:"doctor_id"`
 	ServiceID       string `json:"service_id"`
 	ServiceName     string `json:"service_name"`
+	LocationID      string `json:"location_id"`
 	ScheduledAt     string `json:"scheduled_at"`
 	Duration        int    `json:"duration"`
 	Reason          string `json:"reason"`
@@ -75,11 +285,13 @@ type ServiceResponse struct {
 type TimeSlot struct {
 	StartTime string `json:"start_time" example:"09:00"`
 	EndTime   string `json:"end_time" example:"09:30"`
+	LocationID string `json:"location_id" example:"uuid"`
 }
 
 // Schedule DTOs
 type CreateScheduleRequest struct {
 	DoctorID  string      `json:"doctor_id" example:"uuid"`
+	LocationID string     `json:"location_id,omitempty" example:"uuid"`
 	DayOfWeek int         `json:"day_of_week" example:"1"`
 	Blocks    []TimeBlock `json:"blocks"`
 }
@@ -134,3 +346,8 @@ type TopService struct {
 type ErrorResponse struct {
 	Error string `json:"error" example:"Error message"`
 }
+
+// Message response
+type MessageResponse struct {
+	Message string `json:"message" example:"Logged out successfully"`
+}
@@ -33,11 +33,12 @@ func NewAnalyticsHandler(
 
 // GetDashboardSummary godoc
 // @Summary      Dashboard summary
-// @Description  Retorna resumen con KPIs principales del sistema (solo admin)
+// @Description  Retorna resumen con KPIs principales del sistema (permiso analytics:read)
 // @Tags         Analytics
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        location_id  query  string  false  "Restringe las citas e ingresos a una sede"
 // @Success      200  {object}  dto.DashboardSummary
 // @Failure      401  {object}  dto.ErrorResponse
 // @Failure      403  {object}  dto.ErrorResponse
@@ -48,7 +49,7 @@ func (h *AnalyticsHandler) GetDashboardSummary(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	summary, err := h.getDashboardSummary.Execute(r.Context())
+	summary, err := h.getDashboardSummary.Execute(r.Context(), r.URL.Query().Get("location_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -60,11 +61,12 @@ func (h *AnalyticsHandler) GetDashboardSummary(w http.ResponseWriter, r *http.Re
 
 // GetRevenueStats godoc
 // @Summary      Estadísticas de ingresos
-// @Description  Retorna ingresos agrupados por servicio (solo admin)
+// @Description  Retorna ingresos agrupados por servicio (permiso analytics:read)
 // @Tags         Analytics
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        location_id  query  string  false  "Restringe los ingresos a una sede"
 // @Success      200  {array}   dto.RevenueByService
 // @Failure      401  {object}  dto.ErrorResponse
 // @Failure      403  {object}  dto.ErrorResponse
@@ -75,7 +77,7 @@ func (h *AnalyticsHandler) GetRevenueStats(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	stats, err := h.getRevenueStats.Execute(r.Context())
+	stats, err := h.getRevenueStats.Execute(r.Context(), r.URL.Query().Get("location_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -87,12 +89,13 @@ func (h *AnalyticsHandler) GetRevenueStats(w http.ResponseWriter, r *http.Reques
 
 // GetTopDoctors godoc
 // @Summary      Top doctores más solicitados
-// @Description  Retorna ranking de doctores por cantidad de citas (solo admin)
+// @Description  Retorna ranking de doctores por cantidad de citas (permiso analytics:read)
 // @Tags         Analytics
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        limit  query  int  false  "Número de doctores a retornar" default(10)
+// @Param        location_id  query  string  false  "Restringe el ranking a una sede"
 // @Success      200  {array}   dto.TopDoctor
 // @Failure      401  {object}  dto.ErrorResponse
 // @Router       /api/analytics/top-doctors [get]
@@ -110,7 +113,7 @@ func (h *AnalyticsHandler) GetTopDoctors(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	doctors, err := h.getTopDoctors.Execute(r.Context(), limit)
+	doctors, err := h.getTopDoctors.Execute(r.Context(), limit, r.URL.Query().Get("location_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -122,12 +125,13 @@ func (h *AnalyticsHandler) GetTopDoctors(w http.ResponseWriter, r *http.Request)
 
 // GetTopServices godoc
 // @Summary      Top servicios más populares
-// @Description  Retorna ranking de servicios por demanda (solo admin)
+// @Description  Retorna ranking de servicios por demanda (permiso analytics:read)
 // @Tags         Analytics
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        limit  query  int  false  "Número de servicios a retornar" default(10)
+// @Param        location_id  query  string  false  "Restringe el ranking a una sede"
 // @Success      200  {array}   dto.TopService
 // @Failure      401  {object}  dto.ErrorResponse
 // @Router       /api/analytics/top-services [get]
@@ -145,7 +149,7 @@ func (h *AnalyticsHandler) GetTopServices(w http.ResponseWriter, r *http.Request
 		}
 	}
 
-	services, err := h.getTopServices.Execute(r.Context(), limit)
+	services, err := h.getTopServices.Execute(r.Context(), limit, r.URL.Query().Get("location_id"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
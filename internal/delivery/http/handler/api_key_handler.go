@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/apikey"
+)
+
+// defaultUsageDays is the period the usage of a key covers when days is not given
+const defaultUsageDays = 30
+
+// APIKeyHandler handles HTTP requests for the API keys of integrations
+type APIKeyHandler struct {
+	createAPIKeyUC   *apikey.CreateAPIKeyUseCase
+	listAPIKeysUC    *apikey.ListAPIKeysUseCase
+	revokeAPIKeyUC   *apikey.RevokeAPIKeyUseCase
+	getAPIKeyUsageUC *apikey.GetAPIKeyUsageUseCase
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(
+	createAPIKeyUC *apikey.CreateAPIKeyUseCase,
+	listAPIKeysUC *apikey.ListAPIKeysUseCase,
+	revokeAPIKeyUC *apikey.RevokeAPIKeyUseCase,
+	getAPIKeyUsageUC *apikey.GetAPIKeyUsageUseCase,
+) *APIKeyHandler {
+	return &APIKeyHandler{
+		createAPIKeyUC:   createAPIKeyUC,
+		listAPIKeysUC:    listAPIKeysUC,
+		revokeAPIKeyUC:   revokeAPIKeyUC,
+		getAPIKeyUsageUC: getAPIKeyUsageUC,
+	}
+}
+
+// Create godoc
+// @Summary      Crear API key
+// @Description  Crea una API key para una integración con los permisos indicados (scopes). La key se muestra solo en esta respuesta; se envía en el header X-API-Key. Los permisos api_keys:manage, roles:manage, invitations:manage y users:write no se pueden dar a una key, y quien no es administrador solo puede dar permisos que tiene. La key pierde los permisos que el rol de quien la creó deje de tener
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      dto.CreateAPIKeyRequest  true  "Nombre, permisos y vencimiento opcional"
+// @Success      201  {object}  dto.CreateAPIKeyResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Router       /api/api-keys [post]
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	userRole, ok := r.Context().Value(requestctx.RoleKey).(string)
+	if !ok {
+		http.Error(w, "Role not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req apikey.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.createAPIKeyUC.Execute(r.Context(), userID, userRole, req)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+// List godoc
+// @Summary      Listar API keys
+// @Description  Lista las API keys de la clínica, las más recientes primero, con su último uso. Incluye las revocadas y vencidas
+// @Tags         API Keys
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   dto.APIKeyResponse
+// @Router       /api/api-keys [get]
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.listAPIKeysUC.Execute(r.Context())
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(keys)
+}
+
+// Revoke godoc
+// @Summary      Revocar API key
+// @Description  Revoca una API key; las peticiones hechas con ella se rechazan desde ese momento
+// @Tags         API Keys
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID de la API key"
+// @Success      200  {object}  dto.APIKeyResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Router       /api/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	key, err := h.revokeAPIKeyUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(key)
+}
+
+// Usage godoc
+// @Summary      Uso de una API key
+// @Description  Retorna las peticiones hechas con la key por día (UTC) en los últimos días, hoy incluido. Los días sin peticiones no aparecen
+// @Tags         API Keys
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      string  true   "ID de la API key"
+// @Param        days  query     int     false  "Días a incluir, de 1 a 365 (por defecto 30)"
+// @Success      200  {object}  dto.APIKeyUsageResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/api-keys/{id}/usage [get]
+func (h *APIKeyHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	days := defaultUsageDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil {
+			http.Error(w, "days must be a number", http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	usage, err := h.getAPIKeyUsageUC.Execute(r.Context(), r.PathValue("id"), days)
+	if err != nil {
+		writeAPIKeyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(usage)
+}
+
+// writeAPIKeyError maps the errors of the API key use cases to status codes
+func writeAPIKeyError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "api key not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err.Error() == "api key is already revoked":
+		http.Error(w, err.Error(), http.StatusConflict)
+	case strings.HasPrefix(err.Error(), "permission not held: "):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err.Error() == "name is required",
+		err.Error() == "at least one scope is required",
+		err.Error() == "expires at must be in the future",
+		err.Error() == "api key ID is required",
+		err.Error() == "days must be between 1 and 365",
+		strings.HasPrefix(err.Error(), "unknown permission: "),
+		strings.HasPrefix(err.Error(), "permission cannot be given to an api key: "),
+		strings.HasPrefix(err.Error(), "duplicate permission: "):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
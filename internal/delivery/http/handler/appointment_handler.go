@@ -1,12 +1,11 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"time"
 
-	"version-1-0/internal/delivery/http/middleware"
+	"version-1-0/internal/requestctx"
 	"version-1-0/internal/usecase/appointment"
 )
 
@@ -15,9 +14,12 @@ type AppointmentHandler struct {
 	createAppointmentUC   *appointment.CreateAppointmentUseCase
 	getByPatientUC        *appointment.GetAppointmentsByPatientUseCase
 	getByDoctorUC         *appointment.GetAppointmentsByDoctorUseCase
+	getAllUC              *appointment.GetAllAppointmentsUseCase
+	getAppointmentUC      *appointment.GetAppointmentUseCase
 	cancelAppointmentUC   *appointment.CancelAppointmentUseCase
 	confirmAppointmentUC  *appointment.ConfirmAppointmentUseCase
 	completeAppointmentUC *appointment.CompleteAppointmentUseCase
+	rescheduleUC          *appointment.RescheduleAppointmentUseCase
 	getHistoryUC          *appointment.GetPatientHistoryUseCase
 }
 
@@ -26,25 +28,31 @@ func NewAppointmentHandler(
 	createAppointmentUC *appointment.CreateAppointmentUseCase,
 	getByPatientUC *appointment.GetAppointmentsByPatientUseCase,
 	getByDoctorUC *appointment.GetAppointmentsByDoctorUseCase,
+	getAllUC *appointment.GetAllAppointmentsUseCase,
+	getAppointmentUC *appointment.GetAppointmentUseCase,
 	cancelAppointmentUC *appointment.CancelAppointmentUseCase,
 	confirmAppointmentUC *appointment.ConfirmAppointmentUseCase,
 	completeAppointmentUC *appointment.CompleteAppointmentUseCase,
+	rescheduleUC *appointment.RescheduleAppointmentUseCase,
 	getHistoryUC *appointment.GetPatientHistoryUseCase,
 ) *AppointmentHandler {
 	return &AppointmentHandler{
 		createAppointmentUC:   createAppointmentUC,
 		getByPatientUC:        getByPatientUC,
 		getByDoctorUC:         getByDoctorUC,
+		getAllUC:              getAllUC,
+		getAppointmentUC:      getAppointmentUC,
 		cancelAppointmentUC:   cancelAppointmentUC,
 		confirmAppointmentUC:  confirmAppointmentUC,
 		completeAppointmentUC: completeAppointmentUC,
+		rescheduleUC:          rescheduleUC,
 		getHistoryUC:          getHistoryUC,
 	}
 }
 
 // CreateAppointment godoc
 // @Summary      Crear cita médica
-// @Description  Crear una nueva cita médica con validaciones de disponibilidad
+// @Description  Crear una nueva cita médica con validaciones de disponibilidad. Los pacientes deben haber verificado su email. Con patient_id se reserva a nombre de otro paciente, lo que requiere el permiso appointments:create:any
 // @Tags         Appointments
 // @Accept       json
 // @Produce      json
@@ -53,6 +61,7 @@ func NewAppointmentHandler(
 // @Success      201  {object}  dto.AppointmentResponse
 // @Failure      400  {object}  dto.ErrorResponse
 // @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
 // @Router       /api/appointments [post]
 func (h *AppointmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is POST
@@ -61,12 +70,17 @@ func (h *AppointmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get authenticated user ID from context (patient)
-	patientUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	// Get authenticated user ID from context (the patient, unless booking for another one)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
+	if !ok {
+		http.Error(w, "Role not found in context", http.StatusUnauthorized)
+		return
+	}
 
 	// Decode request body
 	var req appointment.CreateAppointmentRequest
@@ -90,12 +104,15 @@ func (h *AppointmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create appointment with service
-	ctx := context.Background()
+	ctx := r.Context()
 	appointmentCreated, err := h.createAppointmentUC.Execute(
 		ctx,
-		patientUserID,
+		authenticatedUserID,
+		authenticatedUserRole,
+		req.PatientID,
 		req.DoctorID,
 		req.ServiceID,
+		req.LocationID,
 		scheduledAt,
 		req.Reason,
 	)
@@ -109,6 +126,10 @@ func (h *AppointmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusConflict)
 			return
 		}
+		if err.Error() == "email not verified" || err.Error() == "insufficient permissions to book for another patient" {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -121,12 +142,17 @@ func (h *AppointmentHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // GetMyAppointments godoc
 // @Summary      Obtener mis citas
-// @Description  Retorna todas las citas del usuario autenticado
+// @Description  Retorna una página de las citas del usuario autenticado
 // @Tags         Appointments
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {array}   dto.AppointmentResponse
+// @Param        cursor  query     string  false  "next_cursor de la página anterior"
+// @Param        limit   query     int     false  "Cantidad máxima (por defecto 20, máximo 100)"
+// @Param        sort    query     string  false  "Orden: scheduled_at (por defecto) o created_at"
+// @Param        order   query     string  false  "asc o desc (por defecto desc)"
+// @Success      200  {object}  appointment.ListAppointmentsResponse
+// @Failure      400  {object}  dto.ErrorResponse
 // @Failure      401  {object}  dto.ErrorResponse
 // @Router       /api/appointments/my [get]
 func (h *AppointmentHandler) GetMyAppointments(w http.ResponseWriter, r *http.Request) {
@@ -137,16 +163,19 @@ func (h *AppointmentHandler) GetMyAppointments(w http.ResponseWriter, r *http.Re
 	}
 
 	// Get authenticated user ID from context
-	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.getByPatientUC.Execute(ctx, userID)
+	ctx := r.Context()
+	response, err := h.getByPatientUC.Execute(ctx, userID, pageRequest(r))
 	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -160,7 +189,8 @@ func (h *AppointmentHandler) GetMyAppointments(w http.ResponseWriter, r *http.Re
 // GetDoctorAppointments handles the HTTP request for retrieving doctor's appointments
 // Method: GET
 // Requires: JWT token with doctor role
-// Response: 200 OK with array of appointments
+// Query params: cursor, limit, sort (scheduled_at or created_at), order (asc or desc)
+// Response: 200 OK with one page of appointments and next_cursor
 func (h *AppointmentHandler) GetDoctorAppointments(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is GET
 	if r.Method != http.MethodGet {
@@ -169,21 +199,133 @@ func (h *AppointmentHandler) GetDoctorAppointments(w http.ResponseWriter, r *htt
 	}
 
 	// Get authenticated user ID from context
-	doctorUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	doctorUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.getByDoctorUC.Execute(ctx, doctorUserID)
+	ctx := r.Context()
+	response, err := h.getByDoctorUC.Execute(ctx, doctorUserID, pageRequest(r))
 	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetAll godoc
+// @Summary      Listar todas las citas
+// @Description  Retorna una página de citas con filtros opcionales (permiso appointments:read:any)
+// @Tags         Appointments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status      query     string  false  "Estado (pending, confirmed, completed, cancelled)"
+// @Param        doctor_id   query     string  false  "ID del doctor"
+// @Param        patient_id  query     string  false  "ID del paciente"
+// @Param        service_id  query     string  false  "ID del servicio"
+// @Param        location_id query     string  false  "ID de la sede"
+// @Param        date_from   query     string  false  "Fecha inicial (YYYY-MM-DD)"
+// @Param        date_to     query     string  false  "Fecha final (YYYY-MM-DD)"
+// @Param        cursor      query     string  false  "next_cursor de la página anterior"
+// @Param        limit       query     int     false  "Cantidad máxima (por defecto 20, máximo 100)"
+// @Param        sort        query     string  false  "Orden: scheduled_at (por defecto) o created_at"
+// @Param        order       query     string  false  "asc o desc (por defecto desc)"
+// @Success      200  {object}  appointment.ListAppointmentsResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Router       /api/appointments/all [get]
+func (h *AppointmentHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	req := appointment.GetAllAppointmentsRequest{
+		Status:     query.Get("status"),
+		DoctorID:   query.Get("doctor_id"),
+		PatientID:  query.Get("patient_id"),
+		ServiceID:  query.Get("service_id"),
+		LocationID: query.Get("location_id"),
+		DateFrom:   query.Get("date_from"),
+		DateTo:     query.Get("date_to"),
+		Request:    pageRequest(r),
+	}
+
+	response, err := h.getAllUC.Execute(r.Context(), req)
+	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Get handles the HTTP request for reading a single appointment
+// Method: GET
+// Requires: JWT token (the patient, the doctor of the appointment or appointments:read:any)
+// Query parameter: id (appointment ID)
+// Response: 200 OK with the appointment and its version in the ETag header
+func (h *AppointmentHandler) Get(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get appointment ID from query parameter
+	appointmentID := r.URL.Query().Get("id")
+	if appointmentID == "" {
+		http.Error(w, "Appointment ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get authenticated user info from context
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
+	if !ok {
+		http.Error(w, "Role not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	response, err := h.getAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole)
+	if err != nil {
+		if err.Error() == "appointment not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err.Error() == "insufficient permissions to view this appointment" {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	// Return success response
+	setETag(w, response.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -194,7 +336,8 @@ func (h *AppointmentHandler) GetDoctorAppointments(w http.ResponseWriter, r *htt
 // Requires: JWT token (patient, doctor, or admin)
 // Query parameter: id (appointment ID)
 // Request body: JSON with cancellation data
-// Response: 204 No Content on success
+// Optional header: If-Match with the ETag from a previous read
+// Response: 204 No Content on success, 412 if the appointment changed since
 func (h *AppointmentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is PUT
 	if r.Method != http.MethodPut {
@@ -210,13 +353,13 @@ func (h *AppointmentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get authenticated user info from context
-	authenticatedUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
-	authenticatedUserRole, ok := r.Context().Value(middleware.RoleKey).(string)
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
 	if !ok {
 		http.Error(w, "Role not found in context", http.StatusUnauthorized)
 		return
@@ -229,10 +372,18 @@ func (h *AppointmentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	// Execute use case
-	ctx := context.Background()
-	err := h.cancelAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole, req)
+	ctx := r.Context()
+	err := h.cancelAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole, req, expectedVersion)
 	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
 		if err.Error() == "appointment not found" {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -255,9 +406,10 @@ func (h *AppointmentHandler) Cancel(w http.ResponseWriter, r *http.Request) {
 
 // Confirm handles the HTTP request for confirming a pending appointment
 // Method: PUT
-// Requires: JWT token (doctor or admin)
+// Requires: JWT token (the doctor of the appointment or appointments:update:any)
 // Query parameter: id (appointment ID)
-// Response: 200 OK with confirmed appointment data
+// Optional header: If-Match with the ETag from a previous read
+// Response: 200 OK with confirmed appointment data, 412 if the appointment changed since
 func (h *AppointmentHandler) Confirm(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is PUT
 	if r.Method != http.MethodPut {
@@ -273,22 +425,30 @@ func (h *AppointmentHandler) Confirm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get authenticated user info from context
-	authenticatedUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
-	authenticatedUserRole, ok := r.Context().Value(middleware.RoleKey).(string)
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
 	if !ok {
 		http.Error(w, "Role not found in context", http.StatusUnauthorized)
 		return
 	}
 
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.confirmAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole)
+	ctx := r.Context()
+	response, err := h.confirmAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole, expectedVersion)
 	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
 		if err.Error() == "appointment not found" {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -306,6 +466,7 @@ func (h *AppointmentHandler) Confirm(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return success response
+	setETag(w, response.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -313,10 +474,11 @@ func (h *AppointmentHandler) Confirm(w http.ResponseWriter, r *http.Request) {
 
 // Complete handles the HTTP request for completing a confirmed appointment
 // Method: PUT
-// Requires: JWT token (doctor or admin)
+// Requires: JWT token (the doctor of the appointment or appointments:update:any)
 // Query parameter: id (appointment ID)
 // Request body: JSON with completion notes
-// Response: 200 OK with completed appointment data
+// Optional header: If-Match with the ETag from a previous read
+// Response: 200 OK with completed appointment data, 412 if the appointment changed since
 func (h *AppointmentHandler) Complete(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is PUT
 	if r.Method != http.MethodPut {
@@ -332,13 +494,13 @@ func (h *AppointmentHandler) Complete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get authenticated user info from context
-	authenticatedUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
-	authenticatedUserRole, ok := r.Context().Value(middleware.RoleKey).(string)
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
 	if !ok {
 		http.Error(w, "Role not found in context", http.StatusUnauthorized)
 		return
@@ -351,10 +513,18 @@ func (h *AppointmentHandler) Complete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.completeAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole, req)
+	ctx := r.Context()
+	response, err := h.completeAppointmentUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole, req, expectedVersion)
 	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
 		if err.Error() == "appointment not found" {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
@@ -372,6 +542,87 @@ func (h *AppointmentHandler) Complete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return success response
+	setETag(w, response.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Reschedule handles the HTTP request for moving an appointment to a new date and time
+// Method: PUT
+// Requires: JWT token with admin role
+// Query parameter: id (appointment ID)
+// Request body: JSON with new_date and new_time
+// Optional header: If-Match with the ETag from a previous read
+// Response: 200 OK with rescheduled appointment data, 412 if the appointment changed since
+func (h *AppointmentHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get appointment ID from query parameter
+	appointmentID := r.URL.Query().Get("id")
+	if appointmentID == "" {
+		http.Error(w, "Appointment ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get authenticated user info from context
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
+	if !ok {
+		http.Error(w, "Role not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	// Decode request body
+	var req appointment.RescheduleAppointmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	response, err := h.rescheduleUC.Execute(ctx, appointmentID, authenticatedUserID, authenticatedUserRole, req, expectedVersion)
+	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
+		if err.Error() == "appointment not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err.Error() == "insufficient permissions to reschedule this appointment" {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err.Error() == "time slot is not available" {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err.Error() == "failed to check doctor availability" || err.Error() == "failed to reschedule appointment" {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Return success response
+	setETag(w, response.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -398,20 +649,20 @@ func (h *AppointmentHandler) GetHistory(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get authenticated user info from context
-	authenticatedUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
-	authenticatedUserRole, ok := r.Context().Value(middleware.RoleKey).(string)
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
 	if !ok {
 		http.Error(w, "Role not found in context", http.StatusUnauthorized)
 		return
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	response, err := h.getHistoryUC.Execute(ctx, patientID, authenticatedUserID, authenticatedUserRole)
 	if err != nil {
 		if err.Error() == "patient not found" {
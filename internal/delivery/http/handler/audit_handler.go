@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"version-1-0/internal/usecase/audit"
+)
+
+// AuditHandler handles HTTP requests for the audit log
+type AuditHandler struct {
+	listAuditLogUC *audit.ListAuditLogUseCase
+}
+
+// NewAuditHandler creates a new instance of AuditHandler
+func NewAuditHandler(listAuditLogUC *audit.ListAuditLogUseCase) *AuditHandler {
+	return &AuditHandler{
+		listAuditLogUC: listAuditLogUC,
+	}
+}
+
+// List godoc
+// @Summary      Registro de auditoría
+// @Description  Lista las operaciones que modificaron datos, más recientes primero (permiso audit:read)
+// @Tags         Audit
+// @Produce      json
+// @Security     BearerAuth
+// @Param        entity     query     string  false  "Entidad (user, doctor, appointment, service, doctor_service, schedule)"
+// @Param        entity_id  query     string  false  "ID de la entidad"
+// @Param        actor_id   query     string  false  "ID del usuario que realizó la operación"
+// @Param        date_from  query     string  false  "Fecha inicial (YYYY-MM-DD)"
+// @Param        date_to    query     string  false  "Fecha final (YYYY-MM-DD)"
+// @Param        limit      query     int     false  "Cantidad máxima (por defecto 50, máximo 200)"
+// @Param        offset     query     int     false  "Desplazamiento"
+// @Success      200  {object}  audit.ListAuditLogResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Router       /api/audit [get]
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	req := audit.ListAuditLogRequest{
+		Entity:   query.Get("entity"),
+		EntityID: query.Get("entity_id"),
+		ActorID:  query.Get("actor_id"),
+		DateFrom: query.Get("date_from"),
+		DateTo:   query.Get("date_to"),
+	}
+
+	// Invalid numbers fall back to the defaults
+	if limitStr := query.Get("limit"); limitStr != "" {
+		req.Limit, _ = strconv.Atoi(limitStr)
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		req.Offset, _ = strconv.Atoi(offsetStr)
+	}
+
+	response, err := h.listAuditLogUC.Execute(r.Context(), req)
+	if err != nil {
+		errMsg := err.Error()
+		if errMsg == "invalid date_from format, use YYYY-MM-DD" || errMsg == "invalid date_to format, use YYYY-MM-DD" {
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+		http.Error(w, errMsg, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -1,28 +1,53 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 
+	"version-1-0/internal/requestctx"
 	"version-1-0/internal/usecase/auth"
 )
 
 // AuthHandler handles HTTP requests related to authentication operations
 type AuthHandler struct {
-	loginUC *auth.LoginUseCase
+	loginUC              *auth.LoginUseCase
+	refreshUC            *auth.RefreshUseCase
+	logoutUC             *auth.LogoutUseCase
+	forgotPasswordUC     *auth.ForgotPasswordUseCase
+	resetPasswordUC      *auth.ResetPasswordUseCase
+	verifyEmailUC        *auth.VerifyEmailUseCase
+	resendVerificationUC *auth.ResendVerificationUseCase
+	unlockAccountUC      *auth.UnlockAccountUseCase
 }
 
 // NewAuthHandler creates a new instance of AuthHandler
-func NewAuthHandler(loginUC *auth.LoginUseCase) *AuthHandler {
+func NewAuthHandler(
+	loginUC *auth.LoginUseCase,
+	refreshUC *auth.RefreshUseCase,
+	logoutUC *auth.LogoutUseCase,
+	forgotPasswordUC *auth.ForgotPasswordUseCase,
+	resetPasswordUC *auth.ResetPasswordUseCase,
+	verifyEmailUC *auth.VerifyEmailUseCase,
+	resendVerificationUC *auth.ResendVerificationUseCase,
+	unlockAccountUC *auth.UnlockAccountUseCase,
+) *AuthHandler {
 	return &AuthHandler{
-		loginUC: loginUC,
+		loginUC:              loginUC,
+		refreshUC:            refreshUC,
+		logoutUC:             logoutUC,
+		forgotPasswordUC:     forgotPasswordUC,
+		resetPasswordUC:      resetPasswordUC,
+		verifyEmailUC:        verifyEmailUC,
+		resendVerificationUC: resendVerificationUC,
+		unlockAccountUC:      unlockAccountUC,
 	}
 }
 
 // Login godoc
 // @Summary      Login de usuario
-// @Description  Autenticar usuario y obtener token JWT
+// @Description  Autenticar usuario y obtener un token de acceso de corta duración y un refresh token para renovarlo. Tras varios intentos fallidos hay que esperar cada vez más entre uno y otro, y luego la cuenta o la IP quedan bloqueadas por un tiempo; mientras tanto responde 429 con el header Retry-After. El personal de los roles que deben usar el inicio de sesión único recibe 403 y entra por /api/auth/sso/start. Si la cuenta tiene verificación en dos pasos, o su rol la exige, responde en cambio un dto.TwoFactorChallenge y el login sigue en /api/auth/2fa/verify
 // @Tags         Authentication
 // @Accept       json
 // @Produce      json
@@ -30,6 +55,8 @@ func NewAuthHandler(loginUC *auth.LoginUseCase) *AuthHandler {
 // @Success      200  {object}  dto.LoginResponse
 // @Failure      400  {object}  dto.ErrorResponse
 // @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      429  {object}  dto.ErrorResponse
 // @Router       /api/auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is POST
@@ -46,15 +73,264 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.loginUC.Execute(ctx, req)
+	ctx := r.Context()
+	response, wait, err := h.loginUC.Execute(ctx, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		switch err.Error() {
+		case "too many failed logins from this address", "account is temporarily locked", "too many failed logins, try again later":
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		case "this account must sign in with single sign-on":
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		}
+		return
+	}
+
+	// Return success response, or the challenge of the second step
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if response.TwoFactor != nil {
+		json.NewEncoder(w).Encode(response.TwoFactor)
+		return
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// Refresh godoc
+// @Summary      Renovar token
+// @Description  Canjea un refresh token por un token de acceso y un refresh token nuevos. Cada refresh token sirve una sola vez: si se vuelve a usar uno ya canjeado, se cierra la sesión completa
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.RefreshRequest  true  "Refresh token"
+// @Success      200  {object}  dto.LoginResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Router       /api/auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req auth.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.refreshUC.Execute(r.Context(), req)
+	if err != nil {
+		switch err.Error() {
+		case "refresh_token is required":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case "invalid refresh token", "refresh token expired", "refresh token reuse detected":
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Logout godoc
+// @Summary      Cerrar sesión
+// @Description  Revoca la sesión del refresh token. Sus tokens de acceso dejan de valer de inmediato
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.RefreshRequest  true  "Refresh token"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req auth.LogoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.logoutUC.Execute(r.Context(), req); err != nil {
+		if err.Error() == "refresh_token is required" {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Logged out successfully",
+	})
+}
+
+// ForgotPassword godoc
+// @Summary      Olvidé mi contraseña
+// @Description  Envía por email un enlace para elegir una nueva contraseña. La respuesta es la misma exista o no el email, para no revelar qué cuentas están registradas. Cada enlace nuevo invalida los anteriores
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.ForgotPasswordRequest  true  "Email de la cuenta"
+// @Success      202  {object}  dto.MessageResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req auth.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.forgotPasswordUC.Execute(r.Context(), req); err != nil {
+		if err.Error() == "email is required" {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to process request", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Restablecer contraseña
+// @Description  Cambia la contraseña con el token del enlace enviado por email. El token sirve una sola vez, y todas las sesiones abiertas del usuario se cierran
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.ResetPasswordRequest  true  "Token del enlace y nueva contraseña"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req auth.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.resetPasswordUC.Execute(r.Context(), req); err != nil {
+		switch err.Error() {
+		case "token is required", "password must be at least 8 characters long", "invalid or expired reset token":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Password has been reset",
+	})
+}
+
+// UnlockAccount godoc
+// @Summary      Desbloquear cuenta
+// @Description  Levanta el bloqueo de una cuenta con el token del enlace enviado por email al bloquearse tras varios intentos fallidos de login. El token deja de servir cuando el bloqueo termina
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.UnlockAccountRequest  true  "Token del enlace"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/auth/unlock [post]
+func (h *AuthHandler) UnlockAccount(w http.ResponseWriter, r *http.Request) {
+	var req auth.UnlockAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.unlockAccountUC.Execute(r.Context(), req); err != nil {
+		switch err.Error() {
+		case "token is required", "invalid or expired unlock token":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Account has been unlocked",
+	})
+}
+
+// VerifyEmail godoc
+// @Summary      Verificar email
+// @Description  Confirma el email de la cuenta con el token del enlace enviado al registrarse. Abrir el enlace de nuevo después de verificar no es un error
+// @Tags         Authentication
+// @Produce      json
+// @Param        token  query     string  true  "Token del enlace de verificación"
+// @Success      200  {object}  dto.VerifyEmailResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/auth/verify-email [get]
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	response, err := h.verifyEmailUC.Execute(r.Context(), r.URL.Query().Get("token"))
+	if err != nil {
+		switch err.Error() {
+		case "token is required", "invalid or expired verification link":
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
 		return
 	}
 
-	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// ResendVerification godoc
+// @Summary      Reenviar verificación de email
+// @Description  Envía un nuevo enlace de verificación al email del usuario autenticado. Se puede pedir uno cada 5 minutos; antes responde 429 con el header Retry-After
+// @Tags         Authentication
+// @Produce      json
+// @Security     BearerAuth
+// @Success      202  {object}  dto.MessageResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      429  {object}  dto.ErrorResponse
+// @Router       /api/auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	wait, err := h.resendVerificationUC.Execute(r.Context(), userID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case "email already verified":
+			http.Error(w, err.Error(), http.StatusConflict)
+		case "verification email sent recently":
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Verification email sent",
+	})
+}
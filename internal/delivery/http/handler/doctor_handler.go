@@ -1,9 +1,9 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"version-1-0/internal/usecase/doctor"
 )
@@ -11,19 +11,28 @@ import (
 // DoctorHandler handles HTTP requests related to doctor operations
 type DoctorHandler struct {
 	searchDoctorsUC *doctor.SearchDoctorsUseCase
+	listDeletedUC   *doctor.ListDeletedDoctorsUseCase
+	restoreDoctorUC *doctor.RestoreDoctorUseCase
 }
 
 // NewDoctorHandler creates a new instance of DoctorHandler
-func NewDoctorHandler(searchDoctorsUC *doctor.SearchDoctorsUseCase) *DoctorHandler {
+func NewDoctorHandler(
+	searchDoctorsUC *doctor.SearchDoctorsUseCase,
+	listDeletedUC *doctor.ListDeletedDoctorsUseCase,
+	restoreDoctorUC *doctor.RestoreDoctorUseCase,
+) *DoctorHandler {
 	return &DoctorHandler{
 		searchDoctorsUC: searchDoctorsUC,
+		listDeletedUC:   listDeletedUC,
+		restoreDoctorUC: restoreDoctorUC,
 	}
 }
 
 // Search handles the HTTP request for searching doctors
 // Method: GET
-// Query parameter: specialty (optional)
-// Response: 200 OK with array of doctors
+// Query parameters (all optional): q (free text), specialty, service_id, date (YYYY-MM-DD),
+// min_fee, max_fee, cursor, limit, sort (relevance, name, fee or created_at), order (asc or desc)
+// Response: 200 OK with one page of doctors and next_cursor
 func (h *DoctorHandler) Search(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is GET
 	if r.Method != http.MethodGet {
@@ -31,13 +40,39 @@ func (h *DoctorHandler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get specialty from query parameter (optional)
-	specialty := r.URL.Query().Get("specialty")
+	// Get search text and filters from query parameters
+	query := r.URL.Query()
+	req := doctor.SearchDoctorsRequest{
+		Query:     query.Get("q"),
+		Specialty: query.Get("specialty"),
+		ServiceID: query.Get("service_id"),
+		Date:      query.Get("date"),
+		Request:   pageRequest(r),
+	}
+
+	var err error
+	if req.MinFee, err = feeParam(query.Get("min_fee")); err != nil {
+		http.Error(w, "min_fee must be a number", http.StatusBadRequest)
+		return
+	}
+	if req.MaxFee, err = feeParam(query.Get("max_fee")); err != nil {
+		http.Error(w, "max_fee must be a number", http.StatusBadRequest)
+		return
+	}
 
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.searchDoctorsUC.Execute(ctx, specialty)
+	ctx := r.Context()
+	response, err := h.searchDoctorsUC.Execute(ctx, req)
 	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
+		if err.Error() == "invalid date format, use YYYY-MM-DD" ||
+			err.Error() == "fees cannot be negative" ||
+			err.Error() == "min_fee cannot be greater than max_fee" {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -47,3 +82,79 @@ func (h *DoctorHandler) Search(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// feeParam parses an optional fee query parameter
+// Returns nil when the parameter is absent
+func feeParam(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	fee, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &fee, nil
+}
+
+// ListDeleted handles the HTTP request for listing soft deleted doctor profiles
+// Method: GET
+// Requires: Admin role
+// Response: 200 OK with array of deleted doctors
+func (h *DoctorHandler) ListDeleted(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	doctors, err := h.listDeletedUC.Execute(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(doctors)
+}
+
+// Restore handles the HTTP request for restoring a soft deleted doctor profile
+// Method: POST
+// Requires: Admin role
+// Query parameter: id (doctor profile ID)
+// Response: 200 OK on success
+func (h *DoctorHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get doctor ID from query parameter
+	doctorID := r.URL.Query().Get("id")
+	if doctorID == "" {
+		http.Error(w, "id query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	if err := h.restoreDoctorUC.Execute(ctx, doctorID); err != nil {
+		if err.Error() == "doctor not found" || err.Error() == "user not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Doctor restored successfully",
+	})
+}
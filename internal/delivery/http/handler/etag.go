@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"version-1-0/internal/domain"
+)
+
+// setETag exposes the version of a single resource as a strong ETag
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatchVersion reads the If-Match header sent by a client that wants to
+// update only the version it last saw
+// Returns 0 when the header is missing or "*", which skips the check
+// A value that is not one of our ETags can never match, so it fails with 412
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(value, "W/"), `"`))
+	if err != nil || version < 1 {
+		http.Error(w, "If-Match must be an ETag returned by the API", http.StatusPreconditionFailed)
+		return 0, false
+	}
+
+	return version, true
+}
+
+// writeVersionConflict answers a stale update
+// Clients that sent If-Match get 412, the others lost a race and get 409
+// Returns false if err is not a version conflict
+func writeVersionConflict(w http.ResponseWriter, r *http.Request, err error) bool {
+	if !errors.Is(err, domain.ErrVersionConflict) {
+		return false
+	}
+
+	status := http.StatusConflict
+	if r.Header.Get("If-Match") != "" {
+		status = http.StatusPreconditionFailed
+	}
+	http.Error(w, err.Error(), status)
+	return true
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/user"
+)
+
+// InvitationHandler handles HTTP requests for staff invitations
+type InvitationHandler struct {
+	createInvitationUC *user.CreateInvitationUseCase
+	listInvitationsUC  *user.ListInvitationsUseCase
+	revokeInvitationUC *user.RevokeInvitationUseCase
+	acceptInvitationUC *user.AcceptInvitationUseCase
+}
+
+// NewInvitationHandler creates a new invitation handler
+func NewInvitationHandler(
+	createInvitationUC *user.CreateInvitationUseCase,
+	listInvitationsUC *user.ListInvitationsUseCase,
+	revokeInvitationUC *user.RevokeInvitationUseCase,
+	acceptInvitationUC *user.AcceptInvitationUseCase,
+) *InvitationHandler {
+	return &InvitationHandler{
+		createInvitationUC: createInvitationUC,
+		listInvitationsUC:  listInvitationsUC,
+		revokeInvitationUC: revokeInvitationUC,
+		acceptInvitationUC: acceptInvitationUC,
+	}
+}
+
+// Create godoc
+// @Summary      Invitar personal
+// @Description  Envía por email una invitación para crear una cuenta de doctor o administrador. Una invitación nueva al mismo email revoca las pendientes
+// @Tags         Invitations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      dto.CreateInvitationRequest  true  "Email y rol del invitado"
+// @Success      201  {object}  dto.InvitationResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Router       /api/invitations [post]
+func (h *InvitationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req user.CreateInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invitation, err := h.createInvitationUC.Execute(r.Context(), userID, req)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invitation)
+}
+
+// List godoc
+// @Summary      Listar invitaciones
+// @Description  Lista las invitaciones de la clínica, las más recientes primero
+// @Tags         Invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "pending, accepted, revoked o expired"
+// @Success      200  {array}   dto.InvitationResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/invitations [get]
+func (h *InvitationHandler) List(w http.ResponseWriter, r *http.Request) {
+	invitations, err := h.listInvitationsUC.Execute(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitations)
+}
+
+// Revoke godoc
+// @Summary      Revocar invitación
+// @Description  Anula una invitación pendiente para que su enlace deje de funcionar
+// @Tags         Invitations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID de la invitación"
+// @Success      200  {object}  dto.InvitationResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Router       /api/invitations/{id} [delete]
+func (h *InvitationHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	invitation, err := h.revokeInvitationUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invitation)
+}
+
+// Accept godoc
+// @Summary      Aceptar invitación
+// @Description  Crea la cuenta de la invitación con la contraseña elegida. El email y el rol vienen de la invitación, y el email queda verificado. Cada invitación sirve una sola vez
+// @Tags         Invitations
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.AcceptInvitationRequest  true  "Token del enlace y datos de la cuenta"
+// @Success      201  {object}  dto.UserResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/invitations/accept [post]
+func (h *InvitationHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	var req user.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.acceptInvitationUC.Execute(r.Context(), req)
+	if err != nil {
+		writeInvitationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// writeInvitationError maps the errors of the invitation use cases to status codes
+func writeInvitationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "invitation not found", "user not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case "email already exists", "invitation is no longer pending":
+		http.Error(w, err.Error(), http.StatusConflict)
+	case "only administrators can invite administrators":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case "failed to list invitations":
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
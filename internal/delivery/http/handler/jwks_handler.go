@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/pkg/jwtkeys"
+)
+
+// JWKSHandler publishes the public keys that verify access tokens
+type JWKSHandler struct {
+	keys *jwtkeys.KeySet
+}
+
+// NewJWKSHandler creates a new JWKS handler
+func NewJWKSHandler(keys *jwtkeys.KeySet) *JWKSHandler {
+	return &JWKSHandler{
+		keys: keys,
+	}
+}
+
+// Get godoc
+// @Summary      Claves públicas de los tokens
+// @Description  Retorna las claves públicas (JWKS) con las que otros servicios verifican los tokens de acceso. El header kid del token indica la clave. Vacío si los tokens se firman con JWT_SECRET
+// @Tags         Authentication
+// @Produce      json
+// @Success      200  {object}  dto.JWKSResponse
+// @Router       /.well-known/jwks.json [get]
+func (h *JWKSHandler) Get(w http.ResponseWriter, r *http.Request) {
+	// Verifiers cache the set; a new key is published before it signs
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.keys.JWKS())
+}
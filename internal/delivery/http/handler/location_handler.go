@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/internal/usecase/location"
+)
+
+// LocationHandler handles HTTP requests for the clinic and its locations
+type LocationHandler struct {
+	getClinicUC          *location.GetClinicUseCase
+	listLocationsUC      *location.ListLocationsUseCase
+	createLocationUC     *location.CreateLocationUseCase
+	updateLocationUC     *location.UpdateLocationUseCase
+	assignDoctorUC       *location.AssignDoctorUseCase
+	removeDoctorUC       *location.RemoveDoctorUseCase
+	listServicePricesUC  *location.ListServicePricesUseCase
+	setServicePriceUC    *location.SetServicePriceUseCase
+	removeServicePriceUC *location.RemoveServicePriceUseCase
+}
+
+// NewLocationHandler creates a new location handler
+func NewLocationHandler(
+	getClinicUC *location.GetClinicUseCase,
+	listLocationsUC *location.ListLocationsUseCase,
+	createLocationUC *location.CreateLocationUseCase,
+	updateLocationUC *location.UpdateLocationUseCase,
+	assignDoctorUC *location.AssignDoctorUseCase,
+	removeDoctorUC *location.RemoveDoctorUseCase,
+	listServicePricesUC *location.ListServicePricesUseCase,
+	setServicePriceUC *location.SetServicePriceUseCase,
+	removeServicePriceUC *location.RemoveServicePriceUseCase,
+) *LocationHandler {
+	return &LocationHandler{
+		getClinicUC:          getClinicUC,
+		listLocationsUC:      listLocationsUC,
+		createLocationUC:     createLocationUC,
+		updateLocationUC:     updateLocationUC,
+		assignDoctorUC:       assignDoctorUC,
+		removeDoctorUC:       removeDoctorUC,
+		listServicePricesUC:  listServicePricesUC,
+		setServicePriceUC:    setServicePriceUC,
+		removeServicePriceUC: removeServicePriceUC,
+	}
+}
+
+// GetClinic handles GET /api/clinic (public)
+// Returns the clinic selected by the X-Clinic-ID header with its active locations
+func (h *LocationHandler) GetClinic(w http.ResponseWriter, r *http.Request) {
+	clinic, err := h.getClinicUC.Execute(r.Context())
+	if err != nil {
+		if err.Error() == "clinic not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(clinic)
+}
+
+// ListLocations handles GET /api/locations (public)
+// ?include_inactive=true also returns closed locations
+func (h *LocationHandler) ListLocations(w http.ResponseWriter, r *http.Request) {
+	includeInactive := r.URL.Query().Get("include_inactive") == "true"
+
+	locations, err := h.listLocationsUC.Execute(r.Context(), includeInactive)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(locations)
+}
+
+// CreateLocation handles POST /api/locations (admin only)
+func (h *LocationHandler) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	var req location.CreateLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	created, err := h.createLocationUC.Execute(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setETag(w, created.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// UpdateLocation handles PUT /api/locations/{id} (admin only)
+// Honours If-Match with the ETag from a previous read and answers 412 if the location changed since
+func (h *LocationHandler) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	locationID := r.PathValue("id")
+
+	var req location.UpdateLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.updateLocationUC.Execute(r.Context(), locationID, req, expectedVersion)
+	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
+		writeLocationError(w, err)
+		return
+	}
+
+	setETag(w, updated.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// AssignDoctor handles POST /api/locations/{id}/doctors (admin only)
+func (h *LocationHandler) AssignDoctor(w http.ResponseWriter, r *http.Request) {
+	var req location.AssignDoctorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DoctorID == "" {
+		http.Error(w, "doctor_id is required", http.StatusBadRequest)
+		return
+	}
+
+	assigned, err := h.assignDoctorUC.Execute(r.Context(), r.PathValue("id"), req.DoctorID)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(assigned)
+}
+
+// RemoveDoctor handles DELETE /api/locations/{id}/doctors/{doctorId} (admin only)
+// doctorId is the doctor's user ID
+func (h *LocationHandler) RemoveDoctor(w http.ResponseWriter, r *http.Request) {
+	if err := h.removeDoctorUC.Execute(r.Context(), r.PathValue("id"), r.PathValue("doctorId")); err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Doctor removed from location successfully",
+	})
+}
+
+// ListServicePrices handles GET /api/locations/{id}/prices (public)
+func (h *LocationHandler) ListServicePrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.listServicePricesUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(prices)
+}
+
+// SetServicePrice handles PUT /api/locations/{id}/prices/{serviceId} (admin only)
+func (h *LocationHandler) SetServicePrice(w http.ResponseWriter, r *http.Request) {
+	var req location.SetServicePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	price, err := h.setServicePriceUC.Execute(r.Context(), r.PathValue("id"), r.PathValue("serviceId"), req)
+	if err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(price)
+}
+
+// RemoveServicePrice handles DELETE /api/locations/{id}/prices/{serviceId} (admin only)
+func (h *LocationHandler) RemoveServicePrice(w http.ResponseWriter, r *http.Request) {
+	if err := h.removeServicePriceUC.Execute(r.Context(), r.PathValue("id"), r.PathValue("serviceId")); err != nil {
+		writeLocationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Service price removed successfully",
+	})
+}
+
+// writeLocationError maps the errors of the location use cases to status codes
+func writeLocationError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "location not found", "doctor not found", "service not found", "service price not found", "doctor-location relationship not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case "doctor already works at this location", "doctor still has schedules at this location":
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/internal/usecase/auth"
+)
+
+// LockoutHandler handles HTTP requests for the accounts and client addresses
+// locked after too many failed logins
+type LockoutHandler struct {
+	listLockoutsUC *auth.ListLockoutsUseCase
+	clearLockoutUC *auth.ClearLockoutUseCase
+}
+
+// NewLockoutHandler creates a new lockout handler
+func NewLockoutHandler(listLockoutsUC *auth.ListLockoutsUseCase, clearLockoutUC *auth.ClearLockoutUseCase) *LockoutHandler {
+	return &LockoutHandler{
+		listLockoutsUC: listLockoutsUC,
+		clearLockoutUC: clearLockoutUC,
+	}
+}
+
+// List godoc
+// @Summary      Listar bloqueos de login
+// @Description  Lista las cuentas e IPs de la clínica bloqueadas en este momento por demasiados intentos fallidos de login, las de fallo más reciente primero. Para las cuentas, subject es el email con el que se intentó entrar
+// @Tags         Lockouts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        scope  query     string  false  "account o ip"
+// @Success      200  {array}   dto.LockoutResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Router       /api/lockouts [get]
+func (h *LockoutHandler) List(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.listLockoutsUC.Execute(r.Context(), r.URL.Query().Get("scope"))
+	if err != nil {
+		writeLockoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(lockouts)
+}
+
+// Clear godoc
+// @Summary      Levantar bloqueo de login
+// @Description  Desbloquea una cuenta o IP y borra sus intentos fallidos, para que pueda volver a entrar de inmediato. El enlace de desbloqueo enviado por email deja de servir
+// @Tags         Lockouts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID del bloqueo"
+// @Success      200  {object}  dto.LockoutResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/lockouts/{id} [delete]
+func (h *LockoutHandler) Clear(w http.ResponseWriter, r *http.Request) {
+	lockout, err := h.clearLockoutUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeLockoutError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(lockout)
+}
+
+// writeLockoutError maps the errors of the lockout use cases to status codes
+func writeLockoutError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "lockout not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case "failed to list lockouts":
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	case "invalid scope: must be account or ip", "lockout ID is required":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"version-1-0/internal/usecase/pagination"
+)
+
+// pageRequest reads the cursor, limit, sort and order query parameters of a list endpoint
+// An invalid limit falls back to the default, like the other numeric query parameters
+func pageRequest(r *http.Request) pagination.Request {
+	query := r.URL.Query()
+	req := pagination.Request{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Order:  query.Get("order"),
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		req.Limit, _ = strconv.Atoi(limitStr)
+	}
+
+	return req
+}
+
+// writePageError answers invalid pagination parameters with 400
+// Returns false if err is not a pagination error
+func writePageError(w http.ResponseWriter, err error) bool {
+	if !pagination.IsError(err) {
+		return false
+	}
+
+	http.Error(w, err.Error(), http.StatusBadRequest)
+	return true
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/internal/usecase/patient"
+)
+
+// PatientHandler handles HTTP requests for patient profiles
+type PatientHandler struct {
+	findByDocumentUC *patient.FindByDocumentUseCase
+}
+
+// NewPatientHandler creates a new instance of PatientHandler
+func NewPatientHandler(findByDocumentUC *patient.FindByDocumentUseCase) *PatientHandler {
+	return &PatientHandler{
+		findByDocumentUC: findByDocumentUC,
+	}
+}
+
+// FindByDocument godoc
+// @Summary      Buscar pacientes por documento
+// @Description  Busca pacientes por su número de documento exacto, sin distinguir mayúsculas, espacios ni signos (permiso patients:read)
+// @Tags         Patients
+// @Produce      json
+// @Security     BearerAuth
+// @Param        document_number  query     string  true   "Número de documento"
+// @Param        document_type    query     string  false  "Tipo de documento (DNI, CE...)"
+// @Success      200  {array}   patient.PatientResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Router       /api/patients/by-document [get]
+func (h *PatientHandler) FindByDocument(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := patient.FindByDocumentRequest{
+		DocumentType:   query.Get("document_type"),
+		DocumentNumber: query.Get("document_number"),
+	}
+
+	patients, err := h.findByDocumentUC.Execute(r.Context(), req)
+	if err != nil {
+		if err.Error() == "document_number is required" {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(patients)
+}
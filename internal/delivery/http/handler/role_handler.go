@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/role"
+)
+
+// RoleHandler handles HTTP requests for the permissions of the roles of the clinic
+type RoleHandler struct {
+	listRolesUC       *role.ListRolesUseCase
+	getRoleUC         *role.GetRoleUseCase
+	updateRoleUC      *role.UpdateRoleUseCase
+	resetRoleUC       *role.ResetRoleUseCase
+	listPermissionsUC *role.ListPermissionsUseCase
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(
+	listRolesUC *role.ListRolesUseCase,
+	getRoleUC *role.GetRoleUseCase,
+	updateRoleUC *role.UpdateRoleUseCase,
+	resetRoleUC *role.ResetRoleUseCase,
+	listPermissionsUC *role.ListPermissionsUseCase,
+) *RoleHandler {
+	return &RoleHandler{
+		listRolesUC:       listRolesUC,
+		getRoleUC:         getRoleUC,
+		updateRoleUC:      updateRoleUC,
+		resetRoleUC:       resetRoleUC,
+		listPermissionsUC: listPermissionsUC,
+	}
+}
+
+// List godoc
+// @Summary      Listar roles
+// @Description  Lista los roles con los permisos que tienen en la clínica. default indica que el rol conserva sus permisos predeterminados
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   dto.RoleResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Router       /api/roles [get]
+func (h *RoleHandler) List(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.listRolesUC.Execute(r.Context())
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(roles)
+}
+
+// Get godoc
+// @Summary      Obtener rol
+// @Description  Retorna un rol con los permisos que tiene en la clínica
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role  path      string  true  "admin, doctor, nurse, receptionist o patient"
+// @Success      200  {object}  dto.RoleResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/roles/{role} [get]
+func (h *RoleHandler) Get(w http.ResponseWriter, r *http.Request) {
+	response, err := h.getRoleUC.Execute(r.Context(), r.PathValue("role"))
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Update godoc
+// @Summary      Cambiar permisos de un rol
+// @Description  Reemplaza los permisos del rol en la clínica. Rige desde la siguiente petición de cada usuario con el rol. El rol admin tiene siempre todos los permisos y no se puede cambiar. Quien no es administrador solo puede dar permisos que tiene
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role     path      string                  true  "doctor, nurse, receptionist o patient"
+// @Param        request  body      dto.UpdateRoleRequest  true  "Permisos del rol"
+// @Success      200  {object}  dto.RoleResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/roles/{role} [put]
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := r.Context().Value(requestctx.RoleKey).(string)
+	if !ok {
+		http.Error(w, "Role not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req role.UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Permissions == nil {
+		http.Error(w, "permissions is required", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.updateRoleUC.Execute(r.Context(), callerRole, r.PathValue("role"), req)
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Reset godoc
+// @Summary      Restablecer permisos de un rol
+// @Description  Devuelve al rol sus permisos predeterminados en la clínica. Quien no es administrador solo puede hacerlo si tiene todos esos permisos
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        role  path      string  true  "doctor, nurse, receptionist o patient"
+// @Success      200  {object}  dto.RoleResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/roles/{role} [delete]
+func (h *RoleHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	callerRole, ok := r.Context().Value(requestctx.RoleKey).(string)
+	if !ok {
+		http.Error(w, "Role not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.resetRoleUC.Execute(r.Context(), callerRole, r.PathValue("role"))
+	if err != nil {
+		writeRoleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ListPermissions godoc
+// @Summary      Listar permisos
+// @Description  Lista todos los permisos que se pueden dar a un rol. El sufijo :any indica acciones sobre registros de otros usuarios
+// @Tags         Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   dto.PermissionResponse
+// @Router       /api/permissions [get]
+func (h *RoleHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.listPermissionsUC.Execute())
+}
+
+// writeRoleError maps the errors of the role use cases to status codes
+func writeRoleError(w http.ResponseWriter, err error) {
+	switch {
+	case err.Error() == "role not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case strings.HasPrefix(err.Error(), "permission not held: "):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err.Error() == "the admin role always has every permission",
+		strings.HasPrefix(err.Error(), "unknown permission: "),
+		strings.HasPrefix(err.Error(), "duplicate permission: "):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
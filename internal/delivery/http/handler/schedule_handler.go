@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 
@@ -12,6 +11,8 @@ import (
 type ScheduleHandler struct {
 	createScheduleUC *schedule.CreateScheduleUseCase
 	getSchedulesUC   *schedule.GetDoctorSchedulesUseCase
+	getScheduleUC    *schedule.GetScheduleUseCase
+	updateScheduleUC *schedule.UpdateScheduleUseCase
 	deleteScheduleUC *schedule.DeleteScheduleUseCase
 }
 
@@ -19,11 +20,15 @@ type ScheduleHandler struct {
 func NewScheduleHandler(
 	createScheduleUC *schedule.CreateScheduleUseCase,
 	getSchedulesUC *schedule.GetDoctorSchedulesUseCase,
+	getScheduleUC *schedule.GetScheduleUseCase,
+	updateScheduleUC *schedule.UpdateScheduleUseCase,
 	deleteScheduleUC *schedule.DeleteScheduleUseCase,
 ) *ScheduleHandler {
 	return &ScheduleHandler{
 		createScheduleUC: createScheduleUC,
 		getSchedulesUC:   getSchedulesUC,
+		getScheduleUC:    getScheduleUC,
+		updateScheduleUC: updateScheduleUC,
 		deleteScheduleUC: deleteScheduleUC,
 	}
 }
@@ -31,15 +36,27 @@ func NewScheduleHandler(
 // CreateScheduleRequest represents the request body for creating a schedule
 type CreateScheduleRequest struct {
 	DoctorID     string `json:"doctor_id"`
+	LocationID   string `json:"location_id"` // Optional when the doctor works at a single location
 	DayOfWeek    string `json:"day_of_week"`
 	StartTime    string `json:"start_time"`
 	EndTime      string `json:"end_time"`
 	SlotDuration int    `json:"slot_duration"`
 }
 
+// UpdateScheduleRequest represents the request body for updating a schedule
+type UpdateScheduleRequest struct {
+	DoctorID     string `json:"doctor_id"`
+	LocationID   string `json:"location_id"` // Optional, keeps the current location when empty
+	DayOfWeek    string `json:"day_of_week"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	SlotDuration int    `json:"slot_duration"`
+	IsActive     bool   `json:"is_active"`
+}
+
 // CreateSchedule godoc
 // @Summary      Crear horario de doctor
-// @Description  Crear horario personalizado para un doctor (solo admin)
+// @Description  Crear horario personalizado para un doctor (permiso schedules:write)
 // @Tags         Schedules
 // @Accept       json
 // @Produce      json
@@ -80,10 +97,11 @@ func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Create schedule
-	ctx := context.Background()
+	ctx := r.Context()
 	schedule, err := h.createScheduleUC.Execute(
 		ctx,
 		req.DoctorID,
+		req.LocationID,
 		req.DayOfWeek,
 		req.StartTime,
 		req.EndTime,
@@ -102,12 +120,17 @@ func (h *ScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request)
 
 // GetDoctorSchedules godoc
 // @Summary      Obtener horarios de un doctor
-// @Description  Retorna todos los horarios configurados para un doctor
+// @Description  Retorna una página de los horarios activos de un doctor
 // @Tags         Schedules
 // @Accept       json
 // @Produce      json
-// @Param        id  path  string  true  "ID del doctor"
-// @Success      200  {array}   dto.ScheduleResponse
+// @Param        id      path      string  true   "ID del doctor"
+// @Param        cursor  query     string  false  "next_cursor de la página anterior"
+// @Param        limit   query     int     false  "Cantidad máxima (por defecto 20, máximo 100)"
+// @Param        sort    query     string  false  "Orden: day (por defecto, lunes primero) o created_at"
+// @Param        order   query     string  false  "asc o desc (por defecto asc para day, desc para created_at)"
+// @Success      200  {object}  schedule.DoctorSchedulesResponse
+// @Failure      400  {object}  dto.ErrorResponse
 // @Failure      404  {object}  dto.ErrorResponse
 // @Router       /api/schedules/doctor/{id} [get]
 func (h *ScheduleHandler) GetDoctorSchedules(w http.ResponseWriter, r *http.Request) {
@@ -119,9 +142,12 @@ func (h *ScheduleHandler) GetDoctorSchedules(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Get schedules
-	ctx := context.Background()
-	schedules, err := h.getSchedulesUC.Execute(ctx, doctorID)
+	ctx := r.Context()
+	schedules, err := h.getSchedulesUC.Execute(ctx, doctorID, pageRequest(r))
 	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -132,6 +158,107 @@ func (h *ScheduleHandler) GetDoctorSchedules(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(schedules)
 }
 
+// GetSchedule godoc
+// @Summary      Obtener un horario
+// @Description  Retorna un horario, activo o no; el header ETag lleva su versión para enviarla en If-Match al editarlo
+// @Tags         Schedules
+// @Produce      json
+// @Param        id   path      string  true  "ID del horario"
+// @Success      200  {object}  dto.ScheduleResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/schedules/{id} [get]
+func (h *ScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	// Get schedule ID from URL path
+	scheduleID := r.PathValue("id")
+	if scheduleID == "" {
+		http.Error(w, "Schedule ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get schedule
+	ctx := r.Context()
+	schedule, err := h.getScheduleUC.Execute(ctx, scheduleID)
+	if err != nil {
+		if err.Error() == "schedule not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	setETag(w, schedule.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// UpdateSchedule handles PUT /api/schedules/{id} (admin only)
+// Honours If-Match with the ETag from a previous read and answers 412 if the schedule changed since
+func (h *ScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	// Get schedule ID from URL path
+	scheduleID := r.PathValue("id")
+	if scheduleID == "" {
+		http.Error(w, "Schedule ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Parse request
+	var req UpdateScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate required fields
+	if req.DoctorID == "" {
+		http.Error(w, "doctor_id is required", http.StatusBadRequest)
+		return
+	}
+	if req.SlotDuration <= 0 {
+		http.Error(w, "slot_duration must be greater than 0", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Update schedule
+	ctx := r.Context()
+	schedule, err := h.updateScheduleUC.Execute(
+		ctx,
+		scheduleID,
+		req.DoctorID,
+		req.LocationID,
+		req.DayOfWeek,
+		req.StartTime,
+		req.EndTime,
+		req.SlotDuration,
+		req.IsActive,
+		expectedVersion,
+	)
+	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
+		if err.Error() == "schedule not found" || err.Error() == "doctor not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Return success response
+	setETag(w, schedule.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(schedule)
+}
+
 // DeleteSchedule handles DELETE /api/schedules/{id} (admin only)
 func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
 	// Get schedule ID from URL path
@@ -142,7 +269,7 @@ func (h *ScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Delete schedule
-	ctx := context.Background()
+	ctx := r.Context()
 	if err := h.deleteScheduleUC.Execute(ctx, scheduleID); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
 	"net/http"
 	"time"
@@ -13,31 +12,46 @@ import (
 type ServiceHandler struct {
 	createServiceUC         *service.CreateServiceUseCase
 	listServicesUC          *service.ListServicesUseCase
+	getServiceUC            *service.GetServiceUseCase
 	assignServiceToDoctorUC *service.AssignServiceToDoctorUseCase
 	getDoctorsByServiceUC   *service.GetDoctorsByServiceUseCase
 	getAvailableSlotsUC     *service.GetAvailableSlotsUseCase
+	updateServiceUC         *service.UpdateServiceUseCase
+	deleteServiceUC         *service.DeleteServiceUseCase
+	listDeletedUC           *service.ListDeletedServicesUseCase
+	restoreServiceUC        *service.RestoreServiceUseCase
 }
 
 // NewServiceHandler creates a new instance of ServiceHandler
 func NewServiceHandler(
 	createServiceUC *service.CreateServiceUseCase,
 	listServicesUC *service.ListServicesUseCase,
+	getServiceUC *service.GetServiceUseCase,
 	assignServiceToDoctorUC *service.AssignServiceToDoctorUseCase,
 	getDoctorsByServiceUC *service.GetDoctorsByServiceUseCase,
 	getAvailableSlotsUC *service.GetAvailableSlotsUseCase,
+	updateServiceUC *service.UpdateServiceUseCase,
+	deleteServiceUC *service.DeleteServiceUseCase,
+	listDeletedUC *service.ListDeletedServicesUseCase,
+	restoreServiceUC *service.RestoreServiceUseCase,
 ) *ServiceHandler {
 	return &ServiceHandler{
 		createServiceUC:         createServiceUC,
 		listServicesUC:          listServicesUC,
+		getServiceUC:            getServiceUC,
 		assignServiceToDoctorUC: assignServiceToDoctorUC,
 		getDoctorsByServiceUC:   getDoctorsByServiceUC,
 		getAvailableSlotsUC:     getAvailableSlotsUC,
+		updateServiceUC:         updateServiceUC,
+		deleteServiceUC:         deleteServiceUC,
+		listDeletedUC:           listDeletedUC,
+		restoreServiceUC:        restoreServiceUC,
 	}
 }
 
 // CreateService godoc
 // @Summary      Crear servicio
-// @Description  Crear un nuevo servicio médico (solo admin)
+// @Description  Crear un nuevo servicio médico (permiso services:write)
 // @Tags         Services
 // @Accept       json
 // @Produce      json
@@ -62,7 +76,7 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	response, err := h.createServiceUC.Execute(ctx, req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -77,11 +91,17 @@ func (h *ServiceHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 // ListServices godoc
 // @Summary      Listar servicios activos
-// @Description  Retorna todos los servicios médicos disponibles
+// @Description  Retorna una página de los servicios médicos disponibles
 // @Tags         Services
 // @Accept       json
 // @Produce      json
-// @Success      200  {array}   dto.ServiceResponse
+// @Param        cursor  query     string  false  "next_cursor de la página anterior"
+// @Param        limit   query     int     false  "Cantidad máxima (por defecto 20, máximo 100)"
+// @Param        sort    query     string  false  "Orden: name (por defecto) o created_at"
+// @Param        order   query     string  false  "asc o desc (por defecto asc para name, desc para created_at)"
+// @Param        location_id  query  string  false  "Muestra los precios de esta sede"
+// @Success      200  {object}  service.ListServicesResponse
+// @Failure      400  {object}  dto.ErrorResponse
 // @Router       /api/services [get]
 func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is GET
@@ -91,9 +111,19 @@ func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute use case
-	ctx := context.Background()
-	services, err := h.listServicesUC.Execute(ctx)
+	ctx := r.Context()
+	services, err := h.listServicesUC.Execute(ctx, service.ListServicesRequest{
+		Request:    pageRequest(r),
+		LocationID: r.URL.Query().Get("location_id"),
+	})
 	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
+		if err.Error() == "location not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -104,6 +134,46 @@ func (h *ServiceHandler) List(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(services)
 }
 
+// GetService godoc
+// @Summary      Obtener un servicio
+// @Description  Retorna un servicio, activo o no, con su precio propio; el header ETag lleva su versión para enviarla en If-Match al editarlo
+// @Tags         Services
+// @Produce      json
+// @Param        id   query     string  true  "ID del servicio"
+// @Success      200  {object}  dto.ServiceResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/services/get [get]
+func (h *ServiceHandler) Get(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	response, err := h.getServiceUC.Execute(ctx, r.URL.Query().Get("id"))
+	if err != nil {
+		if err.Error() == "service not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err.Error() == "service ID is required" {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	setETag(w, response.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // AssignToDoctor handles the HTTP request for assigning a service to a doctor
 // Method: POST
 // Requires: Admin role
@@ -124,7 +194,7 @@ func (h *ServiceHandler) AssignToDoctor(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	err := h.assignServiceToDoctorUC.Execute(ctx, req.DoctorID, req.ServiceID)
 	if err != nil {
 		// Handle specific error cases
@@ -168,7 +238,7 @@ func (h *ServiceHandler) GetDoctorsByService(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	doctors, err := h.getDoctorsByServiceUC.Execute(ctx, serviceID)
 	if err != nil {
 		if err.Error() == "service not found" {
@@ -194,6 +264,7 @@ func (h *ServiceHandler) GetDoctorsByService(w http.ResponseWriter, r *http.Requ
 // @Param        doctor_id   query     string  true  "ID del usuario doctor"
 // @Param        service_id  query     string  true  "ID del servicio"
 // @Param        date        query     string  true  "Fecha en formato YYYY-MM-DD"
+// @Param        location_id query     string  false "Solo los horarios de esta sede"
 // @Success      200  {array}   dto.TimeSlot
 // @Failure      400  {object}  dto.ErrorResponse
 // @Router       /api/services/available-slots [get]
@@ -231,8 +302,8 @@ func (h *ServiceHandler) GetAvailableSlots(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Get available slots
-	ctx := context.Background()
-	slots, err := h.getAvailableSlotsUC.Execute(ctx, doctorID, serviceID, date)
+	ctx := r.Context()
+	slots, err := h.getAvailableSlotsUC.Execute(ctx, doctorID, serviceID, r.URL.Query().Get("location_id"), date)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -243,3 +314,159 @@ func (h *ServiceHandler) GetAvailableSlots(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(slots)
 }
+
+// Update handles the HTTP request for changing a service
+// Method: PUT
+// Requires: Admin role
+// Query parameter: id
+// Request body: JSON with the fields to change
+// Optional header: If-Match with the ETag from a previous read
+// Response: 200 OK with the updated service, 412 if the service changed since
+func (h *ServiceHandler) Update(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is PUT
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get service ID from query parameter
+	serviceID := r.URL.Query().Get("id")
+	if serviceID == "" {
+		http.Error(w, "id query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	// Decode request body
+	var req service.UpdateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	updated, err := h.updateServiceUC.Execute(ctx, serviceID, req, expectedVersion)
+	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
+		if err.Error() == "service not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	setETag(w, updated.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// Delete handles the HTTP request for soft deleting a service
+// Method: DELETE
+// Requires: Admin role
+// Query parameter: id
+// Response: 204 No Content on success
+func (h *ServiceHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is DELETE
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get service ID from query parameter
+	serviceID := r.URL.Query().Get("id")
+	if serviceID == "" {
+		http.Error(w, "id query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	if err := h.deleteServiceUC.Execute(ctx, serviceID); err != nil {
+		if err.Error() == "service not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err.Error() == "cannot delete service with assigned doctors" {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response (204 No Content)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListDeleted handles the HTTP request for listing soft deleted services
+// Method: GET
+// Requires: Admin role
+// Response: 200 OK with array of deleted services
+func (h *ServiceHandler) ListDeleted(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	services, err := h.listDeletedUC.Execute(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(services)
+}
+
+// Restore handles the HTTP request for restoring a soft deleted service
+// Method: POST
+// Requires: Admin role
+// Query parameter: id
+// Response: 200 OK on success
+func (h *ServiceHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get service ID from query parameter
+	serviceID := r.URL.Query().Get("id")
+	if serviceID == "" {
+		http.Error(w, "id query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	if err := h.restoreServiceUC.Execute(ctx, serviceID); err != nil {
+		if err.Error() == "service not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Service restored successfully",
+	})
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/auth"
+)
+
+// SessionHandler handles HTTP requests for the sessions of users: the devices
+// they are logged in on, signing out one or all of them, and admin sign-outs
+type SessionHandler struct {
+	listUC    *auth.ListSessionsUseCase
+	endUC     *auth.EndSessionUseCase
+	endAllUC  *auth.EndAllSessionsUseCase
+	endUserUC *auth.EndUserSessionsUseCase
+}
+
+// NewSessionHandler creates a new session handler
+func NewSessionHandler(
+	listUC *auth.ListSessionsUseCase,
+	endUC *auth.EndSessionUseCase,
+	endAllUC *auth.EndAllSessionsUseCase,
+	endUserUC *auth.EndUserSessionsUseCase,
+) *SessionHandler {
+	return &SessionHandler{
+		listUC:    listUC,
+		endUC:     endUC,
+		endAllUC:  endAllUC,
+		endUserUC: endUserUC,
+	}
+}
+
+// List godoc
+// @Summary      Mis sesiones activas
+// @Description  Lista los dispositivos con la sesión iniciada: navegador o app (user agent), IP desde la que se inició, fecha de inicio y de la última actividad. current marca la sesión de esta petición
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   dto.SessionResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Router       /api/users/me/sessions [get]
+func (h *SessionHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	sessionID, _ := r.Context().Value(requestctx.SessionIDKey).(string)
+
+	sessions, err := h.listUC.Execute(r.Context(), userID, sessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// End godoc
+// @Summary      Cerrar una de mis sesiones
+// @Description  Cierra la sesión de un dispositivo: su refresh token deja de servir y sus tokens de acceso se rechazan desde la siguiente petición. Cerrar la sesión actual equivale a hacer logout
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID de la sesión"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/users/me/sessions/{id} [delete]
+func (h *SessionHandler) End(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.endUC.Execute(r.Context(), userID, r.PathValue("id")); err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Session has been ended",
+	})
+}
+
+// EndAll godoc
+// @Summary      Cerrar sesión en todos los dispositivos
+// @Description  Cierra todas las sesiones del usuario, incluida la de esta petición. Con keep_current=true conserva la sesión actual y cierra las demás
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        keep_current  query     bool  false  "Conservar la sesión actual"
+// @Success      200  {object}  dto.EndSessionsResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Router       /api/users/me/sessions [delete]
+func (h *SessionHandler) EndAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+	keepSessionID := ""
+	if r.URL.Query().Get("keep_current") == "true" {
+		keepSessionID, _ = r.Context().Value(requestctx.SessionIDKey).(string)
+	}
+
+	ended, err := h.endAllUC.Execute(r.Context(), userID, keepSessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	writeEndedSessions(w, ended)
+}
+
+// ListUser godoc
+// @Summary      Sesiones activas de un usuario
+// @Description  Lista los dispositivos con la sesión iniciada de un usuario de la clínica, incluidos los usuarios desactivados
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID del usuario"
+// @Success      200  {array}   dto.SessionResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/users/{id}/sessions [get]
+func (h *SessionHandler) ListUser(w http.ResponseWriter, r *http.Request) {
+	sessionID, _ := r.Context().Value(requestctx.SessionIDKey).(string)
+
+	sessions, err := h.listUC.Execute(r.Context(), r.PathValue("id"), sessionID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sessions)
+}
+
+// EndUser godoc
+// @Summary      Cerrar todas las sesiones de un usuario
+// @Description  Cierra la sesión del usuario en todos sus dispositivos. Las cuentas desactivadas ya no pueden usar sus tokens, pero sus sesiones volverían a funcionar si se reactivaran; cerrarlas obliga a iniciar sesión de nuevo
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID del usuario"
+// @Success      200  {object}  dto.EndSessionsResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/users/{id}/sessions [delete]
+func (h *SessionHandler) EndUser(w http.ResponseWriter, r *http.Request) {
+	ended, err := h.endUserUC.Execute(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	writeEndedSessions(w, ended)
+}
+
+// writeEndedSessions responds with how many sessions were ended
+func writeEndedSessions(w http.ResponseWriter, ended int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Sessions have been ended",
+		"ended":   ended,
+	})
+}
+
+// writeSessionError maps the errors of the session use cases to status codes
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "user ID is required", "session ID is required":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case "only administrators can end the sessions of an administrator":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case "user not found", "session not found":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
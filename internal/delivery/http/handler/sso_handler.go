@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"version-1-0/internal/usecase/auth"
+)
+
+// ssoStateCookie holds the ID of the state of the single sign-on flow the
+// browser started, so the callback only completes flows of the same browser
+const ssoStateCookie = "sso_state"
+
+// ssoCookiePath limits the state cookie to the single sign-on routes
+const ssoCookiePath = "/api/auth/sso"
+
+// SSOHandler handles HTTP requests of the single sign-on of staff
+type SSOHandler struct {
+	startUC    *auth.StartSSOUseCase
+	completeUC *auth.CompleteSSOUseCase
+	unlinkUC   *auth.UnlinkSSOUseCase
+}
+
+// NewSSOHandler creates a new SSO handler
+func NewSSOHandler(startUC *auth.StartSSOUseCase, completeUC *auth.CompleteSSOUseCase, unlinkUC *auth.UnlinkSSOUseCase) *SSOHandler {
+	return &SSOHandler{
+		startUC:    startUC,
+		completeUC: completeUC,
+		unlinkUC:   unlinkUC,
+	}
+}
+
+// Start godoc
+// @Summary      Iniciar sesión con el proveedor de identidad
+// @Description  Inicio de sesión único (OpenID Connect) para el personal. Retorna la dirección de la página de login del proveedor y el state del flujo; el cliente guarda el state, abre la dirección y, cuando el proveedor vuelve a OIDC_REDIRECT_URL, envía el code y el state a /api/auth/sso/callback. También fija la cookie sso_state (HttpOnly, SameSite=Lax) que liga el flujo al navegador; el cliente debe enviar las cookies (credentials: include) en las dos peticiones. El flujo vale 10 minutos y queda en la clínica de la petición
+// @Tags         Authentication
+// @Produce      json
+// @Success      200  {object}  dto.SSOStartResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      502  {object}  dto.ErrorResponse
+// @Router       /api/auth/sso/start [get]
+func (h *SSOHandler) Start(w http.ResponseWriter, r *http.Request) {
+	response, err := h.startUC.Execute(r.Context())
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Value:    response.StateID,
+		Path:     ssoCookiePath,
+		Expires:  response.ExpiresAt,
+		HttpOnly: true,
+		Secure:   secureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Callback godoc
+// @Summary      Completar el inicio de sesión único
+// @Description  Canjea el code que envió el proveedor por un token de acceso y un refresh token, como el login. La primera vez vincula la identidad del proveedor con la cuenta del personal que tiene el mismo email, si el proveedor lo verificó; después la cuenta se encuentra por la identidad. Los grupos o roles del claim OIDC_ROLE_CLAIM deben otorgar el rol de la cuenta. Los pacientes no pueden usarlo y la verificación en dos pasos queda a cargo del proveedor. El state solo vale una vez y en el navegador que inició el flujo (cookie sso_state)
+// @Tags         Authentication
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.SSOCallbackRequest  true  "Code y state devueltos por el proveedor"
+// @Success      200  {object}  dto.LoginResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/auth/sso/callback [post]
+func (h *SSOHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req auth.SSOCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var browserStateID string
+	if cookie, err := r.Cookie(ssoStateCookie); err == nil {
+		browserStateID = cookie.Value
+	}
+	// The state can only be sent once, so the cookie is no longer needed
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoStateCookie,
+		Path:     ssoCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secureRequest(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	response, err := h.completeUC.Execute(r.Context(), req, browserStateID)
+	if err != nil {
+		writeSSOError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Unlink godoc
+// @Summary      Desvincular la identidad del proveedor de un usuario
+// @Description  Quita el vínculo entre un usuario y su identidad en el proveedor, por ejemplo si la persona tiene una cuenta nueva allí. En su próximo inicio de sesión único la cuenta se vuelve a vincular por email
+// @Tags         Users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID del usuario"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/users/{id}/sso [delete]
+func (h *SSOHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	if err := h.unlinkUC.Execute(r.Context(), r.PathValue("id")); err != nil {
+		writeSSOError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Single sign-on identity has been unlinked",
+	})
+}
+
+// writeSSOError maps the errors of the single sign-on use cases to status codes
+func writeSSOError(w http.ResponseWriter, err error) {
+	switch err.Error() {
+	case "code is required", "state is required", "invalid or expired single sign-on state", "single sign-on was started in another browser", "user ID is required":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case "single sign-on could not be completed", "user is inactive":
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case "the provider has not verified this email", "no staff account for this email", "single sign-on is only available for staff accounts",
+		"the provider does not grant the role of this account", "this account is linked to another identity of the provider",
+		"only administrators can unlink the identity of an administrator":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case "single sign-on is not configured", "user not found", "user has no single sign-on identity":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case "single sign-on provider is unavailable":
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// secureRequest reports whether the client reached the API over HTTPS, directly
+// or through a proxy that terminates TLS
+func secureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/auth"
+)
+
+// TwoFactorHandler handles HTTP requests for the TOTP second factor of staff
+// accounts: the second step of a login, self-service management and admin resets
+type TwoFactorHandler struct {
+	setupUC      *auth.SetupTwoFactorUseCase
+	verifyUC     *auth.VerifyTwoFactorUseCase
+	statusUC     *auth.GetTwoFactorStatusUseCase
+	enrollUC     *auth.EnrollTwoFactorUseCase
+	confirmUC    *auth.ConfirmTwoFactorUseCase
+	regenerateUC *auth.RegenerateRecoveryCodesUseCase
+	disableUC    *auth.DisableTwoFactorUseCase
+	resetUC      *auth.ResetTwoFactorUseCase
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(
+	setupUC *auth.SetupTwoFactorUseCase,
+	verifyUC *auth.VerifyTwoFactorUseCase,
+	statusUC *auth.GetTwoFactorStatusUseCase,
+	enrollUC *auth.EnrollTwoFactorUseCase,
+	confirmUC *auth.ConfirmTwoFactorUseCase,
+	regenerateUC *auth.RegenerateRecoveryCodesUseCase,
+	disableUC *auth.DisableTwoFactorUseCase,
+	resetUC *auth.ResetTwoFactorUseCase,
+) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		setupUC:      setupUC,
+		verifyUC:     verifyUC,
+		statusUC:     statusUC,
+		enrollUC:     enrollUC,
+		confirmUC:    confirmUC,
+		regenerateUC: regenerateUC,
+		disableUC:    disableUC,
+		resetUC:      resetUC,
+	}
+}
+
+// Setup godoc
+// @Summary      Configurar verificación en dos pasos al iniciar sesión
+// @Description  Cuando el login responde setup_required, genera el secreto TOTP del usuario con el challenge_token recibido. otpauth_uri se muestra como código QR para la app autenticadora; el login termina en /api/auth/2fa/verify con el primer código
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.TwoFactorSetupRequest  true  "Challenge del login"
+// @Success      200  {object}  dto.TwoFactorSetupResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Router       /api/auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	var req auth.TwoFactorSetupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.setupUC.Execute(r.Context(), req)
+	if err != nil {
+		writeTwoFactorError(w, err, 0)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Verify godoc
+// @Summary      Segundo paso del login
+// @Description  Canjea el challenge_token del login y un código de la app autenticadora (o un código de recuperación en recovery_code) por el token de acceso y el refresh token. Si el challenge era de configuración, activa la verificación en dos pasos y la respuesta incluye los códigos de recuperación, que solo se muestran esta vez. Los códigos erróneos cuentan como intentos fallidos de login
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.VerifyTwoFactorRequest  true  "Challenge del login y código"
+// @Success      200  {object}  dto.LoginResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      429  {object}  dto.ErrorResponse
+// @Router       /api/auth/2fa/verify [post]
+func (h *TwoFactorHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	var req auth.VerifyTwoFactorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, wait, err := h.verifyUC.Execute(r.Context(), req)
+	if err != nil {
+		writeTwoFactorError(w, err, wait)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Status godoc
+// @Summary      Estado de mi verificación en dos pasos
+// @Description  Indica si la cuenta tiene la verificación en dos pasos activa, si su rol la exige y cuántos códigos de recuperación le quedan
+// @Tags         TwoFactor
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.TwoFactorStatusResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Router       /api/users/me/2fa [get]
+func (h *TwoFactorHandler) Status(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.statusUC.Execute(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(w, err, 0)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Enroll godoc
+// @Summary      Activar verificación en dos pasos
+// @Description  Genera un nuevo secreto TOTP para la cuenta, reemplazando uno que nunca se confirmó. Solo para cuentas del personal. No queda activa hasta confirmarla con un primer código en /api/users/me/2fa/confirm
+// @Tags         TwoFactor
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  dto.TwoFactorSetupResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Router       /api/users/me/2fa [post]
+func (h *TwoFactorHandler) Enroll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.enrollUC.Execute(r.Context(), userID)
+	if err != nil {
+		writeTwoFactorError(w, err, 0)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Confirm godoc
+// @Summary      Confirmar verificación en dos pasos
+// @Description  Activa el secreto generado con un primer código de la app autenticadora y devuelve los códigos de recuperación, que solo se muestran esta vez
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      dto.TwoFactorCodeRequest  true  "Código de la app autenticadora"
+// @Success      200  {object}  dto.RecoveryCodesResponse
+// @Failure      400  {object}  dto.ErrorResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      409  {object}  dto.ErrorResponse
+// @Failure      429  {object}  dto.ErrorResponse
+// @Router       /api/users/me/2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req auth.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, wait, err := h.confirmUC.Execute(r.Context(), userID, req)
+	if err != nil {
+		writeTwoFactorError(w, err, wait)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerar códigos de recuperación
+// @Description  Reemplaza los códigos de recuperación por un juego nuevo, que solo se muestra esta vez. Los anteriores dejan de servir. Requiere un código de la app autenticadora
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      dto.TwoFactorCodeRequest  true  "Código de la app autenticadora"
+// @Success      200  {object}  dto.RecoveryCodesResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      429  {object}  dto.ErrorResponse
+// @Router       /api/users/me/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req auth.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	response, wait, err := h.regenerateUC.Execute(r.Context(), userID, req)
+	if err != nil {
+		writeTwoFactorError(w, err, wait)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// Disable godoc
+// @Summary      Desactivar verificación en dos pasos
+// @Description  Quita la verificación en dos pasos y los códigos de recuperación de la cuenta. Requiere un código de la app autenticadora o un código de recuperación. Los roles que la exigen no pueden desactivarla
+// @Tags         TwoFactor
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        body  body      dto.TwoFactorCodeRequest  true  "Código de la app autenticadora o de recuperación"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      401  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Failure      429  {object}  dto.ErrorResponse
+// @Router       /api/users/me/2fa [delete]
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
+		return
+	}
+
+	var req auth.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if wait, err := h.disableUC.Execute(r.Context(), userID, req); err != nil {
+		writeTwoFactorError(w, err, wait)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Two-factor authentication has been disabled",
+	})
+}
+
+// Reset godoc
+// @Summary      Restablecer verificación en dos pasos de un usuario
+// @Description  Quita la verificación en dos pasos de un usuario que perdió su app autenticadora y sus códigos de recuperación. Si su rol la exige, deberá configurarla de nuevo en su próximo login
+// @Tags         TwoFactor
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "ID del usuario"
+// @Success      200  {object}  dto.MessageResponse
+// @Failure      404  {object}  dto.ErrorResponse
+// @Router       /api/users/{id}/2fa [delete]
+func (h *TwoFactorHandler) Reset(w http.ResponseWriter, r *http.Request) {
+	if err := h.resetUC.Execute(r.Context(), r.PathValue("id")); err != nil {
+		writeTwoFactorError(w, err, 0)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Two-factor authentication has been reset",
+	})
+}
+
+// writeTwoFactorError maps the errors of the two-factor use cases to status codes
+// Refused codes get 429 with Retry-After, like refused logins
+func writeTwoFactorError(w http.ResponseWriter, err error, wait time.Duration) {
+	switch err.Error() {
+	case "too many failed logins from this address", "account is temporarily locked", "too many failed logins, try again later":
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case "invalid or expired two-factor challenge", "invalid two-factor code":
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case "two-factor authentication is only available for staff accounts", "two-factor authentication is required for your role",
+		"only administrators can reset the second factor of an administrator":
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case "user not found", "two-factor authentication is not enabled":
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case "two-factor authentication is already enabled":
+		http.Error(w, err.Error(), http.StatusConflict)
+	case "challenge token is required", "code is required", "user ID is required", "two-factor enrollment has not been started":
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
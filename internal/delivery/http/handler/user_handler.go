@@ -1,51 +1,56 @@
 package handler
 
 import (
-	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strings"
 
-	"version-1-0/internal/delivery/http/middleware"
+	"version-1-0/internal/requestctx"
 	"version-1-0/internal/usecase/user"
 )
 
 // UserHandler handles HTTP requests related to user operations
 type UserHandler struct {
-	createUserUC *user.CreateUserUseCase
-	getUserUC    *user.GetUserUseCase
-	listUsersUC  *user.ListUsersUseCase
-	updateUserUC *user.UpdateUserUseCase
-	deleteUserUC *user.DeleteUserUseCase
+	registerUC    *user.RegisterPatientUseCase
+	getUserUC     *user.GetUserUseCase
+	listUsersUC   *user.ListUsersUseCase
+	updateUserUC  *user.UpdateUserUseCase
+	deleteUserUC  *user.DeleteUserUseCase
+	listDeletedUC *user.ListDeletedUsersUseCase
+	restoreUserUC *user.RestoreUserUseCase
 }
 
 // NewUserHandler creates a new instance of UserHandler
 func NewUserHandler(
-	createUserUC *user.CreateUserUseCase,
+	registerUC *user.RegisterPatientUseCase,
 	getUserUC *user.GetUserUseCase,
 	listUsersUC *user.ListUsersUseCase,
 	updateUserUC *user.UpdateUserUseCase,
 	deleteUserUC *user.DeleteUserUseCase,
+	listDeletedUC *user.ListDeletedUsersUseCase,
+	restoreUserUC *user.RestoreUserUseCase,
 ) *UserHandler {
 	return &UserHandler{
-		createUserUC: createUserUC,
-		getUserUC:    getUserUC,
-		listUsersUC:  listUsersUC,
-		updateUserUC: updateUserUC,
-		deleteUserUC: deleteUserUC,
+		registerUC:    registerUC,
+		getUserUC:     getUserUC,
+		listUsersUC:   listUsersUC,
+		updateUserUC:  updateUserUC,
+		deleteUserUC:  deleteUserUC,
+		listDeletedUC: listDeletedUC,
+		restoreUserUC: restoreUserUC,
 	}
 }
 
 // CreateUser godoc
-// @Summary      Crear usuario
-// @Description  Crear un nuevo usuario (admin, doctor o patient)
+// @Summary      Registrar paciente
+// @Description  Registro público de pacientes. El rol puede omitirse; cualquier rol distinto de patient responde 403, ya que doctores y administradores se crean aceptando una invitación (ver /api/invitations)
 // @Tags         Users
 // @Accept       json
 // @Produce      json
 // @Param        user  body      dto.CreateUserRequest  true  "Datos del usuario"
 // @Success      201  {object}  dto.UserResponse
 // @Failure      400  {object}  dto.ErrorResponse
+// @Failure      403  {object}  dto.ErrorResponse
 // @Router       /api/users [post]
 func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is POST
@@ -62,9 +67,13 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.createUserUC.Execute(ctx, req)
+	ctx := r.Context()
+	response, err := h.registerUC.Execute(ctx, req)
 	if err != nil {
+		if err.Error() == "public signup is limited to patient accounts" {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -94,7 +103,7 @@ func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	response, err := h.getUserUC.Execute(ctx, id)
 	if err != nil {
 		// Check if error is "user not found"
@@ -107,6 +116,7 @@ func (h *UserHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return success response
+	setETag(w, response.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -130,14 +140,14 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user ID from context (set by auth middleware)
-	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	userID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	response, err := h.getUserUC.Execute(ctx, userID)
 	if err != nil {
 		if err.Error() == "user not found" {
@@ -149,6 +159,7 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return success response
+	setETag(w, response.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -157,8 +168,9 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 // List handles the HTTP request for listing all users with pagination
 // Method: GET
 // Requires: JWT token with admin role
-// Query params: limit (optional, default 20), offset (optional, default 0)
-// Response: 200 OK with paginated users list
+// Query params: cursor (next_cursor of the previous page), limit (optional, default 20, max 100),
+// sort (created_at or name), order (asc or desc)
+// Response: 200 OK with one page of users and next_cursor
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is GET
 	if r.Method != http.MethodGet {
@@ -166,32 +178,16 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters for pagination
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	var limit, offset int
-
-	// Parse limit (default 20)
-	if limitStr != "" {
-		fmt.Sscanf(limitStr, "%d", &limit)
-	}
-
-	// Parse offset (default 0)
-	if offsetStr != "" {
-		fmt.Sscanf(offsetStr, "%d", &offset)
-	}
-
-	// Create request
-	req := user.ListUsersRequest{
-		Limit:  limit,
-		Offset: offset,
-	}
+	// Create request from the pagination query parameters
+	req := user.ListUsersRequest{Request: pageRequest(r)}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	response, err := h.listUsersUC.Execute(ctx, req)
 	if err != nil {
+		if writePageError(w, err) {
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -206,7 +202,8 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 // Method: PUT
 // Requires: JWT token (admin can update anyone, users can update themselves)
 // URL parameter: id in path
-// Response: 200 OK with updated user data
+// Optional header: If-Match with the ETag from a previous read
+// Response: 200 OK with updated user data, 412 if the user changed since
 func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Verify HTTP method is PUT
 	if r.Method != http.MethodPut {
@@ -223,13 +220,13 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get authenticated user info from context
-	authenticatedUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
-	authenticatedUserRole, ok := r.Context().Value(middleware.RoleKey).(string)
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
 	if !ok {
 		http.Error(w, "Role not found in context", http.StatusUnauthorized)
 		return
@@ -242,10 +239,18 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
 	// Execute use case
-	ctx := context.Background()
-	response, err := h.updateUserUC.Execute(ctx, userID, authenticatedUserID, authenticatedUserRole, req)
+	ctx := r.Context()
+	response, err := h.updateUserUC.Execute(ctx, userID, authenticatedUserID, authenticatedUserRole, req, expectedVersion)
 	if err != nil {
+		if writeVersionConflict(w, r, err) {
+			return
+		}
 		if err.Error() == "insufficient permissions to update this user" {
 			http.Error(w, err.Error(), http.StatusForbidden)
 			return
@@ -259,12 +264,13 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return success response
+	setETag(w, response.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
 
-// Delete handles the HTTP request for deleting (deactivating) a user
+// Delete handles the HTTP request for soft deleting a user
 // Method: DELETE
 // Requires: JWT token with admin role
 // URL parameter: id in path
@@ -284,20 +290,20 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get authenticated user info from context
-	authenticatedUserID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	authenticatedUserID, ok := r.Context().Value(requestctx.UserIDKey).(string)
 	if !ok {
 		http.Error(w, "User ID not found in context", http.StatusUnauthorized)
 		return
 	}
 
-	authenticatedUserRole, ok := r.Context().Value(middleware.RoleKey).(string)
+	authenticatedUserRole, ok := r.Context().Value(requestctx.RoleKey).(string)
 	if !ok {
 		http.Error(w, "Role not found in context", http.StatusUnauthorized)
 		return
 	}
 
 	// Execute use case
-	ctx := context.Background()
+	ctx := r.Context()
 	err := h.deleteUserUC.Execute(ctx, userID, authenticatedUserID, authenticatedUserRole)
 	if err != nil {
 		if err.Error() == "only administrators can delete users" {
@@ -312,10 +318,6 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		if err.Error() == "user is already inactive" {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -323,3 +325,66 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	// Return success response (204 No Content)
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// ListDeleted handles the HTTP request for listing soft deleted users
+// Method: GET
+// Requires: JWT token with admin role
+// Response: 200 OK with array of deleted users
+func (h *UserHandler) ListDeleted(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is GET
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	users, err := h.listDeletedUC.Execute(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(users)
+}
+
+// Restore handles the HTTP request for restoring a soft deleted user
+// Method: POST
+// Requires: JWT token with admin role
+// Query parameter: id
+// Response: 200 OK on success
+func (h *UserHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	// Verify HTTP method is POST
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user ID from query parameter
+	userID := r.URL.Query().Get("id")
+	if userID == "" {
+		http.Error(w, "User ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Execute use case
+	ctx := r.Context()
+	if err := h.restoreUserUC.Execute(ctx, userID); err != nil {
+		if err.Error() == "user not found" {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return success response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "User restored successfully",
+	})
+}
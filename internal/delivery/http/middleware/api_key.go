@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+)
+
+// APIKeyHeader carries the API key of requests made by integrations
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves the key sent in a request and records its use;
+// implemented by apikey.AuthenticateAPIKeyUseCase
+type APIKeyAuthenticator interface {
+	Execute(ctx context.Context, key string) (*domain.APIKey, error)
+}
+
+// Authenticate accepts either a Bearer token or an API key
+// Requests with an X-API-Key header act as the key: its ID is the user ID, its
+// role is domain.APIKeyRole and what it may do comes from its scopes, so it
+// should guard routes that check permissions. Any other request goes through
+// AuthMiddleware
+func Authenticate(tokens TokenParser, sessions SessionValidator, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		withToken := AuthMiddleware(tokens, sessions)(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawKey := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+			if rawKey == "" {
+				withToken.ServeHTTP(w, r)
+				return
+			}
+
+			// Keys are looked up before the clinic is known; the clinic of
+			// the key is the one the request is made against
+			ctx := r.Context()
+			key, err := apiKeys.Execute(ctx, rawKey)
+			if err != nil {
+				switch err.Error() {
+				case "invalid api key":
+					http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				case "api key has been revoked":
+					http.Error(w, "API key has been revoked", http.StatusUnauthorized)
+				case "api key has expired":
+					http.Error(w, "API key has expired", http.StatusUnauthorized)
+				case "api key creator is no longer active":
+					http.Error(w, "API key creator is no longer active", http.StatusUnauthorized)
+				default:
+					http.Error(w, "Failed to validate API key", http.StatusInternalServerError)
+				}
+				return
+			}
+
+			if scope, scoped := repository.ClinicScope(ctx); scoped && scope != key.ClinicID && r.Header.Get(ClinicHeader) != "" {
+				http.Error(w, "API key belongs to another clinic", http.StatusForbidden)
+				return
+			}
+			ctx = repository.WithClinic(ctx, key.ClinicID)
+
+			ctx = context.WithValue(ctx, requestctx.UserIDKey, key.ID)
+			ctx = context.WithValue(ctx, requestctx.RoleKey, domain.APIKeyRole)
+			ctx = context.WithValue(ctx, requestctx.APIKeyScopesKey, key.Scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -2,25 +2,31 @@ package middleware
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
-)
 
-// ContextKey is a custom type for context keys to avoid collisions
-type ContextKey string
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+)
 
-// UserIDKey is the context key for storing the authenticated user ID
-const UserIDKey ContextKey = "user_id"
+// SessionValidator confirms that the user and session behind a signed access
+// token are still allowed in; implemented by auth.ValidateSessionUseCase
+type SessionValidator interface {
+	Execute(ctx context.Context, userID, role, sessionID string) error
+}
 
-// RoleKey is the context key for storing the user role
-const RoleKey ContextKey = "user_role"
+// TokenParser verifies the signature of access tokens and returns them with
+// their claims as jwt.MapClaims; implemented by jwtkeys.KeySet
+type TokenParser interface {
+	Parse(tokenString string) (*jwt.Token, error)
+}
 
 // AuthMiddleware validates JWT tokens and adds user information to the request context
-// Requires a valid Bearer token in the Authorization header
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+// Requires a valid Bearer token in the Authorization header whose session has
+// not been revoked and whose user is still active with the same role
+func AuthMiddleware(tokens TokenParser, sessions SessionValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Read Authorization header
@@ -39,15 +45,8 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 		// Extract token (remove "Bearer " prefix)
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Verify signing method is HMAC
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
+		// Parse and validate token; the key set checks the signing method and key
+		token, err := tokens.Parse(tokenString)
 		if err != nil || !token.Valid {
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
@@ -74,9 +73,33 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 			return
 		}
 
-		// Add user_id and role to context and execute next handler
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-		ctx = context.WithValue(ctx, RoleKey, userRole)
+		// Tokens are only valid for the clinic their user belongs to; tokens
+		// issued before clinics existed carry no clinic and keep the request's
+		ctx := r.Context()
+		if clinicID, ok := claims["clinic_id"].(string); ok && clinicID != "" {
+			if scope, scoped := repository.ClinicScope(ctx); scoped && scope != clinicID && r.Header.Get(ClinicHeader) != "" {
+				http.Error(w, "Token belongs to another clinic", http.StatusForbidden)
+				return
+			}
+			ctx = repository.WithClinic(ctx, clinicID)
+		}
+
+		// Reject tokens of ended sessions and of users deleted, deactivated
+		// or given another role since the token was issued
+		sessionID, _ := claims["sid"].(string)
+		if err := sessions.Execute(ctx, userID, userRole, sessionID); err != nil {
+			if err.Error() == "token has been revoked" {
+				http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, "Failed to validate token", http.StatusInternalServerError)
+			return
+		}
+
+		// Add user_id, role and session to context and execute next handler
+		ctx = context.WithValue(ctx, requestctx.UserIDKey, userID)
+		ctx = context.WithValue(ctx, requestctx.RoleKey, userRole)
+		ctx = context.WithValue(ctx, requestctx.SessionIDKey, sessionID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 	}
@@ -88,7 +111,7 @@ func RequireRole(requiredRole string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get user role from context (set by previous middleware or modify AuthMiddleware)
-			role, ok := r.Context().Value(RoleKey).(string)
+			role, ok := r.Context().Value(requestctx.RoleKey).(string)
 			if !ok {
 				http.Error(w, "Role not found in context", http.StatusForbidden)
 				return
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/repository"
+)
+
+// ClinicHeader names the clinic a request is made against
+const ClinicHeader = "X-Clinic-ID"
+
+// ClinicMiddleware scopes every request to one clinic, see repository.WithClinic
+// The X-Clinic-ID header picks the clinic; without it the request runs against
+// defaultClinicID so single-clinic deployments need no changes. AuthMiddleware
+// narrows the scope further to the clinic of the token
+func ClinicMiddleware(clinicRepo repository.ClinicRepository, defaultClinicID string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			clinicID := strings.TrimSpace(r.Header.Get(ClinicHeader))
+			if clinicID == "" {
+				clinicID = defaultClinicID
+			} else {
+				if _, err := uuid.Parse(clinicID); err != nil {
+					http.Error(w, "Invalid "+ClinicHeader+" header", http.StatusBadRequest)
+					return
+				}
+
+				clinic, err := clinicRepo.FindClinicByID(r.Context(), clinicID)
+				if err != nil {
+					http.Error(w, "Failed to load clinic", http.StatusInternalServerError)
+					return
+				}
+				if clinic == nil {
+					http.Error(w, "Unknown clinic", http.StatusBadRequest)
+					return
+				}
+			}
+
+			ctx := repository.WithClinic(r.Context(), clinicID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -29,10 +29,10 @@ func CORSMiddleware(allowedOrigins string) func(http.Handler) http.Handler {
 
 			// Set other CORS headers
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept, Origin")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept, Origin, If-Match, X-Clinic-ID")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			w.Header().Set("Access-Control-Max-Age", "3600")
-			w.Header().Set("Access-Control-Expose-Headers", "Content-Length")
+			w.Header().Set("Access-Control-Expose-Headers", "Content-Length, ETag, Retry-After")
 
 			// Handle preflight OPTIONS request
 			if r.Method == http.MethodOptions {
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/requestctx"
+)
+
+// PermissionChecker tells whether a role has a permission in the clinic of the
+// request; implemented by role.Authorizer
+type PermissionChecker interface {
+	Can(ctx context.Context, role string, permission domain.Permission) (bool, error)
+}
+
+// RequirePermission returns a middleware that checks if the role of the
+// authenticated user has the required permission in their clinic
+// Must be used AFTER AuthMiddleware as it depends on the user being authenticated
+func RequirePermission(checker PermissionChecker, permission domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(requestctx.RoleKey).(string)
+			if !ok {
+				http.Error(w, "Role not found in context", http.StatusForbidden)
+				return
+			}
+
+			allowed, err := checker.Can(r.Context(), role, permission)
+			if err != nil {
+				http.Error(w, "Failed to check permissions", http.StatusInternalServerError)
+				return
+			}
+			if !allowed {
+				http.Error(w, "Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/requestctx"
+)
+
+// RequestIDHeader carries the request ID in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs supplied by clients
+const maxRequestIDLength = 128
+
+// maxUserAgentLength bounds the User-Agent kept for each request
+const maxUserAgentLength = 512
+
+// RequestIDMiddleware tags every request with an ID, the client IP address and its user agent
+// An incoming X-Request-ID header is reused so IDs can be traced across services,
+// otherwise a new one is generated; either way it is echoed in the response
+// X-Forwarded-For is only believed from the reverse proxies in trustedProxies
+func RequestIDMiddleware(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := strings.TrimSpace(r.Header.Get(RequestIDHeader))
+			if requestID == "" || len(requestID) > maxRequestIDLength {
+				requestID = uuid.New().String()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ctx := context.WithValue(r.Context(), requestctx.RequestIDKey, requestID)
+			ctx = context.WithValue(ctx, requestctx.ClientIPKey, clientIP(r, trustedProxies))
+			ctx = context.WithValue(ctx, requestctx.UserAgentKey, userAgent(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// userAgent returns the User-Agent header of the request, cut to maxUserAgentLength
+func userAgent(r *http.Request) string {
+	agent := strings.TrimSpace(r.UserAgent())
+	if len(agent) > maxUserAgentLength {
+		agent = strings.ToValidUTF8(agent[:maxUserAgentLength], "")
+	}
+	return agent
+}
+
+// clientIP returns the address of the client that made the request
+// The IP counts towards login lockouts, so X-Forwarded-For, which any client can
+// send, is only read when the connection comes from a trusted proxy. Each proxy
+// appends the address it received the request from, so the hops are walked from
+// the right and the first one that is not a trusted proxy is the client
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	addr = addr.Unmap()
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0 && isTrustedProxy(addr, trustedProxies); i-- {
+		hop, err := parseHop(hops[i])
+		if err != nil {
+			break
+		}
+		addr = hop
+	}
+	return addr.String()
+}
+
+// parseHop parses one X-Forwarded-For entry, which some proxies write with a port
+func parseHop(hop string) (netip.Addr, error) {
+	hop = strings.TrimSpace(hop)
+	if addrPort, err := netip.ParseAddrPort(hop); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	addr, err := netip.ParseAddr(hop)
+	return addr.Unmap(), err
+}
+
+// isTrustedProxy reports whether addr belongs to one of the trusted proxy networks
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,19 +2,26 @@ package http
 
 import (
 	"net/http"
+	"net/netip"
 
 	"version-1-0/internal/delivery/http/handler"
 	"version-1-0/internal/delivery/http/middleware"
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 	_ "version-1-0/docs"
 )
 
 // SetupRouter configures and returns the HTTP router with all application routes
-func SetupRouter(userHandler *handler.UserHandler, authHandler *handler.AuthHandler, appointmentHandler *handler.AppointmentHandler, doctorHandler *handler.DoctorHandler, serviceHandler *handler.ServiceHandler, scheduleHandler *handler.ScheduleHandler, analyticsHandler *handler.AnalyticsHandler, jwtSecret string, allowedOrigins string) http.Handler {
+func SetupRouter(userHandler *handler.UserHandler, authHandler *handler.AuthHandler, appointmentHandler *handler.AppointmentHandler, doctorHandler *handler.DoctorHandler, serviceHandler *handler.ServiceHandler, scheduleHandler *handler.ScheduleHandler, analyticsHandler *handler.AnalyticsHandler, auditHandler *handler.AuditHandler, locationHandler *handler.LocationHandler, patientHandler *handler.PatientHandler, invitationHandler *handler.InvitationHandler, lockoutHandler *handler.LockoutHandler, twoFactorHandler *handler.TwoFactorHandler, roleHandler *handler.RoleHandler, apiKeyHandler *handler.APIKeyHandler, jwksHandler *handler.JWKSHandler, ssoHandler *handler.SSOHandler, sessionHandler *handler.SessionHandler, clinicRepo repository.ClinicRepository, defaultClinicID string, tokens middleware.TokenParser, sessionValidator middleware.SessionValidator, apiKeys middleware.APIKeyAuthenticator, permissions middleware.PermissionChecker, allowedOrigins string, trustedProxies []netip.Prefix) http.Handler {
 	// Create a new HTTP multiplexer
 	mux := http.NewServeMux()
 
+	// Routes guarded by a permission, and the appointment actions that check
+	// one, also accept the API keys of integrations in place of a Bearer token
+	authenticate := middleware.Authenticate(tokens, sessionValidator, apiKeys)
+
 	// Register user routes
 	mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
@@ -26,24 +33,34 @@ func SetupRouter(userHandler *handler.UserHandler, authHandler *handler.AuthHand
 		}
 	})
 
+	// Public keys that verify access tokens - GET /.well-known/jwks.json (public)
+	mux.HandleFunc("GET /.well-known/jwks.json", jwksHandler.Get)
+
 	// Register authentication routes
 	mux.HandleFunc("/api/auth/login", authHandler.Login)
+	mux.HandleFunc("POST /api/auth/refresh", authHandler.Refresh)
+	mux.HandleFunc("POST /api/auth/logout", authHandler.Logout)
+	mux.HandleFunc("POST /api/auth/forgot-password", authHandler.ForgotPassword)
+	mux.HandleFunc("POST /api/auth/reset-password", authHandler.ResetPassword)
+	mux.HandleFunc("GET /api/auth/verify-email", authHandler.VerifyEmail)
+	mux.HandleFunc("POST /api/auth/unlock", authHandler.UnlockAccount)
+	mux.Handle("POST /api/auth/resend-verification", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(authHandler.ResendVerification)))
 
 	// Register protected user routes
 	protectedUserRoutes := http.HandlerFunc(userHandler.GetMe)
-	protectedUserRoutesWithAuth := middleware.AuthMiddleware(jwtSecret)(protectedUserRoutes)
+	protectedUserRoutesWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(protectedUserRoutes)
 	mux.Handle("/api/users/me", protectedUserRoutesWithAuth)
 
-	// Register admin-only routes
-	// List users - requires admin role
+	// Register staff routes, each guarded by the permission it needs
+	// List users - requires users:read
 	listUsersHandler := http.HandlerFunc(userHandler.List)
-	listUsersWithAuth := middleware.AuthMiddleware(jwtSecret)(listUsersHandler)
-	listUsersWithRole := middleware.RequireRole("admin")(listUsersWithAuth)
-	mux.Handle("/api/users/list", listUsersWithRole)
+	listUsersWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersRead)(listUsersHandler)
+	listUsersWithAuth := authenticate(listUsersWithPermission)
+	mux.Handle("/api/users/list", listUsersWithAuth)
 
-	// Update user - requires authentication (admin can update anyone, users can update themselves)
+	// Update user - requires authentication (users:write updates anyone, users can update themselves)
 	updateUserHandler := http.HandlerFunc(userHandler.Update)
-	updateUserWithAuth := middleware.AuthMiddleware(jwtSecret)(updateUserHandler)
+	updateUserWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(updateUserHandler)
 	mux.Handle("/api/users/", updateUserWithAuth)
 
 	// Delete user endpoint will use query param: /api/users/delete?id=xxx
@@ -54,64 +71,250 @@ func SetupRouter(userHandler *handler.UserHandler, authHandler *handler.AuthHand
 			http.Error(w, "Not found", http.StatusNotFound)
 		}
 	})
-	deleteWithRole := middleware.RequireRole("admin")(deleteHandler)
-	deleteWithAuth := middleware.AuthMiddleware(jwtSecret)(deleteWithRole)
+	deleteWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersWrite)(deleteHandler)
+	deleteWithAuth := authenticate(deleteWithPermission)
 	mux.Handle("/api/users/delete", deleteWithAuth)
 
+	// List deleted users - GET /api/users/deleted (requires users:read)
+	listDeletedUsersHandler := http.HandlerFunc(userHandler.ListDeleted)
+	listDeletedUsersWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersRead)(listDeletedUsersHandler)
+	listDeletedUsersWithAuth := authenticate(listDeletedUsersWithPermission)
+	mux.Handle("/api/users/deleted", listDeletedUsersWithAuth)
+
+	// Restore deleted user - POST /api/users/restore?id=xxx (requires users:write)
+	restoreUserHandler := http.HandlerFunc(userHandler.Restore)
+	restoreUserWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersWrite)(restoreUserHandler)
+	restoreUserWithAuth := authenticate(restoreUserWithPermission)
+	mux.Handle("/api/users/restore", restoreUserWithAuth)
+
+	// Staff invitation routes
+	// Public signup only creates patients; staff members join through an invitation
+	// Invite staff member - POST /api/invitations (requires invitations:manage)
+	createInvitationHandler := http.HandlerFunc(invitationHandler.Create)
+	createInvitationWithPermission := middleware.RequirePermission(permissions, domain.PermissionInvitationsManage)(createInvitationHandler)
+	createInvitationWithAuth := authenticate(createInvitationWithPermission)
+	mux.Handle("POST /api/invitations", createInvitationWithAuth)
+
+	// List invitations - GET /api/invitations?status= (requires invitations:manage)
+	listInvitationsHandler := http.HandlerFunc(invitationHandler.List)
+	listInvitationsWithPermission := middleware.RequirePermission(permissions, domain.PermissionInvitationsManage)(listInvitationsHandler)
+	listInvitationsWithAuth := authenticate(listInvitationsWithPermission)
+	mux.Handle("GET /api/invitations", listInvitationsWithAuth)
+
+	// Revoke invitation - DELETE /api/invitations/{id} (requires invitations:manage)
+	revokeInvitationHandler := http.HandlerFunc(invitationHandler.Revoke)
+	revokeInvitationWithPermission := middleware.RequirePermission(permissions, domain.PermissionInvitationsManage)(revokeInvitationHandler)
+	revokeInvitationWithAuth := authenticate(revokeInvitationWithPermission)
+	mux.Handle("DELETE /api/invitations/{id}", revokeInvitationWithAuth)
+
+	// Accept invitation - POST /api/invitations/accept (public, authorized by the emailed token)
+	mux.HandleFunc("POST /api/invitations/accept", invitationHandler.Accept)
+
+	// Login lockout routes
+	// Accounts and client addresses are locked for a while after too many failed logins
+	// List lockouts - GET /api/lockouts?scope= (requires lockouts:manage)
+	listLockoutsHandler := http.HandlerFunc(lockoutHandler.List)
+	listLockoutsWithPermission := middleware.RequirePermission(permissions, domain.PermissionLockoutsManage)(listLockoutsHandler)
+	listLockoutsWithAuth := authenticate(listLockoutsWithPermission)
+	mux.Handle("GET /api/lockouts", listLockoutsWithAuth)
+
+	// Clear lockout - DELETE /api/lockouts/{id} (requires lockouts:manage)
+	clearLockoutHandler := http.HandlerFunc(lockoutHandler.Clear)
+	clearLockoutWithPermission := middleware.RequirePermission(permissions, domain.PermissionLockoutsManage)(clearLockoutHandler)
+	clearLockoutWithAuth := authenticate(clearLockoutWithPermission)
+	mux.Handle("DELETE /api/lockouts/{id}", clearLockoutWithAuth)
+
+	// Two-factor authentication routes
+	// Staff with a second factor get a challenge token at login instead of tokens
+	// Second step of the login - POST /api/auth/2fa/setup and /verify (public, authorized by the challenge token)
+	mux.HandleFunc("POST /api/auth/2fa/setup", twoFactorHandler.Setup)
+	mux.HandleFunc("POST /api/auth/2fa/verify", twoFactorHandler.Verify)
+
+	// Manage my second factor - /api/users/me/2fa (authenticated)
+	mux.Handle("GET /api/users/me/2fa", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(twoFactorHandler.Status)))
+	mux.Handle("POST /api/users/me/2fa", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(twoFactorHandler.Enroll)))
+	mux.Handle("POST /api/users/me/2fa/confirm", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(twoFactorHandler.Confirm)))
+	mux.Handle("POST /api/users/me/2fa/recovery-codes", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(twoFactorHandler.RegenerateRecoveryCodes)))
+	mux.Handle("DELETE /api/users/me/2fa", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(twoFactorHandler.Disable)))
+
+	// Reset a user's second factor - DELETE /api/users/{id}/2fa (requires users:write)
+	resetTwoFactorHandler := http.HandlerFunc(twoFactorHandler.Reset)
+	resetTwoFactorWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersWrite)(resetTwoFactorHandler)
+	resetTwoFactorWithAuth := authenticate(resetTwoFactorWithPermission)
+	mux.Handle("DELETE /api/users/{id}/2fa", resetTwoFactorWithAuth)
+
+	// Single sign-on of staff - GET /api/auth/sso/start and POST /api/auth/sso/callback (public)
+	mux.HandleFunc("GET /api/auth/sso/start", ssoHandler.Start)
+	mux.HandleFunc("POST /api/auth/sso/callback", ssoHandler.Callback)
+
+	// Unlink a user's single sign-on identity - DELETE /api/users/{id}/sso (requires users:write)
+	unlinkSSOHandler := http.HandlerFunc(ssoHandler.Unlink)
+	unlinkSSOWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersWrite)(unlinkSSOHandler)
+	unlinkSSOWithAuth := authenticate(unlinkSSOWithPermission)
+	mux.Handle("DELETE /api/users/{id}/sso", unlinkSSOWithAuth)
+
+	// Manage my sessions - /api/users/me/sessions (authenticated)
+	// DELETE without an ID signs out everywhere; ?keep_current=true keeps this device
+	mux.Handle("GET /api/users/me/sessions", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(sessionHandler.List)))
+	mux.Handle("DELETE /api/users/me/sessions", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(sessionHandler.EndAll)))
+	mux.Handle("DELETE /api/users/me/sessions/{id}", middleware.AuthMiddleware(tokens, sessionValidator)(http.HandlerFunc(sessionHandler.End)))
+
+	// List a user's active sessions - GET /api/users/{id}/sessions (requires users:read)
+	listUserSessionsHandler := http.HandlerFunc(sessionHandler.ListUser)
+	listUserSessionsWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersRead)(listUserSessionsHandler)
+	listUserSessionsWithAuth := authenticate(listUserSessionsWithPermission)
+	mux.Handle("GET /api/users/{id}/sessions", listUserSessionsWithAuth)
+
+	// End all the sessions of a user, deactivated ones included - DELETE /api/users/{id}/sessions (requires users:write)
+	endUserSessionsHandler := http.HandlerFunc(sessionHandler.EndUser)
+	endUserSessionsWithPermission := middleware.RequirePermission(permissions, domain.PermissionUsersWrite)(endUserSessionsHandler)
+	endUserSessionsWithAuth := authenticate(endUserSessionsWithPermission)
+	mux.Handle("DELETE /api/users/{id}/sessions", endUserSessionsWithAuth)
+
+	// Role routes
+	// Each clinic chooses the permissions of its roles; admins always have all of them
+	// List roles - GET /api/roles (requires roles:manage)
+	listRolesHandler := http.HandlerFunc(roleHandler.List)
+	listRolesWithPermission := middleware.RequirePermission(permissions, domain.PermissionRolesManage)(listRolesHandler)
+	listRolesWithAuth := authenticate(listRolesWithPermission)
+	mux.Handle("GET /api/roles", listRolesWithAuth)
+
+	// Get role - GET /api/roles/{role} (requires roles:manage)
+	getRoleHandler := http.HandlerFunc(roleHandler.Get)
+	getRoleWithPermission := middleware.RequirePermission(permissions, domain.PermissionRolesManage)(getRoleHandler)
+	getRoleWithAuth := authenticate(getRoleWithPermission)
+	mux.Handle("GET /api/roles/{role}", getRoleWithAuth)
+
+	// Change role permissions - PUT /api/roles/{role} (requires roles:manage)
+	updateRoleHandler := http.HandlerFunc(roleHandler.Update)
+	updateRoleWithPermission := middleware.RequirePermission(permissions, domain.PermissionRolesManage)(updateRoleHandler)
+	updateRoleWithAuth := authenticate(updateRoleWithPermission)
+	mux.Handle("PUT /api/roles/{role}", updateRoleWithAuth)
+
+	// Reset role permissions - DELETE /api/roles/{role} (requires roles:manage)
+	resetRoleHandler := http.HandlerFunc(roleHandler.Reset)
+	resetRoleWithPermission := middleware.RequirePermission(permissions, domain.PermissionRolesManage)(resetRoleHandler)
+	resetRoleWithAuth := authenticate(resetRoleWithPermission)
+	mux.Handle("DELETE /api/roles/{role}", resetRoleWithAuth)
+
+	// List permissions - GET /api/permissions (requires roles:manage)
+	listPermissionsHandler := http.HandlerFunc(roleHandler.ListPermissions)
+	listPermissionsWithPermission := middleware.RequirePermission(permissions, domain.PermissionRolesManage)(listPermissionsHandler)
+	listPermissionsWithAuth := authenticate(listPermissionsWithPermission)
+	mux.Handle("GET /api/permissions", listPermissionsWithAuth)
+
+	// API key routes
+	// Integrations send a key in the X-API-Key header; only people manage keys
+	// Create API key - POST /api/api-keys (requires api_keys:manage)
+	createAPIKeyHandler := http.HandlerFunc(apiKeyHandler.Create)
+	createAPIKeyWithPermission := middleware.RequirePermission(permissions, domain.PermissionAPIKeysManage)(createAPIKeyHandler)
+	createAPIKeyWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(createAPIKeyWithPermission)
+	mux.Handle("POST /api/api-keys", createAPIKeyWithAuth)
+
+	// List API keys - GET /api/api-keys (requires api_keys:manage)
+	listAPIKeysHandler := http.HandlerFunc(apiKeyHandler.List)
+	listAPIKeysWithPermission := middleware.RequirePermission(permissions, domain.PermissionAPIKeysManage)(listAPIKeysHandler)
+	listAPIKeysWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(listAPIKeysWithPermission)
+	mux.Handle("GET /api/api-keys", listAPIKeysWithAuth)
+
+	// Revoke API key - DELETE /api/api-keys/{id} (requires api_keys:manage)
+	revokeAPIKeyHandler := http.HandlerFunc(apiKeyHandler.Revoke)
+	revokeAPIKeyWithPermission := middleware.RequirePermission(permissions, domain.PermissionAPIKeysManage)(revokeAPIKeyHandler)
+	revokeAPIKeyWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(revokeAPIKeyWithPermission)
+	mux.Handle("DELETE /api/api-keys/{id}", revokeAPIKeyWithAuth)
+
+	// API key usage - GET /api/api-keys/{id}/usage?days=30 (requires api_keys:manage)
+	apiKeyUsageHandler := http.HandlerFunc(apiKeyHandler.Usage)
+	apiKeyUsageWithPermission := middleware.RequirePermission(permissions, domain.PermissionAPIKeysManage)(apiKeyUsageHandler)
+	apiKeyUsageWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(apiKeyUsageWithPermission)
+	mux.Handle("GET /api/api-keys/{id}/usage", apiKeyUsageWithAuth)
+
 	// Appointment routes - require authentication
 	// Create appointment - POST /api/appointments
 	createAppointmentHandler := http.HandlerFunc(appointmentHandler.Create)
-	createAppointmentWithAuth := middleware.AuthMiddleware(jwtSecret)(createAppointmentHandler)
+	createAppointmentWithAuth := authenticate(createAppointmentHandler)
 	mux.Handle("/api/appointments", createAppointmentWithAuth)
 
 	// Get my appointments - GET /api/appointments/my
 	getMyAppointmentsHandler := http.HandlerFunc(appointmentHandler.GetMyAppointments)
-	getMyAppointmentsWithAuth := middleware.AuthMiddleware(jwtSecret)(getMyAppointmentsHandler)
+	getMyAppointmentsWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(getMyAppointmentsHandler)
 	mux.Handle("/api/appointments/my", getMyAppointmentsWithAuth)
 
 	// Get doctor appointments - GET /api/appointments/doctor (requires doctor role)
 	getDoctorAppointmentsHandler := http.HandlerFunc(appointmentHandler.GetDoctorAppointments)
 	getDoctorAppointmentsWithRole := middleware.RequireRole("doctor")(getDoctorAppointmentsHandler)
-	getDoctorAppointmentsWithAuth := middleware.AuthMiddleware(jwtSecret)(getDoctorAppointmentsWithRole)
+	getDoctorAppointmentsWithAuth := middleware.AuthMiddleware(tokens, sessionValidator)(getDoctorAppointmentsWithRole)
 	mux.Handle("/api/appointments/doctor", getDoctorAppointmentsWithAuth)
 
+	// List all appointments - GET /api/appointments/all (requires appointments:read:any)
+	getAllAppointmentsHandler := http.HandlerFunc(appointmentHandler.GetAll)
+	getAllAppointmentsWithPermission := middleware.RequirePermission(permissions, domain.PermissionAppointmentsReadAny)(getAllAppointmentsHandler)
+	getAllAppointmentsWithAuth := authenticate(getAllAppointmentsWithPermission)
+	mux.Handle("/api/appointments/all", getAllAppointmentsWithAuth)
+
+	// Get appointment - GET /api/appointments/get?id=xxx (the patient, the doctor of the appointment or appointments:read:any)
+	getAppointmentHandler := http.HandlerFunc(appointmentHandler.Get)
+	getAppointmentWithAuth := authenticate(getAppointmentHandler)
+	mux.Handle("/api/appointments/get", getAppointmentWithAuth)
+
 	// Cancel appointment - PUT /api/appointments/cancel
 	cancelAppointmentHandler := http.HandlerFunc(appointmentHandler.Cancel)
-	cancelAppointmentWithAuth := middleware.AuthMiddleware(jwtSecret)(cancelAppointmentHandler)
+	cancelAppointmentWithAuth := authenticate(cancelAppointmentHandler)
 	mux.Handle("/api/appointments/cancel", cancelAppointmentWithAuth)
 
-	// Confirm appointment - PUT /api/appointments/confirm?id=xxx (doctor or admin only)
+	// Confirm appointment - PUT /api/appointments/confirm?id=xxx (the doctor of the appointment or appointments:update:any)
 	confirmAppointmentHandler := http.HandlerFunc(appointmentHandler.Confirm)
-	confirmAppointmentWithAuth := middleware.AuthMiddleware(jwtSecret)(confirmAppointmentHandler)
+	confirmAppointmentWithAuth := authenticate(confirmAppointmentHandler)
 	mux.Handle("/api/appointments/confirm", confirmAppointmentWithAuth)
 
-	// Complete appointment - PUT /api/appointments/complete?id=xxx (doctor or admin only)
+	// Complete appointment - PUT /api/appointments/complete?id=xxx (the doctor of the appointment or appointments:update:any)
 	completeAppointmentHandler := http.HandlerFunc(appointmentHandler.Complete)
-	completeAppointmentWithAuth := middleware.AuthMiddleware(jwtSecret)(completeAppointmentHandler)
+	completeAppointmentWithAuth := authenticate(completeAppointmentHandler)
 	mux.Handle("/api/appointments/complete", completeAppointmentWithAuth)
 
+	// Reschedule appointment - PUT /api/appointments/reschedule?id=xxx (requires appointments:reschedule:any)
+	rescheduleAppointmentHandler := http.HandlerFunc(appointmentHandler.Reschedule)
+	rescheduleAppointmentWithPermission := middleware.RequirePermission(permissions, domain.PermissionAppointmentsRescheduleAny)(rescheduleAppointmentHandler)
+	rescheduleAppointmentWithAuth := authenticate(rescheduleAppointmentWithPermission)
+	mux.Handle("/api/appointments/reschedule", rescheduleAppointmentWithAuth)
+
 	// Get patient medical history - GET /api/appointments/history?patient_id=xxx
 	getHistoryHandler := http.HandlerFunc(appointmentHandler.GetHistory)
-	getHistoryWithAuth := middleware.AuthMiddleware(jwtSecret)(getHistoryHandler)
+	getHistoryWithAuth := authenticate(getHistoryHandler)
 	mux.Handle("/api/appointments/history", getHistoryWithAuth)
 
 	// Doctor routes - public search endpoint
 	mux.HandleFunc("/api/doctors/search", doctorHandler.Search)
 
+	// List deleted doctors - GET /api/doctors/deleted (requires doctors:write)
+	listDeletedDoctorsHandler := http.HandlerFunc(doctorHandler.ListDeleted)
+	listDeletedDoctorsWithPermission := middleware.RequirePermission(permissions, domain.PermissionDoctorsWrite)(listDeletedDoctorsHandler)
+	listDeletedDoctorsWithAuth := authenticate(listDeletedDoctorsWithPermission)
+	mux.Handle("/api/doctors/deleted", listDeletedDoctorsWithAuth)
+
+	// Restore deleted doctor - POST /api/doctors/restore?id=xxx (requires doctors:write)
+	restoreDoctorHandler := http.HandlerFunc(doctorHandler.Restore)
+	restoreDoctorWithPermission := middleware.RequirePermission(permissions, domain.PermissionDoctorsWrite)(restoreDoctorHandler)
+	restoreDoctorWithAuth := authenticate(restoreDoctorWithPermission)
+	mux.Handle("/api/doctors/restore", restoreDoctorWithAuth)
+
 	// Service routes
-	// Create service - POST /api/services (admin only)
+	// Create service - POST /api/services (requires services:write)
 	createServiceHandler := http.HandlerFunc(serviceHandler.Create)
-	createServiceWithRole := middleware.RequireRole("admin")(createServiceHandler)
-	createServiceWithAuth := middleware.AuthMiddleware(jwtSecret)(createServiceWithRole)
+	createServiceWithPermission := middleware.RequirePermission(permissions, domain.PermissionServicesWrite)(createServiceHandler)
+	createServiceWithAuth := authenticate(createServiceWithPermission)
 	mux.Handle("/api/services/create", createServiceWithAuth)
 
 	// List services - GET /api/services (public)
 	mux.HandleFunc("/api/services", serviceHandler.List)
 
-	// Assign service to doctor - POST /api/services/assign (admin only)
+	// Get service - GET /api/services/get?id=xxx (public)
+	mux.HandleFunc("/api/services/get", serviceHandler.Get)
+
+	// Assign service to doctor - POST /api/services/assign (requires services:write)
 	assignServiceHandler := http.HandlerFunc(serviceHandler.AssignToDoctor)
-	assignServiceWithRole := middleware.RequireRole("admin")(assignServiceHandler)
-	assignServiceWithAuth := middleware.AuthMiddleware(jwtSecret)(assignServiceWithRole)
+	assignServiceWithPermission := middleware.RequirePermission(permissions, domain.PermissionServicesWrite)(assignServiceHandler)
+	assignServiceWithAuth := authenticate(assignServiceWithPermission)
 	mux.Handle("/api/services/assign", assignServiceWithAuth)
 
 	// Get doctors by service - GET /api/services/doctors?service_id=xxx (public)
@@ -120,47 +323,138 @@ func SetupRouter(userHandler *handler.UserHandler, authHandler *handler.AuthHand
 	// Get available slots - GET /api/services/available-slots?doctor_id=xxx&service_id=yyy&date=YYYY-MM-DD (public)
 	mux.HandleFunc("/api/services/available-slots", serviceHandler.GetAvailableSlots)
 
+	// Update service - PUT /api/services/update?id=xxx (requires services:write)
+	updateServiceHandler := http.HandlerFunc(serviceHandler.Update)
+	updateServiceWithPermission := middleware.RequirePermission(permissions, domain.PermissionServicesWrite)(updateServiceHandler)
+	updateServiceWithAuth := authenticate(updateServiceWithPermission)
+	mux.Handle("/api/services/update", updateServiceWithAuth)
+
+	// Delete service - DELETE /api/services/delete?id=xxx (requires services:write)
+	deleteServiceHandler := http.HandlerFunc(serviceHandler.Delete)
+	deleteServiceWithPermission := middleware.RequirePermission(permissions, domain.PermissionServicesWrite)(deleteServiceHandler)
+	deleteServiceWithAuth := authenticate(deleteServiceWithPermission)
+	mux.Handle("/api/services/delete", deleteServiceWithAuth)
+
+	// List deleted services - GET /api/services/deleted (requires services:write)
+	listDeletedServicesHandler := http.HandlerFunc(serviceHandler.ListDeleted)
+	listDeletedServicesWithPermission := middleware.RequirePermission(permissions, domain.PermissionServicesWrite)(listDeletedServicesHandler)
+	listDeletedServicesWithAuth := authenticate(listDeletedServicesWithPermission)
+	mux.Handle("/api/services/deleted", listDeletedServicesWithAuth)
+
+	// Restore deleted service - POST /api/services/restore?id=xxx (requires services:write)
+	restoreServiceHandler := http.HandlerFunc(serviceHandler.Restore)
+	restoreServiceWithPermission := middleware.RequirePermission(permissions, domain.PermissionServicesWrite)(restoreServiceHandler)
+	restoreServiceWithAuth := authenticate(restoreServiceWithPermission)
+	mux.Handle("/api/services/restore", restoreServiceWithAuth)
+
 	// Schedule routes
-	// Create schedule - POST /api/schedules (admin only)
+	// Create schedule - POST /api/schedules (requires schedules:write)
 	createScheduleHandler := http.HandlerFunc(scheduleHandler.CreateSchedule)
-	createScheduleWithRole := middleware.RequireRole("admin")(createScheduleHandler)
-	createScheduleWithAuth := middleware.AuthMiddleware(jwtSecret)(createScheduleWithRole)
+	createScheduleWithPermission := middleware.RequirePermission(permissions, domain.PermissionSchedulesWrite)(createScheduleHandler)
+	createScheduleWithAuth := authenticate(createScheduleWithPermission)
 	mux.Handle("/api/schedules", createScheduleWithAuth)
 
 	// Get doctor schedules - GET /api/schedules/doctor/{id} (public)
 	mux.HandleFunc("/api/schedules/doctor/{id}", scheduleHandler.GetDoctorSchedules)
 
-	// Delete schedule - DELETE /api/schedules/{id} (admin only)
+	// Get schedule - GET /api/schedules/{id} (public)
+	mux.HandleFunc("GET /api/schedules/{id}", scheduleHandler.GetSchedule)
+
+	// Update schedule - PUT /api/schedules/{id} (requires schedules:write)
+	updateScheduleHandler := http.HandlerFunc(scheduleHandler.UpdateSchedule)
+	updateScheduleWithPermission := middleware.RequirePermission(permissions, domain.PermissionSchedulesWrite)(updateScheduleHandler)
+	updateScheduleWithAuth := authenticate(updateScheduleWithPermission)
+	mux.Handle("PUT /api/schedules/{id}", updateScheduleWithAuth)
+
+	// Delete schedule - DELETE /api/schedules/{id} (requires schedules:write)
 	deleteScheduleHandler := http.HandlerFunc(scheduleHandler.DeleteSchedule)
-	deleteScheduleWithRole := middleware.RequireRole("admin")(deleteScheduleHandler)
-	deleteScheduleWithAuth := middleware.AuthMiddleware(jwtSecret)(deleteScheduleWithRole)
+	deleteScheduleWithPermission := middleware.RequirePermission(permissions, domain.PermissionSchedulesWrite)(deleteScheduleHandler)
+	deleteScheduleWithAuth := authenticate(deleteScheduleWithPermission)
 	mux.Handle("DELETE /api/schedules/{id}", deleteScheduleWithAuth)
 
-	// Analytics routes (admin only)
+	// Analytics routes (requires analytics:read)
 	// Dashboard summary - GET /api/analytics/dashboard
 	dashboardHandler := http.HandlerFunc(analyticsHandler.GetDashboardSummary)
-	dashboardWithRole := middleware.RequireRole("admin")(dashboardHandler)
-	dashboardWithAuth := middleware.AuthMiddleware(jwtSecret)(dashboardWithRole)
+	dashboardWithPermission := middleware.RequirePermission(permissions, domain.PermissionAnalyticsRead)(dashboardHandler)
+	dashboardWithAuth := authenticate(dashboardWithPermission)
 	mux.Handle("/api/analytics/dashboard", dashboardWithAuth)
 
 	// Revenue stats - GET /api/analytics/revenue
 	revenueHandler := http.HandlerFunc(analyticsHandler.GetRevenueStats)
-	revenueWithRole := middleware.RequireRole("admin")(revenueHandler)
-	revenueWithAuth := middleware.AuthMiddleware(jwtSecret)(revenueWithRole)
+	revenueWithPermission := middleware.RequirePermission(permissions, domain.PermissionAnalyticsRead)(revenueHandler)
+	revenueWithAuth := authenticate(revenueWithPermission)
 	mux.Handle("/api/analytics/revenue", revenueWithAuth)
 
 	// Top doctors - GET /api/analytics/top-doctors?limit=10
 	topDoctorsHandler := http.HandlerFunc(analyticsHandler.GetTopDoctors)
-	topDoctorsWithRole := middleware.RequireRole("admin")(topDoctorsHandler)
-	topDoctorsWithAuth := middleware.AuthMiddleware(jwtSecret)(topDoctorsWithRole)
+	topDoctorsWithPermission := middleware.RequirePermission(permissions, domain.PermissionAnalyticsRead)(topDoctorsHandler)
+	topDoctorsWithAuth := authenticate(topDoctorsWithPermission)
 	mux.Handle("/api/analytics/top-doctors", topDoctorsWithAuth)
 
 	// Top services - GET /api/analytics/top-services?limit=10
 	topServicesHandler := http.HandlerFunc(analyticsHandler.GetTopServices)
-	topServicesWithRole := middleware.RequireRole("admin")(topServicesHandler)
-	topServicesWithAuth := middleware.AuthMiddleware(jwtSecret)(topServicesWithRole)
+	topServicesWithPermission := middleware.RequirePermission(permissions, domain.PermissionAnalyticsRead)(topServicesHandler)
+	topServicesWithAuth := authenticate(topServicesWithPermission)
 	mux.Handle("/api/analytics/top-services", topServicesWithAuth)
 
+	// Audit log - GET /api/audit?entity=&entity_id=&actor_id=&date_from=&date_to= (requires audit:read)
+	auditLogHandler := http.HandlerFunc(auditHandler.List)
+	auditLogWithPermission := middleware.RequirePermission(permissions, domain.PermissionAuditRead)(auditLogHandler)
+	auditLogWithAuth := authenticate(auditLogWithPermission)
+	mux.Handle("/api/audit", auditLogWithAuth)
+
+	// Patient lookup - GET /api/patients/by-document?document_number=&document_type= (requires patients:read)
+	findPatientHandler := http.HandlerFunc(patientHandler.FindByDocument)
+	findPatientWithPermission := middleware.RequirePermission(permissions, domain.PermissionPatientsRead)(findPatientHandler)
+	findPatientWithAuth := authenticate(findPatientWithPermission)
+	mux.Handle("GET /api/patients/by-document", findPatientWithAuth)
+
+	// Clinic and location routes
+	// Get clinic - GET /api/clinic (public)
+	mux.HandleFunc("GET /api/clinic", locationHandler.GetClinic)
+
+	// List locations - GET /api/locations (public)
+	mux.HandleFunc("GET /api/locations", locationHandler.ListLocations)
+
+	// Create location - POST /api/locations (requires locations:write)
+	createLocationHandler := http.HandlerFunc(locationHandler.CreateLocation)
+	createLocationWithPermission := middleware.RequirePermission(permissions, domain.PermissionLocationsWrite)(createLocationHandler)
+	createLocationWithAuth := authenticate(createLocationWithPermission)
+	mux.Handle("POST /api/locations", createLocationWithAuth)
+
+	// Update location - PUT /api/locations/{id} (requires locations:write)
+	updateLocationHandler := http.HandlerFunc(locationHandler.UpdateLocation)
+	updateLocationWithPermission := middleware.RequirePermission(permissions, domain.PermissionLocationsWrite)(updateLocationHandler)
+	updateLocationWithAuth := authenticate(updateLocationWithPermission)
+	mux.Handle("PUT /api/locations/{id}", updateLocationWithAuth)
+
+	// Assign doctor to location - POST /api/locations/{id}/doctors (requires locations:write)
+	assignLocationDoctorHandler := http.HandlerFunc(locationHandler.AssignDoctor)
+	assignLocationDoctorWithPermission := middleware.RequirePermission(permissions, domain.PermissionLocationsWrite)(assignLocationDoctorHandler)
+	assignLocationDoctorWithAuth := authenticate(assignLocationDoctorWithPermission)
+	mux.Handle("POST /api/locations/{id}/doctors", assignLocationDoctorWithAuth)
+
+	// Remove doctor from location - DELETE /api/locations/{id}/doctors/{doctorId} (requires locations:write)
+	removeLocationDoctorHandler := http.HandlerFunc(locationHandler.RemoveDoctor)
+	removeLocationDoctorWithPermission := middleware.RequirePermission(permissions, domain.PermissionLocationsWrite)(removeLocationDoctorHandler)
+	removeLocationDoctorWithAuth := authenticate(removeLocationDoctorWithPermission)
+	mux.Handle("DELETE /api/locations/{id}/doctors/{doctorId}", removeLocationDoctorWithAuth)
+
+	// List location prices - GET /api/locations/{id}/prices (public)
+	mux.HandleFunc("GET /api/locations/{id}/prices", locationHandler.ListServicePrices)
+
+	// Set location price - PUT /api/locations/{id}/prices/{serviceId} (requires locations:write)
+	setServicePriceHandler := http.HandlerFunc(locationHandler.SetServicePrice)
+	setServicePriceWithPermission := middleware.RequirePermission(permissions, domain.PermissionLocationsWrite)(setServicePriceHandler)
+	setServicePriceWithAuth := authenticate(setServicePriceWithPermission)
+	mux.Handle("PUT /api/locations/{id}/prices/{serviceId}", setServicePriceWithAuth)
+
+	// Remove location price - DELETE /api/locations/{id}/prices/{serviceId} (requires locations:write)
+	removeServicePriceHandler := http.HandlerFunc(locationHandler.RemoveServicePrice)
+	removeServicePriceWithPermission := middleware.RequirePermission(permissions, domain.PermissionLocationsWrite)(removeServicePriceHandler)
+	removeServicePriceWithAuth := authenticate(removeServicePriceWithPermission)
+	mux.Handle("DELETE /api/locations/{id}/prices/{serviceId}", removeServicePriceWithAuth)
+
 	// Swagger documentation endpoint
 	mux.HandleFunc("/swagger/", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"),
@@ -172,15 +466,22 @@ func SetupRouter(userHandler *handler.UserHandler, authHandler *handler.AuthHand
 		w.Write([]byte("Sistema de Reservas - API Running"))
 	})
 
-	// Apply middlewares in order: CORS -> Recovery -> Logging -> Handlers
+	// Apply middlewares in order: CORS -> Recovery -> Request ID -> Logging -> Clinic -> Handlers
+	// Clinic middleware scopes every repository call of the request to one clinic
+	withClinic := middleware.ClinicMiddleware(clinicRepo, defaultClinicID)(mux)
+
 	// CORS middleware must be first to handle preflight requests
-	withCORS := middleware.CORSMiddleware(allowedOrigins)(mux)
+	withCORS := middleware.CORSMiddleware(allowedOrigins)(withClinic)
 
 	// Recovery middleware wraps everything to catch panics
 	withRecovery := middleware.RecoveryMiddleware(withCORS)
 
-	// Logging middleware wraps the recovery middleware to log all requests
-	withLogging := middleware.LoggingMiddleware(withRecovery)
+	// Request ID middleware tags each request for the audit log, with the client IP
+	// taken from X-Forwarded-For only behind trustedProxies
+	withRequestID := middleware.RequestIDMiddleware(trustedProxies)(withRecovery)
+
+	// Logging middleware wraps everything else to log all requests
+	withLogging := middleware.LoggingMiddleware(withRequestID)
 
 	return withLogging
 }
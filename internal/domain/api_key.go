@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key, so keys are recognizable in logs and by
+// secret scanners. The full key is APIKeyPrefix, the lookup ID, "_" and the secret
+const APIKeyPrefix = "clk_"
+
+// APIKeyRole is the role requests authenticated with an API key act with
+// It is not a role users can have: what a key may do comes from its scopes
+const APIKeyRole = "api_key"
+
+// API key status values, derived from the timestamps of a key
+const (
+	APIKeyActive  = "active"
+	APIKeyRevoked = "revoked"
+	APIKeyExpired = "expired"
+)
+
+// APIKey lets a machine, such as a kiosk or a call-center integration, call the
+// API without logging in as a person. Admins create keys with the permissions
+// they need (scopes); only the SHA-256 hash of the key is stored, next to its
+// prefix so admins can tell keys apart
+type APIKey struct {
+	ID         string       `json:"id"`
+	ClinicID   string       `json:"clinic_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"` // APIKeyPrefix and the lookup ID, shown in full
+	KeyHash    string       `json:"-"`
+	Scopes     []Permission `json:"scopes"`
+	CreatedBy  string       `json:"created_by,omitempty"` // Admin who created it; empty once that account is purged
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"` // Nil for keys that never expire
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	LastUsedIP string       `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+}
+
+// Validate checks if the APIKey entity has all required fields properly set
+func (k *APIKey) Validate() error {
+	if strings.TrimSpace(k.Name) == "" {
+		return errors.New("name is required")
+	}
+
+	if len(k.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+
+	seen := make(map[Permission]bool)
+	for _, scope := range k.Scopes {
+		if !IsValidPermission(string(scope)) {
+			return fmt.Errorf("unknown permission: %s", scope)
+		}
+		if !scope.GrantableToAPIKey() {
+			return fmt.Errorf("permission cannot be given to an api key: %s", scope)
+		}
+		if seen[scope] {
+			return fmt.Errorf("duplicate permission: %s", scope)
+		}
+		seen[scope] = true
+	}
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(k.CreatedAt) {
+		return errors.New("expires at must be in the future")
+	}
+
+	return nil
+}
+
+// Status returns whether the key is active, revoked or expired at the given time
+func (k *APIKey) Status(now time.Time) string {
+	switch {
+	case k.RevokedAt != nil:
+		return APIKeyRevoked
+	case k.ExpiresAt != nil && !now.Before(*k.ExpiresAt):
+		return APIKeyExpired
+	default:
+		return APIKeyActive
+	}
+}
+
+// Has reports whether the key was given a permission
+func (k *APIKey) Has(permission Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantableToAPIKey reports whether a permission can be a scope of an API key
+// Permissions that hand out access stay with people: a leaked key must not be
+// able to mint more keys, change roles, invite staff or take over accounts
+func (p Permission) GrantableToAPIKey() bool {
+	switch p {
+	case PermissionAPIKeysManage, PermissionRolesManage, PermissionInvitationsManage, PermissionUsersWrite:
+		return false
+	default:
+		return true
+	}
+}
+
+// APIKeyUsage counts the requests a key authenticated in one day (UTC)
+type APIKeyUsage struct {
+	KeyID    string    `json:"key_id"`
+	Day      time.Time `json:"day"`
+	Requests int       `json:"requests"`
+}
@@ -23,16 +23,18 @@ type Appointment struct {
 	PatientID          string            `json:"patient_id"`
 	DoctorID           string            `json:"doctor_id"`
 	ServiceID          string            `json:"service_id,omitempty"`          // Optional: Service associated with this appointment
+	LocationID         string            `json:"location_id"`                   // Location where the appointment takes place
 	PatientName        string            `json:"patient_name,omitempty"`        // Full name of patient
 	DoctorName         string            `json:"doctor_name,omitempty"`         // Full name of doctor with "Dr." prefix
 	ServiceName        string            `json:"service_name,omitempty"`        // Name of the service
 	ScheduledAt        time.Time         `json:"scheduled_at"`
 	Duration           int               `json:"duration"` // in minutes
 	Reason             string            `json:"reason"`
-	Notes              string            `json:"notes"`
+	Notes              string            `json:"notes" audit:"redact"` // Encrypted at rest and left out of the audit log
 	Status             AppointmentStatus `json:"status"`
 	CreatedAt          time.Time         `json:"created_at"`
 	UpdatedAt          time.Time         `json:"updated_at"`
+	Version            int               `json:"version"` // Incremented on every update, used for optimistic locking
 	CancelledAt        *time.Time        `json:"cancelled_at,omitempty"`
 	CancellationReason string            `json:"cancellation_reason,omitempty"`
 	Reminder24hSent    bool              `json:"reminder_24h_sent"`
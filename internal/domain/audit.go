@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited entities
+const (
+	AuditEntityUser           = "user"
+	AuditEntityDoctor         = "doctor"
+	AuditEntityAppointment    = "appointment"
+	AuditEntityService        = "service"
+	AuditEntityDoctorService  = "doctor_service"
+	AuditEntitySchedule       = "schedule"
+	AuditEntityLocation       = "location"
+	AuditEntityDoctorLocation = "doctor_location"
+	AuditEntityServicePrice   = "service_price"
+	AuditEntityInvitation     = "invitation"
+	AuditEntityLoginLockout   = "login_lockout"
+	AuditEntityTwoFactor      = "two_factor"
+	AuditEntityRole           = "role"
+	AuditEntityAPIKey         = "api_key"
+	AuditEntityIdentity       = "external_identity"
+	AuditEntitySession        = "session"
+)
+
+// Audited actions
+const (
+	AuditActionCreate        = "create"
+	AuditActionUpdate        = "update"
+	AuditActionDelete        = "delete"
+	AuditActionRestore       = "restore"
+	AuditActionConfirm       = "confirm"
+	AuditActionComplete      = "complete"
+	AuditActionCancel        = "cancel"
+	AuditActionReschedule    = "reschedule"
+	AuditActionAssign        = "assign"
+	AuditActionUnassign      = "unassign"
+	AuditActionResetPassword = "reset_password"
+	AuditActionVerifyEmail   = "verify_email"
+	AuditActionRevoke        = "revoke"
+	AuditActionAccept        = "accept"
+	AuditActionLock          = "lock"
+	AuditActionUnlock        = "unlock"
+	AuditActionEnable        = "enable"
+	AuditActionDisable       = "disable"
+	AuditActionReset         = "reset"
+	AuditActionRegenerate    = "regenerate"
+)
+
+// AuditEntry records who changed what and when
+// Changes holds a JSON object keyed by field name, each value being
+// {"before": ..., "after": ...}; only fields that actually changed are listed
+type AuditEntry struct {
+	ID        string          `json:"id"`
+	ClinicID  string          `json:"clinic_id"`
+	ActorID   string          `json:"actor_id,omitempty"`   // Empty for anonymous requests such as public signup
+	ActorRole string          `json:"actor_role,omitempty"` // Role from the JWT at the time of the change, or api_key
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Action    string          `json:"action"`
+	Changes   json.RawMessage `json:"changes"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Records created by migration 0009 for the data that existed before clinics
+// Databases that only ever run one clinic keep working against them
+const (
+	DefaultClinicID   = "00000000-0000-0000-0000-000000000001"
+	DefaultLocationID = "00000000-0000-0000-0000-000000000001"
+)
+
+// Clinic is a tenant: an organization with its own staff, patients, services
+// and branches. Every other record belongs to exactly one clinic
+type Clinic struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`            // Shown in emails, e.g. "Clínica Internacional"
+	CurrencySymbol string    `json:"currency_symbol"` // Prefix for prices, e.g. "S/"
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Validate checks if the Clinic entity has all required fields properly set
+func (c *Clinic) Validate() error {
+	if strings.TrimSpace(c.ID) == "" {
+		return errors.New("clinic ID is required")
+	}
+
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("clinic name is required")
+	}
+
+	if strings.TrimSpace(c.CurrencySymbol) == "" {
+		return errors.New("clinic currency symbol is required")
+	}
+
+	if c.CreatedAt.IsZero() {
+		return errors.New("clinic created at is required")
+	}
+
+	if c.UpdatedAt.IsZero() {
+		return errors.New("clinic updated at is required")
+	}
+
+	return nil
+}
+
+// FormatAmount formats an amount in the clinic's currency, e.g. "S/ 120.00"
+func (c *Clinic) FormatAmount(amount float64) string {
+	return fmt.Sprintf("%s %.2f", c.CurrencySymbol, amount)
+}
+
+// Location is a branch of a clinic where doctors see patients
+type Location struct {
+	ID        string    `json:"id"`
+	ClinicID  string    `json:"clinic_id"`
+	Name      string    `json:"name"` // e.g. "Sede San Borja"
+	Address   string    `json:"address"`
+	Phone     string    `json:"phone"`
+	IsActive  bool      `json:"is_active"` // Inactive locations take no new schedules or appointments
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"` // Incremented on every update, used for optimistic locking
+}
+
+// Validate checks if the Location entity has all required fields properly set
+func (l *Location) Validate() error {
+	if strings.TrimSpace(l.ID) == "" {
+		return errors.New("location ID is required")
+	}
+
+	if strings.TrimSpace(l.ClinicID) == "" {
+		return errors.New("location clinic ID is required")
+	}
+
+	if strings.TrimSpace(l.Name) == "" {
+		return errors.New("location name is required")
+	}
+
+	if l.CreatedAt.IsZero() {
+		return errors.New("location created at is required")
+	}
+
+	if l.UpdatedAt.IsZero() {
+		return errors.New("location updated at is required")
+	}
+
+	return nil
+}
+
+// DoctorLocation attaches a doctor to a location they work at
+// A doctor's schedules can only be placed at their locations
+type DoctorLocation struct {
+	DoctorID   string    `json:"doctor_id"` // References doctors.id
+	LocationID string    `json:"location_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ServicePrice overrides the price of a service at one location
+// Locations without an override charge the service's own price
+type ServicePrice struct {
+	ServiceID  string    `json:"service_id"`
+	LocationID string    `json:"location_id"`
+	Price      float64   `json:"price"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Validate checks if the ServicePrice has all required fields properly set
+func (p *ServicePrice) Validate() error {
+	if strings.TrimSpace(p.ServiceID) == "" {
+		return errors.New("service ID is required")
+	}
+
+	if strings.TrimSpace(p.LocationID) == "" {
+		return errors.New("location ID is required")
+	}
+
+	if p.Price < 0 {
+		return errors.New("service price cannot be negative")
+	}
+
+	if p.CreatedAt.IsZero() || p.UpdatedAt.IsZero() {
+		return errors.New("service price timestamps are required")
+	}
+
+	return nil
+}
@@ -2,7 +2,6 @@ package domain
 
 import (
 	"errors"
-	"fmt"
 	"strings"
 	"time"
 )
@@ -20,6 +19,7 @@ type Doctor struct {
 	IsAvailable        bool      `json:"is_available"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"` // Set when the doctor profile is soft deleted
 }
 
 // Validate checks if the Doctor entity has all required fields properly set
@@ -60,8 +60,8 @@ func (d *Doctor) Validate() error {
 	return nil
 }
 
-// FormattedFee returns the consultation fee formatted as a string with currency symbol
-// Uses the format "S/ X.XX" (Peruvian Sol) for the clinic
-func (d *Doctor) FormattedFee() string {
-	return fmt.Sprintf("S/ %.2f", d.ConsultationFee)
+// FormattedFee returns the consultation fee in the currency of the doctor's clinic
+// e.g. "S/ 150.00"
+func (d *Doctor) FormattedFee(clinic *Clinic) string {
+	return clinic.FormatAmount(d.ConsultationFee)
 }
@@ -0,0 +1,35 @@
+package domain
+
+import "errors"
+
+// Persistence errors returned by repository implementations when the database
+// rejects a write. Messages match the ones the use cases already return so the
+// HTTP handlers can keep comparing err.Error()
+var (
+	// ErrEmailAlreadyExists is returned when a user email is already registered
+	ErrEmailAlreadyExists = errors.New("email already exists")
+
+	// ErrLicenseNumberAlreadyExists is returned when a doctor license number is already registered
+	ErrLicenseNumberAlreadyExists = errors.New("license number already exists")
+
+	// ErrServiceAlreadyAssigned is returned when a doctor already offers a service
+	ErrServiceAlreadyAssigned = errors.New("service is already assigned to this doctor")
+
+	// ErrDuplicateRecord is returned for any other unique constraint violation
+	ErrDuplicateRecord = errors.New("record already exists")
+
+	// ErrRelatedRecordNotFound is returned when a referenced record does not exist
+	ErrRelatedRecordNotFound = errors.New("related record not found")
+
+	// ErrRecordInUse is returned when a record cannot be deleted because others reference it
+	ErrRecordInUse = errors.New("record is still in use")
+
+	// ErrSlotTaken is returned when an appointment overlaps another active
+	// appointment of the same doctor, either detected by the use case or by
+	// the database overlap guard
+	ErrSlotTaken = errors.New("time slot is not available")
+
+	// ErrVersionConflict is returned when an update names a version that is no
+	// longer current because someone else changed the record in the meantime
+	ErrVersionConflict = errors.New("record was modified by another request")
+)
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// ExternalIdentity links a staff account to the account of the same person at
+// the single sign-on provider. The provider names the person with its issuer
+// and subject, which never change, so once linked by email the account is
+// found again even if the email at the provider changes. The same person may
+// be linked in several clinics, each with its own account
+type ExternalIdentity struct {
+	ID          string    `json:"id"`
+	ClinicID    string    `json:"clinic_id"`
+	UserID      string    `json:"user_id"`
+	Issuer      string    `json:"issuer"`
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"` // Email the provider gave at the last login
+	CreatedAt   time.Time `json:"created_at"`
+	LastLoginAt time.Time `json:"last_login_at"`
+}
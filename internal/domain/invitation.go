@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Invitation status values, derived from the timestamps of an invitation
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationRevoked  = "revoked"
+	InvitationExpired  = "expired"
+)
+
+// Invitation lets an admin bring a staff member into the clinic
+// Staff accounts cannot be created through public signup: the admin invites
+// an email with a role, and whoever opens the emailed link chooses a password
+// to create the account. Only the SHA-256 hash of the token is stored
+type Invitation struct {
+	ID         string     `json:"id"`
+	ClinicID   string     `json:"clinic_id"`
+	Email      string     `json:"email"`
+	Role       UserRole   `json:"role"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  string     `json:"invited_by,omitempty"` // Admin who sent it; empty once that account is purged
+	UserID     string     `json:"user_id,omitempty"`    // Account created when the invitation was accepted
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"` // Set by an admin, or when a newer invitation replaces this one
+}
+
+// Status returns whether the invitation is pending, accepted, revoked or expired at the given time
+func (i *Invitation) Status(now time.Time) string {
+	switch {
+	case i.AcceptedAt != nil:
+		return InvitationAccepted
+	case i.RevokedAt != nil:
+		return InvitationRevoked
+	case !now.Before(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
+
+// IsValidInvitationStatus checks if a given string is a valid invitation status
+func IsValidInvitationStatus(status string) bool {
+	return status == InvitationPending || status == InvitationAccepted ||
+		status == InvitationRevoked || status == InvitationExpired
+}
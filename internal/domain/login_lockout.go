@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// Login lockout scopes: failed logins are counted per account and per client IP address
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+)
+
+// LoginLockout counts the failed logins of an account or IP address and
+// locks it for a while once there are too many. Accounts are tracked by the
+// email typed at login, whether or not it is registered, so a lockout does not
+// reveal which emails exist. Only the SHA-256 hash of the unlock token is stored
+type LoginLockout struct {
+	ID              string     `json:"id"`
+	ClinicID        string     `json:"clinic_id"`
+	Scope           string     `json:"scope"`
+	Subject         string     `json:"subject"` // Lowercased email for accounts, address for IPs
+	Failures        int        `json:"failures"`
+	LastFailureAt   time.Time  `json:"last_failure_at"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+	UnlockTokenHash string     `json:"-"` // Account lockouts only; emailed so the owner can unlock early
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// IsLocked reports whether logins are refused at the given time
+func (l *LoginLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
+
+// IsValidLockoutScope checks if the scope is account or ip
+func IsValidLockoutScope(scope string) bool {
+	return scope == LockoutScopeAccount || scope == LockoutScopeIP
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// PasswordResetToken lets a user who forgot their password choose a new one
+// It is emailed as a link, works once and expires shortly after; only the
+// SHA-256 hash of the token is stored
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"` // Set when the password is reset, or when a newer link replaces this one
+}
+
+// IsExpired reports whether the token can no longer be used at the given time
+func (t *PasswordResetToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
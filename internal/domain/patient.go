@@ -4,20 +4,22 @@ import (
 	"errors"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Patient represents a patient entity in the medical reservation system
+// Fields tagged audit:"redact" are encrypted at rest and never written to the audit log
 type Patient struct {
 	ID                    string    `json:"id"`
 	UserID                string    `json:"user_id"`
 	Birthdate             time.Time `json:"birthdate"`
 	DocumentType          string    `json:"document_type"`
-	DocumentNumber        string    `json:"document_number"`
+	DocumentNumber        string    `json:"document_number" audit:"redact"`
 	Address               string    `json:"address"`
-	EmergencyContactName  string    `json:"emergency_contact_name"`
-	EmergencyContactPhone string    `json:"emergency_contact_phone"`
-	BloodType             string    `json:"blood_type,omitempty"`
-	Allergies             []string  `json:"allergies,omitempty"`
+	EmergencyContactName  string    `json:"emergency_contact_name" audit:"redact"`
+	EmergencyContactPhone string    `json:"emergency_contact_phone" audit:"redact"`
+	BloodType             string    `json:"blood_type,omitempty" audit:"redact"`
+	Allergies             []string  `json:"allergies,omitempty" audit:"redact"`
 	CreatedAt             time.Time `json:"created_at"`
 	UpdatedAt             time.Time `json:"updated_at"`
 }
@@ -85,3 +87,15 @@ func (p *Patient) Age() int {
 
 	return age
 }
+
+// NormalizeDocumentNumber puts a document number in the form used for lookups
+// Case, spaces and punctuation are ignored, so "ab-123.456" matches "AB123456"
+func NormalizeDocumentNumber(number string) string {
+	var b strings.Builder
+	for _, r := range number {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,195 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Permission names an action a role may perform, as "resource:action"
+// The ":any" suffix marks actions on records of other users; without it users
+// can still act on their own appointments and profile
+type Permission string
+
+// Permission constants
+const (
+	PermissionUsersRead                 Permission = "users:read"
+	PermissionUsersWrite                Permission = "users:write"
+	PermissionInvitationsManage         Permission = "invitations:manage"
+	PermissionLockoutsManage            Permission = "lockouts:manage"
+	PermissionRolesManage               Permission = "roles:manage"
+	PermissionAPIKeysManage             Permission = "api_keys:manage"
+	PermissionAppointmentsCreateAny     Permission = "appointments:create:any"
+	PermissionAppointmentsReadAny       Permission = "appointments:read:any"
+	PermissionAppointmentsUpdateAny     Permission = "appointments:update:any"
+	PermissionAppointmentsCancelAny     Permission = "appointments:cancel:any"
+	PermissionAppointmentsRescheduleAny Permission = "appointments:reschedule:any"
+	PermissionHistoryReadAny            Permission = "history:read:any"
+	PermissionPatientsRead              Permission = "patients:read"
+	PermissionDoctorsWrite              Permission = "doctors:write"
+	PermissionServicesWrite             Permission = "services:write"
+	PermissionSchedulesWrite            Permission = "schedules:write"
+	PermissionLocationsWrite            Permission = "locations:write"
+	PermissionAnalyticsRead             Permission = "analytics:read"
+	PermissionAuditRead                 Permission = "audit:read"
+)
+
+// PermissionInfo describes a permission for the admins assigning it
+type PermissionInfo struct {
+	Name        Permission `json:"name"`
+	Description string     `json:"description"`
+}
+
+// Permissions lists every permission a role can be given
+var Permissions = []PermissionInfo{
+	{PermissionUsersRead, "Listar usuarios, incluidos los eliminados, y sus sesiones activas"},
+	{PermissionUsersWrite, "Editar, eliminar y restaurar usuarios, cerrar sus sesiones, restablecer su verificación en dos pasos y desvincular su inicio de sesión único"},
+	{PermissionInvitationsManage, "Invitar personal y revocar invitaciones"},
+	{PermissionLockoutsManage, "Ver y levantar bloqueos de login"},
+	{PermissionRolesManage, "Cambiar los permisos de los roles"},
+	{PermissionAPIKeysManage, "Crear y revocar API keys para integraciones"},
+	{PermissionAppointmentsCreateAny, "Reservar citas a nombre de cualquier paciente"},
+	{PermissionAppointmentsReadAny, "Ver las citas de todos los pacientes"},
+	{PermissionAppointmentsUpdateAny, "Confirmar y completar citas de cualquier doctor"},
+	{PermissionAppointmentsCancelAny, "Cancelar cualquier cita"},
+	{PermissionAppointmentsRescheduleAny, "Reprogramar cualquier cita"},
+	{PermissionHistoryReadAny, "Ver la historia clínica de cualquier paciente"},
+	{PermissionPatientsRead, "Buscar pacientes por documento"},
+	{PermissionDoctorsWrite, "Ver y restaurar doctores eliminados"},
+	{PermissionServicesWrite, "Crear, editar, eliminar y asignar servicios"},
+	{PermissionSchedulesWrite, "Crear, editar y eliminar horarios de los doctores"},
+	{PermissionLocationsWrite, "Crear y editar sedes, sus doctores y sus precios"},
+	{PermissionAnalyticsRead, "Ver las estadísticas de la clínica"},
+	{PermissionAuditRead, "Consultar el registro de auditoría"},
+}
+
+// IsValidPermission checks if a given string is a known Permission
+func IsValidPermission(permission string) bool {
+	for _, info := range Permissions {
+		if string(info.Name) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleDefinition is the set of permissions a role grants in a clinic
+// Roles themselves are fixed, since doctors and patients carry their own
+// profiles; each clinic only chooses what its roles may do. A role the clinic
+// never changed keeps its default permissions and has no stored definition
+type RoleDefinition struct {
+	ClinicID    string       `json:"clinic_id"`
+	Role        UserRole     `json:"role"`
+	Permissions []Permission `json:"permissions"`
+	UpdatedAt   time.Time    `json:"updated_at,omitzero"` // Zero for the defaults of a role
+}
+
+// Validate checks if the RoleDefinition entity has all required fields properly set
+func (d *RoleDefinition) Validate() error {
+	if !IsValidRole(string(d.Role)) {
+		return errors.New("invalid role")
+	}
+
+	if d.Role == RoleAdmin {
+		return errors.New("the admin role always has every permission")
+	}
+
+	seen := make(map[Permission]bool)
+	for _, permission := range d.Permissions {
+		if !IsValidPermission(string(permission)) {
+			return fmt.Errorf("unknown permission: %s", permission)
+		}
+		if seen[permission] {
+			return fmt.Errorf("duplicate permission: %s", permission)
+		}
+		seen[permission] = true
+	}
+
+	if d.UpdatedAt.IsZero() {
+		return errors.New("role updated at is required")
+	}
+
+	return nil
+}
+
+// Has reports whether the definition grants a permission
+func (d *RoleDefinition) Has(permission Permission) bool {
+	for _, p := range d.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManage reports whether users of a role may change accounts of the target role
+// Only admins touch admin accounts, so no permission given to another role
+// can be used to take one over
+func (r UserRole) CanManage(target UserRole) bool {
+	return r == RoleAdmin || target != RoleAdmin
+}
+
+// Roles lists every role in the order they are shown to admins
+var Roles = []UserRole{RoleAdmin, RoleDoctor, RoleNurse, RoleReceptionist, RolePatient}
+
+// RoleDescription explains what a role is meant for
+func RoleDescription(role UserRole) string {
+	switch role {
+	case RoleAdmin:
+		return "Administra la clínica; tiene siempre todos los permisos"
+	case RoleDoctor:
+		return "Atiende sus citas y consulta las historias clínicas"
+	case RoleNurse:
+		return "Apoya la atención y consulta las citas y las historias clínicas"
+	case RoleReceptionist:
+		return "Reserva, reprograma y cancela citas a nombre de los pacientes"
+	case RolePatient:
+		return "Reserva y gestiona sus propias citas"
+	default:
+		return ""
+	}
+}
+
+// DefaultPermissions returns the permissions a role has until its clinic changes them
+// Users of every role can always manage their own profile and appointments
+func DefaultPermissions(role UserRole) []Permission {
+	switch role {
+	case RoleAdmin:
+		all := make([]Permission, len(Permissions))
+		for i, info := range Permissions {
+			all[i] = info.Name
+		}
+		return all
+	case RoleDoctor:
+		return []Permission{PermissionHistoryReadAny}
+	case RoleNurse:
+		return []Permission{
+			PermissionAppointmentsReadAny,
+			PermissionHistoryReadAny,
+			PermissionPatientsRead,
+		}
+	case RoleReceptionist:
+		return []Permission{
+			PermissionAppointmentsCreateAny,
+			PermissionAppointmentsReadAny,
+			PermissionAppointmentsCancelAny,
+			PermissionAppointmentsRescheduleAny,
+			PermissionPatientsRead,
+		}
+	default:
+		return []Permission{}
+	}
+}
+
+// SortPermissions orders permissions like the Permissions catalog, so stored
+// and default sets compare and display the same way
+func SortPermissions(permissions []Permission) {
+	order := make(map[Permission]int, len(Permissions))
+	for i, info := range Permissions {
+		order[info.Name] = i
+	}
+	sort.SliceStable(permissions, func(i, j int) bool {
+		return order[permissions[i]] < order[permissions[j]]
+	})
+}
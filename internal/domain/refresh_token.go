@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// RefreshToken is a long-lived credential a client exchanges for a new access token
+// Every login starts a session; each refresh marks the presented token as used
+// and issues the next one of the same session, so a used token showing up again
+// means it was copied and the whole session is revoked. Only the SHA-256 hash
+// of the token is stored
+type RefreshToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	SessionID string     `json:"session_id"` // Shared by every token of a login; access tokens carry it as "sid"
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`    // Set when the token is exchanged for the next one
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Set on logout, password change or detected reuse
+}
+
+// IsExpired reports whether the token can no longer be exchanged at the given time
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
@@ -9,6 +9,7 @@ import (
 type Schedule struct {
 	ID           string    `json:"id"`
 	DoctorID     string    `json:"doctor_id"`
+	LocationID   string    `json:"location_id"`    // Location the doctor works at during this block
 	DayOfWeek    string    `json:"day_of_week"`    // monday, tuesday, etc.
 	StartTime    string    `json:"start_time"`     // HH:MM format
 	EndTime      string    `json:"end_time"`       // HH:MM format
@@ -16,6 +17,7 @@ type Schedule struct {
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+	Version      int       `json:"version"`        // Incremented on every update, used for optimistic locking
 }
 
 // DayOfWeek constants
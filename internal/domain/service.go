@@ -10,13 +10,16 @@ import (
 // Each service defines the duration (slot time) for appointments
 type Service struct {
 	ID              string    `json:"id"`
+	ClinicID        string    `json:"clinic_id"`                 // Clinic that offers the service
 	Name            string    `json:"name"`                      // e.g., "Consulta Cardiológica", "Electrocardiograma"
 	Description     string    `json:"description"`               // Detailed description of the service
 	DurationMinutes int       `json:"duration_minutes"`          // Duration of each appointment slot (e.g., 30, 45, 60 minutes)
-	Price           float64   `json:"price"`                     // Price of the service
+	Price           float64   `json:"price"`                     // Price of the service, unless a location overrides it
 	IsActive        bool      `json:"is_active"`                 // Whether the service is currently offered
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`                   // Incremented on every update, used for optimistic locking
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`     // Set when the service is soft deleted
 }
 
 // Validate validates the service data
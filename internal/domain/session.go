@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Session is one login of a user on a device
+// It lasts while its refresh tokens do: revoking them ends the session and
+// the access tokens that carry its ID as "sid" (see RefreshToken). The device
+// is told apart by the user agent and IP address the login came from
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"` // Last refresh or request, updated at most once a minute
+	ExpiresAt  time.Time `json:"expires_at"`   // When its current refresh token expires; filled in by ListActiveByUser
+}
@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// TwoFactor is the TOTP second factor of a staff account
+// Enrollment stores a new secret that only becomes active once the user
+// confirms it with a first code from their authenticator app. The secret is
+// encrypted at rest like the sensitive patient fields
+type TwoFactor struct {
+	UserID       string     `json:"user_id"`
+	Secret       string     `json:"-"` // Base32 TOTP secret
+	EnabledAt    *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep int64      `json:"-"` // Time step of the last accepted code, so no code works twice
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// IsEnabled reports whether logins must present a code
+func (t *TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// RecoveryCode lets a user with two-factor authentication log in without their
+// authenticator app. Each code works once; only its SHA-256 hash is stored
+type RecoveryCode struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
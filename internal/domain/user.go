@@ -11,23 +11,30 @@ type UserRole string
 
 // User role constants
 const (
-	RoleAdmin   UserRole = "admin"
-	RoleDoctor  UserRole = "doctor"
-	RolePatient UserRole = "patient"
+	RoleAdmin        UserRole = "admin"
+	RoleDoctor       UserRole = "doctor"
+	RoleNurse        UserRole = "nurse"
+	RoleReceptionist UserRole = "receptionist"
+	RolePatient      UserRole = "patient"
 )
 
 // User represents a user entity in the medical reservation system
 type User struct {
-	ID           string    `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"` // Never expose password hash in JSON
-	FirstName    string    `json:"first_name"`
-	LastName     string    `json:"last_name"`
-	Phone        string    `json:"phone"`
-	Role         UserRole  `json:"role"`
-	IsActive     bool      `json:"is_active"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                 string     `json:"id"`
+	ClinicID           string     `json:"clinic_id"` // Clinic the account belongs to
+	Email              string     `json:"email"`
+	PasswordHash       string     `json:"-"` // Never expose password hash in JSON
+	FirstName          string     `json:"first_name"`
+	LastName           string     `json:"last_name"`
+	Phone              string     `json:"phone"`
+	Role               UserRole   `json:"role"`
+	IsActive           bool       `json:"is_active"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"` // Set when the user opens the verification link
+	VerificationSentAt *time.Time `json:"-"`                           // Last verification email, used to throttle resends
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	Version            int        `json:"version"`              // Incremented on every update, used for optimistic locking
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"` // Set when the user is soft deleted
 }
 
 // Validate checks if the User entity has all required fields properly set
@@ -53,7 +60,7 @@ func (u *User) Validate() error {
 		return errors.New("user last name is required")
 	}
 
-	if u.Role != RoleAdmin && u.Role != RoleDoctor && u.Role != RolePatient {
+	if !IsValidRole(string(u.Role)) {
 		return errors.New("invalid user role")
 	}
 
@@ -68,6 +75,11 @@ func (u *User) Validate() error {
 	return nil
 }
 
+// IsEmailVerified reports whether the user confirmed they own their email
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 // FullName returns the user's full name combining first and last name
 func (u *User) FullName() string {
 	return strings.TrimSpace(u.FirstName + " " + u.LastName)
@@ -76,5 +88,11 @@ func (u *User) FullName() string {
 // IsValidRole checks if a given role string is a valid UserRole
 func IsValidRole(role string) bool {
 	r := UserRole(role)
-	return r == RoleAdmin || r == RoleDoctor || r == RolePatient
+	return r == RolePatient || r.IsStaff()
+}
+
+// IsStaff reports whether the role belongs to clinic staff
+// Staff accounts are created through invitations, never by public signup
+func (r UserRole) IsStaff() bool {
+	return r == RoleAdmin || r == RoleDoctor || r == RoleNurse || r == RoleReceptionist
 }
@@ -0,0 +1,10 @@
+package domain
+
+// CheckVersion compares the version a client last saw with the current one
+// An expected version of zero means the client did not ask for the check
+func CheckVersion(current, expected int) error {
+	if expected != 0 && expected != current {
+		return ErrVersionConflict
+	}
+	return nil
+}
@@ -9,12 +9,52 @@ import (
 
 // AppointmentFilters represents filters for querying appointments
 type AppointmentFilters struct {
-	Status    string
-	DoctorID  string
-	PatientID string
-	ServiceID string
-	DateFrom  *time.Time
-	DateTo    *time.Time
+	ID         string
+	Status     string
+	DoctorID   string
+	PatientID  string
+	ServiceID  string
+	LocationID string
+	DateFrom   *time.Time
+	DateTo     *time.Time
+}
+
+// DoctorSearchFilters narrows the doctors a search ranks; zero values mean no filter
+// Words come folded by textsearch.Words and are matched against the search
+// columns, which hold the same folding of the doctor's text
+type DoctorSearchFilters struct {
+	Words     []string // Each must start a word of the name, specialty, an active service or the bio
+	Specialty []string // Each must start a word of the specialty
+	ServiceID string   // Only doctors that offer this active service
+	MinFee    *float64 // Minimum consultation fee
+	MaxFee    *float64 // Maximum consultation fee
+	DayOfWeek string   // Only doctors with an active schedule on this day
+}
+
+// DoctorSearchResult is an active doctor with the data a search ranks on
+type DoctorSearchResult struct {
+	User     *domain.User
+	Doctor   *domain.Doctor
+	Services []string // Names of the active services the doctor offers
+}
+
+// AuditFilters represents filters for querying the audit log
+type AuditFilters struct {
+	Entity   string
+	EntityID string
+	ActorID  string
+	DateFrom *time.Time
+	DateTo   *time.Time
+	Limit    int
+	Offset   int
+}
+
+// TxManager runs a unit of work atomically
+// Repository calls made with the context passed to fn take part in the same
+// transaction; fn returning an error rolls everything back
+type TxManager interface {
+	// WithinTx runs fn inside a transaction, joining the current one if ctx already carries it
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 // UserRepository defines the interface for user data persistence operations
@@ -28,20 +68,32 @@ type UserRepository interface {
 	// FindByEmail retrieves a user by their email address
 	FindByEmail(ctx context.Context, email string) (*domain.User, error)
 
+	// EmailExists reports whether any user holds the email, in any clinic and
+	// including deleted users: emails are unique across the installation
+	EmailExists(ctx context.Context, email string) (bool, error)
+
 	// Update modifies an existing user in the repository
 	Update(ctx context.Context, user *domain.User) error
 
-	// Delete removes a user from the repository by their ID
+	// MarkVerificationSent records when a verification email was last sent
+	// It is bookkeeping only and does not change the user's version
+	MarkVerificationSent(ctx context.Context, id string, sentAt time.Time) error
+
+	// Delete soft deletes a user; deleted users are hidden from every other query
 	Delete(ctx context.Context, id string) error
 
-	// List retrieves a paginated list of users
-	List(ctx context.Context, limit, offset int) ([]*domain.User, error)
+	// List retrieves one page of users sorted by created_at or name
+	List(ctx context.Context, page PageRequest) ([]*domain.User, error)
+
+	// ListDeleted retrieves soft deleted users, most recently deleted first
+	ListDeleted(ctx context.Context) ([]*domain.User, error)
 
-	// FindDoctorsBySpecialty retrieves all active doctors filtered by specialty
-	FindDoctorsBySpecialty(ctx context.Context, specialty string) ([]*domain.User, error)
+	// Restore clears the deletion mark of a soft deleted user
+	Restore(ctx context.Context, id string) error
 
-	// GetAllDoctors retrieves all active doctors
-	GetAllDoctors(ctx context.Context) ([]*domain.User, error)
+	// PurgeDeleted permanently removes users deleted before the given time
+	// Users with appointment history are kept; returns how many were removed
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
 
 	// FindDoctorIDByUserID returns the doctor.id for a given user_id
 	FindDoctorIDByUserID(ctx context.Context, userID string) (string, error)
@@ -57,6 +109,267 @@ type UserRepository interface {
 	CountAllActive(ctx context.Context) (int, error)
 }
 
+// RefreshTokenRepository defines the interface for refresh token persistence operations
+// Tokens are looked up by the hash of their value; the value itself is never stored
+type RefreshTokenRepository interface {
+	// Create stores a new refresh token
+	Create(ctx context.Context, token *domain.RefreshToken) error
+
+	// FindByHash retrieves a token by the hash of its value, or nil if there is none
+	FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+
+	// MarkUsed sets used_at on a token that is neither used nor revoked
+	// Returns false if another request used or revoked it first
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+
+	// RevokeSession revokes every token of a session
+	RevokeSession(ctx context.Context, sessionID string, revokedAt time.Time) error
+
+	// RevokeUserSessions revokes the tokens of every session of a user except
+	// keepSessionID, which may be empty to end them all
+	RevokeUserSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error
+
+	// IsSessionRevoked reports whether a session was revoked
+	// Sessions without any stored token count as revoked
+	IsSessionRevoked(ctx context.Context, sessionID string) (bool, error)
+
+	// PurgeExpired permanently removes tokens that expired before the given time
+	// Returns how many were removed
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// SessionRepository defines the interface for the devices users are logged in on
+// Whether a session is still active follows from its refresh tokens; see
+// RefreshTokenRepository for ending sessions
+type SessionRepository interface {
+	// Create stores a new session
+	Create(ctx context.Context, session *domain.Session) error
+
+	// FindByID retrieves a session by its ID, or nil if there is none
+	FindByID(ctx context.Context, id string) (*domain.Session, error)
+
+	// ListActiveByUser retrieves the sessions of a user that have a refresh token
+	// neither used, revoked nor expired at the given time, most recently seen first
+	ListActiveByUser(ctx context.Context, userID string, now time.Time) ([]*domain.Session, error)
+
+	// Touch sets last_seen_at of a session
+	Touch(ctx context.Context, id string, seenAt time.Time) error
+
+	// PurgeEnded permanently removes sessions last seen before the given time
+	// that have no refresh token left. Returns how many were removed
+	PurgeEnded(ctx context.Context, before time.Time) (int, error)
+}
+
+// PasswordResetTokenRepository defines the interface for password reset token persistence operations
+// Tokens are looked up by the hash of their value; the value itself is never stored
+type PasswordResetTokenRepository interface {
+	// Create stores a new password reset token
+	Create(ctx context.Context, token *domain.PasswordResetToken) error
+
+	// FindByHash retrieves a token by the hash of its value, or nil if there is none
+	FindByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error)
+
+	// MarkUsed sets used_at on a token that was not used yet
+	// Returns false if another request used it first
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+
+	// InvalidateUserTokens sets used_at on every unused token of a user
+	InvalidateUserTokens(ctx context.Context, userID string, usedAt time.Time) error
+
+	// PurgeExpired permanently removes tokens that expired before the given time
+	// Returns how many were removed
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// UsedTokenRepository records the IDs (jti) of signed single-use tokens, such
+// as the state of a single sign-on flow, until the tokens expire
+// The IDs are random, so they are not scoped to a clinic
+type UsedTokenRepository interface {
+	// Use records that the token with the given ID was used
+	// Returns false if it was used before
+	Use(ctx context.Context, id string, expiresAt time.Time) (bool, error)
+
+	// PurgeExpired permanently removes the IDs of tokens that expired before the given time
+	// Returns how many were removed
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+// InvitationRepository defines the interface for staff invitation persistence operations
+// Invitations are looked up by the hash of their token; the token itself is never stored
+type InvitationRepository interface {
+	// Create stores a new invitation
+	Create(ctx context.Context, invitation *domain.Invitation) error
+
+	// FindByID retrieves an invitation by its unique identifier, or nil if there is none
+	FindByID(ctx context.Context, id string) (*domain.Invitation, error)
+
+	// FindByHash retrieves an invitation by the hash of its token, or nil if there is none
+	FindByHash(ctx context.Context, tokenHash string) (*domain.Invitation, error)
+
+	// List retrieves every invitation, newest first
+	List(ctx context.Context) ([]*domain.Invitation, error)
+
+	// MarkAccepted records the account created from an invitation that is
+	// neither accepted nor revoked. Returns false if another request got there first
+	MarkAccepted(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error)
+
+	// Revoke sets revoked_at on an invitation that is neither accepted nor revoked
+	// Returns false if it was accepted or revoked first
+	Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error)
+
+	// RevokeByEmail revokes every open invitation sent to an email
+	RevokeByEmail(ctx context.Context, email string, revokedAt time.Time) error
+}
+
+// LoginLockoutRepository defines the interface for failed login counter persistence operations
+// There is at most one counter per scope and subject; unlock tokens are looked
+// up by their hash, the token itself is never stored
+type LoginLockoutRepository interface {
+	// ReserveAttempt counts a login attempt as failed on the counter of a scope
+	// and subject, creating it with the given ID if there is none, provided the
+	// counter still has the given failures (0 if there was none). A counter whose
+	// last failure is older than resetBefore starts over, dropping any lockout it had
+	// Returns false if a concurrent attempt changed the counter first
+	ReserveAttempt(ctx context.Context, id, scope, subject string, failures int, attemptAt, resetBefore time.Time) (bool, error)
+
+	// ReleaseAttempt takes back one failure from the counter of a scope and
+	// subject, once the attempt it reserved turned out right
+	ReleaseAttempt(ctx context.Context, scope, subject string) error
+
+	// Find retrieves the counter of a scope and subject, or nil if there is none
+	Find(ctx context.Context, scope, subject string) (*domain.LoginLockout, error)
+
+	// FindByID retrieves a counter by its unique identifier, or nil if there is none
+	FindByID(ctx context.Context, id string) (*domain.LoginLockout, error)
+
+	// FindByUnlockHash retrieves a counter by the hash of its unlock token, or nil if there is none
+	FindByUnlockHash(ctx context.Context, tokenHash string) (*domain.LoginLockout, error)
+
+	// Lock sets locked_until and the unlock token hash (empty for none) on a
+	// counter that is not locked at the given time
+	// Returns false if another request locked it first
+	Lock(ctx context.Context, id string, lockedUntil time.Time, unlockTokenHash string, now time.Time) (bool, error)
+
+	// ListLocked retrieves the counters still locked at the given time, optionally
+	// filtered by scope (empty for both), most recent failure first
+	ListLocked(ctx context.Context, scope string, now time.Time) ([]*domain.LoginLockout, error)
+
+	// Delete removes a counter, clearing its failures and lockout
+	// Returns false if there was none
+	Delete(ctx context.Context, id string) (bool, error)
+
+	// DeleteBySubject removes the counter of a scope and subject, if any
+	DeleteBySubject(ctx context.Context, scope, subject string) error
+
+	// PurgeStale permanently removes counters whose last failure is older than
+	// the given time and that are not locked anymore. Returns how many were removed
+	PurgeStale(ctx context.Context, before time.Time) (int, error)
+}
+
+// TwoFactorRepository defines the interface for TOTP second factor and recovery code persistence operations
+// Recovery codes are looked up by their hash; the codes themselves are never stored
+type TwoFactorRepository interface {
+	// Create stores a new second factor, not enabled yet
+	Create(ctx context.Context, twoFactor *domain.TwoFactor) error
+
+	// FindByUserID retrieves the second factor of a user, or nil if there is none
+	FindByUserID(ctx context.Context, userID string) (*domain.TwoFactor, error)
+
+	// Enable sets enabled_at on a second factor that is not enabled yet, and
+	// records the step of the code that confirmed it
+	// Returns false if another request enabled it first
+	Enable(ctx context.Context, userID string, step int64, enabledAt time.Time) (bool, error)
+
+	// UseStep records the step of an accepted code if it is newer than the last one
+	// Returns false if a code of that step or a later one was already used
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+
+	// Delete removes the second factor of a user together with their recovery codes
+	Delete(ctx context.Context, userID string) error
+
+	// ReplaceRecoveryCodes removes the recovery codes of a user and stores new ones
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*domain.RecoveryCode) error
+
+	// UseRecoveryCode sets used_at on an unused recovery code of a user
+	// Returns false if there is no such code
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) (bool, error)
+
+	// CountRecoveryCodes returns how many unused recovery codes a user has left
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+
+	// Reencrypt seals the secret of every second factor with the primary key
+	// Returns how many rows were rewritten
+	Reencrypt(ctx context.Context) (int, error)
+}
+
+// RoleRepository defines the interface for the permission sets clinics give their roles
+// Only roles a clinic changed have a stored definition; the rest keep their defaults
+type RoleRepository interface {
+	// FindByRole retrieves the stored definition of a role, or nil if there is none
+	FindByRole(ctx context.Context, role domain.UserRole) (*domain.RoleDefinition, error)
+
+	// List retrieves every stored definition of the clinic
+	List(ctx context.Context) ([]*domain.RoleDefinition, error)
+
+	// Save creates or replaces the stored definition of a role
+	Save(ctx context.Context, definition *domain.RoleDefinition) error
+
+	// Delete removes the stored definition of a role, so it goes back to its defaults
+	// Returns false if there was none
+	Delete(ctx context.Context, role domain.UserRole) (bool, error)
+}
+
+// APIKeyRepository defines the contract for API key data persistence
+type APIKeyRepository interface {
+	// Create stores a new API key
+	Create(ctx context.Context, key *domain.APIKey) error
+
+	// FindByID retrieves an API key by its unique identifier, or nil if there is none
+	FindByID(ctx context.Context, id string) (*domain.APIKey, error)
+
+	// FindByPrefix retrieves an API key by its prefix, or nil if there is none
+	// Requests are authenticated before their clinic is known, so call it with
+	// an unscoped context
+	FindByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error)
+
+	// List retrieves every API key, newest first
+	List(ctx context.Context) ([]*domain.APIKey, error)
+
+	// Revoke sets revoked_at on a key that is not revoked yet
+	// Returns false if it was revoked first
+	Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error)
+
+	// RecordUse stores when and from where a key was last used and counts the
+	// request in the usage of that day
+	RecordUse(ctx context.Context, id string, usedAt time.Time, ip string) error
+
+	// ListUsage retrieves the daily usage of a key since a day, oldest first
+	// Days without requests are left out
+	ListUsage(ctx context.Context, id string, since time.Time) ([]*domain.APIKeyUsage, error)
+}
+
+// ExternalIdentityRepository defines the interface for the links between staff
+// accounts and their identities at the single sign-on provider
+type ExternalIdentityRepository interface {
+	// Create links a user to an identity of a provider
+	// Returns domain.ErrDuplicateRecord if the user is already linked to an
+	// identity of that provider, or the identity to a user of the same clinic
+	Create(ctx context.Context, identity *domain.ExternalIdentity) error
+
+	// FindBySubject retrieves the identity a provider names with subject, or nil if there is none
+	FindBySubject(ctx context.Context, issuer, subject string) (*domain.ExternalIdentity, error)
+
+	// ListByUser retrieves the identities linked to a user
+	ListByUser(ctx context.Context, userID string) ([]*domain.ExternalIdentity, error)
+
+	// RecordLogin stores when an identity last signed in and the email the provider gave
+	RecordLogin(ctx context.Context, id, email string, loginAt time.Time) error
+
+	// Delete removes the link of an identity
+	// Returns false if there is none
+	Delete(ctx context.Context, id string) (bool, error)
+}
+
 // PatientRepository defines the interface for patient data persistence operations
 type PatientRepository interface {
 	// Create inserts a new patient into the repository
@@ -76,6 +389,14 @@ type PatientRepository interface {
 
 	// List retrieves a paginated list of patients
 	List(ctx context.Context, limit, offset int) ([]*domain.Patient, error)
+
+	// FindByDocument retrieves the patients with a document number, compared
+	// after domain.NormalizeDocumentNumber; an empty documentType matches any type
+	FindByDocument(ctx context.Context, documentType, documentNumber string) ([]*domain.Patient, error)
+
+	// Reencrypt seals the encrypted fields of every patient with the primary key
+	// and refreshes their blind indexes; returns how many patients were rewritten
+	Reencrypt(ctx context.Context) (int, error)
 }
 
 // DoctorRepository defines the interface for doctor data persistence operations
@@ -95,11 +416,27 @@ type DoctorRepository interface {
 	// Update modifies an existing doctor in the repository
 	Update(ctx context.Context, doctor *domain.Doctor) error
 
-	// Delete removes a doctor from the repository by their ID
+	// Delete soft deletes a doctor profile; deleted doctors are hidden from every other query
 	Delete(ctx context.Context, id string) error
 
 	// List retrieves a paginated list of doctors
 	List(ctx context.Context, limit, offset int) ([]*domain.Doctor, error)
+
+	// ListDeleted retrieves soft deleted doctors, most recently deleted first
+	ListDeleted(ctx context.Context) ([]*domain.Doctor, error)
+
+	// FindDeletedByID retrieves a soft deleted doctor, or nil if there is none with that ID
+	FindDeletedByID(ctx context.Context, id string) (*domain.Doctor, error)
+
+	// Restore clears the deletion mark of a soft deleted doctor
+	Restore(ctx context.Context, id string) error
+
+	// RestoreByUserID clears the deletion mark of a user's doctor profile, if it has one
+	RestoreByUserID(ctx context.Context, userID string) error
+
+	// FindSearchCandidates retrieves the active doctors, with their user and
+	// service names, that pass the filters
+	FindSearchCandidates(ctx context.Context, filters DoctorSearchFilters) ([]*DoctorSearchResult, error)
 }
 
 // AppointmentRepository defines the interface for appointment data persistence operations
@@ -110,16 +447,19 @@ type AppointmentRepository interface {
 	// FindByID retrieves an appointment by its unique identifier
 	FindByID(ctx context.Context, id string) (*domain.Appointment, error)
 
+	// FindByIDForUpdate retrieves an appointment and locks it until the current transaction ends
+	FindByIDForUpdate(ctx context.Context, id string) (*domain.Appointment, error)
+
+	// LockDoctor serializes bookings for a doctor until the current transaction ends
+	LockDoctor(ctx context.Context, doctorID string) error
+
 	// FindByPatientID retrieves all appointments for a specific patient
 	FindByPatientID(ctx context.Context, patientID string) ([]*domain.Appointment, error)
 
-	// FindByDoctorID retrieves all appointments for a specific doctor
-	FindByDoctorID(ctx context.Context, doctorID string) ([]*domain.Appointment, error)
-
 	// FindByDoctorAndDate retrieves all appointments for a doctor on a specific date
 	FindByDoctorAndDate(ctx context.Context, doctorID string, date time.Time) ([]*domain.Appointment, error)
 
-	// Update modifies an existing appointment in the repository
+	// Update modifies the schedule, status and notes of an existing appointment
 	Update(ctx context.Context, appointment *domain.Appointment) error
 
 	// Delete removes an appointment from the repository by its ID
@@ -130,6 +470,8 @@ type AppointmentRepository interface {
 
 	// FindByDoctorAndDateRange retrieves appointments for a doctor within a date range
 	FindByDoctorAndDateRange(ctx context.Context, doctorID string, start, end time.Time) ([]*domain.Appointment, error)
+	// FindByDoctorsAndDateRange retrieves the appointments of several doctors within a date range in one query
+	FindByDoctorsAndDateRange(ctx context.Context, doctorIDs []string, start, end time.Time) ([]*domain.Appointment, error)
 
 	// MarkReminder24hSent marks the 24-hour reminder as sent for an appointment
 	MarkReminder24hSent(ctx context.Context, id string) error
@@ -138,41 +480,49 @@ type AppointmentRepository interface {
 	MarkReminder1hSent(ctx context.Context, id string) error
 
 	// Analytics methods
+	// Each one covers a single location when locationID is set, the whole clinic otherwise
+
 	// CountByStatus counts appointments by status
-	CountByStatus(ctx context.Context, status string) (int, error)
+	CountByStatus(ctx context.Context, status, locationID string) (int, error)
 
 	// CountAll counts all appointments
-	CountAll(ctx context.Context) (int, error)
+	CountAll(ctx context.Context, locationID string) (int, error)
 
-	// GetTotalRevenue calculates total revenue from completed appointments
-	GetTotalRevenue(ctx context.Context) (float64, error)
+	// GetTotalRevenue calculates total revenue from completed appointments,
+	// each charged at the price of its service at its location
+	GetTotalRevenue(ctx context.Context, locationID string) (float64, error)
 
 	// GetRevenueByService gets revenue grouped by service
-	GetRevenueByService(ctx context.Context) (map[string]struct {
+	GetRevenueByService(ctx context.Context, locationID string) (map[string]struct {
 		ServiceName string
 		Count       int
 		Revenue     float64
 	}, error)
 
 	// GetTopDoctors gets doctors with most appointments
-	GetTopDoctors(ctx context.Context, limit int) ([]struct {
+	GetTopDoctors(ctx context.Context, limit int, locationID string) ([]struct {
 		DoctorID              string
 		TotalAppointments     int
 		CompletedAppointments int
 	}, error)
 
 	// GetTopServices gets most used services
-	GetTopServices(ctx context.Context, limit int) ([]struct {
+	GetTopServices(ctx context.Context, limit int, locationID string) ([]struct {
 		ServiceID   string
 		ServiceName string
 		Count       int
 	}, error)
 
-	// FindAllWithFilters retrieves all appointments with optional filters
-	FindAllWithFilters(ctx context.Context, filters AppointmentFilters) ([]*domain.Appointment, error)
+	// FindAllWithFilters retrieves one page of appointments matching the optional filters,
+	// sorted by scheduled_at or created_at
+	FindAllWithFilters(ctx context.Context, filters AppointmentFilters, page PageRequest) ([]*domain.Appointment, error)
 
 	// CountFutureAppointmentsByDoctorAndService counts future appointments for a doctor-service combination
 	CountFutureAppointmentsByDoctorAndService(ctx context.Context, doctorID, serviceID string) (int, error)
+
+	// Reencrypt seals the notes of every appointment with the primary key
+	// Returns how many appointments were rewritten
+	Reencrypt(ctx context.Context) (int, error)
 }
 
 // ScheduleRepository defines methods for schedule data access
@@ -185,9 +535,11 @@ type ScheduleRepository interface {
 
 	// FindByDoctorAndDay finds schedules for a doctor on a specific day
 	FindByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) ([]*domain.Schedule, error)
+	// FindByDoctorsAndDay finds the active schedules of several doctors on a specific day in one query
+	FindByDoctorsAndDay(ctx context.Context, doctorIDs []string, dayOfWeek string) ([]*domain.Schedule, error)
 
-	// FindByDoctor finds all schedules for a doctor
-	FindByDoctor(ctx context.Context, doctorID string) ([]*domain.Schedule, error)
+	// FindByDoctor finds one page of active schedules for a doctor, sorted by day or created_at
+	FindByDoctor(ctx context.Context, doctorID string, page PageRequest) ([]*domain.Schedule, error)
 
 	// Update updates a schedule
 	Update(ctx context.Context, schedule *domain.Schedule) error
@@ -197,6 +549,9 @@ type ScheduleRepository interface {
 
 	// DeleteByDoctorAndDay deletes all schedules for a doctor on a specific day
 	DeleteByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) error
+
+	// ListAll retrieves every schedule, active or not, oldest first
+	ListAll(ctx context.Context) ([]*domain.Schedule, error)
 }
 
 // ServiceRepository defines the interface for service data persistence operations
@@ -207,8 +562,8 @@ type ServiceRepository interface {
 	// FindByID retrieves a service by its unique identifier
 	FindByID(ctx context.Context, id string) (*domain.Service, error)
 
-	// ListActive retrieves all active services
-	ListActive(ctx context.Context) ([]*domain.Service, error)
+	// ListActive retrieves one page of active services sorted by name or created_at
+	ListActive(ctx context.Context, page PageRequest) ([]*domain.Service, error)
 
 	// ListAll retrieves all services (active and inactive)
 	ListAll(ctx context.Context) ([]*domain.Service, error)
@@ -216,8 +571,18 @@ type ServiceRepository interface {
 	// Update modifies an existing service in the repository
 	Update(ctx context.Context, service *domain.Service) error
 
-	// Delete removes a service from the repository by its ID
+	// Delete soft deletes a service; deleted services are hidden from every other query
 	Delete(ctx context.Context, id string) error
+
+	// ListDeleted retrieves soft deleted services, most recently deleted first
+	ListDeleted(ctx context.Context) ([]*domain.Service, error)
+
+	// Restore clears the deletion mark of a soft deleted service
+	Restore(ctx context.Context, id string) error
+
+	// PurgeDeleted permanently removes services deleted before the given time
+	// Services referenced by appointments are kept; returns how many were removed
+	PurgeDeleted(ctx context.Context, before time.Time) (int, error)
 }
 
 // DoctorServiceRepository defines the interface for doctor-service relationship data persistence operations
@@ -239,4 +604,66 @@ type DoctorServiceRepository interface {
 
 	// FindByDoctorAndService retrieves a specific doctor-service relationship
 	FindByDoctorAndService(ctx context.Context, doctorID, serviceID string) (*domain.DoctorService, error)
+
+	// ListAll retrieves every doctor-service relationship, active or not, oldest first
+	ListAll(ctx context.Context) ([]*domain.DoctorService, error)
+}
+
+// ClinicRepository defines the interface for the tenant structure: clinics,
+// their locations, the doctors working at each location and per location prices
+type ClinicRepository interface {
+	// CreateClinic inserts a new clinic
+	CreateClinic(ctx context.Context, clinic *domain.Clinic) error
+
+	// FindClinicByID retrieves a clinic, or nil if there is none with that ID
+	FindClinicByID(ctx context.Context, id string) (*domain.Clinic, error)
+
+	// ListClinics retrieves every clinic, oldest first
+	ListClinics(ctx context.Context) ([]*domain.Clinic, error)
+
+	// CreateLocation inserts a new location
+	CreateLocation(ctx context.Context, location *domain.Location) error
+
+	// FindLocationByID retrieves a location, or nil if there is none with that ID
+	FindLocationByID(ctx context.Context, id string) (*domain.Location, error)
+
+	// ListLocations retrieves the locations of the clinic, oldest first
+	ListLocations(ctx context.Context, activeOnly bool) ([]*domain.Location, error)
+
+	// UpdateLocation modifies a location if its version still matches the stored one
+	UpdateLocation(ctx context.Context, location *domain.Location) error
+
+	// AssignDoctor attaches a doctor to a location
+	AssignDoctor(ctx context.Context, doctorLocation *domain.DoctorLocation) error
+
+	// RemoveDoctor detaches a doctor from a location
+	RemoveDoctor(ctx context.Context, doctorID, locationID string) error
+
+	// FindLocationsByDoctor retrieves the locations a doctor works at, oldest first
+	FindLocationsByDoctor(ctx context.Context, doctorID string) ([]*domain.Location, error)
+
+	// ListDoctorLocations retrieves every doctor-location assignment, oldest first
+	ListDoctorLocations(ctx context.Context) ([]*domain.DoctorLocation, error)
+
+	// SetServicePrice creates or replaces the price of a service at a location
+	SetServicePrice(ctx context.Context, price *domain.ServicePrice) error
+
+	// RemoveServicePrice drops a price override so the service's own price applies again
+	RemoveServicePrice(ctx context.Context, serviceID, locationID string) error
+
+	// FindServicePrices retrieves the price overrides of a location
+	FindServicePrices(ctx context.Context, locationID string) ([]*domain.ServicePrice, error)
+
+	// ListServicePrices retrieves every price override, oldest first
+	ListServicePrices(ctx context.Context) ([]*domain.ServicePrice, error)
+}
+
+// AuditRepository defines the interface for audit log persistence operations
+// Entries are append-only: there is no update or delete
+type AuditRepository interface {
+	// Create appends an entry to the audit log
+	Create(ctx context.Context, entry *domain.AuditEntry) error
+
+	// List retrieves entries matching the filters, newest first
+	List(ctx context.Context, filters AuditFilters) ([]*domain.AuditEntry, error)
 }
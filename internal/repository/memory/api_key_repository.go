@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryAPIKeyRepository implements the APIKeyRepository interface on top of a Store
+type MemoryAPIKeyRepository struct {
+	store *Store
+}
+
+// NewMemoryAPIKeyRepository creates a new instance of MemoryAPIKeyRepository
+func NewMemoryAPIKeyRepository(store *Store) repository.APIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		store: store,
+	}
+}
+
+func apiKeyUsageKey(keyID string, day time.Time) string {
+	return keyID + "/" + day.Format(time.DateOnly)
+}
+
+// Create stores a new API key
+func (r *MemoryAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, key.ClinicID)
+	if err != nil {
+		return err
+	}
+	key.ClinicID = clinicID
+
+	if _, exists := r.store.apiKeys[key.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+	if _, exists := r.store.clinics[key.ClinicID]; !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, exists := r.store.users[key.CreatedBy]; key.CreatedBy != "" && !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+	for _, stored := range r.store.apiKeys {
+		if stored.Prefix == key.Prefix {
+			return domain.ErrDuplicateRecord
+		}
+	}
+
+	k := *key
+	k.Scopes = slices.Clone(key.Scopes)
+	r.store.apiKeys[k.ID] = k
+	return nil
+}
+
+// FindByID retrieves an API key by its unique identifier, or nil if there is none
+func (r *MemoryAPIKeyRepository) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	defer r.store.rlock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || !inClinic(ctx, key.ClinicID) {
+		return nil, nil
+	}
+	key.Scopes = slices.Clone(key.Scopes)
+	return &key, nil
+}
+
+// FindByPrefix retrieves an API key by its prefix, or nil if there is none
+func (r *MemoryAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, key := range r.store.apiKeys {
+		if key.Prefix == prefix && inClinic(ctx, key.ClinicID) {
+			k := key
+			k.Scopes = slices.Clone(key.Scopes)
+			return &k, nil
+		}
+	}
+	return nil, nil
+}
+
+// List retrieves every API key, newest first
+func (r *MemoryAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	defer r.store.rlock(ctx)()
+
+	var keys []*domain.APIKey
+	for _, key := range r.store.apiKeys {
+		if !inClinic(ctx, key.ClinicID) {
+			continue
+		}
+		k := key
+		k.Scopes = slices.Clone(key.Scopes)
+		keys = append(keys, &k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// Revoke sets revoked_at on a key that is not revoked yet
+// Returns false if it was revoked first
+func (r *MemoryAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || !inClinic(ctx, key.ClinicID) || key.RevokedAt != nil {
+		return false, nil
+	}
+	key.RevokedAt = &revokedAt
+	r.store.apiKeys[id] = key
+	return true, nil
+}
+
+// RecordUse stores when and from where a key was last used and counts the
+// request in the usage of that day
+func (r *MemoryAPIKeyRepository) RecordUse(ctx context.Context, id string, usedAt time.Time, ip string) error {
+	defer r.store.lock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if inClinic(ctx, key.ClinicID) {
+		key.LastUsedAt = &usedAt
+		key.LastUsedIP = ip
+		r.store.apiKeys[id] = key
+	}
+
+	day := usedAt.UTC().Truncate(24 * time.Hour)
+	usage := r.store.apiKeyUsage[apiKeyUsageKey(id, day)]
+	usage.KeyID = id
+	usage.Day = day
+	usage.Requests++
+	r.store.apiKeyUsage[apiKeyUsageKey(id, day)] = usage
+	return nil
+}
+
+// ListUsage retrieves the daily usage of a key since a day, oldest first
+func (r *MemoryAPIKeyRepository) ListUsage(ctx context.Context, id string, since time.Time) ([]*domain.APIKeyUsage, error) {
+	defer r.store.rlock(ctx)()
+
+	key, ok := r.store.apiKeys[id]
+	if !ok || !inClinic(ctx, key.ClinicID) {
+		return nil, nil
+	}
+
+	since = since.UTC().Truncate(24 * time.Hour)
+	var usage []*domain.APIKeyUsage
+	for _, day := range r.store.apiKeyUsage {
+		if day.KeyID == id && !day.Day.Before(since) {
+			d := day
+			usage = append(usage, &d)
+		}
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Day.Before(usage[j].Day)
+	})
+	return usage, nil
+}
@@ -0,0 +1,496 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryAppointmentRepository implements the AppointmentRepository interface on top of a Store
+type MemoryAppointmentRepository struct {
+	store *Store
+}
+
+// NewMemoryAppointmentRepository creates a new instance of MemoryAppointmentRepository
+func NewMemoryAppointmentRepository(store *Store) repository.AppointmentRepository {
+	return &MemoryAppointmentRepository{
+		store: store,
+	}
+}
+
+// Create inserts a new appointment into the store
+func (r *MemoryAppointmentRepository) Create(ctx context.Context, appointment *domain.Appointment) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.appointments[appointment.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	if _, ok := r.store.patients[appointment.PatientID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, ok := r.store.doctors[appointment.DoctorID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if appointment.ServiceID != "" {
+		if _, ok := r.store.services[appointment.ServiceID]; !ok {
+			return domain.ErrRelatedRecordNotFound
+		}
+	}
+
+	if appointment.LocationID == "" {
+		appointment.LocationID = domain.DefaultLocationID
+	}
+	if _, ok := r.store.locations[appointment.LocationID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	if r.store.overlaps(appointment) {
+		return domain.ErrSlotTaken
+	}
+
+	if appointment.Version == 0 {
+		appointment.Version = 1
+	}
+
+	// Names are resolved through joins on read, never stored
+	stored := *appointment
+	stored.PatientName = ""
+	stored.DoctorName = ""
+	stored.ServiceName = ""
+	r.store.appointments[appointment.ID] = stored
+
+	return nil
+}
+
+// FindByID retrieves an appointment by its unique identifier
+func (r *MemoryAppointmentRepository) FindByID(ctx context.Context, id string) (*domain.Appointment, error) {
+	defer r.store.rlock(ctx)()
+
+	appointment, ok := r.store.appointments[id]
+	if !ok || !r.store.locationInClinic(ctx, appointment.LocationID) {
+		return nil, nil
+	}
+
+	return &appointment, nil
+}
+
+// FindByIDForUpdate retrieves an appointment inside the current unit of work
+// MemoryTxManager already holds the store lock, so a plain read is enough
+func (r *MemoryAppointmentRepository) FindByIDForUpdate(ctx context.Context, id string) (*domain.Appointment, error) {
+	return r.FindByID(ctx, id)
+}
+
+// LockDoctor is a no-op: units of work are serialized by MemoryTxManager
+func (r *MemoryAppointmentRepository) LockDoctor(ctx context.Context, doctorID string) error {
+	return nil
+}
+
+// FindByPatientID retrieves all appointments for a specific patient with patient and doctor names
+func (r *MemoryAppointmentRepository) FindByPatientID(ctx context.Context, patientID string) ([]*domain.Appointment, error) {
+	appointments := r.findWithNames(ctx, func(a domain.Appointment) bool {
+		return a.PatientID == patientID
+	})
+	sortByScheduledAt(appointments, true)
+
+	return appointments, nil
+}
+
+// FindByDoctorAndDate retrieves all appointments for a doctor on a specific date
+func (r *MemoryAppointmentRepository) FindByDoctorAndDate(ctx context.Context, doctorID string, date time.Time) ([]*domain.Appointment, error) {
+	// Compare against the calendar day in the caller's location
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	return r.FindByDoctorAndDateRange(ctx, doctorID, startOfDay, endOfDay)
+}
+
+// FindByDoctorAndDateRange retrieves appointments for a doctor within [start, end)
+func (r *MemoryAppointmentRepository) FindByDoctorAndDateRange(ctx context.Context, doctorID string, start, end time.Time) ([]*domain.Appointment, error) {
+	appointments := r.find(ctx, func(a domain.Appointment) bool {
+		return a.DoctorID == doctorID && !a.ScheduledAt.Before(start) && a.ScheduledAt.Before(end)
+	})
+	sortByScheduledAt(appointments, false)
+
+	return appointments, nil
+}
+
+// FindByDoctorsAndDateRange retrieves the appointments of several doctors within [start, end)
+func (r *MemoryAppointmentRepository) FindByDoctorsAndDateRange(ctx context.Context, doctorIDs []string, start, end time.Time) ([]*domain.Appointment, error) {
+	appointments := r.find(ctx, func(a domain.Appointment) bool {
+		return slices.Contains(doctorIDs, a.DoctorID) && !a.ScheduledAt.Before(start) && a.ScheduledAt.Before(end)
+	})
+	sortByScheduledAt(appointments, false)
+
+	return appointments, nil
+}
+
+// Update modifies the schedule, location, status and notes of an existing
+// appointment if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *MemoryAppointmentRepository) Update(ctx context.Context, appointment *domain.Appointment) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.appointments[appointment.ID]
+	if !ok || !r.store.locationInClinic(ctx, existing.LocationID) {
+		return errors.New("appointment not found")
+	}
+
+	if existing.Version != appointment.Version {
+		return domain.ErrVersionConflict
+	}
+
+	existing.ScheduledAt = appointment.ScheduledAt
+	existing.Duration = appointment.Duration
+	existing.LocationID = appointment.LocationID
+	existing.Status = appointment.Status
+	if r.store.overlaps(&existing) {
+		return domain.ErrSlotTaken
+	}
+
+	existing.Notes = appointment.Notes
+	existing.UpdatedAt = appointment.UpdatedAt
+	existing.Version++
+	r.store.appointments[appointment.ID] = existing
+	appointment.Version = existing.Version
+
+	return nil
+}
+
+// Delete removes an appointment from the store by its ID
+func (r *MemoryAppointmentRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	if appointment, ok := r.store.appointments[id]; !ok || !r.store.locationInClinic(ctx, appointment.LocationID) {
+		return errors.New("appointment not found")
+	}
+
+	delete(r.store.appointments, id)
+	return nil
+}
+
+// FindByScheduledAtRange retrieves appointments within [start, end] with a specific status
+func (r *MemoryAppointmentRepository) FindByScheduledAtRange(ctx context.Context, start, end time.Time, status string) ([]*domain.Appointment, error) {
+	appointments := r.find(ctx, func(a domain.Appointment) bool {
+		return string(a.Status) == status && !a.ScheduledAt.Before(start) && !a.ScheduledAt.After(end)
+	})
+	sortByScheduledAt(appointments, false)
+
+	return appointments, nil
+}
+
+// MarkReminder24hSent marks the 24-hour reminder as sent for an appointment
+func (r *MemoryAppointmentRepository) MarkReminder24hSent(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	if appointment, ok := r.store.appointments[id]; ok && r.store.locationInClinic(ctx, appointment.LocationID) {
+		appointment.Reminder24hSent = true
+		r.store.appointments[id] = appointment
+	}
+
+	return nil
+}
+
+// MarkReminder1hSent marks the 1-hour reminder as sent for an appointment
+func (r *MemoryAppointmentRepository) MarkReminder1hSent(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	if appointment, ok := r.store.appointments[id]; ok && r.store.locationInClinic(ctx, appointment.LocationID) {
+		appointment.Reminder1hSent = true
+		r.store.appointments[id] = appointment
+	}
+
+	return nil
+}
+
+// CountByStatus counts appointments by status, at one location if locationID is set
+func (r *MemoryAppointmentRepository) CountByStatus(ctx context.Context, status, locationID string) (int, error) {
+	return len(r.find(ctx, func(a domain.Appointment) bool {
+		return string(a.Status) == status && (locationID == "" || a.LocationID == locationID)
+	})), nil
+}
+
+// CountAll counts all appointments, at one location if locationID is set
+func (r *MemoryAppointmentRepository) CountAll(ctx context.Context, locationID string) (int, error) {
+	return len(r.find(ctx, func(a domain.Appointment) bool {
+		return locationID == "" || a.LocationID == locationID
+	})), nil
+}
+
+// GetTotalRevenue sums the price of every completed appointment, each charged
+// at the price of its service at the location it took place
+func (r *MemoryAppointmentRepository) GetTotalRevenue(ctx context.Context, locationID string) (float64, error) {
+	defer r.store.rlock(ctx)()
+
+	var total float64
+	for _, appointment := range r.store.appointments {
+		if appointment.Status != domain.StatusCompleted || !r.store.atLocation(ctx, appointment.LocationID, locationID) {
+			continue
+		}
+		if service, ok := r.store.services[appointment.ServiceID]; ok {
+			total += r.store.priceAt(service, appointment.LocationID)
+		}
+	}
+
+	return total, nil
+}
+
+// GetRevenueByService gets revenue of completed appointments grouped by service,
+// priced like GetTotalRevenue
+func (r *MemoryAppointmentRepository) GetRevenueByService(ctx context.Context, locationID string) (map[string]struct {
+	ServiceName string
+	Count       int
+	Revenue     float64
+}, error) {
+	defer r.store.rlock(ctx)()
+
+	result := make(map[string]struct {
+		ServiceName string
+		Count       int
+		Revenue     float64
+	})
+
+	for _, appointment := range r.store.appointments {
+		if appointment.Status != domain.StatusCompleted || !r.store.atLocation(ctx, appointment.LocationID, locationID) {
+			continue
+		}
+		service, ok := r.store.services[appointment.ServiceID]
+		if !ok {
+			continue
+		}
+
+		item := result[service.ID]
+		item.ServiceName = service.Name
+		item.Count++
+		item.Revenue += r.store.priceAt(service, appointment.LocationID)
+		result[service.ID] = item
+	}
+
+	return result, nil
+}
+
+// GetTopDoctors gets doctors with most appointments
+func (r *MemoryAppointmentRepository) GetTopDoctors(ctx context.Context, limit int, locationID string) ([]struct {
+	DoctorID              string
+	TotalAppointments     int
+	CompletedAppointments int
+}, error) {
+	defer r.store.rlock(ctx)()
+
+	type doctorStats = struct {
+		DoctorID              string
+		TotalAppointments     int
+		CompletedAppointments int
+	}
+
+	byDoctor := make(map[string]*doctorStats)
+	for _, appointment := range r.store.appointments {
+		if !r.store.atLocation(ctx, appointment.LocationID, locationID) {
+			continue
+		}
+		stats, ok := byDoctor[appointment.DoctorID]
+		if !ok {
+			stats = &doctorStats{DoctorID: appointment.DoctorID}
+			byDoctor[appointment.DoctorID] = stats
+		}
+		stats.TotalAppointments++
+		if appointment.Status == domain.StatusCompleted {
+			stats.CompletedAppointments++
+		}
+	}
+
+	results := make([]doctorStats, 0, len(byDoctor))
+	for _, stats := range byDoctor {
+		results = append(results, *stats)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].TotalAppointments > results[j].TotalAppointments
+	})
+
+	return paginate(results, limit, 0), nil
+}
+
+// GetTopServices gets most used services across all appointment statuses
+func (r *MemoryAppointmentRepository) GetTopServices(ctx context.Context, limit int, locationID string) ([]struct {
+	ServiceID   string
+	ServiceName string
+	Count       int
+}, error) {
+	defer r.store.rlock(ctx)()
+
+	type serviceStats = struct {
+		ServiceID   string
+		ServiceName string
+		Count       int
+	}
+
+	byService := make(map[string]*serviceStats)
+	for _, appointment := range r.store.appointments {
+		if !r.store.atLocation(ctx, appointment.LocationID, locationID) {
+			continue
+		}
+		service, ok := r.store.services[appointment.ServiceID]
+		if !ok {
+			continue
+		}
+		stats, ok := byService[service.ID]
+		if !ok {
+			stats = &serviceStats{ServiceID: service.ID, ServiceName: service.Name}
+			byService[service.ID] = stats
+		}
+		stats.Count++
+	}
+
+	results := make([]serviceStats, 0, len(byService))
+	for _, stats := range byService {
+		results = append(results, *stats)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Count > results[j].Count
+	})
+
+	return paginate(results, limit, 0), nil
+}
+
+// FindAllWithFilters retrieves one page of appointments matching the optional filters,
+// including patient, doctor and service names when the related records exist
+func (r *MemoryAppointmentRepository) FindAllWithFilters(ctx context.Context, filters repository.AppointmentFilters, page repository.PageRequest) ([]*domain.Appointment, error) {
+	defer r.store.rlock(ctx)()
+
+	var appointments []*domain.Appointment
+	for _, appointment := range r.store.appointments {
+		if !r.store.atLocation(ctx, appointment.LocationID, filters.LocationID) {
+			continue
+		}
+		if filters.ID != "" && appointment.ID != filters.ID {
+			continue
+		}
+		if filters.Status != "" && string(appointment.Status) != filters.Status {
+			continue
+		}
+		if filters.DoctorID != "" && appointment.DoctorID != filters.DoctorID {
+			continue
+		}
+		if filters.PatientID != "" && appointment.PatientID != filters.PatientID {
+			continue
+		}
+		if filters.ServiceID != "" && appointment.ServiceID != filters.ServiceID {
+			continue
+		}
+		if filters.DateFrom != nil && appointment.ScheduledAt.Before(*filters.DateFrom) {
+			continue
+		}
+		if filters.DateTo != nil && appointment.ScheduledAt.After(*filters.DateTo) {
+			continue
+		}
+
+		a := appointment
+		a.PatientName, _ = r.store.patientName(a.PatientID)
+		a.DoctorName, _ = r.store.doctorName(a.DoctorID)
+		if service, ok := r.store.services[a.ServiceID]; ok {
+			a.ServiceName = service.Name
+		}
+		appointments = append(appointments, &a)
+	}
+
+	return repository.SlicePage(appointments, page,
+		func(a *domain.Appointment) string { return repository.AppointmentSortKey(a, page.Sort) },
+		func(a *domain.Appointment) string { return a.ID },
+	), nil
+}
+
+// CountFutureAppointmentsByDoctorAndService counts future, non-cancelled appointments
+// for a specific doctor-service combination
+func (r *MemoryAppointmentRepository) CountFutureAppointmentsByDoctorAndService(ctx context.Context, doctorID, serviceID string) (int, error) {
+	now := time.Now()
+
+	return len(r.find(ctx, func(a domain.Appointment) bool {
+		return a.DoctorID == doctorID &&
+			a.ServiceID == serviceID &&
+			a.ScheduledAt.After(now) &&
+			a.Status != domain.StatusCancelled
+	})), nil
+}
+
+// Reencrypt has nothing to do: the store only lives in memory and keeps no ciphertext
+func (r *MemoryAppointmentRepository) Reencrypt(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// find returns copies of the appointments matching the predicate
+func (r *MemoryAppointmentRepository) find(ctx context.Context, match func(domain.Appointment) bool) []*domain.Appointment {
+	defer r.store.rlock(ctx)()
+
+	var appointments []*domain.Appointment
+	for _, appointment := range r.store.appointments {
+		if !match(appointment) || !r.store.locationInClinic(ctx, appointment.LocationID) {
+			continue
+		}
+		a := appointment
+		appointments = append(appointments, &a)
+	}
+
+	return appointments
+}
+
+// findWithNames returns the matching appointments with patient and doctor names,
+// skipping rows whose patient or doctor cannot be resolved like an inner join would
+func (r *MemoryAppointmentRepository) findWithNames(ctx context.Context, match func(domain.Appointment) bool) []*domain.Appointment {
+	defer r.store.rlock(ctx)()
+
+	var appointments []*domain.Appointment
+	for _, appointment := range r.store.appointments {
+		if !match(appointment) || !r.store.locationInClinic(ctx, appointment.LocationID) {
+			continue
+		}
+		patientName, ok := r.store.patientName(appointment.PatientID)
+		if !ok {
+			continue
+		}
+		doctorName, ok := r.store.doctorName(appointment.DoctorID)
+		if !ok {
+			continue
+		}
+		a := appointment
+		a.PatientName = patientName
+		a.DoctorName = doctorName
+		appointments = append(appointments, &a)
+	}
+
+	return appointments
+}
+
+// overlaps reports whether an active appointment would overlap another active
+// appointment of the same doctor, like the appointments_no_overlap database guard
+// Callers must hold the lock
+func (s *Store) overlaps(appointment *domain.Appointment) bool {
+	if appointment.Status == domain.StatusCancelled {
+		return false
+	}
+
+	for id, other := range s.appointments {
+		if id == appointment.ID || other.DoctorID != appointment.DoctorID || other.Status == domain.StatusCancelled {
+			continue
+		}
+		if appointment.ScheduledAt.Before(other.EndTime()) && appointment.EndTime().After(other.ScheduledAt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortByScheduledAt orders appointments by scheduled time
+func sortByScheduledAt(appointments []*domain.Appointment, descending bool) {
+	sort.SliceStable(appointments, func(i, j int) bool {
+		if descending {
+			return appointments[i].ScheduledAt.After(appointments[j].ScheduledAt)
+		}
+		return appointments[i].ScheduledAt.Before(appointments[j].ScheduledAt)
+	})
+}
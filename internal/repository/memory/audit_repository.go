@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryAuditRepository implements the AuditRepository interface on top of a Store
+type MemoryAuditRepository struct {
+	store *Store
+}
+
+// NewMemoryAuditRepository creates a new instance of MemoryAuditRepository
+func NewMemoryAuditRepository(store *Store) repository.AuditRepository {
+	return &MemoryAuditRepository{
+		store: store,
+	}
+}
+
+// Create appends an entry to the audit log
+func (r *MemoryAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.auditLog[entry.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	clinicID, err := repository.ClinicFor(ctx, entry.ClinicID)
+	if err != nil {
+		return err
+	}
+	entry.ClinicID = clinicID
+
+	stored := *entry
+	stored.Changes = append([]byte(nil), entry.Changes...)
+	r.store.auditLog[entry.ID] = stored
+	return nil
+}
+
+// List retrieves entries matching the filters, newest first
+func (r *MemoryAuditRepository) List(ctx context.Context, filters repository.AuditFilters) ([]*domain.AuditEntry, error) {
+	defer r.store.rlock(ctx)()
+
+	var entries []*domain.AuditEntry
+	for _, entry := range r.store.auditLog {
+		if !inClinic(ctx, entry.ClinicID) {
+			continue
+		}
+		if filters.Entity != "" && entry.Entity != filters.Entity {
+			continue
+		}
+		if filters.EntityID != "" && entry.EntityID != filters.EntityID {
+			continue
+		}
+		if filters.ActorID != "" && entry.ActorID != filters.ActorID {
+			continue
+		}
+		if filters.DateFrom != nil && entry.CreatedAt.Before(*filters.DateFrom) {
+			continue
+		}
+		if filters.DateTo != nil && entry.CreatedAt.After(*filters.DateTo) {
+			continue
+		}
+
+		e := entry
+		e.Changes = append([]byte(nil), entry.Changes...)
+		entries = append(entries, &e)
+	}
+	sortByCreatedAtDesc(entries, func(e *domain.AuditEntry) time.Time { return e.CreatedAt })
+
+	return paginate(entries, filters.Limit, filters.Offset), nil
+}
@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryClinicRepository implements the ClinicRepository interface on top of a Store
+type MemoryClinicRepository struct {
+	store *Store
+}
+
+// NewMemoryClinicRepository creates a new instance of MemoryClinicRepository
+func NewMemoryClinicRepository(store *Store) repository.ClinicRepository {
+	return &MemoryClinicRepository{
+		store: store,
+	}
+}
+
+// doctorLocationKey and servicePriceKey build the composite primary keys of
+// the doctor_locations and service_prices tables
+func doctorLocationKey(doctorID, locationID string) string {
+	return doctorID + "/" + locationID
+}
+
+func servicePriceKey(serviceID, locationID string) string {
+	return serviceID + "/" + locationID
+}
+
+// priceAt returns what a service costs at a location: its override there, if
+// any, or else its own price
+// Callers must hold the lock
+func (s *Store) priceAt(service domain.Service, locationID string) float64 {
+	if price, ok := s.servicePrices[servicePriceKey(service.ID, locationID)]; ok {
+		return price.Price
+	}
+	return service.Price
+}
+
+// CreateClinic inserts a new clinic
+func (r *MemoryClinicRepository) CreateClinic(ctx context.Context, clinic *domain.Clinic) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.clinics[clinic.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	r.store.clinics[clinic.ID] = *clinic
+	return nil
+}
+
+// FindClinicByID retrieves a clinic, or nil if there is none with that ID
+func (r *MemoryClinicRepository) FindClinicByID(ctx context.Context, id string) (*domain.Clinic, error) {
+	defer r.store.rlock(ctx)()
+
+	clinic, ok := r.store.clinics[id]
+	if !ok || !inClinic(ctx, clinic.ID) {
+		return nil, nil
+	}
+
+	return &clinic, nil
+}
+
+// ListClinics retrieves every clinic, oldest first
+func (r *MemoryClinicRepository) ListClinics(ctx context.Context) ([]*domain.Clinic, error) {
+	defer r.store.rlock(ctx)()
+
+	var clinics []*domain.Clinic
+	for _, clinic := range r.store.clinics {
+		if !inClinic(ctx, clinic.ID) {
+			continue
+		}
+		c := clinic
+		clinics = append(clinics, &c)
+	}
+	sortByCreatedAtAsc(clinics, func(c *domain.Clinic) (time.Time, string) { return c.CreatedAt, c.ID })
+
+	return clinics, nil
+}
+
+// CreateLocation inserts a new location
+func (r *MemoryClinicRepository) CreateLocation(ctx context.Context, location *domain.Location) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.locations[location.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	clinicID, err := repository.ClinicFor(ctx, location.ClinicID)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.store.clinics[clinicID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	location.ClinicID = clinicID
+
+	if location.Version == 0 {
+		location.Version = 1
+	}
+
+	r.store.locations[location.ID] = *location
+	return nil
+}
+
+// FindLocationByID retrieves a location, or nil if there is none with that ID
+func (r *MemoryClinicRepository) FindLocationByID(ctx context.Context, id string) (*domain.Location, error) {
+	defer r.store.rlock(ctx)()
+
+	location, ok := r.store.locations[id]
+	if !ok || !inClinic(ctx, location.ClinicID) {
+		return nil, nil
+	}
+
+	return &location, nil
+}
+
+// ListLocations retrieves the locations of the clinic, oldest first
+func (r *MemoryClinicRepository) ListLocations(ctx context.Context, activeOnly bool) ([]*domain.Location, error) {
+	defer r.store.rlock(ctx)()
+
+	var locations []*domain.Location
+	for _, location := range r.store.locations {
+		if !inClinic(ctx, location.ClinicID) || (activeOnly && !location.IsActive) {
+			continue
+		}
+		l := location
+		locations = append(locations, &l)
+	}
+	sortByCreatedAtAsc(locations, func(l *domain.Location) (time.Time, string) { return l.CreatedAt, l.ID })
+
+	return locations, nil
+}
+
+// UpdateLocation modifies a location if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *MemoryClinicRepository) UpdateLocation(ctx context.Context, location *domain.Location) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.locations[location.ID]
+	if !ok || !inClinic(ctx, existing.ClinicID) {
+		return errors.New("location not found")
+	}
+
+	if existing.Version != location.Version {
+		return domain.ErrVersionConflict
+	}
+
+	existing.Name = location.Name
+	existing.Address = location.Address
+	existing.Phone = location.Phone
+	existing.IsActive = location.IsActive
+	existing.UpdatedAt = location.UpdatedAt
+	existing.Version++
+	r.store.locations[location.ID] = existing
+	location.Version = existing.Version
+
+	return nil
+}
+
+// AssignDoctor attaches a doctor to a location
+// Assigning a doctor twice is not an error
+func (r *MemoryClinicRepository) AssignDoctor(ctx context.Context, doctorLocation *domain.DoctorLocation) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.doctors[doctorLocation.DoctorID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, ok := r.store.locations[doctorLocation.LocationID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	key := doctorLocationKey(doctorLocation.DoctorID, doctorLocation.LocationID)
+	if _, exists := r.store.doctorLocations[key]; !exists {
+		r.store.doctorLocations[key] = *doctorLocation
+	}
+
+	return nil
+}
+
+// RemoveDoctor detaches a doctor from a location
+func (r *MemoryClinicRepository) RemoveDoctor(ctx context.Context, doctorID, locationID string) error {
+	defer r.store.lock(ctx)()
+
+	key := doctorLocationKey(doctorID, locationID)
+	if _, ok := r.store.doctorLocations[key]; !ok || !r.store.locationInClinic(ctx, locationID) {
+		return errors.New("doctor-location relationship not found")
+	}
+
+	delete(r.store.doctorLocations, key)
+	return nil
+}
+
+// FindLocationsByDoctor retrieves the locations a doctor works at, oldest first
+func (r *MemoryClinicRepository) FindLocationsByDoctor(ctx context.Context, doctorID string) ([]*domain.Location, error) {
+	defer r.store.rlock(ctx)()
+
+	var locations []*domain.Location
+	for _, dl := range r.store.doctorLocations {
+		if dl.DoctorID != doctorID {
+			continue
+		}
+		location, ok := r.store.locations[dl.LocationID]
+		if !ok || !inClinic(ctx, location.ClinicID) {
+			continue
+		}
+		locations = append(locations, &location)
+	}
+	sortByCreatedAtAsc(locations, func(l *domain.Location) (time.Time, string) { return l.CreatedAt, l.ID })
+
+	return locations, nil
+}
+
+// ListDoctorLocations retrieves every doctor-location assignment, oldest first
+func (r *MemoryClinicRepository) ListDoctorLocations(ctx context.Context) ([]*domain.DoctorLocation, error) {
+	defer r.store.rlock(ctx)()
+
+	var doctorLocations []*domain.DoctorLocation
+	for _, doctorLocation := range r.store.doctorLocations {
+		if !r.store.locationInClinic(ctx, doctorLocation.LocationID) {
+			continue
+		}
+		dl := doctorLocation
+		doctorLocations = append(doctorLocations, &dl)
+	}
+	sortByCreatedAtAsc(doctorLocations, func(dl *domain.DoctorLocation) (time.Time, string) {
+		return dl.CreatedAt, doctorLocationKey(dl.DoctorID, dl.LocationID)
+	})
+
+	return doctorLocations, nil
+}
+
+// SetServicePrice creates or replaces the price of a service at a location
+func (r *MemoryClinicRepository) SetServicePrice(ctx context.Context, price *domain.ServicePrice) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.services[price.ServiceID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, ok := r.store.locations[price.LocationID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	key := servicePriceKey(price.ServiceID, price.LocationID)
+	stored := *price
+	if existing, ok := r.store.servicePrices[key]; ok {
+		stored.CreatedAt = existing.CreatedAt
+	}
+	r.store.servicePrices[key] = stored
+
+	return nil
+}
+
+// RemoveServicePrice drops a price override so the service's own price applies again
+func (r *MemoryClinicRepository) RemoveServicePrice(ctx context.Context, serviceID, locationID string) error {
+	defer r.store.lock(ctx)()
+
+	key := servicePriceKey(serviceID, locationID)
+	if _, ok := r.store.servicePrices[key]; !ok || !r.store.locationInClinic(ctx, locationID) {
+		return errors.New("service price not found")
+	}
+
+	delete(r.store.servicePrices, key)
+	return nil
+}
+
+// FindServicePrices retrieves the price overrides of a location
+func (r *MemoryClinicRepository) FindServicePrices(ctx context.Context, locationID string) ([]*domain.ServicePrice, error) {
+	return r.findServicePrices(ctx, func(p domain.ServicePrice) bool {
+		return p.LocationID == locationID
+	}), nil
+}
+
+// ListServicePrices retrieves every price override, oldest first
+func (r *MemoryClinicRepository) ListServicePrices(ctx context.Context) ([]*domain.ServicePrice, error) {
+	return r.findServicePrices(ctx, func(domain.ServicePrice) bool { return true }), nil
+}
+
+// findServicePrices returns the price overrides matching the predicate, oldest first
+func (r *MemoryClinicRepository) findServicePrices(ctx context.Context, match func(domain.ServicePrice) bool) []*domain.ServicePrice {
+	defer r.store.rlock(ctx)()
+
+	var prices []*domain.ServicePrice
+	for _, price := range r.store.servicePrices {
+		if !match(price) || !r.store.locationInClinic(ctx, price.LocationID) {
+			continue
+		}
+		p := price
+		prices = append(prices, &p)
+	}
+	sortByCreatedAtAsc(prices, func(p *domain.ServicePrice) (time.Time, string) {
+		return p.CreatedAt, servicePriceKey(p.ServiceID, p.LocationID)
+	})
+
+	return prices
+}
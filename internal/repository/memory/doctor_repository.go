@@ -0,0 +1,310 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/textsearch"
+)
+
+// MemoryDoctorRepository implements the DoctorRepository interface on top of a Store
+type MemoryDoctorRepository struct {
+	store *Store
+}
+
+// NewMemoryDoctorRepository creates a new instance of MemoryDoctorRepository
+func NewMemoryDoctorRepository(store *Store) repository.DoctorRepository {
+	return &MemoryDoctorRepository{
+		store: store,
+	}
+}
+
+// Create inserts a new doctor into the store
+func (r *MemoryDoctorRepository) Create(ctx context.Context, doctor *domain.Doctor) error {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userInClinic(ctx, doctor.UserID) {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	return r.store.insertDoctor(doctor)
+}
+
+// insertDoctor validates constraints and stores a copy of the doctor
+// Callers must hold the write lock
+func (s *Store) insertDoctor(doctor *domain.Doctor) error {
+	if _, exists := s.doctors[doctor.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	if _, ok := s.users[doctor.UserID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	for _, existing := range s.doctors {
+		if existing.UserID == doctor.UserID {
+			return domain.ErrDuplicateRecord
+		}
+		if existing.LicenseNumber == doctor.LicenseNumber {
+			return domain.ErrLicenseNumberAlreadyExists
+		}
+	}
+
+	s.doctors[doctor.ID] = *doctor
+	return nil
+}
+
+// FindByID retrieves a doctor by their unique identifier
+func (r *MemoryDoctorRepository) FindByID(ctx context.Context, id string) (*domain.Doctor, error) {
+	defer r.store.rlock(ctx)()
+
+	doctor, ok := r.store.doctors[id]
+	if !ok || doctor.DeletedAt != nil || !r.store.userInClinic(ctx, doctor.UserID) {
+		return nil, nil
+	}
+
+	return &doctor, nil
+}
+
+// FindByUserID retrieves a doctor by their associated user ID
+func (r *MemoryDoctorRepository) FindByUserID(ctx context.Context, userID string) (*domain.Doctor, error) {
+	defer r.store.rlock(ctx)()
+
+	doctor, ok := r.store.doctorByUserID(userID)
+	if !ok || !r.store.userInClinic(ctx, userID) {
+		return nil, nil
+	}
+
+	return &doctor, nil
+}
+
+// FindBySpecialty retrieves all doctors whose specialty contains the given text
+func (r *MemoryDoctorRepository) FindBySpecialty(ctx context.Context, specialty string) ([]*domain.Doctor, error) {
+	defer r.store.rlock(ctx)()
+
+	needle := strings.ToLower(specialty)
+	var doctors []*domain.Doctor
+	for _, doctor := range r.store.doctors {
+		if doctor.DeletedAt != nil || !strings.Contains(strings.ToLower(doctor.Specialty), needle) || !r.store.userInClinic(ctx, doctor.UserID) {
+			continue
+		}
+		d := doctor
+		doctors = append(doctors, &d)
+	}
+	sortByCreatedAtDesc(doctors, func(d *domain.Doctor) time.Time { return d.CreatedAt })
+
+	return doctors, nil
+}
+
+// Update modifies an existing doctor in the store
+func (r *MemoryDoctorRepository) Update(ctx context.Context, doctor *domain.Doctor) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.doctors[doctor.ID]
+	if !ok || existing.DeletedAt != nil || !r.store.userInClinic(ctx, existing.UserID) {
+		return errors.New("doctor not found")
+	}
+
+	for id, other := range r.store.doctors {
+		if id != doctor.ID && other.LicenseNumber == doctor.LicenseNumber {
+			return domain.ErrLicenseNumberAlreadyExists
+		}
+	}
+
+	existing.Specialty = doctor.Specialty
+	existing.LicenseNumber = doctor.LicenseNumber
+	existing.YearsOfExperience = doctor.YearsOfExperience
+	existing.Education = doctor.Education
+	existing.Bio = doctor.Bio
+	existing.ConsultationFee = doctor.ConsultationFee
+	existing.IsAvailable = doctor.IsAvailable
+	existing.UpdatedAt = doctor.UpdatedAt
+	r.store.doctors[doctor.ID] = existing
+
+	return nil
+}
+
+// Delete performs a soft delete by setting DeletedAt
+func (r *MemoryDoctorRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	doctor, ok := r.store.doctors[id]
+	if !ok || doctor.DeletedAt != nil || !r.store.userInClinic(ctx, doctor.UserID) {
+		return errors.New("doctor not found")
+	}
+
+	now := time.Now()
+	doctor.DeletedAt = &now
+	doctor.UpdatedAt = now
+	r.store.doctors[id] = doctor
+
+	return nil
+}
+
+// List retrieves a paginated list of doctors, newest first
+func (r *MemoryDoctorRepository) List(ctx context.Context, limit, offset int) ([]*domain.Doctor, error) {
+	defer r.store.rlock(ctx)()
+
+	doctors := make([]*domain.Doctor, 0, len(r.store.doctors))
+	for _, doctor := range r.store.doctors {
+		if doctor.DeletedAt != nil || !r.store.userInClinic(ctx, doctor.UserID) {
+			continue
+		}
+		d := doctor
+		doctors = append(doctors, &d)
+	}
+	sortByCreatedAtDesc(doctors, func(d *domain.Doctor) time.Time { return d.CreatedAt })
+
+	return paginate(doctors, limit, offset), nil
+}
+
+// ListDeleted retrieves soft deleted doctors, most recently deleted first
+func (r *MemoryDoctorRepository) ListDeleted(ctx context.Context) ([]*domain.Doctor, error) {
+	defer r.store.rlock(ctx)()
+
+	var doctors []*domain.Doctor
+	for _, doctor := range r.store.doctors {
+		if doctor.DeletedAt == nil || !r.store.userInClinic(ctx, doctor.UserID) {
+			continue
+		}
+		d := doctor
+		doctors = append(doctors, &d)
+	}
+	sortByCreatedAtDesc(doctors, func(d *domain.Doctor) time.Time { return *d.DeletedAt })
+
+	return doctors, nil
+}
+
+// FindDeletedByID retrieves a soft deleted doctor, or nil if there is none with that ID
+func (r *MemoryDoctorRepository) FindDeletedByID(ctx context.Context, id string) (*domain.Doctor, error) {
+	defer r.store.rlock(ctx)()
+
+	doctor, ok := r.store.doctors[id]
+	if !ok || doctor.DeletedAt == nil || !r.store.userInClinic(ctx, doctor.UserID) {
+		return nil, nil
+	}
+
+	return &doctor, nil
+}
+
+// Restore clears the deletion mark of a soft deleted doctor
+func (r *MemoryDoctorRepository) Restore(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	doctor, ok := r.store.doctors[id]
+	if !ok || doctor.DeletedAt == nil || !r.store.userInClinic(ctx, doctor.UserID) {
+		return errors.New("doctor not found")
+	}
+
+	doctor.DeletedAt = nil
+	doctor.UpdatedAt = time.Now()
+	r.store.doctors[id] = doctor
+
+	return nil
+}
+
+// RestoreByUserID clears the deletion mark of a user's doctor profile, if it has one
+func (r *MemoryDoctorRepository) RestoreByUserID(ctx context.Context, userID string) error {
+	defer r.store.lock(ctx)()
+
+	for id, doctor := range r.store.doctors {
+		if doctor.UserID == userID && doctor.DeletedAt != nil && r.store.userInClinic(ctx, userID) {
+			doctor.DeletedAt = nil
+			doctor.UpdatedAt = time.Now()
+			r.store.doctors[id] = doctor
+		}
+	}
+
+	return nil
+}
+
+// FindSearchCandidates retrieves the active doctors, with their user and
+// service names, that pass the filters
+func (r *MemoryDoctorRepository) FindSearchCandidates(ctx context.Context, filters repository.DoctorSearchFilters) ([]*repository.DoctorSearchResult, error) {
+	defer r.store.rlock(ctx)()
+
+	var results []*repository.DoctorSearchResult
+	for _, doctor := range r.store.doctors {
+		if doctor.DeletedAt != nil {
+			continue
+		}
+		user, ok := r.store.users[doctor.UserID]
+		if !ok || user.Role != domain.RoleDoctor || !user.IsActive || user.DeletedAt != nil || !inClinic(ctx, user.ClinicID) {
+			continue
+		}
+		if filters.MinFee != nil && doctor.ConsultationFee < *filters.MinFee {
+			continue
+		}
+		if filters.MaxFee != nil && doctor.ConsultationFee > *filters.MaxFee {
+			continue
+		}
+		if filters.DayOfWeek != "" && !r.store.worksOn(doctor.ID, filters.DayOfWeek) {
+			continue
+		}
+		if len(filters.Specialty) > 0 && !textsearch.MatchesAll(filters.Specialty, doctor.Specialty) {
+			continue
+		}
+
+		offersService := filters.ServiceID == ""
+		var serviceNames []string
+		for _, ds := range r.store.doctorServices {
+			if ds.DoctorID != doctor.ID || !ds.IsActive {
+				continue
+			}
+			service, ok := r.store.services[ds.ServiceID]
+			if !ok || !service.IsActive || service.DeletedAt != nil {
+				continue
+			}
+			if service.ID == filters.ServiceID {
+				offersService = true
+			}
+			serviceNames = append(serviceNames, service.Name)
+		}
+		if !offersService {
+			continue
+		}
+		if len(filters.Words) > 0 && !matchesWords(filters.Words, user, doctor, serviceNames) {
+			continue
+		}
+		sort.Strings(serviceNames)
+
+		u, d := user, doctor
+		results = append(results, &repository.DoctorSearchResult{
+			User:     &u,
+			Doctor:   &d,
+			Services: serviceNames,
+		})
+	}
+
+	return results, nil
+}
+
+// matchesWords reports whether every query word starts a word of the
+// doctor's name, specialty, bio or one of their services
+func matchesWords(words []string, user domain.User, doctor domain.Doctor, serviceNames []string) bool {
+	fields := []textsearch.Field{
+		{Text: user.FirstName + " " + user.LastName, Weight: 1},
+		{Text: doctor.Specialty, Weight: 1},
+		{Text: doctor.Bio, Weight: 1},
+	}
+	for _, name := range serviceNames {
+		fields = append(fields, textsearch.Field{Text: name, Weight: 1})
+	}
+	return textsearch.Score(words, fields) > 0
+}
+
+// worksOn reports whether a doctor has an active schedule on a day of the week
+// Callers must hold the lock
+func (s *Store) worksOn(doctorID, dayOfWeek string) bool {
+	for _, schedule := range s.schedules {
+		if schedule.DoctorID == doctorID && schedule.DayOfWeek == dayOfWeek && schedule.IsActive {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryDoctorServiceRepository implements the DoctorServiceRepository interface on top of a Store
+type MemoryDoctorServiceRepository struct {
+	store *Store
+}
+
+// NewMemoryDoctorServiceRepository creates a new instance of MemoryDoctorServiceRepository
+func NewMemoryDoctorServiceRepository(store *Store) repository.DoctorServiceRepository {
+	return &MemoryDoctorServiceRepository{
+		store: store,
+	}
+}
+
+// Assign creates a relationship between a doctor and a service
+func (r *MemoryDoctorServiceRepository) Assign(ctx context.Context, doctorService *domain.DoctorService) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.doctorServices[doctorService.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	if _, ok := r.store.doctors[doctorService.DoctorID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if !r.store.serviceInClinic(ctx, doctorService.ServiceID) {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, ok := r.store.services[doctorService.ServiceID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	if _, exists := r.store.findDoctorService(doctorService.DoctorID, doctorService.ServiceID); exists {
+		return domain.ErrServiceAlreadyAssigned
+	}
+
+	r.store.doctorServices[doctorService.ID] = *doctorService
+	return nil
+}
+
+// Remove removes a service assignment from a doctor
+func (r *MemoryDoctorServiceRepository) Remove(ctx context.Context, doctorID, serviceID string) error {
+	defer r.store.lock(ctx)()
+
+	ds, ok := r.store.findDoctorService(doctorID, serviceID)
+	if !ok || !r.store.serviceInClinic(ctx, serviceID) {
+		return errors.New("doctor-service relationship not found")
+	}
+
+	delete(r.store.doctorServices, ds.ID)
+	return nil
+}
+
+// FindDoctorsByService returns all active doctors that actively offer a specific service
+func (r *MemoryDoctorServiceRepository) FindDoctorsByService(ctx context.Context, serviceID string) ([]*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	var users []*domain.User
+	for _, ds := range r.store.doctorServices {
+		if ds.ServiceID != serviceID || !ds.IsActive {
+			continue
+		}
+		doctor, ok := r.store.doctors[ds.DoctorID]
+		if !ok || doctor.DeletedAt != nil {
+			continue
+		}
+		user, ok := r.store.users[doctor.UserID]
+		if !ok || !user.IsActive || user.DeletedAt != nil || !inClinic(ctx, user.ClinicID) {
+			continue
+		}
+		u := user
+		users = append(users, &u)
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].FirstName != users[j].FirstName {
+			return users[i].FirstName < users[j].FirstName
+		}
+		return users[i].LastName < users[j].LastName
+	})
+
+	return users, nil
+}
+
+// FindServicesByDoctor returns all active services actively offered by a specific doctor
+func (r *MemoryDoctorServiceRepository) FindServicesByDoctor(ctx context.Context, doctorID string) ([]*domain.Service, error) {
+	defer r.store.rlock(ctx)()
+
+	var services []*domain.Service
+	for _, ds := range r.store.doctorServices {
+		if ds.DoctorID != doctorID || !ds.IsActive {
+			continue
+		}
+		service, ok := r.store.services[ds.ServiceID]
+		if !ok || !service.IsActive || service.DeletedAt != nil || !inClinic(ctx, service.ClinicID) {
+			continue
+		}
+		s := service
+		services = append(services, &s)
+	}
+	sortServicesByName(services)
+
+	return services, nil
+}
+
+// IsAssigned checks if a doctor is actively assigned to a service
+func (r *MemoryDoctorServiceRepository) IsAssigned(ctx context.Context, doctorID, serviceID string) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	ds, ok := r.store.findDoctorService(doctorID, serviceID)
+	return ok && ds.IsActive && r.store.serviceInClinic(ctx, serviceID), nil
+}
+
+// FindByDoctorAndService retrieves a specific doctor-service relationship
+func (r *MemoryDoctorServiceRepository) FindByDoctorAndService(ctx context.Context, doctorID, serviceID string) (*domain.DoctorService, error) {
+	defer r.store.rlock(ctx)()
+
+	ds, ok := r.store.findDoctorService(doctorID, serviceID)
+	if !ok || !r.store.serviceInClinic(ctx, serviceID) {
+		return nil, nil
+	}
+
+	return &ds, nil
+}
+
+// ListAll retrieves every doctor-service relationship, active or not, oldest first
+func (r *MemoryDoctorServiceRepository) ListAll(ctx context.Context) ([]*domain.DoctorService, error) {
+	defer r.store.rlock(ctx)()
+
+	doctorServices := make([]*domain.DoctorService, 0, len(r.store.doctorServices))
+	for _, doctorService := range r.store.doctorServices {
+		if !r.store.serviceInClinic(ctx, doctorService.ServiceID) {
+			continue
+		}
+		ds := doctorService
+		doctorServices = append(doctorServices, &ds)
+	}
+	sortByCreatedAtAsc(doctorServices, func(ds *domain.DoctorService) (time.Time, string) { return ds.CreatedAt, ds.ID })
+
+	return doctorServices, nil
+}
+
+// findDoctorService looks up the relationship for a doctor-service pair
+// Callers must hold the lock
+func (s *Store) findDoctorService(doctorID, serviceID string) (domain.DoctorService, bool) {
+	for _, ds := range s.doctorServices {
+		if ds.DoctorID == doctorID && ds.ServiceID == serviceID {
+			return ds, true
+		}
+	}
+	return domain.DoctorService{}, false
+}
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryExternalIdentityRepository implements the ExternalIdentityRepository interface on top of a Store
+type MemoryExternalIdentityRepository struct {
+	store *Store
+}
+
+// NewMemoryExternalIdentityRepository creates a new instance of MemoryExternalIdentityRepository
+func NewMemoryExternalIdentityRepository(store *Store) repository.ExternalIdentityRepository {
+	return &MemoryExternalIdentityRepository{
+		store: store,
+	}
+}
+
+// Create links a user to an identity of a provider
+func (r *MemoryExternalIdentityRepository) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, identity.ClinicID)
+	if err != nil {
+		return err
+	}
+	identity.ClinicID = clinicID
+
+	if _, exists := r.store.identities[identity.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+	user, exists := r.store.users[identity.UserID]
+	if !exists || user.ClinicID != identity.ClinicID {
+		return domain.ErrRelatedRecordNotFound
+	}
+	for _, stored := range r.store.identities {
+		if stored.Issuer != identity.Issuer {
+			continue
+		}
+		if stored.UserID == identity.UserID || (stored.ClinicID == identity.ClinicID && stored.Subject == identity.Subject) {
+			return domain.ErrDuplicateRecord
+		}
+	}
+
+	r.store.identities[identity.ID] = *identity
+	return nil
+}
+
+// FindBySubject retrieves the identity a provider names with subject, or nil if there is none
+func (r *MemoryExternalIdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*domain.ExternalIdentity, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, identity := range r.store.identities {
+		if identity.Issuer == issuer && identity.Subject == subject && inClinic(ctx, identity.ClinicID) {
+			i := identity
+			return &i, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListByUser retrieves the identities linked to a user, oldest first
+func (r *MemoryExternalIdentityRepository) ListByUser(ctx context.Context, userID string) ([]*domain.ExternalIdentity, error) {
+	defer r.store.rlock(ctx)()
+
+	var identities []*domain.ExternalIdentity
+	for _, identity := range r.store.identities {
+		if identity.UserID == userID && inClinic(ctx, identity.ClinicID) {
+			i := identity
+			identities = append(identities, &i)
+		}
+	}
+
+	sort.Slice(identities, func(i, j int) bool {
+		return identities[i].CreatedAt.Before(identities[j].CreatedAt)
+	})
+	return identities, nil
+}
+
+// RecordLogin stores when an identity last signed in and the email the provider gave
+func (r *MemoryExternalIdentityRepository) RecordLogin(ctx context.Context, id, email string, loginAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	identity, ok := r.store.identities[id]
+	if !ok || !inClinic(ctx, identity.ClinicID) {
+		return nil
+	}
+	identity.Email = email
+	identity.LastLoginAt = loginAt
+	r.store.identities[id] = identity
+	return nil
+}
+
+// Delete removes the link of an identity
+// Returns false if there is none
+func (r *MemoryExternalIdentityRepository) Delete(ctx context.Context, id string) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	identity, ok := r.store.identities[id]
+	if !ok || !inClinic(ctx, identity.ClinicID) {
+		return false, nil
+	}
+	delete(r.store.identities, id)
+	return true, nil
+}
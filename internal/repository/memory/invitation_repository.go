@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryInvitationRepository implements the InvitationRepository interface on top of a Store
+type MemoryInvitationRepository struct {
+	store *Store
+}
+
+// NewMemoryInvitationRepository creates a new instance of MemoryInvitationRepository
+func NewMemoryInvitationRepository(store *Store) repository.InvitationRepository {
+	return &MemoryInvitationRepository{
+		store: store,
+	}
+}
+
+// Create stores a new invitation
+func (r *MemoryInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, invitation.ClinicID)
+	if err != nil {
+		return err
+	}
+	invitation.ClinicID = clinicID
+
+	if _, exists := r.store.invitations[invitation.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+	if _, exists := r.store.clinics[invitation.ClinicID]; !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, exists := r.store.users[invitation.InvitedBy]; invitation.InvitedBy != "" && !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+	for _, stored := range r.store.invitations {
+		if stored.TokenHash == invitation.TokenHash {
+			return domain.ErrDuplicateRecord
+		}
+	}
+
+	r.store.invitations[invitation.ID] = *invitation
+	return nil
+}
+
+// FindByID retrieves an invitation by its unique identifier, or nil if there is none
+func (r *MemoryInvitationRepository) FindByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	defer r.store.rlock(ctx)()
+
+	invitation, ok := r.store.invitations[id]
+	if !ok || !inClinic(ctx, invitation.ClinicID) {
+		return nil, nil
+	}
+	return &invitation, nil
+}
+
+// FindByHash retrieves an invitation by the hash of its token, or nil if there is none
+func (r *MemoryInvitationRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, invitation := range r.store.invitations {
+		if invitation.TokenHash == tokenHash && inClinic(ctx, invitation.ClinicID) {
+			i := invitation
+			return &i, nil
+		}
+	}
+	return nil, nil
+}
+
+// List retrieves every invitation, newest first
+func (r *MemoryInvitationRepository) List(ctx context.Context) ([]*domain.Invitation, error) {
+	defer r.store.rlock(ctx)()
+
+	var invitations []*domain.Invitation
+	for _, invitation := range r.store.invitations {
+		if !inClinic(ctx, invitation.ClinicID) {
+			continue
+		}
+		i := invitation
+		invitations = append(invitations, &i)
+	}
+
+	sort.Slice(invitations, func(a, b int) bool {
+		if !invitations[a].CreatedAt.Equal(invitations[b].CreatedAt) {
+			return invitations[a].CreatedAt.After(invitations[b].CreatedAt)
+		}
+		return invitations[a].ID < invitations[b].ID
+	})
+	return invitations, nil
+}
+
+// MarkAccepted records the account created from an invitation that is neither accepted nor revoked
+// Returns false if another request got there first
+func (r *MemoryInvitationRepository) MarkAccepted(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	invitation, ok := r.store.invitations[id]
+	if !ok || invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !inClinic(ctx, invitation.ClinicID) {
+		return false, nil
+	}
+	if _, exists := r.store.users[userID]; !exists {
+		return false, domain.ErrRelatedRecordNotFound
+	}
+
+	invitation.AcceptedAt = &acceptedAt
+	invitation.UserID = userID
+	r.store.invitations[id] = invitation
+	return true, nil
+}
+
+// Revoke sets revoked_at on an invitation that is neither accepted nor revoked
+// Returns false if it was accepted or revoked first
+func (r *MemoryInvitationRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	invitation, ok := r.store.invitations[id]
+	if !ok || invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !inClinic(ctx, invitation.ClinicID) {
+		return false, nil
+	}
+
+	invitation.RevokedAt = &revokedAt
+	r.store.invitations[id] = invitation
+	return true, nil
+}
+
+// RevokeByEmail revokes every open invitation sent to an email
+func (r *MemoryInvitationRepository) RevokeByEmail(ctx context.Context, email string, revokedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	for id, invitation := range r.store.invitations {
+		if invitation.Email != email || invitation.AcceptedAt != nil || invitation.RevokedAt != nil || !inClinic(ctx, invitation.ClinicID) {
+			continue
+		}
+		invitation.RevokedAt = &revokedAt
+		r.store.invitations[id] = invitation
+	}
+	return nil
+}
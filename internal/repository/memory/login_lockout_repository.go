@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryLoginLockoutRepository implements the LoginLockoutRepository interface on top of a Store
+type MemoryLoginLockoutRepository struct {
+	store *Store
+}
+
+// NewMemoryLoginLockoutRepository creates a new instance of MemoryLoginLockoutRepository
+func NewMemoryLoginLockoutRepository(store *Store) repository.LoginLockoutRepository {
+	return &MemoryLoginLockoutRepository{
+		store: store,
+	}
+}
+
+// ReserveAttempt counts a login attempt as failed on the counter of a scope and
+// subject, creating it with the given ID if there is none, provided the counter
+// still has the given failures. A counter whose last failure is older than
+// resetBefore starts over. Returns false if a concurrent attempt changed it first
+func (r *MemoryLoginLockoutRepository) ReserveAttempt(ctx context.Context, id, scope, subject string, failures int, attemptAt, resetBefore time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	lockout, ok := r.store.findLockout(clinicID, scope, subject)
+	switch {
+	case !ok:
+		if _, exists := r.store.clinics[clinicID]; !exists {
+			return false, domain.ErrRelatedRecordNotFound
+		}
+		lockout = domain.LoginLockout{
+			ID:        id,
+			ClinicID:  clinicID,
+			Scope:     scope,
+			Subject:   subject,
+			CreatedAt: attemptAt,
+		}
+	case lockout.Failures != failures:
+		return false, nil
+	case lockout.LastFailureAt.Before(resetBefore):
+		lockout.Failures = 0
+		lockout.LockedUntil = nil
+		lockout.UnlockTokenHash = ""
+	}
+
+	lockout.Failures++
+	lockout.LastFailureAt = attemptAt
+	r.store.loginLockouts[lockout.ID] = lockout
+	return true, nil
+}
+
+// ReleaseAttempt takes back one failure from the counter of a scope and subject
+func (r *MemoryLoginLockoutRepository) ReleaseAttempt(ctx context.Context, scope, subject string) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if lockout, ok := r.store.findLockout(clinicID, scope, subject); ok && lockout.Failures > 0 {
+		lockout.Failures--
+		r.store.loginLockouts[lockout.ID] = lockout
+	}
+	return nil
+}
+
+// Find retrieves the counter of a scope and subject, or nil if there is none
+func (r *MemoryLoginLockoutRepository) Find(ctx context.Context, scope, subject string) (*domain.LoginLockout, error) {
+	defer r.store.rlock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	lockout, ok := r.store.findLockout(clinicID, scope, subject)
+	if !ok {
+		return nil, nil
+	}
+	return &lockout, nil
+}
+
+// FindByID retrieves a counter by its unique identifier, or nil if there is none
+func (r *MemoryLoginLockoutRepository) FindByID(ctx context.Context, id string) (*domain.LoginLockout, error) {
+	defer r.store.rlock(ctx)()
+
+	lockout, ok := r.store.loginLockouts[id]
+	if !ok || !inClinic(ctx, lockout.ClinicID) {
+		return nil, nil
+	}
+	return &lockout, nil
+}
+
+// FindByUnlockHash retrieves a counter by the hash of its unlock token, or nil if there is none
+func (r *MemoryLoginLockoutRepository) FindByUnlockHash(ctx context.Context, tokenHash string) (*domain.LoginLockout, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, lockout := range r.store.loginLockouts {
+		if lockout.UnlockTokenHash != "" && lockout.UnlockTokenHash == tokenHash && inClinic(ctx, lockout.ClinicID) {
+			l := lockout
+			return &l, nil
+		}
+	}
+	return nil, nil
+}
+
+// Lock sets locked_until and the unlock token hash on a counter that is not locked at the given time
+// Returns false if another request locked it first
+func (r *MemoryLoginLockoutRepository) Lock(ctx context.Context, id string, lockedUntil time.Time, unlockTokenHash string, now time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	lockout, ok := r.store.loginLockouts[id]
+	if !ok || lockout.IsLocked(now) || !inClinic(ctx, lockout.ClinicID) {
+		return false, nil
+	}
+
+	lockout.LockedUntil = &lockedUntil
+	lockout.UnlockTokenHash = unlockTokenHash
+	r.store.loginLockouts[id] = lockout
+	return true, nil
+}
+
+// ListLocked retrieves the counters still locked at the given time, most recent failure first
+func (r *MemoryLoginLockoutRepository) ListLocked(ctx context.Context, scope string, now time.Time) ([]*domain.LoginLockout, error) {
+	defer r.store.rlock(ctx)()
+
+	var lockouts []*domain.LoginLockout
+	for _, lockout := range r.store.loginLockouts {
+		if !lockout.IsLocked(now) || (scope != "" && lockout.Scope != scope) || !inClinic(ctx, lockout.ClinicID) {
+			continue
+		}
+		l := lockout
+		lockouts = append(lockouts, &l)
+	}
+
+	sort.Slice(lockouts, func(a, b int) bool {
+		if !lockouts[a].LastFailureAt.Equal(lockouts[b].LastFailureAt) {
+			return lockouts[a].LastFailureAt.After(lockouts[b].LastFailureAt)
+		}
+		return lockouts[a].ID < lockouts[b].ID
+	})
+	return lockouts, nil
+}
+
+// Delete removes a counter, clearing its failures and lockout
+// Returns false if there was none
+func (r *MemoryLoginLockoutRepository) Delete(ctx context.Context, id string) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	lockout, ok := r.store.loginLockouts[id]
+	if !ok || !inClinic(ctx, lockout.ClinicID) {
+		return false, nil
+	}
+
+	delete(r.store.loginLockouts, id)
+	return true, nil
+}
+
+// DeleteBySubject removes the counter of a scope and subject, if any
+func (r *MemoryLoginLockoutRepository) DeleteBySubject(ctx context.Context, scope, subject string) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	if lockout, ok := r.store.findLockout(clinicID, scope, subject); ok {
+		delete(r.store.loginLockouts, lockout.ID)
+	}
+	return nil
+}
+
+// PurgeStale permanently removes counters whose last failure is older than the
+// given time and that are not locked anymore
+func (r *MemoryLoginLockoutRepository) PurgeStale(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	now := time.Now()
+	purged := 0
+	for id, lockout := range r.store.loginLockouts {
+		if lockout.LastFailureAt.Before(before) && !lockout.IsLocked(now) && inClinic(ctx, lockout.ClinicID) {
+			delete(r.store.loginLockouts, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// findLockout returns the counter of a scope and subject in a clinic
+// Callers must hold the lock
+func (s *Store) findLockout(clinicID, scope, subject string) (domain.LoginLockout, bool) {
+	for _, lockout := range s.loginLockouts {
+		if lockout.ClinicID == clinicID && lockout.Scope == scope && lockout.Subject == subject {
+			return lockout, true
+		}
+	}
+	return domain.LoginLockout{}, false
+}
@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryPasswordResetTokenRepository implements the PasswordResetTokenRepository interface on top of a Store
+type MemoryPasswordResetTokenRepository struct {
+	store *Store
+}
+
+// NewMemoryPasswordResetTokenRepository creates a new instance of MemoryPasswordResetTokenRepository
+func NewMemoryPasswordResetTokenRepository(store *Store) repository.PasswordResetTokenRepository {
+	return &MemoryPasswordResetTokenRepository{
+		store: store,
+	}
+}
+
+// Create stores a new password reset token
+func (r *MemoryPasswordResetTokenRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.resetTokens[token.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+	if _, exists := r.store.users[token.UserID]; !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+	for _, stored := range r.store.resetTokens {
+		if stored.TokenHash == token.TokenHash {
+			return domain.ErrDuplicateRecord
+		}
+	}
+
+	r.store.resetTokens[token.ID] = *token
+	return nil
+}
+
+// FindByHash retrieves a token by the hash of its value, or nil if there is none
+func (r *MemoryPasswordResetTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, token := range r.store.resetTokens {
+		if token.TokenHash == tokenHash && r.store.userInClinic(ctx, token.UserID) {
+			t := token
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// MarkUsed sets used_at on a token that was not used yet
+// Returns false if another request used it first
+func (r *MemoryPasswordResetTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	token, ok := r.store.resetTokens[id]
+	if !ok || token.UsedAt != nil || !r.store.userInClinic(ctx, token.UserID) {
+		return false, nil
+	}
+
+	token.UsedAt = &usedAt
+	r.store.resetTokens[id] = token
+	return true, nil
+}
+
+// InvalidateUserTokens sets used_at on every unused token of a user
+func (r *MemoryPasswordResetTokenRepository) InvalidateUserTokens(ctx context.Context, userID string, usedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	for id, token := range r.store.resetTokens {
+		if token.UserID != userID || token.UsedAt != nil || !r.store.userInClinic(ctx, token.UserID) {
+			continue
+		}
+		token.UsedAt = &usedAt
+		r.store.resetTokens[id] = token
+	}
+	return nil
+}
+
+// PurgeExpired permanently removes tokens that expired before the given time
+func (r *MemoryPasswordResetTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	purged := 0
+	for id, token := range r.store.resetTokens {
+		if token.ExpiresAt.Before(before) && r.store.userInClinic(ctx, token.UserID) {
+			delete(r.store.resetTokens, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
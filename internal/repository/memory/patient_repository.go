@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryPatientRepository implements the PatientRepository interface on top of a Store
+type MemoryPatientRepository struct {
+	store *Store
+}
+
+// NewMemoryPatientRepository creates a new instance of MemoryPatientRepository
+func NewMemoryPatientRepository(store *Store) repository.PatientRepository {
+	return &MemoryPatientRepository{
+		store: store,
+	}
+}
+
+// Create inserts a new patient into the store
+func (r *MemoryPatientRepository) Create(ctx context.Context, patient *domain.Patient) error {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userInClinic(ctx, patient.UserID) {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	return r.store.insertPatient(patient)
+}
+
+// insertPatient validates constraints and stores a copy of the patient
+// Callers must hold the write lock
+func (s *Store) insertPatient(patient *domain.Patient) error {
+	if _, exists := s.patients[patient.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	if _, ok := s.users[patient.UserID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	if _, exists := s.patientByUserID(patient.UserID); exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	s.patients[patient.ID] = copyPatient(*patient)
+	return nil
+}
+
+// FindByID retrieves a patient by their unique identifier
+func (r *MemoryPatientRepository) FindByID(ctx context.Context, id string) (*domain.Patient, error) {
+	defer r.store.rlock(ctx)()
+
+	patient, ok := r.store.patients[id]
+	if !ok || !r.store.userInClinic(ctx, patient.UserID) {
+		return nil, nil
+	}
+
+	patient = copyPatient(patient)
+	return &patient, nil
+}
+
+// FindByUserID retrieves a patient by their associated user ID
+func (r *MemoryPatientRepository) FindByUserID(ctx context.Context, userID string) (*domain.Patient, error) {
+	defer r.store.rlock(ctx)()
+
+	patient, ok := r.store.patientByUserID(userID)
+	if !ok || !r.store.userInClinic(ctx, userID) {
+		return nil, nil
+	}
+
+	patient = copyPatient(patient)
+	return &patient, nil
+}
+
+// Update modifies an existing patient in the store
+func (r *MemoryPatientRepository) Update(ctx context.Context, patient *domain.Patient) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.patients[patient.ID]
+	if !ok || !r.store.userInClinic(ctx, existing.UserID) {
+		return errors.New("patient not found")
+	}
+
+	existing.Birthdate = patient.Birthdate
+	existing.DocumentType = patient.DocumentType
+	existing.DocumentNumber = patient.DocumentNumber
+	existing.Address = patient.Address
+	existing.EmergencyContactName = patient.EmergencyContactName
+	existing.EmergencyContactPhone = patient.EmergencyContactPhone
+	existing.BloodType = patient.BloodType
+	existing.Allergies = patient.Allergies
+	existing.UpdatedAt = patient.UpdatedAt
+	r.store.patients[patient.ID] = copyPatient(existing)
+
+	return nil
+}
+
+// Delete removes a patient and their appointments from the store
+func (r *MemoryPatientRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	if patient, ok := r.store.patients[id]; !ok || !r.store.userInClinic(ctx, patient.UserID) {
+		return errors.New("patient not found")
+	}
+
+	r.store.deletePatientCascade(id)
+	return nil
+}
+
+// List retrieves a paginated list of patients, newest first
+func (r *MemoryPatientRepository) List(ctx context.Context, limit, offset int) ([]*domain.Patient, error) {
+	defer r.store.rlock(ctx)()
+
+	patients := make([]*domain.Patient, 0, len(r.store.patients))
+	for _, patient := range r.store.patients {
+		if !r.store.userInClinic(ctx, patient.UserID) {
+			continue
+		}
+		p := copyPatient(patient)
+		patients = append(patients, &p)
+	}
+	sortByCreatedAtDesc(patients, func(p *domain.Patient) time.Time { return p.CreatedAt })
+
+	return paginate(patients, limit, offset), nil
+}
+
+// FindByDocument retrieves the patients with a document number, newest first
+// The store keeps nothing at rest, so the normalized numbers are compared directly
+func (r *MemoryPatientRepository) FindByDocument(ctx context.Context, documentType, documentNumber string) ([]*domain.Patient, error) {
+	defer r.store.rlock(ctx)()
+
+	patients := []*domain.Patient{}
+	normalized := domain.NormalizeDocumentNumber(documentNumber)
+	if normalized == "" {
+		return patients, nil
+	}
+
+	for _, patient := range r.store.patients {
+		if domain.NormalizeDocumentNumber(patient.DocumentNumber) != normalized ||
+			(documentType != "" && patient.DocumentType != documentType) ||
+			!r.store.userInClinic(ctx, patient.UserID) {
+			continue
+		}
+		p := copyPatient(patient)
+		patients = append(patients, &p)
+	}
+	sortByCreatedAtDesc(patients, func(p *domain.Patient) time.Time { return p.CreatedAt })
+
+	return patients, nil
+}
+
+// Reencrypt has nothing to do: the store only lives in memory and keeps no ciphertext
+func (r *MemoryPatientRepository) Reencrypt(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// copyPatient detaches the allergies slice so callers cannot mutate stored data
+func copyPatient(patient domain.Patient) domain.Patient {
+	patient.Allergies = append([]string{}, patient.Allergies...)
+	return patient
+}
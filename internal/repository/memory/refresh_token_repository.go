@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryRefreshTokenRepository implements the RefreshTokenRepository interface on top of a Store
+type MemoryRefreshTokenRepository struct {
+	store *Store
+}
+
+// NewMemoryRefreshTokenRepository creates a new instance of MemoryRefreshTokenRepository
+func NewMemoryRefreshTokenRepository(store *Store) repository.RefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{
+		store: store,
+	}
+}
+
+// Create stores a new refresh token
+func (r *MemoryRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.refreshTokens[token.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+	if _, exists := r.store.users[token.UserID]; !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+	for _, stored := range r.store.refreshTokens {
+		if stored.TokenHash == token.TokenHash {
+			return domain.ErrDuplicateRecord
+		}
+	}
+
+	r.store.refreshTokens[token.ID] = *token
+	return nil
+}
+
+// FindByHash retrieves a token by the hash of its value, or nil if there is none
+func (r *MemoryRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash && r.store.userInClinic(ctx, token.UserID) {
+			t := token
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+// MarkUsed sets used_at on a token that is neither used nor revoked
+// Returns false if another request used or revoked it first
+func (r *MemoryRefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	token, ok := r.store.refreshTokens[id]
+	if !ok || token.UsedAt != nil || token.RevokedAt != nil || !r.store.userInClinic(ctx, token.UserID) {
+		return false, nil
+	}
+
+	token.UsedAt = &usedAt
+	r.store.refreshTokens[id] = token
+	return true, nil
+}
+
+// RevokeSession revokes every token of a session
+func (r *MemoryRefreshTokenRepository) RevokeSession(ctx context.Context, sessionID string, revokedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	r.store.revokeTokens(ctx, revokedAt, func(token domain.RefreshToken) bool {
+		return token.SessionID == sessionID
+	})
+	return nil
+}
+
+// RevokeUserSessions revokes the tokens of every session of a user except keepSessionID
+func (r *MemoryRefreshTokenRepository) RevokeUserSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	r.store.revokeTokens(ctx, revokedAt, func(token domain.RefreshToken) bool {
+		return token.UserID == userID && token.SessionID != keepSessionID
+	})
+	return nil
+}
+
+// IsSessionRevoked reports whether a session was revoked
+// Sessions without any stored token count as revoked
+func (r *MemoryRefreshTokenRepository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	found := false
+	for _, token := range r.store.refreshTokens {
+		if token.SessionID != sessionID || !r.store.userInClinic(ctx, token.UserID) {
+			continue
+		}
+		if token.RevokedAt != nil {
+			return true, nil
+		}
+		found = true
+	}
+	return !found, nil
+}
+
+// PurgeExpired permanently removes tokens that expired before the given time
+func (r *MemoryRefreshTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	purged := 0
+	for id, token := range r.store.refreshTokens {
+		if token.ExpiresAt.Before(before) && r.store.userInClinic(ctx, token.UserID) {
+			delete(r.store.refreshTokens, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// revokeTokens sets revoked_at on the tokens visible from ctx that match and are not revoked yet
+// Callers must hold the write lock
+func (s *Store) revokeTokens(ctx context.Context, revokedAt time.Time, match func(domain.RefreshToken) bool) {
+	for id, token := range s.refreshTokens {
+		if token.RevokedAt != nil || !match(token) || !s.userInClinic(ctx, token.UserID) {
+			continue
+		}
+		token.RevokedAt = &revokedAt
+		s.refreshTokens[id] = token
+	}
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryRoleRepository implements the RoleRepository interface on top of a Store
+// Definitions belong to the clinic ctx is scoped to, or the default clinic
+type MemoryRoleRepository struct {
+	store *Store
+}
+
+// NewMemoryRoleRepository creates a new instance of MemoryRoleRepository
+func NewMemoryRoleRepository(store *Store) repository.RoleRepository {
+	return &MemoryRoleRepository{
+		store: store,
+	}
+}
+
+func roleKey(clinicID string, role domain.UserRole) string {
+	return clinicID + "/" + string(role)
+}
+
+// FindByRole retrieves the stored definition of a role, or nil if there is none
+func (r *MemoryRoleRepository) FindByRole(ctx context.Context, role domain.UserRole) (*domain.RoleDefinition, error) {
+	defer r.store.rlock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	definition, ok := r.store.roles[roleKey(clinicID, role)]
+	if !ok {
+		return nil, nil
+	}
+	definition.Permissions = slices.Clone(definition.Permissions)
+	return &definition, nil
+}
+
+// List retrieves every stored definition of the clinic
+func (r *MemoryRoleRepository) List(ctx context.Context) ([]*domain.RoleDefinition, error) {
+	defer r.store.rlock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var definitions []*domain.RoleDefinition
+	for _, definition := range r.store.roles {
+		if definition.ClinicID != clinicID {
+			continue
+		}
+		d := definition
+		d.Permissions = slices.Clone(definition.Permissions)
+		definitions = append(definitions, &d)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Role < definitions[j].Role
+	})
+	return definitions, nil
+}
+
+// Save creates or replaces the stored definition of a role
+func (r *MemoryRoleRepository) Save(ctx context.Context, definition *domain.RoleDefinition) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, definition.ClinicID)
+	if err != nil {
+		return err
+	}
+	if _, ok := r.store.clinics[clinicID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+	definition.ClinicID = clinicID
+
+	d := *definition
+	d.Permissions = slices.Clone(definition.Permissions)
+	r.store.roles[roleKey(clinicID, d.Role)] = d
+	return nil
+}
+
+// Delete removes the stored definition of a role, so it goes back to its defaults
+// Returns false if there was none
+func (r *MemoryRoleRepository) Delete(ctx context.Context, role domain.UserRole) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	key := roleKey(clinicID, role)
+	if _, ok := r.store.roles[key]; !ok {
+		return false, nil
+	}
+	delete(r.store.roles, key)
+	return true, nil
+}
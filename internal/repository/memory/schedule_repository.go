@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// dayOrder ranks days of the week the same way the SQL ORDER BY CASE does
+var dayOrder = map[string]int{
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 3,
+	"thursday":  4,
+	"friday":    5,
+	"saturday":  6,
+	"sunday":    7,
+}
+
+// MemoryScheduleRepository implements the ScheduleRepository interface on top of a Store
+type MemoryScheduleRepository struct {
+	store *Store
+}
+
+// NewMemoryScheduleRepository creates a new instance of MemoryScheduleRepository
+func NewMemoryScheduleRepository(store *Store) *MemoryScheduleRepository {
+	return &MemoryScheduleRepository{store: store}
+}
+
+// Create creates a new schedule
+func (r *MemoryScheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.schedules[schedule.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	if _, ok := r.store.doctors[schedule.DoctorID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	if schedule.LocationID == "" {
+		schedule.LocationID = domain.DefaultLocationID
+	}
+	if _, ok := r.store.locations[schedule.LocationID]; !ok {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	if schedule.Version == 0 {
+		schedule.Version = 1
+	}
+
+	r.store.schedules[schedule.ID] = *schedule
+	return nil
+}
+
+// FindByID finds a schedule by ID
+func (r *MemoryScheduleRepository) FindByID(ctx context.Context, id string) (*domain.Schedule, error) {
+	defer r.store.rlock(ctx)()
+
+	schedule, ok := r.store.schedules[id]
+	if !ok || !r.store.locationInClinic(ctx, schedule.LocationID) {
+		return nil, nil
+	}
+
+	return &schedule, nil
+}
+
+// FindByDoctorAndDay finds active schedules for a doctor on a specific day
+func (r *MemoryScheduleRepository) FindByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) ([]*domain.Schedule, error) {
+	return r.findActive(ctx, func(s domain.Schedule) bool {
+		return s.DoctorID == doctorID && s.DayOfWeek == dayOfWeek
+	}), nil
+}
+
+// FindByDoctorsAndDay finds the active schedules of several doctors on a specific day
+func (r *MemoryScheduleRepository) FindByDoctorsAndDay(ctx context.Context, doctorIDs []string, dayOfWeek string) ([]*domain.Schedule, error) {
+	return r.findActive(ctx, func(s domain.Schedule) bool {
+		return slices.Contains(doctorIDs, s.DoctorID) && s.DayOfWeek == dayOfWeek
+	}), nil
+}
+
+// FindByDoctor finds one page of active schedules for a doctor
+func (r *MemoryScheduleRepository) FindByDoctor(ctx context.Context, doctorID string, page repository.PageRequest) ([]*domain.Schedule, error) {
+	schedules := r.findActive(ctx, func(s domain.Schedule) bool {
+		return s.DoctorID == doctorID
+	})
+
+	return repository.SlicePage(schedules, page,
+		func(s *domain.Schedule) string { return repository.ScheduleSortKey(s, page.Sort) },
+		func(s *domain.Schedule) string { return s.ID },
+	), nil
+}
+
+// Update updates a schedule if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *MemoryScheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.schedules[schedule.ID]
+	if !ok || !r.store.locationInClinic(ctx, existing.LocationID) {
+		return errors.New("schedule not found")
+	}
+
+	if existing.Version != schedule.Version {
+		return domain.ErrVersionConflict
+	}
+
+	existing.DoctorID = schedule.DoctorID
+	existing.LocationID = schedule.LocationID
+	existing.DayOfWeek = schedule.DayOfWeek
+	existing.StartTime = schedule.StartTime
+	existing.EndTime = schedule.EndTime
+	existing.SlotDuration = schedule.SlotDuration
+	existing.IsActive = schedule.IsActive
+	existing.UpdatedAt = schedule.UpdatedAt
+	existing.Version++
+	r.store.schedules[schedule.ID] = existing
+	schedule.Version = existing.Version
+
+	return nil
+}
+
+// Delete deletes a schedule
+func (r *MemoryScheduleRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	if schedule, ok := r.store.schedules[id]; ok && r.store.locationInClinic(ctx, schedule.LocationID) {
+		delete(r.store.schedules, id)
+	}
+	return nil
+}
+
+// DeleteByDoctorAndDay deletes all schedules for a doctor on a specific day
+func (r *MemoryScheduleRepository) DeleteByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) error {
+	defer r.store.lock(ctx)()
+
+	for id, schedule := range r.store.schedules {
+		if schedule.DoctorID == doctorID && schedule.DayOfWeek == dayOfWeek && r.store.locationInClinic(ctx, schedule.LocationID) {
+			delete(r.store.schedules, id)
+		}
+	}
+
+	return nil
+}
+
+// ListAll retrieves every schedule, active or not, oldest first
+func (r *MemoryScheduleRepository) ListAll(ctx context.Context) ([]*domain.Schedule, error) {
+	defer r.store.rlock(ctx)()
+
+	schedules := make([]*domain.Schedule, 0, len(r.store.schedules))
+	for _, schedule := range r.store.schedules {
+		if !r.store.locationInClinic(ctx, schedule.LocationID) {
+			continue
+		}
+		s := schedule
+		schedules = append(schedules, &s)
+	}
+	sortByCreatedAtAsc(schedules, func(s *domain.Schedule) (time.Time, string) { return s.CreatedAt, s.ID })
+
+	return schedules, nil
+}
+
+// findActive returns the active schedules matching the predicate,
+// ordered by day of week and then start time
+func (r *MemoryScheduleRepository) findActive(ctx context.Context, match func(domain.Schedule) bool) []*domain.Schedule {
+	defer r.store.rlock(ctx)()
+
+	var schedules []*domain.Schedule
+	for _, schedule := range r.store.schedules {
+		if !schedule.IsActive || !match(schedule) || !r.store.locationInClinic(ctx, schedule.LocationID) {
+			continue
+		}
+		s := schedule
+		schedules = append(schedules, &s)
+	}
+
+	sort.SliceStable(schedules, func(i, j int) bool {
+		if schedules[i].DayOfWeek != schedules[j].DayOfWeek {
+			return dayOrder[schedules[i].DayOfWeek] < dayOrder[schedules[j].DayOfWeek]
+		}
+		return schedules[i].StartTime < schedules[j].StartTime
+	})
+
+	return schedules
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+
+	"version-1-0/internal/repository"
+)
+
+// Clinic scope checks, the in-memory counterpart of the SQL scope conditions
+// Each reports whether a row is visible from ctx: always for an unscoped
+// context, otherwise only when the row belongs to the context's clinic.
+// Callers must hold the lock
+
+// inClinic checks a row that stores its clinic
+func inClinic(ctx context.Context, clinicID string) bool {
+	scope, ok := repository.ClinicScope(ctx)
+	return !ok || clinicID == scope
+}
+
+// userInClinic checks a row reached through its user
+func (s *Store) userInClinic(ctx context.Context, userID string) bool {
+	if _, ok := repository.ClinicScope(ctx); !ok {
+		return true
+	}
+	user, ok := s.users[userID]
+	return ok && inClinic(ctx, user.ClinicID)
+}
+
+// doctorInClinic checks a row reached through its doctor
+func (s *Store) doctorInClinic(ctx context.Context, doctorID string) bool {
+	if _, ok := repository.ClinicScope(ctx); !ok {
+		return true
+	}
+	doctor, ok := s.doctors[doctorID]
+	return ok && s.userInClinic(ctx, doctor.UserID)
+}
+
+// locationInClinic checks a row reached through its location
+func (s *Store) locationInClinic(ctx context.Context, locationID string) bool {
+	if _, ok := repository.ClinicScope(ctx); !ok {
+		return true
+	}
+	location, ok := s.locations[locationID]
+	return ok && inClinic(ctx, location.ClinicID)
+}
+
+// serviceInClinic checks a row reached through its service
+func (s *Store) serviceInClinic(ctx context.Context, serviceID string) bool {
+	if _, ok := repository.ClinicScope(ctx); !ok {
+		return true
+	}
+	service, ok := s.services[serviceID]
+	return ok && inClinic(ctx, service.ClinicID)
+}
+
+// atLocation checks an appointment against the clinic scope and, when
+// locationID is set, against one location
+func (s *Store) atLocation(ctx context.Context, appointmentLocationID, locationID string) bool {
+	if locationID != "" && appointmentLocationID != locationID {
+		return false
+	}
+	return s.locationInClinic(ctx, appointmentLocationID)
+}
@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryServiceRepository implements the ServiceRepository interface on top of a Store
+type MemoryServiceRepository struct {
+	store *Store
+}
+
+// NewMemoryServiceRepository creates a new instance of MemoryServiceRepository
+func NewMemoryServiceRepository(store *Store) repository.ServiceRepository {
+	return &MemoryServiceRepository{
+		store: store,
+	}
+}
+
+// Create inserts a new service into the store
+func (r *MemoryServiceRepository) Create(ctx context.Context, service *domain.Service) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.services[service.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	clinicID, err := repository.ClinicFor(ctx, service.ClinicID)
+	if err != nil {
+		return err
+	}
+	service.ClinicID = clinicID
+
+	if service.Version == 0 {
+		service.Version = 1
+	}
+
+	r.store.services[service.ID] = *service
+	return nil
+}
+
+// FindByID retrieves a service by its unique identifier
+func (r *MemoryServiceRepository) FindByID(ctx context.Context, id string) (*domain.Service, error) {
+	defer r.store.rlock(ctx)()
+
+	service, ok := r.store.services[id]
+	if !ok || service.DeletedAt != nil || !inClinic(ctx, service.ClinicID) {
+		return nil, nil
+	}
+
+	return &service, nil
+}
+
+// ListActive retrieves one page of active services
+func (r *MemoryServiceRepository) ListActive(ctx context.Context, page repository.PageRequest) ([]*domain.Service, error) {
+	return repository.SlicePage(r.list(ctx, true), page,
+		func(s *domain.Service) string { return repository.ServiceSortKey(s, page.Sort) },
+		func(s *domain.Service) string { return s.ID },
+	), nil
+}
+
+// ListAll retrieves all services (active and inactive) ordered by name
+func (r *MemoryServiceRepository) ListAll(ctx context.Context) ([]*domain.Service, error) {
+	return r.list(ctx, false), nil
+}
+
+// Update modifies an existing service if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *MemoryServiceRepository) Update(ctx context.Context, service *domain.Service) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.services[service.ID]
+	if !ok || existing.DeletedAt != nil || !inClinic(ctx, existing.ClinicID) {
+		return errors.New("service not found")
+	}
+
+	if existing.Version != service.Version {
+		return domain.ErrVersionConflict
+	}
+
+	existing.Name = service.Name
+	existing.Description = service.Description
+	existing.DurationMinutes = service.DurationMinutes
+	existing.Price = service.Price
+	existing.IsActive = service.IsActive
+	existing.UpdatedAt = service.UpdatedAt
+	existing.Version++
+	r.store.services[service.ID] = existing
+	service.Version = existing.Version
+
+	return nil
+}
+
+// Delete performs a soft delete by setting DeletedAt
+func (r *MemoryServiceRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	service, ok := r.store.services[id]
+	if !ok || service.DeletedAt != nil || !inClinic(ctx, service.ClinicID) {
+		return errors.New("service not found")
+	}
+
+	now := time.Now()
+	service.DeletedAt = &now
+	service.UpdatedAt = now
+	service.Version++
+	r.store.services[id] = service
+
+	return nil
+}
+
+// ListDeleted retrieves soft deleted services, most recently deleted first
+func (r *MemoryServiceRepository) ListDeleted(ctx context.Context) ([]*domain.Service, error) {
+	defer r.store.rlock(ctx)()
+
+	var services []*domain.Service
+	for _, service := range r.store.services {
+		if service.DeletedAt == nil || !inClinic(ctx, service.ClinicID) {
+			continue
+		}
+		s := service
+		services = append(services, &s)
+	}
+	sortByCreatedAtDesc(services, func(s *domain.Service) time.Time { return *s.DeletedAt })
+
+	return services, nil
+}
+
+// Restore clears the deletion mark of a soft deleted service
+func (r *MemoryServiceRepository) Restore(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	service, ok := r.store.services[id]
+	if !ok || service.DeletedAt == nil || !inClinic(ctx, service.ClinicID) {
+		return errors.New("service not found")
+	}
+
+	service.DeletedAt = nil
+	service.UpdatedAt = time.Now()
+	service.Version++
+	r.store.services[id] = service
+
+	return nil
+}
+
+// PurgeDeleted permanently removes services deleted before the given time,
+// together with their doctor assignments and price overrides
+// Services still referenced by appointments are kept
+func (r *MemoryServiceRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	inUse := make(map[string]bool)
+	for _, appointment := range r.store.appointments {
+		inUse[appointment.ServiceID] = true
+	}
+
+	purged := 0
+	for id, service := range r.store.services {
+		if service.DeletedAt == nil || !service.DeletedAt.Before(before) || inUse[id] || !inClinic(ctx, service.ClinicID) {
+			continue
+		}
+		for dsID, ds := range r.store.doctorServices {
+			if ds.ServiceID == id {
+				delete(r.store.doctorServices, dsID)
+			}
+		}
+		for key, price := range r.store.servicePrices {
+			if price.ServiceID == id {
+				delete(r.store.servicePrices, key)
+			}
+		}
+		delete(r.store.services, id)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// list returns services ordered by name, optionally only the active ones
+func (r *MemoryServiceRepository) list(ctx context.Context, activeOnly bool) []*domain.Service {
+	defer r.store.rlock(ctx)()
+
+	var services []*domain.Service
+	for _, service := range r.store.services {
+		if service.DeletedAt != nil || (activeOnly && !service.IsActive) || !inClinic(ctx, service.ClinicID) {
+			continue
+		}
+		s := service
+		services = append(services, &s)
+	}
+	sortServicesByName(services)
+
+	return services
+}
+
+// sortServicesByName orders services alphabetically by name
+func sortServicesByName(services []*domain.Service) {
+	sort.SliceStable(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+}
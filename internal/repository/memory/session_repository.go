@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemorySessionRepository implements the SessionRepository interface on top of a Store
+type MemorySessionRepository struct {
+	store *Store
+}
+
+// NewMemorySessionRepository creates a new instance of MemorySessionRepository
+func NewMemorySessionRepository(store *Store) repository.SessionRepository {
+	return &MemorySessionRepository{
+		store: store,
+	}
+}
+
+// Create stores a new session
+func (r *MemorySessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	defer r.store.lock(ctx)()
+
+	if _, exists := r.store.sessions[session.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+	if _, exists := r.store.users[session.UserID]; !exists {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	r.store.sessions[session.ID] = *session
+	return nil
+}
+
+// FindByID retrieves a session by its ID, or nil if there is none
+func (r *MemorySessionRepository) FindByID(ctx context.Context, id string) (*domain.Session, error) {
+	defer r.store.rlock(ctx)()
+
+	session, ok := r.store.sessions[id]
+	if !ok || !r.store.userInClinic(ctx, session.UserID) {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// ListActiveByUser retrieves the sessions of a user that have a current refresh token
+// Each active session has exactly one: the others of its chain were used
+func (r *MemorySessionRepository) ListActiveByUser(ctx context.Context, userID string, now time.Time) ([]*domain.Session, error) {
+	defer r.store.rlock(ctx)()
+
+	var sessions []*domain.Session
+	for _, token := range r.store.refreshTokens {
+		if token.UserID != userID || token.UsedAt != nil || token.RevokedAt != nil || !now.Before(token.ExpiresAt) {
+			continue
+		}
+		session, ok := r.store.sessions[token.SessionID]
+		if !ok || session.UserID != userID || !r.store.userInClinic(ctx, session.UserID) {
+			continue
+		}
+		session.ExpiresAt = token.ExpiresAt
+		sessions = append(sessions, &session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastSeenAt.Equal(sessions[j].LastSeenAt) {
+			return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions, nil
+}
+
+// Touch sets last_seen_at of a session; an earlier time leaves it unchanged
+func (r *MemorySessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	session, ok := r.store.sessions[id]
+	if !ok || !seenAt.After(session.LastSeenAt) || !r.store.userInClinic(ctx, session.UserID) {
+		return nil
+	}
+	session.LastSeenAt = seenAt
+	r.store.sessions[id] = session
+	return nil
+}
+
+// PurgeEnded permanently removes sessions last seen before the given time
+// that have no refresh token left
+func (r *MemorySessionRepository) PurgeEnded(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	withTokens := make(map[string]bool)
+	for _, token := range r.store.refreshTokens {
+		withTokens[token.SessionID] = true
+	}
+
+	purged := 0
+	for id, session := range r.store.sessions {
+		if session.LastSeenAt.Before(before) && !withTokens[id] && r.store.userInClinic(ctx, session.UserID) {
+			delete(r.store.sessions, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,287 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"version-1-0/internal/domain"
+)
+
+// Store holds every table of the in-memory backend behind a single lock
+// Repositories share one Store so they can resolve the same joins and
+// cascades the SQL implementations rely on
+type Store struct {
+	mu             sync.RWMutex
+	users          map[string]domain.User
+	patients       map[string]domain.Patient
+	doctors        map[string]domain.Doctor
+	appointments   map[string]domain.Appointment
+	schedules      map[string]domain.Schedule
+	services       map[string]domain.Service
+	doctorServices map[string]domain.DoctorService
+	auditLog       map[string]domain.AuditEntry
+	refreshTokens  map[string]domain.RefreshToken
+	resetTokens    map[string]domain.PasswordResetToken
+	invitations    map[string]domain.Invitation
+	loginLockouts  map[string]domain.LoginLockout
+	twoFactors     map[string]domain.TwoFactor // Keyed by user ID
+	recoveryCodes  map[string]domain.RecoveryCode
+
+	clinics         map[string]domain.Clinic
+	locations       map[string]domain.Location
+	doctorLocations map[string]domain.DoctorLocation // Keyed by doctorLocationKey
+	servicePrices   map[string]domain.ServicePrice   // Keyed by servicePriceKey
+	roles           map[string]domain.RoleDefinition // Keyed by roleKey
+	apiKeys         map[string]domain.APIKey
+	apiKeyUsage     map[string]domain.APIKeyUsage // Keyed by apiKeyUsageKey
+	identities      map[string]domain.ExternalIdentity
+	sessions        map[string]domain.Session
+	usedTokens      map[string]time.Time // Expiration keyed by token ID
+}
+
+// NewStore creates an in-memory store holding only the default clinic and
+// its location, like a database freshly migrated
+func NewStore() *Store {
+	now := time.Now()
+	return &Store{
+		users:          make(map[string]domain.User),
+		patients:       make(map[string]domain.Patient),
+		doctors:        make(map[string]domain.Doctor),
+		appointments:   make(map[string]domain.Appointment),
+		schedules:      make(map[string]domain.Schedule),
+		services:       make(map[string]domain.Service),
+		doctorServices: make(map[string]domain.DoctorService),
+		auditLog:       make(map[string]domain.AuditEntry),
+		refreshTokens:  make(map[string]domain.RefreshToken),
+		resetTokens:    make(map[string]domain.PasswordResetToken),
+		invitations:    make(map[string]domain.Invitation),
+		loginLockouts:  make(map[string]domain.LoginLockout),
+		twoFactors:     make(map[string]domain.TwoFactor),
+		recoveryCodes:  make(map[string]domain.RecoveryCode),
+
+		clinics: map[string]domain.Clinic{
+			domain.DefaultClinicID: {
+				ID:             domain.DefaultClinicID,
+				Name:           "Clínica Internacional",
+				CurrencySymbol: "S/",
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			},
+		},
+		locations: map[string]domain.Location{
+			domain.DefaultLocationID: {
+				ID:        domain.DefaultLocationID,
+				ClinicID:  domain.DefaultClinicID,
+				Name:      "Sede Principal",
+				IsActive:  true,
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   1,
+			},
+		},
+		doctorLocations: make(map[string]domain.DoctorLocation),
+		servicePrices:   make(map[string]domain.ServicePrice),
+		roles:           make(map[string]domain.RoleDefinition),
+		apiKeys:         make(map[string]domain.APIKey),
+		apiKeyUsage:     make(map[string]domain.APIKeyUsage),
+		identities:      make(map[string]domain.ExternalIdentity),
+		sessions:        make(map[string]domain.Session),
+		usedTokens:      make(map[string]time.Time),
+	}
+}
+
+// doctorByUserID finds the doctor profile linked to a user, ignoring soft deleted profiles
+// Callers must hold the lock
+func (s *Store) doctorByUserID(userID string) (domain.Doctor, bool) {
+	for _, doctor := range s.doctors {
+		if doctor.UserID == userID && doctor.DeletedAt == nil {
+			return doctor, true
+		}
+	}
+	return domain.Doctor{}, false
+}
+
+// patientByUserID finds the patient profile linked to a user
+// Callers must hold the lock
+func (s *Store) patientByUserID(userID string) (domain.Patient, bool) {
+	for _, patient := range s.patients {
+		if patient.UserID == userID {
+			return patient, true
+		}
+	}
+	return domain.Patient{}, false
+}
+
+// patientName returns "first last" for the user behind a patient profile
+// Callers must hold the lock
+func (s *Store) patientName(patientID string) (string, bool) {
+	patient, ok := s.patients[patientID]
+	if !ok {
+		return "", false
+	}
+	user, ok := s.users[patient.UserID]
+	if !ok {
+		return "", false
+	}
+	return user.FirstName + " " + user.LastName, true
+}
+
+// doctorName returns "first last" for the user behind a doctor profile
+// Callers must hold the lock
+func (s *Store) doctorName(doctorID string) (string, bool) {
+	doctor, ok := s.doctors[doctorID]
+	if !ok {
+		return "", false
+	}
+	user, ok := s.users[doctor.UserID]
+	if !ok {
+		return "", false
+	}
+	return user.FirstName + " " + user.LastName, true
+}
+
+// deleteDoctorCascade removes a doctor and every row referencing it,
+// matching the ON DELETE CASCADE rules of the SQL schema
+// Callers must hold the write lock
+func (s *Store) deleteDoctorCascade(doctorID string) {
+	for id, schedule := range s.schedules {
+		if schedule.DoctorID == doctorID {
+			delete(s.schedules, id)
+		}
+	}
+	for id, ds := range s.doctorServices {
+		if ds.DoctorID == doctorID {
+			delete(s.doctorServices, id)
+		}
+	}
+	for key, dl := range s.doctorLocations {
+		if dl.DoctorID == doctorID {
+			delete(s.doctorLocations, key)
+		}
+	}
+	for id, appointment := range s.appointments {
+		if appointment.DoctorID == doctorID {
+			delete(s.appointments, id)
+		}
+	}
+	delete(s.doctors, doctorID)
+}
+
+// deleteUserCascade removes a user together with its patient or doctor profile
+// its sessions, refresh and password reset tokens, its second factor and
+// recovery codes and its single sign-on identities. Invitations it sent or
+// accepted and API keys it created are kept without the reference, like ON
+// DELETE SET NULL
+// Callers must hold the write lock
+func (s *Store) deleteUserCascade(userID string) {
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+	for id, token := range s.refreshTokens {
+		if token.UserID == userID {
+			delete(s.refreshTokens, id)
+		}
+	}
+	for id, token := range s.resetTokens {
+		if token.UserID == userID {
+			delete(s.resetTokens, id)
+		}
+	}
+	for id, code := range s.recoveryCodes {
+		if code.UserID == userID {
+			delete(s.recoveryCodes, id)
+		}
+	}
+	delete(s.twoFactors, userID)
+	for id, identity := range s.identities {
+		if identity.UserID == userID {
+			delete(s.identities, id)
+		}
+	}
+	for id, invitation := range s.invitations {
+		if invitation.InvitedBy == userID {
+			invitation.InvitedBy = ""
+		}
+		if invitation.UserID == userID {
+			invitation.UserID = ""
+		}
+		s.invitations[id] = invitation
+	}
+	for id, key := range s.apiKeys {
+		if key.CreatedBy == userID {
+			key.CreatedBy = ""
+			s.apiKeys[id] = key
+		}
+	}
+	for id, patient := range s.patients {
+		if patient.UserID == userID {
+			s.deletePatientCascade(id)
+		}
+	}
+	for id, doctor := range s.doctors {
+		if doctor.UserID == userID {
+			s.deleteDoctorCascade(id)
+		}
+	}
+	delete(s.users, userID)
+}
+
+// userHasAppointments reports whether a user appears in any appointment,
+// either as the patient or as the doctor
+// Callers must hold the lock
+func (s *Store) userHasAppointments(userID string) bool {
+	for _, appointment := range s.appointments {
+		if patient, ok := s.patients[appointment.PatientID]; ok && patient.UserID == userID {
+			return true
+		}
+		if doctor, ok := s.doctors[appointment.DoctorID]; ok && doctor.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// deletePatientCascade removes a patient and its appointments
+// Callers must hold the write lock
+func (s *Store) deletePatientCascade(patientID string) {
+	for id, appointment := range s.appointments {
+		if appointment.PatientID == patientID {
+			delete(s.appointments, id)
+		}
+	}
+	delete(s.patients, patientID)
+}
+
+// paginate applies LIMIT/OFFSET semantics to an already sorted slice
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// sortByCreatedAtAsc orders items oldest first, like ORDER BY created_at ASC, id ASC
+func sortByCreatedAtAsc[T any](items []T, position func(T) (time.Time, string)) {
+	sort.SliceStable(items, func(i, j int) bool {
+		createdI, idI := position(items[i])
+		createdJ, idJ := position(items[j])
+		if !createdI.Equal(createdJ) {
+			return createdI.Before(createdJ)
+		}
+		return idI < idJ
+	})
+}
+
+// sortByCreatedAtDesc orders items newest first, like ORDER BY created_at DESC
+func sortByCreatedAtDesc[T any](items []T, createdAt func(T) time.Time) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return createdAt(items[i]).After(createdAt(items[j]))
+	})
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryTwoFactorRepository implements the TwoFactorRepository interface on top of a Store
+type MemoryTwoFactorRepository struct {
+	store *Store
+}
+
+// NewMemoryTwoFactorRepository creates a new instance of MemoryTwoFactorRepository
+func NewMemoryTwoFactorRepository(store *Store) repository.TwoFactorRepository {
+	return &MemoryTwoFactorRepository{
+		store: store,
+	}
+}
+
+// Create stores a new second factor, not enabled yet
+func (r *MemoryTwoFactorRepository) Create(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[twoFactor.UserID]
+	if !ok || !inClinic(ctx, user.ClinicID) {
+		return domain.ErrRelatedRecordNotFound
+	}
+	if _, exists := r.store.twoFactors[twoFactor.UserID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	t := *twoFactor
+	t.EnabledAt = nil
+	t.LastUsedStep = 0
+	r.store.twoFactors[t.UserID] = t
+	return nil
+}
+
+// FindByUserID retrieves the second factor of a user, or nil if there is none
+func (r *MemoryTwoFactorRepository) FindByUserID(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	defer r.store.rlock(ctx)()
+
+	twoFactor, ok := r.store.twoFactors[userID]
+	if !ok || !r.store.userInClinic(ctx, userID) {
+		return nil, nil
+	}
+	return &twoFactor, nil
+}
+
+// Enable sets enabled_at on a second factor that is not enabled yet
+// Returns false if another request enabled it first
+func (r *MemoryTwoFactorRepository) Enable(ctx context.Context, userID string, step int64, enabledAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	twoFactor, ok := r.store.twoFactors[userID]
+	if !ok || twoFactor.EnabledAt != nil || !r.store.userInClinic(ctx, userID) {
+		return false, nil
+	}
+
+	twoFactor.EnabledAt = &enabledAt
+	twoFactor.LastUsedStep = step
+	r.store.twoFactors[userID] = twoFactor
+	return true, nil
+}
+
+// UseStep records the step of an accepted code if it is newer than the last one
+// Returns false if a code of that step or a later one was already used
+func (r *MemoryTwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	twoFactor, ok := r.store.twoFactors[userID]
+	if !ok || twoFactor.LastUsedStep >= step || !r.store.userInClinic(ctx, userID) {
+		return false, nil
+	}
+
+	twoFactor.LastUsedStep = step
+	r.store.twoFactors[userID] = twoFactor
+	return true, nil
+}
+
+// Delete removes the second factor of a user together with their recovery codes
+func (r *MemoryTwoFactorRepository) Delete(ctx context.Context, userID string) error {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userInClinic(ctx, userID) {
+		return nil
+	}
+
+	r.store.deleteRecoveryCodes(userID)
+	delete(r.store.twoFactors, userID)
+	return nil
+}
+
+// ReplaceRecoveryCodes removes the recovery codes of a user and stores new ones
+func (r *MemoryTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*domain.RecoveryCode) error {
+	defer r.store.lock(ctx)()
+
+	if _, ok := r.store.users[userID]; !ok || !r.store.userInClinic(ctx, userID) {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	r.store.deleteRecoveryCodes(userID)
+	for _, code := range codes {
+		c := *code
+		c.UserID = userID
+		c.UsedAt = nil
+		r.store.recoveryCodes[c.ID] = c
+	}
+	return nil
+}
+
+// UseRecoveryCode sets used_at on an unused recovery code of a user
+// Returns false if there is no such code
+func (r *MemoryTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	if !r.store.userInClinic(ctx, userID) {
+		return false, nil
+	}
+
+	for id, code := range r.store.recoveryCodes {
+		if code.UserID == userID && code.CodeHash == codeHash && code.UsedAt == nil {
+			code.UsedAt = &usedAt
+			r.store.recoveryCodes[id] = code
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CountRecoveryCodes returns how many unused recovery codes a user has left
+func (r *MemoryTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	defer r.store.rlock(ctx)()
+
+	if !r.store.userInClinic(ctx, userID) {
+		return 0, nil
+	}
+
+	count := 0
+	for _, code := range r.store.recoveryCodes {
+		if code.UserID == userID && code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Reencrypt has nothing to do: the store only lives in memory and keeps no ciphertext
+func (r *MemoryTwoFactorRepository) Reencrypt(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// deleteRecoveryCodes removes every recovery code of a user
+// Callers must hold the write lock
+func (s *Store) deleteRecoveryCodes(userID string) {
+	for id, code := range s.recoveryCodes {
+		if code.UserID == userID {
+			delete(s.recoveryCodes, id)
+		}
+	}
+}
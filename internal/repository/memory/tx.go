@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// txKey is the context key marking that the store lock is held by a unit of work
+type txKey struct{}
+
+// MemoryTxManager implements the TxManager interface on top of a Store
+// A unit of work holds the store write lock from start to end, so units never
+// interleave, and a failed unit restores the snapshot taken when it began
+type MemoryTxManager struct {
+	store *Store
+}
+
+// NewMemoryTxManager creates a new instance of MemoryTxManager
+func NewMemoryTxManager(store *Store) repository.TxManager {
+	return &MemoryTxManager{
+		store: store,
+	}
+}
+
+// WithinTx runs fn while holding the store lock, rolling back its writes if it fails
+func (m *MemoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.store.inTx(ctx) {
+		return fn(ctx)
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	snapshot := m.store.snapshot()
+	if err := fn(context.WithValue(ctx, txKey{}, m.store)); err != nil {
+		m.store.restore(snapshot)
+		return err
+	}
+
+	return nil
+}
+
+// inTx reports whether ctx belongs to a unit of work that already holds the lock
+func (s *Store) inTx(ctx context.Context) bool {
+	held, _ := ctx.Value(txKey{}).(*Store)
+	return held == s
+}
+
+// lock takes the write lock unless ctx already holds it and returns the matching unlock
+func (s *Store) lock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// rlock takes the read lock unless ctx already holds the write lock and returns the matching unlock
+func (s *Store) rlock(ctx context.Context) func() {
+	if s.inTx(ctx) {
+		return func() {}
+	}
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+// tables is a point-in-time copy of every table, used to roll back a unit of work
+type tables struct {
+	users          map[string]domain.User
+	patients       map[string]domain.Patient
+	doctors        map[string]domain.Doctor
+	appointments   map[string]domain.Appointment
+	schedules      map[string]domain.Schedule
+	services       map[string]domain.Service
+	doctorServices map[string]domain.DoctorService
+	auditLog       map[string]domain.AuditEntry
+	refreshTokens  map[string]domain.RefreshToken
+	resetTokens    map[string]domain.PasswordResetToken
+	invitations    map[string]domain.Invitation
+	loginLockouts  map[string]domain.LoginLockout
+	twoFactors     map[string]domain.TwoFactor
+	recoveryCodes  map[string]domain.RecoveryCode
+
+	clinics         map[string]domain.Clinic
+	locations       map[string]domain.Location
+	doctorLocations map[string]domain.DoctorLocation
+	servicePrices   map[string]domain.ServicePrice
+	roles           map[string]domain.RoleDefinition
+	apiKeys         map[string]domain.APIKey
+	apiKeyUsage     map[string]domain.APIKeyUsage
+	identities      map[string]domain.ExternalIdentity
+	sessions        map[string]domain.Session
+	usedTokens      map[string]time.Time
+}
+
+// snapshot copies every table
+// Callers must hold the write lock
+func (s *Store) snapshot() tables {
+	return tables{
+		users:          maps.Clone(s.users),
+		patients:       maps.Clone(s.patients),
+		doctors:        maps.Clone(s.doctors),
+		appointments:   maps.Clone(s.appointments),
+		schedules:      maps.Clone(s.schedules),
+		services:       maps.Clone(s.services),
+		doctorServices: maps.Clone(s.doctorServices),
+		auditLog:       maps.Clone(s.auditLog),
+		refreshTokens:  maps.Clone(s.refreshTokens),
+		resetTokens:    maps.Clone(s.resetTokens),
+		invitations:    maps.Clone(s.invitations),
+		loginLockouts:  maps.Clone(s.loginLockouts),
+		twoFactors:     maps.Clone(s.twoFactors),
+		recoveryCodes:  maps.Clone(s.recoveryCodes),
+
+		clinics:         maps.Clone(s.clinics),
+		locations:       maps.Clone(s.locations),
+		doctorLocations: maps.Clone(s.doctorLocations),
+		servicePrices:   maps.Clone(s.servicePrices),
+		roles:           maps.Clone(s.roles),
+		apiKeys:         maps.Clone(s.apiKeys),
+		apiKeyUsage:     maps.Clone(s.apiKeyUsage),
+		identities:      maps.Clone(s.identities),
+		sessions:        maps.Clone(s.sessions),
+		usedTokens:      maps.Clone(s.usedTokens),
+	}
+}
+
+// restore replaces every table with a previous snapshot
+// Callers must hold the write lock
+func (s *Store) restore(t tables) {
+	s.users = t.users
+	s.patients = t.patients
+	s.doctors = t.doctors
+	s.appointments = t.appointments
+	s.schedules = t.schedules
+	s.services = t.services
+	s.doctorServices = t.doctorServices
+	s.auditLog = t.auditLog
+	s.refreshTokens = t.refreshTokens
+	s.resetTokens = t.resetTokens
+	s.invitations = t.invitations
+	s.loginLockouts = t.loginLockouts
+	s.twoFactors = t.twoFactors
+	s.recoveryCodes = t.recoveryCodes
+	s.clinics = t.clinics
+	s.locations = t.locations
+	s.doctorLocations = t.doctorLocations
+	s.servicePrices = t.servicePrices
+	s.roles = t.roles
+	s.apiKeys = t.apiKeys
+	s.apiKeyUsage = t.apiKeyUsage
+	s.identities = t.identities
+	s.sessions = t.sessions
+	s.usedTokens = t.usedTokens
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// MemoryUsedTokenRepository implements the UsedTokenRepository interface on top of a Store
+type MemoryUsedTokenRepository struct {
+	store *Store
+}
+
+// NewMemoryUsedTokenRepository creates a new instance of MemoryUsedTokenRepository
+func NewMemoryUsedTokenRepository(store *Store) repository.UsedTokenRepository {
+	return &MemoryUsedTokenRepository{
+		store: store,
+	}
+}
+
+// Use records that the token with the given ID was used
+// Returns false if it was used before
+func (r *MemoryUsedTokenRepository) Use(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	defer r.store.lock(ctx)()
+
+	if _, used := r.store.usedTokens[id]; used {
+		return false, nil
+	}
+	r.store.usedTokens[id] = expiresAt
+	return true, nil
+}
+
+// PurgeExpired permanently removes the IDs of tokens that expired before the given time
+func (r *MemoryUsedTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	purged := 0
+	for id, expiresAt := range r.store.usedTokens {
+		if expiresAt.Before(before) {
+			delete(r.store.usedTokens, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,288 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// MemoryUserRepository implements the UserRepository interface on top of a Store
+type MemoryUserRepository struct {
+	store *Store
+}
+
+// NewMemoryUserRepository creates a new instance of MemoryUserRepository
+func NewMemoryUserRepository(store *Store) repository.UserRepository {
+	return &MemoryUserRepository{
+		store: store,
+	}
+}
+
+// Create inserts a new user into the store
+func (r *MemoryUserRepository) Create(ctx context.Context, user *domain.User) error {
+	defer r.store.lock(ctx)()
+
+	clinicID, err := repository.ClinicFor(ctx, user.ClinicID)
+	if err != nil {
+		return err
+	}
+	user.ClinicID = clinicID
+
+	if user.Version == 0 {
+		user.Version = 1
+	}
+
+	return r.store.insertUser(user)
+}
+
+// insertUser enforces the primary key and unique email constraints and stores a copy of the user
+// Callers must hold the write lock
+func (s *Store) insertUser(user *domain.User) error {
+	if _, exists := s.users[user.ID]; exists {
+		return domain.ErrDuplicateRecord
+	}
+
+	for _, existing := range s.users {
+		if existing.Email == user.Email {
+			return domain.ErrEmailAlreadyExists
+		}
+	}
+
+	s.users[user.ID] = *user
+	return nil
+}
+
+// FindByID retrieves a user by their unique identifier
+// Returns nil if the user is not found
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil || !inClinic(ctx, user.ClinicID) {
+		return nil, nil
+	}
+
+	return &user, nil
+}
+
+// FindByEmail retrieves a user by their email address
+// Returns nil if the user is not found
+func (r *MemoryUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, user := range r.store.users {
+		if user.Email == email && user.DeletedAt == nil && inClinic(ctx, user.ClinicID) {
+			return &user, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// EmailExists reports whether any user holds the email, ignoring the clinic
+// scope and deleted_at like the unique constraint of the SQL schemas
+func (r *MemoryUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	defer r.store.rlock(ctx)()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Update modifies an existing user if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *MemoryUserRepository) Update(ctx context.Context, user *domain.User) error {
+	defer r.store.lock(ctx)()
+
+	existing, ok := r.store.users[user.ID]
+	if !ok || existing.DeletedAt != nil || !inClinic(ctx, existing.ClinicID) {
+		return errors.New("user not found")
+	}
+
+	if existing.Version != user.Version {
+		return domain.ErrVersionConflict
+	}
+
+	for id, other := range r.store.users {
+		if id != user.ID && other.Email == user.Email {
+			return domain.ErrEmailAlreadyExists
+		}
+	}
+
+	existing.Email = user.Email
+	existing.PasswordHash = user.PasswordHash
+	existing.FirstName = user.FirstName
+	existing.LastName = user.LastName
+	existing.Phone = user.Phone
+	existing.Role = user.Role
+	existing.IsActive = user.IsActive
+	existing.EmailVerifiedAt = user.EmailVerifiedAt
+	existing.UpdatedAt = user.UpdatedAt
+	existing.Version++
+	r.store.users[user.ID] = existing
+	user.Version = existing.Version
+
+	return nil
+}
+
+// MarkVerificationSent records when a verification email was last sent
+func (r *MemoryUserRepository) MarkVerificationSent(ctx context.Context, id string, sentAt time.Time) error {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil || !inClinic(ctx, user.ClinicID) {
+		return nil
+	}
+
+	user.VerificationSentAt = &sentAt
+	r.store.users[id] = user
+	return nil
+}
+
+// Delete performs a soft delete by setting DeletedAt
+func (r *MemoryUserRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt != nil || !inClinic(ctx, user.ClinicID) {
+		return errors.New("user not found")
+	}
+
+	now := time.Now()
+	user.DeletedAt = &now
+	user.UpdatedAt = now
+	user.Version++
+	r.store.users[id] = user
+
+	return nil
+}
+
+// ListDeleted retrieves soft deleted users, most recently deleted first
+func (r *MemoryUserRepository) ListDeleted(ctx context.Context) ([]*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	var users []*domain.User
+	for _, user := range r.store.users {
+		if user.DeletedAt == nil || !inClinic(ctx, user.ClinicID) {
+			continue
+		}
+		u := user
+		users = append(users, &u)
+	}
+	sortByCreatedAtDesc(users, func(u *domain.User) time.Time { return *u.DeletedAt })
+
+	return users, nil
+}
+
+// Restore clears the deletion mark of a soft deleted user
+func (r *MemoryUserRepository) Restore(ctx context.Context, id string) error {
+	defer r.store.lock(ctx)()
+
+	user, ok := r.store.users[id]
+	if !ok || user.DeletedAt == nil || !inClinic(ctx, user.ClinicID) {
+		return errors.New("user not found")
+	}
+
+	user.DeletedAt = nil
+	user.UpdatedAt = time.Now()
+	user.Version++
+	r.store.users[id] = user
+
+	return nil
+}
+
+// PurgeDeleted permanently removes users deleted before the given time
+// Their patient or doctor profile goes with them; users that still appear in
+// appointments are kept so clinical history is never lost
+func (r *MemoryUserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	defer r.store.lock(ctx)()
+
+	purged := 0
+	for id, user := range r.store.users {
+		if user.DeletedAt == nil || !user.DeletedAt.Before(before) || !inClinic(ctx, user.ClinicID) || r.store.userHasAppointments(id) {
+			continue
+		}
+		r.store.deleteUserCascade(id)
+		purged++
+	}
+
+	return purged, nil
+}
+
+// List retrieves one page of users
+func (r *MemoryUserRepository) List(ctx context.Context, page repository.PageRequest) ([]*domain.User, error) {
+	defer r.store.rlock(ctx)()
+
+	users := make([]*domain.User, 0, len(r.store.users))
+	for _, user := range r.store.users {
+		if user.DeletedAt != nil || !inClinic(ctx, user.ClinicID) {
+			continue
+		}
+		u := user
+		users = append(users, &u)
+	}
+
+	return repository.SlicePage(users, page,
+		func(u *domain.User) string { return repository.UserSortKey(u, page.Sort) },
+		func(u *domain.User) string { return u.ID },
+	), nil
+}
+
+// FindDoctorIDByUserID returns the doctor.id for a given user_id
+func (r *MemoryUserRepository) FindDoctorIDByUserID(ctx context.Context, userID string) (string, error) {
+	defer r.store.rlock(ctx)()
+
+	doctor, ok := r.store.doctorByUserID(userID)
+	if !ok || !r.store.userInClinic(ctx, userID) {
+		return "", errors.New("doctor not found")
+	}
+
+	return doctor.ID, nil
+}
+
+// FindPatientIDByUserID returns the patient.id for a given user_id
+func (r *MemoryUserRepository) FindPatientIDByUserID(ctx context.Context, userID string) (string, error) {
+	defer r.store.rlock(ctx)()
+
+	patient, ok := r.store.patientByUserID(userID)
+	if !ok || !r.store.userInClinic(ctx, userID) {
+		return "", errors.New("patient not found")
+	}
+
+	return patient.ID, nil
+}
+
+// CountByRole counts active users by role
+func (r *MemoryUserRepository) CountByRole(ctx context.Context, role string) (int, error) {
+	defer r.store.rlock(ctx)()
+
+	count := 0
+	for _, user := range r.store.users {
+		if string(user.Role) == role && user.IsActive && user.DeletedAt == nil && inClinic(ctx, user.ClinicID) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// CountAllActive counts all active users
+func (r *MemoryUserRepository) CountAllActive(ctx context.Context) (int, error) {
+	defer r.store.rlock(ctx)()
+
+	count := 0
+	for _, user := range r.store.users {
+		if user.IsActive && user.DeletedAt == nil && inClinic(ctx, user.ClinicID) {
+			count++
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// otherClinicID is a second tenant next to the default clinic
+const otherClinicID = "00000000-0000-0000-0000-000000000002"
+
+func newUser(id, email string, role domain.UserRole) *domain.User {
+	now := time.Now()
+	return &domain.User{
+		ID:           id,
+		Email:        email,
+		PasswordHash: "hash",
+		FirstName:    "Ana",
+		LastName:     "Torres",
+		Role:         role,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func TestUserCreateEnforcesUniqueKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository(NewStore())
+
+	if err := repo.Create(ctx, newUser("user-1", "ana@clinica.test", domain.RolePatient)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		user *domain.User
+		want error
+	}{
+		{"duplicate ID", ctx, newUser("user-1", "otra@clinica.test", domain.RolePatient), domain.ErrDuplicateRecord},
+		{"duplicate email", ctx, newUser("user-2", "ana@clinica.test", domain.RolePatient), domain.ErrEmailAlreadyExists},
+		// users.email is unique across clinics in the SQL schemas
+		{"duplicate email in another clinic", repository.WithClinic(ctx, otherClinicID), newUser("user-3", "ana@clinica.test", domain.RolePatient), domain.ErrEmailAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := repo.Create(tt.ctx, tt.user); !errors.Is(err, tt.want) {
+				t.Errorf("Create error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserEmailExistsIgnoresScopeAndDeletion(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository(NewStore())
+
+	if err := repo.Create(ctx, newUser("user-1", "ana@clinica.test", domain.RolePatient)); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Delete(ctx, "user-1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	other := repository.WithClinic(ctx, otherClinicID)
+	if exists, err := repo.EmailExists(other, "ana@clinica.test"); err != nil || !exists {
+		t.Errorf("EmailExists(deleted, other clinic) = %v, %v; want true", exists, err)
+	}
+	if exists, err := repo.EmailExists(ctx, "luis@clinica.test"); err != nil || exists {
+		t.Errorf("EmailExists(unknown) = %v, %v; want false", exists, err)
+	}
+}
+
+func TestUserUpdateEnforcesUniqueEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryUserRepository(NewStore())
+
+	for _, user := range []*domain.User{
+		newUser("user-1", "ana@clinica.test", domain.RolePatient),
+		newUser("user-2", "luis@clinica.test", domain.RolePatient),
+	} {
+		if err := repo.Create(ctx, user); err != nil {
+			t.Fatalf("create %s: %v", user.ID, err)
+		}
+	}
+
+	user, err := repo.FindByID(ctx, "user-2")
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	user.Email = "ana@clinica.test"
+	if err := repo.Update(ctx, user); !errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Errorf("Update error = %v, want %v", err, domain.ErrEmailAlreadyExists)
+	}
+}
+
+func TestFindProfileIDsByUserID(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	users := NewMemoryUserRepository(store)
+
+	for _, user := range []*domain.User{
+		newUser("doctor-user", "doctor@clinica.test", domain.RoleDoctor),
+		newUser("patient-user", "patient@clinica.test", domain.RolePatient),
+	} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("create %s: %v", user.ID, err)
+		}
+	}
+	if err := NewMemoryDoctorRepository(store).Create(ctx, &domain.Doctor{ID: "doctor-1", UserID: "doctor-user", LicenseNumber: "LIC-1"}); err != nil {
+		t.Fatalf("create doctor: %v", err)
+	}
+	if err := NewMemoryPatientRepository(store).Create(ctx, &domain.Patient{ID: "patient-1", UserID: "patient-user"}); err != nil {
+		t.Fatalf("create patient: %v", err)
+	}
+
+	if id, err := users.FindDoctorIDByUserID(ctx, "doctor-user"); err != nil || id != "doctor-1" {
+		t.Errorf("FindDoctorIDByUserID = %q, %v; want doctor-1", id, err)
+	}
+	if id, err := users.FindPatientIDByUserID(ctx, "patient-user"); err != nil || id != "patient-1" {
+		t.Errorf("FindPatientIDByUserID = %q, %v; want patient-1", id, err)
+	}
+
+	// Profile IDs are not user IDs, and users of other clinics are not visible
+	other := repository.WithClinic(ctx, otherClinicID)
+	misses := []struct {
+		name string
+		find func() (string, error)
+		want string
+	}{
+		{"doctor by profile ID", func() (string, error) { return users.FindDoctorIDByUserID(ctx, "doctor-1") }, "doctor not found"},
+		{"doctor of a patient", func() (string, error) { return users.FindDoctorIDByUserID(ctx, "patient-user") }, "doctor not found"},
+		{"doctor in another clinic", func() (string, error) { return users.FindDoctorIDByUserID(other, "doctor-user") }, "doctor not found"},
+		{"patient by profile ID", func() (string, error) { return users.FindPatientIDByUserID(ctx, "patient-1") }, "patient not found"},
+		{"patient of a doctor", func() (string, error) { return users.FindPatientIDByUserID(ctx, "doctor-user") }, "patient not found"},
+		{"patient in another clinic", func() (string, error) { return users.FindPatientIDByUserID(other, "patient-user") }, "patient not found"},
+	}
+	for _, tt := range misses {
+		t.Run(tt.name, func(t *testing.T) {
+			if id, err := tt.find(); err == nil || err.Error() != tt.want {
+				t.Errorf("got %q, %v; want error %q", id, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Dialects lists every supported dialect; new migrations get a file pair for each
+var Dialects = []Dialect{SQLite, Postgres}
+
+// nonNameChars matches everything that is not allowed in a migration name
+var nonNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Create writes an empty up/down file pair for every dialect under dir, numbered
+// one past the highest existing version, and returns the paths it created
+func Create(dir, name string) ([]string, error) {
+	name = strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, fmt.Errorf("migration name must contain letters or digits")
+	}
+
+	fsys := os.DirFS(dir)
+	next := 1
+	for _, d := range Dialects {
+		list, err := Load(fsys, d)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) > 0 && list[len(list)-1].Version >= next {
+			next = list[len(list)-1].Version + 1
+		}
+	}
+
+	var created []string
+	for _, d := range Dialects {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, d.Name(), fmt.Sprintf("%04d_%s.%s.sql", next, name, direction))
+			content := fmt.Sprintf("-- %04d %s (%s, %s)\n", next, name, d.Name(), direction)
+
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err != nil {
+				return created, err
+			}
+			_, err = file.WriteString(content)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return created, err
+			}
+
+			created = append(created, path)
+		}
+	}
+
+	return created, nil
+}
@@ -0,0 +1,129 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Dialect abstracts the SQL differences between the supported database engines
+// so the same migration set can run against SQLite and PostgreSQL
+type Dialect interface {
+	// Name returns the dialect identifier ("sqlite" or "postgres")
+	Name() string
+
+	// Placeholder returns the bind parameter for the n-th argument (1-based)
+	Placeholder(n int) string
+
+	// ColumnExists reports whether a column is already present on a table
+	ColumnExists(db *sql.DB, table, column string) (bool, error)
+
+	// SuspendForeignKeys stops enforcing foreign keys on conn while a migration
+	// runs and returns the function that enforces them again
+	SuspendForeignKeys(ctx context.Context, conn *sql.Conn) (func() error, error)
+
+	// CheckForeignKeys fails if the migration left rows whose parent is missing
+	CheckForeignKeys(tx *sql.Tx) error
+}
+
+// SQLite is the dialect for the embedded SQLite backend
+var SQLite Dialect = sqliteDialect{}
+
+// Postgres is the dialect for the PostgreSQL backend
+var Postgres Dialect = postgresDialect{}
+
+// sqliteDialect implements Dialect for SQLite
+type sqliteDialect struct{}
+
+// Name returns the dialect identifier
+func (sqliteDialect) Name() string {
+	return "sqlite"
+}
+
+// Placeholder returns a positional SQLite parameter
+func (sqliteDialect) Placeholder(n int) string {
+	return "?"
+}
+
+// ColumnExists inspects the table using the pragma_table_info table-valued function
+func (sqliteDialect) ColumnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SuspendForeignKeys switches the foreign_keys pragma off, which SQLite only
+// allows outside a transaction. Changing a constraint means rebuilding the
+// table, and dropping a table others reference would otherwise delete their rows
+func (sqliteDialect) SuspendForeignKeys(ctx context.Context, conn *sql.Conn) (func() error, error) {
+	var enabled bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return func() error { return nil }, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+		return err
+	}, nil
+}
+
+// CheckForeignKeys runs foreign_key_check, since nothing was enforced meanwhile
+func (sqliteDialect) CheckForeignKeys(tx *sql.Tx) error {
+	var table, parent string
+	var rowID sql.NullInt64
+	var index int
+	err := tx.QueryRow("PRAGMA foreign_key_check").Scan(&table, &rowID, &parent, &index)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("foreign key violation: a row of %s references a missing row of %s", table, parent)
+}
+
+// postgresDialect implements Dialect for PostgreSQL
+type postgresDialect struct{}
+
+// Name returns the dialect identifier
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+// Placeholder returns a numbered PostgreSQL parameter
+func (postgresDialect) Placeholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// ColumnExists inspects the table using information_schema
+func (postgresDialect) ColumnExists(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM information_schema.columns
+		WHERE table_name = $1 AND column_name = $2
+	`, table, column).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SuspendForeignKeys does nothing: PostgreSQL alters constraints in place
+func (postgresDialect) SuspendForeignKeys(ctx context.Context, conn *sql.Conn) (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+// CheckForeignKeys does nothing: PostgreSQL enforced them all along
+func (postgresDialect) CheckForeignKeys(tx *sql.Tx) error {
+	return nil
+}
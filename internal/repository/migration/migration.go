@@ -0,0 +1,492 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"version-1-0/migrations"
+)
+
+// Migration represents a versioned pair of SQL scripts for one dialect
+type Migration struct {
+	Version  int
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string // SHA-256 of UpSQL, recorded in schema_migrations when applied
+}
+
+// Status describes a migration as seen by a particular database
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // the embedded file no longer matches the recorded checksum
+	Missing   bool // applied in the database but no file ships with this build
+}
+
+// Migrator applies and reverts the embedded migrations of one dialect
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	version   int
+	name      string
+	checksum  string
+	appliedAt sql.NullTime
+}
+
+// fileNamePattern matches NNNN_name.up.sql and NNNN_name.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// NewMigrator loads the embedded migrations for the dialect and makes sure
+// the schema_migrations table exists
+func NewMigrator(db *sql.DB, d Dialect) (*Migrator, error) {
+	list, err := Load(migrations.FS, d)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Migrator{db: db, dialect: d, migrations: list}
+	if err := m.prepareMigrationsTable(); err != nil {
+		return nil, fmt.Errorf("failed to prepare migrations table: %v", err)
+	}
+
+	return m, nil
+}
+
+// Run executes all pending migrations against the database using the given dialect
+func Run(db *sql.DB, d Dialect) error {
+	m, err := NewMigrator(db, d)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Up()
+	return err
+}
+
+// CheckCurrent returns an error if the database has pending or modified migrations
+// It never changes the schema, so it is what the API runs at boot unless
+// automatic migrations are enabled
+func CheckCurrent(db *sql.DB, d Dialect) error {
+	m, err := NewMigrator(db, d)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+	for _, s := range statuses {
+		if s.Modified {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied", s.Version, s.Name)
+		}
+		if !s.Applied {
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("database schema is out of date: %d pending migration(s), run `go run ./cmd/migrate up`", pending)
+	}
+
+	return nil
+}
+
+// Load reads the migrations of a dialect from fsys, where each dialect lives in
+// a directory named after it, and returns them ordered by version
+func Load(fsys fs.FS, d Dialect) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, d.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s migrations: %v", d.Name(), err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q (expected NNNN_name.up.sql or NNNN_name.down.sql)", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, d.Name()+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %04d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.UpSQL = string(content)
+			m.Checksum = checksum(content)
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" || m.DownSQL == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both an up and a down file", m.Version, m.Name)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+
+	return list, nil
+}
+
+// Up applies every pending migration in order and returns how many were applied
+// Each migration runs in its own transaction together with its schema_migrations row
+func (m *Migrator) Up() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	log.Printf("Current database version: %d (%s)", currentVersion(applied), m.dialect.Name())
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		if err := m.apply(migration); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the last n applied migrations, newest first
+func (m *Migrator) Down(n int) error {
+	if n < 1 {
+		return fmt.Errorf("number of migrations to revert must be at least 1")
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	if n > len(versions) {
+		return fmt.Errorf("cannot revert %d migration(s): only %d applied", n, len(versions))
+	}
+
+	for _, version := range versions[:n] {
+		migration, ok := m.find(version)
+		if !ok {
+			return fmt.Errorf("migration %04d is applied but its files are missing from this build", version)
+		}
+
+		if err := m.revert(migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Redo reverts the last applied migration and applies it again
+func (m *Migrator) Redo() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+
+	version := currentVersion(applied)
+	if version == 0 {
+		return fmt.Errorf("no migration has been applied yet")
+	}
+
+	migration, ok := m.find(version)
+	if !ok {
+		return fmt.Errorf("migration %04d is applied but its files are missing from this build", version)
+	}
+
+	if err := m.revert(migration); err != nil {
+		return err
+	}
+
+	return m.apply(migration)
+}
+
+// Status lists every known migration, embedded or recorded, ordered by version
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.Modified = row.checksum != migration.Checksum
+			if row.appliedAt.Valid {
+				appliedAt := row.appliedAt.Time
+				status.AppliedAt = &appliedAt
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	for version, row := range applied {
+		if _, ok := m.find(version); ok {
+			continue
+		}
+		status := Status{Version: version, Name: row.name, Applied: true, Missing: true}
+		if row.appliedAt.Valid {
+			appliedAt := row.appliedAt.Time
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// apply runs the up script and records the version in a single transaction
+func (m *Migrator) apply(migration Migration) error {
+	log.Printf("Running migration %04d: %s", migration.Version, migration.Name)
+
+	err := m.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(migration.UpSQL); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(
+			fmt.Sprintf("INSERT INTO schema_migrations (version, name, checksum) VALUES (%s, %s, %s)",
+				m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3)),
+			migration.Version, migration.Name, migration.Checksum,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %v", migration.Version, migration.Name, err)
+	}
+
+	log.Printf("✓ Migration %04d completed successfully", migration.Version)
+	return nil
+}
+
+// revert runs the down script and removes the version in a single transaction
+func (m *Migrator) revert(migration Migration) error {
+	log.Printf("Reverting migration %04d: %s", migration.Version, migration.Name)
+
+	err := m.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(migration.DownSQL); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec("DELETE FROM schema_migrations WHERE version = "+m.dialect.Placeholder(1), migration.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("reverting migration %04d_%s failed: %v", migration.Version, migration.Name, err)
+	}
+
+	log.Printf("✓ Migration %04d reverted successfully", migration.Version)
+	return nil
+}
+
+// inTx runs fn inside a transaction, rolling back on error
+// The dialect may suspend foreign keys for it, in which case they are checked
+// before committing
+func (m *Migrator) inTx(fn func(tx *sql.Tx) error) (err error) {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	restore, err := m.dialect.SuspendForeignKeys(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if restoreErr := restore(); err == nil {
+			err = restoreErr
+		}
+	}()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := m.dialect.CheckForeignKeys(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// verify refuses to continue when an applied migration was edited afterwards,
+// since the database would silently differ from what the files describe
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	for _, migration := range m.migrations {
+		row, ok := applied[migration.Version]
+		if ok && row.checksum != migration.Checksum {
+			return fmt.Errorf("migration %04d_%s was modified after it was applied (checksum %s, recorded %s)",
+				migration.Version, migration.Name, shortChecksum(migration.Checksum), shortChecksum(row.checksum))
+		}
+	}
+	return nil
+}
+
+// find returns the embedded migration with the given version
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// applied returns the rows of schema_migrations keyed by version
+func (m *Migrator) applied() (map[int]appliedMigration, error) {
+	rows, err := m.db.Query("SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[row.version] = row
+	}
+
+	return applied, rows.Err()
+}
+
+// prepareMigrationsTable creates schema_migrations, upgrades tables written by the
+// previous Go-function runner (version and applied_at only) and stamps their rows
+// with the name and checksum of the matching file
+func (m *Migrator) prepareMigrationsTable() error {
+	if _, err := m.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL DEFAULT '',
+			checksum TEXT NOT NULL DEFAULT '',
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return err
+	}
+
+	if _, err := addColumnIfMissing(m.db, m.dialect, "schema_migrations", "name", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if _, err := addColumnIfMissing(m.db, m.dialect, "schema_migrations", "checksum", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE schema_migrations SET name = %s, checksum = %s WHERE version = %s AND checksum = ''",
+		m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3))
+	for _, migration := range m.migrations {
+		if _, err := m.db.Exec(query, migration.Name, migration.Checksum, migration.Version); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// currentVersion returns the highest applied version, or 0 on an empty database
+func currentVersion(applied map[int]appliedMigration) int {
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+// checksum returns the hex SHA-256 of a migration script
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// shortChecksum abbreviates a checksum for error messages
+func shortChecksum(sum string) string {
+	if len(sum) > 12 {
+		return sum[:12]
+	}
+	if sum == "" {
+		return "none"
+	}
+	return sum
+}
+
+// addColumnIfMissing adds a column to a table unless it already exists
+func addColumnIfMissing(db *sql.DB, d Dialect, table, column, definition string) (bool, error) {
+	exists, err := d.ColumnExists(db, table, column)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
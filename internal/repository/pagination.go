@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"version-1-0/internal/domain"
+)
+
+// Sort keys accepted by the paginated lists
+// Every list orders by its sort key and then by ID, so rows sharing a key
+// still come back in a stable order and no row is skipped between pages
+const (
+	SortScheduledAt = "scheduled_at"
+	SortCreatedAt   = "created_at"
+	SortName        = "name"
+	SortDay         = "day"
+)
+
+// timeKeyLayout formats time sort keys with a fixed width so they compare as strings
+const timeKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// PageRequest selects one page of a keyset paginated list
+type PageRequest struct {
+	Sort  string
+	Desc  bool
+	Limit int     // Maximum number of rows, 0 means no limit
+	After *Cursor // Last row of the previous page, nil for the first page
+}
+
+// Cursor is the position of a row in a sorted list
+type Cursor struct {
+	Key string // Sort key of the row, as returned by the SortKey helpers
+	ID  string
+}
+
+// IsTimeSort reports whether a sort key holds a timestamp
+func IsTimeSort(sort string) bool {
+	return sort == SortScheduledAt || sort == SortCreatedAt
+}
+
+// TimeKey formats a timestamp as a sort key
+func TimeKey(t time.Time) string {
+	return t.UTC().Format(timeKeyLayout)
+}
+
+// ParseTimeKey reads back a sort key written by TimeKey
+func ParseTimeKey(key string) (time.Time, error) {
+	return time.Parse(timeKeyLayout, key)
+}
+
+// AppointmentSortKey returns the value an appointment is ordered by
+func AppointmentSortKey(appointment *domain.Appointment, sort string) string {
+	if sort == SortCreatedAt {
+		return TimeKey(appointment.CreatedAt)
+	}
+	return TimeKey(appointment.ScheduledAt)
+}
+
+// UserSortKey returns the value a user (or doctor) is ordered by
+// Names sort by "first last", which is also how the SQL backends build the key
+func UserSortKey(user *domain.User, sort string) string {
+	if sort == SortName {
+		return user.FirstName + " " + user.LastName
+	}
+	return TimeKey(user.CreatedAt)
+}
+
+// ServiceSortKey returns the value a service is ordered by
+func ServiceSortKey(service *domain.Service, sort string) string {
+	if sort == SortCreatedAt {
+		return TimeKey(service.CreatedAt)
+	}
+	return service.Name
+}
+
+// dayOrder ranks days of the week starting on monday
+var dayOrder = map[string]int{
+	"monday":    1,
+	"tuesday":   2,
+	"wednesday": 3,
+	"thursday":  4,
+	"friday":    5,
+	"saturday":  6,
+	"sunday":    7,
+}
+
+// ScheduleSortKey returns the value a schedule is ordered by
+// The day key is the day rank followed by the start time, e.g. "1 09:00"
+func ScheduleSortKey(schedule *domain.Schedule, sort string) string {
+	if sort == SortCreatedAt {
+		return TimeKey(schedule.CreatedAt)
+	}
+	return fmt.Sprintf("%d %s", dayOrder[schedule.DayOfWeek], schedule.StartTime)
+}
+
+// SlicePage sorts items by their sort key and ID and returns the page that
+// follows page.After, like the keyset queries of the SQL backends
+// Used where the rows are already in memory; it reorders items in place
+func SlicePage[T any](items []T, page PageRequest, key func(T) string, id func(T) string) []T {
+	less := func(keyA, idA, keyB, idB string) bool {
+		if keyA != keyB {
+			return keyA < keyB
+		}
+		return idA < idB
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if page.Desc {
+			return less(key(items[j]), id(items[j]), key(items[i]), id(items[i]))
+		}
+		return less(key(items[i]), id(items[i]), key(items[j]), id(items[j]))
+	})
+
+	if page.After != nil {
+		start := len(items)
+		for i, item := range items {
+			if page.Desc && less(key(item), id(item), page.After.Key, page.After.ID) ||
+				!page.Desc && less(page.After.Key, page.After.ID, key(item), id(item)) {
+				start = i
+				break
+			}
+		}
+		items = items[start:]
+	}
+
+	if page.Limit > 0 && page.Limit < len(items) {
+		items = items[:page.Limit]
+	}
+	return items
+}
@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresAPIKeyRepository implements the APIKeyRepository interface using PostgreSQL
+type PostgresAPIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAPIKeyRepository creates a new instance of PostgresAPIKeyRepository
+func NewPostgresAPIKeyRepository(pool *pgxpool.Pool) repository.APIKeyRepository {
+	return &PostgresAPIKeyRepository{
+		pool: pool,
+	}
+}
+
+// apiKeyColumns lists the API key columns in the order scanAPIKey reads them
+const apiKeyColumns = `id, clinic_id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, last_used_ip, created_at, revoked_at`
+
+// Create stores a new API key
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, clinic_id, name, prefix, key_hash, scopes, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, key.ClinicID)
+	if err != nil {
+		return err
+	}
+	key.ClinicID = clinicID
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		key.ID,
+		key.ClinicID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		joinPermissions(key.Scopes),
+		nullIfEmpty(key.CreatedBy),
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves an API key by its unique identifier, or nil if there is none
+func (r *PostgresAPIKeyRepository) FindByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	query, args := scope(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, []interface{}{id}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByPrefix retrieves an API key by its prefix, or nil if there is none
+func (r *PostgresAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query, args := scope(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, []interface{}{prefix}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// List retrieves every API key, newest first
+func (r *PostgresAPIKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	query, args := scope(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE 1=1`, nil, ownClinic(""))
+	query += ` ORDER BY created_at DESC, id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke sets revoked_at on a key that is not revoked yet
+// Returns false if it was revoked first
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+	query, args := scope(ctx, query, []interface{}{revokedAt, id}, ownClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RecordUse stores when and from where a key was last used and counts the
+// request in the usage of that day
+func (r *PostgresAPIKeyRepository) RecordUse(ctx context.Context, id string, usedAt time.Time, ip string) error {
+	query, args := scope(ctx, `UPDATE api_keys SET last_used_at = $1, last_used_ip = $2 WHERE id = $3`, []interface{}{usedAt, ip, id}, ownClinic(""))
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	_, err := conn(ctx, r.pool).Exec(ctx, `
+		INSERT INTO api_key_usage (key_id, day, requests)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1
+	`, id, usageDay(usedAt))
+
+	return mapError(err)
+}
+
+// ListUsage retrieves the daily usage of a key since a day, oldest first
+func (r *PostgresAPIKeyRepository) ListUsage(ctx context.Context, id string, since time.Time) ([]*domain.APIKeyUsage, error) {
+	query := `
+		SELECT key_id, day, requests
+		FROM api_key_usage
+		WHERE key_id = $1 AND day >= $2
+	`
+	query, args := scope(ctx, query, []interface{}{id, usageDay(since)}, "key_id IN (SELECT id FROM api_keys WHERE clinic_id = $%d)")
+	query += ` ORDER BY day`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []*domain.APIKeyUsage
+	for rows.Next() {
+		var day domain.APIKeyUsage
+		if err := rows.Scan(&day.KeyID, &day.Day, &day.Requests); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &day)
+	}
+
+	return usage, rows.Err()
+}
+
+// findOne runs a query selecting apiKeyColumns and returns its single row, or nil if there is none
+func (r *PostgresAPIKeyRepository) findOne(ctx context.Context, query string, args []interface{}) (*domain.APIKey, error) {
+	key, err := scanAPIKey(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// scanAPIKey reads a row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes string
+	var createdBy *string
+
+	err := row.Scan(
+		&key.ID,
+		&key.ClinicID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&createdBy,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.LastUsedIP,
+		&key.CreatedAt,
+		&key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = splitPermissions(scopes)
+	if createdBy != nil {
+		key.CreatedBy = *createdBy
+	}
+
+	return &key, nil
+}
+
+// usageDay truncates a time to the UTC day its usage is counted in
+func usageDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
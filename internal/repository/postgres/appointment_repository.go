@@ -0,0 +1,849 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/fieldcrypt"
+)
+
+// PostgresAppointmentRepository implements the AppointmentRepository interface using PostgreSQL
+// Appointment notes are stored encrypted
+type PostgresAppointmentRepository struct {
+	pool   *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPostgresAppointmentRepository creates a new instance of PostgresAppointmentRepository
+func NewPostgresAppointmentRepository(pool *pgxpool.Pool, cipher *fieldcrypt.Cipher) repository.AppointmentRepository {
+	return &PostgresAppointmentRepository{
+		pool:   pool,
+		cipher: cipher,
+	}
+}
+
+// appointmentColumns is the column list shared by the basic appointment queries
+const appointmentColumns = `id, patient_id, doctor_id, location_id, scheduled_at, duration, status, reason, COALESCE(notes, ''),
+	created_at, updated_at, reminder_24h_sent, reminder_1h_sent, version`
+
+// Create inserts a new appointment into the database
+func (r *PostgresAppointmentRepository) Create(ctx context.Context, appointment *domain.Appointment) error {
+	query := `
+		INSERT INTO appointments (
+			id, patient_id, doctor_id, location_id, scheduled_at, duration,
+			reason, notes, status, created_at, updated_at,
+			reminder_24h_sent, reminder_1h_sent, service_id, version
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	if appointment.LocationID == "" {
+		appointment.LocationID = domain.DefaultLocationID
+	}
+
+	if appointment.Version == 0 {
+		appointment.Version = 1
+	}
+
+	notes, err := r.cipher.Encrypt(appointment.Notes)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		appointment.ID,
+		appointment.PatientID,
+		appointment.DoctorID,
+		appointment.LocationID,
+		appointment.ScheduledAt,
+		appointment.Duration,
+		appointment.Reason,
+		notes,
+		string(appointment.Status),
+		appointment.CreatedAt,
+		appointment.UpdatedAt,
+		appointment.Reminder24hSent,
+		appointment.Reminder1hSent,
+		nullIfEmpty(appointment.ServiceID),
+		appointment.Version,
+	)
+
+	return mapError(err)
+}
+
+// CreateMany inserts several appointments in a single COPY round trip
+func (r *PostgresAppointmentRepository) CreateMany(ctx context.Context, appointments []*domain.Appointment) error {
+	for _, a := range appointments {
+		if a.LocationID == "" {
+			a.LocationID = domain.DefaultLocationID
+		}
+	}
+
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"appointments"},
+		[]string{
+			"id", "patient_id", "doctor_id", "location_id", "service_id", "scheduled_at", "duration", "reason", "notes", "status",
+			"created_at", "updated_at", "cancelled_at", "cancellation_reason", "reminder_24h_sent", "reminder_1h_sent",
+		},
+		pgx.CopyFromSlice(len(appointments), func(i int) ([]interface{}, error) {
+			a := appointments[i]
+			ids := make([]interface{}, 0, 5)
+			for _, id := range []string{a.ID, a.PatientID, a.DoctorID, a.LocationID, a.ServiceID} {
+				u, err := toUUID(id)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, u)
+			}
+			notes, err := r.cipher.Encrypt(a.Notes)
+			if err != nil {
+				return nil, err
+			}
+			return append(ids,
+				a.ScheduledAt, a.Duration, a.Reason, notes, string(a.Status),
+				a.CreatedAt, a.UpdatedAt, a.CancelledAt, nullIfEmpty(a.CancellationReason),
+				a.Reminder24hSent, a.Reminder1hSent,
+			), nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves an appointment by its unique identifier
+func (r *PostgresAppointmentRepository) FindByID(ctx context.Context, id string) (*domain.Appointment, error) {
+	query, args := scope(ctx, `SELECT `+appointmentColumns+` FROM appointments WHERE id = $1`, []interface{}{id}, locationClinic(""))
+
+	return r.queryAppointment(ctx, query, args...)
+}
+
+// FindByIDForUpdate retrieves an appointment and locks its row until the current transaction ends
+func (r *PostgresAppointmentRepository) FindByIDForUpdate(ctx context.Context, id string) (*domain.Appointment, error) {
+	query, args := scope(ctx, `SELECT `+appointmentColumns+` FROM appointments WHERE id = $1`, []interface{}{id}, locationClinic(""))
+
+	return r.queryAppointment(ctx, query+" FOR UPDATE", args...)
+}
+
+// LockDoctor locks the doctor row so concurrent bookings for the same doctor
+// wait for each other instead of both passing the overlap check
+func (r *PostgresAppointmentRepository) LockDoctor(ctx context.Context, doctorID string) error {
+	query := `SELECT 1 FROM doctors WHERE id = $1 FOR UPDATE`
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, doctorID)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// queryAppointment runs a single-row appointment query, returning nil if nothing matches
+func (r *PostgresAppointmentRepository) queryAppointment(ctx context.Context, query string, args ...interface{}) (*domain.Appointment, error) {
+	appointment, err := r.scanAppointment(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+// FindByPatientID retrieves all appointments for a specific patient
+func (r *PostgresAppointmentRepository) FindByPatientID(ctx context.Context, patientID string) ([]*domain.Appointment, error) {
+	query := `
+		SELECT
+			a.id,
+			a.patient_id,
+			a.doctor_id,
+			a.location_id,
+			a.scheduled_at,
+			a.duration,
+			a.status,
+			a.reason,
+			COALESCE(a.notes, ''),
+			a.created_at,
+			a.updated_at,
+			a.reminder_24h_sent,
+			a.reminder_1h_sent,
+			a.version,
+			(pu.first_name || ' ' || pu.last_name) as patient_name,
+			(du.first_name || ' ' || du.last_name) as doctor_name
+		FROM appointments a
+		JOIN patients pt ON pt.id = a.patient_id
+		JOIN users pu ON pu.id = pt.user_id
+		JOIN doctors doc ON doc.id = a.doctor_id
+		JOIN users du ON du.id = doc.user_id
+		WHERE a.patient_id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{patientID}, locationClinic("a."))
+
+	return r.queryAppointmentsWithNames(ctx, query+" ORDER BY a.scheduled_at DESC", args...)
+}
+
+// FindByDoctorAndDate retrieves all appointments for a doctor on a specific date
+func (r *PostgresAppointmentRepository) FindByDoctorAndDate(ctx context.Context, doctorID string, date time.Time) ([]*domain.Appointment, error) {
+	query := `
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE doctor_id = $1 AND scheduled_at >= $2 AND scheduled_at < $3
+	`
+
+	// Compare against the calendar day in the caller's location rather than the session time zone
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	query, args := scope(ctx, query, []interface{}{doctorID, startOfDay, endOfDay}, locationClinic(""))
+
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
+}
+
+// FindByDoctorAndDateRange retrieves appointments for a doctor within a date range
+func (r *PostgresAppointmentRepository) FindByDoctorAndDateRange(ctx context.Context, doctorID string, start, end time.Time) ([]*domain.Appointment, error) {
+	query := `
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE doctor_id = $1 AND scheduled_at >= $2 AND scheduled_at < $3
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, start, end}, locationClinic(""))
+
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
+}
+
+// FindByDoctorsAndDateRange retrieves the appointments of several doctors within a date range in one query
+func (r *PostgresAppointmentRepository) FindByDoctorsAndDateRange(ctx context.Context, doctorIDs []string, start, end time.Time) ([]*domain.Appointment, error) {
+	if len(doctorIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE doctor_id = ANY($1) AND scheduled_at >= $2 AND scheduled_at < $3
+	`
+	query, args := scope(ctx, query, []interface{}{doctorIDs, start, end}, locationClinic(""))
+
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
+}
+
+// Update modifies the schedule, location, status and notes of an existing
+// appointment if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *PostgresAppointmentRepository) Update(ctx context.Context, appointment *domain.Appointment) error {
+	query := `
+		UPDATE appointments
+		SET scheduled_at = $1, duration = $2, location_id = $3, status = $4, notes = $5, updated_at = $6, version = version + 1
+		WHERE id = $7 AND version = $8
+	`
+
+	notes, err := r.cipher.Encrypt(appointment.Notes)
+	if err != nil {
+		return err
+	}
+
+	query, args := scope(ctx, query, []interface{}{
+		appointment.ScheduledAt,
+		appointment.Duration,
+		appointment.LocationID,
+		string(appointment.Status),
+		notes,
+		appointment.UpdatedAt,
+		appointment.ID,
+		appointment.Version,
+	}, locationClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil {
+		return errors.New("appointment not found")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return versionMismatch(ctx, r.pool, `SELECT 1 FROM appointments WHERE id = $1`, appointment.ID, locationClinic(""), errors.New("appointment not found"))
+	}
+
+	appointment.Version++
+	return nil
+}
+
+// Delete removes an appointment from the database
+func (r *PostgresAppointmentRepository) Delete(ctx context.Context, id string) error {
+	query, args := scope(ctx, `DELETE FROM appointments WHERE id = $1`, []interface{}{id}, locationClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("appointment not found")
+	}
+
+	return nil
+}
+
+// FindByScheduledAtRange finds appointments within a time range with specific status
+func (r *PostgresAppointmentRepository) FindByScheduledAtRange(ctx context.Context, start, end time.Time, status string) ([]*domain.Appointment, error) {
+	query := `
+		SELECT ` + appointmentColumns + `
+		FROM appointments
+		WHERE scheduled_at >= $1 AND scheduled_at <= $2 AND status = $3
+	`
+	query, args := scope(ctx, query, []interface{}{start, end, status}, locationClinic(""))
+
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
+}
+
+// MarkReminder24hSent marks the 24-hour reminder as sent
+func (r *PostgresAppointmentRepository) MarkReminder24hSent(ctx context.Context, id string) error {
+	query, args := scope(ctx, `UPDATE appointments SET reminder_24h_sent = TRUE WHERE id = $1`, []interface{}{id}, locationClinic(""))
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// MarkReminder1hSent marks the 1-hour reminder as sent
+func (r *PostgresAppointmentRepository) MarkReminder1hSent(ctx context.Context, id string) error {
+	query, args := scope(ctx, `UPDATE appointments SET reminder_1h_sent = TRUE WHERE id = $1`, []interface{}{id}, locationClinic(""))
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// queryAppointments is a helper method to query appointments selected with appointmentColumns
+func (r *PostgresAppointmentRepository) queryAppointments(ctx context.Context, query string, args ...interface{}) ([]*domain.Appointment, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []*domain.Appointment
+	for rows.Next() {
+		appointment, err := r.scanAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appointments = append(appointments, appointment)
+	}
+
+	return appointments, rows.Err()
+}
+
+// queryAppointmentsWithNames is a helper method to query appointments with patient and doctor names
+func (r *PostgresAppointmentRepository) queryAppointmentsWithNames(ctx context.Context, query string, args ...interface{}) ([]*domain.Appointment, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []*domain.Appointment
+
+	for rows.Next() {
+		var appointment domain.Appointment
+		var status string
+
+		err := rows.Scan(
+			&appointment.ID,
+			&appointment.PatientID,
+			&appointment.DoctorID,
+			&appointment.LocationID,
+			&appointment.ScheduledAt,
+			&appointment.Duration,
+			&status,
+			&appointment.Reason,
+			&appointment.Notes,
+			&appointment.CreatedAt,
+			&appointment.UpdatedAt,
+			&appointment.Reminder24hSent,
+			&appointment.Reminder1hSent,
+			&appointment.Version,
+			&appointment.PatientName,
+			&appointment.DoctorName,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.decryptNotes(&appointment); err != nil {
+			return nil, err
+		}
+
+		appointment.Status = domain.AppointmentStatus(status)
+
+		appointments = append(appointments, &appointment)
+	}
+
+	return appointments, rows.Err()
+}
+
+// scanAppointment reads an appointment row selected with appointmentColumns
+func (r *PostgresAppointmentRepository) scanAppointment(row rowScanner) (*domain.Appointment, error) {
+	var appointment domain.Appointment
+	var status string
+
+	err := row.Scan(
+		&appointment.ID,
+		&appointment.PatientID,
+		&appointment.DoctorID,
+		&appointment.LocationID,
+		&appointment.ScheduledAt,
+		&appointment.Duration,
+		&status,
+		&appointment.Reason,
+		&appointment.Notes,
+		&appointment.CreatedAt,
+		&appointment.UpdatedAt,
+		&appointment.Reminder24hSent,
+		&appointment.Reminder1hSent,
+		&appointment.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.decryptNotes(&appointment); err != nil {
+		return nil, err
+	}
+
+	appointment.Status = domain.AppointmentStatus(status)
+
+	return &appointment, nil
+}
+
+// CountByStatus counts appointments by status, at one location if locationID is set
+func (r *PostgresAppointmentRepository) CountByStatus(ctx context.Context, status, locationID string) (int, error) {
+	query, args := atLocation(ctx, `SELECT COUNT(*) FROM appointments WHERE status = $1`, []interface{}{status}, locationID, "")
+
+	var count int
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountAll counts all appointments, at one location if locationID is set
+func (r *PostgresAppointmentRepository) CountAll(ctx context.Context, locationID string) (int, error) {
+	query, args := atLocation(ctx, `SELECT COUNT(*) FROM appointments WHERE 1=1`, nil, locationID, "")
+
+	var count int
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// GetTotalRevenue calculates total revenue from completed appointments, each
+// charged at the price of its service at the location it took place
+func (r *PostgresAppointmentRepository) GetTotalRevenue(ctx context.Context, locationID string) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(COALESCE(sp.price, s.price)), 0)
+		FROM appointments a
+		JOIN services s ON a.service_id = s.id
+		LEFT JOIN service_prices sp ON sp.service_id = a.service_id AND sp.location_id = a.location_id
+		WHERE a.status = $1
+	`
+	query, args := atLocation(ctx, query, []interface{}{string(domain.StatusCompleted)}, locationID, "a.")
+
+	var revenue float64
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&revenue); err != nil {
+		return 0, err
+	}
+
+	return revenue, nil
+}
+
+// GetRevenueByService gets revenue grouped by service, priced like GetTotalRevenue
+func (r *PostgresAppointmentRepository) GetRevenueByService(ctx context.Context, locationID string) (map[string]struct {
+	ServiceName string
+	Count       int
+	Revenue     float64
+}, error) {
+	query := `
+		SELECT
+			a.service_id,
+			s.name,
+			COUNT(*) as count,
+			SUM(COALESCE(sp.price, s.price)) as revenue
+		FROM appointments a
+		JOIN services s ON a.service_id = s.id
+		LEFT JOIN service_prices sp ON sp.service_id = a.service_id AND sp.location_id = a.location_id
+		WHERE a.status = $1
+	`
+	query, args := atLocation(ctx, query, []interface{}{string(domain.StatusCompleted)}, locationID, "a.")
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" GROUP BY a.service_id, s.name ORDER BY revenue DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]struct {
+		ServiceName string
+		Count       int
+		Revenue     float64
+	})
+
+	for rows.Next() {
+		var serviceID, serviceName string
+		var count int
+		var revenue float64
+
+		if err := rows.Scan(&serviceID, &serviceName, &count, &revenue); err != nil {
+			return nil, err
+		}
+
+		result[serviceID] = struct {
+			ServiceName string
+			Count       int
+			Revenue     float64
+		}{
+			ServiceName: serviceName,
+			Count:       count,
+			Revenue:     revenue,
+		}
+	}
+
+	return result, rows.Err()
+}
+
+// GetTopDoctors gets doctors with most appointments
+func (r *PostgresAppointmentRepository) GetTopDoctors(ctx context.Context, limit int, locationID string) ([]struct {
+	DoctorID              string
+	TotalAppointments     int
+	CompletedAppointments int
+}, error) {
+	query := `
+		SELECT
+			doctor_id,
+			COUNT(*) as total,
+			COUNT(*) FILTER (WHERE status = 'completed') as completed
+		FROM appointments
+		WHERE 1=1
+	`
+	query, args := atLocation(ctx, query, nil, locationID, "")
+	query += fmt.Sprintf(" GROUP BY doctor_id ORDER BY total DESC LIMIT $%d", len(args)+1)
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []struct {
+		DoctorID              string
+		TotalAppointments     int
+		CompletedAppointments int
+	}
+
+	for rows.Next() {
+		var item struct {
+			DoctorID              string
+			TotalAppointments     int
+			CompletedAppointments int
+		}
+
+		if err := rows.Scan(&item.DoctorID, &item.TotalAppointments, &item.CompletedAppointments); err != nil {
+			return nil, err
+		}
+
+		results = append(results, item)
+	}
+
+	return results, rows.Err()
+}
+
+// GetTopServices gets most used services
+func (r *PostgresAppointmentRepository) GetTopServices(ctx context.Context, limit int, locationID string) ([]struct {
+	ServiceID   string
+	ServiceName string
+	Count       int
+}, error) {
+	query := `
+		SELECT
+			a.service_id,
+			s.name,
+			COUNT(*) as count
+		FROM appointments a
+		JOIN services s ON a.service_id = s.id
+		WHERE 1=1
+	`
+	query, args := atLocation(ctx, query, nil, locationID, "a.")
+	query += fmt.Sprintf(" GROUP BY a.service_id, s.name ORDER BY count DESC LIMIT $%d", len(args)+1)
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []struct {
+		ServiceID   string
+		ServiceName string
+		Count       int
+	}
+
+	for rows.Next() {
+		var item struct {
+			ServiceID   string
+			ServiceName string
+			Count       int
+		}
+
+		if err := rows.Scan(&item.ServiceID, &item.ServiceName, &item.Count); err != nil {
+			return nil, err
+		}
+
+		results = append(results, item)
+	}
+
+	return results, rows.Err()
+}
+
+// FindAllWithFilters retrieves one page of appointments matching the optional filters
+func (r *PostgresAppointmentRepository) FindAllWithFilters(ctx context.Context, filters repository.AppointmentFilters, page repository.PageRequest) ([]*domain.Appointment, error) {
+	query := `
+		SELECT
+			a.id,
+			a.patient_id,
+			a.doctor_id,
+			a.location_id,
+			COALESCE(a.service_id::text, ''),
+			a.scheduled_at,
+			a.duration,
+			a.reason,
+			COALESCE(a.notes, ''),
+			a.status,
+			a.cancelled_at,
+			COALESCE(a.cancellation_reason, ''),
+			a.reminder_24h_sent,
+			a.reminder_1h_sent,
+			a.version,
+			a.created_at,
+			a.updated_at,
+			COALESCE(u_patient.first_name || ' ' || u_patient.last_name, '') as patient_name,
+			COALESCE(u_doctor.first_name || ' ' || u_doctor.last_name, '') as doctor_name,
+			COALESCE(s.name, '') as service_name
+		FROM appointments a
+		LEFT JOIN patients p ON a.patient_id = p.id
+		LEFT JOIN users u_patient ON p.user_id = u_patient.id
+		LEFT JOIN doctors d ON a.doctor_id = d.id
+		LEFT JOIN users u_doctor ON d.user_id = u_doctor.id
+		LEFT JOIN services s ON a.service_id = s.id
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, []interface{}{}, locationClinic("a."))
+	argIndex := len(args) + 1
+
+	// Add filters dynamically
+	if filters.ID != "" {
+		query += " AND a.id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.ID)
+		argIndex++
+	}
+
+	if filters.Status != "" {
+		query += " AND a.status = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.Status)
+		argIndex++
+	}
+
+	if filters.DoctorID != "" {
+		query += " AND a.doctor_id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.DoctorID)
+		argIndex++
+	}
+
+	if filters.PatientID != "" {
+		query += " AND a.patient_id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.PatientID)
+		argIndex++
+	}
+
+	if filters.ServiceID != "" {
+		query += " AND a.service_id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.ServiceID)
+		argIndex++
+	}
+
+	if filters.LocationID != "" {
+		query += " AND a.location_id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.LocationID)
+		argIndex++
+	}
+
+	if filters.DateFrom != nil {
+		query += " AND a.scheduled_at >= $" + fmt.Sprint(argIndex)
+		args = append(args, *filters.DateFrom)
+		argIndex++
+	}
+
+	if filters.DateTo != nil {
+		query += " AND a.scheduled_at <= $" + fmt.Sprint(argIndex)
+		args = append(args, *filters.DateTo)
+		argIndex++
+	}
+
+	query, args, err := keysetPage(query, args, page, appointmentSortExpr(page.Sort, "a."), "a.id")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var appointments []*domain.Appointment
+	for rows.Next() {
+		var a domain.Appointment
+		var status string
+
+		err := rows.Scan(
+			&a.ID,
+			&a.PatientID,
+			&a.DoctorID,
+			&a.LocationID,
+			&a.ServiceID,
+			&a.ScheduledAt,
+			&a.Duration,
+			&a.Reason,
+			&a.Notes,
+			&status,
+			&a.CancelledAt,
+			&a.CancellationReason,
+			&a.Reminder24hSent,
+			&a.Reminder1hSent,
+			&a.Version,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+			&a.PatientName,
+			&a.DoctorName,
+			&a.ServiceName,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.decryptNotes(&a); err != nil {
+			return nil, err
+		}
+
+		a.Status = domain.AppointmentStatus(status)
+
+		appointments = append(appointments, &a)
+	}
+
+	return appointments, rows.Err()
+}
+
+// CountFutureAppointmentsByDoctorAndService counts future appointments for a specific doctor-service combination
+func (r *PostgresAppointmentRepository) CountFutureAppointmentsByDoctorAndService(ctx context.Context, doctorID, serviceID string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM appointments
+		WHERE doctor_id = $1
+		AND service_id = $2
+		AND scheduled_at > now()
+		AND status != $3
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, serviceID, string(domain.StatusCancelled)}, locationClinic(""))
+
+	var count int
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&count)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Reencrypt rewrites the notes that are not sealed with the primary key
+// Rows are read in batches by ID and each one is updated on its own, so an
+// interrupted run can simply be started again. The version is left alone:
+// the appointment itself does not change
+func (r *PostgresAppointmentRepository) Reencrypt(ctx context.Context) (int, error) {
+	type encryptedRow struct {
+		id, notes string
+	}
+
+	rewritten := 0
+	lastID := zeroUUID
+	for {
+		query, args := scope(ctx, `
+			SELECT id, COALESCE(notes, '')
+			FROM appointments
+			WHERE id > $1
+		`, []interface{}{lastID}, locationClinic(""))
+		query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args)+1)
+
+		rows, err := conn(ctx, r.pool).Query(ctx, query, append(args, reencryptBatch)...)
+		if err != nil {
+			return rewritten, err
+		}
+
+		var batch []encryptedRow
+		for rows.Next() {
+			var row encryptedRow
+			if err := rows.Scan(&row.id, &row.notes); err != nil {
+				rows.Close()
+				return rewritten, err
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, row := range batch {
+			lastID = row.id
+
+			values, err := rewrap(r.cipher, row.notes)
+			if err != nil {
+				return rewritten, fmt.Errorf("appointment %s: %w", row.id, err)
+			}
+			if values == nil {
+				continue
+			}
+
+			if _, err := conn(ctx, r.pool).Exec(ctx, `UPDATE appointments SET notes = $1 WHERE id = $2`, values[0], row.id); err != nil {
+				return rewritten, mapError(err)
+			}
+			rewritten++
+		}
+	}
+}
+
+// decryptNotes opens the notes of a scanned appointment in place
+func (r *PostgresAppointmentRepository) decryptNotes(appointment *domain.Appointment) error {
+	notes, err := r.cipher.Decrypt(appointment.Notes)
+	if err != nil {
+		return fmt.Errorf("appointment %s: %w", appointment.ID, err)
+	}
+
+	appointment.Notes = notes
+	return nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresAuditRepository implements the AuditRepository interface using PostgreSQL
+type PostgresAuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresAuditRepository creates a new instance of PostgresAuditRepository
+func NewPostgresAuditRepository(pool *pgxpool.Pool) repository.AuditRepository {
+	return &PostgresAuditRepository{
+		pool: pool,
+	}
+}
+
+// Create appends an entry to the audit log
+func (r *PostgresAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (id, clinic_id, actor_id, actor_role, entity, entity_id, action, changes, ip_address, request_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, entry.ClinicID)
+	if err != nil {
+		return err
+	}
+	entry.ClinicID = clinicID
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		entry.ID,
+		entry.ClinicID,
+		entry.ActorID,
+		entry.ActorRole,
+		entry.Entity,
+		entry.EntityID,
+		entry.Action,
+		string(entry.Changes),
+		entry.IPAddress,
+		entry.RequestID,
+		entry.CreatedAt,
+	)
+
+	return mapError(err)
+}
+
+// List retrieves entries matching the filters, newest first
+func (r *PostgresAuditRepository) List(ctx context.Context, filters repository.AuditFilters) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT id, clinic_id, actor_id, actor_role, entity, entity_id, action, changes, ip_address, request_id, created_at
+		FROM audit_log
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, []interface{}{}, ownClinic(""))
+	argIndex := len(args) + 1
+
+	// Add filters dynamically
+	if filters.Entity != "" {
+		query += " AND entity = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.Entity)
+		argIndex++
+	}
+
+	if filters.EntityID != "" {
+		query += " AND entity_id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.EntityID)
+		argIndex++
+	}
+
+	if filters.ActorID != "" {
+		query += " AND actor_id = $" + fmt.Sprint(argIndex)
+		args = append(args, filters.ActorID)
+		argIndex++
+	}
+
+	if filters.DateFrom != nil {
+		query += " AND created_at >= $" + fmt.Sprint(argIndex)
+		args = append(args, *filters.DateFrom)
+		argIndex++
+	}
+
+	if filters.DateTo != nil {
+		query += " AND created_at <= $" + fmt.Sprint(argIndex)
+		args = append(args, *filters.DateTo)
+		argIndex++
+	}
+
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argIndex, argIndex+1)
+	args = append(args, filters.Limit, filters.Offset)
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var changes []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ClinicID,
+			&entry.ActorID,
+			&entry.ActorRole,
+			&entry.Entity,
+			&entry.EntityID,
+			&entry.Action,
+			&changes,
+			&entry.IPAddress,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Changes = changes
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
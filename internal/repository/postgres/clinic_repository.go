@@ -0,0 +1,380 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresClinicRepository implements the ClinicRepository interface using PostgreSQL
+type PostgresClinicRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresClinicRepository creates a new instance of PostgresClinicRepository
+func NewPostgresClinicRepository(pool *pgxpool.Pool) repository.ClinicRepository {
+	return &PostgresClinicRepository{
+		pool: pool,
+	}
+}
+
+// locationColumns is the column list shared by every location query
+const locationColumns = `id, clinic_id, name, address, phone, is_active, created_at, updated_at, version`
+
+// CreateClinic inserts a new clinic
+func (r *PostgresClinicRepository) CreateClinic(ctx context.Context, clinic *domain.Clinic) error {
+	query := `
+		INSERT INTO clinics (id, name, currency_symbol, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		clinic.ID,
+		clinic.Name,
+		clinic.CurrencySymbol,
+		clinic.CreatedAt,
+		clinic.UpdatedAt,
+	)
+
+	return mapError(err)
+}
+
+// FindClinicByID retrieves a clinic, or nil if there is none with that ID
+func (r *PostgresClinicRepository) FindClinicByID(ctx context.Context, id string) (*domain.Clinic, error) {
+	query := `
+		SELECT id, name, currency_symbol, created_at, updated_at
+		FROM clinics
+		WHERE id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{id}, "id = $%d")
+
+	clinics, err := r.queryClinics(ctx, query, args...)
+	if err != nil || len(clinics) == 0 {
+		return nil, err
+	}
+
+	return clinics[0], nil
+}
+
+// ListClinics retrieves every clinic, oldest first
+func (r *PostgresClinicRepository) ListClinics(ctx context.Context) ([]*domain.Clinic, error) {
+	query := `
+		SELECT id, name, currency_symbol, created_at, updated_at
+		FROM clinics
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, "id = $%d")
+
+	return r.queryClinics(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+}
+
+// CreateLocation inserts a new location
+func (r *PostgresClinicRepository) CreateLocation(ctx context.Context, location *domain.Location) error {
+	query := `
+		INSERT INTO locations (` + locationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, location.ClinicID)
+	if err != nil {
+		return err
+	}
+	location.ClinicID = clinicID
+
+	if location.Version == 0 {
+		location.Version = 1
+	}
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		location.ID,
+		location.ClinicID,
+		location.Name,
+		location.Address,
+		location.Phone,
+		location.IsActive,
+		location.CreatedAt,
+		location.UpdatedAt,
+		location.Version,
+	)
+
+	return mapError(err)
+}
+
+// FindLocationByID retrieves a location, or nil if there is none with that ID
+func (r *PostgresClinicRepository) FindLocationByID(ctx context.Context, id string) (*domain.Location, error) {
+	query, args := scope(ctx, `SELECT `+locationColumns+` FROM locations WHERE id = $1`, []interface{}{id}, ownClinic(""))
+
+	locations, err := r.queryLocations(ctx, query, args...)
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+
+	return locations[0], nil
+}
+
+// ListLocations retrieves the locations of the clinic, oldest first
+func (r *PostgresClinicRepository) ListLocations(ctx context.Context, activeOnly bool) ([]*domain.Location, error) {
+	query, args := scope(ctx, `SELECT `+locationColumns+` FROM locations WHERE 1=1`, nil, ownClinic(""))
+
+	if activeOnly {
+		query += " AND is_active = TRUE"
+	}
+
+	return r.queryLocations(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+}
+
+// UpdateLocation modifies a location if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *PostgresClinicRepository) UpdateLocation(ctx context.Context, location *domain.Location) error {
+	query := `
+		UPDATE locations
+		SET name = $1, address = $2, phone = $3, is_active = $4, updated_at = $5, version = version + 1
+		WHERE id = $6 AND version = $7
+	`
+	query, args := scope(ctx, query, []interface{}{
+		location.Name,
+		location.Address,
+		location.Phone,
+		location.IsActive,
+		location.UpdatedAt,
+		location.ID,
+		location.Version,
+	}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil {
+		return errors.New("location not found")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return versionMismatch(ctx, r.pool, `SELECT 1 FROM locations WHERE id = $1`, location.ID, ownClinic(""), errors.New("location not found"))
+	}
+
+	location.Version++
+	return nil
+}
+
+// AssignDoctor attaches a doctor to a location
+// Assigning a doctor twice is not an error
+func (r *PostgresClinicRepository) AssignDoctor(ctx context.Context, doctorLocation *domain.DoctorLocation) error {
+	query := `
+		INSERT INTO doctor_locations (doctor_id, location_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (doctor_id, location_id) DO NOTHING
+	`
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, doctorLocation.DoctorID, doctorLocation.LocationID, doctorLocation.CreatedAt)
+	return mapError(err)
+}
+
+// RemoveDoctor detaches a doctor from a location
+func (r *PostgresClinicRepository) RemoveDoctor(ctx context.Context, doctorID, locationID string) error {
+	query, args := scope(ctx, `DELETE FROM doctor_locations WHERE doctor_id = $1 AND location_id = $2`, []interface{}{doctorID, locationID}, locationClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("doctor-location relationship not found")
+	}
+
+	return nil
+}
+
+// FindLocationsByDoctor retrieves the locations a doctor works at, oldest first
+func (r *PostgresClinicRepository) FindLocationsByDoctor(ctx context.Context, doctorID string) ([]*domain.Location, error) {
+	query := `
+		SELECT l.id, l.clinic_id, l.name, l.address, l.phone, l.is_active, l.created_at, l.updated_at, l.version
+		FROM locations l
+		INNER JOIN doctor_locations dl ON dl.location_id = l.id
+		WHERE dl.doctor_id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID}, ownClinic("l."))
+
+	return r.queryLocations(ctx, query+" ORDER BY l.created_at ASC, l.id ASC", args...)
+}
+
+// ListDoctorLocations retrieves every doctor-location assignment, oldest first
+func (r *PostgresClinicRepository) ListDoctorLocations(ctx context.Context) ([]*domain.DoctorLocation, error) {
+	query := `
+		SELECT doctor_id, location_id, created_at
+		FROM doctor_locations
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, locationClinic(""))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY created_at ASC, doctor_id ASC, location_id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctorLocations []*domain.DoctorLocation
+	for rows.Next() {
+		var dl domain.DoctorLocation
+		if err := rows.Scan(&dl.DoctorID, &dl.LocationID, &dl.CreatedAt); err != nil {
+			return nil, err
+		}
+		doctorLocations = append(doctorLocations, &dl)
+	}
+
+	return doctorLocations, rows.Err()
+}
+
+// SetServicePrice creates or replaces the price of a service at a location
+func (r *PostgresClinicRepository) SetServicePrice(ctx context.Context, price *domain.ServicePrice) error {
+	query := `
+		INSERT INTO service_prices (service_id, location_id, price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (service_id, location_id) DO UPDATE SET price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		price.ServiceID,
+		price.LocationID,
+		price.Price,
+		price.CreatedAt,
+		price.UpdatedAt,
+	)
+
+	return mapError(err)
+}
+
+// RemoveServicePrice drops a price override so the service's own price applies again
+func (r *PostgresClinicRepository) RemoveServicePrice(ctx context.Context, serviceID, locationID string) error {
+	query, args := scope(ctx, `DELETE FROM service_prices WHERE service_id = $1 AND location_id = $2`, []interface{}{serviceID, locationID}, locationClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("service price not found")
+	}
+
+	return nil
+}
+
+// FindServicePrices retrieves the price overrides of a location
+func (r *PostgresClinicRepository) FindServicePrices(ctx context.Context, locationID string) ([]*domain.ServicePrice, error) {
+	query := `
+		SELECT service_id, location_id, price, created_at, updated_at
+		FROM service_prices
+		WHERE location_id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{locationID}, locationClinic(""))
+
+	return r.queryServicePrices(ctx, query+" ORDER BY created_at ASC, service_id ASC", args...)
+}
+
+// ListServicePrices retrieves every price override, oldest first
+func (r *PostgresClinicRepository) ListServicePrices(ctx context.Context) ([]*domain.ServicePrice, error) {
+	query := `
+		SELECT service_id, location_id, price, created_at, updated_at
+		FROM service_prices
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, locationClinic(""))
+
+	return r.queryServicePrices(ctx, query+" ORDER BY created_at ASC, service_id ASC, location_id ASC", args...)
+}
+
+// queryClinics is a helper method to query clinics
+// A malformed ID matches no clinic rather than failing
+func (r *PostgresClinicRepository) queryClinics(ctx context.Context, query string, args ...interface{}) ([]*domain.Clinic, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clinics []*domain.Clinic
+	for rows.Next() {
+		var clinic domain.Clinic
+		if err := rows.Scan(&clinic.ID, &clinic.Name, &clinic.CurrencySymbol, &clinic.CreatedAt, &clinic.UpdatedAt); err != nil {
+			return nil, err
+		}
+		clinics = append(clinics, &clinic)
+	}
+
+	if err := rows.Err(); err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return clinics, nil
+}
+
+// queryLocations is a helper method to query locations
+// A malformed ID matches no location rather than failing
+func (r *PostgresClinicRepository) queryLocations(ctx context.Context, query string, args ...interface{}) ([]*domain.Location, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []*domain.Location
+	for rows.Next() {
+		var location domain.Location
+		err := rows.Scan(
+			&location.ID,
+			&location.ClinicID,
+			&location.Name,
+			&location.Address,
+			&location.Phone,
+			&location.IsActive,
+			&location.CreatedAt,
+			&location.UpdatedAt,
+			&location.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, &location)
+	}
+
+	if err := rows.Err(); err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// queryServicePrices is a helper method to query price overrides
+// A malformed ID matches no override rather than failing
+func (r *PostgresClinicRepository) queryServicePrices(ctx context.Context, query string, args ...interface{}) ([]*domain.ServicePrice, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*domain.ServicePrice
+	for rows.Next() {
+		var price domain.ServicePrice
+		if err := rows.Scan(&price.ServiceID, &price.LocationID, &price.Price, &price.CreatedAt, &price.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, &price)
+	}
+
+	if err := rows.Err(); err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	return prices, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"version-1-0/internal/repository/migration"
+)
+
+// InitDB initializes and returns a PostgreSQL connection pool
+// With autoMigrate the pending migrations are applied, otherwise the schema must
+// already be current (see cmd/migrate)
+// Returns an error if the connection cannot be established
+func InitDB(databaseURL string, autoMigrate bool) (*pgxpool.Pool, error) {
+	pool, err := Open(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// The migration runner is shared with SQLite and works on database/sql,
+	// so it gets a *sql.DB view over the same pool
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	if autoMigrate {
+		err = migration.Run(db, migration.Postgres)
+	} else {
+		err = migration.CheckCurrent(db, migration.Postgres)
+	}
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	log.Println("Database schema is up to date")
+
+	return pool, nil
+}
+
+// Open creates a PostgreSQL connection pool without touching the schema
+func Open(databaseURL string) (*pgxpool.Pool, error) {
+	ctx := context.Background()
+
+	// Open PostgreSQL connection pool
+	pool, err := pgxpool.New(ctx, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Test connection
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	log.Printf("Database connection established: PostgreSQL")
+
+	return pool, nil
+}
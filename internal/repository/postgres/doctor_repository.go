@@ -0,0 +1,453 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresDoctorRepository implements the DoctorRepository interface using PostgreSQL
+type PostgresDoctorRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresDoctorRepository creates a new instance of PostgresDoctorRepository
+func NewPostgresDoctorRepository(pool *pgxpool.Pool) repository.DoctorRepository {
+	return &PostgresDoctorRepository{
+		pool: pool,
+	}
+}
+
+// doctorColumns is the column list shared by every doctor query
+const doctorColumns = `id, user_id, specialty, license_number, years_of_experience,
+	COALESCE(education, ''), COALESCE(bio, ''), consultation_fee, is_available, created_at, updated_at`
+
+// Create inserts a new doctor into the database
+func (r *PostgresDoctorRepository) Create(ctx context.Context, doctor *domain.Doctor) error {
+	query := `
+		INSERT INTO doctors (id, user_id, specialty, license_number, years_of_experience,
+		                     education, bio, consultation_fee, is_available, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		doctor.ID,
+		doctor.UserID,
+		doctor.Specialty,
+		doctor.LicenseNumber,
+		doctor.YearsOfExperience,
+		doctor.Education,
+		doctor.Bio,
+		doctor.ConsultationFee,
+		doctor.IsAvailable,
+		doctor.CreatedAt,
+		doctor.UpdatedAt,
+	)
+
+	return mapError(err)
+}
+
+// CreateMany inserts several doctors in a single COPY round trip
+func (r *PostgresDoctorRepository) CreateMany(ctx context.Context, doctors []*domain.Doctor) error {
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"doctors"},
+		[]string{"id", "user_id", "specialty", "license_number", "years_of_experience", "education", "bio", "consultation_fee", "is_available", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(doctors), func(i int) ([]interface{}, error) {
+			d := doctors[i]
+			id, err := toUUID(d.ID)
+			if err != nil {
+				return nil, err
+			}
+			userID, err := toUUID(d.UserID)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{id, userID, d.Specialty, d.LicenseNumber, d.YearsOfExperience, d.Education, d.Bio, d.ConsultationFee, d.IsAvailable, d.CreatedAt, d.UpdatedAt}, nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves a doctor by their unique identifier
+func (r *PostgresDoctorRepository) FindByID(ctx context.Context, id string) (*domain.Doctor, error) {
+	query, args := scope(ctx, `SELECT `+doctorColumns+` FROM doctors WHERE id = $1 AND deleted_at IS NULL`, []interface{}{id}, userClinic(""))
+
+	return r.queryDoctor(ctx, query, args...)
+}
+
+// FindByUserID retrieves a doctor by their associated user ID
+func (r *PostgresDoctorRepository) FindByUserID(ctx context.Context, userID string) (*domain.Doctor, error) {
+	query, args := scope(ctx, `SELECT `+doctorColumns+` FROM doctors WHERE user_id = $1 AND deleted_at IS NULL`, []interface{}{userID}, userClinic(""))
+
+	return r.queryDoctor(ctx, query, args...)
+}
+
+// FindBySpecialty retrieves all doctors with a specific specialty
+func (r *PostgresDoctorRepository) FindBySpecialty(ctx context.Context, specialty string) ([]*domain.Doctor, error) {
+	query := `
+		SELECT ` + doctorColumns + `
+		FROM doctors
+		WHERE specialty ILIKE $1 AND deleted_at IS NULL
+	`
+
+	searchPattern := "%" + specialty + "%"
+	query, args := scope(ctx, query, []interface{}{searchPattern}, userClinic(""))
+
+	return r.queryDoctors(ctx, query+" ORDER BY created_at DESC", args...)
+}
+
+// Update modifies an existing doctor in the database
+func (r *PostgresDoctorRepository) Update(ctx context.Context, doctor *domain.Doctor) error {
+	query := `
+		UPDATE doctors
+		SET specialty = $1,
+		    license_number = $2,
+		    years_of_experience = $3,
+		    education = $4,
+		    bio = $5,
+		    consultation_fee = $6,
+		    is_available = $7,
+		    updated_at = $8
+		WHERE id = $9 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{
+		doctor.Specialty,
+		doctor.LicenseNumber,
+		doctor.YearsOfExperience,
+		doctor.Education,
+		doctor.Bio,
+		doctor.ConsultationFee,
+		doctor.IsAvailable,
+		doctor.UpdatedAt,
+		doctor.ID,
+	}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("doctor not found")
+	}
+
+	return nil
+}
+
+// Delete performs a soft delete by setting deleted_at
+func (r *PostgresDoctorRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE doctors
+		SET deleted_at = $1,
+		    updated_at = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), id}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("doctor not found")
+	}
+
+	return nil
+}
+
+// List retrieves a paginated list of doctors
+func (r *PostgresDoctorRepository) List(ctx context.Context, limit, offset int) ([]*domain.Doctor, error) {
+	query := `
+		SELECT ` + doctorColumns + `
+		FROM doctors
+		WHERE deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, nil, userClinic(""))
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+
+	return r.queryDoctors(ctx, query, append(args, limit, offset)...)
+}
+
+// ListDeleted retrieves soft deleted doctors, most recently deleted first
+func (r *PostgresDoctorRepository) ListDeleted(ctx context.Context) ([]*domain.Doctor, error) {
+	query := `
+		SELECT ` + doctorColumns + `, deleted_at
+		FROM doctors
+		WHERE deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, nil, userClinic(""))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY deleted_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctors []*domain.Doctor
+	for rows.Next() {
+		var deletedAt time.Time
+		doctor, err := scanDoctor(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		doctor.DeletedAt = &deletedAt
+		doctors = append(doctors, doctor)
+	}
+
+	return doctors, rows.Err()
+}
+
+// FindDeletedByID retrieves a soft deleted doctor, or nil if there is none with that ID
+func (r *PostgresDoctorRepository) FindDeletedByID(ctx context.Context, id string) (*domain.Doctor, error) {
+	query, args := scope(ctx, `SELECT `+doctorColumns+`, deleted_at FROM doctors WHERE id = $1 AND deleted_at IS NOT NULL`, []interface{}{id}, userClinic(""))
+
+	var deletedAt time.Time
+	doctor, err := scanDoctor(conn(ctx, r.pool).QueryRow(ctx, query, args...), &deletedAt)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	doctor.DeletedAt = &deletedAt
+
+	return doctor, nil
+}
+
+// Restore clears the deletion mark of a soft deleted doctor
+func (r *PostgresDoctorRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE doctors
+		SET deleted_at = NULL,
+		    updated_at = $1
+		WHERE id = $2 AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), id}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("doctor not found")
+	}
+
+	return nil
+}
+
+// RestoreByUserID clears the deletion mark of a user's doctor profile, if it has one
+func (r *PostgresDoctorRepository) RestoreByUserID(ctx context.Context, userID string) error {
+	query := `
+		UPDATE doctors
+		SET deleted_at = NULL,
+		    updated_at = $1
+		WHERE user_id = $2 AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), userID}, userClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return mapError(err)
+}
+
+// queryDoctor runs a single-row doctor query, returning nil if nothing matches
+func (r *PostgresDoctorRepository) queryDoctor(ctx context.Context, query string, args ...interface{}) (*domain.Doctor, error) {
+	doctor, err := scanDoctor(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return doctor, nil
+}
+
+// queryDoctors is a helper method to query a list of doctors
+func (r *PostgresDoctorRepository) queryDoctors(ctx context.Context, query string, args ...interface{}) ([]*domain.Doctor, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctors []*domain.Doctor
+	for rows.Next() {
+		doctor, err := scanDoctor(rows)
+		if err != nil {
+			return nil, err
+		}
+		doctors = append(doctors, doctor)
+	}
+
+	return doctors, rows.Err()
+}
+
+// scanDoctor reads a doctor row selected with doctorColumns
+// Extra destinations are scanned from the columns that follow
+func scanDoctor(row rowScanner, extra ...interface{}) (*domain.Doctor, error) {
+	var doctor domain.Doctor
+
+	dest := []interface{}{
+		&doctor.ID,
+		&doctor.UserID,
+		&doctor.Specialty,
+		&doctor.LicenseNumber,
+		&doctor.YearsOfExperience,
+		&doctor.Education,
+		&doctor.Bio,
+		&doctor.ConsultationFee,
+		&doctor.IsAvailable,
+		&doctor.CreatedAt,
+		&doctor.UpdatedAt,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &doctor, nil
+}
+
+// FindSearchCandidates retrieves the active doctors, with their user and
+// service names, that pass the filters
+func (r *PostgresDoctorRepository) FindSearchCandidates(ctx context.Context, filters repository.DoctorSearchFilters) ([]*repository.DoctorSearchResult, error) {
+	query := `
+		SELECT d.id, d.user_id, d.specialty, d.license_number, d.years_of_experience,
+		       COALESCE(d.education, ''), COALESCE(d.bio, ''), d.consultation_fee, d.is_available, d.created_at, d.updated_at,
+		       u.email, u.first_name, u.last_name, u.phone, u.role, u.is_active, u.created_at, u.updated_at, u.version
+		FROM doctors d
+		INNER JOIN users u ON u.id = d.user_id
+		WHERE u.role = 'doctor' AND u.is_active = TRUE
+		AND u.deleted_at IS NULL AND d.deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{}, ownClinic("u."))
+
+	// Query words hold only letters and digits, so they are safe as prefix terms
+	for _, word := range filters.Words {
+		args = append(args, word+":*")
+		query += fmt.Sprintf(` AND (u.search_name @@ to_tsquery('simple', $%[1]d)
+			OR d.search_specialty @@ to_tsquery('simple', $%[1]d)
+			OR d.search_bio @@ to_tsquery('simple', $%[1]d)
+			OR EXISTS (
+				SELECT 1 FROM doctor_services ds
+				JOIN services s ON s.id = ds.service_id
+				WHERE ds.doctor_id = d.id AND ds.is_active = TRUE
+				AND s.is_active = TRUE AND s.deleted_at IS NULL
+				AND s.search_name @@ to_tsquery('simple', $%[1]d)
+			))`, len(args))
+	}
+
+	if len(filters.Specialty) > 0 {
+		args = append(args, strings.Join(filters.Specialty, ":* & ")+":*")
+		query += fmt.Sprintf(" AND d.search_specialty @@ to_tsquery('simple', $%d)", len(args))
+	}
+
+	if filters.MinFee != nil {
+		args = append(args, *filters.MinFee)
+		query += fmt.Sprintf(" AND d.consultation_fee >= $%d", len(args))
+	}
+
+	if filters.MaxFee != nil {
+		args = append(args, *filters.MaxFee)
+		query += fmt.Sprintf(" AND d.consultation_fee <= $%d", len(args))
+	}
+
+	if filters.ServiceID != "" {
+		args = append(args, filters.ServiceID)
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM doctor_services ds
+			JOIN services s ON s.id = ds.service_id
+			WHERE ds.doctor_id = d.id AND ds.service_id = $%d AND ds.is_active = TRUE
+			AND s.is_active = TRUE AND s.deleted_at IS NULL
+		)`, len(args))
+	}
+
+	if filters.DayOfWeek != "" {
+		args = append(args, filters.DayOfWeek)
+		query += fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM schedules sc
+			WHERE sc.doctor_id = d.id AND sc.day_of_week = $%d AND sc.is_active = TRUE
+		)`, len(args))
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*repository.DoctorSearchResult
+	byDoctorID := make(map[string]*repository.DoctorSearchResult)
+	for rows.Next() {
+		var user domain.User
+		var role string
+		doctor, err := scanDoctor(rows,
+			&user.Email,
+			&user.FirstName,
+			&user.LastName,
+			&user.Phone,
+			&role,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		user.ID = doctor.UserID
+		user.Role = domain.UserRole(role)
+
+		result := &repository.DoctorSearchResult{User: &user, Doctor: doctor}
+		results = append(results, result)
+		byDoctorID[doctor.ID] = result
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	// Attach the names of the services each candidate offers
+	serviceRows, err := conn(ctx, r.pool).Query(ctx, `
+		SELECT ds.doctor_id, s.name
+		FROM doctor_services ds
+		JOIN services s ON s.id = ds.service_id
+		WHERE ds.is_active = TRUE AND s.is_active = TRUE AND s.deleted_at IS NULL
+		ORDER BY s.name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer serviceRows.Close()
+
+	for serviceRows.Next() {
+		var doctorID, name string
+		if err := serviceRows.Scan(&doctorID, &name); err != nil {
+			return nil, err
+		}
+		if result, ok := byDoctorID[doctorID]; ok {
+			result.Services = append(result.Services, name)
+		}
+	}
+
+	return results, serviceRows.Err()
+}
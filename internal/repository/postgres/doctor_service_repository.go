@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresDoctorServiceRepository implements the DoctorServiceRepository interface using PostgreSQL
+type PostgresDoctorServiceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresDoctorServiceRepository creates a new instance of PostgresDoctorServiceRepository
+func NewPostgresDoctorServiceRepository(pool *pgxpool.Pool) repository.DoctorServiceRepository {
+	return &PostgresDoctorServiceRepository{
+		pool: pool,
+	}
+}
+
+// Assign creates a relationship between a doctor and a service
+func (r *PostgresDoctorServiceRepository) Assign(ctx context.Context, doctorService *domain.DoctorService) error {
+	query := `
+		INSERT INTO doctor_services (id, doctor_id, service_id, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		doctorService.ID,
+		doctorService.DoctorID,
+		doctorService.ServiceID,
+		doctorService.IsActive,
+		doctorService.CreatedAt,
+		doctorService.UpdatedAt,
+	)
+
+	return mapError(err)
+}
+
+// AssignMany creates several doctor-service relationships in a single COPY round trip
+func (r *PostgresDoctorServiceRepository) AssignMany(ctx context.Context, doctorServices []*domain.DoctorService) error {
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"doctor_services"},
+		[]string{"id", "doctor_id", "service_id", "is_active", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(doctorServices), func(i int) ([]interface{}, error) {
+			ds := doctorServices[i]
+			id, err := toUUID(ds.ID)
+			if err != nil {
+				return nil, err
+			}
+			doctorID, err := toUUID(ds.DoctorID)
+			if err != nil {
+				return nil, err
+			}
+			serviceID, err := toUUID(ds.ServiceID)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{id, doctorID, serviceID, ds.IsActive, ds.CreatedAt, ds.UpdatedAt}, nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// Remove removes a service assignment from a doctor
+func (r *PostgresDoctorServiceRepository) Remove(ctx context.Context, doctorID, serviceID string) error {
+	query, args := scope(ctx, `DELETE FROM doctor_services WHERE doctor_id = $1 AND service_id = $2`, []interface{}{doctorID, serviceID}, serviceClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("doctor-service relationship not found")
+	}
+
+	return nil
+}
+
+// FindDoctorsByService returns all doctors that offer a specific service
+func (r *PostgresDoctorServiceRepository) FindDoctorsByService(ctx context.Context, serviceID string) ([]*domain.User, error) {
+	query := `
+		SELECT
+			u.id,
+			u.clinic_id,
+			u.email,
+			u.password_hash,
+			u.first_name,
+			u.last_name,
+			u.phone,
+			u.role,
+			u.is_active,
+			u.created_at,
+			u.updated_at,
+			u.version
+		FROM users u
+		INNER JOIN doctors d ON d.user_id = u.id
+		INNER JOIN doctor_services ds ON ds.doctor_id = d.id
+		WHERE ds.service_id = $1 AND ds.is_active = TRUE AND u.is_active = TRUE
+		AND u.deleted_at IS NULL AND d.deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{serviceID}, ownClinic("u."))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY u.first_name ASC, u.last_name ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// FindServicesByDoctor returns all services offered by a specific doctor
+func (r *PostgresDoctorServiceRepository) FindServicesByDoctor(ctx context.Context, doctorID string) ([]*domain.Service, error) {
+	query := `
+		SELECT
+			s.id,
+			s.clinic_id,
+			s.name,
+			COALESCE(s.description, ''),
+			s.duration_minutes,
+			s.price,
+			s.is_active,
+			s.created_at,
+			s.updated_at,
+			s.version
+		FROM services s
+		INNER JOIN doctor_services ds ON ds.service_id = s.id
+		WHERE ds.doctor_id = $1 AND ds.is_active = TRUE AND s.is_active = TRUE AND s.deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID}, ownClinic("s."))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY s.name ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*domain.Service
+	for rows.Next() {
+		service, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+
+	return services, rows.Err()
+}
+
+// IsAssigned checks if a doctor is assigned to a service
+func (r *PostgresDoctorServiceRepository) IsAssigned(ctx context.Context, doctorID, serviceID string) (bool, error) {
+	query := `
+		SELECT 1 FROM doctor_services
+		WHERE doctor_id = $1 AND service_id = $2 AND is_active = TRUE
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, serviceID}, serviceClinic(""))
+
+	var assigned bool
+	if err := conn(ctx, r.pool).QueryRow(ctx, "SELECT EXISTS ("+query+")", args...).Scan(&assigned); err != nil {
+		return false, err
+	}
+
+	return assigned, nil
+}
+
+// ListAll retrieves every doctor-service relationship, active or not, oldest first
+func (r *PostgresDoctorServiceRepository) ListAll(ctx context.Context) ([]*domain.DoctorService, error) {
+	query := `
+		SELECT id, doctor_id, service_id, is_active, created_at, updated_at
+		FROM doctor_services
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, serviceClinic(""))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctorServices []*domain.DoctorService
+	for rows.Next() {
+		var ds domain.DoctorService
+		if err := rows.Scan(&ds.ID, &ds.DoctorID, &ds.ServiceID, &ds.IsActive, &ds.CreatedAt, &ds.UpdatedAt); err != nil {
+			return nil, err
+		}
+		doctorServices = append(doctorServices, &ds)
+	}
+
+	return doctorServices, rows.Err()
+}
+
+// FindByDoctorAndService retrieves a specific doctor-service relationship
+func (r *PostgresDoctorServiceRepository) FindByDoctorAndService(ctx context.Context, doctorID, serviceID string) (*domain.DoctorService, error) {
+	query := `
+		SELECT id, doctor_id, service_id, is_active, created_at, updated_at
+		FROM doctor_services
+		WHERE doctor_id = $1 AND service_id = $2
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, serviceID}, serviceClinic(""))
+
+	var ds domain.DoctorService
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&ds.ID,
+		&ds.DoctorID,
+		&ds.ServiceID,
+		&ds.IsActive,
+		&ds.CreatedAt,
+		&ds.UpdatedAt,
+	)
+
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &ds, nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+)
+
+// PostgreSQL error codes handled by the repositories
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeUniqueViolation           = "23505"
+	codeForeignKeyViolation       = "23503"
+	codeExclusionViolation        = "23P01"
+	codeInvalidTextRepresentation = "22P02"
+)
+
+// uniqueConstraintErrors maps unique constraint names to domain errors
+var uniqueConstraintErrors = map[string]error{
+	"users_email_key":                          domain.ErrEmailAlreadyExists,
+	"doctors_license_number_key":               domain.ErrLicenseNumberAlreadyExists,
+	"doctor_services_doctor_id_service_id_key": domain.ErrServiceAlreadyAssigned,
+}
+
+// mapError translates PostgreSQL constraint violations into domain errors
+// Any other error is returned unchanged
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		if mapped, ok := uniqueConstraintErrors[pgErr.ConstraintName]; ok {
+			return mapped
+		}
+		return domain.ErrDuplicateRecord
+	case codeForeignKeyViolation:
+		// The same code is raised for a missing parent on insert
+		// and for a parent that is still referenced on delete
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			return domain.ErrRecordInUse
+		}
+		return domain.ErrRelatedRecordNotFound
+	case codeExclusionViolation:
+		if pgErr.ConstraintName == "appointments_no_overlap" {
+			return domain.ErrSlotTaken
+		}
+	}
+
+	return err
+}
+
+// isNotFound reports whether a single-row query matched nothing
+// A malformed UUID can never match a row, so it is treated the same way
+func isNotFound(err error) bool {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == codeInvalidTextRepresentation
+}
+
+// versionMismatch explains why an UPDATE guarded by "version = $n" changed no row:
+// it returns domain.ErrVersionConflict if the row named by id still exists, or
+// notFound otherwise. rowQuery selects the row by id as $1; clinic is the scope
+// condition of its table, so rows of other clinics count as missing
+func versionMismatch(ctx context.Context, pool *pgxpool.Pool, rowQuery, id, clinic string, notFound error) error {
+	query, args := scope(ctx, rowQuery, []interface{}{id}, clinic)
+
+	var exists bool
+	if err := conn(ctx, pool).QueryRow(ctx, "SELECT EXISTS("+query+")", args...).Scan(&exists); err != nil {
+		if isNotFound(err) {
+			return notFound
+		}
+		return err
+	}
+	if exists {
+		return domain.ErrVersionConflict
+	}
+	return notFound
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresExternalIdentityRepository implements the ExternalIdentityRepository interface using PostgreSQL
+type PostgresExternalIdentityRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresExternalIdentityRepository creates a new instance of PostgresExternalIdentityRepository
+func NewPostgresExternalIdentityRepository(pool *pgxpool.Pool) repository.ExternalIdentityRepository {
+	return &PostgresExternalIdentityRepository{
+		pool: pool,
+	}
+}
+
+// identityColumns lists the identity columns in the order scanIdentity reads them
+const identityColumns = `id, clinic_id, user_id, issuer, subject, email, created_at, last_login_at`
+
+// Create links a user to an identity of a provider
+// The user must belong to the clinic of the identity
+func (r *PostgresExternalIdentityRepository) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	query := `
+		INSERT INTO external_identities (id, clinic_id, user_id, issuer, subject, email, created_at, last_login_at)
+		SELECT $1, clinic_id, id, $2, $3, $4, $5, $6 FROM users WHERE id = $7 AND clinic_id = $8
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, identity.ClinicID)
+	if err != nil {
+		return err
+	}
+	identity.ClinicID = clinicID
+
+	result, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		identity.ID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+		identity.LastLoginAt,
+		identity.UserID,
+		identity.ClinicID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	return nil
+}
+
+// FindBySubject retrieves the identity a provider names with subject, or nil if there is none
+func (r *PostgresExternalIdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*domain.ExternalIdentity, error) {
+	query, args := scope(ctx, `SELECT `+identityColumns+` FROM external_identities WHERE issuer = $1 AND subject = $2`, []interface{}{issuer, subject}, ownClinic(""))
+
+	identity, err := scanIdentity(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// ListByUser retrieves the identities linked to a user, oldest first
+func (r *PostgresExternalIdentityRepository) ListByUser(ctx context.Context, userID string) ([]*domain.ExternalIdentity, error) {
+	query, args := scope(ctx, `SELECT `+identityColumns+` FROM external_identities WHERE user_id = $1`, []interface{}{userID}, ownClinic(""))
+	query += ` ORDER BY created_at, id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*domain.ExternalIdentity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// RecordLogin stores when an identity last signed in and the email the provider gave
+func (r *PostgresExternalIdentityRepository) RecordLogin(ctx context.Context, id, email string, loginAt time.Time) error {
+	query, args := scope(ctx, `UPDATE external_identities SET email = $1, last_login_at = $2 WHERE id = $3`, []interface{}{email, loginAt, id}, ownClinic(""))
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// Delete removes the link of an identity
+// Returns false if there is none
+func (r *PostgresExternalIdentityRepository) Delete(ctx context.Context, id string) (bool, error) {
+	query, args := scope(ctx, `DELETE FROM external_identities WHERE id = $1`, []interface{}{id}, ownClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// scanIdentity reads a row selected with identityColumns
+func scanIdentity(row rowScanner) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := row.Scan(
+		&identity.ID,
+		&identity.ClinicID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresInvitationRepository implements the InvitationRepository interface using PostgreSQL
+type PostgresInvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresInvitationRepository creates a new instance of PostgresInvitationRepository
+func NewPostgresInvitationRepository(pool *pgxpool.Pool) repository.InvitationRepository {
+	return &PostgresInvitationRepository{
+		pool: pool,
+	}
+}
+
+// invitationColumns lists the invitation columns in the order scanInvitation reads them
+const invitationColumns = `id, clinic_id, email, role, token_hash, invited_by, user_id, expires_at, created_at, accepted_at, revoked_at`
+
+// Create stores a new invitation
+func (r *PostgresInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	query := `
+		INSERT INTO invitations (id, clinic_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, invitation.ClinicID)
+	if err != nil {
+		return err
+	}
+	invitation.ClinicID = clinicID
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		invitation.ID,
+		invitation.ClinicID,
+		invitation.Email,
+		string(invitation.Role),
+		invitation.TokenHash,
+		nullIfEmpty(invitation.InvitedBy),
+		invitation.ExpiresAt,
+		invitation.CreatedAt,
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves an invitation by its unique identifier, or nil if there is none
+func (r *PostgresInvitationRepository) FindByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	query, args := scope(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE id = $1`, []interface{}{id}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByHash retrieves an invitation by the hash of its token, or nil if there is none
+func (r *PostgresInvitationRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	query, args := scope(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE token_hash = $1`, []interface{}{tokenHash}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// List retrieves every invitation, newest first
+func (r *PostgresInvitationRepository) List(ctx context.Context) ([]*domain.Invitation, error) {
+	query, args := scope(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE 1=1`, nil, ownClinic(""))
+	query += ` ORDER BY created_at DESC, id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*domain.Invitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// MarkAccepted records the account created from an invitation that is neither accepted nor revoked
+// Returns false if another request got there first
+func (r *PostgresInvitationRepository) MarkAccepted(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET accepted_at = $1, user_id = $2
+		WHERE id = $3 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{acceptedAt, userID, id}, ownClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// Revoke sets revoked_at on an invitation that is neither accepted nor revoked
+// Returns false if it was accepted or revoked first
+func (r *PostgresInvitationRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET revoked_at = $1
+		WHERE id = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt, id}, ownClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// RevokeByEmail revokes every open invitation sent to an email
+func (r *PostgresInvitationRepository) RevokeByEmail(ctx context.Context, email string, revokedAt time.Time) error {
+	query := `
+		UPDATE invitations
+		SET revoked_at = $1
+		WHERE email = $2 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt, email}, ownClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// findOne runs a query selecting invitationColumns and returns its single row, or nil if there is none
+func (r *PostgresInvitationRepository) findOne(ctx context.Context, query string, args []interface{}) (*domain.Invitation, error) {
+	invitation, err := scanInvitation(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// scanInvitation reads a row selected with invitationColumns
+func scanInvitation(row rowScanner) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	var role string
+	var invitedBy, userID *string
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.ClinicID,
+		&invitation.Email,
+		&role,
+		&invitation.TokenHash,
+		&invitedBy,
+		&userID,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+		&invitation.AcceptedAt,
+		&invitation.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.Role = domain.UserRole(role)
+	if invitedBy != nil {
+		invitation.InvitedBy = *invitedBy
+	}
+	if userID != nil {
+		invitation.UserID = *userID
+	}
+
+	return &invitation, nil
+}
@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresLoginLockoutRepository implements the LoginLockoutRepository interface using PostgreSQL
+type PostgresLoginLockoutRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresLoginLockoutRepository creates a new instance of PostgresLoginLockoutRepository
+func NewPostgresLoginLockoutRepository(pool *pgxpool.Pool) repository.LoginLockoutRepository {
+	return &PostgresLoginLockoutRepository{
+		pool: pool,
+	}
+}
+
+// lockoutColumns lists the login lockout columns in the order scanLockout reads them
+const lockoutColumns = `id, clinic_id, scope, subject, failures, last_failure_at, locked_until, unlock_token_hash, created_at`
+
+// ReserveAttempt counts a login attempt as failed on the counter of a scope and
+// subject, creating it with the given ID if there is none, provided the counter
+// still has the given failures. A counter whose last failure is older than
+// resetBefore starts over. Returns false if a concurrent attempt changed it first
+func (r *PostgresLoginLockoutRepository) ReserveAttempt(ctx context.Context, id, lockoutScope, subject string, failures int, attemptAt, resetBefore time.Time) (bool, error) {
+	query := `
+		INSERT INTO login_lockouts (id, clinic_id, scope, subject, failures, last_failure_at, created_at)
+		VALUES ($1, $2, $3, $4, 1, $5, $5)
+		ON CONFLICT (clinic_id, scope, subject) DO UPDATE SET
+			failures = CASE WHEN login_lockouts.last_failure_at < $6 THEN 1 ELSE login_lockouts.failures + 1 END,
+			locked_until = CASE WHEN login_lockouts.last_failure_at < $6 THEN NULL ELSE login_lockouts.locked_until END,
+			unlock_token_hash = CASE WHEN login_lockouts.last_failure_at < $6 THEN NULL ELSE login_lockouts.unlock_token_hash END,
+			last_failure_at = EXCLUDED.last_failure_at
+		WHERE login_lockouts.failures = $7
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id, clinicID, lockoutScope, subject, attemptAt, resetBefore, failures)
+	if err != nil {
+		return false, mapError(err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReleaseAttempt takes back one failure from the counter of a scope and subject
+func (r *PostgresLoginLockoutRepository) ReleaseAttempt(ctx context.Context, lockoutScope, subject string) error {
+	query := `UPDATE login_lockouts SET failures = failures - 1 WHERE scope = $1 AND subject = $2 AND failures > 0`
+	query, args := scope(ctx, query, []interface{}{lockoutScope, subject}, ownClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// Find retrieves the counter of a scope and subject, or nil if there is none
+func (r *PostgresLoginLockoutRepository) Find(ctx context.Context, lockoutScope, subject string) (*domain.LoginLockout, error) {
+	query := `SELECT ` + lockoutColumns + ` FROM login_lockouts WHERE scope = $1 AND subject = $2`
+	query, args := scope(ctx, query, []interface{}{lockoutScope, subject}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByID retrieves a counter by its unique identifier, or nil if there is none
+func (r *PostgresLoginLockoutRepository) FindByID(ctx context.Context, id string) (*domain.LoginLockout, error) {
+	query, args := scope(ctx, `SELECT `+lockoutColumns+` FROM login_lockouts WHERE id = $1`, []interface{}{id}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByUnlockHash retrieves a counter by the hash of its unlock token, or nil if there is none
+func (r *PostgresLoginLockoutRepository) FindByUnlockHash(ctx context.Context, tokenHash string) (*domain.LoginLockout, error) {
+	query, args := scope(ctx, `SELECT `+lockoutColumns+` FROM login_lockouts WHERE unlock_token_hash = $1`, []interface{}{tokenHash}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// Lock sets locked_until and the unlock token hash on a counter that is not locked at the given time
+// Returns false if another request locked it first
+func (r *PostgresLoginLockoutRepository) Lock(ctx context.Context, id string, lockedUntil time.Time, unlockTokenHash string, now time.Time) (bool, error) {
+	query := `
+		UPDATE login_lockouts
+		SET locked_until = $1, unlock_token_hash = $2
+		WHERE id = $3 AND (locked_until IS NULL OR locked_until <= $4)
+	`
+	query, args := scope(ctx, query, []interface{}{lockedUntil, nullIfEmpty(unlockTokenHash), id, now}, ownClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// ListLocked retrieves the counters still locked at the given time, most recent failure first
+func (r *PostgresLoginLockoutRepository) ListLocked(ctx context.Context, lockoutScope string, now time.Time) ([]*domain.LoginLockout, error) {
+	query := `SELECT ` + lockoutColumns + ` FROM login_lockouts WHERE locked_until > $1`
+	args := []interface{}{now}
+	if lockoutScope != "" {
+		args = append(args, lockoutScope)
+		query += fmt.Sprintf(` AND scope = $%d`, len(args))
+	}
+	query, args = scope(ctx, query, args, ownClinic(""))
+	query += ` ORDER BY last_failure_at DESC, id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lockouts []*domain.LoginLockout
+	for rows.Next() {
+		lockout, err := scanLockout(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, lockout)
+	}
+
+	return lockouts, rows.Err()
+}
+
+// Delete removes a counter, clearing its failures and lockout
+// Returns false if there was none
+func (r *PostgresLoginLockoutRepository) Delete(ctx context.Context, id string) (bool, error) {
+	query, args := scope(ctx, `DELETE FROM login_lockouts WHERE id = $1`, []interface{}{id}, ownClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// DeleteBySubject removes the counter of a scope and subject, if any
+func (r *PostgresLoginLockoutRepository) DeleteBySubject(ctx context.Context, lockoutScope, subject string) error {
+	query, args := scope(ctx, `DELETE FROM login_lockouts WHERE scope = $1 AND subject = $2`, []interface{}{lockoutScope, subject}, ownClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// PurgeStale permanently removes counters whose last failure is older than the
+// given time and that are not locked anymore
+func (r *PostgresLoginLockoutRepository) PurgeStale(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM login_lockouts
+		WHERE last_failure_at < $1 AND (locked_until IS NULL OR locked_until <= NOW())
+	`
+	query, args := scope(ctx, query, []interface{}{before}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// findOne runs a query selecting lockoutColumns and returns its single row, or nil if there is none
+func (r *PostgresLoginLockoutRepository) findOne(ctx context.Context, query string, args []interface{}) (*domain.LoginLockout, error) {
+	lockout, err := scanLockout(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lockout, nil
+}
+
+// scanLockout reads a row selected with lockoutColumns
+func scanLockout(row rowScanner) (*domain.LoginLockout, error) {
+	var lockout domain.LoginLockout
+	var unlockTokenHash *string
+
+	err := row.Scan(
+		&lockout.ID,
+		&lockout.ClinicID,
+		&lockout.Scope,
+		&lockout.Subject,
+		&lockout.Failures,
+		&lockout.LastFailureAt,
+		&lockout.LockedUntil,
+		&unlockTokenHash,
+		&lockout.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if unlockTokenHash != nil {
+		lockout.UnlockTokenHash = *unlockTokenHash
+	}
+
+	return &lockout, nil
+}
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresPasswordResetTokenRepository implements the PasswordResetTokenRepository interface using PostgreSQL
+type PostgresPasswordResetTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresPasswordResetTokenRepository creates a new instance of PostgresPasswordResetTokenRepository
+func NewPostgresPasswordResetTokenRepository(pool *pgxpool.Pool) repository.PasswordResetTokenRepository {
+	return &PostgresPasswordResetTokenRepository{
+		pool: pool,
+	}
+}
+
+// Create stores a new password reset token
+func (r *PostgresPasswordResetTokenRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return mapError(err)
+}
+
+// FindByHash retrieves a token by the hash of its value, or nil if there is none
+func (r *PostgresPasswordResetTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, created_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash = $1
+	`
+	query, args := scope(ctx, query, []interface{}{tokenHash}, userClinic(""))
+
+	var token domain.PasswordResetToken
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+	)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed sets used_at on a token that was not used yet
+// Returns false if another request used it first
+func (r *PostgresPasswordResetTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt, id}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// InvalidateUserTokens sets used_at on every unused token of a user
+func (r *PostgresPasswordResetTokenRepository) InvalidateUserTokens(ctx context.Context, userID string, usedAt time.Time) error {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = $1
+		WHERE user_id = $2 AND used_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt, userID}, userClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// PurgeExpired permanently removes tokens that expired before the given time
+func (r *PostgresPasswordResetTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args := scope(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < $1`, []interface{}{before}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,419 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/fieldcrypt"
+)
+
+// PostgresPatientRepository implements the PatientRepository interface using PostgreSQL
+// The document number, emergency contact, blood type and allergies are stored
+// encrypted, each allergy on its own; the document number also gets a blind index
+type PostgresPatientRepository struct {
+	pool   *pgxpool.Pool
+	cipher *fieldcrypt.Cipher
+}
+
+// NewPostgresPatientRepository creates a new instance of PostgresPatientRepository
+func NewPostgresPatientRepository(pool *pgxpool.Pool, cipher *fieldcrypt.Cipher) repository.PatientRepository {
+	return &PostgresPatientRepository{
+		pool:   pool,
+		cipher: cipher,
+	}
+}
+
+// patientColumns is the column list shared by every patient query
+const patientColumns = `id, user_id, birthdate, document_type, document_number,
+	address, emergency_contact_name, emergency_contact_phone,
+	COALESCE(blood_type, ''), COALESCE(allergies, '{}'), created_at, updated_at`
+
+// Create inserts a new patient into the database
+func (r *PostgresPatientRepository) Create(ctx context.Context, patient *domain.Patient) error {
+	query := `
+		INSERT INTO patients (id, user_id, birthdate, document_type, document_number, document_number_index,
+		                      address, emergency_contact_name, emergency_contact_phone,
+		                      blood_type, allergies, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		patient.ID,
+		patient.UserID,
+		patient.Birthdate,
+		patient.DocumentType,
+		sealed.documentNumber,
+		sealed.documentNumberIndex,
+		patient.Address,
+		sealed.emergencyContactName,
+		sealed.emergencyContactPhone,
+		sealed.bloodType,
+		sealed.allergies,
+		patient.CreatedAt,
+		patient.UpdatedAt,
+	)
+
+	return mapError(err)
+}
+
+// CreateMany inserts several patients in a single COPY round trip
+func (r *PostgresPatientRepository) CreateMany(ctx context.Context, patients []*domain.Patient) error {
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"patients"},
+		[]string{"id", "user_id", "birthdate", "document_type", "document_number", "document_number_index", "address", "emergency_contact_name", "emergency_contact_phone", "blood_type", "allergies", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(patients), func(i int) ([]interface{}, error) {
+			p := patients[i]
+			id, err := toUUID(p.ID)
+			if err != nil {
+				return nil, err
+			}
+			userID, err := toUUID(p.UserID)
+			if err != nil {
+				return nil, err
+			}
+			sealed, err := r.seal(p)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{id, userID, p.Birthdate, p.DocumentType, sealed.documentNumber, sealed.documentNumberIndex, p.Address, sealed.emergencyContactName, sealed.emergencyContactPhone, sealed.bloodType, sealed.allergies, p.CreatedAt, p.UpdatedAt}, nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves a patient by their unique identifier
+func (r *PostgresPatientRepository) FindByID(ctx context.Context, id string) (*domain.Patient, error) {
+	query := `SELECT ` + patientColumns + ` FROM patients WHERE id = $1`
+
+	return r.queryPatient(ctx, query, id)
+}
+
+// FindByUserID retrieves a patient by their associated user ID
+func (r *PostgresPatientRepository) FindByUserID(ctx context.Context, userID string) (*domain.Patient, error) {
+	query := `SELECT ` + patientColumns + ` FROM patients WHERE user_id = $1`
+
+	return r.queryPatient(ctx, query, userID)
+}
+
+// Update modifies an existing patient in the database
+func (r *PostgresPatientRepository) Update(ctx context.Context, patient *domain.Patient) error {
+	query := `
+		UPDATE patients
+		SET birthdate = $1,
+		    document_type = $2,
+		    document_number = $3,
+		    document_number_index = $4,
+		    address = $5,
+		    emergency_contact_name = $6,
+		    emergency_contact_phone = $7,
+		    blood_type = $8,
+		    allergies = $9,
+		    updated_at = $10
+		WHERE id = $11
+	`
+
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+
+	query, args := scope(ctx, query, []interface{}{
+		patient.Birthdate,
+		patient.DocumentType,
+		sealed.documentNumber,
+		sealed.documentNumberIndex,
+		patient.Address,
+		sealed.emergencyContactName,
+		sealed.emergencyContactPhone,
+		sealed.bloodType,
+		sealed.allergies,
+		patient.UpdatedAt,
+		patient.ID,
+	}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("patient not found")
+	}
+
+	return nil
+}
+
+// Delete removes a patient from the repository by their ID
+func (r *PostgresPatientRepository) Delete(ctx context.Context, id string) error {
+	query, args := scope(ctx, `DELETE FROM patients WHERE id = $1`, []interface{}{id}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("patient not found")
+	}
+
+	return nil
+}
+
+// List retrieves a paginated list of patients
+func (r *PostgresPatientRepository) List(ctx context.Context, limit, offset int) ([]*domain.Patient, error) {
+	query := `
+		SELECT ` + patientColumns + `
+		FROM patients
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, userClinic(""))
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanPatients(rows)
+}
+
+// FindByDocument retrieves the patients with a document number through its blind index
+// Rows not yet encrypted have no index and are compared in plaintext
+func (r *PostgresPatientRepository) FindByDocument(ctx context.Context, documentType, documentNumber string) ([]*domain.Patient, error) {
+	normalized := domain.NormalizeDocumentNumber(documentNumber)
+	if normalized == "" {
+		return []*domain.Patient{}, nil
+	}
+
+	query := `
+		SELECT ` + patientColumns + `
+		FROM patients
+		WHERE (document_number_index = $1 OR (document_number_index IS NULL AND document_number = $2))
+	`
+	args := []interface{}{r.cipher.BlindIndex(normalized), documentNumber}
+	if documentType != "" {
+		args = append(args, documentType)
+		query += fmt.Sprintf(" AND document_type = $%d", len(args))
+	}
+	query, args = scope(ctx, query, args, userClinic(""))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY created_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanPatients(rows)
+}
+
+// Reencrypt rewrites the encrypted fields of the patients whose values are not
+// sealed with the primary key, or whose blind index is missing or stale
+// Rows are read in batches by ID and each one is updated on its own, so an
+// interrupted run can simply be started again
+func (r *PostgresPatientRepository) Reencrypt(ctx context.Context) (int, error) {
+	type encryptedRow struct {
+		id                    string
+		documentNumber        string
+		documentNumberIndex   *string
+		emergencyContactName  string
+		emergencyContactPhone string
+		bloodType             string
+		allergies             []string
+	}
+
+	rewritten := 0
+	lastID := zeroUUID
+	for {
+		query, args := scope(ctx, `
+			SELECT id, document_number, document_number_index, emergency_contact_name,
+			       emergency_contact_phone, COALESCE(blood_type, ''), COALESCE(allergies, '{}')
+			FROM patients
+			WHERE id > $1
+		`, []interface{}{lastID}, userClinic(""))
+		query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args)+1)
+
+		rows, err := conn(ctx, r.pool).Query(ctx, query, append(args, reencryptBatch)...)
+		if err != nil {
+			return rewritten, err
+		}
+
+		var batch []encryptedRow
+		for rows.Next() {
+			var row encryptedRow
+			if err := rows.Scan(&row.id, &row.documentNumber, &row.documentNumberIndex, &row.emergencyContactName,
+				&row.emergencyContactPhone, &row.bloodType, &row.allergies); err != nil {
+				rows.Close()
+				return rewritten, err
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, row := range batch {
+			lastID = row.id
+
+			documentNumber, err := r.cipher.Decrypt(row.documentNumber)
+			if err != nil {
+				return rewritten, fmt.Errorf("patient %s: %w", row.id, err)
+			}
+			index := r.cipher.BlindIndex(domain.NormalizeDocumentNumber(documentNumber))
+
+			values, err := rewrap(r.cipher, row.documentNumber, row.emergencyContactName, row.emergencyContactPhone, row.bloodType)
+			if err != nil {
+				return rewritten, fmt.Errorf("patient %s: %w", row.id, err)
+			}
+			allergies, err := rewrap(r.cipher, row.allergies...)
+			if err != nil {
+				return rewritten, fmt.Errorf("patient %s: %w", row.id, err)
+			}
+			if values == nil && allergies == nil && row.documentNumberIndex != nil && *row.documentNumberIndex == index {
+				continue
+			}
+			if values == nil {
+				values = []string{row.documentNumber, row.emergencyContactName, row.emergencyContactPhone, row.bloodType}
+			}
+			if allergies == nil {
+				allergies = row.allergies
+			}
+
+			_, err = conn(ctx, r.pool).Exec(ctx, `
+				UPDATE patients
+				SET document_number = $1, document_number_index = $2, emergency_contact_name = $3,
+				    emergency_contact_phone = $4, blood_type = $5, allergies = $6
+				WHERE id = $7
+			`, values[0], index, values[1], values[2], values[3], allergiesArray(allergies), row.id)
+			if err != nil {
+				return rewritten, mapError(err)
+			}
+			rewritten++
+		}
+	}
+}
+
+// queryPatient runs a single-row patient query, returning nil if nothing matches
+// Patients of other clinics than the one ctx is scoped to never match
+func (r *PostgresPatientRepository) queryPatient(ctx context.Context, query string, args ...interface{}) (*domain.Patient, error) {
+	query, args = scope(ctx, query, args, userClinic(""))
+	patient, err := r.scanPatient(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return patient, nil
+}
+
+// scanPatients reads every row of a query selecting patientColumns
+func (r *PostgresPatientRepository) scanPatients(rows pgx.Rows) ([]*domain.Patient, error) {
+	patients := []*domain.Patient{}
+	for rows.Next() {
+		patient, err := r.scanPatient(rows)
+		if err != nil {
+			return nil, err
+		}
+		patients = append(patients, patient)
+	}
+
+	return patients, rows.Err()
+}
+
+// scanPatient reads a patient row selected with patientColumns and decrypts
+// the sensitive fields
+func (r *PostgresPatientRepository) scanPatient(row rowScanner) (*domain.Patient, error) {
+	var patient domain.Patient
+
+	err := row.Scan(
+		&patient.ID,
+		&patient.UserID,
+		&patient.Birthdate,
+		&patient.DocumentType,
+		&patient.DocumentNumber,
+		&patient.Address,
+		&patient.EmergencyContactName,
+		&patient.EmergencyContactPhone,
+		&patient.BloodType,
+		&patient.Allergies,
+		&patient.CreatedAt,
+		&patient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []*string{&patient.DocumentNumber, &patient.EmergencyContactName, &patient.EmergencyContactPhone, &patient.BloodType}
+	for i := range patient.Allergies {
+		fields = append(fields, &patient.Allergies[i])
+	}
+	for _, field := range fields {
+		if *field, err = r.cipher.Decrypt(*field); err != nil {
+			return nil, fmt.Errorf("patient %s: %w", patient.ID, err)
+		}
+	}
+
+	return &patient, nil
+}
+
+// sealedPatient holds the column values of the encrypted patient fields
+type sealedPatient struct {
+	documentNumber        string
+	documentNumberIndex   string
+	emergencyContactName  string
+	emergencyContactPhone string
+	bloodType             string
+	allergies             []string
+}
+
+// seal encrypts the sensitive fields of a patient for writing
+func (r *PostgresPatientRepository) seal(patient *domain.Patient) (*sealedPatient, error) {
+	values, err := encrypt(r.cipher, patient.DocumentNumber, patient.EmergencyContactName,
+		patient.EmergencyContactPhone, patient.BloodType)
+	if err != nil {
+		return nil, err
+	}
+	allergies, err := encrypt(r.cipher, patient.Allergies...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealedPatient{
+		documentNumber:        values[0],
+		documentNumberIndex:   r.cipher.BlindIndex(domain.NormalizeDocumentNumber(patient.DocumentNumber)),
+		emergencyContactName:  values[1],
+		emergencyContactPhone: values[2],
+		bloodType:             values[3],
+		allergies:             allergiesArray(allergies),
+	}, nil
+}
+
+// allergiesArray makes sure a nil slice is stored as an empty text[] instead of NULL
+func allergiesArray(allergies []string) []string {
+	if allergies == nil {
+		return []string{}
+	}
+	return allergies
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresRefreshTokenRepository implements the RefreshTokenRepository interface using PostgreSQL
+type PostgresRefreshTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRefreshTokenRepository creates a new instance of PostgresRefreshTokenRepository
+func NewPostgresRefreshTokenRepository(pool *pgxpool.Pool) repository.RefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{
+		pool: pool,
+	}
+}
+
+// Create stores a new refresh token
+func (r *PostgresRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.SessionID,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return mapError(err)
+}
+
+// FindByHash retrieves a token by the hash of its value, or nil if there is none
+func (r *PostgresRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, session_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+	query, args := scope(ctx, query, []interface{}{tokenHash}, userClinic(""))
+
+	var token domain.RefreshToken
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+	)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed sets used_at on a token that is neither used nor revoked
+// Returns false if another request used or revoked it first
+func (r *PostgresRefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt, id}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeSession revokes every token of a session
+func (r *PostgresRefreshTokenRepository) RevokeSession(ctx context.Context, sessionID string, revokedAt time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE session_id = $2 AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt, sessionID}, userClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// RevokeUserSessions revokes the tokens of every session of a user except keepSessionID
+// The session is compared as text so an empty keepSessionID keeps none
+func (r *PostgresRefreshTokenRepository) RevokeUserSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = $1
+		WHERE user_id = $2 AND session_id::text <> $3 AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt, userID, keepSessionID}, userClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// IsSessionRevoked reports whether a session was revoked
+// Sessions without any stored token, or whose ID is not a UUID, count as revoked
+func (r *PostgresRefreshTokenRepository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	query := `
+		SELECT COUNT(*), COUNT(revoked_at)
+		FROM refresh_tokens
+		WHERE session_id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{sessionID}, userClinic(""))
+
+	var tokens, revoked int
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&tokens, &revoked)
+	if isNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return tokens == 0 || revoked > 0, nil
+}
+
+// PurgeExpired permanently removes tokens that expired before the given time
+func (r *PostgresRefreshTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args := scope(ctx, `DELETE FROM refresh_tokens WHERE expires_at < $1`, []interface{}{before}, userClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
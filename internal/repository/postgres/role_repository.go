@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresRoleRepository implements the RoleRepository interface using PostgreSQL
+// Definitions belong to the clinic ctx is scoped to, or the default clinic
+type PostgresRoleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRoleRepository creates a new instance of PostgresRoleRepository
+func NewPostgresRoleRepository(pool *pgxpool.Pool) repository.RoleRepository {
+	return &PostgresRoleRepository{
+		pool: pool,
+	}
+}
+
+// FindByRole retrieves the stored definition of a role, or nil if there is none
+func (r *PostgresRoleRepository) FindByRole(ctx context.Context, role domain.UserRole) (*domain.RoleDefinition, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn(ctx, r.pool).QueryRow(
+		ctx,
+		`SELECT clinic_id, role, permissions, updated_at FROM roles WHERE clinic_id = $1 AND role = $2`,
+		clinicID,
+		string(role),
+	)
+	definition, err := scanRoleDefinition(row)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// List retrieves every stored definition of the clinic
+func (r *PostgresRoleRepository) List(ctx context.Context) ([]*domain.RoleDefinition, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.pool).Query(
+		ctx,
+		`SELECT clinic_id, role, permissions, updated_at FROM roles WHERE clinic_id = $1 ORDER BY role`,
+		clinicID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var definitions []*domain.RoleDefinition
+	for rows.Next() {
+		definition, err := scanRoleDefinition(rows)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+// Save creates or replaces the stored definition of a role
+func (r *PostgresRoleRepository) Save(ctx context.Context, definition *domain.RoleDefinition) error {
+	clinicID, err := repository.ClinicFor(ctx, definition.ClinicID)
+	if err != nil {
+		return err
+	}
+	definition.ClinicID = clinicID
+
+	query := `
+		INSERT INTO roles (clinic_id, role, permissions, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (clinic_id, role) DO UPDATE
+		SET permissions = excluded.permissions, updated_at = excluded.updated_at
+	`
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		definition.ClinicID,
+		string(definition.Role),
+		joinPermissions(definition.Permissions),
+		definition.UpdatedAt,
+	)
+
+	return mapError(err)
+}
+
+// Delete removes the stored definition of a role, so it goes back to its defaults
+// Returns false if there was none
+func (r *PostgresRoleRepository) Delete(ctx context.Context, role domain.UserRole) (bool, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	result, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM roles WHERE clinic_id = $1 AND role = $2`, clinicID, string(role))
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() > 0, nil
+}
+
+// scanRoleDefinition reads a roles row selected by FindByRole or List
+func scanRoleDefinition(row rowScanner) (*domain.RoleDefinition, error) {
+	var definition domain.RoleDefinition
+	var role, permissions string
+	if err := row.Scan(&definition.ClinicID, &role, &permissions, &definition.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	definition.Role = domain.UserRole(role)
+	definition.Permissions = splitPermissions(permissions)
+	return &definition, nil
+}
+
+// joinPermissions encodes a permission set as the space separated names stored in roles and api_keys
+func joinPermissions(permissions []domain.Permission) string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return strings.Join(names, " ")
+}
+
+// splitPermissions decodes the permissions column of roles and the scopes of api_keys
+func splitPermissions(column string) []domain.Permission {
+	permissions := []domain.Permission{}
+	for _, name := range strings.Fields(column) {
+		permissions = append(permissions, domain.Permission(name))
+	}
+	return permissions
+}
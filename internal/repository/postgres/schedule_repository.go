@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresScheduleRepository implements ScheduleRepository for PostgreSQL
+type PostgresScheduleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresScheduleRepository creates a new PostgreSQL schedule repository
+func NewPostgresScheduleRepository(pool *pgxpool.Pool) *PostgresScheduleRepository {
+	return &PostgresScheduleRepository{pool: pool}
+}
+
+// Create creates a new schedule
+func (r *PostgresScheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
+	query := `
+		INSERT INTO schedules (id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	if schedule.LocationID == "" {
+		schedule.LocationID = domain.DefaultLocationID
+	}
+
+	if schedule.Version == 0 {
+		schedule.Version = 1
+	}
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		schedule.ID,
+		schedule.DoctorID,
+		schedule.LocationID,
+		schedule.DayOfWeek,
+		schedule.StartTime,
+		schedule.EndTime,
+		schedule.SlotDuration,
+		schedule.IsActive,
+		schedule.CreatedAt,
+		schedule.UpdatedAt,
+		schedule.Version,
+	)
+
+	return mapError(err)
+}
+
+// CreateMany inserts several schedules in a single COPY round trip
+func (r *PostgresScheduleRepository) CreateMany(ctx context.Context, schedules []*domain.Schedule) error {
+	for _, s := range schedules {
+		if s.LocationID == "" {
+			s.LocationID = domain.DefaultLocationID
+		}
+	}
+
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"schedules"},
+		[]string{"id", "doctor_id", "location_id", "day_of_week", "start_time", "end_time", "slot_duration", "is_active", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(schedules), func(i int) ([]interface{}, error) {
+			s := schedules[i]
+			id, err := toUUID(s.ID)
+			if err != nil {
+				return nil, err
+			}
+			doctorID, err := toUUID(s.DoctorID)
+			if err != nil {
+				return nil, err
+			}
+			locationID, err := toUUID(s.LocationID)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{id, doctorID, locationID, s.DayOfWeek, s.StartTime, s.EndTime, s.SlotDuration, s.IsActive, s.CreatedAt, s.UpdatedAt}, nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// FindByID finds a schedule by ID
+func (r *PostgresScheduleRepository) FindByID(ctx context.Context, id string) (*domain.Schedule, error) {
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{id}, locationClinic(""))
+
+	schedules, err := r.querySchedules(ctx, query, args...)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if len(schedules) == 0 {
+		return nil, nil
+	}
+
+	return schedules[0], nil
+}
+
+// FindByDoctorAndDay finds schedules for a doctor on a specific day
+func (r *PostgresScheduleRepository) FindByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) ([]*domain.Schedule, error) {
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE doctor_id = $1 AND day_of_week = $2 AND is_active = TRUE
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, dayOfWeek}, locationClinic(""))
+
+	return r.querySchedules(ctx, query+" ORDER BY start_time ASC", args...)
+}
+
+// FindByDoctorsAndDay finds the active schedules of several doctors on a specific day in one query
+func (r *PostgresScheduleRepository) FindByDoctorsAndDay(ctx context.Context, doctorIDs []string, dayOfWeek string) ([]*domain.Schedule, error) {
+	if len(doctorIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE doctor_id = ANY($1) AND day_of_week = $2 AND is_active = TRUE
+	`
+	query, args := scope(ctx, query, []interface{}{doctorIDs, dayOfWeek}, locationClinic(""))
+
+	return r.querySchedules(ctx, query+" ORDER BY start_time ASC", args...)
+}
+
+// FindByDoctor finds one page of active schedules for a doctor
+func (r *PostgresScheduleRepository) FindByDoctor(ctx context.Context, doctorID string, page repository.PageRequest) ([]*domain.Schedule, error) {
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE doctor_id = $1 AND is_active = TRUE
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID}, locationClinic(""))
+
+	query, args, err := keysetPage(query, args, page, scheduleSortExpr(page.Sort), "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.querySchedules(ctx, query, args...)
+}
+
+// Update updates a schedule if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *PostgresScheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
+	query := `
+		UPDATE schedules
+		SET doctor_id = $1, location_id = $2, day_of_week = $3, start_time = $4, end_time = $5,
+		    slot_duration = $6, is_active = $7, updated_at = $8, version = version + 1
+		WHERE id = $9 AND version = $10
+	`
+	query, args := scope(ctx, query, []interface{}{
+		schedule.DoctorID,
+		schedule.LocationID,
+		schedule.DayOfWeek,
+		schedule.StartTime,
+		schedule.EndTime,
+		schedule.SlotDuration,
+		schedule.IsActive,
+		schedule.UpdatedAt,
+		schedule.ID,
+		schedule.Version,
+	}, locationClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil {
+		return errors.New("schedule not found")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return versionMismatch(ctx, r.pool, `SELECT 1 FROM schedules WHERE id = $1`, schedule.ID, locationClinic(""), errors.New("schedule not found"))
+	}
+
+	schedule.Version++
+	return nil
+}
+
+// Delete deletes a schedule
+func (r *PostgresScheduleRepository) Delete(ctx context.Context, id string) error {
+	query, args := scope(ctx, `DELETE FROM schedules WHERE id = $1`, []interface{}{id}, locationClinic(""))
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// DeleteByDoctorAndDay deletes all schedules for a doctor on a specific day
+func (r *PostgresScheduleRepository) DeleteByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) error {
+	query, args := scope(ctx, `DELETE FROM schedules WHERE doctor_id = $1 AND day_of_week = $2`, []interface{}{doctorID, dayOfWeek}, locationClinic(""))
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// ListAll retrieves every schedule, active or not, oldest first
+func (r *PostgresScheduleRepository) ListAll(ctx context.Context) ([]*domain.Schedule, error) {
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, locationClinic(""))
+
+	return r.querySchedules(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+}
+
+// querySchedules is a helper method to query schedules
+func (r *PostgresScheduleRepository) querySchedules(ctx context.Context, query string, args ...interface{}) ([]*domain.Schedule, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*domain.Schedule
+
+	for rows.Next() {
+		var schedule domain.Schedule
+
+		err := rows.Scan(
+			&schedule.ID,
+			&schedule.DoctorID,
+			&schedule.LocationID,
+			&schedule.DayOfWeek,
+			&schedule.StartTime,
+			&schedule.EndTime,
+			&schedule.SlotDuration,
+			&schedule.IsActive,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+			&schedule.Version,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, &schedule)
+	}
+
+	return schedules, rows.Err()
+}
@@ -0,0 +1,314 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresServiceRepository implements the ServiceRepository interface using PostgreSQL
+type PostgresServiceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresServiceRepository creates a new instance of PostgresServiceRepository
+func NewPostgresServiceRepository(pool *pgxpool.Pool) repository.ServiceRepository {
+	return &PostgresServiceRepository{
+		pool: pool,
+	}
+}
+
+// serviceColumns is the column list shared by every service query
+const serviceColumns = `id, clinic_id, name, COALESCE(description, ''), duration_minutes, price, is_active, created_at, updated_at, version`
+
+// Create inserts a new service into the database
+func (r *PostgresServiceRepository) Create(ctx context.Context, service *domain.Service) error {
+	query := `
+		INSERT INTO services (id, clinic_id, name, description, duration_minutes, price, is_active, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, service.ClinicID)
+	if err != nil {
+		return err
+	}
+	service.ClinicID = clinicID
+
+	if service.Version == 0 {
+		service.Version = 1
+	}
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		service.ID,
+		service.ClinicID,
+		service.Name,
+		service.Description,
+		service.DurationMinutes,
+		service.Price,
+		service.IsActive,
+		service.CreatedAt,
+		service.UpdatedAt,
+		service.Version,
+	)
+
+	return mapError(err)
+}
+
+// CreateMany inserts several services in a single COPY round trip
+func (r *PostgresServiceRepository) CreateMany(ctx context.Context, services []*domain.Service) error {
+	for _, s := range services {
+		clinicID, err := repository.ClinicFor(ctx, s.ClinicID)
+		if err != nil {
+			return err
+		}
+		s.ClinicID = clinicID
+	}
+
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"services"},
+		[]string{"id", "clinic_id", "name", "description", "duration_minutes", "price", "is_active", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(services), func(i int) ([]interface{}, error) {
+			s := services[i]
+			id, err := toUUID(s.ID)
+			if err != nil {
+				return nil, err
+			}
+			clinicID, err := toUUID(s.ClinicID)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{id, clinicID, s.Name, s.Description, s.DurationMinutes, s.Price, s.IsActive, s.CreatedAt, s.UpdatedAt}, nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves a service by its unique identifier
+func (r *PostgresServiceRepository) FindByID(ctx context.Context, id string) (*domain.Service, error) {
+	query, args := scope(ctx, `SELECT `+serviceColumns+` FROM services WHERE id = $1 AND deleted_at IS NULL`, []interface{}{id}, ownClinic(""))
+
+	service, err := scanService(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return service, nil
+}
+
+// ListActive retrieves one page of active services
+func (r *PostgresServiceRepository) ListActive(ctx context.Context, page repository.PageRequest) ([]*domain.Service, error) {
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services
+		WHERE is_active = TRUE AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	query, args, err := keysetPage(query, args, page, serviceSortExpr(page.Sort, ""), "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryServices(ctx, query, args...)
+}
+
+// ListAll retrieves all services (active and inactive)
+func (r *PostgresServiceRepository) ListAll(ctx context.Context) ([]*domain.Service, error) {
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services
+		WHERE deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	return r.queryServices(ctx, query+" ORDER BY name ASC", args...)
+}
+
+// Update modifies an existing service if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *PostgresServiceRepository) Update(ctx context.Context, service *domain.Service) error {
+	query := `
+		UPDATE services
+		SET name = $1, description = $2, duration_minutes = $3, price = $4, is_active = $5, updated_at = $6, version = version + 1
+		WHERE id = $7 AND version = $8 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{
+		service.Name,
+		service.Description,
+		service.DurationMinutes,
+		service.Price,
+		service.IsActive,
+		service.UpdatedAt,
+		service.ID,
+		service.Version,
+	}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil {
+		return errors.New("service not found")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return versionMismatch(ctx, r.pool, `SELECT 1 FROM services WHERE id = $1 AND deleted_at IS NULL`, service.ID, ownClinic(""), errors.New("service not found"))
+	}
+
+	service.Version++
+	return nil
+}
+
+// Delete performs a soft delete by setting deleted_at
+func (r *PostgresServiceRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE services
+		SET deleted_at = $1,
+		    updated_at = $1,
+		    version = version + 1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), id}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("service not found")
+	}
+
+	return nil
+}
+
+// ListDeleted retrieves soft deleted services, most recently deleted first
+func (r *PostgresServiceRepository) ListDeleted(ctx context.Context) ([]*domain.Service, error) {
+	query := `
+		SELECT ` + serviceColumns + `, deleted_at
+		FROM services
+		WHERE deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY deleted_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*domain.Service
+	for rows.Next() {
+		var deletedAt time.Time
+		service, err := scanService(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		service.DeletedAt = &deletedAt
+		services = append(services, service)
+	}
+
+	return services, rows.Err()
+}
+
+// Restore clears the deletion mark of a soft deleted service
+func (r *PostgresServiceRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE services
+		SET deleted_at = NULL,
+		    updated_at = $1,
+		    version = version + 1
+		WHERE id = $2 AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), id}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("service not found")
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes services deleted before the given time
+// Services still referenced by appointments are kept
+func (r *PostgresServiceRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM services s
+		WHERE s.deleted_at < $1
+		AND NOT EXISTS (SELECT 1 FROM appointments a WHERE a.service_id = s.id)
+	`
+	query, args := scope(ctx, query, []interface{}{before}, ownClinic("s."))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// queryServices is a helper method to query services
+func (r *PostgresServiceRepository) queryServices(ctx context.Context, query string, args ...interface{}) ([]*domain.Service, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var services []*domain.Service
+	for rows.Next() {
+		service, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+
+	return services, rows.Err()
+}
+
+// scanService reads a service row selected with serviceColumns
+// Extra destinations are scanned from the columns that follow
+func scanService(row rowScanner, extra ...interface{}) (*domain.Service, error) {
+	var service domain.Service
+
+	dest := []interface{}{
+		&service.ID,
+		&service.ClinicID,
+		&service.Name,
+		&service.Description,
+		&service.DurationMinutes,
+		&service.Price,
+		&service.IsActive,
+		&service.CreatedAt,
+		&service.UpdatedAt,
+		&service.Version,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &service, nil
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresSessionRepository implements the SessionRepository interface using PostgreSQL
+type PostgresSessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresSessionRepository creates a new instance of PostgresSessionRepository
+func NewPostgresSessionRepository(pool *pgxpool.Pool) repository.SessionRepository {
+	return &PostgresSessionRepository{
+		pool: pool,
+	}
+}
+
+// Create stores a new session
+func (r *PostgresSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt,
+		session.LastSeenAt,
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves a session by its ID, or nil if there is none
+func (r *PostgresSessionRepository) FindByID(ctx context.Context, id string) (*domain.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at
+		FROM sessions
+		WHERE id = $1
+	`
+	query, args := scope(ctx, query, []interface{}{id}, userClinic(""))
+
+	var session domain.Session
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListActiveByUser retrieves the sessions of a user that have a current refresh token
+// Each active session has exactly one: the others of its chain were used
+func (r *PostgresSessionRepository) ListActiveByUser(ctx context.Context, userID string, now time.Time) ([]*domain.Session, error) {
+	query := `
+		SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.created_at, s.last_seen_at, t.expires_at
+		FROM sessions s
+		JOIN refresh_tokens t ON t.session_id = s.id
+		WHERE s.user_id = $1 AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > $2
+	`
+	query, args := scope(ctx, query, []interface{}{userID, now}, userClinic("s."))
+	query += ` ORDER BY s.last_seen_at DESC, s.id`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		var session domain.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch sets last_seen_at of a session; an earlier time leaves it unchanged
+func (r *PostgresSessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = $1
+		WHERE id = $2 AND last_seen_at < $1
+	`
+	query, args := scope(ctx, query, []interface{}{seenAt, id}, userClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// PurgeEnded permanently removes sessions last seen before the given time
+// that have no refresh token left
+func (r *PostgresSessionRepository) PurgeEnded(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE last_seen_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.session_id = sessions.id)
+	`
+	query, args := scope(ctx, query, []interface{}{before}, userClinic(""))
+
+	result, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(result.RowsAffected()), nil
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/repository"
+)
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// PostgresTxManager implements the TxManager interface using PostgreSQL transactions
+// Concurrent bookings are serialized with row locks taken by the repositories
+// (see LockDoctor and FindByIDForUpdate), so the default READ COMMITTED level is enough
+type PostgresTxManager struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresTxManager creates a new instance of PostgresTxManager
+func NewPostgresTxManager(pool *pgxpool.Pool) repository.TxManager {
+	return &PostgresTxManager{
+		pool: pool,
+	}
+}
+
+// WithinTx runs fn inside a transaction, committing if it returns nil
+func (m *PostgresTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	return pgx.BeginFunc(ctx, m.pool, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction carried by ctx, or the pool when there is none
+func conn(ctx context.Context, pool *pgxpool.Pool) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx so the same
+// statement helpers can run inside or outside a transaction
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// rowScanner is satisfied by both pgx.Row and pgx.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// toUUID converts a string identifier for use with CopyFrom, which encodes
+// values in binary and cannot send plain strings to uuid columns
+// An empty string becomes NULL
+func toUUID(id string) (pgtype.UUID, error) {
+	var u pgtype.UUID
+	if id == "" {
+		return u, nil
+	}
+	err := u.Scan(id)
+	return u, err
+}
+
+// nullIfEmpty returns nil for empty strings so optional columns are stored as NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
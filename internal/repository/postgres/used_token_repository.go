@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/repository"
+)
+
+// PostgresUsedTokenRepository implements the UsedTokenRepository interface using PostgreSQL
+type PostgresUsedTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUsedTokenRepository creates a new instance of PostgresUsedTokenRepository
+func NewPostgresUsedTokenRepository(pool *pgxpool.Pool) repository.UsedTokenRepository {
+	return &PostgresUsedTokenRepository{
+		pool: pool,
+	}
+}
+
+// Use records that the token with the given ID was used
+// Returns false if it was used before
+func (r *PostgresUsedTokenRepository) Use(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO used_tokens (id, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, id, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// PurgeExpired permanently removes the IDs of tokens that expired before the given time
+func (r *PostgresUsedTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	tag, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM used_tokens WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
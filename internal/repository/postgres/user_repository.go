@@ -0,0 +1,412 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// PostgresUserRepository implements the UserRepository interface using PostgreSQL
+type PostgresUserRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresUserRepository creates a new instance of PostgresUserRepository
+func NewPostgresUserRepository(pool *pgxpool.Pool) repository.UserRepository {
+	return &PostgresUserRepository{
+		pool: pool,
+	}
+}
+
+// userColumns is the column list shared by every user query
+const userColumns = `id, clinic_id, email, password_hash, first_name, last_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version`
+
+// Create inserts a new user into the database
+func (r *PostgresUserRepository) Create(ctx context.Context, user *domain.User) error {
+	query := `
+		INSERT INTO users (id, clinic_id, email, password_hash, first_name, last_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, user.ClinicID)
+	if err != nil {
+		return err
+	}
+	user.ClinicID = clinicID
+
+	if user.Version == 0 {
+		user.Version = 1
+	}
+
+	_, err = conn(ctx, r.pool).Exec(
+		ctx,
+		query,
+		user.ID,
+		user.ClinicID,
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.Phone,
+		string(user.Role),
+		user.IsActive,
+		user.EmailVerifiedAt,
+		user.VerificationSentAt,
+		user.CreatedAt,
+		user.UpdatedAt,
+		user.Version,
+	)
+
+	return mapError(err)
+}
+
+// CreateMany inserts several users in a single COPY round trip
+func (r *PostgresUserRepository) CreateMany(ctx context.Context, users []*domain.User) error {
+	for _, u := range users {
+		clinicID, err := repository.ClinicFor(ctx, u.ClinicID)
+		if err != nil {
+			return err
+		}
+		u.ClinicID = clinicID
+	}
+
+	_, err := conn(ctx, r.pool).CopyFrom(
+		ctx,
+		pgx.Identifier{"users"},
+		[]string{"id", "clinic_id", "email", "password_hash", "first_name", "last_name", "phone", "role", "is_active", "email_verified_at", "created_at", "updated_at"},
+		pgx.CopyFromSlice(len(users), func(i int) ([]interface{}, error) {
+			u := users[i]
+			id, err := toUUID(u.ID)
+			if err != nil {
+				return nil, err
+			}
+			clinicID, err := toUUID(u.ClinicID)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{id, clinicID, u.Email, u.PasswordHash, u.FirstName, u.LastName, u.Phone, string(u.Role), u.IsActive, u.EmailVerifiedAt, u.CreatedAt, u.UpdatedAt}, nil
+		}),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves a user by their unique identifier
+// Returns nil if the user is not found
+func (r *PostgresUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
+	query, args := scope(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1 AND deleted_at IS NULL`, []interface{}{id}, ownClinic(""))
+
+	return r.queryUser(ctx, query, args...)
+}
+
+// FindByEmail retrieves a user by their email address
+// Returns nil if the user is not found
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	query, args := scope(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1 AND deleted_at IS NULL`, []interface{}{email}, ownClinic(""))
+
+	return r.queryUser(ctx, query, args...)
+}
+
+// EmailExists reports whether any user holds the email, ignoring the clinic
+// scope and deleted_at like the users_email_key constraint
+func (r *PostgresUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.pool).QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`, email).Scan(&exists)
+	return exists, err
+}
+
+// Update modifies an existing user if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *PostgresUserRepository) Update(ctx context.Context, user *domain.User) error {
+	query := `
+		UPDATE users
+		SET email = $1, password_hash = $2, first_name = $3, last_name = $4, phone = $5, role = $6, is_active = $7, email_verified_at = $8, updated_at = $9, version = version + 1
+		WHERE id = $10 AND version = $11 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		user.Phone,
+		string(user.Role),
+		user.IsActive,
+		user.EmailVerifiedAt,
+		user.UpdatedAt,
+		user.ID,
+		user.Version,
+	}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return versionMismatch(ctx, r.pool, `SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL`, user.ID, ownClinic(""), errors.New("user not found"))
+	}
+
+	user.Version++
+	return nil
+}
+
+// MarkVerificationSent records when a verification email was last sent
+func (r *PostgresUserRepository) MarkVerificationSent(ctx context.Context, id string, sentAt time.Time) error {
+	query, args := scope(ctx, `UPDATE users SET verification_sent_at = $1 WHERE id = $2 AND deleted_at IS NULL`, []interface{}{sentAt, id}, ownClinic(""))
+
+	_, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	return err
+}
+
+// Delete performs a soft delete by setting deleted_at
+func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = $1,
+		    updated_at = $1,
+		    version = version + 1
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), id}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// List retrieves a paginated list of users
+func (r *PostgresUserRepository) List(ctx context.Context, page repository.PageRequest) ([]*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	query, args, err := keysetPage(query, args, page, userSortExpr(page.Sort, ""), "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryUsers(ctx, query, args...)
+}
+
+// ListDeleted retrieves soft deleted users, most recently deleted first
+func (r *PostgresUserRepository) ListDeleted(ctx context.Context) ([]*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `, deleted_at
+		FROM users
+		WHERE deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query+" ORDER BY deleted_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		var deletedAt time.Time
+		user, err := scanUser(rows, &deletedAt)
+		if err != nil {
+			return nil, err
+		}
+		user.DeletedAt = &deletedAt
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// Restore clears the deletion mark of a soft deleted user
+func (r *PostgresUserRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL,
+		    updated_at = $1,
+		    version = version + 1
+		WHERE id = $2 AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now(), id}, ownClinic(""))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil && !isNotFound(err) {
+		return mapError(err)
+	}
+
+	if err != nil || tag.RowsAffected() == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes users deleted before the given time
+// Their patient or doctor profile goes with them; users that still appear in
+// appointments are kept so clinical history is never lost
+func (r *PostgresUserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM users u
+		WHERE u.deleted_at < $1
+		AND NOT EXISTS (
+			SELECT 1 FROM appointments a
+			INNER JOIN patients p ON p.id = a.patient_id
+			WHERE p.user_id = u.id
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM appointments a
+			INNER JOIN doctors d ON d.id = a.doctor_id
+			WHERE d.user_id = u.id
+		)
+	`
+	query, args := scope(ctx, query, []interface{}{before}, ownClinic("u."))
+
+	tag, err := conn(ctx, r.pool).Exec(ctx, query, args...)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// FindDoctorIDByUserID returns the doctor.id for a given user_id
+func (r *PostgresUserRepository) FindDoctorIDByUserID(ctx context.Context, userID string) (string, error) {
+	query, args := scope(ctx, `SELECT id FROM doctors WHERE user_id = $1 AND deleted_at IS NULL`, []interface{}{userID}, userClinic(""))
+
+	var doctorID string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&doctorID)
+	if err != nil {
+		if isNotFound(err) {
+			return "", errors.New("doctor not found")
+		}
+		return "", err
+	}
+
+	return doctorID, nil
+}
+
+// FindPatientIDByUserID returns the patient.id for a given user_id
+func (r *PostgresUserRepository) FindPatientIDByUserID(ctx context.Context, userID string) (string, error) {
+	query, args := scope(ctx, `SELECT id FROM patients WHERE user_id = $1`, []interface{}{userID}, userClinic(""))
+
+	var patientID string
+	err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&patientID)
+	if err != nil {
+		if isNotFound(err) {
+			return "", errors.New("patient not found")
+		}
+		return "", err
+	}
+
+	return patientID, nil
+}
+
+// CountByRole counts users by role
+func (r *PostgresUserRepository) CountByRole(ctx context.Context, role string) (int, error) {
+	query, args := scope(ctx, `SELECT COUNT(*) FROM users WHERE role = $1 AND is_active = TRUE AND deleted_at IS NULL`, []interface{}{role}, ownClinic(""))
+
+	var count int
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountAllActive counts all active users
+func (r *PostgresUserRepository) CountAllActive(ctx context.Context) (int, error) {
+	query, args := scope(ctx, `SELECT COUNT(*) FROM users WHERE is_active = TRUE AND deleted_at IS NULL`, nil, ownClinic(""))
+
+	var count int
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// queryUser runs a single-row user query, returning nil if nothing matches
+func (r *PostgresUserRepository) queryUser(ctx context.Context, query string, args ...interface{}) (*domain.User, error) {
+	user, err := scanUser(conn(ctx, r.pool).QueryRow(ctx, query, args...))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// queryUsers is a helper method to query a list of users
+func (r *PostgresUserRepository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]*domain.User, error) {
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// scanUser reads a user row selected with userColumns
+// Extra destinations are scanned from the columns that follow
+func scanUser(row rowScanner, extra ...interface{}) (*domain.User, error) {
+	var user domain.User
+	var role string
+
+	dest := []interface{}{
+		&user.ID,
+		&user.ClinicID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.FirstName,
+		&user.LastName,
+		&user.Phone,
+		&role,
+		&user.IsActive,
+		&user.EmailVerifiedAt,
+		&user.VerificationSentAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Version,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = domain.UserRole(role)
+
+	return &user, nil
+}
@@ -9,17 +9,21 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/pkg/fieldcrypt"
 )
 
 // SqliteAppointmentRepository implements the AppointmentRepository interface using SQLite
+// Appointment notes are stored encrypted
 type SqliteAppointmentRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *fieldcrypt.Cipher
 }
 
 // NewSqliteAppointmentRepository creates a new instance of SqliteAppointmentRepository
-func NewSqliteAppointmentRepository(db *sql.DB) repository.AppointmentRepository {
+func NewSqliteAppointmentRepository(db *sql.DB, cipher *fieldcrypt.Cipher) repository.AppointmentRepository {
 	return &SqliteAppointmentRepository{
-		db: db,
+		db:     db,
+		cipher: cipher,
 	}
 }
 
@@ -27,49 +31,66 @@ func NewSqliteAppointmentRepository(db *sql.DB) repository.AppointmentRepository
 func (r *SqliteAppointmentRepository) Create(ctx context.Context, appointment *domain.Appointment) error {
 	query := `
 		INSERT INTO appointments (
-			id, patient_id, doctor_id, scheduled_at, duration,
+			id, patient_id, doctor_id, location_id, scheduled_at, duration,
 			reason, notes, status, created_at, updated_at,
-			reminder_24h_sent, reminder_1h_sent, service_id
+			reminder_24h_sent, reminder_1h_sent, service_id, version
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	if appointment.LocationID == "" {
+		appointment.LocationID = domain.DefaultLocationID
+	}
+
+	if appointment.Version == 0 {
+		appointment.Version = 1
+	}
+
+	notes, err := r.cipher.Encrypt(appointment.Notes)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		appointment.ID,
 		appointment.PatientID,
 		appointment.DoctorID,
-		appointment.ScheduledAt,
+		appointment.LocationID,
+		appointment.ScheduledAt.UTC(),
 		appointment.Duration,
 		appointment.Reason,
-		appointment.Notes,
+		notes,
 		appointment.Status,
-		appointment.CreatedAt,
-		appointment.UpdatedAt,
+		appointment.CreatedAt.UTC(),
+		appointment.UpdatedAt.UTC(),
 		appointment.Reminder24hSent,
 		appointment.Reminder1hSent,
 		appointment.ServiceID,
+		appointment.Version,
 	)
 
-	return err
+	return mapError(err)
 }
 
 // FindByID retrieves an appointment by its unique identifier
 func (r *SqliteAppointmentRepository) FindByID(ctx context.Context, id string) (*domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent
+		SELECT id, patient_id, doctor_id, location_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent, version
 		FROM appointments
-		WHERE id = $1
+		WHERE id = ?
 	`
+	query, args := scope(ctx, query, []interface{}{id}, locationClinic(""))
 
 	var appointment domain.Appointment
 	var scheduledAt, createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&appointment.ID,
 		&appointment.PatientID,
 		&appointment.DoctorID,
+		&appointment.LocationID,
 		&scheduledAt,
 		&appointment.Duration,
 		&appointment.Status,
@@ -79,6 +100,7 @@ func (r *SqliteAppointmentRepository) FindByID(ctx context.Context, id string) (
 		&updatedAt,
 		&appointment.Reminder24hSent,
 		&appointment.Reminder1hSent,
+		&appointment.Version,
 	)
 
 	if err != nil {
@@ -88,6 +110,10 @@ func (r *SqliteAppointmentRepository) FindByID(ctx context.Context, id string) (
 		return nil, err
 	}
 
+	if err := r.decryptNotes(&appointment); err != nil {
+		return nil, err
+	}
+
 	// Assign scanned values
 	appointment.ScheduledAt = scheduledAt
 	appointment.CreatedAt = createdAt
@@ -96,43 +122,27 @@ func (r *SqliteAppointmentRepository) FindByID(ctx context.Context, id string) (
 	return &appointment, nil
 }
 
-// FindByPatientID retrieves all appointments for a specific patient
-func (r *SqliteAppointmentRepository) FindByPatientID(ctx context.Context, patientID string) ([]*domain.Appointment, error) {
-	query := `
-		SELECT
-			a.id,
-			a.patient_id,
-			a.doctor_id,
-			a.scheduled_at,
-			a.duration,
-			a.status,
-			a.reason,
-			a.notes,
-			a.created_at,
-			a.updated_at,
-			a.reminder_24h_sent,
-			a.reminder_1h_sent,
-			(pu.first_name || ' ' || pu.last_name) as patient_name,
-			(du.first_name || ' ' || du.last_name) as doctor_name
-		FROM appointments a
-		JOIN patients pt ON pt.id = a.patient_id
-		JOIN users pu ON pu.id = pt.user_id
-		JOIN doctors doc ON doc.id = a.doctor_id
-		JOIN users du ON du.id = doc.user_id
-		WHERE a.patient_id = $1
-		ORDER BY a.scheduled_at DESC
-	`
+// FindByIDForUpdate retrieves an appointment inside the current unit of work
+// SQLite has no row locks; the BEGIN IMMEDIATE transaction already holds the
+// database write lock, so a plain read is enough
+func (r *SqliteAppointmentRepository) FindByIDForUpdate(ctx context.Context, id string) (*domain.Appointment, error) {
+	return r.FindByID(ctx, id)
+}
 
-	return r.queryAppointmentsWithNames(ctx, query, patientID)
+// LockDoctor is a no-op for SQLite: writers are already serialized by the
+// BEGIN IMMEDIATE transaction opened by SqliteTxManager
+func (r *SqliteAppointmentRepository) LockDoctor(ctx context.Context, doctorID string) error {
+	return nil
 }
 
-// FindByDoctorID retrieves all appointments for a specific doctor
-func (r *SqliteAppointmentRepository) FindByDoctorID(ctx context.Context, doctorID string) ([]*domain.Appointment, error) {
+// FindByPatientID retrieves all appointments for a specific patient
+func (r *SqliteAppointmentRepository) FindByPatientID(ctx context.Context, patientID string) ([]*domain.Appointment, error) {
 	query := `
 		SELECT
 			a.id,
 			a.patient_id,
 			a.doctor_id,
+			a.location_id,
 			a.scheduled_at,
 			a.duration,
 			a.status,
@@ -142,6 +152,7 @@ func (r *SqliteAppointmentRepository) FindByDoctorID(ctx context.Context, doctor
 			a.updated_at,
 			a.reminder_24h_sent,
 			a.reminder_1h_sent,
+			a.version,
 			(pu.first_name || ' ' || pu.last_name) as patient_name,
 			(du.first_name || ' ' || du.last_name) as doctor_name
 		FROM appointments a
@@ -149,56 +160,89 @@ func (r *SqliteAppointmentRepository) FindByDoctorID(ctx context.Context, doctor
 		JOIN users pu ON pu.id = pt.user_id
 		JOIN doctors doc ON doc.id = a.doctor_id
 		JOIN users du ON du.id = doc.user_id
-		WHERE a.doctor_id = $1
-		ORDER BY a.scheduled_at DESC
+		WHERE a.patient_id = ?
 	`
+	query, args := scope(ctx, query, []interface{}{patientID}, locationClinic("a."))
 
-	return r.queryAppointmentsWithNames(ctx, query, doctorID)
+	return r.queryAppointmentsWithNames(ctx, query+" ORDER BY a.scheduled_at DESC", args...)
 }
 
 // FindByDoctorAndDate retrieves all appointments for a doctor on a specific date
 func (r *SqliteAppointmentRepository) FindByDoctorAndDate(ctx context.Context, doctorID string, date time.Time) ([]*domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent
+		SELECT id, patient_id, doctor_id, location_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent, version
 		FROM appointments
-		WHERE doctor_id = $1 AND DATE(scheduled_at) = DATE($2)
-		ORDER BY scheduled_at ASC
+		WHERE doctor_id = ? AND scheduled_at >= ? AND scheduled_at < ?
 	`
 
-	return r.queryAppointments(ctx, query, doctorID, date)
+	// Compare against the calendar day in the caller's location
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	query, args := scope(ctx, query, []interface{}{doctorID, startOfDay.UTC(), endOfDay.UTC()}, locationClinic(""))
+
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
 }
 
 // FindByDoctorAndDateRange retrieves appointments for a doctor within a date range
 func (r *SqliteAppointmentRepository) FindByDoctorAndDateRange(ctx context.Context, doctorID string, start, end time.Time) ([]*domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent
+		SELECT id, patient_id, doctor_id, location_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent, version
+		FROM appointments
+		WHERE doctor_id = ? AND scheduled_at >= ? AND scheduled_at < ?
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, start.UTC(), end.UTC()}, locationClinic(""))
+
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
+}
+
+// FindByDoctorsAndDateRange retrieves the appointments of several doctors within a date range in one query
+func (r *SqliteAppointmentRepository) FindByDoctorsAndDateRange(ctx context.Context, doctorIDs []string, start, end time.Time) ([]*domain.Appointment, error) {
+	if len(doctorIDs) == 0 {
+		return nil, nil
+	}
+
+	ids, args := inList(doctorIDs)
+	query := `
+		SELECT id, patient_id, doctor_id, location_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent, version
 		FROM appointments
-		WHERE doctor_id = $1 AND scheduled_at >= $2 AND scheduled_at < $3
-		ORDER BY scheduled_at ASC
+		WHERE doctor_id IN ` + ids + ` AND scheduled_at >= ? AND scheduled_at < ?
 	`
+	query, args = scope(ctx, query, append(args, start.UTC(), end.UTC()), locationClinic(""))
 
-	return r.queryAppointments(ctx, query, doctorID, start, end)
+	return r.queryAppointments(ctx, query+" ORDER BY scheduled_at ASC", args...)
 }
 
-// Update modifies an existing appointment in the database
+// Update modifies the schedule, location, status and notes of an existing
+// appointment if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
 func (r *SqliteAppointmentRepository) Update(ctx context.Context, appointment *domain.Appointment) error {
 	query := `
 		UPDATE appointments
-		SET status = $1, notes = $2, updated_at = $3
-		WHERE id = $4
+		SET scheduled_at = ?, duration = ?, location_id = ?, status = ?, notes = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ?
 	`
 
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
+	notes, err := r.cipher.Encrypt(appointment.Notes)
+	if err != nil {
+		return err
+	}
+
+	query, args := scope(ctx, query, []interface{}{
+		appointment.ScheduledAt.UTC(),
+		appointment.Duration,
+		appointment.LocationID,
 		appointment.Status,
-		appointment.Notes,
-		appointment.UpdatedAt,
+		notes,
+		appointment.UpdatedAt.UTC(),
 		appointment.ID,
-	)
+		appointment.Version,
+	}, locationClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
-		return err
+		return mapError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -207,17 +251,18 @@ func (r *SqliteAppointmentRepository) Update(ctx context.Context, appointment *d
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("appointment not found")
+		return versionMismatch(ctx, r.db, `SELECT 1 FROM appointments WHERE id = ?`, appointment.ID, locationClinic(""), errors.New("appointment not found"))
 	}
 
+	appointment.Version++
 	return nil
 }
 
 // Delete removes an appointment from the database
 func (r *SqliteAppointmentRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM appointments WHERE id = $1`
+	query, args := scope(ctx, `DELETE FROM appointments WHERE id = ?`, []interface{}{id}, locationClinic(""))
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -236,7 +281,7 @@ func (r *SqliteAppointmentRepository) Delete(ctx context.Context, id string) err
 
 // queryAppointments is a helper method to query appointments
 func (r *SqliteAppointmentRepository) queryAppointments(ctx context.Context, query string, args ...interface{}) ([]*domain.Appointment, error) {
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -252,6 +297,7 @@ func (r *SqliteAppointmentRepository) queryAppointments(ctx context.Context, que
 			&appointment.ID,
 			&appointment.PatientID,
 			&appointment.DoctorID,
+			&appointment.LocationID,
 			&scheduledAt,
 			&appointment.Duration,
 			&appointment.Status,
@@ -261,12 +307,17 @@ func (r *SqliteAppointmentRepository) queryAppointments(ctx context.Context, que
 			&updatedAt,
 			&appointment.Reminder24hSent,
 			&appointment.Reminder1hSent,
+			&appointment.Version,
 		)
 
 		if err != nil {
 			return nil, err
 		}
 
+		if err := r.decryptNotes(&appointment); err != nil {
+			return nil, err
+		}
+
 		// Assign scanned values
 		appointment.ScheduledAt = scheduledAt
 		appointment.CreatedAt = createdAt
@@ -281,13 +332,13 @@ func (r *SqliteAppointmentRepository) queryAppointments(ctx context.Context, que
 // FindByScheduledAtRange finds appointments within a time range with specific status
 func (r *SqliteAppointmentRepository) FindByScheduledAtRange(ctx context.Context, start, end time.Time, status string) ([]*domain.Appointment, error) {
 	query := `
-		SELECT id, patient_id, doctor_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent
+		SELECT id, patient_id, doctor_id, location_id, scheduled_at, duration, status, reason, notes, created_at, updated_at, reminder_24h_sent, reminder_1h_sent, version
 		FROM appointments
-		WHERE scheduled_at >= $1 AND scheduled_at <= $2 AND status = $3
-		ORDER BY scheduled_at ASC
+		WHERE scheduled_at >= ? AND scheduled_at <= ? AND status = ?
 	`
+	query, args := scope(ctx, query, []interface{}{start.UTC(), end.UTC(), status}, locationClinic(""))
 
-	rows, err := r.db.QueryContext(ctx, query, start, end, status)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY scheduled_at ASC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -303,6 +354,7 @@ func (r *SqliteAppointmentRepository) FindByScheduledAtRange(ctx context.Context
 			&appointment.ID,
 			&appointment.PatientID,
 			&appointment.DoctorID,
+			&appointment.LocationID,
 			&scheduledAt,
 			&appointment.Duration,
 			&appointment.Status,
@@ -312,12 +364,17 @@ func (r *SqliteAppointmentRepository) FindByScheduledAtRange(ctx context.Context
 			&updatedAt,
 			&appointment.Reminder24hSent,
 			&appointment.Reminder1hSent,
+			&appointment.Version,
 		)
 
 		if err != nil {
 			return nil, err
 		}
 
+		if err := r.decryptNotes(&appointment); err != nil {
+			return nil, err
+		}
+
 		appointment.ScheduledAt = scheduledAt
 		appointment.CreatedAt = createdAt
 		appointment.UpdatedAt = updatedAt
@@ -330,21 +387,21 @@ func (r *SqliteAppointmentRepository) FindByScheduledAtRange(ctx context.Context
 
 // MarkReminder24hSent marks the 24-hour reminder as sent
 func (r *SqliteAppointmentRepository) MarkReminder24hSent(ctx context.Context, id string) error {
-	query := `UPDATE appointments SET reminder_24h_sent = TRUE WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query, args := scope(ctx, `UPDATE appointments SET reminder_24h_sent = TRUE WHERE id = ?`, []interface{}{id}, locationClinic(""))
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	return err
 }
 
 // MarkReminder1hSent marks the 1-hour reminder as sent
 func (r *SqliteAppointmentRepository) MarkReminder1hSent(ctx context.Context, id string) error {
-	query := `UPDATE appointments SET reminder_1h_sent = TRUE WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query, args := scope(ctx, `UPDATE appointments SET reminder_1h_sent = TRUE WHERE id = ?`, []interface{}{id}, locationClinic(""))
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	return err
 }
 
 // queryAppointmentsWithNames is a helper method to query appointments with patient and doctor names
 func (r *SqliteAppointmentRepository) queryAppointmentsWithNames(ctx context.Context, query string, args ...interface{}) ([]*domain.Appointment, error) {
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -361,6 +418,7 @@ func (r *SqliteAppointmentRepository) queryAppointmentsWithNames(ctx context.Con
 			&appointment.ID,
 			&appointment.PatientID,
 			&appointment.DoctorID,
+			&appointment.LocationID,
 			&scheduledAt,
 			&appointment.Duration,
 			&appointment.Status,
@@ -370,6 +428,7 @@ func (r *SqliteAppointmentRepository) queryAppointmentsWithNames(ctx context.Con
 			&updatedAt,
 			&appointment.Reminder24hSent,
 			&appointment.Reminder1hSent,
+			&appointment.Version,
 			&patientName,
 			&doctorName,
 		)
@@ -378,6 +437,10 @@ func (r *SqliteAppointmentRepository) queryAppointmentsWithNames(ctx context.Con
 			return nil, err
 		}
 
+		if err := r.decryptNotes(&appointment); err != nil {
+			return nil, err
+		}
+
 		// Assign scanned values
 		appointment.ScheduledAt = scheduledAt
 		appointment.CreatedAt = createdAt
@@ -393,12 +456,12 @@ func (r *SqliteAppointmentRepository) queryAppointmentsWithNames(ctx context.Con
 	return appointments, rows.Err()
 }
 
-// CountByStatus counts appointments by status
-func (r *SqliteAppointmentRepository) CountByStatus(ctx context.Context, status string) (int, error) {
-	query := `SELECT COUNT(*) FROM appointments WHERE status = $1`
+// CountByStatus counts appointments by status, at one location if locationID is set
+func (r *SqliteAppointmentRepository) CountByStatus(ctx context.Context, status, locationID string) (int, error) {
+	query, args := atLocation(ctx, `SELECT COUNT(*) FROM appointments WHERE status = ?`, []interface{}{status}, locationID, "")
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, status).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -406,12 +469,12 @@ func (r *SqliteAppointmentRepository) CountByStatus(ctx context.Context, status
 	return count, nil
 }
 
-// CountAll counts all appointments
-func (r *SqliteAppointmentRepository) CountAll(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM appointments`
+// CountAll counts all appointments, at one location if locationID is set
+func (r *SqliteAppointmentRepository) CountAll(ctx context.Context, locationID string) (int, error) {
+	query, args := atLocation(ctx, `SELECT COUNT(*) FROM appointments WHERE 1=1`, nil, locationID, "")
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -419,17 +482,20 @@ func (r *SqliteAppointmentRepository) CountAll(ctx context.Context) (int, error)
 	return count, nil
 }
 
-// GetTotalRevenue calculates total revenue from completed appointments
-func (r *SqliteAppointmentRepository) GetTotalRevenue(ctx context.Context) (float64, error) {
+// GetTotalRevenue calculates total revenue from completed appointments, each
+// charged at the price of its service at the location it took place
+func (r *SqliteAppointmentRepository) GetTotalRevenue(ctx context.Context, locationID string) (float64, error) {
 	query := `
-		SELECT COALESCE(SUM(s.price), 0)
+		SELECT COALESCE(SUM(COALESCE(sp.price, s.price)), 0)
 		FROM appointments a
 		JOIN services s ON a.service_id = s.id
-		WHERE a.status = $1
+		LEFT JOIN service_prices sp ON sp.service_id = a.service_id AND sp.location_id = a.location_id
+		WHERE a.status = ?
 	`
+	query, args := atLocation(ctx, query, []interface{}{"completed"}, locationID, "a.")
 
 	var revenue float64
-	err := r.db.QueryRowContext(ctx, query, "completed").Scan(&revenue)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&revenue)
 	if err != nil {
 		return 0, err
 	}
@@ -437,8 +503,8 @@ func (r *SqliteAppointmentRepository) GetTotalRevenue(ctx context.Context) (floa
 	return revenue, nil
 }
 
-// GetRevenueByService gets revenue grouped by service
-func (r *SqliteAppointmentRepository) GetRevenueByService(ctx context.Context) (map[string]struct {
+// GetRevenueByService gets revenue grouped by service, priced like GetTotalRevenue
+func (r *SqliteAppointmentRepository) GetRevenueByService(ctx context.Context, locationID string) (map[string]struct {
 	ServiceName string
 	Count       int
 	Revenue     float64
@@ -448,15 +514,15 @@ func (r *SqliteAppointmentRepository) GetRevenueByService(ctx context.Context) (
 			a.service_id,
 			s.name,
 			COUNT(*) as count,
-			SUM(s.price) as revenue
+			SUM(COALESCE(sp.price, s.price)) as revenue
 		FROM appointments a
 		JOIN services s ON a.service_id = s.id
-		WHERE a.status = $1
-		GROUP BY a.service_id, s.name
-		ORDER BY revenue DESC
+		LEFT JOIN service_prices sp ON sp.service_id = a.service_id AND sp.location_id = a.location_id
+		WHERE a.status = ?
 	`
+	query, args := atLocation(ctx, query, []interface{}{"completed"}, locationID, "a.")
 
-	rows, err := r.db.QueryContext(ctx, query, "completed")
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" GROUP BY a.service_id, s.name ORDER BY revenue DESC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -492,7 +558,7 @@ func (r *SqliteAppointmentRepository) GetRevenueByService(ctx context.Context) (
 }
 
 // GetTopDoctors gets doctors with most appointments
-func (r *SqliteAppointmentRepository) GetTopDoctors(ctx context.Context, limit int) ([]struct {
+func (r *SqliteAppointmentRepository) GetTopDoctors(ctx context.Context, limit int, locationID string) ([]struct {
 	DoctorID              string
 	TotalAppointments     int
 	CompletedAppointments int
@@ -503,12 +569,11 @@ func (r *SqliteAppointmentRepository) GetTopDoctors(ctx context.Context, limit i
 			COUNT(*) as total,
 			SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END) as completed
 		FROM appointments
-		GROUP BY doctor_id
-		ORDER BY total DESC
-		LIMIT $1
+		WHERE 1=1
 	`
+	query, args := atLocation(ctx, query, nil, locationID, "")
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" GROUP BY doctor_id ORDER BY total DESC LIMIT ?", append(args, limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -538,7 +603,7 @@ func (r *SqliteAppointmentRepository) GetTopDoctors(ctx context.Context, limit i
 }
 
 // GetTopServices gets most used services
-func (r *SqliteAppointmentRepository) GetTopServices(ctx context.Context, limit int) ([]struct {
+func (r *SqliteAppointmentRepository) GetTopServices(ctx context.Context, limit int, locationID string) ([]struct {
 	ServiceID   string
 	ServiceName string
 	Count       int
@@ -550,12 +615,11 @@ func (r *SqliteAppointmentRepository) GetTopServices(ctx context.Context, limit
 			COUNT(*) as count
 		FROM appointments a
 		JOIN services s ON a.service_id = s.id
-		GROUP BY a.service_id, s.name
-		ORDER BY count DESC
-		LIMIT $1
+		WHERE 1=1
 	`
+	query, args := atLocation(ctx, query, nil, locationID, "a.")
 
-	rows, err := r.db.QueryContext(ctx, query, limit)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" GROUP BY a.service_id, s.name ORDER BY count DESC LIMIT ?", append(args, limit)...)
 	if err != nil {
 		return nil, err
 	}
@@ -584,13 +648,14 @@ func (r *SqliteAppointmentRepository) GetTopServices(ctx context.Context, limit
 	return results, rows.Err()
 }
 
-// FindAllWithFilters retrieves all appointments with optional filters
-func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, filters repository.AppointmentFilters) ([]*domain.Appointment, error) {
+// FindAllWithFilters retrieves one page of appointments matching the optional filters
+func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, filters repository.AppointmentFilters, page repository.PageRequest) ([]*domain.Appointment, error) {
 	query := `
 		SELECT
 			a.id,
 			a.patient_id,
 			a.doctor_id,
+			a.location_id,
 			a.service_id,
 			a.scheduled_at,
 			a.duration,
@@ -601,6 +666,7 @@ func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, fi
 			a.cancellation_reason,
 			a.reminder_24h_sent,
 			a.reminder_1h_sent,
+			a.version,
 			a.created_at,
 			a.updated_at,
 			u_patient.first_name || ' ' || u_patient.last_name as patient_name,
@@ -614,50 +680,55 @@ func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, fi
 		LEFT JOIN services s ON a.service_id = s.id
 		WHERE 1=1
 	`
-
-	args := []interface{}{}
-	argIndex := 1
+	query, args := scope(ctx, query, []interface{}{}, locationClinic("a."))
 
 	// Add filters dynamically
+	if filters.ID != "" {
+		query += " AND a.id = ?"
+		args = append(args, filters.ID)
+	}
+
 	if filters.Status != "" {
-		query += " AND a.status = $" + fmt.Sprint(argIndex)
+		query += " AND a.status = ?"
 		args = append(args, filters.Status)
-		argIndex++
 	}
 
 	if filters.DoctorID != "" {
-		query += " AND a.doctor_id = $" + fmt.Sprint(argIndex)
+		query += " AND a.doctor_id = ?"
 		args = append(args, filters.DoctorID)
-		argIndex++
 	}
 
 	if filters.PatientID != "" {
-		query += " AND a.patient_id = $" + fmt.Sprint(argIndex)
+		query += " AND a.patient_id = ?"
 		args = append(args, filters.PatientID)
-		argIndex++
 	}
 
 	if filters.ServiceID != "" {
-		query += " AND a.service_id = $" + fmt.Sprint(argIndex)
+		query += " AND a.service_id = ?"
 		args = append(args, filters.ServiceID)
-		argIndex++
+	}
+
+	if filters.LocationID != "" {
+		query += " AND a.location_id = ?"
+		args = append(args, filters.LocationID)
 	}
 
 	if filters.DateFrom != nil {
-		query += " AND a.scheduled_at >= $" + fmt.Sprint(argIndex)
-		args = append(args, *filters.DateFrom)
-		argIndex++
+		query += " AND a.scheduled_at >= ?"
+		args = append(args, filters.DateFrom.UTC())
 	}
 
 	if filters.DateTo != nil {
-		query += " AND a.scheduled_at <= $" + fmt.Sprint(argIndex)
-		args = append(args, *filters.DateTo)
-		argIndex++
+		query += " AND a.scheduled_at <= ?"
+		args = append(args, filters.DateTo.UTC())
 	}
 
-	query += " ORDER BY a.scheduled_at DESC"
+	query, args, err := keysetPage(query, args, page, appointmentSortExpr(page.Sort, "a."), "a.id")
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -673,6 +744,7 @@ func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, fi
 			&a.ID,
 			&a.PatientID,
 			&a.DoctorID,
+			&a.LocationID,
 			&serviceID,
 			&a.ScheduledAt,
 			&a.Duration,
@@ -683,6 +755,7 @@ func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, fi
 			&cancellationReason,
 			&a.Reminder24hSent,
 			&a.Reminder1hSent,
+			&a.Version,
 			&a.CreatedAt,
 			&a.UpdatedAt,
 			&patientName,
@@ -693,6 +766,10 @@ func (r *SqliteAppointmentRepository) FindAllWithFilters(ctx context.Context, fi
 			return nil, err
 		}
 
+		if err := r.decryptNotes(&a); err != nil {
+			return nil, err
+		}
+
 		if serviceID.Valid {
 			a.ServiceID = serviceID.String
 		}
@@ -721,21 +798,15 @@ func (r *SqliteAppointmentRepository) CountFutureAppointmentsByDoctorAndService(
 	query := `
 		SELECT COUNT(*)
 		FROM appointments
-		WHERE doctor_id = $1
-		AND service_id = $2
-		AND scheduled_at > $3
-		AND status != $4
+		WHERE doctor_id = ?
+		AND service_id = ?
+		AND scheduled_at > ?
+		AND status != ?
 	`
+	query, args := scope(ctx, query, []interface{}{doctorID, serviceID, time.Now().UTC(), domain.StatusCancelled}, locationClinic(""))
 
 	var count int
-	err := r.db.QueryRowContext(
-		ctx,
-		query,
-		doctorID,
-		serviceID,
-		time.Now(),
-		domain.StatusCancelled,
-	).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 
 	if err != nil {
 		return 0, err
@@ -743,3 +814,73 @@ func (r *SqliteAppointmentRepository) CountFutureAppointmentsByDoctorAndService(
 
 	return count, nil
 }
+
+// Reencrypt rewrites the notes that are not sealed with the primary key
+// Rows are read in batches by ID and each one is updated on its own, so an
+// interrupted run can simply be started again. The version is left alone:
+// the appointment itself does not change
+func (r *SqliteAppointmentRepository) Reencrypt(ctx context.Context) (int, error) {
+	type encryptedRow struct {
+		id, notes string
+	}
+
+	rewritten := 0
+	lastID := ""
+	for {
+		query, args := scope(ctx, `
+			SELECT id, COALESCE(notes, '')
+			FROM appointments
+			WHERE id > ?
+		`, []interface{}{lastID}, locationClinic(""))
+
+		rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY id LIMIT ?", append(args, reencryptBatch)...)
+		if err != nil {
+			return rewritten, err
+		}
+
+		var batch []encryptedRow
+		for rows.Next() {
+			var row encryptedRow
+			if err := rows.Scan(&row.id, &row.notes); err != nil {
+				rows.Close()
+				return rewritten, err
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, row := range batch {
+			lastID = row.id
+
+			values, err := rewrap(r.cipher, row.notes)
+			if err != nil {
+				return rewritten, fmt.Errorf("appointment %s: %w", row.id, err)
+			}
+			if values == nil {
+				continue
+			}
+
+			if _, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE appointments SET notes = ? WHERE id = ?`, values[0], row.id); err != nil {
+				return rewritten, err
+			}
+			rewritten++
+		}
+	}
+}
+
+// decryptNotes opens the notes of a scanned appointment in place
+func (r *SqliteAppointmentRepository) decryptNotes(appointment *domain.Appointment) error {
+	notes, err := r.cipher.Decrypt(appointment.Notes)
+	if err != nil {
+		return fmt.Errorf("appointment %s: %w", appointment.ID, err)
+	}
+
+	appointment.Notes = notes
+	return nil
+}
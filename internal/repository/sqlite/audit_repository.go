@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteAuditRepository implements the AuditRepository interface using SQLite
+type SqliteAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteAuditRepository creates a new instance of SqliteAuditRepository
+func NewSqliteAuditRepository(db *sql.DB) repository.AuditRepository {
+	return &SqliteAuditRepository{
+		db: db,
+	}
+}
+
+// Create appends an entry to the audit log
+func (r *SqliteAuditRepository) Create(ctx context.Context, entry *domain.AuditEntry) error {
+	query := `
+		INSERT INTO audit_log (id, clinic_id, actor_id, actor_role, entity, entity_id, action, changes, ip_address, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, entry.ClinicID)
+	if err != nil {
+		return err
+	}
+	entry.ClinicID = clinicID
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		entry.ID,
+		entry.ClinicID,
+		entry.ActorID,
+		entry.ActorRole,
+		entry.Entity,
+		entry.EntityID,
+		entry.Action,
+		string(entry.Changes),
+		entry.IPAddress,
+		entry.RequestID,
+		entry.CreatedAt.UTC(),
+	)
+
+	return err
+}
+
+// List retrieves entries matching the filters, newest first
+func (r *SqliteAuditRepository) List(ctx context.Context, filters repository.AuditFilters) ([]*domain.AuditEntry, error) {
+	query := `
+		SELECT id, clinic_id, actor_id, actor_role, entity, entity_id, action, changes, ip_address, request_id, created_at
+		FROM audit_log
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, []interface{}{}, ownClinic(""))
+
+	// Add filters dynamically
+	if filters.Entity != "" {
+		query += " AND entity = ?"
+		args = append(args, filters.Entity)
+	}
+
+	if filters.EntityID != "" {
+		query += " AND entity_id = ?"
+		args = append(args, filters.EntityID)
+	}
+
+	if filters.ActorID != "" {
+		query += " AND actor_id = ?"
+		args = append(args, filters.ActorID)
+	}
+
+	if filters.DateFrom != nil {
+		query += " AND created_at >= ?"
+		args = append(args, filters.DateFrom.UTC())
+	}
+
+	if filters.DateTo != nil {
+		query += " AND created_at <= ?"
+		args = append(args, filters.DateTo.UTC())
+	}
+
+	query += " ORDER BY created_at DESC LIMIT ? OFFSET ?"
+	args = append(args, filters.Limit, filters.Offset)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*domain.AuditEntry
+	for rows.Next() {
+		var entry domain.AuditEntry
+		var changes string
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ClinicID,
+			&entry.ActorID,
+			&entry.ActorRole,
+			&entry.Entity,
+			&entry.EntityID,
+			&entry.Action,
+			&changes,
+			&entry.IPAddress,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Changes = []byte(changes)
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
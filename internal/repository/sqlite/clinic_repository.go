@@ -0,0 +1,385 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteClinicRepository implements the ClinicRepository interface using SQLite
+type SqliteClinicRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteClinicRepository creates a new instance of SqliteClinicRepository
+func NewSqliteClinicRepository(db *sql.DB) repository.ClinicRepository {
+	return &SqliteClinicRepository{
+		db: db,
+	}
+}
+
+// CreateClinic inserts a new clinic
+func (r *SqliteClinicRepository) CreateClinic(ctx context.Context, clinic *domain.Clinic) error {
+	query := `
+		INSERT INTO clinics (id, name, currency_symbol, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		clinic.ID,
+		clinic.Name,
+		clinic.CurrencySymbol,
+		clinic.CreatedAt.UTC(),
+		clinic.UpdatedAt.UTC(),
+	)
+
+	return err
+}
+
+// FindClinicByID retrieves a clinic, or nil if there is none with that ID
+func (r *SqliteClinicRepository) FindClinicByID(ctx context.Context, id string) (*domain.Clinic, error) {
+	query := `
+		SELECT id, name, currency_symbol, created_at, updated_at
+		FROM clinics
+		WHERE id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{id}, "id = ?")
+
+	clinics, err := r.queryClinics(ctx, query, args...)
+	if err != nil || len(clinics) == 0 {
+		return nil, err
+	}
+
+	return clinics[0], nil
+}
+
+// ListClinics retrieves every clinic, oldest first
+func (r *SqliteClinicRepository) ListClinics(ctx context.Context) ([]*domain.Clinic, error) {
+	query := `
+		SELECT id, name, currency_symbol, created_at, updated_at
+		FROM clinics
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, "id = ?")
+
+	return r.queryClinics(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+}
+
+// CreateLocation inserts a new location
+func (r *SqliteClinicRepository) CreateLocation(ctx context.Context, location *domain.Location) error {
+	query := `
+		INSERT INTO locations (id, clinic_id, name, address, phone, is_active, created_at, updated_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, location.ClinicID)
+	if err != nil {
+		return err
+	}
+	location.ClinicID = clinicID
+
+	if location.Version == 0 {
+		location.Version = 1
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		location.ID,
+		location.ClinicID,
+		location.Name,
+		location.Address,
+		location.Phone,
+		location.IsActive,
+		location.CreatedAt.UTC(),
+		location.UpdatedAt.UTC(),
+		location.Version,
+	)
+
+	return err
+}
+
+// FindLocationByID retrieves a location, or nil if there is none with that ID
+func (r *SqliteClinicRepository) FindLocationByID(ctx context.Context, id string) (*domain.Location, error) {
+	query := `
+		SELECT id, clinic_id, name, address, phone, is_active, created_at, updated_at, version
+		FROM locations
+		WHERE id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{id}, ownClinic(""))
+
+	locations, err := r.queryLocations(ctx, query, args...)
+	if err != nil || len(locations) == 0 {
+		return nil, err
+	}
+
+	return locations[0], nil
+}
+
+// ListLocations retrieves the locations of the clinic, oldest first
+func (r *SqliteClinicRepository) ListLocations(ctx context.Context, activeOnly bool) ([]*domain.Location, error) {
+	query := `
+		SELECT id, clinic_id, name, address, phone, is_active, created_at, updated_at, version
+		FROM locations
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	if activeOnly {
+		query += " AND is_active = TRUE"
+	}
+
+	return r.queryLocations(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+}
+
+// UpdateLocation modifies a location if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
+func (r *SqliteClinicRepository) UpdateLocation(ctx context.Context, location *domain.Location) error {
+	query := `
+		UPDATE locations
+		SET name = ?, address = ?, phone = ?, is_active = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ?
+	`
+	query, args := scope(ctx, query, []interface{}{
+		location.Name,
+		location.Address,
+		location.Phone,
+		location.IsActive,
+		location.UpdatedAt.UTC(),
+		location.ID,
+		location.Version,
+	}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return versionMismatch(ctx, r.db, `SELECT 1 FROM locations WHERE id = ?`, location.ID, ownClinic(""), errors.New("location not found"))
+	}
+
+	location.Version++
+	return nil
+}
+
+// AssignDoctor attaches a doctor to a location
+// Assigning a doctor twice is not an error
+func (r *SqliteClinicRepository) AssignDoctor(ctx context.Context, doctorLocation *domain.DoctorLocation) error {
+	query := `
+		INSERT INTO doctor_locations (doctor_id, location_id, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (doctor_id, location_id) DO NOTHING
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, doctorLocation.DoctorID, doctorLocation.LocationID, doctorLocation.CreatedAt.UTC())
+	return err
+}
+
+// RemoveDoctor detaches a doctor from a location
+func (r *SqliteClinicRepository) RemoveDoctor(ctx context.Context, doctorID, locationID string) error {
+	query, args := scope(ctx, `DELETE FROM doctor_locations WHERE doctor_id = ? AND location_id = ?`, []interface{}{doctorID, locationID}, locationClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("doctor-location relationship not found")
+	}
+
+	return nil
+}
+
+// FindLocationsByDoctor retrieves the locations a doctor works at, oldest first
+func (r *SqliteClinicRepository) FindLocationsByDoctor(ctx context.Context, doctorID string) ([]*domain.Location, error) {
+	query := `
+		SELECT l.id, l.clinic_id, l.name, l.address, l.phone, l.is_active, l.created_at, l.updated_at, l.version
+		FROM locations l
+		INNER JOIN doctor_locations dl ON dl.location_id = l.id
+		WHERE dl.doctor_id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID}, ownClinic("l."))
+
+	return r.queryLocations(ctx, query+" ORDER BY l.created_at ASC, l.id ASC", args...)
+}
+
+// ListDoctorLocations retrieves every doctor-location assignment, oldest first
+func (r *SqliteClinicRepository) ListDoctorLocations(ctx context.Context) ([]*domain.DoctorLocation, error) {
+	query := `
+		SELECT doctor_id, location_id, created_at
+		FROM doctor_locations
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, locationClinic(""))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY created_at ASC, doctor_id ASC, location_id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctorLocations []*domain.DoctorLocation
+	for rows.Next() {
+		var dl domain.DoctorLocation
+		if err := rows.Scan(&dl.DoctorID, &dl.LocationID, &dl.CreatedAt); err != nil {
+			return nil, err
+		}
+		doctorLocations = append(doctorLocations, &dl)
+	}
+
+	return doctorLocations, rows.Err()
+}
+
+// SetServicePrice creates or replaces the price of a service at a location
+func (r *SqliteClinicRepository) SetServicePrice(ctx context.Context, price *domain.ServicePrice) error {
+	query := `
+		INSERT INTO service_prices (service_id, location_id, price, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (service_id, location_id) DO UPDATE SET price = excluded.price, updated_at = excluded.updated_at
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		price.ServiceID,
+		price.LocationID,
+		price.Price,
+		price.CreatedAt.UTC(),
+		price.UpdatedAt.UTC(),
+	)
+
+	return err
+}
+
+// RemoveServicePrice drops a price override so the service's own price applies again
+func (r *SqliteClinicRepository) RemoveServicePrice(ctx context.Context, serviceID, locationID string) error {
+	query, args := scope(ctx, `DELETE FROM service_prices WHERE service_id = ? AND location_id = ?`, []interface{}{serviceID, locationID}, locationClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("service price not found")
+	}
+
+	return nil
+}
+
+// FindServicePrices retrieves the price overrides of a location
+func (r *SqliteClinicRepository) FindServicePrices(ctx context.Context, locationID string) ([]*domain.ServicePrice, error) {
+	query := `
+		SELECT service_id, location_id, price, created_at, updated_at
+		FROM service_prices
+		WHERE location_id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{locationID}, locationClinic(""))
+
+	return r.queryServicePrices(ctx, query+" ORDER BY created_at ASC, service_id ASC", args...)
+}
+
+// ListServicePrices retrieves every price override, oldest first
+func (r *SqliteClinicRepository) ListServicePrices(ctx context.Context) ([]*domain.ServicePrice, error) {
+	query := `
+		SELECT service_id, location_id, price, created_at, updated_at
+		FROM service_prices
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, locationClinic(""))
+
+	return r.queryServicePrices(ctx, query+" ORDER BY created_at ASC, service_id ASC, location_id ASC", args...)
+}
+
+// queryClinics is a helper method to query clinics
+func (r *SqliteClinicRepository) queryClinics(ctx context.Context, query string, args ...interface{}) ([]*domain.Clinic, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clinics []*domain.Clinic
+	for rows.Next() {
+		var clinic domain.Clinic
+		if err := rows.Scan(&clinic.ID, &clinic.Name, &clinic.CurrencySymbol, &clinic.CreatedAt, &clinic.UpdatedAt); err != nil {
+			return nil, err
+		}
+		clinics = append(clinics, &clinic)
+	}
+
+	return clinics, rows.Err()
+}
+
+// queryLocations is a helper method to query locations
+func (r *SqliteClinicRepository) queryLocations(ctx context.Context, query string, args ...interface{}) ([]*domain.Location, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []*domain.Location
+	for rows.Next() {
+		var location domain.Location
+		err := rows.Scan(
+			&location.ID,
+			&location.ClinicID,
+			&location.Name,
+			&location.Address,
+			&location.Phone,
+			&location.IsActive,
+			&location.CreatedAt,
+			&location.UpdatedAt,
+			&location.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, &location)
+	}
+
+	return locations, rows.Err()
+}
+
+// queryServicePrices is a helper method to query price overrides
+func (r *SqliteClinicRepository) queryServicePrices(ctx context.Context, query string, args ...interface{}) ([]*domain.ServicePrice, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*domain.ServicePrice
+	for rows.Next() {
+		var price domain.ServicePrice
+		if err := rows.Scan(&price.ServiceID, &price.LocationID, &price.Price, &price.CreatedAt, &price.UpdatedAt); err != nil {
+			return nil, err
+		}
+		prices = append(prices, &price)
+	}
+
+	return prices, rows.Err()
+}
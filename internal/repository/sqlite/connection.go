@@ -3,32 +3,86 @@ package sqlite
 import (
 	"database/sql"
 	"log"
+	"strings"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	"version-1-0/internal/repository/migration"
 )
 
-// InitDB initializes and returns a PostgreSQL database connection
-// Returns an error if the connection cannot be established
-func InitDB(databaseURL string) (*sql.DB, error) {
-	// Open PostgreSQL connection
-	db, err := sql.Open("pgx", databaseURL)
+// InitDB opens (or creates) an embedded SQLite database at the given path
+// Use ":memory:" for a throwaway in-memory database
+// With autoMigrate the pending migrations are applied, otherwise the schema must
+// already be current (see cmd/migrate). An in-memory database starts empty on
+// every boot, so it is always migrated
+func InitDB(path string, autoMigrate bool) (*sql.DB, error) {
+	db, err := Open(path)
 	if err != nil {
 		return nil, err
 	}
 
-	// Test connection
-	if err := db.Ping(); err != nil {
+	if autoMigrate || path == ":memory:" {
+		err = migration.Run(db, migration.SQLite)
+	} else {
+		err = migration.CheckCurrent(db, migration.SQLite)
+	}
+	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	log.Printf("Database connection established: PostgreSQL (Neon)")
+	log.Println("Database schema is up to date")
 
-	// Run migrations with version control
-	if err := runMigrations(db); err != nil {
+	return db, nil
+}
+
+// Open opens an embedded SQLite database without touching its schema
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", buildDSN(path))
+	if err != nil {
 		return nil, err
 	}
 
-	log.Println("Database migrations completed successfully")
+	// An in-memory database lives and dies with its connection,
+	// so every query must share the same one
+	if path == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Printf("Database connection established: SQLite (%s)", path)
 
 	return db, nil
 }
+
+// buildDSN appends the connection pragmas required by the repositories:
+// foreign key enforcement, a busy timeout for concurrent writers, WAL journaling,
+// an ISO-8601 time format that SQLite date functions understand and
+// BEGIN IMMEDIATE transactions so units of work take the write lock up front
+func buildDSN(path string) string {
+	params := []string{
+		"_pragma=foreign_keys(1)",
+		"_pragma=busy_timeout(5000)",
+		"_time_format=sqlite",
+		"_txlock=immediate",
+	}
+	if path != ":memory:" {
+		params = append(params, "_pragma=journal_mode(WAL)")
+	}
+
+	separator := "?"
+	if strings.Contains(path, "?") {
+		separator = "&"
+	}
+
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+
+	return path + separator + strings.Join(params, "&")
+}
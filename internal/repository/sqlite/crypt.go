@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"version-1-0/pkg/fieldcrypt"
+)
+
+// reencryptBatch is how many rows Reencrypt reads at a time
+const reencryptBatch = 500
+
+// encrypt seals each value with the primary key, keeping the order
+func encrypt(cipher *fieldcrypt.Cipher, values ...string) ([]string, error) {
+	sealed := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		if sealed[i], err = cipher.Encrypt(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return sealed, nil
+}
+
+// rewrap seals each stored value with the primary key, keeping the order
+// Returns nil when every value already was, so the row needs no update
+func rewrap(cipher *fieldcrypt.Cipher, values ...string) ([]string, error) {
+	changed := false
+	sealed := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		if sealed[i], err = cipher.Rewrap(value); err != nil {
+			return nil, err
+		}
+		changed = changed || sealed[i] != value
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return sealed, nil
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
 )
 
-// SqliteDoctorRepository implements the DoctorRepository interface using PostgreSQL
+// SqliteDoctorRepository implements the DoctorRepository interface using SQLite
 type SqliteDoctorRepository struct {
 	db *sql.DB
 }
@@ -23,53 +24,31 @@ func NewSqliteDoctorRepository(db *sql.DB) repository.DoctorRepository {
 
 // Create inserts a new doctor into the database
 func (r *SqliteDoctorRepository) Create(ctx context.Context, doctor *domain.Doctor) error {
-	return r.CreateWithTx(ctx, nil, doctor)
-}
-
-// CreateWithTx inserts a new doctor using a transaction or database connection
-func (r *SqliteDoctorRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, doctor *domain.Doctor) error {
 	query := `
 		INSERT INTO doctors (id, user_id, specialty, license_number, years_of_experience,
-		                     education, bio, consultation_fee, is_available, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		                     education, bio, search_specialty, search_bio, consultation_fee, is_available, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	var err error
-	if tx != nil {
-		_, err = tx.ExecContext(
-			ctx,
-			query,
-			doctor.ID,
-			doctor.UserID,
-			doctor.Specialty,
-			doctor.LicenseNumber,
-			doctor.YearsOfExperience,
-			doctor.Education,
-			doctor.Bio,
-			doctor.ConsultationFee,
-			doctor.IsAvailable,
-			doctor.CreatedAt,
-			doctor.UpdatedAt,
-		)
-	} else {
-		_, err = r.db.ExecContext(
-			ctx,
-			query,
-			doctor.ID,
-			doctor.UserID,
-			doctor.Specialty,
-			doctor.LicenseNumber,
-			doctor.YearsOfExperience,
-			doctor.Education,
-			doctor.Bio,
-			doctor.ConsultationFee,
-			doctor.IsAvailable,
-			doctor.CreatedAt,
-			doctor.UpdatedAt,
-		)
-	}
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		doctor.ID,
+		doctor.UserID,
+		doctor.Specialty,
+		doctor.LicenseNumber,
+		doctor.YearsOfExperience,
+		doctor.Education,
+		doctor.Bio,
+		searchWords(doctor.Specialty),
+		searchWords(doctor.Bio),
+		doctor.ConsultationFee,
+		doctor.IsAvailable,
+		doctor.CreatedAt.UTC(),
+		doctor.UpdatedAt.UTC(),
+	)
 
-	return err
+	return mapError(err)
 }
 
 // FindByID retrieves a doctor by their unique identifier
@@ -78,11 +57,12 @@ func (r *SqliteDoctorRepository) FindByID(ctx context.Context, id string) (*doma
 		SELECT id, user_id, specialty, license_number, years_of_experience,
 		       education, bio, consultation_fee, is_available, created_at, updated_at
 		FROM doctors
-		WHERE id = $1
+		WHERE id = ? AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{id}, userClinic(""))
 
 	var doctor domain.Doctor
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&doctor.ID,
 		&doctor.UserID,
 		&doctor.Specialty,
@@ -112,11 +92,12 @@ func (r *SqliteDoctorRepository) FindByUserID(ctx context.Context, userID string
 		SELECT id, user_id, specialty, license_number, years_of_experience,
 		       education, bio, consultation_fee, is_available, created_at, updated_at
 		FROM doctors
-		WHERE user_id = $1
+		WHERE user_id = ? AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{userID}, userClinic(""))
 
 	var doctor domain.Doctor
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&doctor.ID,
 		&doctor.UserID,
 		&doctor.Specialty,
@@ -146,12 +127,13 @@ func (r *SqliteDoctorRepository) FindBySpecialty(ctx context.Context, specialty
 		SELECT id, user_id, specialty, license_number, years_of_experience,
 		       education, bio, consultation_fee, is_available, created_at, updated_at
 		FROM doctors
-		WHERE LOWER(specialty) LIKE LOWER($1)
-		ORDER BY created_at DESC
+		WHERE LOWER(specialty) LIKE LOWER(?) AND deleted_at IS NULL
 	`
 
 	searchPattern := "%" + specialty + "%"
-	rows, err := r.db.QueryContext(ctx, query, searchPattern)
+	query, args := scope(ctx, query, []interface{}{searchPattern}, userClinic(""))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY created_at DESC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -186,33 +168,36 @@ func (r *SqliteDoctorRepository) FindBySpecialty(ctx context.Context, specialty
 func (r *SqliteDoctorRepository) Update(ctx context.Context, doctor *domain.Doctor) error {
 	query := `
 		UPDATE doctors
-		SET specialty = $1,
-		    license_number = $2,
-		    years_of_experience = $3,
-		    education = $4,
-		    bio = $5,
-		    consultation_fee = $6,
-		    is_available = $7,
-		    updated_at = $8
-		WHERE id = $9
+		SET specialty = ?,
+		    license_number = ?,
+		    years_of_experience = ?,
+		    education = ?,
+		    bio = ?,
+		    search_specialty = ?,
+		    search_bio = ?,
+		    consultation_fee = ?,
+		    is_available = ?,
+		    updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
-
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
+	query, args := scope(ctx, query, []interface{}{
 		doctor.Specialty,
 		doctor.LicenseNumber,
 		doctor.YearsOfExperience,
 		doctor.Education,
 		doctor.Bio,
+		searchWords(doctor.Specialty),
+		searchWords(doctor.Bio),
 		doctor.ConsultationFee,
 		doctor.IsAvailable,
-		doctor.UpdatedAt,
+		doctor.UpdatedAt.UTC(),
 		doctor.ID,
-	)
+	}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
-		return err
+		return mapError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -227,11 +212,17 @@ func (r *SqliteDoctorRepository) Update(ctx context.Context, doctor *domain.Doct
 	return nil
 }
 
-// Delete removes a doctor from the repository by their ID
+// Delete performs a soft delete by setting deleted_at
 func (r *SqliteDoctorRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM doctors WHERE id = $1`
+	query := `
+		UPDATE doctors
+		SET deleted_at = ?1,
+		    updated_at = ?1
+		WHERE id = ?2 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), id}, userClinic(""))
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -254,11 +245,52 @@ func (r *SqliteDoctorRepository) List(ctx context.Context, limit, offset int) ([
 		SELECT id, user_id, specialty, license_number, years_of_experience,
 		       education, bio, consultation_fee, is_available, created_at, updated_at
 		FROM doctors
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, nil, userClinic(""))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY created_at DESC LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctors []*domain.Doctor
+	for rows.Next() {
+		var doctor domain.Doctor
+		err := rows.Scan(
+			&doctor.ID,
+			&doctor.UserID,
+			&doctor.Specialty,
+			&doctor.LicenseNumber,
+			&doctor.YearsOfExperience,
+			&doctor.Education,
+			&doctor.Bio,
+			&doctor.ConsultationFee,
+			&doctor.IsAvailable,
+			&doctor.CreatedAt,
+			&doctor.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		doctors = append(doctors, &doctor)
+	}
+
+	return doctors, rows.Err()
+}
+
+// ListDeleted retrieves soft deleted doctors, most recently deleted first
+func (r *SqliteDoctorRepository) ListDeleted(ctx context.Context) ([]*domain.Doctor, error) {
+	query := `
+		SELECT id, user_id, specialty, license_number, years_of_experience,
+		       education, bio, consultation_fee, is_available, created_at, updated_at, deleted_at
+		FROM doctors
+		WHERE deleted_at IS NOT NULL
 	`
+	query, args := scope(ctx, query, nil, userClinic(""))
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY deleted_at DESC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -267,6 +299,7 @@ func (r *SqliteDoctorRepository) List(ctx context.Context, limit, offset int) ([
 	var doctors []*domain.Doctor
 	for rows.Next() {
 		var doctor domain.Doctor
+		var deletedAt time.Time
 		err := rows.Scan(
 			&doctor.ID,
 			&doctor.UserID,
@@ -279,12 +312,227 @@ func (r *SqliteDoctorRepository) List(ctx context.Context, limit, offset int) ([
 			&doctor.IsAvailable,
 			&doctor.CreatedAt,
 			&doctor.UpdatedAt,
+			&deletedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		doctor.DeletedAt = &deletedAt
 		doctors = append(doctors, &doctor)
 	}
 
 	return doctors, rows.Err()
 }
+
+// FindDeletedByID retrieves a soft deleted doctor, or nil if there is none with that ID
+func (r *SqliteDoctorRepository) FindDeletedByID(ctx context.Context, id string) (*domain.Doctor, error) {
+	query := `
+		SELECT id, user_id, specialty, license_number, years_of_experience,
+		       education, bio, consultation_fee, is_available, created_at, updated_at, deleted_at
+		FROM doctors
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{id}, userClinic(""))
+
+	var doctor domain.Doctor
+	var deletedAt time.Time
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&doctor.ID,
+		&doctor.UserID,
+		&doctor.Specialty,
+		&doctor.LicenseNumber,
+		&doctor.YearsOfExperience,
+		&doctor.Education,
+		&doctor.Bio,
+		&doctor.ConsultationFee,
+		&doctor.IsAvailable,
+		&doctor.CreatedAt,
+		&doctor.UpdatedAt,
+		&deletedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	doctor.DeletedAt = &deletedAt
+	return &doctor, nil
+}
+
+// Restore clears the deletion mark of a soft deleted doctor
+func (r *SqliteDoctorRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE doctors
+		SET deleted_at = NULL,
+		    updated_at = ?
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), id}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("doctor not found")
+	}
+
+	return nil
+}
+
+// RestoreByUserID clears the deletion mark of a user's doctor profile, if it has one
+func (r *SqliteDoctorRepository) RestoreByUserID(ctx context.Context, userID string) error {
+	query := `
+		UPDATE doctors
+		SET deleted_at = NULL,
+		    updated_at = ?
+		WHERE user_id = ? AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), userID}, userClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// FindSearchCandidates retrieves the active doctors, with their user and
+// service names, that pass the filters
+func (r *SqliteDoctorRepository) FindSearchCandidates(ctx context.Context, filters repository.DoctorSearchFilters) ([]*repository.DoctorSearchResult, error) {
+	query := `
+		SELECT u.id, u.email, u.first_name, u.last_name, u.phone, u.role, u.is_active, u.created_at, u.updated_at, u.version,
+		       d.id, d.specialty, d.license_number, d.years_of_experience,
+		       COALESCE(d.education, ''), COALESCE(d.bio, ''), d.consultation_fee, d.is_available, d.created_at, d.updated_at
+		FROM doctors d
+		INNER JOIN users u ON u.id = d.user_id
+		WHERE u.role = 'doctor' AND u.is_active = TRUE
+		AND u.deleted_at IS NULL AND d.deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{}, ownClinic("u."))
+
+	for _, word := range filters.Words {
+		query += ` AND (u.search_name LIKE ? OR d.search_specialty LIKE ? OR d.search_bio LIKE ? OR EXISTS (
+			SELECT 1 FROM doctor_services ds
+			JOIN services s ON s.id = ds.service_id
+			WHERE ds.doctor_id = d.id AND ds.is_active = TRUE
+			AND s.is_active = TRUE AND s.deleted_at IS NULL AND s.search_name LIKE ?
+		))`
+		pattern := wordPattern(word)
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+
+	for _, word := range filters.Specialty {
+		query += " AND d.search_specialty LIKE ?"
+		args = append(args, wordPattern(word))
+	}
+
+	if filters.MinFee != nil {
+		query += " AND d.consultation_fee >= ?"
+		args = append(args, *filters.MinFee)
+	}
+
+	if filters.MaxFee != nil {
+		query += " AND d.consultation_fee <= ?"
+		args = append(args, *filters.MaxFee)
+	}
+
+	if filters.ServiceID != "" {
+		query += ` AND EXISTS (
+			SELECT 1 FROM doctor_services ds
+			JOIN services s ON s.id = ds.service_id
+			WHERE ds.doctor_id = d.id AND ds.service_id = ? AND ds.is_active = TRUE
+			AND s.is_active = TRUE AND s.deleted_at IS NULL
+		)`
+		args = append(args, filters.ServiceID)
+	}
+
+	if filters.DayOfWeek != "" {
+		query += ` AND EXISTS (
+			SELECT 1 FROM schedules sc
+			WHERE sc.doctor_id = d.id AND sc.day_of_week = ? AND sc.is_active = TRUE
+		)`
+		args = append(args, filters.DayOfWeek)
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*repository.DoctorSearchResult
+	byDoctorID := make(map[string]*repository.DoctorSearchResult)
+	for rows.Next() {
+		var user domain.User
+		var doctor domain.Doctor
+		err := rows.Scan(
+			&user.ID,
+			&user.Email,
+			&user.FirstName,
+			&user.LastName,
+			&user.Phone,
+			&user.Role,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+			&doctor.ID,
+			&doctor.Specialty,
+			&doctor.LicenseNumber,
+			&doctor.YearsOfExperience,
+			&doctor.Education,
+			&doctor.Bio,
+			&doctor.ConsultationFee,
+			&doctor.IsAvailable,
+			&doctor.CreatedAt,
+			&doctor.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		doctor.UserID = user.ID
+
+		result := &repository.DoctorSearchResult{User: &user, Doctor: &doctor}
+		results = append(results, result)
+		byDoctorID[doctor.ID] = result
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	// Attach the names of the services each candidate offers
+	serviceRows, err := conn(ctx, r.db).QueryContext(ctx, `
+		SELECT ds.doctor_id, s.name
+		FROM doctor_services ds
+		JOIN services s ON s.id = ds.service_id
+		WHERE ds.is_active = TRUE AND s.is_active = TRUE AND s.deleted_at IS NULL
+		ORDER BY s.name ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer serviceRows.Close()
+
+	for serviceRows.Next() {
+		var doctorID, name string
+		if err := serviceRows.Scan(&doctorID, &name); err != nil {
+			return nil, err
+		}
+		if result, ok := byDoctorID[doctorID]; ok {
+			result.Services = append(result.Services, name)
+		}
+	}
+
+	return results, serviceRows.Err()
+}
@@ -26,28 +26,28 @@ func NewSqliteDoctorServiceRepository(db *sql.DB) repository.DoctorServiceReposi
 func (r *SqliteDoctorServiceRepository) Assign(ctx context.Context, doctorService *domain.DoctorService) error {
 	query := `
 		INSERT INTO doctor_services (id, doctor_id, service_id, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		doctorService.ID,
 		doctorService.DoctorID,
 		doctorService.ServiceID,
 		doctorService.IsActive,
-		doctorService.CreatedAt,
-		doctorService.UpdatedAt,
+		doctorService.CreatedAt.UTC(),
+		doctorService.UpdatedAt.UTC(),
 	)
 
-	return err
+	return mapError(err)
 }
 
 // Remove removes a service assignment from a doctor
 func (r *SqliteDoctorServiceRepository) Remove(ctx context.Context, doctorID, serviceID string) error {
-	query := `DELETE FROM doctor_services WHERE doctor_id = $1 AND service_id = $2`
+	query, args := scope(ctx, `DELETE FROM doctor_services WHERE doctor_id = ? AND service_id = ?`, []interface{}{doctorID, serviceID}, serviceClinic(""))
 
-	result, err := r.db.ExecContext(ctx, query, doctorID, serviceID)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -69,6 +69,7 @@ func (r *SqliteDoctorServiceRepository) FindDoctorsByService(ctx context.Context
 	query := `
 		SELECT
 			u.id,
+			u.clinic_id,
 			u.email,
 			u.password_hash,
 			u.first_name,
@@ -77,15 +78,17 @@ func (r *SqliteDoctorServiceRepository) FindDoctorsByService(ctx context.Context
 			u.role,
 			u.is_active,
 			u.created_at,
-			u.updated_at
+			u.updated_at,
+			u.version
 		FROM users u
 		INNER JOIN doctors d ON d.user_id = u.id
 		INNER JOIN doctor_services ds ON ds.doctor_id = d.id
-		WHERE ds.service_id = $1 AND ds.is_active = TRUE AND u.is_active = TRUE
-		ORDER BY u.first_name ASC, u.last_name ASC
+		WHERE ds.service_id = ? AND ds.is_active = TRUE AND u.is_active = TRUE
+		AND u.deleted_at IS NULL AND d.deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{serviceID}, ownClinic("u."))
 
-	rows, err := r.db.QueryContext(ctx, query, serviceID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY u.first_name ASC, u.last_name ASC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +103,7 @@ func (r *SqliteDoctorServiceRepository) FindDoctorsByService(ctx context.Context
 
 		err := rows.Scan(
 			&user.ID,
+			&user.ClinicID,
 			&user.Email,
 			&user.PasswordHash,
 			&user.FirstName,
@@ -109,6 +113,7 @@ func (r *SqliteDoctorServiceRepository) FindDoctorsByService(ctx context.Context
 			&isActive,
 			&createdAt,
 			&updatedAt,
+			&user.Version,
 		)
 
 		if err != nil {
@@ -131,20 +136,23 @@ func (r *SqliteDoctorServiceRepository) FindServicesByDoctor(ctx context.Context
 	query := `
 		SELECT
 			s.id,
+			s.clinic_id,
 			s.name,
 			s.description,
 			s.duration_minutes,
 			s.price,
 			s.is_active,
 			s.created_at,
-			s.updated_at
+			s.updated_at,
+			s.version
 		FROM services s
 		INNER JOIN doctor_services ds ON ds.service_id = s.id
-		WHERE ds.doctor_id = $1 AND ds.is_active = TRUE AND s.is_active = TRUE
-		ORDER BY s.name ASC
+		WHERE ds.doctor_id = ? AND ds.is_active = TRUE AND s.is_active = TRUE
+		AND s.deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{doctorID}, ownClinic("s."))
 
-	rows, err := r.db.QueryContext(ctx, query, doctorID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY s.name ASC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +167,7 @@ func (r *SqliteDoctorServiceRepository) FindServicesByDoctor(ctx context.Context
 
 		err := rows.Scan(
 			&service.ID,
+			&service.ClinicID,
 			&service.Name,
 			&service.Description,
 			&service.DurationMinutes,
@@ -166,6 +175,7 @@ func (r *SqliteDoctorServiceRepository) FindServicesByDoctor(ctx context.Context
 			&isActive,
 			&createdAt,
 			&updatedAt,
+			&service.Version,
 		)
 
 		if err != nil {
@@ -187,11 +197,12 @@ func (r *SqliteDoctorServiceRepository) FindServicesByDoctor(ctx context.Context
 func (r *SqliteDoctorServiceRepository) IsAssigned(ctx context.Context, doctorID, serviceID string) (bool, error) {
 	query := `
 		SELECT COUNT(*) FROM doctor_services
-		WHERE doctor_id = $1 AND service_id = $2 AND is_active = TRUE
+		WHERE doctor_id = ? AND service_id = ? AND is_active = TRUE
 	`
+	query, args := scope(ctx, query, []interface{}{doctorID, serviceID}, serviceClinic(""))
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, doctorID, serviceID).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return false, err
 	}
@@ -199,19 +210,47 @@ func (r *SqliteDoctorServiceRepository) IsAssigned(ctx context.Context, doctorID
 	return count > 0, nil
 }
 
+// ListAll retrieves every doctor-service relationship, active or not, oldest first
+func (r *SqliteDoctorServiceRepository) ListAll(ctx context.Context) ([]*domain.DoctorService, error) {
+	query := `
+		SELECT id, doctor_id, service_id, is_active, created_at, updated_at
+		FROM doctor_services
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, serviceClinic(""))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var doctorServices []*domain.DoctorService
+	for rows.Next() {
+		var ds domain.DoctorService
+		if err := rows.Scan(&ds.ID, &ds.DoctorID, &ds.ServiceID, &ds.IsActive, &ds.CreatedAt, &ds.UpdatedAt); err != nil {
+			return nil, err
+		}
+		doctorServices = append(doctorServices, &ds)
+	}
+
+	return doctorServices, rows.Err()
+}
+
 // FindByDoctorAndService retrieves a specific doctor-service relationship
 func (r *SqliteDoctorServiceRepository) FindByDoctorAndService(ctx context.Context, doctorID, serviceID string) (*domain.DoctorService, error) {
 	query := `
 		SELECT id, doctor_id, service_id, is_active, created_at, updated_at
 		FROM doctor_services
-		WHERE doctor_id = $1 AND service_id = $2
+		WHERE doctor_id = ? AND service_id = ?
 	`
+	query, args := scope(ctx, query, []interface{}{doctorID, serviceID}, serviceClinic(""))
 
 	var ds domain.DoctorService
 	var isActive bool
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, doctorID, serviceID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&ds.ID,
 		&ds.DoctorID,
 		&ds.ServiceID,
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	sqlitedriver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"version-1-0/internal/domain"
+)
+
+// slotTakenMessage is raised by the appointment overlap triggers
+const slotTakenMessage = "appointment slot taken"
+
+// uniqueConstraintErrors maps the columns named by a UNIQUE constraint failure
+// to domain errors
+var uniqueConstraintErrors = map[string]error{
+	"users.email":            domain.ErrEmailAlreadyExists,
+	"doctors.license_number": domain.ErrLicenseNumberAlreadyExists,
+	"doctor_services.doctor_id, doctor_services.service_id": domain.ErrServiceAlreadyAssigned,
+}
+
+// mapError translates errors raised by the schema guards into domain errors
+// Unique and primary key violations become domain.ErrDuplicateRecord unless
+// uniqueConstraintErrors names a more specific error, as in the PostgreSQL
+// repositories. Any other error is returned unchanged
+func mapError(err error) error {
+	var sqliteErr *sqlitedriver.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			if mapped, ok := uniqueConstraintErrors[uniqueColumns(sqliteErr)]; ok {
+				return mapped
+			}
+			return domain.ErrDuplicateRecord
+		}
+	}
+
+	if err != nil && strings.Contains(err.Error(), slotTakenMessage) {
+		return domain.ErrSlotTaken
+	}
+	return err
+}
+
+// uniqueColumns returns the columns named by a UNIQUE constraint failure,
+// reported as "UNIQUE constraint failed: users.email (2067)"
+func uniqueColumns(err *sqlitedriver.Error) string {
+	_, columns, _ := strings.Cut(err.Error(), "UNIQUE constraint failed: ")
+	columns, _, _ = strings.Cut(columns, " (")
+	return columns
+}
+
+// versionMismatch explains why an UPDATE guarded by "version = ?" changed no row:
+// it returns domain.ErrVersionConflict if the row named by id still exists, or
+// notFound otherwise. rowQuery selects the row by id; clinic is the scope
+// condition of its table, so rows of other clinics count as missing
+func versionMismatch(ctx context.Context, db *sql.DB, rowQuery, id, clinic string, notFound error) error {
+	query, args := scope(ctx, rowQuery, []interface{}{id}, clinic)
+
+	var exists bool
+	if err := conn(ctx, db).QueryRowContext(ctx, "SELECT EXISTS("+query+")", args...).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrVersionConflict
+	}
+	return notFound
+}
@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"version-1-0/internal/domain"
+)
+
+func newTestUser(id, email string) *domain.User {
+	now := time.Now()
+	return &domain.User{
+		ID:           id,
+		Email:        email,
+		PasswordHash: "hash",
+		FirstName:    "Ana",
+		LastName:     "Torres",
+		Role:         domain.RoleDoctor,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func newTestIdentity(id, userID, subject string) *domain.ExternalIdentity {
+	now := time.Now()
+	return &domain.ExternalIdentity{
+		ID:          id,
+		UserID:      userID,
+		Issuer:      "https://login.clinica.test",
+		Subject:     subject,
+		Email:       "ana@clinica.test",
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+}
+
+func TestMapErrorTranslatesUniqueViolations(t *testing.T) {
+	ctx := context.Background()
+	db, err := InitDB(filepath.Join(t.TempDir(), "clinica.db"), true)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	users := NewSqliteUserRepository(db)
+	identities := NewSqliteExternalIdentityRepository(db)
+
+	for _, user := range []*domain.User{newTestUser("user-1", "ana@clinica.test"), newTestUser("user-2", "luis@clinica.test")} {
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("create %s: %v", user.ID, err)
+		}
+	}
+	if err := identities.Create(ctx, newTestIdentity("identity-1", "user-1", "subject-1")); err != nil {
+		t.Fatalf("create identity: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		insert func() error
+		want   error
+	}{
+		{"user primary key", func() error { return users.Create(ctx, newTestUser("user-1", "otra@clinica.test")) }, domain.ErrDuplicateRecord},
+		{"user email", func() error { return users.Create(ctx, newTestUser("user-3", "ana@clinica.test")) }, domain.ErrEmailAlreadyExists},
+		// The single sign-on flow reports both as an identity linked elsewhere
+		{"identity subject", func() error { return identities.Create(ctx, newTestIdentity("identity-2", "user-2", "subject-1")) }, domain.ErrDuplicateRecord},
+		{"identity per user", func() error { return identities.Create(ctx, newTestIdentity("identity-3", "user-1", "subject-2")) }, domain.ErrDuplicateRecord},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.insert(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
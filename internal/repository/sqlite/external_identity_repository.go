@@ -0,0 +1,147 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteExternalIdentityRepository implements the ExternalIdentityRepository interface using SQLite
+type SqliteExternalIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteExternalIdentityRepository creates a new instance of SqliteExternalIdentityRepository
+func NewSqliteExternalIdentityRepository(db *sql.DB) repository.ExternalIdentityRepository {
+	return &SqliteExternalIdentityRepository{
+		db: db,
+	}
+}
+
+// identityColumns lists the identity columns in the order scanIdentity reads them
+const identityColumns = `id, clinic_id, user_id, issuer, subject, email, created_at, last_login_at`
+
+// Create links a user to an identity of a provider
+// The user must belong to the clinic of the identity
+func (r *SqliteExternalIdentityRepository) Create(ctx context.Context, identity *domain.ExternalIdentity) error {
+	query := `
+		INSERT INTO external_identities (id, clinic_id, user_id, issuer, subject, email, created_at, last_login_at)
+		SELECT ?, clinic_id, id, ?, ?, ?, ?, ? FROM users WHERE id = ? AND clinic_id = ?
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, identity.ClinicID)
+	if err != nil {
+		return err
+	}
+	identity.ClinicID = clinicID
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		identity.ID,
+		identity.Issuer,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt.UTC(),
+		identity.LastLoginAt.UTC(),
+		identity.UserID,
+		identity.ClinicID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	return nil
+}
+
+// FindBySubject retrieves the identity a provider names with subject, or nil if there is none
+func (r *SqliteExternalIdentityRepository) FindBySubject(ctx context.Context, issuer, subject string) (*domain.ExternalIdentity, error) {
+	query, args := scope(ctx, `SELECT `+identityColumns+` FROM external_identities WHERE issuer = ? AND subject = ?`, []interface{}{issuer, subject}, ownClinic(""))
+
+	identity, err := scanIdentity(conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// ListByUser retrieves the identities linked to a user, oldest first
+func (r *SqliteExternalIdentityRepository) ListByUser(ctx context.Context, userID string) ([]*domain.ExternalIdentity, error) {
+	query, args := scope(ctx, `SELECT `+identityColumns+` FROM external_identities WHERE user_id = ?`, []interface{}{userID}, ownClinic(""))
+	query += ` ORDER BY created_at, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*domain.ExternalIdentity
+	for rows.Next() {
+		identity, err := scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// RecordLogin stores when an identity last signed in and the email the provider gave
+func (r *SqliteExternalIdentityRepository) RecordLogin(ctx context.Context, id, email string, loginAt time.Time) error {
+	query, args := scope(ctx, `UPDATE external_identities SET email = ?, last_login_at = ? WHERE id = ?`, []interface{}{email, loginAt.UTC(), id}, ownClinic(""))
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// Delete removes the link of an identity
+// Returns false if there is none
+func (r *SqliteExternalIdentityRepository) Delete(ctx context.Context, id string) (bool, error) {
+	query, args := scope(ctx, `DELETE FROM external_identities WHERE id = ?`, []interface{}{id}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// scanIdentity reads a row selected with identityColumns
+func scanIdentity(row rowScanner) (*domain.ExternalIdentity, error) {
+	var identity domain.ExternalIdentity
+	err := row.Scan(
+		&identity.ID,
+		&identity.ClinicID,
+		&identity.UserID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
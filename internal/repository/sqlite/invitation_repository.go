@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteInvitationRepository implements the InvitationRepository interface using SQLite
+type SqliteInvitationRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteInvitationRepository creates a new instance of SqliteInvitationRepository
+func NewSqliteInvitationRepository(db *sql.DB) repository.InvitationRepository {
+	return &SqliteInvitationRepository{
+		db: db,
+	}
+}
+
+// invitationColumns lists the invitation columns in the order scanInvitation reads them
+const invitationColumns = `id, clinic_id, email, role, token_hash, invited_by, user_id, expires_at, created_at, accepted_at, revoked_at`
+
+// Create stores a new invitation
+func (r *SqliteInvitationRepository) Create(ctx context.Context, invitation *domain.Invitation) error {
+	query := `
+		INSERT INTO invitations (id, clinic_id, email, role, token_hash, invited_by, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, invitation.ClinicID)
+	if err != nil {
+		return err
+	}
+	invitation.ClinicID = clinicID
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		invitation.ID,
+		invitation.ClinicID,
+		invitation.Email,
+		string(invitation.Role),
+		invitation.TokenHash,
+		nullIfEmpty(invitation.InvitedBy),
+		invitation.ExpiresAt.UTC(),
+		invitation.CreatedAt.UTC(),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves an invitation by its unique identifier, or nil if there is none
+func (r *SqliteInvitationRepository) FindByID(ctx context.Context, id string) (*domain.Invitation, error) {
+	query, args := scope(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE id = ?`, []interface{}{id}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByHash retrieves an invitation by the hash of its token, or nil if there is none
+func (r *SqliteInvitationRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	query, args := scope(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE token_hash = ?`, []interface{}{tokenHash}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// List retrieves every invitation, newest first
+func (r *SqliteInvitationRepository) List(ctx context.Context) ([]*domain.Invitation, error) {
+	query, args := scope(ctx, `SELECT `+invitationColumns+` FROM invitations WHERE 1=1`, nil, ownClinic(""))
+	query += ` ORDER BY created_at DESC, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invitations []*domain.Invitation
+	for rows.Next() {
+		invitation, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	return invitations, rows.Err()
+}
+
+// MarkAccepted records the account created from an invitation that is neither accepted nor revoked
+// Returns false if another request got there first
+func (r *SqliteInvitationRepository) MarkAccepted(ctx context.Context, id, userID string, acceptedAt time.Time) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET accepted_at = ?, user_id = ?
+		WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{acceptedAt.UTC(), userID, id}, ownClinic(""))
+
+	return r.update(ctx, query, args)
+}
+
+// Revoke sets revoked_at on an invitation that is neither accepted nor revoked
+// Returns false if it was accepted or revoked first
+func (r *SqliteInvitationRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (bool, error) {
+	query := `
+		UPDATE invitations
+		SET revoked_at = ?
+		WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt.UTC(), id}, ownClinic(""))
+
+	return r.update(ctx, query, args)
+}
+
+// RevokeByEmail revokes every open invitation sent to an email
+func (r *SqliteInvitationRepository) RevokeByEmail(ctx context.Context, email string, revokedAt time.Time) error {
+	query := `
+		UPDATE invitations
+		SET revoked_at = ?
+		WHERE email = ? AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt.UTC(), email}, ownClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// update runs a conditional UPDATE and reports whether it changed a row
+func (r *SqliteInvitationRepository) update(ctx context.Context, query string, args []interface{}) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// findOne runs a query selecting invitationColumns and returns its single row, or nil if there is none
+func (r *SqliteInvitationRepository) findOne(ctx context.Context, query string, args []interface{}) (*domain.Invitation, error) {
+	invitation, err := scanInvitation(conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// scanInvitation reads a row selected with invitationColumns
+func scanInvitation(row rowScanner) (*domain.Invitation, error) {
+	var invitation domain.Invitation
+	var role string
+	var invitedBy, userID sql.NullString
+	var acceptedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.ClinicID,
+		&invitation.Email,
+		&role,
+		&invitation.TokenHash,
+		&invitedBy,
+		&userID,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt,
+		&acceptedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation.Role = domain.UserRole(role)
+	invitation.InvitedBy = invitedBy.String
+	invitation.UserID = userID.String
+	invitation.AcceptedAt = timePtr(acceptedAt)
+	invitation.RevokedAt = timePtr(revokedAt)
+
+	return &invitation, nil
+}
@@ -0,0 +1,239 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteLoginLockoutRepository implements the LoginLockoutRepository interface using SQLite
+type SqliteLoginLockoutRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteLoginLockoutRepository creates a new instance of SqliteLoginLockoutRepository
+func NewSqliteLoginLockoutRepository(db *sql.DB) repository.LoginLockoutRepository {
+	return &SqliteLoginLockoutRepository{
+		db: db,
+	}
+}
+
+// lockoutColumns lists the login lockout columns in the order scanLockout reads them
+const lockoutColumns = `id, clinic_id, scope, subject, failures, last_failure_at, locked_until, unlock_token_hash, created_at`
+
+// ReserveAttempt counts a login attempt as failed on the counter of a scope and
+// subject, creating it with the given ID if there is none, provided the counter
+// still has the given failures. A counter whose last failure is older than
+// resetBefore starts over. Returns false if a concurrent attempt changed it first
+func (r *SqliteLoginLockoutRepository) ReserveAttempt(ctx context.Context, id, lockoutScope, subject string, failures int, attemptAt, resetBefore time.Time) (bool, error) {
+	query := `
+		INSERT INTO login_lockouts (id, clinic_id, scope, subject, failures, last_failure_at, created_at)
+		VALUES (?, ?, ?, ?, 1, ?, ?)
+		ON CONFLICT (clinic_id, scope, subject) DO UPDATE SET
+			failures = CASE WHEN login_lockouts.last_failure_at < ? THEN 1 ELSE login_lockouts.failures + 1 END,
+			locked_until = CASE WHEN login_lockouts.last_failure_at < ? THEN NULL ELSE login_lockouts.locked_until END,
+			unlock_token_hash = CASE WHEN login_lockouts.last_failure_at < ? THEN NULL ELSE login_lockouts.unlock_token_hash END,
+			last_failure_at = excluded.last_failure_at
+		WHERE login_lockouts.failures = ?
+	`
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		id,
+		clinicID,
+		lockoutScope,
+		subject,
+		attemptAt.UTC(),
+		attemptAt.UTC(),
+		resetBefore.UTC(),
+		resetBefore.UTC(),
+		resetBefore.UTC(),
+		failures,
+	)
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ReleaseAttempt takes back one failure from the counter of a scope and subject
+func (r *SqliteLoginLockoutRepository) ReleaseAttempt(ctx context.Context, lockoutScope, subject string) error {
+	query := `UPDATE login_lockouts SET failures = failures - 1 WHERE scope = ? AND subject = ? AND failures > 0`
+	query, args := scope(ctx, query, []interface{}{lockoutScope, subject}, ownClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// Find retrieves the counter of a scope and subject, or nil if there is none
+func (r *SqliteLoginLockoutRepository) Find(ctx context.Context, lockoutScope, subject string) (*domain.LoginLockout, error) {
+	query := `SELECT ` + lockoutColumns + ` FROM login_lockouts WHERE scope = ? AND subject = ?`
+	query, args := scope(ctx, query, []interface{}{lockoutScope, subject}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByID retrieves a counter by its unique identifier, or nil if there is none
+func (r *SqliteLoginLockoutRepository) FindByID(ctx context.Context, id string) (*domain.LoginLockout, error) {
+	query, args := scope(ctx, `SELECT `+lockoutColumns+` FROM login_lockouts WHERE id = ?`, []interface{}{id}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// FindByUnlockHash retrieves a counter by the hash of its unlock token, or nil if there is none
+func (r *SqliteLoginLockoutRepository) FindByUnlockHash(ctx context.Context, tokenHash string) (*domain.LoginLockout, error) {
+	query, args := scope(ctx, `SELECT `+lockoutColumns+` FROM login_lockouts WHERE unlock_token_hash = ?`, []interface{}{tokenHash}, ownClinic(""))
+	return r.findOne(ctx, query, args)
+}
+
+// Lock sets locked_until and the unlock token hash on a counter that is not locked at the given time
+// Returns false if another request locked it first
+func (r *SqliteLoginLockoutRepository) Lock(ctx context.Context, id string, lockedUntil time.Time, unlockTokenHash string, now time.Time) (bool, error) {
+	query := `
+		UPDATE login_lockouts
+		SET locked_until = ?, unlock_token_hash = ?
+		WHERE id = ? AND (locked_until IS NULL OR locked_until <= ?)
+	`
+	query, args := scope(ctx, query, []interface{}{lockedUntil.UTC(), nullIfEmpty(unlockTokenHash), id, now.UTC()}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, mapError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ListLocked retrieves the counters still locked at the given time, most recent failure first
+func (r *SqliteLoginLockoutRepository) ListLocked(ctx context.Context, lockoutScope string, now time.Time) ([]*domain.LoginLockout, error) {
+	query := `SELECT ` + lockoutColumns + ` FROM login_lockouts WHERE locked_until > ?`
+	args := []interface{}{now.UTC()}
+	if lockoutScope != "" {
+		query += ` AND scope = ?`
+		args = append(args, lockoutScope)
+	}
+	query, args = scope(ctx, query, args, ownClinic(""))
+	query += ` ORDER BY last_failure_at DESC, id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lockouts []*domain.LoginLockout
+	for rows.Next() {
+		lockout, err := scanLockout(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockouts = append(lockouts, lockout)
+	}
+
+	return lockouts, rows.Err()
+}
+
+// Delete removes a counter, clearing its failures and lockout
+// Returns false if there was none
+func (r *SqliteLoginLockoutRepository) Delete(ctx context.Context, id string) (bool, error) {
+	query, args := scope(ctx, `DELETE FROM login_lockouts WHERE id = ?`, []interface{}{id}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeleteBySubject removes the counter of a scope and subject, if any
+func (r *SqliteLoginLockoutRepository) DeleteBySubject(ctx context.Context, lockoutScope, subject string) error {
+	query, args := scope(ctx, `DELETE FROM login_lockouts WHERE scope = ? AND subject = ?`, []interface{}{lockoutScope, subject}, ownClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// PurgeStale permanently removes counters whose last failure is older than the
+// given time and that are not locked anymore
+func (r *SqliteLoginLockoutRepository) PurgeStale(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM login_lockouts
+		WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until <= ?)
+	`
+	query, args := scope(ctx, query, []interface{}{before.UTC(), time.Now().UTC()}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
+// findOne runs a query selecting lockoutColumns and returns its single row, or nil if there is none
+func (r *SqliteLoginLockoutRepository) findOne(ctx context.Context, query string, args []interface{}) (*domain.LoginLockout, error) {
+	lockout, err := scanLockout(conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lockout, nil
+}
+
+// scanLockout reads a row selected with lockoutColumns
+func scanLockout(row rowScanner) (*domain.LoginLockout, error) {
+	var lockout domain.LoginLockout
+	var lockedUntil sql.NullTime
+	var unlockTokenHash sql.NullString
+
+	err := row.Scan(
+		&lockout.ID,
+		&lockout.ClinicID,
+		&lockout.Scope,
+		&lockout.Subject,
+		&lockout.Failures,
+		&lockout.LastFailureAt,
+		&lockedUntil,
+		&unlockTokenHash,
+		&lockout.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	lockout.LockedUntil = timePtr(lockedUntil)
+	lockout.UnlockTokenHash = unlockTokenHash.String
+
+	return &lockout, nil
+}
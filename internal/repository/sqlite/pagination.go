@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"version-1-0/internal/repository"
+)
+
+// keysetPage appends the cursor condition, ORDER BY and LIMIT of a keyset page
+// to a query that already has a WHERE clause
+// sortExpr and idExpr are the SQL expressions of the sort key and of the row ID
+func keysetPage(query string, args []interface{}, page repository.PageRequest, sortExpr, idExpr string) (string, []interface{}, error) {
+	direction, comparison := "ASC", ">"
+	if page.Desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	if page.After != nil {
+		var key interface{} = page.After.Key
+		if repository.IsTimeSort(page.Sort) {
+			t, err := repository.ParseTimeKey(page.After.Key)
+			if err != nil {
+				return "", nil, err
+			}
+			key = t
+		}
+		query += fmt.Sprintf(" AND (%s, %s) %s (?, ?)", sortExpr, idExpr, comparison)
+		args = append(args, key, page.After.ID)
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s %s, %s %s", sortExpr, direction, idExpr, direction)
+
+	if page.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, page.Limit)
+	}
+
+	return query, args, nil
+}
+
+// appointmentSortExpr returns the column an appointment page is sorted by
+func appointmentSortExpr(sort, prefix string) string {
+	if sort == repository.SortCreatedAt {
+		return prefix + "created_at"
+	}
+	return prefix + "scheduled_at"
+}
+
+// userSortExpr returns the expression a user page is sorted by, matching repository.UserSortKey
+func userSortExpr(sort, prefix string) string {
+	if sort == repository.SortName {
+		return prefix + "first_name || ' ' || " + prefix + "last_name"
+	}
+	return prefix + "created_at"
+}
+
+// serviceSortExpr returns the column a service page is sorted by
+func serviceSortExpr(sort, prefix string) string {
+	if sort == repository.SortCreatedAt {
+		return prefix + "created_at"
+	}
+	return prefix + "name"
+}
+
+// scheduleSortExpr returns the expression a schedule page is sorted by, matching repository.ScheduleSortKey
+func scheduleSortExpr(sort string) string {
+	if sort == repository.SortCreatedAt {
+		return "created_at"
+	}
+	return `CASE day_of_week
+				WHEN 'monday' THEN '1'
+				WHEN 'tuesday' THEN '2'
+				WHEN 'wednesday' THEN '3'
+				WHEN 'thursday' THEN '4'
+				WHEN 'friday' THEN '5'
+				WHEN 'saturday' THEN '6'
+				WHEN 'sunday' THEN '7'
+			END || ' ' || start_time`
+}
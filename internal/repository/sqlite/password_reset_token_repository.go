@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqlitePasswordResetTokenRepository implements the PasswordResetTokenRepository interface using SQLite
+type SqlitePasswordResetTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSqlitePasswordResetTokenRepository creates a new instance of SqlitePasswordResetTokenRepository
+func NewSqlitePasswordResetTokenRepository(db *sql.DB) repository.PasswordResetTokenRepository {
+	return &SqlitePasswordResetTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a new password reset token
+func (r *SqlitePasswordResetTokenRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	query := `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.TokenHash,
+		token.ExpiresAt.UTC(),
+		token.CreatedAt.UTC(),
+	)
+
+	return mapError(err)
+}
+
+// FindByHash retrieves a token by the hash of its value, or nil if there is none
+func (r *SqlitePasswordResetTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.PasswordResetToken, error) {
+	query := `
+		SELECT id, user_id, token_hash, expires_at, created_at, used_at
+		FROM password_reset_tokens
+		WHERE token_hash = ?
+	`
+	query, args := scope(ctx, query, []interface{}{tokenHash}, userClinic(""))
+
+	var token domain.PasswordResetToken
+	var usedAt sql.NullTime
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+
+	return &token, nil
+}
+
+// MarkUsed sets used_at on a token that was not used yet
+// Returns false if another request used it first
+func (r *SqlitePasswordResetTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = ?
+		WHERE id = ? AND used_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt.UTC(), id}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// InvalidateUserTokens sets used_at on every unused token of a user
+func (r *SqlitePasswordResetTokenRepository) InvalidateUserTokens(ctx context.Context, userID string, usedAt time.Time) error {
+	query := `
+		UPDATE password_reset_tokens
+		SET used_at = ?
+		WHERE user_id = ? AND used_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt.UTC(), userID}, userClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// PurgeExpired permanently removes tokens that expired before the given time
+func (r *SqlitePasswordResetTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args := scope(ctx, `DELETE FROM password_reset_tokens WHERE expires_at < ?`, []interface{}{before.UTC()}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
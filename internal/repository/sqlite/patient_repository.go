@@ -4,78 +4,62 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/pkg/fieldcrypt"
 )
 
-// SqlitePatientRepository implements the PatientRepository interface using PostgreSQL
+// SqlitePatientRepository implements the PatientRepository interface using SQLite
+// The document number, emergency contact, blood type and allergies are stored
+// encrypted; the document number also gets a blind index for lookups
 type SqlitePatientRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *fieldcrypt.Cipher
 }
 
 // NewSqlitePatientRepository creates a new instance of SqlitePatientRepository
-func NewSqlitePatientRepository(db *sql.DB) repository.PatientRepository {
+func NewSqlitePatientRepository(db *sql.DB, cipher *fieldcrypt.Cipher) repository.PatientRepository {
 	return &SqlitePatientRepository{
-		db: db,
+		db:     db,
+		cipher: cipher,
 	}
 }
 
 // Create inserts a new patient into the database
 func (r *SqlitePatientRepository) Create(ctx context.Context, patient *domain.Patient) error {
-	return r.CreateWithTx(ctx, nil, patient)
-}
-
-// CreateWithTx inserts a new patient using a transaction or database connection
-func (r *SqlitePatientRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, patient *domain.Patient) error {
 	query := `
-		INSERT INTO patients (id, user_id, birthdate, document_type, document_number,
+		INSERT INTO patients (id, user_id, birthdate, document_type, document_number, document_number_index,
 		                      address, emergency_contact_name, emergency_contact_phone,
 		                      blood_type, allergies, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	// Convert allergies slice to comma-separated string
-	allergiesStr := strings.Join(patient.Allergies, ",")
-
-	var err error
-	if tx != nil {
-		_, err = tx.ExecContext(
-			ctx,
-			query,
-			patient.ID,
-			patient.UserID,
-			patient.Birthdate,
-			patient.DocumentType,
-			patient.DocumentNumber,
-			patient.Address,
-			patient.EmergencyContactName,
-			patient.EmergencyContactPhone,
-			patient.BloodType,
-			allergiesStr,
-			patient.CreatedAt,
-			patient.UpdatedAt,
-		)
-	} else {
-		_, err = r.db.ExecContext(
-			ctx,
-			query,
-			patient.ID,
-			patient.UserID,
-			patient.Birthdate,
-			patient.DocumentType,
-			patient.DocumentNumber,
-			patient.Address,
-			patient.EmergencyContactName,
-			patient.EmergencyContactPhone,
-			patient.BloodType,
-			allergiesStr,
-			patient.CreatedAt,
-			patient.UpdatedAt,
-		)
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
 	}
 
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		patient.ID,
+		patient.UserID,
+		patient.Birthdate.UTC(),
+		patient.DocumentType,
+		sealed.documentNumber,
+		sealed.documentNumberIndex,
+		patient.Address,
+		sealed.emergencyContactName,
+		sealed.emergencyContactPhone,
+		sealed.bloodType,
+		sealed.allergies,
+		patient.CreatedAt.UTC(),
+		patient.UpdatedAt.UTC(),
+	)
+
 	return err
 }
 
@@ -86,41 +70,10 @@ func (r *SqlitePatientRepository) FindByID(ctx context.Context, id string) (*dom
 		       address, emergency_contact_name, emergency_contact_phone,
 		       blood_type, allergies, created_at, updated_at
 		FROM patients
-		WHERE id = $1
+		WHERE id = ?
 	`
 
-	var patient domain.Patient
-	var allergiesStr string
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&patient.ID,
-		&patient.UserID,
-		&patient.Birthdate,
-		&patient.DocumentType,
-		&patient.DocumentNumber,
-		&patient.Address,
-		&patient.EmergencyContactName,
-		&patient.EmergencyContactPhone,
-		&patient.BloodType,
-		&allergiesStr,
-		&patient.CreatedAt,
-		&patient.UpdatedAt,
-	)
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	// Convert allergies string back to slice
-	if allergiesStr != "" {
-		patient.Allergies = strings.Split(allergiesStr, ",")
-	} else {
-		patient.Allergies = []string{}
-	}
-
-	return &patient, nil
+	return r.queryPatient(ctx, query, id)
 }
 
 // FindByUserID retrieves a patient by their associated user ID
@@ -130,65 +83,49 @@ func (r *SqlitePatientRepository) FindByUserID(ctx context.Context, userID strin
 		       address, emergency_contact_name, emergency_contact_phone,
 		       blood_type, allergies, created_at, updated_at
 		FROM patients
-		WHERE user_id = $1
+		WHERE user_id = ?
 	`
 
-	var patient domain.Patient
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(
-		&patient.ID,
-		&patient.UserID,
-		&patient.Birthdate,
-		&patient.DocumentType,
-		&patient.DocumentNumber,
-		&patient.Address,
-		&patient.EmergencyContactName,
-		&patient.EmergencyContactPhone,
-		&patient.BloodType,
-		&patient.Allergies,
-		&patient.CreatedAt,
-		&patient.UpdatedAt,
-	)
-
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, nil
-		}
-		return nil, err
-	}
-
-	return &patient, nil
+	return r.queryPatient(ctx, query, userID)
 }
 
 // Update modifies an existing patient in the database
 func (r *SqlitePatientRepository) Update(ctx context.Context, patient *domain.Patient) error {
 	query := `
 		UPDATE patients
-		SET birthdate = $1,
-		    document_type = $2,
-		    document_number = $3,
-		    address = $4,
-		    emergency_contact_name = $5,
-		    emergency_contact_phone = $6,
-		    blood_type = $7,
-		    allergies = $8,
-		    updated_at = $9
-		WHERE id = $10
+		SET birthdate = ?,
+		    document_type = ?,
+		    document_number = ?,
+		    document_number_index = ?,
+		    address = ?,
+		    emergency_contact_name = ?,
+		    emergency_contact_phone = ?,
+		    blood_type = ?,
+		    allergies = ?,
+		    updated_at = ?
+		WHERE id = ?
 	`
 
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
-		patient.Birthdate,
+	sealed, err := r.seal(patient)
+	if err != nil {
+		return err
+	}
+
+	query, args := scope(ctx, query, []interface{}{
+		patient.Birthdate.UTC(),
 		patient.DocumentType,
-		patient.DocumentNumber,
+		sealed.documentNumber,
+		sealed.documentNumberIndex,
 		patient.Address,
-		patient.EmergencyContactName,
-		patient.EmergencyContactPhone,
-		patient.BloodType,
-		patient.Allergies,
-		patient.UpdatedAt,
+		sealed.emergencyContactName,
+		sealed.emergencyContactPhone,
+		sealed.bloodType,
+		sealed.allergies,
+		patient.UpdatedAt.UTC(),
 		patient.ID,
-	)
+	}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
 		return err
@@ -208,9 +145,9 @@ func (r *SqlitePatientRepository) Update(ctx context.Context, patient *domain.Pa
 
 // Delete removes a patient from the repository by their ID
 func (r *SqlitePatientRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM patients WHERE id = $1`
+	query, args := scope(ctx, `DELETE FROM patients WHERE id = ?`, []interface{}{id}, userClinic(""))
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -234,38 +171,240 @@ func (r *SqlitePatientRepository) List(ctx context.Context, limit, offset int) (
 		       address, emergency_contact_name, emergency_contact_phone,
 		       blood_type, allergies, created_at, updated_at
 		FROM patients
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE 1=1
 	`
+	query, args := scope(ctx, query, nil, userClinic(""))
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY created_at DESC LIMIT ? OFFSET ?", append(args, limit, offset)...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var patients []*domain.Patient
+	return r.scanPatients(rows)
+}
+
+// FindByDocument retrieves the patients with a document number through its blind index
+// Rows not yet encrypted have no index and are compared in plaintext
+func (r *SqlitePatientRepository) FindByDocument(ctx context.Context, documentType, documentNumber string) ([]*domain.Patient, error) {
+	normalized := domain.NormalizeDocumentNumber(documentNumber)
+	if normalized == "" {
+		return []*domain.Patient{}, nil
+	}
+
+	query := `
+		SELECT id, user_id, birthdate, document_type, document_number,
+		       address, emergency_contact_name, emergency_contact_phone,
+		       blood_type, allergies, created_at, updated_at
+		FROM patients
+		WHERE (document_number_index = ? OR (document_number_index IS NULL AND document_number = ?))
+	`
+	args := []interface{}{r.cipher.BlindIndex(normalized), documentNumber}
+	if documentType != "" {
+		query += " AND document_type = ?"
+		args = append(args, documentType)
+	}
+	query, args = scope(ctx, query, args, userClinic(""))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY created_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanPatients(rows)
+}
+
+// Reencrypt rewrites the encrypted fields of the patients whose values are not
+// sealed with the primary key, or whose blind index is missing or stale
+// Rows are read in batches by ID and each one is updated on its own, so an
+// interrupted run can simply be started again
+func (r *SqlitePatientRepository) Reencrypt(ctx context.Context) (int, error) {
+	type encryptedRow struct {
+		id                    string
+		documentNumber        string
+		documentNumberIndex   sql.NullString
+		emergencyContactName  string
+		emergencyContactPhone string
+		bloodType             string
+		allergies             string
+	}
+
+	rewritten := 0
+	lastID := ""
+	for {
+		query, args := scope(ctx, `
+			SELECT id, document_number, document_number_index, emergency_contact_name,
+			       emergency_contact_phone, COALESCE(blood_type, ''), COALESCE(allergies, '')
+			FROM patients
+			WHERE id > ?
+		`, []interface{}{lastID}, userClinic(""))
+
+		rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY id LIMIT ?", append(args, reencryptBatch)...)
+		if err != nil {
+			return rewritten, err
+		}
+
+		var batch []encryptedRow
+		for rows.Next() {
+			var row encryptedRow
+			if err := rows.Scan(&row.id, &row.documentNumber, &row.documentNumberIndex, &row.emergencyContactName,
+				&row.emergencyContactPhone, &row.bloodType, &row.allergies); err != nil {
+				rows.Close()
+				return rewritten, err
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, row := range batch {
+			lastID = row.id
+
+			documentNumber, err := r.cipher.Decrypt(row.documentNumber)
+			if err != nil {
+				return rewritten, fmt.Errorf("patient %s: %w", row.id, err)
+			}
+			index := r.cipher.BlindIndex(domain.NormalizeDocumentNumber(documentNumber))
+
+			values, err := rewrap(r.cipher, row.documentNumber, row.emergencyContactName, row.emergencyContactPhone, row.bloodType, row.allergies)
+			if err != nil {
+				return rewritten, fmt.Errorf("patient %s: %w", row.id, err)
+			}
+			if values == nil && row.documentNumberIndex.String == index {
+				continue
+			}
+			if values == nil {
+				values = []string{row.documentNumber, row.emergencyContactName, row.emergencyContactPhone, row.bloodType, row.allergies}
+			}
+
+			_, err = conn(ctx, r.db).ExecContext(ctx, `
+				UPDATE patients
+				SET document_number = ?, document_number_index = ?, emergency_contact_name = ?,
+				    emergency_contact_phone = ?, blood_type = ?, allergies = ?
+				WHERE id = ?
+			`, values[0], index, values[1], values[2], values[3], values[4], row.id)
+			if err != nil {
+				return rewritten, err
+			}
+			rewritten++
+		}
+	}
+}
+
+// queryPatient runs a single-row patient query, returning nil if nothing matches
+// Patients of other clinics than the one ctx is scoped to never match
+func (r *SqlitePatientRepository) queryPatient(ctx context.Context, query string, args ...interface{}) (*domain.Patient, error) {
+	query, args = scope(ctx, query, args, userClinic(""))
+	patient, err := r.scanPatient(conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return patient, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPatients reads every row of a patient query
+func (r *SqlitePatientRepository) scanPatients(rows *sql.Rows) ([]*domain.Patient, error) {
+	patients := []*domain.Patient{}
 	for rows.Next() {
-		var patient domain.Patient
-		err := rows.Scan(
-			&patient.ID,
-			&patient.UserID,
-			&patient.Birthdate,
-			&patient.DocumentType,
-			&patient.DocumentNumber,
-			&patient.Address,
-			&patient.EmergencyContactName,
-			&patient.EmergencyContactPhone,
-			&patient.BloodType,
-			&patient.Allergies,
-			&patient.CreatedAt,
-			&patient.UpdatedAt,
-		)
+		patient, err := r.scanPatient(rows)
 		if err != nil {
 			return nil, err
 		}
-		patients = append(patients, &patient)
+		patients = append(patients, patient)
 	}
 
 	return patients, rows.Err()
 }
+
+// scanPatient reads a patient row, decrypting the sensitive fields and
+// converting the stored allergy list back to a slice
+func (r *SqlitePatientRepository) scanPatient(row rowScanner) (*domain.Patient, error) {
+	var patient domain.Patient
+	var bloodType, allergies sql.NullString
+
+	err := row.Scan(
+		&patient.ID,
+		&patient.UserID,
+		&patient.Birthdate,
+		&patient.DocumentType,
+		&patient.DocumentNumber,
+		&patient.Address,
+		&patient.EmergencyContactName,
+		&patient.EmergencyContactPhone,
+		&bloodType,
+		&allergies,
+		&patient.CreatedAt,
+		&patient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []*string{&patient.DocumentNumber, &patient.EmergencyContactName, &patient.EmergencyContactPhone, &bloodType.String, &allergies.String}
+	for _, field := range fields {
+		if *field, err = r.cipher.Decrypt(*field); err != nil {
+			return nil, fmt.Errorf("patient %s: %w", patient.ID, err)
+		}
+	}
+
+	patient.BloodType = bloodType.String
+	patient.Allergies = splitAllergies(allergies.String)
+
+	return &patient, nil
+}
+
+// sealedPatient holds the column values of the encrypted patient fields
+type sealedPatient struct {
+	documentNumber        string
+	documentNumberIndex   string
+	emergencyContactName  string
+	emergencyContactPhone string
+	bloodType             string
+	allergies             string
+}
+
+// seal encrypts the sensitive fields of a patient for writing
+func (r *SqlitePatientRepository) seal(patient *domain.Patient) (*sealedPatient, error) {
+	values, err := encrypt(r.cipher, patient.DocumentNumber, patient.EmergencyContactName,
+		patient.EmergencyContactPhone, patient.BloodType, joinAllergies(patient.Allergies))
+	if err != nil {
+		return nil, err
+	}
+
+	return &sealedPatient{
+		documentNumber:        values[0],
+		documentNumberIndex:   r.cipher.BlindIndex(domain.NormalizeDocumentNumber(patient.DocumentNumber)),
+		emergencyContactName:  values[1],
+		emergencyContactPhone: values[2],
+		bloodType:             values[3],
+		allergies:             values[4],
+	}, nil
+}
+
+// joinAllergies stores the allergy list as a comma-separated string
+func joinAllergies(allergies []string) string {
+	return strings.Join(allergies, ",")
+}
+
+// splitAllergies converts a comma-separated allergy string back to a slice
+func splitAllergies(allergies string) []string {
+	if allergies == "" {
+		return []string{}
+	}
+	return strings.Split(allergies, ",")
+}
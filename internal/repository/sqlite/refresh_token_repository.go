@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteRefreshTokenRepository implements the RefreshTokenRepository interface using SQLite
+type SqliteRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteRefreshTokenRepository creates a new instance of SqliteRefreshTokenRepository
+func NewSqliteRefreshTokenRepository(db *sql.DB) repository.RefreshTokenRepository {
+	return &SqliteRefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a new refresh token
+func (r *SqliteRefreshTokenRepository) Create(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, session_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.SessionID,
+		token.TokenHash,
+		token.ExpiresAt.UTC(),
+		token.CreatedAt.UTC(),
+	)
+
+	return mapError(err)
+}
+
+// FindByHash retrieves a token by the hash of its value, or nil if there is none
+func (r *SqliteRefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, session_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = ?
+	`
+	query, args := scope(ctx, query, []interface{}{tokenHash}, userClinic(""))
+
+	var token domain.RefreshToken
+	var usedAt, revokedAt sql.NullTime
+
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.SessionID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+		&revokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+// MarkUsed sets used_at on a token that is neither used nor revoked
+// Returns false if another request used or revoked it first
+func (r *SqliteRefreshTokenRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE refresh_tokens
+		SET used_at = ?
+		WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt.UTC(), id}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RevokeSession revokes every token of a session
+func (r *SqliteRefreshTokenRepository) RevokeSession(ctx context.Context, sessionID string, revokedAt time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE session_id = ? AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt.UTC(), sessionID}, userClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// RevokeUserSessions revokes the tokens of every session of a user except keepSessionID
+func (r *SqliteRefreshTokenRepository) RevokeUserSessions(ctx context.Context, userID, keepSessionID string, revokedAt time.Time) error {
+	query := `
+		UPDATE refresh_tokens
+		SET revoked_at = ?
+		WHERE user_id = ? AND session_id <> ? AND revoked_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{revokedAt.UTC(), userID, keepSessionID}, userClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// IsSessionRevoked reports whether a session was revoked
+// Sessions without any stored token count as revoked
+func (r *SqliteRefreshTokenRepository) IsSessionRevoked(ctx context.Context, sessionID string) (bool, error) {
+	query := `
+		SELECT COUNT(*), COUNT(revoked_at)
+		FROM refresh_tokens
+		WHERE session_id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{sessionID}, userClinic(""))
+
+	var tokens, revoked int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&tokens, &revoked); err != nil {
+		return false, err
+	}
+
+	return tokens == 0 || revoked > 0, nil
+}
+
+// PurgeExpired permanently removes tokens that expired before the given time
+func (r *SqliteRefreshTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	query, args := scope(ctx, `DELETE FROM refresh_tokens WHERE expires_at < ?`, []interface{}{before.UTC()}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
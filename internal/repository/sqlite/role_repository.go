@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteRoleRepository implements the RoleRepository interface using SQLite
+// Definitions belong to the clinic ctx is scoped to, or the default clinic
+type SqliteRoleRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteRoleRepository creates a new instance of SqliteRoleRepository
+func NewSqliteRoleRepository(db *sql.DB) repository.RoleRepository {
+	return &SqliteRoleRepository{
+		db: db,
+	}
+}
+
+// FindByRole retrieves the stored definition of a role, or nil if there is none
+func (r *SqliteRoleRepository) FindByRole(ctx context.Context, role domain.UserRole) (*domain.RoleDefinition, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	row := conn(ctx, r.db).QueryRowContext(
+		ctx,
+		`SELECT clinic_id, role, permissions, updated_at FROM roles WHERE clinic_id = ? AND role = ?`,
+		clinicID,
+		string(role),
+	)
+	definition, err := scanRoleDefinition(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return definition, nil
+}
+
+// List retrieves every stored definition of the clinic
+func (r *SqliteRoleRepository) List(ctx context.Context) ([]*domain.RoleDefinition, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(
+		ctx,
+		`SELECT clinic_id, role, permissions, updated_at FROM roles WHERE clinic_id = ? ORDER BY role`,
+		clinicID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var definitions []*domain.RoleDefinition
+	for rows.Next() {
+		definition, err := scanRoleDefinition(rows)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+
+	return definitions, rows.Err()
+}
+
+// Save creates or replaces the stored definition of a role
+func (r *SqliteRoleRepository) Save(ctx context.Context, definition *domain.RoleDefinition) error {
+	clinicID, err := repository.ClinicFor(ctx, definition.ClinicID)
+	if err != nil {
+		return err
+	}
+	definition.ClinicID = clinicID
+
+	query := `
+		INSERT INTO roles (clinic_id, role, permissions, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (clinic_id, role) DO UPDATE
+		SET permissions = excluded.permissions, updated_at = excluded.updated_at
+	`
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		definition.ClinicID,
+		string(definition.Role),
+		joinPermissions(definition.Permissions),
+		definition.UpdatedAt.UTC(),
+	)
+
+	return mapError(err)
+}
+
+// Delete removes the stored definition of a role, so it goes back to its defaults
+// Returns false if there was none
+func (r *SqliteRoleRepository) Delete(ctx context.Context, role domain.UserRole) (bool, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return false, err
+	}
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM roles WHERE clinic_id = ? AND role = ?`, clinicID, string(role))
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// scanRoleDefinition reads a roles row selected by FindByRole or List
+func scanRoleDefinition(row rowScanner) (*domain.RoleDefinition, error) {
+	var definition domain.RoleDefinition
+	var role, permissions string
+	if err := row.Scan(&definition.ClinicID, &role, &permissions, &definition.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	definition.Role = domain.UserRole(role)
+	definition.Permissions = splitPermissions(permissions)
+	return &definition, nil
+}
+
+// joinPermissions encodes a permission set as the space separated names stored in roles and api_keys
+func joinPermissions(permissions []domain.Permission) string {
+	names := make([]string, len(permissions))
+	for i, permission := range permissions {
+		names[i] = string(permission)
+	}
+	return strings.Join(names, " ")
+}
+
+// splitPermissions decodes the permissions column of roles and the scopes of api_keys
+func splitPermissions(column string) []domain.Permission {
+	permissions := []domain.Permission{}
+	for _, name := range strings.Fields(column) {
+		permissions = append(permissions, domain.Permission(name))
+	}
+	return permissions
+}
@@ -3,9 +3,11 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
 )
 
 // SqliteScheduleRepository implements ScheduleRepository for SQLite
@@ -21,22 +23,32 @@ func NewSqliteScheduleRepository(db *sql.DB) *SqliteScheduleRepository {
 // Create creates a new schedule
 func (r *SqliteScheduleRepository) Create(ctx context.Context, schedule *domain.Schedule) error {
 	query := `
-		INSERT INTO schedules (id, doctor_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO schedules (id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	if schedule.LocationID == "" {
+		schedule.LocationID = domain.DefaultLocationID
+	}
+
+	if schedule.Version == 0 {
+		schedule.Version = 1
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		schedule.ID,
 		schedule.DoctorID,
+		schedule.LocationID,
 		schedule.DayOfWeek,
 		schedule.StartTime,
 		schedule.EndTime,
 		schedule.SlotDuration,
 		schedule.IsActive,
-		schedule.CreatedAt,
-		schedule.UpdatedAt,
+		schedule.CreatedAt.UTC(),
+		schedule.UpdatedAt.UTC(),
+		schedule.Version,
 	)
 
 	return err
@@ -45,12 +57,13 @@ func (r *SqliteScheduleRepository) Create(ctx context.Context, schedule *domain.
 // FindByID finds a schedule by ID
 func (r *SqliteScheduleRepository) FindByID(ctx context.Context, id string) (*domain.Schedule, error) {
 	query := `
-		SELECT id, doctor_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
 		FROM schedules
-		WHERE id = $1
+		WHERE id = ?
 	`
+	query, args := scope(ctx, query, []interface{}{id}, locationClinic(""))
 
-	schedules, err := r.querySchedules(ctx, query, id)
+	schedules, err := r.querySchedules(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -65,79 +78,118 @@ func (r *SqliteScheduleRepository) FindByID(ctx context.Context, id string) (*do
 // FindByDoctorAndDay finds schedules for a doctor on a specific day
 func (r *SqliteScheduleRepository) FindByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) ([]*domain.Schedule, error) {
 	query := `
-		SELECT id, doctor_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE doctor_id = ? AND day_of_week = ? AND is_active = TRUE
+	`
+	query, args := scope(ctx, query, []interface{}{doctorID, dayOfWeek}, locationClinic(""))
+
+	return r.querySchedules(ctx, query+" ORDER BY start_time ASC", args...)
+}
+
+// FindByDoctorsAndDay finds the active schedules of several doctors on a specific day in one query
+func (r *SqliteScheduleRepository) FindByDoctorsAndDay(ctx context.Context, doctorIDs []string, dayOfWeek string) ([]*domain.Schedule, error) {
+	if len(doctorIDs) == 0 {
+		return nil, nil
+	}
+
+	ids, args := inList(doctorIDs)
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
 		FROM schedules
-		WHERE doctor_id = $1 AND day_of_week = $2 AND is_active = TRUE
-		ORDER BY start_time ASC
+		WHERE doctor_id IN ` + ids + ` AND day_of_week = ? AND is_active = TRUE
 	`
+	query, args = scope(ctx, query, append(args, dayOfWeek), locationClinic(""))
 
-	return r.querySchedules(ctx, query, doctorID, dayOfWeek)
+	return r.querySchedules(ctx, query+" ORDER BY start_time ASC", args...)
 }
 
-// FindByDoctor finds all schedules for a doctor
-func (r *SqliteScheduleRepository) FindByDoctor(ctx context.Context, doctorID string) ([]*domain.Schedule, error) {
+// FindByDoctor finds one page of active schedules for a doctor
+func (r *SqliteScheduleRepository) FindByDoctor(ctx context.Context, doctorID string, page repository.PageRequest) ([]*domain.Schedule, error) {
 	query := `
-		SELECT id, doctor_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
 		FROM schedules
-		WHERE doctor_id = $1 AND is_active = TRUE
-		ORDER BY
-			CASE day_of_week
-				WHEN 'monday' THEN 1
-				WHEN 'tuesday' THEN 2
-				WHEN 'wednesday' THEN 3
-				WHEN 'thursday' THEN 4
-				WHEN 'friday' THEN 5
-				WHEN 'saturday' THEN 6
-				WHEN 'sunday' THEN 7
-			END,
-			start_time ASC
+		WHERE doctor_id = ? AND is_active = TRUE
 	`
+	query, args := scope(ctx, query, []interface{}{doctorID}, locationClinic(""))
 
-	return r.querySchedules(ctx, query, doctorID)
+	query, args, err := keysetPage(query, args, page, scheduleSortExpr(page.Sort), "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.querySchedules(ctx, query, args...)
 }
 
-// Update updates a schedule
+// Update updates a schedule if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
 func (r *SqliteScheduleRepository) Update(ctx context.Context, schedule *domain.Schedule) error {
 	query := `
 		UPDATE schedules
-		SET doctor_id = $1, day_of_week = $2, start_time = $3, end_time = $4,
-		    slot_duration = $5, is_active = $6, updated_at = $7
-		WHERE id = $8
+		SET doctor_id = ?, location_id = ?, day_of_week = ?, start_time = ?, end_time = ?,
+		    slot_duration = ?, is_active = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ?
 	`
-
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
+	query, args := scope(ctx, query, []interface{}{
 		schedule.DoctorID,
+		schedule.LocationID,
 		schedule.DayOfWeek,
 		schedule.StartTime,
 		schedule.EndTime,
 		schedule.SlotDuration,
 		schedule.IsActive,
-		schedule.UpdatedAt,
+		schedule.UpdatedAt.UTC(),
 		schedule.ID,
-	)
+		schedule.Version,
+	}, locationClinic(""))
 
-	return err
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return versionMismatch(ctx, r.db, `SELECT 1 FROM schedules WHERE id = ?`, schedule.ID, locationClinic(""), errors.New("schedule not found"))
+	}
+
+	schedule.Version++
+	return nil
 }
 
 // Delete deletes a schedule
 func (r *SqliteScheduleRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM schedules WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query, args := scope(ctx, `DELETE FROM schedules WHERE id = ?`, []interface{}{id}, locationClinic(""))
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	return err
 }
 
 // DeleteByDoctorAndDay deletes all schedules for a doctor on a specific day
 func (r *SqliteScheduleRepository) DeleteByDoctorAndDay(ctx context.Context, doctorID, dayOfWeek string) error {
-	query := `DELETE FROM schedules WHERE doctor_id = $1 AND day_of_week = $2`
-	_, err := r.db.ExecContext(ctx, query, doctorID, dayOfWeek)
+	query, args := scope(ctx, `DELETE FROM schedules WHERE doctor_id = ? AND day_of_week = ?`, []interface{}{doctorID, dayOfWeek}, locationClinic(""))
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	return err
 }
 
+// ListAll retrieves every schedule, active or not, oldest first
+func (r *SqliteScheduleRepository) ListAll(ctx context.Context) ([]*domain.Schedule, error) {
+	query := `
+		SELECT id, doctor_id, location_id, day_of_week, start_time, end_time, slot_duration, is_active, created_at, updated_at, version
+		FROM schedules
+		WHERE 1=1
+	`
+	query, args := scope(ctx, query, nil, locationClinic(""))
+
+	return r.querySchedules(ctx, query+" ORDER BY created_at ASC, id ASC", args...)
+}
+
 // querySchedules is a helper method to query schedules
 func (r *SqliteScheduleRepository) querySchedules(ctx context.Context, query string, args ...interface{}) ([]*domain.Schedule, error) {
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -153,6 +205,7 @@ func (r *SqliteScheduleRepository) querySchedules(ctx context.Context, query str
 		err := rows.Scan(
 			&schedule.ID,
 			&schedule.DoctorID,
+			&schedule.LocationID,
 			&schedule.DayOfWeek,
 			&schedule.StartTime,
 			&schedule.EndTime,
@@ -160,6 +213,7 @@ func (r *SqliteScheduleRepository) querySchedules(ctx context.Context, query str
 			&isActive,
 			&createdAt,
 			&updatedAt,
+			&schedule.Version,
 		)
 
 		if err != nil {
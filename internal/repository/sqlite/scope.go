@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+
+	"version-1-0/internal/repository"
+)
+
+// Conditions that tie each table to the clinic owning its rows, for use with scope
+// Users, services, locations and the audit log store the clinic; every other
+// table reaches it through its user, location or service. prefix is the table
+// alias with its dot, or empty
+func ownClinic(prefix string) string {
+	return prefix + "clinic_id = ?"
+}
+
+func userClinic(prefix string) string {
+	return prefix + "user_id IN (SELECT id FROM users WHERE clinic_id = ?)"
+}
+
+func locationClinic(prefix string) string {
+	return prefix + "location_id IN (SELECT id FROM locations WHERE clinic_id = ?)"
+}
+
+func serviceClinic(prefix string) string {
+	return prefix + "service_id IN (SELECT id FROM services WHERE clinic_id = ?)"
+}
+
+// scope appends condition to a query whose WHERE clause is still open when
+// ctx is scoped to a clinic; unscoped contexts get the query unchanged
+// condition has a single placeholder for the clinic ID, appended to args
+func scope(ctx context.Context, query string, args []interface{}, condition string) (string, []interface{}) {
+	clinicID, ok := repository.ClinicScope(ctx)
+	if !ok {
+		return query, args
+	}
+	return query + " AND " + condition, append(args, clinicID)
+}
+
+// atLocation narrows an appointment query to one location when locationID is
+// set, on top of the clinic scope. prefix is the appointments alias with its dot
+func atLocation(ctx context.Context, query string, args []interface{}, locationID, prefix string) (string, []interface{}) {
+	query, args = scope(ctx, query, args, locationClinic(prefix))
+	if locationID == "" {
+		return query, args
+	}
+	return query + " AND " + prefix + "location_id = ?", append(args, locationID)
+}
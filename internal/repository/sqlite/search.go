@@ -0,0 +1,44 @@
+package sqlite
+
+import (
+	"database/sql/driver"
+	"strings"
+
+	sqlitedriver "modernc.org/sqlite"
+
+	"version-1-0/pkg/textsearch"
+)
+
+func init() {
+	// Migrations fill the search columns of existing rows with the same folding
+	sqlitedriver.MustRegisterDeterministicScalarFunction("search_words", 1, func(ctx *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+		text, _ := args[0].(string)
+		return searchWords(text), nil
+	})
+}
+
+// searchWords folds text into what the search columns store: its words in
+// lowercase without accents, separated and surrounded by single spaces
+func searchWords(text string) string {
+	words := textsearch.Words(text)
+	if len(words) == 0 {
+		return ""
+	}
+	return " " + strings.Join(words, " ") + " "
+}
+
+// inList returns the placeholders and arguments of an IN list over values
+func inList(values []string) (string, []interface{}) {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ") + ")", args
+}
+
+// wordPattern is the LIKE pattern that finds, in a search column, the words
+// a folded query word starts
+// Query words hold only letters and digits, so nothing needs escaping
+func wordPattern(word string) string {
+	return "% " + word + "%"
+}
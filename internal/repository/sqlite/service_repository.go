@@ -25,21 +25,34 @@ func NewSqliteServiceRepository(db *sql.DB) repository.ServiceRepository {
 // Create inserts a new service into the database
 func (r *SqliteServiceRepository) Create(ctx context.Context, service *domain.Service) error {
 	query := `
-		INSERT INTO services (id, name, description, duration_minutes, price, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO services (id, clinic_id, name, search_name, description, duration_minutes, price, is_active, created_at, updated_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	clinicID, err := repository.ClinicFor(ctx, service.ClinicID)
+	if err != nil {
+		return err
+	}
+	service.ClinicID = clinicID
+
+	if service.Version == 0 {
+		service.Version = 1
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(
 		ctx,
 		query,
 		service.ID,
+		service.ClinicID,
 		service.Name,
+		searchWords(service.Name),
 		service.Description,
 		service.DurationMinutes,
 		service.Price,
 		service.IsActive,
-		service.CreatedAt,
-		service.UpdatedAt,
+		service.CreatedAt.UTC(),
+		service.UpdatedAt.UTC(),
+		service.Version,
 	)
 
 	return err
@@ -48,17 +61,19 @@ func (r *SqliteServiceRepository) Create(ctx context.Context, service *domain.Se
 // FindByID retrieves a service by its unique identifier
 func (r *SqliteServiceRepository) FindByID(ctx context.Context, id string) (*domain.Service, error) {
 	query := `
-		SELECT id, name, description, duration_minutes, price, is_active, created_at, updated_at
+		SELECT id, clinic_id, name, description, duration_minutes, price, is_active, created_at, updated_at, version
 		FROM services
-		WHERE id = $1
+		WHERE id = ? AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{id}, ownClinic(""))
 
 	var service domain.Service
 	var isActive bool
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&service.ID,
+		&service.ClinicID,
 		&service.Name,
 		&service.Description,
 		&service.DurationMinutes,
@@ -66,6 +81,7 @@ func (r *SqliteServiceRepository) FindByID(ctx context.Context, id string) (*dom
 		&isActive,
 		&createdAt,
 		&updatedAt,
+		&service.Version,
 	)
 
 	if err != nil {
@@ -83,48 +99,56 @@ func (r *SqliteServiceRepository) FindByID(ctx context.Context, id string) (*dom
 	return &service, nil
 }
 
-// ListActive retrieves all active services
-func (r *SqliteServiceRepository) ListActive(ctx context.Context) ([]*domain.Service, error) {
+// ListActive retrieves one page of active services
+func (r *SqliteServiceRepository) ListActive(ctx context.Context, page repository.PageRequest) ([]*domain.Service, error) {
 	query := `
-		SELECT id, name, description, duration_minutes, price, is_active, created_at, updated_at
+		SELECT id, clinic_id, name, description, duration_minutes, price, is_active, created_at, updated_at, version
 		FROM services
-		WHERE is_active = TRUE
-		ORDER BY name ASC
+		WHERE is_active = TRUE AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
 
-	return r.queryServices(ctx, query)
+	query, args, err := keysetPage(query, args, page, serviceSortExpr(page.Sort, ""), "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryServices(ctx, query, args...)
 }
 
 // ListAll retrieves all services (active and inactive)
 func (r *SqliteServiceRepository) ListAll(ctx context.Context) ([]*domain.Service, error) {
 	query := `
-		SELECT id, name, description, duration_minutes, price, is_active, created_at, updated_at
+		SELECT id, clinic_id, name, description, duration_minutes, price, is_active, created_at, updated_at, version
 		FROM services
-		ORDER BY name ASC
+		WHERE deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
 
-	return r.queryServices(ctx, query)
+	return r.queryServices(ctx, query+" ORDER BY name ASC", args...)
 }
 
-// Update modifies an existing service in the database
+// Update modifies an existing service if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
 func (r *SqliteServiceRepository) Update(ctx context.Context, service *domain.Service) error {
 	query := `
 		UPDATE services
-		SET name = $1, description = $2, duration_minutes = $3, price = $4, is_active = $5, updated_at = $6
-		WHERE id = $7
+		SET name = ?, search_name = ?, description = ?, duration_minutes = ?, price = ?, is_active = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
-
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
+	query, args := scope(ctx, query, []interface{}{
 		service.Name,
+		searchWords(service.Name),
 		service.Description,
 		service.DurationMinutes,
 		service.Price,
 		service.IsActive,
-		service.UpdatedAt,
+		service.UpdatedAt.UTC(),
 		service.ID,
-	)
+		service.Version,
+	}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
 		return err
@@ -136,17 +160,98 @@ func (r *SqliteServiceRepository) Update(ctx context.Context, service *domain.Se
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("service not found")
+		return versionMismatch(ctx, r.db, `SELECT 1 FROM services WHERE id = ? AND deleted_at IS NULL`, service.ID, ownClinic(""), errors.New("service not found"))
 	}
 
+	service.Version++
 	return nil
 }
 
-// Delete removes a service from the database
+// Delete performs a soft delete by setting deleted_at
 func (r *SqliteServiceRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM services WHERE id = $1`
+	query := `
+		UPDATE services
+		SET deleted_at = ?1,
+		    updated_at = ?1,
+		    version = version + 1
+		WHERE id = ?2 AND deleted_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), id}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errors.New("service not found")
+	}
+
+	return nil
+}
+
+// ListDeleted retrieves soft deleted services, most recently deleted first
+func (r *SqliteServiceRepository) ListDeleted(ctx context.Context) ([]*domain.Service, error) {
+	query := `
+		SELECT id, clinic_id, name, description, duration_minutes, price, is_active, created_at, updated_at, version, deleted_at
+		FROM services
+		WHERE deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY deleted_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	var services []*domain.Service
+
+	for rows.Next() {
+		var service domain.Service
+		var deletedAt time.Time
+
+		err := rows.Scan(
+			&service.ID,
+			&service.ClinicID,
+			&service.Name,
+			&service.Description,
+			&service.DurationMinutes,
+			&service.Price,
+			&service.IsActive,
+			&service.CreatedAt,
+			&service.UpdatedAt,
+			&service.Version,
+			&deletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		service.DeletedAt = &deletedAt
+		services = append(services, &service)
+	}
+
+	return services, rows.Err()
+}
+
+// Restore clears the deletion mark of a soft deleted service
+func (r *SqliteServiceRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE services
+		SET deleted_at = NULL,
+		    updated_at = ?,
+		    version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL
+	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), id}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -163,9 +268,32 @@ func (r *SqliteServiceRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// PurgeDeleted permanently removes services deleted before the given time
+// Services that are still referenced by appointments are kept
+func (r *SqliteServiceRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM services
+		WHERE deleted_at < ?
+		AND NOT EXISTS (SELECT 1 FROM appointments a WHERE a.service_id = services.id)
+	`
+	query, args := scope(ctx, query, []interface{}{before.UTC()}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
+
 // queryServices is a helper method to query services
 func (r *SqliteServiceRepository) queryServices(ctx context.Context, query string, args ...interface{}) ([]*domain.Service, error) {
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -180,6 +308,7 @@ func (r *SqliteServiceRepository) queryServices(ctx context.Context, query strin
 
 		err := rows.Scan(
 			&service.ID,
+			&service.ClinicID,
 			&service.Name,
 			&service.Description,
 			&service.DurationMinutes,
@@ -187,6 +316,7 @@ func (r *SqliteServiceRepository) queryServices(ctx context.Context, query strin
 			&isActive,
 			&createdAt,
 			&updatedAt,
+			&service.Version,
 		)
 
 		if err != nil {
@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// SqliteSessionRepository implements the SessionRepository interface using SQLite
+type SqliteSessionRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteSessionRepository creates a new instance of SqliteSessionRepository
+func NewSqliteSessionRepository(db *sql.DB) repository.SessionRepository {
+	return &SqliteSessionRepository{
+		db: db,
+	}
+}
+
+// Create stores a new session
+func (r *SqliteSessionRepository) Create(ctx context.Context, session *domain.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, user_agent, ip_address, created_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		session.ID,
+		session.UserID,
+		session.UserAgent,
+		session.IPAddress,
+		session.CreatedAt.UTC(),
+		session.LastSeenAt.UTC(),
+	)
+
+	return mapError(err)
+}
+
+// FindByID retrieves a session by its ID, or nil if there is none
+func (r *SqliteSessionRepository) FindByID(ctx context.Context, id string) (*domain.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at
+		FROM sessions
+		WHERE id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{id}, userClinic(""))
+
+	var session domain.Session
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListActiveByUser retrieves the sessions of a user that have a current refresh token
+// Each active session has exactly one: the others of its chain were used
+func (r *SqliteSessionRepository) ListActiveByUser(ctx context.Context, userID string, now time.Time) ([]*domain.Session, error) {
+	query := `
+		SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.created_at, s.last_seen_at, t.expires_at
+		FROM sessions s
+		JOIN refresh_tokens t ON t.session_id = s.id
+		WHERE s.user_id = ? AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > ?
+	`
+	query, args := scope(ctx, query, []interface{}{userID, now.UTC()}, userClinic("s."))
+	query += ` ORDER BY s.last_seen_at DESC, s.id`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		var session domain.Session
+		err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch sets last_seen_at of a session; an earlier time leaves it unchanged
+func (r *SqliteSessionRepository) Touch(ctx context.Context, id string, seenAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = ?1
+		WHERE id = ?2 AND last_seen_at < ?1
+	`
+	query, args := scope(ctx, query, []interface{}{seenAt.UTC(), id}, userClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// PurgeEnded permanently removes sessions last seen before the given time
+// that have no refresh token left
+func (r *SqliteSessionRepository) PurgeEnded(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM sessions
+		WHERE last_seen_at < ?
+		  AND NOT EXISTS (SELECT 1 FROM refresh_tokens t WHERE t.session_id = sessions.id)
+	`
+	query, args := scope(ctx, query, []interface{}{before.UTC()}, userClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,248 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/fieldcrypt"
+)
+
+// SqliteTwoFactorRepository implements the TwoFactorRepository interface using SQLite
+// TOTP secrets are encrypted with cipher before they are written
+type SqliteTwoFactorRepository struct {
+	db     *sql.DB
+	cipher *fieldcrypt.Cipher
+}
+
+// NewSqliteTwoFactorRepository creates a new instance of SqliteTwoFactorRepository
+func NewSqliteTwoFactorRepository(db *sql.DB, cipher *fieldcrypt.Cipher) repository.TwoFactorRepository {
+	return &SqliteTwoFactorRepository{
+		db:     db,
+		cipher: cipher,
+	}
+}
+
+// Create stores a new second factor, not enabled yet
+func (r *SqliteTwoFactorRepository) Create(ctx context.Context, twoFactor *domain.TwoFactor) error {
+	secret, err := r.cipher.Encrypt(twoFactor.Secret)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO two_factor (user_id, secret, last_used_step, created_at)
+		SELECT id, ?, 0, ? FROM users WHERE id = ?
+	`
+	query, args := scope(ctx, query, []interface{}{secret, twoFactor.CreatedAt.UTC(), twoFactor.UserID}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return mapError(err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return domain.ErrRelatedRecordNotFound
+	}
+
+	return nil
+}
+
+// FindByUserID retrieves the second factor of a user, or nil if there is none
+func (r *SqliteTwoFactorRepository) FindByUserID(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	query, args := scope(ctx, `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM two_factor
+		WHERE user_id = ?
+	`, []interface{}{userID}, userClinic(""))
+
+	var twoFactor domain.TwoFactor
+	var enabledAt sql.NullTime
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
+		&twoFactor.UserID,
+		&twoFactor.Secret,
+		&enabledAt,
+		&twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if twoFactor.Secret, err = r.cipher.Decrypt(twoFactor.Secret); err != nil {
+		return nil, fmt.Errorf("two-factor secret of user %s: %w", twoFactor.UserID, err)
+	}
+	twoFactor.EnabledAt = timePtr(enabledAt)
+
+	return &twoFactor, nil
+}
+
+// Enable sets enabled_at on a second factor that is not enabled yet
+// Returns false if another request enabled it first
+func (r *SqliteTwoFactorRepository) Enable(ctx context.Context, userID string, step int64, enabledAt time.Time) (bool, error) {
+	query := `
+		UPDATE two_factor
+		SET enabled_at = ?, last_used_step = ?
+		WHERE user_id = ? AND enabled_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{enabledAt.UTC(), step, userID}, userClinic(""))
+
+	return r.update(ctx, query, args)
+}
+
+// UseStep records the step of an accepted code if it is newer than the last one
+// Returns false if a code of that step or a later one was already used
+func (r *SqliteTwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `
+		UPDATE two_factor
+		SET last_used_step = ?
+		WHERE user_id = ? AND last_used_step < ?
+	`
+	query, args := scope(ctx, query, []interface{}{step, userID, step}, userClinic(""))
+
+	return r.update(ctx, query, args)
+}
+
+// Delete removes the second factor of a user together with their recovery codes
+func (r *SqliteTwoFactorRepository) Delete(ctx context.Context, userID string) error {
+	query, args := scope(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, []interface{}{userID}, userClinic(""))
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	query, args = scope(ctx, `DELETE FROM two_factor WHERE user_id = ?`, []interface{}{userID}, userClinic(""))
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// ReplaceRecoveryCodes removes the recovery codes of a user and stores new ones
+func (r *SqliteTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*domain.RecoveryCode) error {
+	query, args := scope(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, []interface{}{userID}, userClinic(""))
+	if _, err := conn(ctx, r.db).ExecContext(ctx, query, args...); err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		_, err := conn(ctx, r.db).ExecContext(
+			ctx,
+			`INSERT INTO recovery_codes (id, user_id, code_hash, created_at) VALUES (?, ?, ?, ?)`,
+			code.ID,
+			userID,
+			code.CodeHash,
+			code.CreatedAt.UTC(),
+		)
+		if err != nil {
+			return mapError(err)
+		}
+	}
+
+	return nil
+}
+
+// UseRecoveryCode sets used_at on an unused recovery code of a user
+// Returns false if there is no such code
+func (r *SqliteTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE recovery_codes
+		SET used_at = ?
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`
+	query, args := scope(ctx, query, []interface{}{usedAt.UTC(), userID, codeHash}, userClinic(""))
+
+	return r.update(ctx, query, args)
+}
+
+// CountRecoveryCodes returns how many unused recovery codes a user has left
+func (r *SqliteTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query, args := scope(ctx, `SELECT COUNT(*) FROM recovery_codes WHERE user_id = ? AND used_at IS NULL`, []interface{}{userID}, userClinic(""))
+
+	var count int
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Reencrypt rewrites the secrets that are not sealed with the primary key
+// Rows are read in batches by user ID and each one is updated on its own, so
+// an interrupted run can simply be started again
+func (r *SqliteTwoFactorRepository) Reencrypt(ctx context.Context) (int, error) {
+	type encryptedRow struct {
+		userID, secret string
+	}
+
+	rewritten := 0
+	lastID := ""
+	for {
+		query, args := scope(ctx, `
+			SELECT user_id, secret
+			FROM two_factor
+			WHERE user_id > ?
+		`, []interface{}{lastID}, userClinic(""))
+
+		rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY user_id LIMIT ?", append(args, reencryptBatch)...)
+		if err != nil {
+			return rewritten, err
+		}
+
+		var batch []encryptedRow
+		for rows.Next() {
+			var row encryptedRow
+			if err := rows.Scan(&row.userID, &row.secret); err != nil {
+				rows.Close()
+				return rewritten, err
+			}
+			batch = append(batch, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+		if len(batch) == 0 {
+			return rewritten, nil
+		}
+
+		for _, row := range batch {
+			lastID = row.userID
+
+			values, err := rewrap(r.cipher, row.secret)
+			if err != nil {
+				return rewritten, fmt.Errorf("two-factor secret of user %s: %w", row.userID, err)
+			}
+			if values == nil {
+				continue
+			}
+
+			if _, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE two_factor SET secret = ? WHERE user_id = ?`, values[0], row.userID); err != nil {
+				return rewritten, err
+			}
+			rewritten++
+		}
+	}
+}
+
+// update runs a conditional UPDATE and reports whether it changed a row
+func (r *SqliteTwoFactorRepository) update(ctx context.Context, query string, args []interface{}) (bool, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
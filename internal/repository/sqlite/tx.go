@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"version-1-0/internal/repository"
+)
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// execer is satisfied by both *sql.DB and *sql.Tx so repository queries
+// run inside the transaction carried by the context when there is one
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SqliteTxManager implements the TxManager interface using SQLite transactions
+// Transactions start with BEGIN IMMEDIATE (see buildDSN), which takes the
+// database write lock up front, so units of work never interleave their writes
+type SqliteTxManager struct {
+	db *sql.DB
+}
+
+// NewSqliteTxManager creates a new instance of SqliteTxManager
+func NewSqliteTxManager(db *sql.DB) repository.TxManager {
+	return &SqliteTxManager{
+		db: db,
+	}
+}
+
+// WithinTx runs fn inside a transaction, committing if it returns nil
+func (m *SqliteTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// conn returns the transaction carried by ctx, or the database when there is none
+func conn(ctx context.Context, db *sql.DB) execer {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// SqliteUsedTokenRepository implements the UsedTokenRepository interface using SQLite
+type SqliteUsedTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSqliteUsedTokenRepository creates a new instance of SqliteUsedTokenRepository
+func NewSqliteUsedTokenRepository(db *sql.DB) repository.UsedTokenRepository {
+	return &SqliteUsedTokenRepository{
+		db: db,
+	}
+}
+
+// Use records that the token with the given ID was used
+// Returns false if it was used before
+func (r *SqliteUsedTokenRepository) Use(ctx context.Context, id string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO used_tokens (id, expires_at)
+		VALUES (?, ?)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, expiresAt.UTC())
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// PurgeExpired permanently removes the IDs of tokens that expired before the given time
+func (r *SqliteUsedTokenRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM used_tokens WHERE expires_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rowsAffected), nil
+}
@@ -22,78 +22,64 @@ func NewSqliteUserRepository(db *sql.DB) repository.UserRepository {
 	}
 }
 
-// GetDB returns the database connection for transaction management
-func (r *SqliteUserRepository) GetDB() *sql.DB {
-	return r.db
-}
-
 // Create inserts a new user into the database
 func (r *SqliteUserRepository) Create(ctx context.Context, user *domain.User) error {
-	return r.CreateWithTx(ctx, nil, user)
-}
-
-// CreateWithTx inserts a new user using a transaction or database connection
-func (r *SqliteUserRepository) CreateWithTx(ctx context.Context, tx *sql.Tx, user *domain.User) error {
 	query := `
-		INSERT INTO users (id, email, password_hash, first_name, last_name, phone, role, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO users (id, clinic_id, email, password_hash, first_name, last_name, search_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	var err error
-	if tx != nil {
-		_, err = tx.ExecContext(
-			ctx,
-			query,
-			user.ID,
-			user.Email,
-			user.PasswordHash,
-			user.FirstName,
-			user.LastName,
-			user.Phone,
-			user.Role,
-			user.IsActive,
-			user.CreatedAt,
-			user.UpdatedAt,
-		)
-	} else {
-		_, err = r.db.ExecContext(
-			ctx,
-			query,
-			user.ID,
-			user.Email,
-			user.PasswordHash,
-			user.FirstName,
-			user.LastName,
-			user.Phone,
-			user.Role,
-			user.IsActive,
-			user.CreatedAt,
-			user.UpdatedAt,
-		)
-	}
-
+	clinicID, err := repository.ClinicFor(ctx, user.ClinicID)
 	if err != nil {
 		return err
 	}
+	user.ClinicID = clinicID
 
-	return nil
+	if user.Version == 0 {
+		user.Version = 1
+	}
+
+	_, err = conn(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		user.ID,
+		user.ClinicID,
+		user.Email,
+		user.PasswordHash,
+		user.FirstName,
+		user.LastName,
+		searchWords(user.FirstName+" "+user.LastName),
+		user.Phone,
+		user.Role,
+		user.IsActive,
+		utcTime(user.EmailVerifiedAt),
+		utcTime(user.VerificationSentAt),
+		user.CreatedAt.UTC(),
+		user.UpdatedAt.UTC(),
+		user.Version,
+	)
+
+	return mapError(err)
 }
 
 // FindByID retrieves a user by their unique identifier
 // Returns nil if the user is not found
 func (r *SqliteUserRepository) FindByID(ctx context.Context, id string) (*domain.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, role, is_active, created_at, updated_at
+		SELECT id, clinic_id, email, password_hash, first_name, last_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version
 		FROM users
-		WHERE id = $1
+		WHERE id = ? AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{id}, ownClinic(""))
 
 	var user domain.User
 	var isActive bool
+	var emailVerifiedAt, verificationSentAt sql.NullTime
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&user.ID,
+		&user.ClinicID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.FirstName,
@@ -101,8 +87,11 @@ func (r *SqliteUserRepository) FindByID(ctx context.Context, id string) (*domain
 		&user.Phone,
 		&user.Role,
 		&isActive,
+		&emailVerifiedAt,
+		&verificationSentAt,
 		&createdAt,
 		&updatedAt,
+		&user.Version,
 	)
 
 	if err != nil {
@@ -116,6 +105,8 @@ func (r *SqliteUserRepository) FindByID(ctx context.Context, id string) (*domain
 	user.IsActive = isActive
 	user.CreatedAt = createdAt
 	user.UpdatedAt = updatedAt
+	user.EmailVerifiedAt = timePtr(emailVerifiedAt)
+	user.VerificationSentAt = timePtr(verificationSentAt)
 
 	return &user, nil
 }
@@ -124,17 +115,20 @@ func (r *SqliteUserRepository) FindByID(ctx context.Context, id string) (*domain
 // Returns nil if the user is not found
 func (r *SqliteUserRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, role, is_active, created_at, updated_at
+		SELECT id, clinic_id, email, password_hash, first_name, last_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version
 		FROM users
-		WHERE email = $1
+		WHERE email = ? AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{email}, ownClinic(""))
 
 	var user domain.User
 	var isActive bool
+	var emailVerifiedAt, verificationSentAt sql.NullTime
 	var createdAt, updatedAt time.Time
 
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(
 		&user.ID,
+		&user.ClinicID,
 		&user.Email,
 		&user.PasswordHash,
 		&user.FirstName,
@@ -142,8 +136,11 @@ func (r *SqliteUserRepository) FindByEmail(ctx context.Context, email string) (*
 		&user.Phone,
 		&user.Role,
 		&isActive,
+		&emailVerifiedAt,
+		&verificationSentAt,
 		&createdAt,
 		&updatedAt,
+		&user.Version,
 	)
 
 	if err != nil {
@@ -157,34 +154,47 @@ func (r *SqliteUserRepository) FindByEmail(ctx context.Context, email string) (*
 	user.IsActive = isActive
 	user.CreatedAt = createdAt
 	user.UpdatedAt = updatedAt
+	user.EmailVerifiedAt = timePtr(emailVerifiedAt)
+	user.VerificationSentAt = timePtr(verificationSentAt)
 
 	return &user, nil
 }
 
-// Update modifies an existing user in the database
+// EmailExists reports whether any user holds the email, ignoring the clinic
+// scope and deleted_at like the UNIQUE constraint on users.email
+func (r *SqliteUserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	var exists bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM users WHERE email = ?)`, email).Scan(&exists)
+	return exists, err
+}
+
+// Update modifies an existing user if its version still matches the stored one
+// Returns domain.ErrVersionConflict if another update got there first
 func (r *SqliteUserRepository) Update(ctx context.Context, user *domain.User) error {
 	query := `
 		UPDATE users
-		SET email = $1, password_hash = $2, first_name = $3, last_name = $4, phone = $5, role = $6, is_active = $7, updated_at = $8
-		WHERE id = $9
+		SET email = ?, password_hash = ?, first_name = ?, last_name = ?, search_name = ?, phone = ?, role = ?, is_active = ?, email_verified_at = ?, updated_at = ?, version = version + 1
+		WHERE id = ? AND version = ? AND deleted_at IS NULL
 	`
-
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
+	query, args := scope(ctx, query, []interface{}{
 		user.Email,
 		user.PasswordHash,
 		user.FirstName,
 		user.LastName,
+		searchWords(user.FirstName + " " + user.LastName),
 		user.Phone,
 		user.Role,
 		user.IsActive,
-		user.UpdatedAt,
+		utcTime(user.EmailVerifiedAt),
+		user.UpdatedAt.UTC(),
 		user.ID,
-	)
+		user.Version,
+	}, ownClinic(""))
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 
 	if err != nil {
-		return err
+		return mapError(err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -193,22 +203,33 @@ func (r *SqliteUserRepository) Update(ctx context.Context, user *domain.User) er
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("user not found")
+		return versionMismatch(ctx, r.db, `SELECT 1 FROM users WHERE id = ? AND deleted_at IS NULL`, user.ID, ownClinic(""), errors.New("user not found"))
 	}
 
+	user.Version++
 	return nil
 }
 
-// Delete performs a soft delete by marking the user as inactive
+// MarkVerificationSent records when a verification email was last sent
+func (r *SqliteUserRepository) MarkVerificationSent(ctx context.Context, id string, sentAt time.Time) error {
+	query, args := scope(ctx, `UPDATE users SET verification_sent_at = ? WHERE id = ? AND deleted_at IS NULL`, []interface{}{sentAt.UTC(), id}, ownClinic(""))
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	return err
+}
+
+// Delete performs a soft delete by setting deleted_at
 func (r *SqliteUserRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		UPDATE users
-		SET is_active = FALSE,
-		    updated_at = $1
-		WHERE id = $2
+		SET deleted_at = ?1,
+		    updated_at = ?1,
+		    version = version + 1
+		WHERE id = ?2 AND deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), id}, ownClinic(""))
 
-	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -226,15 +247,20 @@ func (r *SqliteUserRepository) Delete(ctx context.Context, id string) error {
 }
 
 // List retrieves a paginated list of users
-func (r *SqliteUserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
+func (r *SqliteUserRepository) List(ctx context.Context, page repository.PageRequest) ([]*domain.User, error) {
 	query := `
-		SELECT id, email, password_hash, first_name, last_name, phone, role, is_active, created_at, updated_at
+		SELECT id, clinic_id, email, password_hash, first_name, last_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version
 		FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		WHERE deleted_at IS NULL
 	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	query, args, err := keysetPage(query, args, page, userSortExpr(page.Sort, ""), "id")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -245,10 +271,12 @@ func (r *SqliteUserRepository) List(ctx context.Context, limit, offset int) ([]*
 	for rows.Next() {
 		var user domain.User
 		var isActive bool
+		var emailVerifiedAt, verificationSentAt sql.NullTime
 		var createdAt, updatedAt time.Time
 
 		err := rows.Scan(
 			&user.ID,
+			&user.ClinicID,
 			&user.Email,
 			&user.PasswordHash,
 			&user.FirstName,
@@ -256,8 +284,11 @@ func (r *SqliteUserRepository) List(ctx context.Context, limit, offset int) ([]*
 			&user.Phone,
 			&user.Role,
 			&isActive,
+			&emailVerifiedAt,
+			&verificationSentAt,
 			&createdAt,
 			&updatedAt,
+			&user.Version,
 		)
 
 		if err != nil {
@@ -268,6 +299,8 @@ func (r *SqliteUserRepository) List(ctx context.Context, limit, offset int) ([]*
 		user.IsActive = isActive
 		user.CreatedAt = createdAt
 		user.UpdatedAt = updatedAt
+		user.EmailVerifiedAt = timePtr(emailVerifiedAt)
+		user.VerificationSentAt = timePtr(verificationSentAt)
 
 		users = append(users, &user)
 	}
@@ -279,22 +312,16 @@ func (r *SqliteUserRepository) List(ctx context.Context, limit, offset int) ([]*
 	return users, nil
 }
 
-// FindDoctorsBySpecialty retrieves all active doctors filtered by specialty
-func (r *SqliteUserRepository) FindDoctorsBySpecialty(ctx context.Context, specialty string) ([]*domain.User, error) {
+// ListDeleted retrieves soft deleted users, most recently deleted first
+func (r *SqliteUserRepository) ListDeleted(ctx context.Context) ([]*domain.User, error) {
 	query := `
-		SELECT u.id, u.email, u.password_hash, u.first_name, u.last_name, u.phone, u.role, u.is_active, u.created_at, u.updated_at
-		FROM users u
-		INNER JOIN doctors d ON u.id = d.user_id
-		WHERE u.role = 'doctor'
-		AND u.is_active = TRUE
-		AND LOWER(d.specialty) LIKE LOWER($1)
-		ORDER BY u.last_name ASC, u.first_name ASC
+		SELECT id, clinic_id, email, password_hash, first_name, last_name, phone, role, is_active, email_verified_at, verification_sent_at, created_at, updated_at, version, deleted_at
+		FROM users
+		WHERE deleted_at IS NOT NULL
 	`
+	query, args := scope(ctx, query, nil, ownClinic(""))
 
-	// Use LIKE with % for flexible matching
-	searchPattern := "%" + specialty + "%"
-
-	rows, err := r.db.QueryContext(ctx, query, searchPattern)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query+" ORDER BY deleted_at DESC", args...)
 	if err != nil {
 		return nil, err
 	}
@@ -303,89 +330,106 @@ func (r *SqliteUserRepository) FindDoctorsBySpecialty(ctx context.Context, speci
 	var users []*domain.User
 	for rows.Next() {
 		var user domain.User
-		var isActive bool
-		var createdAt, updatedAt time.Time
+		var emailVerifiedAt, verificationSentAt sql.NullTime
+		var deletedAt time.Time
 
 		err := rows.Scan(
 			&user.ID,
+			&user.ClinicID,
 			&user.Email,
 			&user.PasswordHash,
 			&user.FirstName,
 			&user.LastName,
 			&user.Phone,
 			&user.Role,
-			&isActive,
-			&createdAt,
-			&updatedAt,
+			&user.IsActive,
+			&emailVerifiedAt,
+			&verificationSentAt,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Version,
+			&deletedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		user.IsActive = isActive
-		user.CreatedAt = createdAt
-		user.UpdatedAt = updatedAt
-
+		user.EmailVerifiedAt = timePtr(emailVerifiedAt)
+		user.VerificationSentAt = timePtr(verificationSentAt)
+		user.DeletedAt = &deletedAt
 		users = append(users, &user)
 	}
 
 	return users, rows.Err()
 }
 
-// GetAllDoctors retrieves all active doctors that have a complete profile
-func (r *SqliteUserRepository) GetAllDoctors(ctx context.Context) ([]*domain.User, error) {
+// Restore clears the deletion mark of a soft deleted user
+func (r *SqliteUserRepository) Restore(ctx context.Context, id string) error {
 	query := `
-		SELECT u.id, u.email, u.password_hash, u.first_name, u.last_name, u.phone, u.role, u.is_active, u.created_at, u.updated_at
-		FROM users u
-		INNER JOIN doctors d ON u.id = d.user_id
-		WHERE u.role = 'doctor' AND u.is_active = TRUE
-		ORDER BY u.last_name ASC, u.first_name ASC
+		UPDATE users
+		SET deleted_at = NULL,
+		    updated_at = ?,
+		    version = version + 1
+		WHERE id = ? AND deleted_at IS NOT NULL
 	`
+	query, args := scope(ctx, query, []interface{}{time.Now().UTC(), id}, ownClinic(""))
 
-	rows, err := r.db.QueryContext(ctx, query)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
 
-	var users []*domain.User
-	for rows.Next() {
-		var user domain.User
-		var isActive bool
-		var createdAt, updatedAt time.Time
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.PasswordHash,
-			&user.FirstName,
-			&user.LastName,
-			&user.Phone,
-			&user.Role,
-			&isActive,
-			&createdAt,
-			&updatedAt,
+	if rowsAffected == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// PurgeDeleted permanently removes users deleted before the given time
+// Their patient or doctor profile goes with them; users that still appear in
+// appointments are kept so clinical history is never lost
+func (r *SqliteUserRepository) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		DELETE FROM users
+		WHERE deleted_at < ?
+		AND NOT EXISTS (
+			SELECT 1 FROM appointments a
+			INNER JOIN patients p ON p.id = a.patient_id
+			WHERE p.user_id = users.id
 		)
-		if err != nil {
-			return nil, err
-		}
+		AND NOT EXISTS (
+			SELECT 1 FROM appointments a
+			INNER JOIN doctors d ON d.id = a.doctor_id
+			WHERE d.user_id = users.id
+		)
+	`
+	query, args := scope(ctx, query, []interface{}{before.UTC()}, ownClinic(""))
 
-		user.IsActive = isActive
-		user.CreatedAt = createdAt
-		user.UpdatedAt = updatedAt
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
 
-		users = append(users, &user)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
 	}
 
-	return users, rows.Err()
+	return int(rowsAffected), nil
 }
 
 // FindDoctorIDByUserID returns the doctor.id for a given user_id
 func (r *SqliteUserRepository) FindDoctorIDByUserID(ctx context.Context, userID string) (string, error) {
-	query := `SELECT id FROM doctors WHERE user_id = $1`
+	query, args := scope(ctx, `SELECT id FROM doctors WHERE user_id = ? AND deleted_at IS NULL`, []interface{}{userID}, userClinic(""))
 
 	var doctorID string
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&doctorID)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&doctorID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", errors.New("doctor not found")
@@ -398,10 +442,10 @@ func (r *SqliteUserRepository) FindDoctorIDByUserID(ctx context.Context, userID
 
 // FindPatientIDByUserID returns the patient.id for a given user_id
 func (r *SqliteUserRepository) FindPatientIDByUserID(ctx context.Context, userID string) (string, error) {
-	query := `SELECT id FROM patients WHERE user_id = $1`
+	query, args := scope(ctx, `SELECT id FROM patients WHERE user_id = ?`, []interface{}{userID}, userClinic(""))
 
 	var patientID string
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&patientID)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&patientID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return "", errors.New("patient not found")
@@ -414,10 +458,10 @@ func (r *SqliteUserRepository) FindPatientIDByUserID(ctx context.Context, userID
 
 // CountByRole counts users by role
 func (r *SqliteUserRepository) CountByRole(ctx context.Context, role string) (int, error) {
-	query := `SELECT COUNT(*) FROM users WHERE role = $1 AND is_active = TRUE`
+	query, args := scope(ctx, `SELECT COUNT(*) FROM users WHERE role = ? AND is_active = TRUE AND deleted_at IS NULL`, []interface{}{role}, ownClinic(""))
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, role).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
@@ -427,13 +471,37 @@ func (r *SqliteUserRepository) CountByRole(ctx context.Context, role string) (in
 
 // CountAllActive counts all active users
 func (r *SqliteUserRepository) CountAllActive(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM users WHERE is_active = TRUE`
+	query, args := scope(ctx, `SELECT COUNT(*) FROM users WHERE is_active = TRUE AND deleted_at IS NULL`, nil, ownClinic(""))
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
 	if err != nil {
 		return 0, err
 	}
 
 	return count, nil
 }
+
+// utcTime converts an optional time to UTC for storage, keeping nil as NULL
+func utcTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+// timePtr returns the time of a nullable column, or nil if it is NULL
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+// nullIfEmpty returns nil for empty strings so optional columns are stored as NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+)
+
+// ErrOtherClinic is returned when a write names a clinic other than the one
+// the context is scoped to
+var ErrOtherClinic = errors.New("record belongs to another clinic")
+
+// clinicKey is the context key under which the clinic scope is stored
+type clinicKey struct{}
+
+// WithClinic scopes every repository call made with the returned context to
+// one clinic: reads only see that clinic's records and writes can only touch them
+// The HTTP layer scopes every request; background jobs and command line tools
+// run unscoped and see every clinic
+func WithClinic(ctx context.Context, clinicID string) context.Context {
+	return context.WithValue(ctx, clinicKey{}, clinicID)
+}
+
+// ClinicScope returns the clinic ctx is scoped to, or false for an unscoped context
+func ClinicScope(ctx context.Context) (string, bool) {
+	clinicID, ok := ctx.Value(clinicKey{}).(string)
+	return clinicID, ok && clinicID != ""
+}
+
+// ClinicFor returns the clinic a new record must be stored under
+// A scoped context always decides, and a record naming a different clinic is
+// rejected; unscoped callers get the clinic the record names, or the default
+// clinic when it names none
+func ClinicFor(ctx context.Context, clinicID string) (string, error) {
+	if scope, ok := ClinicScope(ctx); ok {
+		if clinicID != "" && clinicID != scope {
+			return "", ErrOtherClinic
+		}
+		return scope, nil
+	}
+	if clinicID == "" {
+		return domain.DefaultClinicID, nil
+	}
+	return clinicID, nil
+}
@@ -0,0 +1,37 @@
+// Package requestctx holds the keys of the values the HTTP middleware stores in
+// the context of each request, so the handlers and the use cases can read them
+// without the use cases depending on the delivery layer
+package requestctx
+
+import "context"
+
+// Key is a custom type for context keys to avoid collisions
+type Key string
+
+// UserIDKey is the context key for storing the authenticated user ID
+const UserIDKey Key = "user_id"
+
+// RoleKey is the context key for storing the user role
+const RoleKey Key = "user_role"
+
+// SessionIDKey is the context key for storing the session the access token belongs to
+const SessionIDKey Key = "session_id"
+
+// APIKeyScopesKey is the context key for storing the permissions of the API key
+// a request was authenticated with
+const APIKeyScopesKey Key = "api_key_scopes"
+
+// RequestIDKey is the context key for storing the request ID
+const RequestIDKey Key = "request_id"
+
+// ClientIPKey is the context key for storing the client IP address
+const ClientIPKey Key = "client_ip"
+
+// UserAgentKey is the context key for storing the User-Agent header of the client
+const UserAgentKey Key = "user_agent"
+
+// String returns a string stored in ctx, or "" if there is none
+func String(ctx context.Context, key Key) string {
+	value, _ := ctx.Value(key).(string)
+	return value
+}
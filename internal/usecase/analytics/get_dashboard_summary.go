@@ -25,36 +25,38 @@ func NewGetDashboardSummaryUseCase(
 }
 
 // Execute retrieves the dashboard summary statistics
-func (uc *GetDashboardSummaryUseCase) Execute(ctx context.Context) (*DashboardSummary, error) {
+// A non-empty locationID restricts the appointment and revenue figures to that
+// location; patient and doctor counts always cover the whole clinic
+func (uc *GetDashboardSummaryUseCase) Execute(ctx context.Context, locationID string) (*DashboardSummary, error) {
 	summary := &DashboardSummary{}
 
 	// Count all appointments
-	totalAppointments, err := uc.appointmentRepo.CountAll(ctx)
+	totalAppointments, err := uc.appointmentRepo.CountAll(ctx, locationID)
 	if err != nil {
 		return nil, err
 	}
 	summary.TotalAppointments = totalAppointments
 
 	// Count appointments by status
-	pending, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusPending))
+	pending, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusPending), locationID)
 	if err != nil {
 		return nil, err
 	}
 	summary.PendingAppointments = pending
 
-	confirmed, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusConfirmed))
+	confirmed, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusConfirmed), locationID)
 	if err != nil {
 		return nil, err
 	}
 	summary.ConfirmedAppointments = confirmed
 
-	completed, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusCompleted))
+	completed, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusCompleted), locationID)
 	if err != nil {
 		return nil, err
 	}
 	summary.CompletedAppointments = completed
 
-	cancelled, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusCancelled))
+	cancelled, err := uc.appointmentRepo.CountByStatus(ctx, string(domain.StatusCancelled), locationID)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +76,7 @@ func (uc *GetDashboardSummaryUseCase) Execute(ctx context.Context) (*DashboardSu
 	summary.TotalDoctors = doctors
 
 	// Calculate total revenue
-	revenue, err := uc.appointmentRepo.GetTotalRevenue(ctx)
+	revenue, err := uc.appointmentRepo.GetTotalRevenue(ctx, locationID)
 	if err != nil {
 		return nil, err
 	}
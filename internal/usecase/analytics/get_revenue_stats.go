@@ -20,10 +20,10 @@ func NewGetRevenueStatsUseCase(
 	}
 }
 
-// Execute retrieves revenue statistics by service
-func (uc *GetRevenueStatsUseCase) Execute(ctx context.Context) ([]*RevenueByService, error) {
+// Execute retrieves revenue statistics by service, at one location if locationID is set
+func (uc *GetRevenueStatsUseCase) Execute(ctx context.Context, locationID string) ([]*RevenueByService, error) {
 	// Get revenue by service from repository
-	revenueMap, err := uc.appointmentRepo.GetRevenueByService(ctx)
+	revenueMap, err := uc.appointmentRepo.GetRevenueByService(ctx, locationID)
 	if err != nil {
 		return nil, err
 	}
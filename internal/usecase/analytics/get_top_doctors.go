@@ -23,14 +23,14 @@ func NewGetTopDoctorsUseCase(
 	}
 }
 
-// Execute retrieves top doctors by number of appointments
-func (uc *GetTopDoctorsUseCase) Execute(ctx context.Context, limit int) ([]*TopDoctor, error) {
+// Execute retrieves top doctors by number of appointments, at one location if locationID is set
+func (uc *GetTopDoctorsUseCase) Execute(ctx context.Context, limit int, locationID string) ([]*TopDoctor, error) {
 	if limit <= 0 {
 		limit = 10 // Default to top 10
 	}
 
 	// Get top doctors from repository
-	doctorsData, err := uc.appointmentRepo.GetTopDoctors(ctx, limit)
+	doctorsData, err := uc.appointmentRepo.GetTopDoctors(ctx, limit, locationID)
 	if err != nil {
 		return nil, err
 	}
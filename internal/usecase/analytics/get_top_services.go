@@ -20,14 +20,14 @@ func NewGetTopServicesUseCase(
 	}
 }
 
-// Execute retrieves most popular services
-func (uc *GetTopServicesUseCase) Execute(ctx context.Context, limit int) ([]*TopService, error) {
+// Execute retrieves most popular services, at one location if locationID is set
+func (uc *GetTopServicesUseCase) Execute(ctx context.Context, limit int, locationID string) ([]*TopService, error) {
 	if limit <= 0 {
 		limit = 10 // Default to top 10
 	}
 
 	// Get top services from repository
-	servicesData, err := uc.appointmentRepo.GetTopServices(ctx, limit)
+	servicesData, err := uc.appointmentRepo.GetTopServices(ctx, limit, locationID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,139 @@
+package apikey_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/usecase/apikey"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
+)
+
+// fixture holds the use cases of API keys on one memory store, with a
+// receptionist that may manage keys
+type fixture struct {
+	ctx          context.Context
+	roleRepo     repository.RoleRepository
+	userRepo     repository.UserRepository
+	create       *apikey.CreateAPIKeyUseCase
+	authenticate *apikey.AuthenticateAPIKeyUseCase
+	receptionist *domain.User
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	roleRepo := memory.NewMemoryRoleRepository(store)
+	userRepo := memory.NewMemoryUserRepository(store)
+	apiKeyRepo := memory.NewMemoryAPIKeyRepository(store)
+	authorizer := role.NewAuthorizer(roleRepo)
+
+	f := &fixture{
+		ctx:          ctx,
+		roleRepo:     roleRepo,
+		userRepo:     userRepo,
+		create:       apikey.NewCreateAPIKeyUseCase(apiKeyRepo, authorizer, memory.NewMemoryTxManager(store), audit.NewRecorder(memory.NewMemoryAuditRepository(store))),
+		authenticate: apikey.NewAuthenticateAPIKeyUseCase(apiKeyRepo, userRepo, authorizer),
+		receptionist: &domain.User{
+			ID:           "receptionist-user",
+			Email:        "recepcion@clinica.test",
+			PasswordHash: "hash",
+			FirstName:    "Rosa",
+			LastName:     "Quispe",
+			Role:         domain.RoleReceptionist,
+			IsActive:     true,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		},
+	}
+	if err := userRepo.Create(ctx, f.receptionist); err != nil {
+		t.Fatalf("create receptionist: %v", err)
+	}
+	f.setReceptionistPermissions(t, append(domain.DefaultPermissions(domain.RoleReceptionist), domain.PermissionAPIKeysManage))
+	return f
+}
+
+func (f *fixture) setReceptionistPermissions(t *testing.T, permissions []domain.Permission) {
+	t.Helper()
+	permissions = slices.Clone(permissions)
+	domain.SortPermissions(permissions)
+	definition := &domain.RoleDefinition{Role: domain.RoleReceptionist, Permissions: permissions, UpdatedAt: time.Now()}
+	if err := f.roleRepo.Save(f.ctx, definition); err != nil {
+		t.Fatalf("save receptionist role: %v", err)
+	}
+}
+
+func TestCreateAPIKeyOnlyGrantsHeldScopes(t *testing.T) {
+	f := newFixture(t)
+
+	tests := []struct {
+		name        string
+		creatorRole domain.UserRole
+		scopes      []domain.Permission
+		wantErr     string
+	}{
+		{"held scopes", domain.RoleReceptionist, []domain.Permission{domain.PermissionAppointmentsCreateAny, domain.PermissionPatientsRead}, ""},
+		{"scope the role lacks", domain.RoleReceptionist, []domain.Permission{domain.PermissionPatientsRead, domain.PermissionAnalyticsRead}, "permission not held: analytics:read"},
+		{"admin", domain.RoleAdmin, []domain.Permission{domain.PermissionAnalyticsRead, domain.PermissionAuditRead}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := apikey.CreateAPIKeyRequest{Name: tt.name}
+			for _, scope := range tt.scopes {
+				req.Scopes = append(req.Scopes, string(scope))
+			}
+
+			_, err := f.create.Execute(f.ctx, f.receptionist.ID, string(tt.creatorRole), req)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Execute error = %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateAPIKeyFollowsCreatorRole(t *testing.T) {
+	f := newFixture(t)
+
+	created, err := f.create.Execute(f.ctx, f.receptionist.ID, string(domain.RoleReceptionist), apikey.CreateAPIKeyRequest{
+		Name:   "Kiosco",
+		Scopes: []string{string(domain.PermissionAppointmentsReadAny), string(domain.PermissionPatientsRead)},
+	})
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+
+	key, err := f.authenticate.Execute(f.ctx, created.Key)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if want := []domain.Permission{domain.PermissionAppointmentsReadAny, domain.PermissionPatientsRead}; !slices.Equal(key.Scopes, want) {
+		t.Errorf("scopes = %v, want %v", key.Scopes, want)
+	}
+
+	// The clinic takes patients:read from reception after the key was created
+	f.setReceptionistPermissions(t, []domain.Permission{domain.PermissionAppointmentsReadAny, domain.PermissionAPIKeysManage})
+	key, err = f.authenticate.Execute(f.ctx, created.Key)
+	if err != nil {
+		t.Fatalf("authenticate after the role changed: %v", err)
+	}
+	if want := []domain.Permission{domain.PermissionAppointmentsReadAny}; !slices.Equal(key.Scopes, want) {
+		t.Errorf("scopes after the role changed = %v, want %v", key.Scopes, want)
+	}
+
+	f.receptionist.IsActive = false
+	if err := f.userRepo.Update(f.ctx, f.receptionist); err != nil {
+		t.Fatalf("deactivate creator: %v", err)
+	}
+	if _, err := f.authenticate.Execute(f.ctx, created.Key); err == nil || err.Error() != "api key creator is no longer active" {
+		t.Errorf("authenticate with a deactivated creator error = %v, want %q", err, "api key creator is no longer active")
+	}
+}
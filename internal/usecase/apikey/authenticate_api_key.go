@@ -0,0 +1,112 @@
+package apikey
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/auth"
+	"version-1-0/internal/usecase/role"
+)
+
+// errInvalidAPIKey is returned for keys that are malformed or do not exist
+var errInvalidAPIKey = errors.New("invalid api key")
+
+// errCreatorInactive is returned for keys whose creator can no longer log in
+var errCreatorInactive = errors.New("api key creator is no longer active")
+
+// AuthenticateAPIKeyUseCase resolves the API key sent with a request
+// It implements middleware.APIKeyAuthenticator
+type AuthenticateAPIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	userRepo   repository.UserRepository
+	authorizer *role.Authorizer
+}
+
+// NewAuthenticateAPIKeyUseCase creates a new instance of AuthenticateAPIKeyUseCase
+func NewAuthenticateAPIKeyUseCase(apiKeyRepo repository.APIKeyRepository, userRepo repository.UserRepository, authorizer *role.Authorizer) *AuthenticateAPIKeyUseCase {
+	return &AuthenticateAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		authorizer: authorizer,
+	}
+}
+
+// Execute returns the key a request was made with and records its use
+// Revoked and expired keys are refused; the key is found by its prefix in any
+// clinic and then compared with the stored hash. The returned key only keeps
+// the scopes the role of its creator still has, and keys whose creator was
+// deleted or deactivated are refused
+func (uc *AuthenticateAPIKeyUseCase) Execute(ctx context.Context, rawKey string) (*domain.APIKey, error) {
+	rest, ok := strings.CutPrefix(rawKey, domain.APIKeyPrefix)
+	if !ok {
+		return nil, errInvalidAPIKey
+	}
+	lookupID, secret, ok := strings.Cut(rest, "_")
+	if !ok || lookupID == "" || secret == "" {
+		return nil, errInvalidAPIKey
+	}
+
+	key, err := uc.apiKeyRepo.FindByPrefix(repository.WithClinic(ctx, ""), domain.APIKeyPrefix+lookupID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || subtle.ConstantTimeCompare([]byte(auth.HashToken(rawKey)), []byte(key.KeyHash)) != 1 {
+		return nil, errInvalidAPIKey
+	}
+
+	now := time.Now()
+	switch key.Status(now) {
+	case domain.APIKeyRevoked:
+		return nil, errors.New("api key has been revoked")
+	case domain.APIKeyExpired:
+		return nil, errors.New("api key has expired")
+	}
+
+	clinicCtx := repository.WithClinic(ctx, key.ClinicID)
+	if key.Scopes, err = uc.creatorScopes(clinicCtx, key); err != nil {
+		return nil, err
+	}
+
+	ip, _ := ctx.Value(requestctx.ClientIPKey).(string)
+	if err := uc.apiKeyRepo.RecordUse(clinicCtx, key.ID, now, ip); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+	key.LastUsedIP = ip
+
+	return key, nil
+}
+
+// creatorScopes returns the scopes of the key the role of its creator has now
+// A role that lost a permission after the key was created takes it from the
+// key too
+func (uc *AuthenticateAPIKeyUseCase) creatorScopes(ctx context.Context, key *domain.APIKey) ([]domain.Permission, error) {
+	if key.CreatedBy == "" {
+		return nil, errCreatorInactive
+	}
+	creator, err := uc.userRepo.FindByID(ctx, key.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if creator == nil || !creator.IsActive {
+		return nil, errCreatorInactive
+	}
+
+	scopes := make([]domain.Permission, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		can, err := uc.authorizer.Can(ctx, string(creator.Role), scope)
+		if err != nil {
+			return nil, err
+		}
+		if can {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,135 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
+	"version-1-0/internal/usecase/role"
+)
+
+// lookupIDAlphabet holds the characters of the lookup ID of a key
+// It has 32 characters so every random byte maps to one without bias
+const lookupIDAlphabet = "abcdefghijklmnopqrstuvwxyz234567"
+
+// lookupIDLength is the number of characters of the lookup ID of a key
+const lookupIDLength = 8
+
+// CreateAPIKeyUseCase handles the business logic for creating an API key
+type CreateAPIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	authorizer *role.Authorizer
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewCreateAPIKeyUseCase creates a new instance of CreateAPIKeyUseCase
+func NewCreateAPIKeyUseCase(
+	apiKeyRepo repository.APIKeyRepository,
+	authorizer *role.Authorizer,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *CreateAPIKeyUseCase {
+	return &CreateAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		authorizer: authorizer,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute creates an API key of the clinic with the given scopes
+// Every scope must be a permission the role of the creator has, so a key never
+// does more than its creator could. The response carries the key itself,
+// which cannot be retrieved afterwards
+func (uc *CreateAPIKeyUseCase) Execute(ctx context.Context, createdBy, creatorRole string, req CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	now := time.Now()
+	key := domain.APIKey{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(req.Name),
+		Scopes:    make([]domain.Permission, len(req.Scopes)),
+		CreatedBy: createdBy,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+	for i, scope := range req.Scopes {
+		key.Scopes[i] = domain.Permission(scope)
+	}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	domain.SortPermissions(key.Scopes)
+
+	if err := uc.authorizer.CheckGrant(ctx, creatorRole, key.Scopes); err != nil {
+		return nil, err
+	}
+
+	lookupID, err := newLookupID()
+	if err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+	secret, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate api key")
+	}
+	key.Prefix = domain.APIKeyPrefix + lookupID
+	rawKey := key.Prefix + "_" + secret
+	key.KeyHash = auth.HashToken(rawKey)
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.apiKeyRepo.Create(ctx, &key); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityAPIKey, key.ID, domain.AuditActionCreate, nil, &key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &CreateAPIKeyResponse{
+		Key:            rawKey,
+		APIKeyResponse: *toAPIKeyResponse(&key, now),
+	}, nil
+}
+
+// newLookupID returns the random lowercase ID that identifies a key
+func newLookupID() (string, error) {
+	random := make([]byte, lookupIDLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	for i, b := range random {
+		random[i] = lookupIDAlphabet[int(b)%len(lookupIDAlphabet)]
+	}
+	return string(random), nil
+}
+
+// toAPIKeyResponse converts an API key to its response, with its status at the given time
+func toAPIKeyResponse(key *domain.APIKey, now time.Time) *APIKeyResponse {
+	response := &APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     make([]string, len(key.Scopes)),
+		Status:     key.Status(now),
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+		CreatedAt:  key.CreatedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+	for i, scope := range key.Scopes {
+		response.Scopes[i] = string(scope)
+	}
+	return response
+}
@@ -0,0 +1,48 @@
+package apikey
+
+import "time"
+
+// CreateAPIKeyRequest represents the input data for creating an API key
+// Scopes are permission names; keys without expires_at never expire
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyResponse represents an API key; the key itself is only shown when it is created
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Status     string     `json:"status"` // active, revoked or expired
+	CreatedBy  string     `json:"created_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse represents a new API key together with the key itself
+// Only its hash is stored, so the key cannot be shown again
+type CreateAPIKeyResponse struct {
+	Key string `json:"key"`
+	APIKeyResponse
+}
+
+// APIKeyUsageResponse represents the requests a key made per day (UTC)
+// Days without requests are left out
+type APIKeyUsageResponse struct {
+	KeyID         string          `json:"key_id"`
+	Since         time.Time       `json:"since"`
+	TotalRequests int             `json:"total_requests"`
+	Days          []UsageResponse `json:"days"`
+}
+
+// UsageResponse represents the requests a key made in one day
+type UsageResponse struct {
+	Day      string `json:"day"` // YYYY-MM-DD
+	Requests int    `json:"requests"`
+}
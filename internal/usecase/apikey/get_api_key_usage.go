@@ -0,0 +1,60 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// maxUsageDays bounds how far back the usage of a key can be requested
+const maxUsageDays = 365
+
+// GetAPIKeyUsageUseCase handles the business logic for reporting the usage of an API key
+type GetAPIKeyUsageUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewGetAPIKeyUsageUseCase creates a new instance of GetAPIKeyUsageUseCase
+func NewGetAPIKeyUsageUseCase(apiKeyRepo repository.APIKeyRepository) *GetAPIKeyUsageUseCase {
+	return &GetAPIKeyUsageUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Execute returns the requests a key made per day over the last days, today included
+func (uc *GetAPIKeyUsageUseCase) Execute(ctx context.Context, keyID string, days int) (*APIKeyUsageResponse, error) {
+	if days < 1 || days > maxUsageDays {
+		return nil, errors.New("days must be between 1 and 365")
+	}
+
+	key, err := uc.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("api key not found")
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	usage, err := uc.apiKeyRepo.ListUsage(ctx, key.ID, since)
+	if err != nil {
+		return nil, errors.New("failed to get api key usage")
+	}
+
+	response := &APIKeyUsageResponse{
+		KeyID: key.ID,
+		Since: since,
+		Days:  make([]UsageResponse, 0, len(usage)),
+	}
+	for _, day := range usage {
+		response.TotalRequests += day.Requests
+		response.Days = append(response.Days, UsageResponse{
+			Day:      day.Day.UTC().Format(time.DateOnly),
+			Requests: day.Requests,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,38 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// ListAPIKeysUseCase handles the business logic for listing API keys
+type ListAPIKeysUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewListAPIKeysUseCase creates a new instance of ListAPIKeysUseCase
+func NewListAPIKeysUseCase(apiKeyRepo repository.APIKeyRepository) *ListAPIKeysUseCase {
+	return &ListAPIKeysUseCase{
+		apiKeyRepo: apiKeyRepo,
+	}
+}
+
+// Execute returns the API keys of the clinic, newest first, including the
+// revoked and expired ones
+func (uc *ListAPIKeysUseCase) Execute(ctx context.Context) ([]APIKeyResponse, error) {
+	keys, err := uc.apiKeyRepo.List(ctx)
+	if err != nil {
+		return nil, errors.New("failed to list api keys")
+	}
+
+	now := time.Now()
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, *toAPIKeyResponse(key, now))
+	}
+
+	return response, nil
+}
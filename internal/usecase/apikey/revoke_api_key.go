@@ -0,0 +1,75 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// errAPIKeyAlreadyRevoked is returned when a key was revoked before
+var errAPIKeyAlreadyRevoked = errors.New("api key is already revoked")
+
+// RevokeAPIKeyUseCase handles the business logic for revoking an API key
+type RevokeAPIKeyUseCase struct {
+	apiKeyRepo repository.APIKeyRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewRevokeAPIKeyUseCase creates a new instance of RevokeAPIKeyUseCase
+func NewRevokeAPIKeyUseCase(
+	apiKeyRepo repository.APIKeyRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *RevokeAPIKeyUseCase {
+	return &RevokeAPIKeyUseCase{
+		apiKeyRepo: apiKeyRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute revokes an API key; requests made with it are refused from then on
+// Expired keys can be revoked too, so they cannot be brought back
+func (uc *RevokeAPIKeyUseCase) Execute(ctx context.Context, keyID string) (*APIKeyResponse, error) {
+	if strings.TrimSpace(keyID) == "" {
+		return nil, errors.New("api key ID is required")
+	}
+
+	key, err := uc.apiKeyRepo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, errors.New("api key not found")
+	}
+	if key.RevokedAt != nil {
+		return nil, errAPIKeyAlreadyRevoked
+	}
+
+	now := time.Now()
+	before := *key
+	key.RevokedAt = &now
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		revoked, err := uc.apiKeyRepo.Revoke(ctx, key.ID, now)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return errAPIKeyAlreadyRevoked
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityAPIKey, key.ID, domain.AuditActionRevoke, &before, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toAPIKeyResponse(key, now), nil
+}
@@ -7,6 +7,8 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 	"version-1-0/pkg/email"
 )
 
@@ -14,75 +16,106 @@ import (
 type CancelAppointmentUseCase struct {
 	appointmentRepo repository.AppointmentRepository
 	userRepo        repository.UserRepository
+	clinicRepo      repository.ClinicRepository
+	txManager       repository.TxManager
+	recorder        *audit.Recorder
+	authorizer      *role.Authorizer
 	emailService    *email.EmailService
 }
 
 // NewCancelAppointmentUseCase creates a new instance of CancelAppointmentUseCase
-func NewCancelAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, emailService *email.EmailService) *CancelAppointmentUseCase {
+func NewCancelAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, clinicRepo repository.ClinicRepository, txManager repository.TxManager, recorder *audit.Recorder, authorizer *role.Authorizer, emailService *email.EmailService) *CancelAppointmentUseCase {
 	return &CancelAppointmentUseCase{
 		appointmentRepo: appointmentRepo,
 		userRepo:        userRepo,
+		clinicRepo:      clinicRepo,
+		txManager:       txManager,
+		recorder:        recorder,
+		authorizer:      authorizer,
 		emailService:    emailService,
 	}
 }
 
 // Execute cancels an appointment with permission validation
-// Only the patient, the doctor involved, or an admin can cancel an appointment
-func (uc *CancelAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string, req CancelAppointmentRequest) error {
-	// Retrieve the appointment
-	appointment, err := uc.appointmentRepo.FindByID(ctx, appointmentID)
+// Only the patient, the doctor involved, or roles with appointments:cancel:any can cancel an appointment
+// expectedVersion is the version the client last saw, zero skips the check
+func (uc *CancelAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string, req CancelAppointmentRequest, expectedVersion int) error {
+	canCancelAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionAppointmentsCancelAny)
 	if err != nil {
 		return err
 	}
-	if appointment == nil {
-		return errors.New("appointment not found")
-	}
 
-	// Get real patient.id and doctor.id from authenticatedUserID
-	// authenticatedUserID is a user.id, but appointment stores patient.id and doctor.id
-	var realPatientID, realDoctorID string
+	// Read, check and cancel in one unit of work so a concurrent reschedule
+	// cannot overwrite the cancellation
+	var appointment *domain.Appointment
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
 
-	// If user is patient, get their patient.id
-	if authenticatedUserRole == "patient" {
-		realPatientID, err = uc.userRepo.FindPatientIDByUserID(ctx, authenticatedUserID)
+		// Retrieve and lock the appointment
+		appointment, err = uc.appointmentRepo.FindByIDForUpdate(ctx, appointmentID)
 		if err != nil {
 			return err
 		}
-	}
+		if appointment == nil {
+			return errors.New("appointment not found")
+		}
 
-	// If user is doctor, get their doctor.id
-	if authenticatedUserRole == "doctor" {
-		realDoctorID, err = uc.userRepo.FindDoctorIDByUserID(ctx, authenticatedUserID)
-		if err != nil {
+		// Get real patient.id and doctor.id from authenticatedUserID
+		// authenticatedUserID is a user.id, but appointment stores patient.id and doctor.id
+		var realPatientID, realDoctorID string
+
+		// If user is patient, get their patient.id
+		if authenticatedUserRole == "patient" {
+			realPatientID, err = uc.userRepo.FindPatientIDByUserID(ctx, authenticatedUserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// If user is doctor, get their doctor.id
+		if authenticatedUserRole == "doctor" {
+			realDoctorID, err = uc.userRepo.FindDoctorIDByUserID(ctx, authenticatedUserID)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Verify permissions: only the patient, the doctor, or a role allowed to cancel any appointment
+		if !canCancelAny &&
+			realPatientID != appointment.PatientID &&
+			realDoctorID != appointment.DoctorID {
+			return errors.New("insufficient permissions to cancel this appointment")
+		}
+
+		// Refuse to act on a stale copy when the client sent the version it saw
+		if err := domain.CheckVersion(appointment.Version, expectedVersion); err != nil {
 			return err
 		}
-	}
 
-	// Verify permissions: only the patient, the doctor, or an admin can cancel
-	if authenticatedUserRole != "admin" &&
-		realPatientID != appointment.PatientID &&
-		realDoctorID != appointment.DoctorID {
-		return errors.New("insufficient permissions to cancel this appointment")
-	}
+		// Verify that the appointment is not already cancelled
+		if appointment.Status == domain.StatusCancelled {
+			return errors.New("appointment is already cancelled")
+		}
 
-	// Verify that the appointment is not already cancelled
-	if appointment.Status == domain.StatusCancelled {
-		return errors.New("appointment is already cancelled")
-	}
+		// Update appointment status and notes
+		before := *appointment
+		appointment.Status = domain.StatusCancelled
 
-	// Update appointment status and notes
-	appointment.Status = domain.StatusCancelled
+		// Build cancellation note
+		cancellationNote := "Cancelled"
+		if req.Reason != "" {
+			cancellationNote += ": " + req.Reason
+		}
+		appointment.Notes = cancellationNote
+		appointment.UpdatedAt = time.Now()
 
-	// Build cancellation note
-	cancellationNote := "Cancelled"
-	if req.Reason != "" {
-		cancellationNote += ": " + req.Reason
-	}
-	appointment.Notes = cancellationNote
-	appointment.UpdatedAt = time.Now()
+		// Save changes to database
+		if err := uc.appointmentRepo.Update(ctx, appointment); err != nil {
+			return err
+		}
 
-	// Save changes to database
-	err = uc.appointmentRepo.Update(ctx, appointment)
+		return uc.recorder.Record(ctx, domain.AuditEntityAppointment, appointment.ID, domain.AuditActionCancel, &before, appointment)
+	})
 	if err != nil {
 		return err
 	}
@@ -97,9 +130,11 @@ func (uc *CancelAppointmentUseCase) Execute(ctx context.Context, appointmentID s
 		doctorName := doctor.FirstName + " " + doctor.LastName
 		date := appointment.ScheduledAt.Format("2006-01-02")
 		time := appointment.ScheduledAt.Format("15:04")
+		branch := branchFor(ctx, uc.clinicRepo, appointment.LocationID)
 
 		// Notify patient
 		uc.emailService.SendAppointmentCancelled(
+			branch,
 			patient.Email,
 			patientName,
 			doctorName,
@@ -110,6 +145,7 @@ func (uc *CancelAppointmentUseCase) Execute(ctx context.Context, appointmentID s
 
 		// Notify doctor
 		uc.emailService.SendAppointmentCancelled(
+			branch,
 			doctor.Email,
 			doctorName,
 			doctorName,
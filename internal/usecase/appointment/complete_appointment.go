@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 	"version-1-0/pkg/email"
 )
 
@@ -12,21 +15,30 @@ import (
 type CompleteAppointmentUseCase struct {
 	appointmentRepo repository.AppointmentRepository
 	userRepo        repository.UserRepository
+	clinicRepo      repository.ClinicRepository
+	txManager       repository.TxManager
+	recorder        *audit.Recorder
+	authorizer      *role.Authorizer
 	emailService    *email.EmailService
 }
 
 // NewCompleteAppointmentUseCase creates a new instance of CompleteAppointmentUseCase
-func NewCompleteAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, emailService *email.EmailService) *CompleteAppointmentUseCase {
+func NewCompleteAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, clinicRepo repository.ClinicRepository, txManager repository.TxManager, recorder *audit.Recorder, authorizer *role.Authorizer, emailService *email.EmailService) *CompleteAppointmentUseCase {
 	return &CompleteAppointmentUseCase{
 		appointmentRepo: appointmentRepo,
 		userRepo:        userRepo,
+		clinicRepo:      clinicRepo,
+		txManager:       txManager,
+		recorder:        recorder,
+		authorizer:      authorizer,
 		emailService:    emailService,
 	}
 }
 
 // Execute completes an appointment with medical notes
-// Only doctors or admins can complete appointments
-func (uc *CompleteAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string, req CompleteAppointmentRequest) (*CompleteAppointmentResponse, error) {
+// Only the doctor of the appointment or roles with appointments:update:any can complete it
+// expectedVersion is the version the client last saw, zero skips the check
+func (uc *CompleteAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string, req CompleteAppointmentRequest, expectedVersion int) (*CompleteAppointmentResponse, error) {
 	// Retrieve the appointment
 	appointment, err := uc.appointmentRepo.FindByID(ctx, appointmentID)
 	if err != nil {
@@ -36,19 +48,35 @@ func (uc *CompleteAppointmentUseCase) Execute(ctx context.Context, appointmentID
 		return nil, errors.New("appointment not found")
 	}
 
-	// Verify permissions: only the doctor or an admin can complete
-	if authenticatedUserRole != "admin" && authenticatedUserID != appointment.DoctorID {
+	// Verify permissions: only the doctor or a role allowed to update any appointment can complete
+	canUpdateAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionAppointmentsUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+	if !canUpdateAny && authenticatedUserID != appointment.DoctorID {
 		return nil, errors.New("insufficient permissions to complete this appointment")
 	}
 
+	// Refuse to act on a stale copy when the client sent the version it saw
+	if err := domain.CheckVersion(appointment.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
 	// Use domain method to complete with notes
+	before := *appointment
 	err = appointment.Complete(req.Notes)
 	if err != nil {
 		return nil, err
 	}
 
-	// Save changes to database
-	err = uc.appointmentRepo.Update(ctx, appointment)
+	// Save changes to database together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.appointmentRepo.Update(ctx, appointment); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityAppointment, appointment.ID, domain.AuditActionComplete, &before, appointment)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -64,6 +92,7 @@ func (uc *CompleteAppointmentUseCase) Execute(ctx context.Context, appointmentID
 		Reason:          appointment.Reason,
 		Notes:           appointment.Notes,
 		UpdatedAt:       appointment.UpdatedAt,
+		Version:         appointment.Version,
 	}
 
 	// Get patient and doctor info for email
@@ -77,6 +106,7 @@ func (uc *CompleteAppointmentUseCase) Execute(ctx context.Context, appointmentID
 		date := appointment.ScheduledAt.Format("2006-01-02")
 
 		uc.emailService.SendAppointmentCompleted(
+			branchFor(ctx, uc.clinicRepo, appointment.LocationID),
 			patient.Email,
 			patientName,
 			doctorName,
@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 	"version-1-0/pkg/email"
 )
 
@@ -12,21 +15,30 @@ import (
 type ConfirmAppointmentUseCase struct {
 	appointmentRepo repository.AppointmentRepository
 	userRepo        repository.UserRepository
+	clinicRepo      repository.ClinicRepository
+	txManager       repository.TxManager
+	recorder        *audit.Recorder
+	authorizer      *role.Authorizer
 	emailService    *email.EmailService
 }
 
 // NewConfirmAppointmentUseCase creates a new instance of ConfirmAppointmentUseCase
-func NewConfirmAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, emailService *email.EmailService) *ConfirmAppointmentUseCase {
+func NewConfirmAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, clinicRepo repository.ClinicRepository, txManager repository.TxManager, recorder *audit.Recorder, authorizer *role.Authorizer, emailService *email.EmailService) *ConfirmAppointmentUseCase {
 	return &ConfirmAppointmentUseCase{
 		appointmentRepo: appointmentRepo,
 		userRepo:        userRepo,
+		clinicRepo:      clinicRepo,
+		txManager:       txManager,
+		recorder:        recorder,
+		authorizer:      authorizer,
 		emailService:    emailService,
 	}
 }
 
 // Execute confirms an appointment
-// Only doctors or admins can confirm appointments
-func (uc *ConfirmAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string) (*ConfirmAppointmentResponse, error) {
+// Only the doctor of the appointment or roles with appointments:update:any can confirm it
+// expectedVersion is the version the client last saw, zero skips the check
+func (uc *ConfirmAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string, expectedVersion int) (*ConfirmAppointmentResponse, error) {
 	// Retrieve the appointment
 	appointment, err := uc.appointmentRepo.FindByID(ctx, appointmentID)
 	if err != nil {
@@ -36,19 +48,35 @@ func (uc *ConfirmAppointmentUseCase) Execute(ctx context.Context, appointmentID
 		return nil, errors.New("appointment not found")
 	}
 
-	// Verify permissions: only the doctor or an admin can confirm
-	if authenticatedUserRole != "admin" && authenticatedUserID != appointment.DoctorID {
+	// Verify permissions: only the doctor or a role allowed to update any appointment can confirm
+	canUpdateAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionAppointmentsUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+	if !canUpdateAny && authenticatedUserID != appointment.DoctorID {
 		return nil, errors.New("insufficient permissions to confirm this appointment")
 	}
 
+	// Refuse to act on a stale copy when the client sent the version it saw
+	if err := domain.CheckVersion(appointment.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
 	// Use domain method to confirm
+	before := *appointment
 	err = appointment.Confirm()
 	if err != nil {
 		return nil, err
 	}
 
-	// Save changes to database
-	err = uc.appointmentRepo.Update(ctx, appointment)
+	// Save changes to database together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.appointmentRepo.Update(ctx, appointment); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityAppointment, appointment.ID, domain.AuditActionConfirm, &before, appointment)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +91,7 @@ func (uc *ConfirmAppointmentUseCase) Execute(ctx context.Context, appointmentID
 		Status:          string(appointment.Status),
 		Reason:          appointment.Reason,
 		UpdatedAt:       appointment.UpdatedAt,
+		Version:         appointment.Version,
 	}
 
 	// Get patient and doctor info for email
@@ -74,6 +103,7 @@ func (uc *ConfirmAppointmentUseCase) Execute(ctx context.Context, appointmentID
 		patientName := patient.FirstName + " " + patient.LastName
 		doctorName := doctor.FirstName + " " + doctor.LastName
 		uc.emailService.SendAppointmentConfirmed(
+			branchFor(ctx, uc.clinicRepo, appointment.LocationID),
 			patient.Email,
 			patientName,
 			doctorName,
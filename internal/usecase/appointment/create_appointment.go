@@ -10,6 +10,8 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 	"version-1-0/pkg/email"
 )
 
@@ -19,6 +21,11 @@ type CreateAppointmentUseCase struct {
 	userRepo          repository.UserRepository
 	serviceRepo       repository.ServiceRepository
 	doctorServiceRepo repository.DoctorServiceRepository
+	scheduleRepo      repository.ScheduleRepository
+	clinicRepo        repository.ClinicRepository
+	txManager         repository.TxManager
+	recorder          *audit.Recorder
+	authorizer        *role.Authorizer
 	emailService      *email.EmailService
 }
 
@@ -28,6 +35,11 @@ func NewCreateAppointmentUseCase(
 	userRepo repository.UserRepository,
 	serviceRepo repository.ServiceRepository,
 	doctorServiceRepo repository.DoctorServiceRepository,
+	scheduleRepo repository.ScheduleRepository,
+	clinicRepo repository.ClinicRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	authorizer *role.Authorizer,
 	emailService *email.EmailService,
 ) *CreateAppointmentUseCase {
 	return &CreateAppointmentUseCase{
@@ -35,20 +47,46 @@ func NewCreateAppointmentUseCase(
 		userRepo:          userRepo,
 		serviceRepo:       serviceRepo,
 		doctorServiceRepo: doctorServiceRepo,
+		scheduleRepo:      scheduleRepo,
+		clinicRepo:        clinicRepo,
+		txManager:         txManager,
+		recorder:          recorder,
+		authorizer:        authorizer,
 		emailService:      emailService,
 	}
 }
 
 // Execute creates a new appointment with a service
-func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, patientID, doctorID, serviceID string, scheduledAt time.Time, reason string) (*domain.Appointment, error) {
+// Users book for themselves when patientID is empty; booking for another
+// patient, as the front desk does, needs appointments:create:any
+// An empty locationID lets the doctor's schedule decide where it takes place
+func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, authenticatedUserID, authenticatedUserRole, patientID, doctorID, serviceID, locationID string, scheduledAt time.Time, reason string) (*domain.Appointment, error) {
+	onBehalf := patientID != "" && patientID != authenticatedUserID
+	if onBehalf {
+		canCreateAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionAppointmentsCreateAny)
+		if err != nil {
+			return nil, err
+		}
+		if !canCreateAny {
+			return nil, errors.New("insufficient permissions to book for another patient")
+		}
+	} else {
+		patientID = authenticatedUserID
+	}
+
 	// Validate patient exists
 	patient, err := uc.userRepo.FindByID(ctx, patientID)
 	if err != nil {
 		return nil, err
 	}
-	if patient == nil {
+	if patient == nil || (onBehalf && patient.Role != domain.RolePatient) {
 		return nil, errors.New("patient not found")
 	}
+	// Patients must prove they own their email before booking under it; staff
+	// booking for a patient vouch for them
+	if !onBehalf && patient.Role == domain.RolePatient && !patient.IsEmailVerified() {
+		return nil, errors.New("email not verified")
+	}
 
 	// Validate doctor exists
 	doctor, err := uc.userRepo.FindByID(ctx, doctorID)
@@ -92,25 +130,12 @@ func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, patientID, doct
 		return nil, errors.New("doctor does not offer this service")
 	}
 
-	// Check for scheduling conflicts
-	appointmentEnd := scheduledAt.Add(time.Duration(service.DurationMinutes) * time.Minute)
-	conflicts, err := uc.appointmentRepo.FindByDoctorAndDate(ctx, realDoctorID, scheduledAt)
+	// Place the appointment at the location the doctor is at that time
+	locationID, err = resolveLocation(ctx, uc.scheduleRepo, uc.clinicRepo, realDoctorID, scheduledAt, locationID)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, conflict := range conflicts {
-		if conflict.Status == "cancelled" {
-			continue
-		}
-
-		conflictEnd := conflict.ScheduledAt.Add(time.Duration(conflict.Duration) * time.Minute)
-
-		if scheduledAt.Before(conflictEnd) && appointmentEnd.After(conflict.ScheduledAt) {
-			return nil, errors.New("time slot is not available")
-		}
-	}
-
 	// Create appointment
 	now := time.Now()
 	appointment := &domain.Appointment{
@@ -119,6 +144,7 @@ func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, patientID, doct
 		DoctorID:    realDoctorID,
 		ServiceID:   serviceID,
 		ServiceName: service.Name,
+		LocationID:  locationID,
 		ScheduledAt: scheduledAt,
 		Duration:    service.DurationMinutes,
 		Reason:      reason,
@@ -131,7 +157,24 @@ func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, patientID, doct
 		return nil, err
 	}
 
-	if err := uc.appointmentRepo.Create(ctx, appointment); err != nil {
+	// Check for conflicts and insert in one unit of work, holding the doctor lock,
+	// so two concurrent bookings for the same slot cannot both succeed
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.appointmentRepo.LockDoctor(ctx, realDoctorID); err != nil {
+			return err
+		}
+
+		if err := checkSlotAvailable(ctx, uc.appointmentRepo, appointment); err != nil {
+			return err
+		}
+
+		if err := uc.appointmentRepo.Create(ctx, appointment); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityAppointment, appointment.ID, domain.AuditActionCreate, nil, appointment)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -141,9 +184,10 @@ func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, patientID, doct
 		doctorName := doctor.FirstName + " " + doctor.LastName
 		date := scheduledAt.Format("2006-01-02")
 		timeStr := scheduledAt.Format("15:04")
+		branch := branchFor(ctx, uc.clinicRepo, appointment.LocationID)
 
 		go func() {
-			if err := uc.emailService.SendAppointmentCreated(patient.Email, patientName, doctorName, date, timeStr); err != nil {
+			if err := uc.emailService.SendAppointmentCreated(branch, patient.Email, patientName, doctorName, date, timeStr); err != nil {
 				log.Printf("Failed to send appointment created email: %v", err)
 			}
 		}()
@@ -151,3 +195,30 @@ func (uc *CreateAppointmentUseCase) Execute(ctx context.Context, patientID, doct
 
 	return appointment, nil
 }
+
+// checkSlotAvailable returns domain.ErrSlotTaken if the appointment overlaps another
+// active appointment of the same doctor on that day. The database guard enforces the
+// same rule; checking first keeps the common case free of constraint violations
+func checkSlotAvailable(ctx context.Context, appointmentRepo repository.AppointmentRepository, appointment *domain.Appointment) error {
+	existingAppointments, err := appointmentRepo.FindByDoctorAndDate(ctx, appointment.DoctorID, appointment.ScheduledAt)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range existingAppointments {
+		// Skip the appointment itself when rescheduling
+		if existing.ID == appointment.ID {
+			continue
+		}
+
+		if existing.Status == domain.StatusCancelled {
+			continue
+		}
+
+		if appointment.ScheduledAt.Before(existing.EndTime()) && appointment.EndTime().After(existing.ScheduledAt) {
+			return domain.ErrSlotTaken
+		}
+	}
+
+	return nil
+}
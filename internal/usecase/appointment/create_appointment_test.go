@@ -0,0 +1,326 @@
+package appointment_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/repository/sqlite"
+	"version-1-0/internal/usecase/appointment"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/pagination"
+	"version-1-0/internal/usecase/role"
+	"version-1-0/internal/usecase/service"
+	"version-1-0/internal/usecase/user"
+	"version-1-0/pkg/fieldcrypt"
+)
+
+// repos holds the repositories of one storage backend
+type repos struct {
+	user          repository.UserRepository
+	doctor        repository.DoctorRepository
+	patient       repository.PatientRepository
+	appointment   repository.AppointmentRepository
+	service       repository.ServiceRepository
+	doctorService repository.DoctorServiceRepository
+	schedule      repository.ScheduleRepository
+	audit         repository.AuditRepository
+	clinic        repository.ClinicRepository
+	role          repository.RoleRepository
+	tx            repository.TxManager
+}
+
+// newMemoryRepos returns the repositories of an empty in-memory store
+func newMemoryRepos(t *testing.T) repos {
+	t.Helper()
+
+	store := memory.NewStore()
+	return repos{
+		user:          memory.NewMemoryUserRepository(store),
+		doctor:        memory.NewMemoryDoctorRepository(store),
+		patient:       memory.NewMemoryPatientRepository(store),
+		appointment:   memory.NewMemoryAppointmentRepository(store),
+		service:       memory.NewMemoryServiceRepository(store),
+		doctorService: memory.NewMemoryDoctorServiceRepository(store),
+		schedule:      memory.NewMemoryScheduleRepository(store),
+		audit:         memory.NewMemoryAuditRepository(store),
+		clinic:        memory.NewMemoryClinicRepository(store),
+		role:          memory.NewMemoryRoleRepository(store),
+		tx:            memory.NewMemoryTxManager(store),
+	}
+}
+
+// newSQLiteRepos returns the repositories of a freshly migrated SQLite file
+// A file rather than ":memory:" keeps the connection pool, so concurrent use
+// cases run on separate connections as they do in the API
+func newSQLiteRepos(t *testing.T) repos {
+	t.Helper()
+
+	db, err := sqlite.InitDB(filepath.Join(t.TempDir(), "clinica.db"), true)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	cipher, err := fieldcrypt.New([]fieldcrypt.Key{{ID: "test", Secret: make([]byte, fieldcrypt.KeySize)}}, make([]byte, fieldcrypt.KeySize))
+	if err != nil {
+		t.Fatalf("create cipher: %v", err)
+	}
+
+	return repos{
+		user:          sqlite.NewSqliteUserRepository(db),
+		doctor:        sqlite.NewSqliteDoctorRepository(db),
+		patient:       sqlite.NewSqlitePatientRepository(db, cipher),
+		appointment:   sqlite.NewSqliteAppointmentRepository(db, cipher),
+		service:       sqlite.NewSqliteServiceRepository(db),
+		doctorService: sqlite.NewSqliteDoctorServiceRepository(db),
+		schedule:      sqlite.NewSqliteScheduleRepository(db),
+		audit:         sqlite.NewSqliteAuditRepository(db),
+		clinic:        sqlite.NewSqliteClinicRepository(db),
+		role:          sqlite.NewSqliteRoleRepository(db),
+		tx:            sqlite.NewSqliteTxManager(db),
+	}
+}
+
+// backends lists the storage backends the use cases are exercised against
+var backends = []struct {
+	name  string
+	repos func(t *testing.T) repos
+}{
+	{"memory", newMemoryRepos},
+	{"sqlite", newSQLiteRepos},
+}
+
+// fixture is a doctor offering one service and a patient with a verified
+// email, created through the same use cases as the API
+type fixture struct {
+	repos         repos
+	createUC      *appointment.CreateAppointmentUseCase
+	doctorUserID  string
+	patientUserID string
+	serviceID     string
+}
+
+func newFixture(t *testing.T, r repos) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	recorder := audit.NewRecorder(r.audit)
+	createUserUC := user.NewCreateUserUseCase(r.user, r.doctor, r.patient, r.clinic, r.tx, recorder, nil)
+
+	doctor, err := createUserUC.Execute(ctx, user.CreateUserRequest{
+		Email:     "doctor@clinica.test",
+		Password:  "password123",
+		FirstName: "María",
+		LastName:  "Salazar",
+		Role:      string(domain.RoleDoctor),
+	})
+	if err != nil {
+		t.Fatalf("create doctor: %v", err)
+	}
+
+	patient, err := createUserUC.Execute(ctx, user.CreateUserRequest{
+		Email:     "patient@clinica.test",
+		Password:  "password123",
+		FirstName: "Sofía",
+		LastName:  "García",
+		Phone:     "+51 999 888 777",
+		Role:      string(domain.RolePatient),
+	})
+	if err != nil {
+		t.Fatalf("create patient: %v", err)
+	}
+
+	account, err := r.user.FindByID(ctx, patient.ID)
+	if err != nil {
+		t.Fatalf("find patient: %v", err)
+	}
+	account.EmailVerifiedAt = &account.CreatedAt
+	if err := r.user.Update(ctx, account); err != nil {
+		t.Fatalf("verify patient email: %v", err)
+	}
+
+	created, err := service.NewCreateServiceUseCase(r.service, r.tx, recorder).Execute(ctx, service.CreateServiceRequest{
+		Name:            "Consulta general",
+		Description:     "Consulta de medicina general",
+		DurationMinutes: 30,
+		Price:           50,
+	})
+	if err != nil {
+		t.Fatalf("create service: %v", err)
+	}
+
+	assignUC := service.NewAssignServiceToDoctorUseCase(r.doctorService, r.service, r.user, r.tx, recorder)
+	if err := assignUC.Execute(ctx, doctor.ID, created.ID); err != nil {
+		t.Fatalf("assign service: %v", err)
+	}
+
+	return &fixture{
+		repos:         r,
+		createUC:      appointment.NewCreateAppointmentUseCase(r.appointment, r.user, r.service, r.doctorService, r.schedule, r.clinic, r.tx, recorder, role.NewAuthorizer(r.role), nil),
+		doctorUserID:  doctor.ID,
+		patientUserID: patient.ID,
+		serviceID:     created.ID,
+	}
+}
+
+// book books the fixture service for the patient at scheduledAt
+func (f *fixture) book(ctx context.Context, scheduledAt time.Time) (*domain.Appointment, error) {
+	return f.createUC.Execute(ctx, f.patientUserID, string(domain.RolePatient), "", f.doctorUserID, f.serviceID, "", scheduledAt, "Control anual")
+}
+
+// mustDoctorID returns the doctor profile ID of the fixture doctor
+func (f *fixture) mustDoctorID(t *testing.T) string {
+	t.Helper()
+
+	doctorID, err := f.repos.user.FindDoctorIDByUserID(context.Background(), f.doctorUserID)
+	if err != nil {
+		t.Fatalf("FindDoctorIDByUserID: %v", err)
+	}
+	return doctorID
+}
+
+// nextSlot returns a slot far enough in the future to never be in the past
+func nextSlot() time.Time {
+	return time.Now().AddDate(0, 0, 7).Truncate(time.Hour)
+}
+
+func TestCreateAppointmentStoresProfileIDs(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, newMemoryRepos(t))
+
+	booked, err := f.book(ctx, nextSlot())
+	if err != nil {
+		t.Fatalf("book: %v", err)
+	}
+
+	doctorID, err := f.repos.user.FindDoctorIDByUserID(ctx, f.doctorUserID)
+	if err != nil {
+		t.Fatalf("FindDoctorIDByUserID: %v", err)
+	}
+	patientID, err := f.repos.user.FindPatientIDByUserID(ctx, f.patientUserID)
+	if err != nil {
+		t.Fatalf("FindPatientIDByUserID: %v", err)
+	}
+
+	// Appointments reference doctors.id and patients.id, never users.id
+	if doctorID == f.doctorUserID || patientID == f.patientUserID {
+		t.Fatalf("profile IDs must differ from user IDs: doctor %s, patient %s", doctorID, patientID)
+	}
+	if booked.DoctorID != doctorID {
+		t.Errorf("DoctorID = %s, want doctor profile %s", booked.DoctorID, doctorID)
+	}
+	if booked.PatientID != patientID {
+		t.Errorf("PatientID = %s, want patient profile %s", booked.PatientID, patientID)
+	}
+
+	// The listings take user IDs and must find the appointment through the profiles
+	byPatient, err := appointment.NewGetAppointmentsByPatientUseCase(f.repos.appointment, f.repos.user).Execute(ctx, f.patientUserID, pagination.Request{})
+	if err != nil {
+		t.Fatalf("list by patient: %v", err)
+	}
+	if len(byPatient.Appointments) != 1 || byPatient.Appointments[0].ID != booked.ID {
+		t.Errorf("patient listing = %+v, want only %s", byPatient.Appointments, booked.ID)
+	}
+
+	byDoctor, err := appointment.NewGetAppointmentsByDoctorUseCase(f.repos.appointment, f.repos.user).Execute(ctx, f.doctorUserID, pagination.Request{})
+	if err != nil {
+		t.Fatalf("list by doctor: %v", err)
+	}
+	if len(byDoctor.Appointments) != 1 || byDoctor.Appointments[0].ID != booked.ID {
+		t.Errorf("doctor listing = %+v, want only %s", byDoctor.Appointments, booked.ID)
+	}
+}
+
+func TestCreateAppointmentRejectsUnknownProfiles(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, newMemoryRepos(t))
+
+	// A patient account has no doctor profile and the other way around
+	if _, err := f.repos.user.FindDoctorIDByUserID(ctx, f.patientUserID); err == nil || err.Error() != "doctor not found" {
+		t.Errorf("FindDoctorIDByUserID(patient) error = %v, want doctor not found", err)
+	}
+	if _, err := f.repos.user.FindPatientIDByUserID(ctx, f.doctorUserID); err == nil || err.Error() != "patient not found" {
+		t.Errorf("FindPatientIDByUserID(doctor) error = %v, want patient not found", err)
+	}
+
+	_, err := f.createUC.Execute(ctx, f.patientUserID, string(domain.RolePatient), "", f.patientUserID, f.serviceID, "", nextSlot(), "Control anual")
+	if err == nil || err.Error() != "doctor not found" {
+		t.Errorf("booking a patient as doctor error = %v, want doctor not found", err)
+	}
+}
+
+func TestCreateAppointmentRejectsTakenSlot(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, newMemoryRepos(t))
+	slot := nextSlot()
+
+	if _, err := f.book(ctx, slot); err != nil {
+		t.Fatalf("first booking: %v", err)
+	}
+	if _, err := f.book(ctx, slot.Add(15*time.Minute)); !errors.Is(err, domain.ErrSlotTaken) {
+		t.Errorf("overlapping booking error = %v, want %v", err, domain.ErrSlotTaken)
+	}
+	if _, err := f.book(ctx, slot.Add(30*time.Minute)); err != nil {
+		t.Errorf("adjacent booking: %v", err)
+	}
+}
+
+func TestCreateAppointmentConcurrentBookings(t *testing.T) {
+	const bookings = 8
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newFixture(t, backend.repos(t))
+			slot := nextSlot()
+
+			// Release every booking at once so they race for the same slot
+			start := make(chan struct{})
+			errs := make([]error, bookings)
+			var wg sync.WaitGroup
+			for i := range bookings {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					_, errs[i] = f.book(ctx, slot)
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			succeeded := 0
+			for _, err := range errs {
+				switch {
+				case err == nil:
+					succeeded++
+				case errors.Is(err, domain.ErrSlotTaken):
+					// The handler answers 409 on this message
+					if err.Error() != "time slot is not available" {
+						t.Errorf("slot taken message = %q", err.Error())
+					}
+				default:
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+			if succeeded != 1 {
+				t.Errorf("%d bookings succeeded, want exactly 1", succeeded)
+			}
+
+			stored, err := f.repos.appointment.FindByDoctorAndDate(ctx, f.mustDoctorID(t), slot)
+			if err != nil {
+				t.Fatalf("FindByDoctorAndDate: %v", err)
+			}
+			if len(stored) != 1 {
+				t.Errorf("%d appointments stored for the slot, want 1", len(stored))
+			}
+		})
+	}
+}
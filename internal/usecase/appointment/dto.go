@@ -1,13 +1,19 @@
 package appointment
 
-import "time"
+import (
+	"time"
+
+	"version-1-0/internal/usecase/pagination"
+)
 
 // CreateAppointmentRequest represents the input data for creating a new appointment
 type CreateAppointmentRequest struct {
+	PatientID       string `json:"patient_id"` // Optional, books for another patient; needs appointments:create:any
 	DoctorID        string `json:"doctor_id"`
 	ServiceID       string `json:"service_id"`
 	AppointmentDate string `json:"appointment_date"` // Format: "2006-01-02" (YYYY-MM-DD)
 	AppointmentTime string `json:"appointment_time"` // Format: "15:04" (HH:MM 24-hour)
+	LocationID      string `json:"location_id"`      // Optional, the doctor's schedule decides when empty
 	Reason          string `json:"reason"`
 }
 
@@ -32,12 +38,14 @@ type GetAppointmentResponse struct {
 	PatientName     string    `json:"patient_name,omitempty"`
 	DoctorName      string    `json:"doctor_name,omitempty"`
 	ServiceName     string    `json:"service_name,omitempty"`
+	LocationID      string    `json:"location_id,omitempty"`
 	AppointmentDate string    `json:"appointment_date"`
 	AppointmentTime string    `json:"appointment_time"`
 	Status          string    `json:"status"`
 	Reason          string    `json:"reason"`
 	Notes           string    `json:"notes"`
 	CreatedAt       time.Time `json:"created_at"`
+	Version         int       `json:"version"`
 }
 
 // CancelAppointmentRequest represents the input data for canceling an appointment
@@ -55,6 +63,7 @@ type ConfirmAppointmentResponse struct {
 	Status          string    `json:"status"`
 	Reason          string    `json:"reason"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
 }
 
 // CompleteAppointmentRequest represents the input for completing an appointment
@@ -73,22 +82,34 @@ type CompleteAppointmentResponse struct {
 	Reason          string    `json:"reason"`
 	Notes           string    `json:"notes"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
 }
 
 // GetAllAppointmentsRequest represents filters for querying all appointments
 type GetAllAppointmentsRequest struct {
-	Status    string `json:"status"`     // Filter by status (pending, confirmed, completed, cancelled)
-	DoctorID  string `json:"doctor_id"`  // Filter by doctor ID
-	PatientID string `json:"patient_id"` // Filter by patient ID
-	ServiceID string `json:"service_id"` // Filter by service ID
-	DateFrom  string `json:"date_from"`  // Filter from date (YYYY-MM-DD)
-	DateTo    string `json:"date_to"`    // Filter to date (YYYY-MM-DD)
+	Status     string `json:"status"`      // Filter by status (pending, confirmed, completed, cancelled)
+	DoctorID   string `json:"doctor_id"`   // Filter by doctor ID
+	PatientID  string `json:"patient_id"`  // Filter by patient ID
+	ServiceID  string `json:"service_id"`  // Filter by service ID
+	LocationID string `json:"location_id"` // Filter by location ID
+	DateFrom   string `json:"date_from"`   // Filter from date (YYYY-MM-DD)
+	DateTo     string `json:"date_to"`     // Filter to date (YYYY-MM-DD)
+	pagination.Request
+}
+
+// ListAppointmentsResponse represents one page of appointments
+// Sort accepts scheduled_at (default, latest first) and created_at
+// NextCursor is empty on the last page
+type ListAppointmentsResponse struct {
+	Appointments []GetAppointmentResponse `json:"appointments"`
+	NextCursor   string                   `json:"next_cursor"`
 }
 
 // RescheduleAppointmentRequest represents the input for rescheduling an appointment
 type RescheduleAppointmentRequest struct {
-	NewDate string `json:"new_date"` // New date (YYYY-MM-DD)
-	NewTime string `json:"new_time"` // New time (HH:MM)
+	NewDate    string `json:"new_date"`    // New date (YYYY-MM-DD)
+	NewTime    string `json:"new_time"`    // New time (HH:MM)
+	LocationID string `json:"location_id"` // Optional, the doctor's schedule decides when empty
 }
 
 // RescheduleAppointmentResponse represents the response after rescheduling
@@ -99,9 +120,11 @@ type RescheduleAppointmentResponse struct {
 	PatientName     string    `json:"patient_name,omitempty"`
 	DoctorName      string    `json:"doctor_name,omitempty"`
 	ServiceName     string    `json:"service_name,omitempty"`
+	LocationID      string    `json:"location_id"`
 	AppointmentDate string    `json:"appointment_date"`
 	AppointmentTime string    `json:"appointment_time"`
 	Status          string    `json:"status"`
 	Reason          string    `json:"reason"`
 	UpdatedAt       time.Time `json:"updated_at"`
+	Version         int       `json:"version"`
 }
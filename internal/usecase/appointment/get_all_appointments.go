@@ -4,9 +4,17 @@ import (
 	"context"
 	"time"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/pagination"
 )
 
+// appointmentSorts are the orders appointments can be listed in
+var appointmentSorts = pagination.Sorts{
+	repository.SortScheduledAt: true,
+	repository.SortCreatedAt:   true,
+}
+
 // GetAllAppointmentsUseCase handles retrieving all appointments with filters (admin only)
 type GetAllAppointmentsUseCase struct {
 	appointmentRepo repository.AppointmentRepository
@@ -19,14 +27,15 @@ func NewGetAllAppointmentsUseCase(appointmentRepo repository.AppointmentReposito
 	}
 }
 
-// Execute retrieves all appointments with optional filters
-func (uc *GetAllAppointmentsUseCase) Execute(ctx context.Context, req GetAllAppointmentsRequest) ([]GetAppointmentResponse, error) {
+// Execute retrieves one page of appointments with optional filters
+func (uc *GetAllAppointmentsUseCase) Execute(ctx context.Context, req GetAllAppointmentsRequest) (*ListAppointmentsResponse, error) {
 	// Build filters
 	filters := repository.AppointmentFilters{
-		Status:    req.Status,
-		DoctorID:  req.DoctorID,
-		PatientID: req.PatientID,
-		ServiceID: req.ServiceID,
+		Status:     req.Status,
+		DoctorID:   req.DoctorID,
+		PatientID:  req.PatientID,
+		ServiceID:  req.ServiceID,
+		LocationID: req.LocationID,
 	}
 
 	// Parse date filters if provided
@@ -46,31 +55,56 @@ func (uc *GetAllAppointmentsUseCase) Execute(ctx context.Context, req GetAllAppo
 		}
 	}
 
+	return listAppointments(ctx, uc.appointmentRepo, filters, req.Request)
+}
+
+// listAppointments retrieves one page of appointments matching the filters
+// and converts them to response DTOs
+func listAppointments(ctx context.Context, appointmentRepo repository.AppointmentRepository, filters repository.AppointmentFilters, req pagination.Request) (*ListAppointmentsResponse, error) {
+	page, err := pagination.Resolve(req, appointmentSorts, repository.SortScheduledAt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Retrieve appointments from repository with filters
-	appointments, err := uc.appointmentRepo.FindAllWithFilters(ctx, filters)
+	appointments, err := appointmentRepo.FindAllWithFilters(ctx, filters, pagination.Lookahead(page))
 	if err != nil {
 		return nil, err
 	}
 
+	appointments, nextCursor := pagination.Finish(page, appointments, func(a *domain.Appointment) repository.Cursor {
+		return repository.Cursor{Key: repository.AppointmentSortKey(a, page.Sort), ID: a.ID}
+	})
+
 	// Convert domain appointments to response DTOs
 	responses := make([]GetAppointmentResponse, len(appointments))
 	for i, appointment := range appointments {
-		responses[i] = GetAppointmentResponse{
-			ID:              appointment.ID,
-			PatientID:       appointment.PatientID,
-			DoctorID:        appointment.DoctorID,
-			ServiceID:       appointment.ServiceID,
-			PatientName:     appointment.PatientName,
-			DoctorName:      appointment.DoctorName,
-			ServiceName:     appointment.ServiceName,
-			AppointmentDate: appointment.ScheduledAt.Format("2006-01-02"),
-			AppointmentTime: appointment.ScheduledAt.Format("15:04"),
-			Status:          string(appointment.Status),
-			Reason:          appointment.Reason,
-			Notes:           appointment.Notes,
-			CreatedAt:       appointment.CreatedAt,
-		}
+		responses[i] = appointmentResponse(appointment)
 	}
 
-	return responses, nil
+	return &ListAppointmentsResponse{
+		Appointments: responses,
+		NextCursor:   nextCursor,
+	}, nil
+}
+
+// appointmentResponse converts an appointment read with its names to its response DTO
+func appointmentResponse(appointment *domain.Appointment) GetAppointmentResponse {
+	return GetAppointmentResponse{
+		ID:              appointment.ID,
+		PatientID:       appointment.PatientID,
+		DoctorID:        appointment.DoctorID,
+		ServiceID:       appointment.ServiceID,
+		PatientName:     appointment.PatientName,
+		DoctorName:      appointment.DoctorName,
+		ServiceName:     appointment.ServiceName,
+		LocationID:      appointment.LocationID,
+		AppointmentDate: appointment.ScheduledAt.Format("2006-01-02"),
+		AppointmentTime: appointment.ScheduledAt.Format("15:04"),
+		Status:          string(appointment.Status),
+		Reason:          appointment.Reason,
+		Notes:           appointment.Notes,
+		CreatedAt:       appointment.CreatedAt,
+		Version:         appointment.Version,
+	}
 }
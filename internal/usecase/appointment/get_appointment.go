@@ -0,0 +1,67 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/role"
+)
+
+// GetAppointmentUseCase handles retrieving a single appointment
+type GetAppointmentUseCase struct {
+	appointmentRepo repository.AppointmentRepository
+	userRepo        repository.UserRepository
+	authorizer      *role.Authorizer
+}
+
+// NewGetAppointmentUseCase creates a new instance of GetAppointmentUseCase
+func NewGetAppointmentUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, authorizer *role.Authorizer) *GetAppointmentUseCase {
+	return &GetAppointmentUseCase{
+		appointmentRepo: appointmentRepo,
+		userRepo:        userRepo,
+		authorizer:      authorizer,
+	}
+}
+
+// Execute retrieves an appointment with its names and version
+// Only the patient, the doctor involved, or roles with appointments:read:any can see it
+func (uc *GetAppointmentUseCase) Execute(ctx context.Context, appointmentID string, authenticatedUserID string, authenticatedUserRole string) (*GetAppointmentResponse, error) {
+	appointments, err := uc.appointmentRepo.FindAllWithFilters(ctx, repository.AppointmentFilters{ID: appointmentID}, repository.PageRequest{Sort: repository.SortScheduledAt, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(appointments) == 0 {
+		return nil, errors.New("appointment not found")
+	}
+	appointment := appointments[0]
+
+	canReadAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionAppointmentsReadAny)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadAny {
+		// authenticatedUserID is a user.id, but appointment stores patient.id and doctor.id
+		var realPatientID, realDoctorID string
+		if authenticatedUserRole == "patient" {
+			realPatientID, err = uc.userRepo.FindPatientIDByUserID(ctx, authenticatedUserID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if authenticatedUserRole == "doctor" {
+			realDoctorID, err = uc.userRepo.FindDoctorIDByUserID(ctx, authenticatedUserID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if realPatientID != appointment.PatientID && realDoctorID != appointment.DoctorID {
+			return nil, errors.New("insufficient permissions to view this appointment")
+		}
+	}
+
+	response := appointmentResponse(appointment)
+	return &response, nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/pagination"
 )
 
 // GetAppointmentsByPatientUseCase handles retrieving all appointments for a patient
@@ -21,8 +22,8 @@ func NewGetAppointmentsByPatientUseCase(appointmentRepo repository.AppointmentRe
 	}
 }
 
-// Execute retrieves all appointments for a specific patient
-func (uc *GetAppointmentsByPatientUseCase) Execute(ctx context.Context, patientUserID string) ([]GetAppointmentResponse, error) {
+// Execute retrieves one page of appointments for a specific patient
+func (uc *GetAppointmentsByPatientUseCase) Execute(ctx context.Context, patientUserID string, req pagination.Request) (*ListAppointmentsResponse, error) {
 	// Convert user_id to patient.id from patients table
 	patientID, err := uc.userRepo.FindPatientIDByUserID(ctx, patientUserID)
 	if err != nil {
@@ -30,30 +31,7 @@ func (uc *GetAppointmentsByPatientUseCase) Execute(ctx context.Context, patientU
 	}
 
 	// Retrieve appointments from repository using the real patient.id
-	appointments, err := uc.appointmentRepo.FindByPatientID(ctx, patientID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert domain appointments to response DTOs
-	responses := make([]GetAppointmentResponse, len(appointments))
-	for i, appointment := range appointments {
-		responses[i] = GetAppointmentResponse{
-			ID:              appointment.ID,
-			PatientID:       appointment.PatientID,
-			DoctorID:        appointment.DoctorID,
-			PatientName:     appointment.PatientName,
-			DoctorName:      appointment.DoctorName,
-			AppointmentDate: appointment.ScheduledAt.Format("2006-01-02"),
-			AppointmentTime: appointment.ScheduledAt.Format("15:04"),
-			Status:          string(appointment.Status),
-			Reason:          appointment.Reason,
-			Notes:           appointment.Notes,
-			CreatedAt:       appointment.CreatedAt,
-		}
-	}
-
-	return responses, nil
+	return listAppointments(ctx, uc.appointmentRepo, repository.AppointmentFilters{PatientID: patientID}, req)
 }
 
 // GetAppointmentsByDoctorUseCase handles retrieving all appointments for a doctor
@@ -70,8 +48,8 @@ func NewGetAppointmentsByDoctorUseCase(appointmentRepo repository.AppointmentRep
 	}
 }
 
-// Execute retrieves all appointments for a specific doctor
-func (uc *GetAppointmentsByDoctorUseCase) Execute(ctx context.Context, doctorUserID string) ([]GetAppointmentResponse, error) {
+// Execute retrieves one page of appointments for a specific doctor
+func (uc *GetAppointmentsByDoctorUseCase) Execute(ctx context.Context, doctorUserID string, req pagination.Request) (*ListAppointmentsResponse, error) {
 	// Convert user_id to doctor.id from doctors table
 	doctorID, err := uc.userRepo.FindDoctorIDByUserID(ctx, doctorUserID)
 	if err != nil {
@@ -79,28 +57,5 @@ func (uc *GetAppointmentsByDoctorUseCase) Execute(ctx context.Context, doctorUse
 	}
 
 	// Retrieve appointments from repository using the real doctor.id
-	appointments, err := uc.appointmentRepo.FindByDoctorID(ctx, doctorID)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert domain appointments to response DTOs
-	responses := make([]GetAppointmentResponse, len(appointments))
-	for i, appointment := range appointments {
-		responses[i] = GetAppointmentResponse{
-			ID:              appointment.ID,
-			PatientID:       appointment.PatientID,
-			DoctorID:        appointment.DoctorID,
-			PatientName:     appointment.PatientName,
-			DoctorName:      appointment.DoctorName,
-			AppointmentDate: appointment.ScheduledAt.Format("2006-01-02"),
-			AppointmentTime: appointment.ScheduledAt.Format("15:04"),
-			Status:          string(appointment.Status),
-			Reason:          appointment.Reason,
-			Notes:           appointment.Notes,
-			CreatedAt:       appointment.CreatedAt,
-		}
-	}
-
-	return responses, nil
+	return listAppointments(ctx, uc.appointmentRepo, repository.AppointmentFilters{DoctorID: doctorID}, req)
 }
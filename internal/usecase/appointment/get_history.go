@@ -4,29 +4,37 @@ import (
 	"context"
 	"errors"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/role"
 )
 
 // GetPatientHistoryUseCase handles retrieving completed appointments (medical history)
 type GetPatientHistoryUseCase struct {
 	appointmentRepo repository.AppointmentRepository
 	userRepo        repository.UserRepository
+	authorizer      *role.Authorizer
 }
 
 // NewGetPatientHistoryUseCase creates a new instance
-func NewGetPatientHistoryUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository) *GetPatientHistoryUseCase {
+func NewGetPatientHistoryUseCase(appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, authorizer *role.Authorizer) *GetPatientHistoryUseCase {
 	return &GetPatientHistoryUseCase{
 		appointmentRepo: appointmentRepo,
 		userRepo:        userRepo,
+		authorizer:      authorizer,
 	}
 }
 
 // Execute retrieves medical history (completed appointments) for a patient
-// Doctors and admins can see any patient's history
-// Patients can only see their own history
+// Roles with history:read:any, such as doctors and nurses, can see any patient's history
+// Everyone else can only see their own history
 func (uc *GetPatientHistoryUseCase) Execute(ctx context.Context, patientID string, authenticatedUserID string, authenticatedUserRole string) ([]GetAppointmentResponse, error) {
 	// Verify permissions
-	if authenticatedUserRole == "patient" && authenticatedUserID != patientID {
+	canReadAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionHistoryReadAny)
+	if err != nil {
+		return nil, err
+	}
+	if !canReadAny && authenticatedUserID != patientID {
 		return nil, errors.New("patients can only view their own medical history")
 	}
 
@@ -54,12 +62,14 @@ func (uc *GetPatientHistoryUseCase) Execute(ctx context.Context, patientID strin
 				ID:              appointment.ID,
 				PatientID:       appointment.PatientID,
 				DoctorID:        appointment.DoctorID,
+				LocationID:      appointment.LocationID,
 				AppointmentDate: appointment.ScheduledAt.Format("2006-01-02"),
 				AppointmentTime: appointment.ScheduledAt.Format("15:04"),
 				Status:          string(appointment.Status),
 				Reason:          appointment.Reason,
 				Notes:           appointment.Notes,
 				CreatedAt:       appointment.CreatedAt,
+				Version:         appointment.Version,
 			})
 		}
 	}
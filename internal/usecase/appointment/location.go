@@ -0,0 +1,68 @@
+package appointment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/email"
+)
+
+// resolveLocation picks the location an appointment takes place at
+// The doctor's schedule block covering scheduledAt decides; without one the
+// requested location is used, or else the first location the doctor works at
+func resolveLocation(ctx context.Context, scheduleRepo repository.ScheduleRepository, clinicRepo repository.ClinicRepository, doctorID string, scheduledAt time.Time, locationID string) (string, error) {
+	schedules, err := scheduleRepo.FindByDoctorAndDay(ctx, doctorID, domain.GetDayOfWeekFromDate(scheduledAt))
+	if err != nil {
+		return "", err
+	}
+
+	at := scheduledAt.Format("15:04")
+	for _, schedule := range schedules {
+		if !schedule.IsActive || at < schedule.StartTime || at >= schedule.EndTime {
+			continue
+		}
+		if locationID != "" && locationID != schedule.LocationID {
+			return "", errors.New("doctor is at another location at that time")
+		}
+		return schedule.LocationID, nil
+	}
+
+	locations, err := clinicRepo.FindLocationsByDoctor(ctx, doctorID)
+	if err != nil {
+		return "", err
+	}
+
+	for _, location := range locations {
+		if !location.IsActive || (locationID != "" && location.ID != locationID) {
+			continue
+		}
+		return location.ID, nil
+	}
+
+	if locationID != "" {
+		return "", errors.New("doctor does not work at this location")
+	}
+	return "", errors.New("doctor does not work at any location")
+}
+
+// branchFor describes where an appointment takes place for notification emails
+// Missing records leave the fields empty so the templates fall back to defaults
+func branchFor(ctx context.Context, clinicRepo repository.ClinicRepository, locationID string) email.Branch {
+	var branch email.Branch
+
+	location, _ := clinicRepo.FindLocationByID(ctx, locationID)
+	if location == nil {
+		return branch
+	}
+	branch.Location = location.Name
+	branch.Address = location.Address
+
+	if clinic, _ := clinicRepo.FindClinicByID(ctx, location.ClinicID); clinic != nil {
+		branch.Clinic = clinic.Name
+	}
+
+	return branch
+}
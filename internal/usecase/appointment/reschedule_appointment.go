@@ -7,55 +7,52 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 )
 
-// RescheduleAppointmentUseCase handles rescheduling an appointment (admin only)
+// RescheduleAppointmentUseCase handles rescheduling an appointment
 type RescheduleAppointmentUseCase struct {
 	appointmentRepo repository.AppointmentRepository
 	serviceRepo     repository.ServiceRepository
+	scheduleRepo    repository.ScheduleRepository
+	clinicRepo      repository.ClinicRepository
+	txManager       repository.TxManager
+	recorder        *audit.Recorder
+	authorizer      *role.Authorizer
 }
 
 // NewRescheduleAppointmentUseCase creates a new instance of RescheduleAppointmentUseCase
 func NewRescheduleAppointmentUseCase(
 	appointmentRepo repository.AppointmentRepository,
 	serviceRepo repository.ServiceRepository,
+	scheduleRepo repository.ScheduleRepository,
+	clinicRepo repository.ClinicRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	authorizer *role.Authorizer,
 ) *RescheduleAppointmentUseCase {
 	return &RescheduleAppointmentUseCase{
 		appointmentRepo: appointmentRepo,
 		serviceRepo:     serviceRepo,
+		scheduleRepo:    scheduleRepo,
+		clinicRepo:      clinicRepo,
+		txManager:       txManager,
+		recorder:        recorder,
+		authorizer:      authorizer,
 	}
 }
 
 // Execute reschedules an appointment to a new date/time with validations
+// expectedVersion is the version the client last saw, zero skips the check
 func (uc *RescheduleAppointmentUseCase) Execute(
 	ctx context.Context,
 	appointmentID string,
 	authenticatedUserID string,
 	authenticatedUserRole string,
 	req RescheduleAppointmentRequest,
+	expectedVersion int,
 ) (*RescheduleAppointmentResponse, error) {
-	// Find the appointment
-	appointment, err := uc.appointmentRepo.FindByID(ctx, appointmentID)
-	if err != nil {
-		return nil, errors.New("appointment not found")
-	}
-
-	// Check permissions: patients can only reschedule their own appointments, admins can reschedule any
-	if authenticatedUserRole != "admin" {
-		if appointment.PatientID != authenticatedUserID {
-			return nil, errors.New("insufficient permissions to reschedule this appointment")
-		}
-	}
-
-	// Check if appointment can be rescheduled
-	if appointment.Status == domain.StatusCancelled {
-		return nil, errors.New("cannot reschedule a cancelled appointment")
-	}
-
-	if appointment.Status == domain.StatusCompleted {
-		return nil, errors.New("cannot reschedule a completed appointment")
-	}
-
 	// Parse new scheduled time in Peru timezone (America/Lima UTC-5)
 	location, err := time.LoadLocation("America/Lima")
 	if err != nil {
@@ -74,57 +71,88 @@ func (uc *RescheduleAppointmentUseCase) Execute(
 		return nil, errors.New("new appointment time must be in the future")
 	}
 
-	// Get service to know the duration
-	var duration int
-	if appointment.ServiceID != "" {
-		service, err := uc.serviceRepo.FindByID(ctx, appointment.ServiceID)
-		if err == nil && service != nil {
-			duration = service.DurationMinutes
-		} else {
-			// Use appointment's existing duration if service not found
-			duration = appointment.Duration
+	// Read, check and move the appointment in one unit of work so a concurrent
+	// booking or cancellation cannot slip in between the checks and the update
+	var appointment *domain.Appointment
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Find and lock the appointment
+		appointment, err = uc.appointmentRepo.FindByIDForUpdate(ctx, appointmentID)
+		if err != nil || appointment == nil {
+			return errors.New("appointment not found")
 		}
-	} else {
-		duration = appointment.Duration
-	}
 
-	// Check for conflicts with other appointments
-	endTime := newScheduledAt.Add(time.Duration(duration) * time.Minute)
+		// Check permissions: patients can only reschedule their own appointments,
+		// roles with appointments:reschedule:any can reschedule any
+		canRescheduleAny, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionAppointmentsRescheduleAny)
+		if err != nil {
+			return err
+		}
+		if !canRescheduleAny {
+			if appointment.PatientID != authenticatedUserID {
+				return errors.New("insufficient permissions to reschedule this appointment")
+			}
+		}
 
-	// Find all appointments for this doctor on the new date
-	existingAppointments, err := uc.appointmentRepo.FindByDoctorAndDate(ctx, appointment.DoctorID, newScheduledAt)
-	if err != nil {
-		return nil, errors.New("failed to check doctor availability")
-	}
+		// Refuse to act on a stale copy when the client sent the version it saw
+		if err := domain.CheckVersion(appointment.Version, expectedVersion); err != nil {
+			return err
+		}
 
-	// Check for time slot conflicts (exclude current appointment)
-	for _, existing := range existingAppointments {
-		// Skip the appointment being rescheduled
-		if existing.ID == appointmentID {
-			continue
+		// Check if appointment can be rescheduled
+		if appointment.Status == domain.StatusCancelled {
+			return errors.New("cannot reschedule a cancelled appointment")
 		}
 
-		// Skip cancelled appointments
-		if existing.Status == domain.StatusCancelled {
-			continue
+		if appointment.Status == domain.StatusCompleted {
+			return errors.New("cannot reschedule a completed appointment")
 		}
 
-		existingStart := existing.ScheduledAt
-		existingEnd := existing.EndTime()
+		before := *appointment
 
-		// Check if time slots overlap
-		if (newScheduledAt.Before(existingEnd) && endTime.After(existingStart)) {
-			return nil, errors.New("time slot conflicts with another appointment")
+		// Get service to know the duration, falling back to the appointment's own
+		if appointment.ServiceID != "" {
+			service, err := uc.serviceRepo.FindByID(ctx, appointment.ServiceID)
+			if err == nil && service != nil {
+				appointment.Duration = service.DurationMinutes
+			}
+		}
+
+		// Serialize with other bookings for this doctor
+		if err := uc.appointmentRepo.LockDoctor(ctx, appointment.DoctorID); err != nil {
+			return errors.New("failed to check doctor availability")
 		}
-	}
 
-	// Update appointment with new scheduled time
-	appointment.ScheduledAt = newScheduledAt
-	appointment.UpdatedAt = time.Now()
+		// The new time may fall in a block the doctor works at another location
+		locationID, err := resolveLocation(ctx, uc.scheduleRepo, uc.clinicRepo, appointment.DoctorID, newScheduledAt, req.LocationID)
+		if err != nil {
+			return err
+		}
+
+		// Update appointment with new scheduled time
+		appointment.ScheduledAt = newScheduledAt
+		appointment.LocationID = locationID
+		appointment.UpdatedAt = time.Now()
+
+		// Check for time slot conflicts (the appointment itself is skipped)
+		if err := checkSlotAvailable(ctx, uc.appointmentRepo, appointment); err != nil {
+			if errors.Is(err, domain.ErrSlotTaken) {
+				return err
+			}
+			return errors.New("failed to check doctor availability")
+		}
 
-	// Save updated appointment
-	if err := uc.appointmentRepo.Update(ctx, appointment); err != nil {
-		return nil, errors.New("failed to reschedule appointment")
+		// Save updated appointment
+		if err := uc.appointmentRepo.Update(ctx, appointment); err != nil {
+			if errors.Is(err, domain.ErrSlotTaken) || errors.Is(err, domain.ErrVersionConflict) {
+				return err
+			}
+			return errors.New("failed to reschedule appointment")
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityAppointment, appointment.ID, domain.AuditActionReschedule, &before, appointment)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return response
@@ -135,11 +163,13 @@ func (uc *RescheduleAppointmentUseCase) Execute(
 		PatientName:     appointment.PatientName,
 		DoctorName:      appointment.DoctorName,
 		ServiceName:     appointment.ServiceName,
+		LocationID:      appointment.LocationID,
 		AppointmentDate: appointment.ScheduledAt.Format("2006-01-02"),
 		AppointmentTime: appointment.ScheduledAt.Format("15:04"),
 		Status:          string(appointment.Status),
 		Reason:          appointment.Reason,
 		UpdatedAt:       appointment.UpdatedAt,
+		Version:         appointment.Version,
 	}
 
 	return response, nil
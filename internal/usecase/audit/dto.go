@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ListAuditLogRequest represents the filters for listing audit entries
+// Dates use the YYYY-MM-DD format; DateTo includes the whole day
+type ListAuditLogRequest struct {
+	Entity   string `json:"entity"`
+	EntityID string `json:"entity_id"`
+	ActorID  string `json:"actor_id"`
+	DateFrom string `json:"date_from"`
+	DateTo   string `json:"date_to"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
+
+// AuditEntryResponse represents an audit entry in responses
+type AuditEntryResponse struct {
+	ID        string          `json:"id"`
+	ActorID   string          `json:"actor_id,omitempty"`
+	ActorRole string          `json:"actor_role,omitempty"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entity_id"`
+	Action    string          `json:"action"`
+	Changes   json.RawMessage `json:"changes"`
+	IPAddress string          `json:"ip_address,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ListAuditLogResponse represents the output data for listing audit entries
+type ListAuditLogResponse struct {
+	Entries []AuditEntryResponse `json:"entries"`
+	Limit   int                  `json:"limit"`
+	Offset  int                  `json:"offset"`
+	HasMore bool                 `json:"has_more"`
+}
@@ -0,0 +1,91 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// ListAuditLogUseCase handles retrieving audit entries with filters (admin only)
+type ListAuditLogUseCase struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewListAuditLogUseCase creates a new instance of ListAuditLogUseCase
+func NewListAuditLogUseCase(auditRepo repository.AuditRepository) *ListAuditLogUseCase {
+	return &ListAuditLogUseCase{
+		auditRepo: auditRepo,
+	}
+}
+
+// Execute retrieves a page of audit entries, newest first
+func (uc *ListAuditLogUseCase) Execute(ctx context.Context, req ListAuditLogRequest) (*ListAuditLogResponse, error) {
+	// Validate and set default limit
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	if req.Limit > 200 {
+		req.Limit = 200
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	filters := repository.AuditFilters{
+		Entity:   req.Entity,
+		EntityID: req.EntityID,
+		ActorID:  req.ActorID,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	}
+
+	// Parse date filters if provided
+	if req.DateFrom != "" {
+		dateFrom, err := time.Parse("2006-01-02", req.DateFrom)
+		if err != nil {
+			return nil, errors.New("invalid date_from format, use YYYY-MM-DD")
+		}
+		filters.DateFrom = &dateFrom
+	}
+
+	if req.DateTo != "" {
+		dateTo, err := time.Parse("2006-01-02", req.DateTo)
+		if err != nil {
+			return nil, errors.New("invalid date_to format, use YYYY-MM-DD")
+		}
+		// Set to end of day
+		endOfDay := dateTo.Add(24*time.Hour - time.Nanosecond)
+		filters.DateTo = &endOfDay
+	}
+
+	entries, err := uc.auditRepo.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert domain entries to response DTOs
+	responses := make([]AuditEntryResponse, len(entries))
+	for i, entry := range entries {
+		responses[i] = AuditEntryResponse{
+			ID:        entry.ID,
+			ActorID:   entry.ActorID,
+			ActorRole: entry.ActorRole,
+			Entity:    entry.Entity,
+			EntityID:  entry.EntityID,
+			Action:    entry.Action,
+			Changes:   entry.Changes,
+			IPAddress: entry.IPAddress,
+			RequestID: entry.RequestID,
+			CreatedAt: entry.CreatedAt,
+		}
+	}
+
+	return &ListAuditLogResponse{
+		Entries: responses,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+		HasMore: len(entries) == req.Limit,
+	}, nil
+}
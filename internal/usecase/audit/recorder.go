@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+)
+
+// Recorder writes audit entries on behalf of the state-changing use cases
+// The actor, client IP and request ID are read from the request context set up
+// by AuthMiddleware and RequestIDMiddleware; calls made outside an HTTP request
+// are recorded with an empty actor
+type Recorder struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewRecorder creates a new instance of Recorder
+func NewRecorder(auditRepo repository.AuditRepository) *Recorder {
+	return &Recorder{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record stores an audit entry describing how an entity changed
+// before and after are snapshots of the entity (nil for creations and deletions);
+// only the JSON fields that differ between them are kept
+// Call it with the transaction context of the change so both commit together
+func (r *Recorder) Record(ctx context.Context, entity, entityID, action string, before, after interface{}) error {
+	changes, err := diff(before, after)
+	if err != nil {
+		return err
+	}
+
+	entry := &domain.AuditEntry{
+		ID:        uuid.New().String(),
+		ActorID:   requestctx.String(ctx, requestctx.UserIDKey),
+		ActorRole: requestctx.String(ctx, requestctx.RoleKey),
+		Entity:    entity,
+		EntityID:  entityID,
+		Action:    action,
+		Changes:   changes,
+		IPAddress: requestctx.String(ctx, requestctx.ClientIPKey),
+		RequestID: requestctx.String(ctx, requestctx.RequestIDKey),
+		CreatedAt: time.Now(),
+	}
+
+	return r.auditRepo.Create(ctx, entry)
+}
+
+// redacted replaces the values of fields tagged audit:"redact" in the changes
+const redacted = "[redacted]"
+
+// fieldChange is the before/after pair stored for each changed field
+type fieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// diff compares the JSON form of two snapshots and returns the changed fields
+// Sensitive fields are reported as changed without their values
+func diff(before, after interface{}) (json.RawMessage, error) {
+	beforeFields, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]fieldChange)
+	for name, value := range beforeFields {
+		if other, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, other) {
+			changes[name] = fieldChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = fieldChange{Before: nil, After: value}
+		}
+	}
+
+	for name := range redactedFields(before, after) {
+		change, ok := changes[name]
+		if !ok {
+			continue
+		}
+		if change.Before != nil {
+			change.Before = redacted
+		}
+		if change.After != nil {
+			change.After = redacted
+		}
+		changes[name] = change
+	}
+
+	return json.Marshal(changes)
+}
+
+// redactedFields returns the JSON names of the fields tagged audit:"redact"
+// in the structs the snapshots point to
+func redactedFields(snapshots ...interface{}) map[string]bool {
+	result := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		if snapshot == nil {
+			continue
+		}
+		t := reflect.TypeOf(snapshot)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			continue
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Tag.Get("audit") != "redact" {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			result[name] = true
+		}
+	}
+
+	return result
+}
+
+// fields decodes a snapshot into a map keyed by JSON field name
+// Fields tagged json:"-", such as password hashes, never reach the audit log
+func fields(snapshot interface{}) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	if snapshot == nil {
+		return result, nil
+	}
+	if v := reflect.ValueOf(snapshot); v.Kind() == reflect.Ptr && v.IsNil() {
+		return result, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
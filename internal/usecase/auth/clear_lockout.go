@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// errLockoutNotFound is returned for unknown lockouts and ones that already ended
+var errLockoutNotFound = errors.New("lockout not found")
+
+// ClearLockoutUseCase handles the business logic for an admin lifting a lockout
+type ClearLockoutUseCase struct {
+	lockoutRepo repository.LoginLockoutRepository
+	txManager   repository.TxManager
+	recorder    *audit.Recorder
+}
+
+// NewClearLockoutUseCase creates a new instance of ClearLockoutUseCase
+func NewClearLockoutUseCase(
+	lockoutRepo repository.LoginLockoutRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *ClearLockoutUseCase {
+	return &ClearLockoutUseCase{
+		lockoutRepo: lockoutRepo,
+		txManager:   txManager,
+		recorder:    recorder,
+	}
+}
+
+// Execute lifts the lockout of an account or client address and forgets its
+// failed logins, so it can log in again right away
+// The unlock link emailed for an account stops working
+func (uc *ClearLockoutUseCase) Execute(ctx context.Context, lockoutID string) (*LockoutResponse, error) {
+	if strings.TrimSpace(lockoutID) == "" {
+		return nil, errors.New("lockout ID is required")
+	}
+
+	lockout, err := uc.lockoutRepo.FindByID(ctx, lockoutID)
+	if err != nil {
+		return nil, err
+	}
+	if lockout == nil || !lockout.IsLocked(time.Now()) {
+		return nil, errLockoutNotFound
+	}
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := uc.lockoutRepo.Delete(ctx, lockout.ID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return errLockoutNotFound
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityLoginLockout, lockout.ID, domain.AuditActionUnlock, lockout, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toLockoutResponse(lockout), nil
+}
@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// CompleteSSOUseCase handles the business logic for the return from the
+// single sign-on provider: trading the code it sent for the session tokens
+type CompleteSSOUseCase struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.ExternalIdentityRepository
+	sso          *SSOService
+	tokens       *TokenService
+}
+
+// NewCompleteSSOUseCase creates a new instance of CompleteSSOUseCase
+func NewCompleteSSOUseCase(
+	userRepo repository.UserRepository,
+	identityRepo repository.ExternalIdentityRepository,
+	sso *SSOService,
+	tokens *TokenService,
+) *CompleteSSOUseCase {
+	return &CompleteSSOUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		sso:          sso,
+		tokens:       tokens,
+	}
+}
+
+// Execute starts the session of the staff member the provider identified
+// browserStateID is the ID of the state kept by the browser when the flow
+// started; a state sent from another browser, or sent twice, is refused
+// The first login links the identity to the active staff account with the
+// email the provider verified; later logins find the account by the identity
+// Single sign-on replaces the password and the second factor of the account:
+// the provider is trusted to ask for both
+func (uc *CompleteSSOUseCase) Execute(ctx context.Context, req SSOCallbackRequest, browserStateID string) (*LoginResponse, error) {
+	if !uc.sso.Enabled() {
+		return nil, errSSONotConfigured
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		return nil, errors.New("code is required")
+	}
+	if strings.TrimSpace(req.State) == "" {
+		return nil, errors.New("state is required")
+	}
+
+	state, err := uc.sso.parseState(req.State)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.sso.useState(ctx, state, browserStateID); err != nil {
+		return nil, err
+	}
+
+	claims, err := uc.sso.provider.Exchange(ctx, req.Code, uc.sso.verifier(state), state.nonce)
+	if err != nil {
+		log.Printf("Error completing single sign-on: %v", err)
+		return nil, errSSOFailed
+	}
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	email = strings.TrimSpace(email)
+
+	// The flow continues in the clinic it was started for
+	ctx = repository.WithClinic(ctx, state.clinicID)
+	identity, err := uc.identityRepo.FindBySubject(ctx, uc.sso.provider.Issuer(), subject)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *domain.User
+	if identity != nil {
+		if user, err = uc.userRepo.FindByID(ctx, identity.UserID); err != nil {
+			return nil, err
+		}
+	} else {
+		// Linking trusts the email, so the provider must have verified it
+		if verified, _ := claims["email_verified"].(bool); !verified || email == "" {
+			return nil, errSSOEmailNotVerified
+		}
+		if user, err = uc.userRepo.FindByEmail(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+
+	if user == nil {
+		return nil, errSSONoAccount
+	}
+	if !user.Role.IsStaff() {
+		return nil, errSSOStaffOnly
+	}
+	if !user.IsActive {
+		return nil, errors.New("user is inactive")
+	}
+	if !slices.Contains(uc.sso.grantedRoles(claims), user.Role) {
+		return nil, errSSORoleNotGranted
+	}
+
+	now := time.Now()
+	if identity == nil {
+		if err := uc.sso.link(ctx, user, subject, email, now); err != nil {
+			return nil, err
+		}
+	} else {
+		if email == "" {
+			email = identity.Email
+		}
+		if err := uc.identityRepo.RecordLogin(ctx, identity.ID, email, now); err != nil {
+			log.Printf("Error recording single sign-on login: %v", err)
+		}
+	}
+
+	// Start a new session with its first pair of tokens
+	return uc.tokens.Start(ctx, user)
+}
@@ -0,0 +1,284 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
+	"version-1-0/pkg/jwtkeys"
+	"version-1-0/pkg/oidc"
+)
+
+// testClientID is the client the API is registered as at the test provider
+const testClientID = "clinica-api"
+
+// testProvider is an OpenID Connect provider that signs in whoever the test
+// says, once per code
+type testProvider struct {
+	server *httptest.Server
+	key    ed25519.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]jwt.MapClaims // Claims of the ID token, keyed by code
+	pkce  map[string]string        // Challenge of the flow, keyed by code
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	p := &testProvider{
+		key:   private,
+		codes: make(map[string]jwt.MapClaims),
+		pkce:  make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.server.URL,
+			"authorization_endpoint": p.server.URL + "/authorize",
+			"token_endpoint":         p.server.URL + "/token",
+			"jwks_uri":               p.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"kid": "test",
+				"use": "sig",
+				"x":   base64.RawURLEncoding.EncodeToString(public),
+			}},
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		code := r.PostFormValue("code")
+		p.mu.Lock()
+		claims, ok := p.codes[code]
+		challenge := p.pkce[code]
+		delete(p.codes, code)
+		p.mu.Unlock()
+		if !ok || oidc.Challenge(r.PostFormValue("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(p.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idToken})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+// signIn plays the login page of the provider: it issues a code for the flow
+// of authorizationURL whose ID token carries the given claims
+func (p *testProvider) signIn(t *testing.T, authorizationURL string, claims jwt.MapClaims) string {
+	t.Helper()
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("parse authorization URL: %v", err)
+	}
+	query := parsed.Query()
+
+	now := time.Now()
+	idClaims := jwt.MapClaims{
+		"iss":   p.server.URL,
+		"aud":   testClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": query.Get("nonce"),
+	}
+	for name, value := range claims {
+		idClaims[name] = value
+	}
+
+	code := "code-" + query.Get("nonce")
+	p.mu.Lock()
+	p.codes[code] = idClaims
+	p.pkce[code] = query.Get("code_challenge")
+	p.mu.Unlock()
+	return code
+}
+
+// ssoFixture holds the single sign-on use cases on one memory store
+type ssoFixture struct {
+	ctx          context.Context
+	provider     *testProvider
+	userRepo     repository.UserRepository
+	identityRepo repository.ExternalIdentityRepository
+	start        *auth.StartSSOUseCase
+	complete     *auth.CompleteSSOUseCase
+}
+
+func newSSOFixture(t *testing.T) *ssoFixture {
+	t.Helper()
+	store := memory.NewStore()
+	provider := newTestProvider(t)
+	userRepo := memory.NewMemoryUserRepository(store)
+	identityRepo := memory.NewMemoryExternalIdentityRepository(store)
+
+	sso := auth.NewSSOService(
+		oidc.New(oidc.Config{Issuer: provider.server.URL, ClientID: testClientID, RedirectURL: "http://localhost:5173/auth/sso/callback", Scopes: []string{"openid", "email"}}),
+		identityRepo,
+		memory.NewMemoryUsedTokenRepository(store),
+		memory.NewMemoryTxManager(store),
+		audit.NewRecorder(memory.NewMemoryAuditRepository(store)),
+		"test-secret",
+		"realm_access.roles",
+		map[string]domain.UserRole{"medicos": domain.RoleDoctor, "recepcion": domain.RoleReceptionist},
+		nil,
+	)
+	tokens := auth.NewTokenService(memory.NewMemoryRefreshTokenRepository(store), memory.NewMemorySessionRepository(store), jwtkeys.NewHMAC("test-secret"), 15, 30)
+
+	return &ssoFixture{
+		ctx:          context.Background(),
+		provider:     provider,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		start:        auth.NewStartSSOUseCase(sso),
+		complete:     auth.NewCompleteSSOUseCase(userRepo, identityRepo, sso, tokens),
+	}
+}
+
+func (f *ssoFixture) createUser(t *testing.T, id, email string, role domain.UserRole) {
+	t.Helper()
+	now := time.Now()
+	user := &domain.User{
+		ID:           id,
+		Email:        email,
+		PasswordHash: "hash",
+		FirstName:    "Ana",
+		LastName:     "Torres",
+		Role:         role,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := f.userRepo.Create(f.ctx, user); err != nil {
+		t.Fatalf("create %s: %v", id, err)
+	}
+}
+
+// begin starts a flow and signs in at the provider with the given claims
+// It returns the callback request and the state ID the browser keeps
+func (f *ssoFixture) begin(t *testing.T, claims jwt.MapClaims) (auth.SSOCallbackRequest, string) {
+	t.Helper()
+	started, err := f.start.Execute(f.ctx)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	code := f.provider.signIn(t, started.AuthorizationURL, claims)
+	return auth.SSOCallbackRequest{Code: code, State: started.State}, started.StateID
+}
+
+// staffClaims returns the claims of a person of the provider with a verified email
+func staffClaims(subject, email string, roles ...string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":            subject,
+		"email":          email,
+		"email_verified": true,
+		"realm_access":   map[string]interface{}{"roles": roles},
+	}
+}
+
+func TestCompleteSSO(t *testing.T) {
+	f := newSSOFixture(t)
+	f.createUser(t, "doctor-user", "maria@clinica.test", domain.RoleDoctor)
+	f.createUser(t, "receptionist-user", "rosa@clinica.test", domain.RoleReceptionist)
+	f.createUser(t, "patient-user", "sofia@clinica.test", domain.RolePatient)
+
+	unverified := staffClaims("subject-rosa-2", "rosa@clinica.test", "recepcion")
+	unverified["email_verified"] = false
+
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		wantUser string
+		wantErr  string
+	}{
+		{"links a staff account by verified email", staffClaims("subject-maria", "maria@clinica.test", "medicos"), "doctor-user", ""},
+		{"finds a linked account by subject", staffClaims("subject-maria", "maria.salazar@otra.test", "medicos"), "doctor-user", ""},
+		{"maps nested role claims", staffClaims("subject-rosa", "rosa@clinica.test", "offline_access", "recepcion"), "receptionist-user", ""},
+		{"refuses unverified emails", unverified, "", "the provider has not verified this email"},
+		{"refuses roles the provider does not grant", staffClaims("subject-maria-2", "maria@clinica.test", "recepcion"), "", "the provider does not grant the role of this account"},
+		{"refuses a second identity for a linked account", staffClaims("subject-maria-2", "maria@clinica.test", "medicos"), "", "this account is linked to another identity of the provider"},
+		{"refuses unmapped roles", staffClaims("subject-rosa-2", "rosa@clinica.test", "enfermeria"), "", "the provider does not grant the role of this account"},
+		{"refuses patients", staffClaims("subject-sofia", "sofia@clinica.test", "medicos"), "", "single sign-on is only available for staff accounts"},
+		{"refuses unknown emails", staffClaims("subject-luis", "luis@clinica.test", "medicos"), "", "no staff account for this email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, stateID := f.begin(t, tt.claims)
+			response, err := f.complete.Execute(f.ctx, req, stateID)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if response.User.ID != tt.wantUser || response.Token == "" || response.RefreshToken == "" {
+				t.Errorf("logged in as %q with token %t, want %q with tokens", response.User.ID, response.Token != "", tt.wantUser)
+			}
+		})
+	}
+
+	identity, err := f.identityRepo.FindBySubject(f.ctx, f.provider.server.URL, "subject-maria")
+	if err != nil || identity == nil || identity.UserID != "doctor-user" {
+		t.Errorf("identity of subject-maria = %+v, %v; want it linked to doctor-user", identity, err)
+	}
+}
+
+func TestCompleteSSOBindsStateToBrowser(t *testing.T) {
+	f := newSSOFixture(t)
+	f.createUser(t, "doctor-user", "maria@clinica.test", domain.RoleDoctor)
+	claims := staffClaims("subject-maria", "maria@clinica.test", "medicos")
+
+	// A state started in another browser, such as the attacker's own flow
+	// sent to a victim, is refused
+	req, _ := f.begin(t, claims)
+	_, otherStateID := f.begin(t, claims)
+	for _, stateID := range []string{"", otherStateID} {
+		if _, err := f.complete.Execute(f.ctx, req, stateID); err == nil || err.Error() != "single sign-on was started in another browser" {
+			t.Errorf("Execute with state ID %q error = %v, want another browser", stateID, err)
+		}
+	}
+
+	// A state completes one login only
+	req, stateID := f.begin(t, claims)
+	if _, err := f.complete.Execute(f.ctx, req, stateID); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if _, err := f.complete.Execute(f.ctx, req, stateID); err == nil || err.Error() != "invalid or expired single sign-on state" {
+		t.Errorf("replayed state error = %v, want invalid state", err)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// ConfirmTwoFactorUseCase handles the business logic for enabling an enrolled
+// second factor with a first code from the authenticator app
+type ConfirmTwoFactorUseCase struct {
+	userRepo  repository.UserRepository
+	twoFactor *TwoFactorService
+}
+
+// NewConfirmTwoFactorUseCase creates a new instance of ConfirmTwoFactorUseCase
+func NewConfirmTwoFactorUseCase(userRepo repository.UserRepository, twoFactor *TwoFactorService) *ConfirmTwoFactorUseCase {
+	return &ConfirmTwoFactorUseCase{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// Execute enables the pending second factor of the user and returns their recovery codes
+// Wrong codes count as failed logins; the returned duration tells how long to
+// wait once they are refused
+func (uc *ConfirmTwoFactorUseCase) Execute(ctx context.Context, userID string, req TwoFactorCodeRequest) (*RecoveryCodesResponse, time.Duration, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if user == nil {
+		return nil, 0, errors.New("user not found")
+	}
+
+	codes, wait, err := uc.twoFactor.Confirm(ctx, user, req.Code)
+	if err != nil {
+		return nil, wait, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, 0, nil
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// DisableTwoFactorUseCase handles the business logic for a user turning off
+// their own second factor
+type DisableTwoFactorUseCase struct {
+	userRepo  repository.UserRepository
+	twoFactor *TwoFactorService
+}
+
+// NewDisableTwoFactorUseCase creates a new instance of DisableTwoFactorUseCase
+func NewDisableTwoFactorUseCase(userRepo repository.UserRepository, twoFactor *TwoFactorService) *DisableTwoFactorUseCase {
+	return &DisableTwoFactorUseCase{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// Execute removes the second factor and recovery codes of the user
+// The request must carry a code from the authenticator app or a recovery code;
+// wrong codes count as failed logins and the returned duration tells how long
+// to wait once they are refused. Roles that require a second factor cannot
+// disable it, only ask an admin to reset it
+func (uc *DisableTwoFactorUseCase) Execute(ctx context.Context, userID string, req TwoFactorCodeRequest) (time.Duration, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, errors.New("user not found")
+	}
+	if uc.twoFactor.Required(user.Role) {
+		return 0, errors.New("two-factor authentication is required for your role")
+	}
+
+	twoFactor, err := uc.twoFactor.enabled(ctx, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	if wait, err := uc.twoFactor.Verify(ctx, user, twoFactor, req.Code, req.RecoveryCode); err != nil {
+		return wait, err
+	}
+
+	return 0, uc.twoFactor.remove(ctx, twoFactor, domain.AuditActionDisable)
+}
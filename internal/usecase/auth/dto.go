@@ -9,15 +9,148 @@ type LoginRequest struct {
 }
 
 // LoginResponse represents the output data after successful authentication
-// Includes the JWT token, expiration time, and user information
+// Includes the short-lived access token, the refresh token that renews it,
+// their expiration times, and user information
+// RecoveryCodes is only set by the login that completes a required two-factor
+// enrollment. When a second step is needed, TwoFactor carries the challenge
+// and the handler answers with it instead of tokens
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-	User      struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	User             struct {
 		ID        string `json:"id"`
 		Email     string `json:"email"`
 		FirstName string `json:"first_name"`
 		LastName  string `json:"last_name"`
 		Role      string `json:"role"`
 	} `json:"user"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+
+	TwoFactor *TwoFactorChallenge `json:"-"`
+}
+
+// TwoFactorChallenge is the answer to a login with the right password when the
+// user must still give a code. SetupRequired means the role requires a second
+// factor the user has not enrolled yet: the client starts with POST /api/auth/2fa/setup
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	SetupRequired     bool      `json:"setup_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// TwoFactorSetupRequest represents the input data for enrolling a second
+// factor during a login, with the challenge token the login returned
+type TwoFactorSetupRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+// TwoFactorSetupResponse represents a new TOTP secret to add to an authenticator app
+// OTPAuthURI is meant to be shown as a QR code; Secret is for typing it by hand
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// VerifyTwoFactorRequest represents the input data for the second step of a login
+// Code comes from the authenticator app; RecoveryCode may be sent instead
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code,omitempty"`
+}
+
+// TwoFactorCodeRequest represents a code proving the user holds their second factor
+type TwoFactorCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+// RecoveryCodesResponse represents a new set of recovery codes, shown only once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TwoFactorStatusResponse represents the second factor of a user
+// Required tells whether the role of the user cannot disable it
+type TwoFactorStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// RefreshRequest represents the input data for exchanging a refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest represents the input data for ending a session
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ForgotPasswordRequest represents the input data for requesting a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest represents the input data for choosing a new password
+// with the token of a password reset link
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// VerifyEmailResponse represents the output data after confirming an email address
+type VerifyEmailResponse struct {
+	Email           string    `json:"email"`
+	EmailVerifiedAt time.Time `json:"email_verified_at"`
+}
+
+// UnlockAccountRequest represents the input data for unlocking an account
+// with the token emailed when it was locked
+type UnlockAccountRequest struct {
+	Token string `json:"token"`
+}
+
+// LockoutResponse represents a locked account or client IP address
+// Subject is the email typed at login for accounts, or the address for IPs
+type LockoutResponse struct {
+	ID            string    `json:"id"`
+	Scope         string    `json:"scope"`
+	Subject       string    `json:"subject"`
+	Failures      int       `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+// SessionResponse represents a session of a user on one device
+// Current marks the session of the access token making the request
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
+
+// SSOStartResponse represents the start of a single sign-on login
+// The client keeps State to compare it with the one the provider sends back,
+// then opens AuthorizationURL. StateID goes in a cookie, not in the body
+type SSOStartResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	StateID          string    `json:"-"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// SSOCallbackRequest represents the code and state the provider sent back to the web app
+type SSOCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
 }
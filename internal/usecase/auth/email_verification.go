@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/email"
+)
+
+// verificationResendInterval is the minimum time between two verification emails to one user
+const verificationResendInterval = 5 * time.Minute
+
+// EmailVerification signs and sends the links that confirm a user owns their email
+// Links are signed rather than stored: they carry the user, clinic and email
+// they were issued for, so changing the email invalidates the links sent before
+type EmailVerification struct {
+	userRepo     repository.UserRepository
+	clinicRepo   repository.ClinicRepository
+	emailService *email.EmailService
+	key          []byte
+	verifyURL    string
+	linkTTL      time.Duration
+}
+
+// NewEmailVerification creates a new instance of EmailVerification
+// The signing key is derived from the JWT secret, so a verification link can
+// never be used as an access token nor the other way around
+// frontendURL is the base URL of the web app, whose /verify-email page
+// receives the token and calls GET /api/auth/verify-email
+func NewEmailVerification(
+	userRepo repository.UserRepository,
+	clinicRepo repository.ClinicRepository,
+	emailService *email.EmailService,
+	jwtSecret string,
+	frontendURL string,
+	linkHours int,
+) *EmailVerification {
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte("email-verification"))
+
+	return &EmailVerification{
+		userRepo:     userRepo,
+		clinicRepo:   clinicRepo,
+		emailService: emailService,
+		key:          mac.Sum(nil),
+		verifyURL:    frontendURL + "/verify-email",
+		linkTTL:      time.Duration(linkHours) * time.Hour,
+	}
+}
+
+// Send emails a new verification link to user and records when it was sent
+// The email goes out in the background; failures are logged by the email service
+func (v *EmailVerification) Send(ctx context.Context, user *domain.User) error {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":       user.ID,
+		"clinic_id": user.ClinicID,
+		"email":     user.Email,
+		"iat":       now.Unix(),
+		"exp":       now.Add(v.linkTTL).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(v.key)
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	if err := v.userRepo.MarkVerificationSent(ctx, user.ID, now); err != nil {
+		return err
+	}
+
+	if v.emailService != nil {
+		var branch email.Branch
+		if clinic, _ := v.clinicRepo.FindClinicByID(ctx, user.ClinicID); clinic != nil {
+			branch.Clinic = clinic.Name
+		}
+
+		go v.emailService.SendEmailVerification(
+			branch,
+			user.Email,
+			user.FirstName+" "+user.LastName,
+			v.verifyURL+"?token="+url.QueryEscape(token),
+			fmt.Sprintf("%d horas", int(v.linkTTL.Hours())),
+		)
+	}
+
+	return nil
+}
+
+// SendNew sends the first verification link of a newly created user
+// A failure is only logged: the account exists and the user can ask for
+// another link, so it must not turn the signup into an error
+func (v *EmailVerification) SendNew(ctx context.Context, user *domain.User) {
+	if err := v.Send(ctx, user); err != nil {
+		log.Printf("Error sending verification email to user %s: %v", user.ID, err)
+	}
+}
+
+// verificationClaims is what a valid verification link was issued for
+type verificationClaims struct {
+	userID   string
+	clinicID string
+	email    string
+}
+
+// parse checks the signature and expiration of a verification link token
+func (v *EmailVerification) parse(token string) (*verificationClaims, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		return v.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !parsed.Valid {
+		return nil, errors.New("invalid or expired verification link")
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid or expired verification link")
+	}
+	userID, _ := claims["sub"].(string)
+	clinicID, _ := claims["clinic_id"].(string)
+	address, _ := claims["email"].(string)
+	if userID == "" || clinicID == "" || address == "" {
+		return nil, errors.New("invalid or expired verification link")
+	}
+
+	return &verificationClaims{userID: userID, clinicID: clinicID, email: address}, nil
+}
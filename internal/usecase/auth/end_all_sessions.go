@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// EndAllSessionsUseCase handles the business logic for a user signing out everywhere
+type EndAllSessionsUseCase struct {
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	txManager        repository.TxManager
+}
+
+// NewEndAllSessionsUseCase creates a new instance of EndAllSessionsUseCase
+func NewEndAllSessionsUseCase(sessionRepo repository.SessionRepository, refreshTokenRepo repository.RefreshTokenRepository, txManager repository.TxManager) *EndAllSessionsUseCase {
+	return &EndAllSessionsUseCase{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		txManager:        txManager,
+	}
+}
+
+// Execute revokes every session of the user except keepSessionID, which may be
+// empty to end the session of the request too. Returns how many were ended
+func (uc *EndAllSessionsUseCase) Execute(ctx context.Context, userID, keepSessionID string) (int, error) {
+	if userID == "" {
+		return 0, errors.New("user ID is required")
+	}
+
+	ended := 0
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		sessions, err := uc.sessionRepo.ListActiveByUser(ctx, userID, now)
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions {
+			if session.ID != keepSessionID {
+				ended++
+			}
+		}
+
+		return uc.refreshTokenRepo.RevokeUserSessions(ctx, userID, keepSessionID, now)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return ended, nil
+}
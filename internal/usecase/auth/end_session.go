@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// EndSessionUseCase handles the business logic for a user signing out one of their devices
+type EndSessionUseCase struct {
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+// NewEndSessionUseCase creates a new instance of EndSessionUseCase
+func NewEndSessionUseCase(sessionRepo repository.SessionRepository, refreshTokenRepo repository.RefreshTokenRepository) *EndSessionUseCase {
+	return &EndSessionUseCase{
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+// Execute revokes a session of the user, which ends its access tokens on their
+// next request. Sessions of other users and sessions already ended are not found
+func (uc *EndSessionUseCase) Execute(ctx context.Context, userID, sessionID string) error {
+	if strings.TrimSpace(sessionID) == "" {
+		return errors.New("session ID is required")
+	}
+
+	session, err := uc.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session == nil || session.UserID != userID {
+		return errors.New("session not found")
+	}
+
+	revoked, err := uc.refreshTokenRepo.IsSessionRevoked(ctx, session.ID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errors.New("session not found")
+	}
+
+	return uc.refreshTokenRepo.RevokeSession(ctx, session.ID, time.Now())
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+)
+
+// EndUserSessionsUseCase handles the business logic for an admin signing a user
+// out of every device, such as a deactivated account or a stolen laptop
+type EndUserSessionsUseCase struct {
+	userRepo         repository.UserRepository
+	sessionRepo      repository.SessionRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	txManager        repository.TxManager
+	recorder         *audit.Recorder
+}
+
+// NewEndUserSessionsUseCase creates a new instance of EndUserSessionsUseCase
+func NewEndUserSessionsUseCase(
+	userRepo repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *EndUserSessionsUseCase {
+	return &EndUserSessionsUseCase{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		txManager:        txManager,
+		recorder:         recorder,
+	}
+}
+
+// Execute revokes every session of a user, active or deactivated, and returns
+// how many were ended. Deactivated users are already refused on every request,
+// but their sessions would work again if the account were reactivated
+func (uc *EndUserSessionsUseCase) Execute(ctx context.Context, userID string) (int, error) {
+	if strings.TrimSpace(userID) == "" {
+		return 0, errors.New("user ID is required")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, errors.New("user not found")
+	}
+	// Only admins end the sessions of another admin
+	role, _ := ctx.Value(requestctx.RoleKey).(string)
+	if !domain.UserRole(role).CanManage(user.Role) {
+		return 0, errors.New("only administrators can end the sessions of an administrator")
+	}
+
+	ended := 0
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		sessions, err := uc.sessionRepo.ListActiveByUser(ctx, user.ID, now)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.refreshTokenRepo.RevokeUserSessions(ctx, user.ID, "", now); err != nil {
+			return err
+		}
+
+		for _, session := range sessions {
+			if err := uc.recorder.Record(ctx, domain.AuditEntitySession, session.ID, domain.AuditActionRevoke, session, nil); err != nil {
+				return err
+			}
+		}
+		ended = len(sessions)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return ended, nil
+}
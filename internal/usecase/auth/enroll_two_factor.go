@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/repository"
+)
+
+// EnrollTwoFactorUseCase handles the business logic for a signed in staff
+// member adding a second factor to their account
+type EnrollTwoFactorUseCase struct {
+	userRepo  repository.UserRepository
+	twoFactor *TwoFactorService
+}
+
+// NewEnrollTwoFactorUseCase creates a new instance of EnrollTwoFactorUseCase
+func NewEnrollTwoFactorUseCase(userRepo repository.UserRepository, twoFactor *TwoFactorService) *EnrollTwoFactorUseCase {
+	return &EnrollTwoFactorUseCase{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// Execute stores a new secret for the user, replacing one that was never confirmed
+// It only becomes active once ConfirmTwoFactorUseCase accepts a first code
+func (uc *EnrollTwoFactorUseCase) Execute(ctx context.Context, userID string) (*TwoFactorSetupResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	return uc.twoFactor.Enroll(ctx, user)
+}
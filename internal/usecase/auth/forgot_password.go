@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/pkg/email"
+)
+
+// ForgotPasswordUseCase handles the business logic for requesting a password reset link
+type ForgotPasswordUseCase struct {
+	userRepo       repository.UserRepository
+	resetTokenRepo repository.PasswordResetTokenRepository
+	clinicRepo     repository.ClinicRepository
+	emailService   *email.EmailService
+	resetURL       string
+	tokenTTL       time.Duration
+}
+
+// NewForgotPasswordUseCase creates a new instance of ForgotPasswordUseCase
+// frontendURL is the base URL of the web app, whose /reset-password page
+// receives the token and calls POST /api/auth/reset-password
+func NewForgotPasswordUseCase(
+	userRepo repository.UserRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	clinicRepo repository.ClinicRepository,
+	emailService *email.EmailService,
+	frontendURL string,
+	tokenMinutes int,
+) *ForgotPasswordUseCase {
+	return &ForgotPasswordUseCase{
+		userRepo:       userRepo,
+		resetTokenRepo: resetTokenRepo,
+		clinicRepo:     clinicRepo,
+		emailService:   emailService,
+		resetURL:       frontendURL + "/reset-password",
+		tokenTTL:       time.Duration(tokenMinutes) * time.Minute,
+	}
+}
+
+// Execute emails a password reset link to the user with the given email
+// The result is the same whether or not the email belongs to an active user,
+// so the endpoint cannot be used to find out which emails are registered
+// A new link replaces the ones sent before
+func (uc *ForgotPasswordUseCase) Execute(ctx context.Context, req ForgotPasswordRequest) error {
+	if strings.TrimSpace(req.Email) == "" {
+		return errors.New("email is required")
+	}
+
+	user, err := uc.userRepo.FindByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return nil
+	}
+
+	token, err := NewOpaqueToken()
+	if err != nil {
+		return errors.New("failed to generate token")
+	}
+
+	now := time.Now()
+	stored := &domain.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: HashToken(token),
+		ExpiresAt: now.Add(uc.tokenTTL),
+		CreatedAt: now,
+	}
+	if err := uc.resetTokenRepo.InvalidateUserTokens(ctx, user.ID, now); err != nil {
+		return err
+	}
+	if err := uc.resetTokenRepo.Create(ctx, stored); err != nil {
+		return err
+	}
+
+	if uc.emailService != nil {
+		var branch email.Branch
+		if clinic, _ := uc.clinicRepo.FindClinicByID(ctx, user.ClinicID); clinic != nil {
+			branch.Clinic = clinic.Name
+		}
+
+		// Sent in the background so the response time does not tell
+		// registered emails apart; failures are logged by the email service
+		go uc.emailService.SendPasswordReset(
+			branch,
+			user.Email,
+			user.FirstName+" "+user.LastName,
+			uc.resetURL+"?token="+url.QueryEscape(token),
+			fmt.Sprintf("%d minutos", int(uc.tokenTTL.Minutes())),
+		)
+	}
+
+	return nil
+}
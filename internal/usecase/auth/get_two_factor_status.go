@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/repository"
+)
+
+// GetTwoFactorStatusUseCase handles the business logic for showing a user
+// whether their account has a second factor
+type GetTwoFactorStatusUseCase struct {
+	userRepo      repository.UserRepository
+	twoFactorRepo repository.TwoFactorRepository
+	twoFactor     *TwoFactorService
+}
+
+// NewGetTwoFactorStatusUseCase creates a new instance of GetTwoFactorStatusUseCase
+func NewGetTwoFactorStatusUseCase(userRepo repository.UserRepository, twoFactorRepo repository.TwoFactorRepository, twoFactor *TwoFactorService) *GetTwoFactorStatusUseCase {
+	return &GetTwoFactorStatusUseCase{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		twoFactor:     twoFactor,
+	}
+}
+
+// Execute returns the second factor status of the user
+// An enrollment that was never confirmed counts as not enabled
+func (uc *GetTwoFactorStatusUseCase) Execute(ctx context.Context, userID string) (*TwoFactorStatusResponse, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	status := &TwoFactorStatusResponse{
+		Required: uc.twoFactor.Required(user.Role),
+	}
+
+	twoFactor, err := uc.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.IsEnabled() {
+		return status, nil
+	}
+
+	status.Enabled = true
+	status.EnabledAt = twoFactor.EnabledAt
+	if status.RecoveryCodesRemaining, err = uc.twoFactorRepo.CountRecoveryCodes(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
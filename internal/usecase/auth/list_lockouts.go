@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// ListLockoutsUseCase handles the business logic for listing locked accounts and addresses
+type ListLockoutsUseCase struct {
+	lockoutRepo repository.LoginLockoutRepository
+}
+
+// NewListLockoutsUseCase creates a new instance of ListLockoutsUseCase
+func NewListLockoutsUseCase(lockoutRepo repository.LoginLockoutRepository) *ListLockoutsUseCase {
+	return &ListLockoutsUseCase{
+		lockoutRepo: lockoutRepo,
+	}
+}
+
+// Execute returns the accounts and client addresses of the clinic that are
+// locked right now, most recent failure first
+// An empty scope returns both kinds
+func (uc *ListLockoutsUseCase) Execute(ctx context.Context, scope string) ([]LockoutResponse, error) {
+	if scope != "" && !domain.IsValidLockoutScope(scope) {
+		return nil, errors.New("invalid scope: must be account or ip")
+	}
+
+	lockouts, err := uc.lockoutRepo.ListLocked(ctx, scope, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to list lockouts")
+	}
+
+	response := make([]LockoutResponse, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		response = append(response, *toLockoutResponse(lockout))
+	}
+
+	return response, nil
+}
+
+// toLockoutResponse converts a locked counter to its API representation
+func toLockoutResponse(lockout *domain.LoginLockout) *LockoutResponse {
+	response := &LockoutResponse{
+		ID:            lockout.ID,
+		Scope:         lockout.Scope,
+		Subject:       lockout.Subject,
+		Failures:      lockout.Failures,
+		LastFailureAt: lockout.LastFailureAt,
+	}
+	if lockout.LockedUntil != nil {
+		response.LockedUntil = *lockout.LockedUntil
+	}
+	return response
+}
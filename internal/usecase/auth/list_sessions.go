@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// ListSessionsUseCase handles the business logic for listing the devices a user is logged in on
+type ListSessionsUseCase struct {
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+}
+
+// NewListSessionsUseCase creates a new instance of ListSessionsUseCase
+func NewListSessionsUseCase(userRepo repository.UserRepository, sessionRepo repository.SessionRepository) *ListSessionsUseCase {
+	return &ListSessionsUseCase{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+	}
+}
+
+// Execute returns the active sessions of a user, most recently seen first
+// currentSessionID is the session of the request, marked as current
+func (uc *ListSessionsUseCase) Execute(ctx context.Context, userID, currentSessionID string) ([]SessionResponse, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	sessions, err := uc.sessionRepo.ListActiveByUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to list sessions")
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, toSessionResponse(session, currentSessionID))
+	}
+
+	return response, nil
+}
+
+// toSessionResponse converts a session to its API representation
+func toSessionResponse(session *domain.Session, currentSessionID string) SessionResponse {
+	return SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: session.LastSeenAt,
+		ExpiresAt:  session.ExpiresAt,
+		Current:    session.ID == currentSessionID,
+	}
+}
@@ -3,95 +3,110 @@ package auth
 import (
 	"context"
 	"errors"
+	"log"
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
 )
 
 // LoginUseCase handles the business logic for user authentication
 type LoginUseCase struct {
-	userRepo           repository.UserRepository
-	jwtSecret          string
-	jwtExpirationHours int
+	userRepo  repository.UserRepository
+	tokens    *TokenService
+	guard     *LoginGuard
+	twoFactor *TwoFactorService
+	sso       *SSOService
 }
 
 // NewLoginUseCase creates a new instance of LoginUseCase
-func NewLoginUseCase(userRepo repository.UserRepository, jwtSecret string, jwtExpirationHours int) *LoginUseCase {
+func NewLoginUseCase(userRepo repository.UserRepository, tokens *TokenService, guard *LoginGuard, twoFactor *TwoFactorService, sso *SSOService) *LoginUseCase {
 	return &LoginUseCase{
-		userRepo:           userRepo,
-		jwtSecret:          jwtSecret,
-		jwtExpirationHours: jwtExpirationHours,
+		userRepo:  userRepo,
+		tokens:    tokens,
+		guard:     guard,
+		twoFactor: twoFactor,
+		sso:       sso,
 	}
 }
 
-// Execute authenticates a user and returns a JWT token if credentials are valid
-// Returns an error if credentials are invalid or user is inactive
-func (uc *LoginUseCase) Execute(ctx context.Context, req LoginRequest) (*LoginResponse, error) {
+// Execute authenticates a user and starts a session if credentials are valid
+// The response carries an access token and the refresh token that renews it,
+// or only a two-factor challenge when the user must still give a code
+// Returns an error if credentials are invalid or user is inactive, and when
+// too many logins failed; the returned duration then tells how long to wait
+func (uc *LoginUseCase) Execute(ctx context.Context, req LoginRequest) (*LoginResponse, time.Duration, error) {
 	// Validate email is not empty
 	if strings.TrimSpace(req.Email) == "" {
-		return nil, errors.New("email is required")
+		return nil, 0, errors.New("email is required")
 	}
 
 	// Validate password is not empty
 	if strings.TrimSpace(req.Password) == "" {
-		return nil, errors.New("password is required")
+		return nil, 0, errors.New("password is required")
+	}
+
+	// Refuse the attempt while the account or client address is locked or must wait;
+	// otherwise it counts as failed until the password turns out right
+	if wait, err := uc.guard.Reserve(ctx, req.Email); err != nil {
+		return nil, wait, err
 	}
 
 	// Find user by email
 	user, err := uc.userRepo.FindByEmail(ctx, req.Email)
 	if err != nil || user == nil {
 		// Return generic error for security (don't reveal if email exists)
-		return nil, errors.New("invalid credentials")
+		return nil, 0, uc.failed(ctx, req.Email, nil)
 	}
 
 	// Verify password with bcrypt
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
 		// Password doesn't match
-		return nil, errors.New("invalid credentials")
+		return nil, 0, uc.failed(ctx, req.Email, user)
+	}
+	if err := uc.guard.Release(ctx, req.Email); err != nil {
+		log.Printf("Error releasing login attempt: %v", err)
 	}
 
 	// Verify user is active
 	if !user.IsActive {
-		return nil, errors.New("user is inactive")
+		return nil, 0, errors.New("user is inactive")
 	}
 
-	// Generate JWT token
-	expiresAt := time.Now().Add(time.Duration(uc.jwtExpirationHours) * time.Hour)
-
-	// Create JWT claims
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"role":    string(user.Role),
-		"exp":     expiresAt.Unix(),
+	// Staff of roles that sign in through the provider have no password login;
+	// only told once the password is right, so it reveals nothing
+	if uc.sso.Required(user.Role) {
+		return nil, 0, errSSORequired
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign token with secret key
-	tokenString, err := token.SignedString([]byte(uc.jwtSecret))
+	// Staff with a second factor, or whose role requires one, finish with a code;
+	// failed logins are only forgotten once they do
+	challenge, err := uc.twoFactor.Challenge(ctx, user)
 	if err != nil {
-		return nil, errors.New("failed to generate token")
+		return nil, 0, err
+	}
+	if challenge != nil {
+		return &LoginResponse{TwoFactor: challenge}, 0, nil
 	}
 
-	// Build and return login response
-	response := &LoginResponse{
-		Token:     tokenString,
-		ExpiresAt: expiresAt,
+	if err := uc.guard.Succeeded(ctx, req.Email); err != nil {
+		log.Printf("Error clearing failed logins: %v", err)
 	}
 
-	// Set user information
-	response.User.ID = user.ID
-	response.User.Email = user.Email
-	response.User.FirstName = user.FirstName
-	response.User.LastName = user.LastName
-	response.User.Role = string(user.Role)
+	// Start a new session with its first pair of tokens
+	response, err := uc.tokens.Start(ctx, user)
+	return response, 0, err
+}
 
-	return response, nil
+// failed counts a failed login and returns the generic error for it
+// Counting errors are only logged so they do not reveal whether the email exists
+func (uc *LoginUseCase) failed(ctx context.Context, email string, user *domain.User) error {
+	if err := uc.guard.Failed(ctx, email, user); err != nil {
+		log.Printf("Error recording failed login: %v", err)
+	}
+	return errors.New("invalid credentials")
 }
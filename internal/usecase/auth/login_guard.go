@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/pkg/email"
+)
+
+// Login attempts refused by the LoginGuard; the handler answers all of them
+// with 429 and a Retry-After header
+var (
+	errAddressLocked = errors.New("too many failed logins from this address")
+	errAccountLocked = errors.New("account is temporarily locked")
+	errLoginDelayed  = errors.New("too many failed logins, try again later")
+)
+
+// Progressive delay between failed logins of one account: none after the
+// first failure, then a wait that doubles with each further failure
+const (
+	loginDelayBase = time.Second
+	maxLoginDelay  = time.Minute
+)
+
+// maxReserveTries bounds how often an attempt is retried when concurrent
+// attempts keep changing the counter it read
+const maxReserveTries = 5
+
+// LoginGuard protects login against password guessing
+// Failed logins are counted per account and per client IP address. Accounts
+// must wait longer and longer between failures and are locked once they reach
+// the limit; the owner is emailed a link to unlock early. Addresses are only
+// locked, with a higher limit since several users may share one. Every lockout
+// is written to the audit log
+// Each attempt is counted as failed before the password or code is compared,
+// and given back once it turns out right, so a burst of concurrent guesses
+// cannot all pass the limits before the first of them is counted
+type LoginGuard struct {
+	lockoutRepo   repository.LoginLockoutRepository
+	clinicRepo    repository.ClinicRepository
+	emailService  *email.EmailService
+	recorder      *audit.Recorder
+	unlockURL     string
+	maxAttempts   int
+	ipMaxAttempts int
+	lockDuration  time.Duration
+}
+
+// NewLoginGuard creates a new instance of LoginGuard
+// frontendURL is the base URL of the web app, whose /unlock-account page
+// receives the token and calls POST /api/auth/unlock
+func NewLoginGuard(
+	lockoutRepo repository.LoginLockoutRepository,
+	clinicRepo repository.ClinicRepository,
+	emailService *email.EmailService,
+	recorder *audit.Recorder,
+	frontendURL string,
+	maxAttempts int,
+	ipMaxAttempts int,
+	lockMinutes int,
+) *LoginGuard {
+	return &LoginGuard{
+		lockoutRepo:   lockoutRepo,
+		clinicRepo:    clinicRepo,
+		emailService:  emailService,
+		recorder:      recorder,
+		unlockURL:     frontendURL + "/unlock-account",
+		maxAttempts:   maxAttempts,
+		ipMaxAttempts: ipMaxAttempts,
+		lockDuration:  time.Duration(lockMinutes) * time.Minute,
+	}
+}
+
+// Reserve counts a login attempt for the email from the client address of ctx,
+// before its password or code is compared. It must be followed by Failed or,
+// when the password or code is right, by Release. When the attempt is refused,
+// the returned duration tells how long to wait
+func (g *LoginGuard) Reserve(ctx context.Context, emailAddress string) (time.Duration, error) {
+	ip := clientIP(ctx)
+	if ip != "" {
+		if wait, err := g.reserve(ctx, domain.LockoutScopeIP, ip); err != nil {
+			return wait, err
+		}
+	}
+
+	wait, err := g.reserve(ctx, domain.LockoutScopeAccount, accountSubject(emailAddress))
+	if err != nil && ip != "" {
+		if err := g.lockoutRepo.ReleaseAttempt(ctx, domain.LockoutScopeIP, ip); err != nil {
+			log.Printf("Error releasing login attempt: %v", err)
+		}
+	}
+	return wait, err
+}
+
+// Failed locks the account or client address whose reserved attempt made it
+// reach its limit
+// user is the account the email belongs to, or nil if there is none
+func (g *LoginGuard) Failed(ctx context.Context, emailAddress string, user *domain.User) error {
+	now := time.Now()
+
+	account, err := g.lockoutRepo.Find(ctx, domain.LockoutScopeAccount, accountSubject(emailAddress))
+	if err != nil {
+		return err
+	}
+	if account != nil && account.Failures >= g.maxAttempts {
+		if err := g.lock(ctx, account, user, now); err != nil {
+			return err
+		}
+	}
+
+	ip := clientIP(ctx)
+	if ip == "" {
+		return nil
+	}
+	address, err := g.lockoutRepo.Find(ctx, domain.LockoutScopeIP, ip)
+	if err != nil {
+		return err
+	}
+	if address != nil && address.Failures >= g.ipMaxAttempts {
+		return g.lock(ctx, address, nil, now)
+	}
+
+	return nil
+}
+
+// Release gives back the attempt reserved for the email once its password
+// or code turned out right
+func (g *LoginGuard) Release(ctx context.Context, emailAddress string) error {
+	if err := g.lockoutRepo.ReleaseAttempt(ctx, domain.LockoutScopeAccount, accountSubject(emailAddress)); err != nil {
+		return err
+	}
+
+	if ip := clientIP(ctx); ip != "" {
+		return g.lockoutRepo.ReleaseAttempt(ctx, domain.LockoutScopeIP, ip)
+	}
+	return nil
+}
+
+// Succeeded forgets the failed logins of the email
+// Failures from the client address are kept, so that guessing passwords of
+// many accounts still locks the address even if one of the guesses works
+func (g *LoginGuard) Succeeded(ctx context.Context, emailAddress string) error {
+	return g.lockoutRepo.DeleteBySubject(ctx, domain.LockoutScopeAccount, accountSubject(emailAddress))
+}
+
+// reserve counts an attempt on the counter of a scope and subject unless it
+// is locked, has as many attempts as its limit or, for accounts, must still
+// wait after the last one. The counter is only updated if no concurrent
+// attempt changed it since it was read; otherwise it is read again
+func (g *LoginGuard) reserve(ctx context.Context, scope, subject string) (time.Duration, error) {
+	maxAttempts, lockedErr := g.maxAttempts, errAccountLocked
+	if scope == domain.LockoutScopeIP {
+		maxAttempts, lockedErr = g.ipMaxAttempts, errAddressLocked
+	}
+
+	for range maxReserveTries {
+		now := time.Now()
+		resetBefore := now.Add(-g.lockDuration)
+
+		lockout, err := g.lockoutRepo.Find(ctx, scope, subject)
+		if err != nil {
+			return 0, err
+		}
+
+		failures := 0
+		if lockout != nil {
+			failures = lockout.Failures
+			if lockout.IsLocked(now) {
+				return lockout.LockedUntil.Sub(now), lockedErr
+			}
+		}
+		// Failures older than a lockout are forgotten and the counter starts over
+		if lockout != nil && !lockout.LastFailureAt.Before(resetBefore) {
+			// Attempts still in progress may have reached the limit before locking it
+			if lockout.Failures >= maxAttempts {
+				return lockout.LastFailureAt.Add(g.lockDuration).Sub(now), lockedErr
+			}
+			if scope == domain.LockoutScopeAccount {
+				if wait := lockout.LastFailureAt.Add(loginDelay(lockout.Failures)).Sub(now); wait > 0 {
+					return wait, errLoginDelayed
+				}
+			}
+		}
+
+		reserved, err := g.lockoutRepo.ReserveAttempt(ctx, uuid.New().String(), scope, subject, failures, now, resetBefore)
+		if err != nil {
+			return 0, err
+		}
+		if reserved {
+			return 0, nil
+		}
+	}
+
+	return loginDelayBase, errLoginDelayed
+}
+
+// lock locks an account or address and records it in the audit log
+// The owner of an active account is emailed a link to unlock it; nothing
+// happens if a concurrent failed login locked it first
+func (g *LoginGuard) lock(ctx context.Context, lockout *domain.LoginLockout, user *domain.User, now time.Time) error {
+	var token, tokenHash string
+	if user != nil && user.IsActive {
+		var err error
+		token, err = NewOpaqueToken()
+		if err != nil {
+			return errors.New("failed to generate token")
+		}
+		tokenHash = HashToken(token)
+	}
+
+	lockedUntil := now.Add(g.lockDuration)
+	locked, err := g.lockoutRepo.Lock(ctx, lockout.ID, lockedUntil, tokenHash, now)
+	if err != nil || !locked {
+		return err
+	}
+	lockout.LockedUntil = &lockedUntil
+
+	if err := g.recorder.Record(ctx, domain.AuditEntityLoginLockout, lockout.ID, domain.AuditActionLock, nil, lockout); err != nil {
+		return err
+	}
+
+	if token != "" && g.emailService != nil {
+		var branch email.Branch
+		if clinic, _ := g.clinicRepo.FindClinicByID(ctx, user.ClinicID); clinic != nil {
+			branch.Clinic = clinic.Name
+		}
+
+		go g.emailService.SendAccountUnlock(
+			branch,
+			user.Email,
+			user.FirstName+" "+user.LastName,
+			g.unlockURL+"?token="+url.QueryEscape(token),
+			fmt.Sprintf("%d minutos", int(g.lockDuration.Minutes())),
+		)
+	}
+
+	return nil
+}
+
+// loginDelay is how long an account must wait after its last failed login
+// when it has failed the given number of times in a row
+func loginDelay(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+
+	delay := loginDelayBase
+	for i := 2; i < failures && delay < maxLoginDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxLoginDelay)
+}
+
+// accountSubject is the subject accounts are tracked under
+// Emails are compared case-insensitively so changing the case does not reset the count
+func accountSubject(emailAddress string) string {
+	return strings.ToLower(strings.TrimSpace(emailAddress))
+}
+
+// clientIP returns the client address set by RequestIDMiddleware, or "" outside an HTTP request
+func clientIP(ctx context.Context) string {
+	ip, _ := ctx.Value(requestctx.ClientIPKey).(string)
+	return ip
+}
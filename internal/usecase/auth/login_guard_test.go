@@ -0,0 +1,270 @@
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
+)
+
+// Limits of the guard under test
+const (
+	testMaxAttempts   = 5
+	testIPMaxAttempts = 20
+	testLockMinutes   = 15
+)
+
+const testEmail = "paciente@clinica.test"
+
+// guardFixture holds a login guard and the unlock use case on one memory store
+type guardFixture struct {
+	ctx         context.Context
+	lockoutRepo repository.LoginLockoutRepository
+	guard       *auth.LoginGuard
+	unlock      *auth.UnlockAccountUseCase
+}
+
+// slowReads widens the gap between reading a counter and reserving an attempt
+// on it, so concurrent attempts all read the same count
+type slowReads struct {
+	repository.LoginLockoutRepository
+}
+
+func (r slowReads) Find(ctx context.Context, scope, subject string) (*domain.LoginLockout, error) {
+	lockout, err := r.LoginLockoutRepository.Find(ctx, scope, subject)
+	time.Sleep(5 * time.Millisecond)
+	return lockout, err
+}
+
+func newGuardFixture() *guardFixture {
+	store := memory.NewStore()
+	lockoutRepo := slowReads{memory.NewMemoryLoginLockoutRepository(store)}
+	recorder := audit.NewRecorder(memory.NewMemoryAuditRepository(store))
+	return &guardFixture{
+		ctx:         context.Background(),
+		lockoutRepo: lockoutRepo,
+		guard:       auth.NewLoginGuard(lockoutRepo, memory.NewMemoryClinicRepository(store), nil, recorder, "http://localhost:5173", testMaxAttempts, testIPMaxAttempts, testLockMinutes),
+		unlock:      auth.NewUnlockAccountUseCase(lockoutRepo, memory.NewMemoryTxManager(store), recorder),
+	}
+}
+
+// fail counts failed logins of an account that all happened at the given time
+func (f *guardFixture) fail(t *testing.T, failures int, at time.Time) {
+	t.Helper()
+	for i := range failures {
+		reserved, err := f.lockoutRepo.ReserveAttempt(f.ctx, "lockout-1", domain.LockoutScopeAccount, testEmail, i, at, at.Add(-time.Hour))
+		if err != nil || !reserved {
+			t.Fatalf("reserve failure %d: reserved %t, err %v", i+1, reserved, err)
+		}
+	}
+}
+
+// lock locks the account until the given time with the hash of an unlock token
+func (f *guardFixture) lock(t *testing.T, lockedUntil time.Time, unlockTokenHash string) {
+	t.Helper()
+	lockout := f.find(t)
+	if lockout == nil {
+		t.Fatalf("no failures to lock")
+	}
+	if locked, err := f.lockoutRepo.Lock(f.ctx, lockout.ID, lockedUntil, unlockTokenHash, time.Now()); err != nil || !locked {
+		t.Fatalf("lock: locked %t, err %v", locked, err)
+	}
+}
+
+// find returns the counter of the account, or nil if there is none
+func (f *guardFixture) find(t *testing.T) *domain.LoginLockout {
+	t.Helper()
+	lockout, err := f.lockoutRepo.Find(f.ctx, domain.LockoutScopeAccount, testEmail)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	return lockout
+}
+
+func TestLoginGuardLocksAfterMaxAttempts(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      *domain.User
+		wantToken bool
+	}{
+		{"active account", &domain.User{ID: "patient-user", Email: testEmail, IsActive: true}, true},
+		{"email without an account", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGuardFixture()
+			// Old enough that the delay between failures already passed
+			f.fail(t, testMaxAttempts-1, time.Now().Add(-2*time.Minute))
+
+			if _, err := f.guard.Reserve(f.ctx, testEmail); err != nil {
+				t.Fatalf("Reserve of the last attempt: %v", err)
+			}
+			if err := f.guard.Failed(f.ctx, testEmail, tt.user); err != nil {
+				t.Fatalf("Failed: %v", err)
+			}
+
+			lockout := f.find(t)
+			if lockout == nil || !lockout.IsLocked(time.Now()) {
+				t.Fatalf("account not locked after %d failures", testMaxAttempts)
+			}
+			if got := lockout.UnlockTokenHash != ""; got != tt.wantToken {
+				t.Errorf("unlock token set = %t, want %t", got, tt.wantToken)
+			}
+
+			// Upper case does not get around the lockout
+			wait, err := f.guard.Reserve(f.ctx, "PACIENTE@clinica.test")
+			if err == nil || err.Error() != "account is temporarily locked" {
+				t.Fatalf("Reserve error = %v, want account locked", err)
+			}
+			if wait <= (testLockMinutes-1)*time.Minute || wait > testLockMinutes*time.Minute {
+				t.Errorf("wait = %v, want about %d minutes", wait, testLockMinutes)
+			}
+		})
+	}
+}
+
+func TestLoginGuardResetWindow(t *testing.T) {
+	tests := []struct {
+		name         string
+		age          time.Duration // Time since the failures that locked the account
+		wantErr      string
+		wantFailures int
+	}{
+		{"lockout still running", (testLockMinutes - 1) * time.Minute, "account is temporarily locked", testMaxAttempts},
+		{"failures older than a lockout", (testLockMinutes + 1) * time.Minute, "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGuardFixture()
+			failedAt := time.Now().Add(-tt.age)
+			f.fail(t, testMaxAttempts, failedAt)
+			f.lock(t, failedAt.Add(testLockMinutes*time.Minute), "")
+
+			_, err := f.guard.Reserve(f.ctx, testEmail)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Reserve error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("Reserve: %v", err)
+			}
+
+			lockout := f.find(t)
+			if lockout.Failures != tt.wantFailures {
+				t.Errorf("failures = %d, want %d", lockout.Failures, tt.wantFailures)
+			}
+			if tt.wantErr == "" && lockout.LockedUntil != nil {
+				t.Errorf("locked until %v, want the old lockout dropped", lockout.LockedUntil)
+			}
+		})
+	}
+}
+
+func TestLoginGuardConcurrentReserve(t *testing.T) {
+	const attempts = 60
+	tests := []struct {
+		name    string
+		ip      string
+		email   func(i int) string
+		scope   string
+		subject string
+		limit   int
+	}{
+		{"one account", "", func(int) string { return testEmail }, domain.LockoutScopeAccount, testEmail, testMaxAttempts},
+		{"one address", "203.0.113.7", func(i int) string { return fmt.Sprintf("user%d@clinica.test", i) }, domain.LockoutScopeIP, "203.0.113.7", testIPMaxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGuardFixture()
+			ctx := f.ctx
+			if tt.ip != "" {
+				ctx = context.WithValue(ctx, requestctx.ClientIPKey, tt.ip)
+			}
+
+			var mu sync.Mutex
+			reserved := 0
+			var wg sync.WaitGroup
+			for i := range attempts {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := f.guard.Reserve(ctx, tt.email(i)); err == nil {
+						mu.Lock()
+						reserved++
+						mu.Unlock()
+					}
+				}()
+			}
+			wg.Wait()
+
+			if reserved == 0 || reserved > tt.limit {
+				t.Errorf("%d of %d attempts reserved, want between 1 and %d", reserved, attempts, tt.limit)
+			}
+			lockout, err := f.lockoutRepo.Find(ctx, tt.scope, tt.subject)
+			if err != nil || lockout == nil {
+				t.Fatalf("find counter: %v", err)
+			}
+			// Every reserved attempt is counted once, and refused ones not at all
+			if lockout.Failures != reserved {
+				t.Errorf("counter has %d failures, want the %d reserved", lockout.Failures, reserved)
+			}
+		})
+	}
+}
+
+func TestUnlockAccount(t *testing.T) {
+	tests := []struct {
+		name      string
+		lockedFor time.Duration // Time the lockout still runs; negative if it ended
+		token     func(emailed string) string
+		wantErr   string
+	}{
+		{"emailed token", 10 * time.Minute, func(emailed string) string { return emailed }, ""},
+		{"unknown token", 10 * time.Minute, func(string) string { return "not-the-token" }, "invalid or expired unlock token"},
+		{"lockout already ended", -time.Minute, func(emailed string) string { return emailed }, "invalid or expired unlock token"},
+		{"no token", 10 * time.Minute, func(string) string { return " " }, "token is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newGuardFixture()
+			emailed, err := auth.NewOpaqueToken()
+			if err != nil {
+				t.Fatalf("token: %v", err)
+			}
+			f.fail(t, testMaxAttempts, time.Now().Add(-time.Minute))
+			f.lock(t, time.Now().Add(tt.lockedFor), auth.HashToken(emailed))
+
+			err = f.unlock.Execute(f.ctx, auth.UnlockAccountRequest{Token: tt.token(emailed)})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+
+			if lockout := f.find(t); lockout != nil {
+				t.Errorf("counter kept %d failures, want them forgotten", lockout.Failures)
+			}
+			if _, err := f.guard.Reserve(f.ctx, testEmail); err != nil {
+				t.Errorf("Reserve after unlocking: %v", err)
+			}
+
+			// The link works once
+			err = f.unlock.Execute(f.ctx, auth.UnlockAccountRequest{Token: emailed})
+			if err == nil || err.Error() != "invalid or expired unlock token" {
+				t.Errorf("second unlock error = %v, want invalid token", err)
+			}
+		})
+	}
+}
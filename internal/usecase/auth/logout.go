@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// LogoutUseCase handles the business logic for ending a session
+type LogoutUseCase struct {
+	refreshTokenRepo repository.RefreshTokenRepository
+}
+
+// NewLogoutUseCase creates a new instance of LogoutUseCase
+func NewLogoutUseCase(refreshTokenRepo repository.RefreshTokenRepository) *LogoutUseCase {
+	return &LogoutUseCase{
+		refreshTokenRepo: refreshTokenRepo,
+	}
+}
+
+// Execute revokes the session of a refresh token, which also ends the access
+// tokens issued for it. Unknown, expired or already revoked tokens are not an
+// error, so logging out twice is harmless
+func (uc *LogoutUseCase) Execute(ctx context.Context, req LogoutRequest) error {
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		return errors.New("refresh_token is required")
+	}
+
+	stored, err := uc.refreshTokenRepo.FindByHash(ctx, HashToken(req.RefreshToken))
+	if err != nil {
+		return err
+	}
+	if stored == nil || stored.RevokedAt != nil {
+		return nil
+	}
+
+	return uc.refreshTokenRepo.RevokeSession(ctx, stored.SessionID, time.Now())
+}
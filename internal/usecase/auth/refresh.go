@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// errTokenReused aborts a refresh whose token was spent by a concurrent request
+var errTokenReused = errors.New("refresh token reuse detected")
+
+// RefreshUseCase handles the business logic for renewing an access token
+type RefreshUseCase struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	tokens           *TokenService
+	txManager        repository.TxManager
+}
+
+// NewRefreshUseCase creates a new instance of RefreshUseCase
+func NewRefreshUseCase(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	tokens *TokenService,
+	txManager repository.TxManager,
+) *RefreshUseCase {
+	return &RefreshUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		tokens:           tokens,
+		txManager:        txManager,
+	}
+}
+
+// Execute exchanges a refresh token for a new access token and refresh token
+// of the same session. Each refresh token works once: presenting one that was
+// already exchanged means someone kept a copy, so the whole session is revoked
+func (uc *RefreshUseCase) Execute(ctx context.Context, req RefreshRequest) (*LoginResponse, error) {
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		return nil, errors.New("refresh_token is required")
+	}
+
+	stored, err := uc.refreshTokenRepo.FindByHash(ctx, HashToken(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.RevokedAt != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	now := time.Now()
+	if stored.UsedAt != nil {
+		return nil, uc.revokeReused(ctx, stored, now)
+	}
+	if stored.IsExpired(now) {
+		return nil, errors.New("refresh token expired")
+	}
+
+	// Deleted and deactivated users cannot renew their sessions
+	user, err := uc.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// Spend the token and issue the next one atomically
+	var response *LoginResponse
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		used, err := uc.refreshTokenRepo.MarkUsed(ctx, stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errTokenReused
+		}
+
+		response, err = uc.tokens.Issue(ctx, user, stored.SessionID)
+		return err
+	})
+	if errors.Is(err, errTokenReused) {
+		return nil, uc.revokeReused(ctx, stored, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// revokeReused ends the session of a refresh token presented after it was spent
+// There is no telling whether the client or an attacker holds the copy, so
+// both lose access and the user has to log in again
+func (uc *RefreshUseCase) revokeReused(ctx context.Context, token *domain.RefreshToken, now time.Time) error {
+	log.Printf("Refresh token reuse detected for user %s: session %s revoked", token.UserID, token.SessionID)
+
+	if err := uc.refreshTokenRepo.RevokeSession(ctx, token.SessionID, now); err != nil {
+		return err
+	}
+
+	return errTokenReused
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// RegenerateRecoveryCodesUseCase handles the business logic for replacing the
+// recovery codes of a user, after they ran low or may have been seen
+type RegenerateRecoveryCodesUseCase struct {
+	userRepo  repository.UserRepository
+	twoFactor *TwoFactorService
+}
+
+// NewRegenerateRecoveryCodesUseCase creates a new instance of RegenerateRecoveryCodesUseCase
+func NewRegenerateRecoveryCodesUseCase(userRepo repository.UserRepository, twoFactor *TwoFactorService) *RegenerateRecoveryCodesUseCase {
+	return &RegenerateRecoveryCodesUseCase{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// Execute replaces the recovery codes of the user with a new set, shown only once
+// The request must carry a code from the authenticator app; wrong codes count
+// as failed logins and the returned duration tells how long to wait once they are refused
+func (uc *RegenerateRecoveryCodesUseCase) Execute(ctx context.Context, userID string, req TwoFactorCodeRequest) (*RecoveryCodesResponse, time.Duration, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if user == nil {
+		return nil, 0, errors.New("user not found")
+	}
+
+	codes, wait, err := uc.twoFactor.RegenerateRecoveryCodes(ctx, user, req.Code)
+	if err != nil {
+		return nil, wait, err
+	}
+
+	return &RecoveryCodesResponse{RecoveryCodes: codes}, 0, nil
+}
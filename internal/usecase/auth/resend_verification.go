@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// ResendVerificationUseCase handles the business logic for sending a new verification link
+type ResendVerificationUseCase struct {
+	userRepo     repository.UserRepository
+	verification *EmailVerification
+}
+
+// NewResendVerificationUseCase creates a new instance of ResendVerificationUseCase
+func NewResendVerificationUseCase(userRepo repository.UserRepository, verification *EmailVerification) *ResendVerificationUseCase {
+	return &ResendVerificationUseCase{
+		userRepo:     userRepo,
+		verification: verification,
+	}
+}
+
+// Execute sends a new verification link to the authenticated user
+// Only one email is sent every few minutes; the returned duration tells how
+// long to wait when the request is refused for that reason
+func (uc *ResendVerificationUseCase) Execute(ctx context.Context, userID string) (time.Duration, error) {
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user == nil {
+		return 0, errors.New("user not found")
+	}
+	if user.IsEmailVerified() {
+		return 0, errors.New("email already verified")
+	}
+
+	if user.VerificationSentAt != nil {
+		if wait := time.Until(user.VerificationSentAt.Add(verificationResendInterval)); wait > 0 {
+			return wait, errors.New("verification email sent recently")
+		}
+	}
+
+	return 0, uc.verification.Send(ctx, user)
+}
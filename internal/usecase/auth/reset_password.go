@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// errInvalidResetToken is returned for unknown, used and expired tokens alike
+var errInvalidResetToken = errors.New("invalid or expired reset token")
+
+// ResetPasswordUseCase handles the business logic for choosing a new password
+// with the token of a password reset link
+type ResetPasswordUseCase struct {
+	userRepo         repository.UserRepository
+	resetTokenRepo   repository.PasswordResetTokenRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	txManager        repository.TxManager
+	recorder         *audit.Recorder
+}
+
+// NewResetPasswordUseCase creates a new instance of ResetPasswordUseCase
+func NewResetPasswordUseCase(
+	userRepo repository.UserRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *ResetPasswordUseCase {
+	return &ResetPasswordUseCase{
+		userRepo:         userRepo,
+		resetTokenRepo:   resetTokenRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		txManager:        txManager,
+		recorder:         recorder,
+	}
+}
+
+// Execute sets a new password for the user of the token and ends all of the
+// user's sessions, since whoever forgot the password may not be the only one
+// holding them. The token and any other pending link of the user stop working
+func (uc *ResetPasswordUseCase) Execute(ctx context.Context, req ResetPasswordRequest) error {
+	if strings.TrimSpace(req.Token) == "" {
+		return errors.New("token is required")
+	}
+	if len(req.NewPassword) < 8 {
+		return errors.New("password must be at least 8 characters long")
+	}
+
+	stored, err := uc.resetTokenRepo.FindByHash(ctx, HashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if stored == nil || stored.UsedAt != nil || stored.IsExpired(now) {
+		return errInvalidResetToken
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, stored.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		return errInvalidResetToken
+	}
+	before := *user
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+	user.PasswordHash = string(hashedPassword)
+	user.UpdatedAt = now
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		used, err := uc.resetTokenRepo.MarkUsed(ctx, stored.ID, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errInvalidResetToken
+		}
+		if err := uc.resetTokenRepo.InvalidateUserTokens(ctx, user.ID, now); err != nil {
+			return err
+		}
+
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := uc.refreshTokenRepo.RevokeUserSessions(ctx, user.ID, "", now); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityUser, user.ID, domain.AuditActionResetPassword, &before, user)
+	})
+}
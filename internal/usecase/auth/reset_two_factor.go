@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+)
+
+// ResetTwoFactorUseCase handles the business logic for an admin removing the
+// second factor of a user who lost their authenticator app and recovery codes
+type ResetTwoFactorUseCase struct {
+	userRepo      repository.UserRepository
+	twoFactorRepo repository.TwoFactorRepository
+	twoFactor     *TwoFactorService
+}
+
+// NewResetTwoFactorUseCase creates a new instance of ResetTwoFactorUseCase
+func NewResetTwoFactorUseCase(userRepo repository.UserRepository, twoFactorRepo repository.TwoFactorRepository, twoFactor *TwoFactorService) *ResetTwoFactorUseCase {
+	return &ResetTwoFactorUseCase{
+		userRepo:      userRepo,
+		twoFactorRepo: twoFactorRepo,
+		twoFactor:     twoFactor,
+	}
+}
+
+// Execute removes the second factor and recovery codes of a user, pending or enabled
+// If their role requires one, their next login asks them to enroll again
+func (uc *ResetTwoFactorUseCase) Execute(ctx context.Context, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user ID is required")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	// Only admins reset the second factor of another admin
+	role, _ := ctx.Value(requestctx.RoleKey).(string)
+	if !domain.UserRole(role).CanManage(user.Role) {
+		return errors.New("only administrators can reset the second factor of an administrator")
+	}
+
+	twoFactor, err := uc.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil {
+		return errTwoFactorNotEnabled
+	}
+
+	return uc.twoFactor.remove(ctx, twoFactor, domain.AuditActionReset)
+}
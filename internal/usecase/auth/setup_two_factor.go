@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/repository"
+)
+
+// SetupTwoFactorUseCase handles the business logic for enrolling a second
+// factor during a login, when the role of the user requires one
+type SetupTwoFactorUseCase struct {
+	userRepo  repository.UserRepository
+	twoFactor *TwoFactorService
+}
+
+// NewSetupTwoFactorUseCase creates a new instance of SetupTwoFactorUseCase
+func NewSetupTwoFactorUseCase(userRepo repository.UserRepository, twoFactor *TwoFactorService) *SetupTwoFactorUseCase {
+	return &SetupTwoFactorUseCase{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+	}
+}
+
+// Execute stores a new secret for the user of a setup challenge
+// The login finishes with POST /api/auth/2fa/verify and a first code for it
+func (uc *SetupTwoFactorUseCase) Execute(ctx context.Context, req TwoFactorSetupRequest) (*TwoFactorSetupResponse, error) {
+	if strings.TrimSpace(req.ChallengeToken) == "" {
+		return nil, errors.New("challenge token is required")
+	}
+
+	claims, err := uc.twoFactor.parseChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.purpose != challengeSetup {
+		return nil, errInvalidChallenge
+	}
+
+	// The challenge is looked up in the clinic it was issued for
+	ctx = repository.WithClinic(ctx, claims.clinicID)
+	user, err := uc.userRepo.FindByID(ctx, claims.userID)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.issuedFor(user) {
+		return nil, errInvalidChallenge
+	}
+
+	return uc.twoFactor.Enroll(ctx, user)
+}
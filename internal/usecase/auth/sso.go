@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/pkg/oidc"
+)
+
+// Errors shared by the single sign-on use cases
+var (
+	errSSONotConfigured    = errors.New("single sign-on is not configured")
+	errSSOUnavailable      = errors.New("single sign-on provider is unavailable")
+	errInvalidSSOState     = errors.New("invalid or expired single sign-on state")
+	errSSOOtherBrowser     = errors.New("single sign-on was started in another browser")
+	errSSOFailed           = errors.New("single sign-on could not be completed")
+	errSSOEmailNotVerified = errors.New("the provider has not verified this email")
+	errSSONoAccount        = errors.New("no staff account for this email")
+	errSSOStaffOnly        = errors.New("single sign-on is only available for staff accounts")
+	errSSORoleNotGranted   = errors.New("the provider does not grant the role of this account")
+	errSSOLinkedElsewhere  = errors.New("this account is linked to another identity of the provider")
+	errSSORequired         = errors.New("this account must sign in with single sign-on")
+)
+
+const (
+	// ssoStateTTL is how long a person has to sign in at the provider
+	ssoStateTTL = 10 * time.Minute
+
+	// ssoPurpose marks state tokens so no other token signed with the same key passes as one
+	ssoPurpose = "sso"
+)
+
+// SSOService implements the single sign-on of staff through an OpenID Connect provider
+// A login starts with a signed state token that carries the clinic and nonce
+// of the flow; the PKCE verifier is derived from it with a server-side key, so
+// it never travels through the browser. The ID of the state is also kept in a
+// cookie of the browser that started the flow, and each state can complete
+// only one login. When the person comes back, their
+// identity is found by the subject the provider gives, or linked once to the
+// staff account with the same email; the groups or roles the provider lists in
+// roleClaim must grant the role the account has
+type SSOService struct {
+	provider      *oidc.Provider
+	identityRepo  repository.ExternalIdentityRepository
+	usedTokenRepo repository.UsedTokenRepository
+	txManager     repository.TxManager
+	recorder      *audit.Recorder
+	stateKey      []byte
+	verifierKey   []byte
+	roleClaim     string
+	roleMapping   map[string]domain.UserRole
+	requiredRoles []domain.UserRole
+}
+
+// NewSSOService creates a new instance of SSOService
+// provider is nil when single sign-on is not configured. The keys of the state
+// tokens and PKCE verifiers are derived from the JWT secret
+// requiredRoles lists the staff roles that cannot log in with a password
+func NewSSOService(
+	provider *oidc.Provider,
+	identityRepo repository.ExternalIdentityRepository,
+	usedTokenRepo repository.UsedTokenRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	jwtSecret string,
+	roleClaim string,
+	roleMapping map[string]domain.UserRole,
+	requiredRoles []domain.UserRole,
+) *SSOService {
+	return &SSOService{
+		provider:      provider,
+		identityRepo:  identityRepo,
+		usedTokenRepo: usedTokenRepo,
+		txManager:     txManager,
+		recorder:      recorder,
+		stateKey:      deriveKey(jwtSecret, "sso-state"),
+		verifierKey:   deriveKey(jwtSecret, "sso-pkce-verifier"),
+		roleClaim:     roleClaim,
+		roleMapping:   roleMapping,
+		requiredRoles: requiredRoles,
+	}
+}
+
+// deriveKey returns a key for one purpose from the JWT secret
+func deriveKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Enabled reports whether single sign-on is configured
+func (s *SSOService) Enabled() bool {
+	return s.provider != nil
+}
+
+// Required reports whether users of the role must log in through the provider
+func (s *SSOService) Required(role domain.UserRole) bool {
+	return s.Enabled() && slices.Contains(s.requiredRoles, role)
+}
+
+// ssoState holds what a state token carries
+type ssoState struct {
+	id        string
+	clinicID  string
+	nonce     string
+	expiresAt time.Time
+}
+
+// newState signs the state token of a new flow
+func (s *SSOService) newState(clinicID string, now time.Time) (string, *ssoState, error) {
+	id, err := NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+	nonce, err := NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	claims := jwt.MapClaims{
+		"jti":       id,
+		"clinic_id": clinicID,
+		"nonce":     nonce,
+		"purpose":   ssoPurpose,
+		"iat":       now.Unix(),
+		"exp":       now.Add(ssoStateTTL).Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.stateKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &ssoState{id: id, clinicID: clinicID, nonce: nonce, expiresAt: now.Add(ssoStateTTL)}, nil
+}
+
+// parseState checks the signature and expiration of a state token
+func (s *SSOService) parseState(token string) (*ssoState, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		return s.stateKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !parsed.Valid {
+		return nil, errInvalidSSOState
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errInvalidSSOState
+	}
+	id, _ := claims["jti"].(string)
+	clinicID, _ := claims["clinic_id"].(string)
+	nonce, _ := claims["nonce"].(string)
+	purpose, _ := claims["purpose"].(string)
+	if id == "" || clinicID == "" || nonce == "" || purpose != ssoPurpose {
+		return nil, errInvalidSSOState
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, errInvalidSSOState
+	}
+
+	return &ssoState{id: id, clinicID: clinicID, nonce: nonce, expiresAt: expiresAt.Time}, nil
+}
+
+// useState checks that a state comes back to the browser that started its
+// flow, whose cookie holds the ID of the state, and records the ID so the
+// state completes only one login
+func (s *SSOService) useState(ctx context.Context, state *ssoState, browserStateID string) error {
+	if subtle.ConstantTimeCompare([]byte(browserStateID), []byte(state.id)) != 1 {
+		return errSSOOtherBrowser
+	}
+
+	first, err := s.usedTokenRepo.Use(ctx, ssoPurpose+":"+state.id, state.expiresAt)
+	if err != nil {
+		return err
+	}
+	if !first {
+		return errInvalidSSOState
+	}
+	return nil
+}
+
+// verifier returns the PKCE code verifier of a flow
+func (s *SSOService) verifier(state *ssoState) string {
+	mac := hmac.New(sha256.New, s.verifierKey)
+	mac.Write([]byte(state.id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// grantedRoles returns the roles the claims of an ID token grant
+// roleClaim may name a nested claim with dots, such as realm_access.roles;
+// its value is a list of strings or a single string
+func (s *SSOService) grantedRoles(claims jwt.MapClaims) []domain.UserRole {
+	var value interface{} = map[string]interface{}(claims)
+	for _, name := range strings.Split(s.roleClaim, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	var values []string
+	switch v := value.(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	var roles []domain.UserRole
+	for _, v := range values {
+		if role, ok := s.roleMapping[v]; ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// link stores the identity of a user signing in through the provider for the first time
+func (s *SSOService) link(ctx context.Context, user *domain.User, subject, email string, now time.Time) error {
+	identity := &domain.ExternalIdentity{
+		ID:          uuid.New().String(),
+		ClinicID:    user.ClinicID,
+		UserID:      user.ID,
+		Issuer:      s.provider.Issuer(),
+		Subject:     subject,
+		Email:       email,
+		CreatedAt:   now,
+		LastLoginAt: now,
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// The account keeps its identity until an admin unlinks it, so the
+		// email alone never moves it to another person of the provider
+		linked, err := s.identityRepo.ListByUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		for _, other := range linked {
+			if other.Issuer == identity.Issuer {
+				return errSSOLinkedElsewhere
+			}
+		}
+
+		if err := s.identityRepo.Create(ctx, identity); err != nil {
+			return err
+		}
+		return s.recorder.Record(ctx, domain.AuditEntityIdentity, identity.ID, domain.AuditActionCreate, nil, identity)
+	})
+	if errors.Is(err, domain.ErrDuplicateRecord) {
+		return errSSOLinkedElsewhere
+	}
+	return err
+}
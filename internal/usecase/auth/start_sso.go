@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// StartSSOUseCase handles the business logic for sending staff to the
+// single sign-on provider
+type StartSSOUseCase struct {
+	sso *SSOService
+}
+
+// NewStartSSOUseCase creates a new instance of StartSSOUseCase
+func NewStartSSOUseCase(sso *SSOService) *StartSSOUseCase {
+	return &StartSSOUseCase{
+		sso: sso,
+	}
+}
+
+// Execute returns the address of the provider's login page for a new flow in
+// the clinic of the request, with the state the provider will send back and
+// the ID of the state, which the browser keeps in a cookie
+func (uc *StartSSOUseCase) Execute(ctx context.Context) (*SSOStartResponse, error) {
+	if !uc.sso.Enabled() {
+		return nil, errSSONotConfigured
+	}
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	token, state, err := uc.sso.newState(clinicID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := uc.sso.provider.AuthCodeURL(ctx, token, state.nonce, uc.sso.verifier(state))
+	if err != nil {
+		log.Printf("Error reaching the single sign-on provider: %v", err)
+		return nil, errSSOUnavailable
+	}
+
+	return &SSOStartResponse{
+		AuthorizationURL: authorizationURL,
+		State:            token,
+		StateID:          state.id,
+		ExpiresAt:        state.expiresAt,
+	}, nil
+}
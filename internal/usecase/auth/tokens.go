@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/pkg/jwtkeys"
+)
+
+// TokenService issues the tokens of a session: a short-lived signed access token
+// and an opaque refresh token, stored hashed, that the client trades for the next pair
+type TokenService struct {
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionRepo      repository.SessionRepository
+	tokens           *jwtkeys.KeySet
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+}
+
+// NewTokenService creates a new instance of TokenService
+func NewTokenService(refreshTokenRepo repository.RefreshTokenRepository, sessionRepo repository.SessionRepository, tokens *jwtkeys.KeySet, accessTokenMinutes int, refreshTokenDays int) *TokenService {
+	return &TokenService{
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		tokens:           tokens,
+		accessTokenTTL:   time.Duration(accessTokenMinutes) * time.Minute,
+		refreshTokenTTL:  time.Duration(refreshTokenDays) * 24 * time.Hour,
+	}
+}
+
+// Start begins a session of user on the device the request came from and
+// issues its first pair of tokens
+func (s *TokenService) Start(ctx context.Context, user *domain.User) (*LoginResponse, error) {
+	now := time.Now()
+	session := &domain.Session{
+		ID:         uuid.New().String(),
+		UserID:     user.ID,
+		UserAgent:  requestctx.String(ctx, requestctx.UserAgentKey),
+		IPAddress:  requestctx.String(ctx, requestctx.ClientIPKey),
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user, session.ID, now)
+}
+
+// Issue signs an access token for user and stores a new refresh token, both
+// belonging to sessionID, and marks the session as seen. Call it with the
+// transaction context of the refresh so the previous token is only spent if
+// the new one is stored
+func (s *TokenService) Issue(ctx context.Context, user *domain.User, sessionID string) (*LoginResponse, error) {
+	now := time.Now()
+	if err := s.sessionRepo.Touch(ctx, sessionID, now); err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, user, sessionID, now)
+}
+
+// issue signs the access token and stores the refresh token of a session
+func (s *TokenService) issue(ctx context.Context, user *domain.User, sessionID string, now time.Time) (*LoginResponse, error) {
+	expiresAt := now.Add(s.accessTokenTTL)
+
+	// Create JWT claims; sid lets AuthMiddleware reject tokens of ended sessions
+	claims := jwt.MapClaims{
+		"user_id":   user.ID,
+		"email":     user.Email,
+		"role":      string(user.Role),
+		"clinic_id": user.ClinicID,
+		"sid":       sessionID,
+		"iat":       now.Unix(),
+		"exp":       expiresAt.Unix(),
+	}
+
+	// Sign token with the signing key, named in its kid header
+	tokenString, err := s.tokens.Sign(claims)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	refreshToken, err := NewOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	stored := &domain.RefreshToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		SessionID: sessionID,
+		TokenHash: HashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTokenTTL),
+		CreatedAt: now,
+	}
+	if err := s.refreshTokenRepo.Create(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	// Build and return login response
+	response := &LoginResponse{
+		Token:            tokenString,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+	}
+
+	// Set user information
+	response.User.ID = user.ID
+	response.User.Email = user.Email
+	response.User.FirstName = user.FirstName
+	response.User.LastName = user.LastName
+	response.User.Role = string(user.Role)
+
+	return response, nil
+}
+
+// NewOpaqueToken returns 32 random bytes encoded for use in URLs and JSON
+// Refresh, password reset and invitation tokens all use this form
+func NewOpaqueToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// HashToken returns the SHA-256 of an opaque token, the form it is stored in
+// The tokens are random, so a fast unsalted hash is enough to keep a database
+// leak from handing out working tokens
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
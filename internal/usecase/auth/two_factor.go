@@ -0,0 +1,483 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/pkg/totp"
+)
+
+// Errors shared by the two-factor use cases
+var (
+	errInvalidChallenge     = errors.New("invalid or expired two-factor challenge")
+	errInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	errTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	errTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	errTwoFactorStaffOnly   = errors.New("two-factor authentication is only available for staff accounts")
+)
+
+// Purposes of a challenge token: finish a login with a code, or enroll a
+// second factor first because the role requires one
+const (
+	challengeLogin = "2fa"
+	challengeSetup = "2fa_setup"
+)
+
+const (
+	// challengeTTL is how long a password login waits for its second step
+	challengeTTL = 5 * time.Minute
+
+	// codeSkew accepts the codes of one step before and after the current one
+	codeSkew = 1
+
+	// recoveryCodeCount is how many recovery codes a user gets at a time
+	recoveryCodeCount = 10
+
+	// defaultIssuer names the service in authenticator apps when the clinic is unknown
+	defaultIssuer = "Clínica"
+)
+
+// recoveryAlphabet has 32 characters, so each random byte picks one without
+// bias, and leaves out the ones easily mistaken for others (0, 1, l, o)
+const recoveryAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+// TwoFactorService implements the TOTP second factor of staff accounts
+// A login with the right password gets a short-lived challenge token instead
+// of tokens when the user has a second factor, or must enroll one because
+// their role requires it; the token is then traded, once, for the session
+// together with a code. A change to the account since the password was
+// checked, such as a password reset, voids the challenge. Wrong codes count
+// as failed logins in the LoginGuard, so guessing codes locks the account like
+// guessing passwords does
+type TwoFactorService struct {
+	twoFactorRepo repository.TwoFactorRepository
+	clinicRepo    repository.ClinicRepository
+	usedTokenRepo repository.UsedTokenRepository
+	guard         *LoginGuard
+	txManager     repository.TxManager
+	recorder      *audit.Recorder
+	key           []byte
+	requiredRoles []domain.UserRole
+}
+
+// NewTwoFactorService creates a new instance of TwoFactorService
+// The key of the challenge tokens is derived from the JWT secret, so a
+// challenge can never be used as an access token nor the other way around
+// requiredRoles lists the staff roles that cannot log in without a second factor
+func NewTwoFactorService(
+	twoFactorRepo repository.TwoFactorRepository,
+	clinicRepo repository.ClinicRepository,
+	usedTokenRepo repository.UsedTokenRepository,
+	guard *LoginGuard,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	jwtSecret string,
+	requiredRoles []domain.UserRole,
+) *TwoFactorService {
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte("two-factor-challenge"))
+
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		clinicRepo:    clinicRepo,
+		usedTokenRepo: usedTokenRepo,
+		guard:         guard,
+		txManager:     txManager,
+		recorder:      recorder,
+		key:           mac.Sum(nil),
+		requiredRoles: requiredRoles,
+	}
+}
+
+// Required reports whether users of the role must log in with a second factor
+func (s *TwoFactorService) Required(role domain.UserRole) bool {
+	return slices.Contains(s.requiredRoles, role)
+}
+
+// Challenge decides whether a user who gave the right password needs a second step
+// Returns nil when the session can start right away
+func (s *TwoFactorService) Challenge(ctx context.Context, user *domain.User) (*TwoFactorChallenge, error) {
+	if !user.Role.IsStaff() {
+		return nil, nil
+	}
+
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := challengeLogin
+	if twoFactor == nil || !twoFactor.IsEnabled() {
+		if !s.Required(user.Role) {
+			return nil, nil
+		}
+		purpose = challengeSetup
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(challengeTTL)
+	claims := jwt.MapClaims{
+		"jti":             uuid.New().String(),
+		"sub":             user.ID,
+		"clinic_id":       user.ClinicID,
+		"purpose":         purpose,
+		"user_updated_at": user.UpdatedAt.UnixMicro(),
+		"iat":             now.Unix(),
+		"exp":             expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &TwoFactorChallenge{
+		TwoFactorRequired: true,
+		SetupRequired:     purpose == challengeSetup,
+		ChallengeToken:    token,
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// challengeClaims is what a valid challenge token was issued for
+type challengeClaims struct {
+	id            string
+	userID        string
+	clinicID      string
+	purpose       string
+	userUpdatedAt int64 // UpdatedAt of the user when the password was checked, in microseconds
+	expiresAt     time.Time
+}
+
+// issuedFor reports whether the challenge still holds for the user it names:
+// the account exists, is active and has not changed since the challenge was
+// issued, so a password reset or a deactivation voids pending challenges
+func (c *challengeClaims) issuedFor(user *domain.User) bool {
+	return user != nil && user.IsActive && user.UpdatedAt.UnixMicro() <= c.userUpdatedAt
+}
+
+// parseChallenge checks the signature and expiration of a challenge token
+func (s *TwoFactorService) parseChallenge(token string) (*challengeClaims, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		return s.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !parsed.Valid {
+		return nil, errInvalidChallenge
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errInvalidChallenge
+	}
+	id, _ := claims["jti"].(string)
+	userID, _ := claims["sub"].(string)
+	clinicID, _ := claims["clinic_id"].(string)
+	purpose, _ := claims["purpose"].(string)
+	userUpdatedAt, hasUpdatedAt := claims["user_updated_at"].(float64)
+	if id == "" || userID == "" || clinicID == "" || !hasUpdatedAt || (purpose != challengeLogin && purpose != challengeSetup) {
+		return nil, errInvalidChallenge
+	}
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return nil, errInvalidChallenge
+	}
+
+	return &challengeClaims{
+		id:            id,
+		userID:        userID,
+		clinicID:      clinicID,
+		purpose:       purpose,
+		userUpdatedAt: int64(userUpdatedAt),
+		expiresAt:     expiresAt.Time,
+	}, nil
+}
+
+// useChallenge records the ID of a challenge whose code was accepted, so the
+// challenge starts only one session
+func (s *TwoFactorService) useChallenge(ctx context.Context, claims *challengeClaims) error {
+	first, err := s.usedTokenRepo.Use(ctx, challengeLogin+":"+claims.id, claims.expiresAt)
+	if err != nil {
+		return err
+	}
+	if !first {
+		return errInvalidChallenge
+	}
+	return nil
+}
+
+// Enroll stores a new secret for user, replacing one that was never confirmed
+// The second factor only becomes active once Confirm accepts a first code
+func (s *TwoFactorService) Enroll(ctx context.Context, user *domain.User) (*TwoFactorSetupResponse, error) {
+	if !user.Role.IsStaff() {
+		return nil, errTwoFactorStaffOnly
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.twoFactorRepo.FindByUserID(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if existing.IsEnabled() {
+				return errTwoFactorEnabled
+			}
+			if err := s.twoFactorRepo.Delete(ctx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		return s.twoFactorRepo.Create(ctx, &domain.TwoFactor{
+			UserID:    user.ID,
+			Secret:    secret,
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := defaultIssuer
+	if clinic, _ := s.clinicRepo.FindClinicByID(ctx, user.ClinicID); clinic != nil {
+		issuer = clinic.Name
+	}
+
+	return &TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: totp.URI(issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables the pending second factor of user with a first code from the
+// authenticator app and returns the user's recovery codes
+// Not to be called within a transaction: a wrong code must stay counted
+func (s *TwoFactorService) Confirm(ctx context.Context, user *domain.User, code string) ([]string, time.Duration, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if twoFactor == nil {
+		return nil, 0, errors.New("two-factor enrollment has not been started")
+	}
+	if twoFactor.IsEnabled() {
+		return nil, 0, errTwoFactorEnabled
+	}
+	if strings.TrimSpace(code) == "" {
+		return nil, 0, errors.New("code is required")
+	}
+
+	if wait, err := s.guard.Reserve(ctx, user.Email); err != nil {
+		return nil, wait, err
+	}
+	now := time.Now()
+	step, ok := totp.Validate(twoFactor.Secret, code, now, codeSkew)
+	if !ok {
+		return nil, 0, s.failed(ctx, user)
+	}
+	s.passed(ctx, user)
+
+	var codes []string
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		enabled, err := s.twoFactorRepo.Enable(ctx, user.ID, step, now)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return errTwoFactorEnabled
+		}
+		twoFactor.EnabledAt = &now
+		twoFactor.LastUsedStep = step
+
+		if codes, err = s.replaceRecoveryCodes(ctx, user.ID, now); err != nil {
+			return err
+		}
+
+		return s.recorder.Record(ctx, domain.AuditEntityTwoFactor, user.ID, domain.AuditActionEnable, nil, twoFactor)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return codes, 0, nil
+}
+
+// Verify checks a code from the authenticator app, or else a recovery code,
+// against the enabled second factor of user. Each of them works only once
+// Not to be called within a transaction: a wrong code must stay counted
+func (s *TwoFactorService) Verify(ctx context.Context, user *domain.User, twoFactor *domain.TwoFactor, code, recoveryCode string) (time.Duration, error) {
+	code = strings.TrimSpace(code)
+	recoveryCode = normalizeRecoveryCode(recoveryCode)
+	if code == "" && recoveryCode == "" {
+		return 0, errors.New("code is required")
+	}
+
+	if wait, err := s.guard.Reserve(ctx, user.Email); err != nil {
+		return wait, err
+	}
+
+	now := time.Now()
+	if code == "" {
+		used, err := s.twoFactorRepo.UseRecoveryCode(ctx, user.ID, HashToken(recoveryCode), now)
+		if err != nil {
+			return 0, err
+		}
+		if !used {
+			return 0, s.failed(ctx, user)
+		}
+		s.passed(ctx, user)
+		return 0, nil
+	}
+
+	step, ok := totp.Validate(twoFactor.Secret, code, now, codeSkew)
+	if !ok {
+		return 0, s.failed(ctx, user)
+	}
+	// A code seen once, even by an eavesdropper, does not work again
+	fresh, err := s.twoFactorRepo.UseStep(ctx, user.ID, step)
+	if err != nil {
+		return 0, err
+	}
+	if !fresh {
+		return 0, s.failed(ctx, user)
+	}
+	s.passed(ctx, user)
+
+	return 0, nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of user after checking
+// a code from the authenticator app, and returns the new ones
+// Not to be called within a transaction: a wrong code must stay counted
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, user *domain.User, code string) ([]string, time.Duration, error) {
+	twoFactor, err := s.enabled(ctx, user.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	// A recovery code cannot vouch for new ones
+	if wait, err := s.Verify(ctx, user, twoFactor, code, ""); err != nil {
+		return nil, wait, err
+	}
+
+	var codes []string
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		remaining, err := s.twoFactorRepo.CountRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if codes, err = s.replaceRecoveryCodes(ctx, user.ID, time.Now()); err != nil {
+			return err
+		}
+
+		before := map[string]int{"recovery_codes_remaining": remaining}
+		after := map[string]int{"recovery_codes_remaining": len(codes)}
+		return s.recorder.Record(ctx, domain.AuditEntityTwoFactor, user.ID, domain.AuditActionRegenerate, before, after)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return codes, 0, nil
+}
+
+// remove deletes the second factor of a user and records it in the audit log
+// under action, either disable by the user or reset by an admin
+func (s *TwoFactorService) remove(ctx context.Context, twoFactor *domain.TwoFactor, action string) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.twoFactorRepo.Delete(ctx, twoFactor.UserID); err != nil {
+			return err
+		}
+		return s.recorder.Record(ctx, domain.AuditEntityTwoFactor, twoFactor.UserID, action, twoFactor, nil)
+	})
+}
+
+// enabled returns the enabled second factor of a user
+// Returns errTwoFactorNotEnabled if there is none or it was never confirmed
+func (s *TwoFactorService) enabled(ctx context.Context, userID string) (*domain.TwoFactor, error) {
+	twoFactor, err := s.twoFactorRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil || !twoFactor.IsEnabled() {
+		return nil, errTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// replaceRecoveryCodes generates a new set of recovery codes for a user,
+// dropping the previous ones, and returns them in clear for a single display
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID string, now time.Time) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	stored := make([]*domain.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, errors.New("failed to generate recovery codes")
+		}
+		codes[i] = code
+		stored[i] = &domain.RecoveryCode{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			CodeHash:  HashToken(normalizeRecoveryCode(code)),
+			CreatedAt: now,
+		}
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, stored); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// failed counts a wrong code as a failed login of the user and returns the error for it
+func (s *TwoFactorService) failed(ctx context.Context, user *domain.User) error {
+	if err := s.guard.Failed(ctx, user.Email, user); err != nil {
+		log.Printf("Error recording failed two-factor code: %v", err)
+	}
+	return errInvalidTwoFactorCode
+}
+
+// passed gives back the attempt reserved for a code that turned out right
+func (s *TwoFactorService) passed(ctx context.Context, user *domain.User) {
+	if err := s.guard.Release(ctx, user.Email); err != nil {
+		log.Printf("Error releasing two-factor attempt: %v", err)
+	}
+}
+
+// newRecoveryCode returns a random code formatted as two groups of five characters
+func newRecoveryCode() (string, error) {
+	random := make([]byte, 10)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, 0, len(random)+1)
+	for i, b := range random {
+		if i == len(random)/2 {
+			code = append(code, '-')
+		}
+		code = append(code, recoveryAlphabet[b%byte(len(recoveryAlphabet))])
+	}
+	return string(code), nil
+}
+
+// normalizeRecoveryCode ignores case, dashes and spaces so a code can be typed loosely
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,294 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
+	"version-1-0/pkg/jwtkeys"
+	"version-1-0/pkg/totp"
+)
+
+const testPassword = "demo12345"
+
+// twoFactorFixture holds the login use cases on one memory store, with an
+// admin whose role requires a second factor
+type twoFactorFixture struct {
+	ctx      context.Context
+	userRepo repository.UserRepository
+	login    *auth.LoginUseCase
+	setup    *auth.SetupTwoFactorUseCase
+	verify   *auth.VerifyTwoFactorUseCase
+	admin    *domain.User
+}
+
+func newTwoFactorFixture(t *testing.T) *twoFactorFixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	userRepo := memory.NewMemoryUserRepository(store)
+	clinicRepo := memory.NewMemoryClinicRepository(store)
+	usedTokenRepo := memory.NewMemoryUsedTokenRepository(store)
+	txManager := memory.NewMemoryTxManager(store)
+	recorder := audit.NewRecorder(memory.NewMemoryAuditRepository(store))
+
+	guard := auth.NewLoginGuard(memory.NewMemoryLoginLockoutRepository(store), clinicRepo, nil, recorder, "http://localhost:5173", 5, 20, 15)
+	twoFactor := auth.NewTwoFactorService(memory.NewMemoryTwoFactorRepository(store), clinicRepo, usedTokenRepo, guard, txManager, recorder, "test-secret", []domain.UserRole{domain.RoleAdmin})
+	sso := auth.NewSSOService(nil, memory.NewMemoryExternalIdentityRepository(store), usedTokenRepo, txManager, recorder, "test-secret", "", nil, nil)
+	tokens := auth.NewTokenService(memory.NewMemoryRefreshTokenRepository(store), memory.NewMemorySessionRepository(store), jwtkeys.NewHMAC("test-secret"), 15, 30)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	now := time.Now()
+	f := &twoFactorFixture{
+		ctx:      ctx,
+		userRepo: userRepo,
+		login:    auth.NewLoginUseCase(userRepo, tokens, guard, twoFactor, sso),
+		setup:    auth.NewSetupTwoFactorUseCase(userRepo, twoFactor),
+		verify:   auth.NewVerifyTwoFactorUseCase(userRepo, twoFactor, tokens, guard),
+		admin: &domain.User{
+			ID:           "admin-user",
+			Email:        "admin@clinica.test",
+			PasswordHash: string(hash),
+			FirstName:    "Elena",
+			LastName:     "Rojas",
+			Role:         domain.RoleAdmin,
+			IsActive:     true,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		},
+	}
+	if err := userRepo.Create(ctx, f.admin); err != nil {
+		t.Fatalf("create admin: %v", err)
+	}
+	return f
+}
+
+// challenge logs the admin in with the password and returns the challenge token
+func (f *twoFactorFixture) challenge(t *testing.T) string {
+	t.Helper()
+	response, _, err := f.login.Execute(f.ctx, auth.LoginRequest{Email: f.admin.Email, Password: testPassword})
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if response.TwoFactor == nil || response.TwoFactor.ChallengeToken == "" {
+		t.Fatalf("login started a session, want a two-factor challenge")
+	}
+	return response.TwoFactor.ChallengeToken
+}
+
+// enrollment is the second factor the admin enrolled at their first login
+type enrollment struct {
+	secret        string
+	step          int64 // Step of the code that confirmed it
+	recoveryCodes []string
+}
+
+// enroll goes through the first login of the admin, which enrolls and
+// confirms their second factor
+func (f *twoFactorFixture) enroll(t *testing.T) *enrollment {
+	t.Helper()
+	token := f.challenge(t)
+	setup, err := f.setup.Execute(f.ctx, auth.TwoFactorSetupRequest{ChallengeToken: token})
+	if err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	step := totp.Step(time.Now())
+	response, _, err := f.verify.Execute(f.ctx, auth.VerifyTwoFactorRequest{ChallengeToken: token, Code: code(t, setup.Secret, step)})
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	return &enrollment{secret: setup.Secret, step: step, recoveryCodes: response.RecoveryCodes}
+}
+
+func code(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	code, err := totp.Code(secret, step)
+	if err != nil {
+		t.Fatalf("code: %v", err)
+	}
+	return code
+}
+
+func TestConfirmTwoFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    func(t *testing.T, secret string) string
+		wantErr string
+	}{
+		{"first code from the app", func(t *testing.T, secret string) string { return code(t, secret, totp.Step(time.Now())) }, ""},
+		{"code of another time", func(t *testing.T, secret string) string { return code(t, secret, totp.Step(time.Now())-10) }, "invalid two-factor code"},
+		{"no code", func(t *testing.T, secret string) string { return "" }, "code is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTwoFactorFixture(t)
+			token := f.challenge(t)
+			setup, err := f.setup.Execute(f.ctx, auth.TwoFactorSetupRequest{ChallengeToken: token})
+			if err != nil {
+				t.Fatalf("setup: %v", err)
+			}
+
+			response, _, err := f.verify.Execute(f.ctx, auth.VerifyTwoFactorRequest{ChallengeToken: token, Code: tt.code(t, setup.Secret)})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if response.Token == "" || len(response.RecoveryCodes) != 10 {
+				t.Errorf("got token %t and %d recovery codes, want a token and 10 codes", response.Token != "", len(response.RecoveryCodes))
+			}
+
+			// The next login asks for a code instead of enrolling again
+			if _, err := f.setup.Execute(f.ctx, auth.TwoFactorSetupRequest{ChallengeToken: f.challenge(t)}); err == nil {
+				t.Errorf("setup after confirming succeeded, want it refused")
+			}
+		})
+	}
+}
+
+func TestVerifyTwoFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest
+		wantErr string
+	}{
+		{
+			"code from the app",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				return auth.VerifyTwoFactorRequest{Code: code(t, enrolled.secret, enrolled.step+1)}
+			},
+			"",
+		},
+		{
+			"code of a step already used",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				return auth.VerifyTwoFactorRequest{Code: code(t, enrolled.secret, enrolled.step)}
+			},
+			"invalid two-factor code",
+		},
+		{
+			"code of another time",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				return auth.VerifyTwoFactorRequest{Code: code(t, enrolled.secret, enrolled.step+10)}
+			},
+			"invalid two-factor code",
+		},
+		{
+			"recovery code",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				return auth.VerifyTwoFactorRequest{RecoveryCode: enrolled.recoveryCodes[0]}
+			},
+			"",
+		},
+		{
+			"recovery code already used",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				req := auth.VerifyTwoFactorRequest{ChallengeToken: f.challenge(t), RecoveryCode: enrolled.recoveryCodes[0]}
+				if _, _, err := f.verify.Execute(f.ctx, req); err != nil {
+					t.Fatalf("first use of the recovery code: %v", err)
+				}
+				return auth.VerifyTwoFactorRequest{RecoveryCode: enrolled.recoveryCodes[0]}
+			},
+			"invalid two-factor code",
+		},
+		{
+			"unknown recovery code",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				return auth.VerifyTwoFactorRequest{RecoveryCode: "zzzzz-zzzzz"}
+			},
+			"invalid two-factor code",
+		},
+		{
+			"no code",
+			func(t *testing.T, f *twoFactorFixture, enrolled *enrollment) auth.VerifyTwoFactorRequest {
+				return auth.VerifyTwoFactorRequest{}
+			},
+			"code is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTwoFactorFixture(t)
+			enrolled := f.enroll(t)
+			req := tt.request(t, f, enrolled)
+			req.ChallengeToken = f.challenge(t)
+
+			response, _, err := f.verify.Execute(f.ctx, req)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute: %v", err)
+			}
+			if response.Token == "" || response.RecoveryCodes != nil {
+				t.Errorf("got token %t and recovery codes %v, want a token only", response.Token != "", response.RecoveryCodes)
+			}
+		})
+	}
+}
+
+func TestTwoFactorChallengeIsSingleUse(t *testing.T) {
+	f := newTwoFactorFixture(t)
+	enrolled := f.enroll(t)
+
+	token := f.challenge(t)
+	if _, _, err := f.verify.Execute(f.ctx, auth.VerifyTwoFactorRequest{ChallengeToken: token, RecoveryCode: enrolled.recoveryCodes[0]}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	// Even with another valid code the challenge does not start a second session
+	_, _, err := f.verify.Execute(f.ctx, auth.VerifyTwoFactorRequest{ChallengeToken: token, RecoveryCode: enrolled.recoveryCodes[1]})
+	if err == nil || err.Error() != "invalid or expired two-factor challenge" {
+		t.Errorf("replayed challenge error = %v, want invalid challenge", err)
+	}
+}
+
+func TestTwoFactorChallengeVoidedByAccountChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(user *domain.User)
+	}{
+		{"password reset", func(user *domain.User) { user.PasswordHash = "new-hash" }},
+		{"deactivation", func(user *domain.User) { user.IsActive = false }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newTwoFactorFixture(t)
+			enrolled := f.enroll(t)
+			token := f.challenge(t)
+
+			user, err := f.userRepo.FindByID(f.ctx, f.admin.ID)
+			if err != nil || user == nil {
+				t.Fatalf("find admin: %v", err)
+			}
+			tt.change(user)
+			user.UpdatedAt = time.Now()
+			if err := f.userRepo.Update(f.ctx, user); err != nil {
+				t.Fatalf("update admin: %v", err)
+			}
+
+			_, _, err = f.verify.Execute(f.ctx, auth.VerifyTwoFactorRequest{ChallengeToken: token, RecoveryCode: enrolled.recoveryCodes[0]})
+			if err == nil || err.Error() != "invalid or expired two-factor challenge" {
+				t.Errorf("Execute error = %v, want invalid challenge", err)
+			}
+		})
+	}
+}
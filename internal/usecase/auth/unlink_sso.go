@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+)
+
+// UnlinkSSOUseCase handles the business logic for an admin removing the link
+// between a user and their single sign-on identity, for example when the
+// person got a new account at the provider
+type UnlinkSSOUseCase struct {
+	userRepo     repository.UserRepository
+	identityRepo repository.ExternalIdentityRepository
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
+}
+
+// NewUnlinkSSOUseCase creates a new instance of UnlinkSSOUseCase
+func NewUnlinkSSOUseCase(userRepo repository.UserRepository, identityRepo repository.ExternalIdentityRepository, txManager repository.TxManager, recorder *audit.Recorder) *UnlinkSSOUseCase {
+	return &UnlinkSSOUseCase{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		txManager:    txManager,
+		recorder:     recorder,
+	}
+}
+
+// Execute removes every identity linked to a user
+// Their next single sign-on links the account again by email
+func (uc *UnlinkSSOUseCase) Execute(ctx context.Context, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user ID is required")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+	// Only admins unlink another admin, who could otherwise be taken over
+	// through an identity with the same email
+	role, _ := ctx.Value(requestctx.RoleKey).(string)
+	if !domain.UserRole(role).CanManage(user.Role) {
+		return errors.New("only administrators can unlink the identity of an administrator")
+	}
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		identities, err := uc.identityRepo.ListByUser(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if len(identities) == 0 {
+			return errors.New("user has no single sign-on identity")
+		}
+
+		for _, identity := range identities {
+			if _, err := uc.identityRepo.Delete(ctx, identity.ID); err != nil {
+				return err
+			}
+			if err := uc.recorder.Record(ctx, domain.AuditEntityIdentity, identity.ID, domain.AuditActionDelete, identity, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// errInvalidUnlockToken is returned for unknown tokens and lockouts that already ended
+var errInvalidUnlockToken = errors.New("invalid or expired unlock token")
+
+// UnlockAccountUseCase handles the business logic for unlocking an account
+// with the link emailed when it was locked after too many failed logins
+type UnlockAccountUseCase struct {
+	lockoutRepo repository.LoginLockoutRepository
+	txManager   repository.TxManager
+	recorder    *audit.Recorder
+}
+
+// NewUnlockAccountUseCase creates a new instance of UnlockAccountUseCase
+func NewUnlockAccountUseCase(
+	lockoutRepo repository.LoginLockoutRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *UnlockAccountUseCase {
+	return &UnlockAccountUseCase{
+		lockoutRepo: lockoutRepo,
+		txManager:   txManager,
+		recorder:    recorder,
+	}
+}
+
+// Execute ends the lockout of the token and forgets the failed logins of the account
+// The client address stays locked if it reached its own limit
+func (uc *UnlockAccountUseCase) Execute(ctx context.Context, req UnlockAccountRequest) error {
+	if strings.TrimSpace(req.Token) == "" {
+		return errors.New("token is required")
+	}
+
+	lockout, err := uc.lockoutRepo.FindByUnlockHash(ctx, HashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if lockout == nil || !lockout.IsLocked(time.Now()) {
+		return errInvalidUnlockToken
+	}
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		deleted, err := uc.lockoutRepo.Delete(ctx, lockout.ID)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return errInvalidUnlockToken
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityLoginLockout, lockout.ID, domain.AuditActionUnlock, lockout, nil)
+	})
+}
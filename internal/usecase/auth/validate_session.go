@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// sessionSeenInterval is how stale last_seen_at of a session may get before a
+// request updates it, so most requests only read it
+const sessionSeenInterval = time.Minute
+
+// ValidateSessionUseCase checks that a signed access token still grants access
+// A token stays valid until it expires, so AuthMiddleware asks on every request
+// whether its user or session was ended in the meantime
+type ValidateSessionUseCase struct {
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionRepo      repository.SessionRepository
+}
+
+// NewValidateSessionUseCase creates a new instance of ValidateSessionUseCase
+func NewValidateSessionUseCase(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, sessionRepo repository.SessionRepository) *ValidateSessionUseCase {
+	return &ValidateSessionUseCase{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+	}
+}
+
+// Execute returns an error if the user of the token was deleted or deactivated,
+// no longer has the role the token claims, or if the session was revoked
+// Tokens issued before sessions existed carry no session and are refused
+// Sessions that pass are marked as seen for the list of the user's devices
+func (uc *ValidateSessionUseCase) Execute(ctx context.Context, userID, role, sessionID string) error {
+	if sessionID == "" {
+		return errors.New("token has been revoked")
+	}
+
+	user, err := uc.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive || string(user.Role) != role {
+		return errors.New("token has been revoked")
+	}
+
+	revoked, err := uc.refreshTokenRepo.IsSessionRevoked(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return errors.New("token has been revoked")
+	}
+
+	session, err := uc.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if now := time.Now(); session != nil && now.Sub(session.LastSeenAt) >= sessionSeenInterval {
+		if err := uc.sessionRepo.Touch(ctx, sessionID, now); err != nil {
+			log.Printf("Error recording session activity: %v", err)
+		}
+	}
+
+	return nil
+}
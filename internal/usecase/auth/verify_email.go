@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// VerifyEmailUseCase handles the business logic for confirming an email address
+type VerifyEmailUseCase struct {
+	userRepo     repository.UserRepository
+	verification *EmailVerification
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
+}
+
+// NewVerifyEmailUseCase creates a new instance of VerifyEmailUseCase
+func NewVerifyEmailUseCase(userRepo repository.UserRepository, verification *EmailVerification, txManager repository.TxManager, recorder *audit.Recorder) *VerifyEmailUseCase {
+	return &VerifyEmailUseCase{
+		userRepo:     userRepo,
+		verification: verification,
+		txManager:    txManager,
+		recorder:     recorder,
+	}
+}
+
+// Execute marks the email of the link's user as verified
+// The link is looked up in the clinic it was issued for, whatever clinic the
+// request names. Opening a link again after verifying is not an error
+func (uc *VerifyEmailUseCase) Execute(ctx context.Context, token string) (*VerifyEmailResponse, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.New("token is required")
+	}
+
+	claims, err := uc.verification.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = repository.WithClinic(ctx, claims.clinicID)
+	user, err := uc.userRepo.FindByID(ctx, claims.userID)
+	if err != nil {
+		return nil, err
+	}
+	// A link sent before the email changed does not verify the new one
+	if user == nil || !strings.EqualFold(user.Email, claims.email) {
+		return nil, errors.New("invalid or expired verification link")
+	}
+
+	if !user.IsEmailVerified() {
+		before := *user
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		user.UpdatedAt = now
+
+		err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := uc.userRepo.Update(ctx, user); err != nil {
+				return err
+			}
+			return uc.recorder.Record(ctx, domain.AuditEntityUser, user.ID, domain.AuditActionVerifyEmail, &before, user)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &VerifyEmailResponse{
+		Email:           user.Email,
+		EmailVerifiedAt: *user.EmailVerifiedAt,
+	}, nil
+}
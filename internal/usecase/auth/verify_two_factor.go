@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// VerifyTwoFactorUseCase handles the business logic for the second step of a
+// login: trading a challenge token and a code for the session tokens
+type VerifyTwoFactorUseCase struct {
+	userRepo  repository.UserRepository
+	twoFactor *TwoFactorService
+	tokens    *TokenService
+	guard     *LoginGuard
+}
+
+// NewVerifyTwoFactorUseCase creates a new instance of VerifyTwoFactorUseCase
+func NewVerifyTwoFactorUseCase(
+	userRepo repository.UserRepository,
+	twoFactor *TwoFactorService,
+	tokens *TokenService,
+	guard *LoginGuard,
+) *VerifyTwoFactorUseCase {
+	return &VerifyTwoFactorUseCase{
+		userRepo:  userRepo,
+		twoFactor: twoFactor,
+		tokens:    tokens,
+		guard:     guard,
+	}
+}
+
+// Execute starts the session of the challenge's user if the code is right
+// Each challenge starts one session at most. A setup challenge confirms the
+// second factor enrolled with it, and the response then also carries the
+// recovery codes. Wrong codes count as failed logins; the returned duration
+// tells how long to wait once they are refused
+func (uc *VerifyTwoFactorUseCase) Execute(ctx context.Context, req VerifyTwoFactorRequest) (*LoginResponse, time.Duration, error) {
+	if strings.TrimSpace(req.ChallengeToken) == "" {
+		return nil, 0, errors.New("challenge token is required")
+	}
+
+	claims, err := uc.twoFactor.parseChallenge(req.ChallengeToken)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The challenge is looked up in the clinic it was issued for
+	ctx = repository.WithClinic(ctx, claims.clinicID)
+	user, err := uc.userRepo.FindByID(ctx, claims.userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !claims.issuedFor(user) {
+		return nil, 0, errInvalidChallenge
+	}
+
+	var recoveryCodes []string
+	if claims.purpose == challengeSetup {
+		var wait time.Duration
+		if recoveryCodes, wait, err = uc.twoFactor.Confirm(ctx, user, req.Code); err != nil {
+			return nil, wait, err
+		}
+	} else {
+		twoFactor, err := uc.twoFactor.enabled(ctx, user.ID)
+		// Reset by an admin since the password was checked
+		if err == errTwoFactorNotEnabled {
+			return nil, 0, errInvalidChallenge
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if wait, err := uc.twoFactor.Verify(ctx, user, twoFactor, req.Code, req.RecoveryCode); err != nil {
+			return nil, wait, err
+		}
+	}
+
+	if err := uc.twoFactor.useChallenge(ctx, claims); err != nil {
+		return nil, 0, err
+	}
+
+	if err := uc.guard.Succeeded(ctx, user.Email); err != nil {
+		log.Printf("Error clearing failed logins: %v", err)
+	}
+
+	// Start a new session with its first pair of tokens
+	response, err := uc.tokens.Start(ctx, user)
+	if err != nil {
+		return nil, 0, err
+	}
+	response.RecoveryCodes = recoveryCodes
+
+	return response, 0, nil
+}
@@ -1,14 +1,52 @@
 package doctor
 
-import "time"
+import (
+	"time"
+
+	"version-1-0/internal/usecase/pagination"
+)
 
 // DoctorSearchResponse represents a doctor in search results
 type DoctorSearchResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Phone     string    `json:"phone"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	ID              string    `json:"id"`
+	Email           string    `json:"email"`
+	FirstName       string    `json:"first_name"`
+	LastName        string    `json:"last_name"`
+	Phone           string    `json:"phone"`
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	Specialty       string    `json:"specialty"`
+	ConsultationFee float64   `json:"consultation_fee"`
+	Bio             string    `json:"bio,omitempty"`
+	Services        []string  `json:"services"`        // Names of the services the doctor offers
+	Score           int       `json:"score,omitempty"` // Relevance to the query, only set when q is given
+}
+
+// SearchDoctorsRequest represents the input for searching doctors
+// Sort accepts relevance (default when q is given), name (default otherwise), fee and created_at
+type SearchDoctorsRequest struct {
+	Query     string   `json:"q"`          // Free text matched against name, specialty, services and bio
+	Specialty string   `json:"specialty"`  // Only doctors whose specialty matches, ignoring accents and case
+	ServiceID string   `json:"service_id"` // Only doctors that offer this service
+	Date      string   `json:"date"`       // YYYY-MM-DD, only doctors with a free slot that day
+	MinFee    *float64 `json:"min_fee"`
+	MaxFee    *float64 `json:"max_fee"`
+	pagination.Request
+}
+
+// SearchDoctorsResponse represents one page of doctors
+// NextCursor is empty on the last page
+type SearchDoctorsResponse struct {
+	Doctors    []DoctorSearchResponse `json:"doctors"`
+	NextCursor string                 `json:"next_cursor"`
+}
+
+// DeletedDoctorResponse represents a soft deleted doctor profile
+type DeletedDoctorResponse struct {
+	ID              string     `json:"id"`
+	UserID          string     `json:"user_id"`
+	Specialty       string     `json:"specialty"`
+	LicenseNumber   string     `json:"license_number"`
+	ConsultationFee float64    `json:"consultation_fee"`
+	DeletedAt       *time.Time `json:"deleted_at"`
 }
@@ -0,0 +1,41 @@
+package doctor
+
+import (
+	"context"
+
+	"version-1-0/internal/repository"
+)
+
+// ListDeletedDoctorsUseCase handles retrieving soft deleted doctor profiles
+type ListDeletedDoctorsUseCase struct {
+	doctorRepo repository.DoctorRepository
+}
+
+// NewListDeletedDoctorsUseCase creates a new instance of ListDeletedDoctorsUseCase
+func NewListDeletedDoctorsUseCase(doctorRepo repository.DoctorRepository) *ListDeletedDoctorsUseCase {
+	return &ListDeletedDoctorsUseCase{
+		doctorRepo: doctorRepo,
+	}
+}
+
+// Execute retrieves every soft deleted doctor profile that has not been purged yet
+func (uc *ListDeletedDoctorsUseCase) Execute(ctx context.Context) ([]DeletedDoctorResponse, error) {
+	doctors, err := uc.doctorRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]DeletedDoctorResponse, len(doctors))
+	for i, doctor := range doctors {
+		responses[i] = DeletedDoctorResponse{
+			ID:              doctor.ID,
+			UserID:          doctor.UserID,
+			Specialty:       doctor.Specialty,
+			LicenseNumber:   doctor.LicenseNumber,
+			ConsultationFee: doctor.ConsultationFee,
+			DeletedAt:       doctor.DeletedAt,
+		}
+	}
+
+	return responses, nil
+}
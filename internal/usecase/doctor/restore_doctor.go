@@ -0,0 +1,73 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// RestoreDoctorUseCase handles the business logic for restoring soft deleted doctor profiles
+type RestoreDoctorUseCase struct {
+	doctorRepo repository.DoctorRepository
+	userRepo   repository.UserRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewRestoreDoctorUseCase creates a new instance of RestoreDoctorUseCase
+func NewRestoreDoctorUseCase(
+	doctorRepo repository.DoctorRepository,
+	userRepo repository.UserRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *RestoreDoctorUseCase {
+	return &RestoreDoctorUseCase{
+		doctorRepo: doctorRepo,
+		userRepo:   userRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute restores a soft deleted doctor profile by ID
+// If the doctor's user account was deleted along with it, the account is restored too
+func (uc *RestoreDoctorUseCase) Execute(ctx context.Context, doctorID string) error {
+	if strings.TrimSpace(doctorID) == "" {
+		return errors.New("doctor ID is required")
+	}
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		doctor, err := uc.doctorRepo.FindDeletedByID(ctx, doctorID)
+		if err != nil {
+			return err
+		}
+		if doctor == nil {
+			return errors.New("doctor not found")
+		}
+
+		if err := uc.doctorRepo.Restore(ctx, doctorID); err != nil {
+			return err
+		}
+		if err := uc.recorder.Record(ctx, domain.AuditEntityDoctor, doctorID, domain.AuditActionRestore, nil, nil); err != nil {
+			return err
+		}
+
+		// FindByID skips deleted users, so nil means the account needs restoring as well
+		user, err := uc.userRepo.FindByID(ctx, doctor.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			if err := uc.userRepo.Restore(ctx, doctor.UserID); err != nil {
+				return err
+			}
+			return uc.recorder.Record(ctx, domain.AuditEntityUser, doctor.UserID, domain.AuditActionRestore, nil, nil)
+		}
+
+		return nil
+	})
+}
@@ -2,64 +2,307 @@ package doctor
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"fmt"
+	"time"
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/pagination"
+	"version-1-0/pkg/textsearch"
 )
 
-// SearchDoctorsUseCase handles searching for doctors by specialty
+// Sort keys only the doctor search offers; results are ranked in memory
+const (
+	sortRelevance = "relevance"
+	sortFee       = "fee"
+)
+
+// doctorSorts are the orders doctors can be listed in
+var doctorSorts = pagination.Sorts{
+	sortRelevance:            true,
+	repository.SortName:      false,
+	sortFee:                  false,
+	repository.SortCreatedAt: true,
+}
+
+// Weights of a match in each searchable field
+// A name hit outranks a specialty hit, which outranks services and the bio
+const (
+	nameWeight      = 4
+	specialtyWeight = 3
+	serviceWeight   = 2
+	bioWeight       = 1
+)
+
+// SearchDoctorsUseCase handles searching for doctors by text and filters
 type SearchDoctorsUseCase struct {
-	userRepo repository.UserRepository
+	doctorRepo      repository.DoctorRepository
+	serviceRepo     repository.ServiceRepository
+	scheduleRepo    repository.ScheduleRepository
+	appointmentRepo repository.AppointmentRepository
 }
 
 // NewSearchDoctorsUseCase creates a new instance of SearchDoctorsUseCase
-func NewSearchDoctorsUseCase(userRepo repository.UserRepository) *SearchDoctorsUseCase {
+func NewSearchDoctorsUseCase(
+	doctorRepo repository.DoctorRepository,
+	serviceRepo repository.ServiceRepository,
+	scheduleRepo repository.ScheduleRepository,
+	appointmentRepo repository.AppointmentRepository,
+) *SearchDoctorsUseCase {
 	return &SearchDoctorsUseCase{
-		userRepo: userRepo,
+		doctorRepo:      doctorRepo,
+		serviceRepo:     serviceRepo,
+		scheduleRepo:    scheduleRepo,
+		appointmentRepo: appointmentRepo,
 	}
 }
 
-// Execute searches for doctors, optionally filtered by specialty
-// Uses the first 6 characters of the search term to avoid accent/encoding issues
-// This makes the search work with accented input (e.g., "Cardiología" finds "Cardiologia")
-func (uc *SearchDoctorsUseCase) Execute(ctx context.Context, specialty string) ([]DoctorSearchResponse, error) {
-	var users []*domain.User
-	var err error
-
-	// If specialty is provided, filter by specialty
-	if strings.TrimSpace(specialty) != "" {
-		searchTerm := strings.TrimSpace(specialty)
-
-		// Use only first 6 characters to avoid accent issues
-		// This allows "Cardiología" to match "Cardiologia"
-		if len(searchTerm) > 6 {
-			searchTerm = searchTerm[:6]
+// doctorMatch is a candidate that passed every filter, with its relevance
+type doctorMatch struct {
+	result *repository.DoctorSearchResult
+	score  int
+}
+
+// Execute searches for one page of doctors
+// Text is folded to lowercase without accents, so "cardiología" and
+// "Cardiologia" match the same doctors; every word of q must start a word of
+// the doctor's name, specialty, services or bio
+func (uc *SearchDoctorsUseCase) Execute(ctx context.Context, req SearchDoctorsRequest) (*SearchDoctorsResponse, error) {
+	query := textsearch.Words(req.Query)
+
+	fallback := repository.SortName
+	if len(query) > 0 {
+		fallback = sortRelevance
+	}
+	page, err := pagination.Resolve(req.Request, doctorSorts, fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate fee range
+	if req.MinFee != nil && *req.MinFee < 0 || req.MaxFee != nil && *req.MaxFee < 0 {
+		return nil, errors.New("fees cannot be negative")
+	}
+	if req.MinFee != nil && req.MaxFee != nil && *req.MinFee > *req.MaxFee {
+		return nil, errors.New("min_fee cannot be greater than max_fee")
+	}
+
+	filters := repository.DoctorSearchFilters{
+		Words:     query,
+		Specialty: textsearch.Words(req.Specialty),
+		ServiceID: req.ServiceID,
+		MinFee:    req.MinFee,
+		MaxFee:    req.MaxFee,
+	}
+
+	// Availability needs the date's weekday to narrow the candidates and the
+	// visit length to look for a free slot
+	var date time.Time
+	visitMinutes := 0
+	if req.Date != "" {
+		date, err = time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, errors.New("invalid date format, use YYYY-MM-DD")
 		}
+		filters.DayOfWeek = domain.GetDayOfWeekFromDate(date)
 
-		users, err = uc.userRepo.FindDoctorsBySpecialty(ctx, searchTerm)
-	} else {
-		// Otherwise, get all doctors
-		users, err = uc.userRepo.GetAllDoctors(ctx)
+		if req.ServiceID != "" {
+			service, err := uc.serviceRepo.FindByID(ctx, req.ServiceID)
+			if err != nil {
+				return nil, err
+			}
+			if service != nil {
+				visitMinutes = service.DurationMinutes
+			}
+		}
 	}
 
+	candidates, err := uc.doctorRepo.FindSearchCandidates(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
 
+	// The repository already matched the words; scoring only ranks them
+	var matches []doctorMatch
+	for _, candidate := range candidates {
+		score := 0
+		if len(query) > 0 {
+			score = textsearch.Score(query, searchFields(candidate))
+			if score == 0 {
+				continue
+			}
+		}
+		matches = append(matches, doctorMatch{result: candidate, score: score})
+	}
+
+	if req.Date != "" {
+		matches, err = uc.withFreeSlot(ctx, matches, date, visitMinutes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Rank and cut the page in memory, where the relevance is known
+	position := func(m doctorMatch) repository.Cursor {
+		return repository.Cursor{Key: matchSortKey(m, page.Sort), ID: m.result.User.ID}
+	}
+	matches = repository.SlicePage(matches, pagination.Lookahead(page),
+		func(m doctorMatch) string { return position(m).Key },
+		func(m doctorMatch) string { return position(m).ID },
+	)
+	matches, nextCursor := pagination.Finish(page, matches, position)
+
 	// Convert to response DTOs
-	responses := make([]DoctorSearchResponse, len(users))
-	for i, user := range users {
+	responses := make([]DoctorSearchResponse, len(matches))
+	for i, match := range matches {
+		user, doctor := match.result.User, match.result.Doctor
+		services := match.result.Services
+		if services == nil {
+			services = []string{}
+		}
 		responses[i] = DoctorSearchResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Phone:     user.Phone,
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
+			ID:              user.ID,
+			Email:           user.Email,
+			FirstName:       user.FirstName,
+			LastName:        user.LastName,
+			Phone:           user.Phone,
+			IsActive:        user.IsActive,
+			CreatedAt:       user.CreatedAt,
+			Specialty:       doctor.Specialty,
+			ConsultationFee: doctor.ConsultationFee,
+			Bio:             doctor.Bio,
+			Services:        services,
+			Score:           match.score,
 		}
 	}
 
-	return responses, nil
+	return &SearchDoctorsResponse{
+		Doctors:    responses,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// searchFields lists the text of a doctor the query is matched against
+func searchFields(candidate *repository.DoctorSearchResult) []textsearch.Field {
+	fields := []textsearch.Field{
+		{Text: candidate.User.FirstName + " " + candidate.User.LastName, Weight: nameWeight},
+		{Text: candidate.Doctor.Specialty, Weight: specialtyWeight},
+		{Text: candidate.Doctor.Bio, Weight: bioWeight},
+	}
+	for _, service := range candidate.Services {
+		fields = append(fields, textsearch.Field{Text: service, Weight: serviceWeight})
+	}
+	return fields
+}
+
+// matchSortKey returns the value a search result is ordered by
+// Numbers are zero padded so they compare as strings
+func matchSortKey(m doctorMatch, sort string) string {
+	switch sort {
+	case sortRelevance:
+		return fmt.Sprintf("%06d", m.score)
+	case sortFee:
+		return fmt.Sprintf("%012.2f", m.result.Doctor.ConsultationFee)
+	default:
+		return repository.UserSortKey(m.result.User, sort)
+	}
+}
+
+// withFreeSlot keeps the matches whose doctor has a free slot on the date
+// Schedules and appointments of every match are loaded in one query each
+func (uc *SearchDoctorsUseCase) withFreeSlot(ctx context.Context, matches []doctorMatch, date time.Time, visitMinutes int) ([]doctorMatch, error) {
+	if len(matches) == 0 {
+		return matches, nil
+	}
+
+	doctorIDs := make([]string, len(matches))
+	for i, match := range matches {
+		doctorIDs[i] = match.result.Doctor.ID
+	}
+
+	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	schedules, err := uc.scheduleRepo.FindByDoctorsAndDay(ctx, doctorIDs, domain.GetDayOfWeekFromDate(date))
+	if err != nil {
+		return nil, err
+	}
+	appointments, err := uc.appointmentRepo.FindByDoctorsAndDateRange(ctx, doctorIDs, startOfDay, endOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	schedulesByDoctor := make(map[string][]*domain.Schedule)
+	for _, schedule := range schedules {
+		schedulesByDoctor[schedule.DoctorID] = append(schedulesByDoctor[schedule.DoctorID], schedule)
+	}
+	appointmentsByDoctor := make(map[string][]*domain.Appointment)
+	for _, appointment := range appointments {
+		appointmentsByDoctor[appointment.DoctorID] = append(appointmentsByDoctor[appointment.DoctorID], appointment)
+	}
+
+	now := time.Now()
+	free := matches[:0]
+	for _, match := range matches {
+		doctorID := match.result.Doctor.ID
+		if hasFreeSlot(schedulesByDoctor[doctorID], appointmentsByDoctor[doctorID], startOfDay, visitMinutes, now) {
+			free = append(free, match)
+		}
+	}
+	return free, nil
+}
+
+// hasFreeSlot reports whether a doctor's schedules for the day hold a slot
+// that is still ahead and does not overlap a booked appointment
+// Slots are laid out like GetAvailableSlots does: from the start of each
+// schedule block, one visit after another; visitMinutes of zero uses the
+// schedule's own slot duration
+func hasFreeSlot(schedules []*domain.Schedule, appointments []*domain.Appointment, startOfDay time.Time, visitMinutes int, now time.Time) bool {
+	for _, schedule := range schedules {
+		duration := visitMinutes
+		if duration <= 0 {
+			duration = schedule.SlotDuration
+		}
+		if duration <= 0 {
+			continue
+		}
+
+		start, err := time.Parse("15:04", schedule.StartTime)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("15:04", schedule.EndTime)
+		if err != nil {
+			continue
+		}
+
+		endMinutes := end.Hour()*60 + end.Minute()
+		for minutes := start.Hour()*60 + start.Minute(); minutes < endMinutes; minutes += duration {
+			slotStart := startOfDay.Add(time.Duration(minutes) * time.Minute)
+			slotEnd := slotStart.Add(time.Duration(duration) * time.Minute)
+			if slotStart.Before(now) {
+				continue
+			}
+			if !overlapsAppointment(appointments, slotStart, slotEnd) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// overlapsAppointment reports whether a time range collides with a booked appointment
+func overlapsAppointment(appointments []*domain.Appointment, start, end time.Time) bool {
+	for _, appointment := range appointments {
+		if appointment.Status == domain.StatusCancelled {
+			continue
+		}
+		appointmentEnd := appointment.ScheduledAt.Add(time.Duration(appointment.Duration) * time.Minute)
+		if start.Before(appointmentEnd) && end.After(appointment.ScheduledAt) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,100 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// AssignDoctorUseCase handles attaching a doctor to a location
+type AssignDoctorUseCase struct {
+	clinicRepo repository.ClinicRepository
+	userRepo   repository.UserRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewAssignDoctorUseCase creates a new instance of AssignDoctorUseCase
+func NewAssignDoctorUseCase(clinicRepo repository.ClinicRepository, userRepo repository.UserRepository, txManager repository.TxManager, recorder *audit.Recorder) *AssignDoctorUseCase {
+	return &AssignDoctorUseCase{
+		clinicRepo: clinicRepo,
+		userRepo:   userRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute attaches the doctor with the given user ID to a location
+func (uc *AssignDoctorUseCase) Execute(ctx context.Context, locationID, userID string) (*domain.DoctorLocation, error) {
+	location, err := uc.clinicRepo.FindLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, errors.New("location not found")
+	}
+	if !location.IsActive {
+		return nil, errors.New("location is not active")
+	}
+
+	doctorID, err := findDoctorID(ctx, uc.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refuse duplicates so the audit log only records real assignments
+	locations, err := uc.clinicRepo.FindLocationsByDoctor(ctx, doctorID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range locations {
+		if existing.ID == location.ID {
+			return nil, errors.New("doctor already works at this location")
+		}
+	}
+
+	doctorLocation := &domain.DoctorLocation{
+		DoctorID:   doctorID,
+		LocationID: location.ID,
+		CreatedAt:  time.Now(),
+	}
+
+	// Save to repository together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.clinicRepo.AssignDoctor(ctx, doctorLocation); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityDoctorLocation, doctorLocationID(doctorLocation), domain.AuditActionAssign, nil, doctorLocation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doctorLocation, nil
+}
+
+// findDoctorID returns the doctor.id of the doctor with the given user ID
+func findDoctorID(ctx context.Context, userRepo repository.UserRepository, userID string) (string, error) {
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", errors.New("doctor not found")
+	}
+	if user.Role != domain.RoleDoctor {
+		return "", errors.New("user is not a doctor")
+	}
+
+	return userRepo.FindDoctorIDByUserID(ctx, userID)
+}
+
+// doctorLocationID identifies a doctor-location assignment in the audit log
+func doctorLocationID(doctorLocation *domain.DoctorLocation) string {
+	return doctorLocation.DoctorID + "/" + doctorLocation.LocationID
+}
@@ -0,0 +1,68 @@
+package location
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// CreateLocationUseCase handles opening a new location of the clinic
+type CreateLocationUseCase struct {
+	clinicRepo repository.ClinicRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewCreateLocationUseCase creates a new instance of CreateLocationUseCase
+func NewCreateLocationUseCase(clinicRepo repository.ClinicRepository, txManager repository.TxManager, recorder *audit.Recorder) *CreateLocationUseCase {
+	return &CreateLocationUseCase{
+		clinicRepo: clinicRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute creates a new active location in the clinic of the request
+func (uc *CreateLocationUseCase) Execute(ctx context.Context, req CreateLocationRequest) (*domain.Location, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	location := &domain.Location{
+		ID:        uuid.New().String(),
+		ClinicID:  clinicID,
+		Name:      strings.TrimSpace(req.Name),
+		Address:   strings.TrimSpace(req.Address),
+		Phone:     strings.TrimSpace(req.Phone),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+
+	if err := location.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Save to repository together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.clinicRepo.CreateLocation(ctx, location); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityLocation, location.ID, domain.AuditActionCreate, nil, location)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
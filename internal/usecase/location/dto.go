@@ -0,0 +1,35 @@
+package location
+
+import "version-1-0/internal/domain"
+
+// CreateLocationRequest represents the input data for opening a new location
+type CreateLocationRequest struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Phone   string `json:"phone"`
+}
+
+// UpdateLocationRequest represents the input data for updating a location
+// Uses pointers for optional fields
+type UpdateLocationRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Address  *string `json:"address,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// AssignDoctorRequest represents the input for attaching a doctor to a location
+type AssignDoctorRequest struct {
+	DoctorID string `json:"doctor_id"` // The doctor's user ID
+}
+
+// SetServicePriceRequest represents the price a service costs at a location
+type SetServicePriceRequest struct {
+	Price float64 `json:"price"`
+}
+
+// ClinicResponse represents the clinic of the request together with its locations
+type ClinicResponse struct {
+	*domain.Clinic
+	Locations []*domain.Location `json:"locations"`
+}
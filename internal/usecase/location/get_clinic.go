@@ -0,0 +1,43 @@
+package location
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/repository"
+)
+
+// GetClinicUseCase handles retrieving the clinic the request is scoped to
+type GetClinicUseCase struct {
+	clinicRepo repository.ClinicRepository
+}
+
+// NewGetClinicUseCase creates a new instance of GetClinicUseCase
+func NewGetClinicUseCase(clinicRepo repository.ClinicRepository) *GetClinicUseCase {
+	return &GetClinicUseCase{
+		clinicRepo: clinicRepo,
+	}
+}
+
+// Execute retrieves the clinic with its active locations
+func (uc *GetClinicUseCase) Execute(ctx context.Context) (*ClinicResponse, error) {
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	clinic, err := uc.clinicRepo.FindClinicByID(ctx, clinicID)
+	if err != nil {
+		return nil, err
+	}
+	if clinic == nil {
+		return nil, errors.New("clinic not found")
+	}
+
+	locations, err := uc.clinicRepo.ListLocations(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClinicResponse{Clinic: clinic, Locations: locations}, nil
+}
@@ -0,0 +1,34 @@
+package location
+
+import (
+	"context"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// ListLocationsUseCase handles retrieving the locations of the clinic
+type ListLocationsUseCase struct {
+	clinicRepo repository.ClinicRepository
+}
+
+// NewListLocationsUseCase creates a new instance of ListLocationsUseCase
+func NewListLocationsUseCase(clinicRepo repository.ClinicRepository) *ListLocationsUseCase {
+	return &ListLocationsUseCase{
+		clinicRepo: clinicRepo,
+	}
+}
+
+// Execute retrieves the locations of the clinic, oldest first
+// Inactive locations are only included when includeInactive is set
+func (uc *ListLocationsUseCase) Execute(ctx context.Context, includeInactive bool) ([]*domain.Location, error) {
+	locations, err := uc.clinicRepo.ListLocations(ctx, !includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	if locations == nil {
+		locations = []*domain.Location{}
+	}
+
+	return locations, nil
+}
@@ -0,0 +1,63 @@
+package location
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// ListServicePricesUseCase handles retrieving the price overrides of a location
+type ListServicePricesUseCase struct {
+	clinicRepo repository.ClinicRepository
+}
+
+// NewListServicePricesUseCase creates a new instance of ListServicePricesUseCase
+func NewListServicePricesUseCase(clinicRepo repository.ClinicRepository) *ListServicePricesUseCase {
+	return &ListServicePricesUseCase{
+		clinicRepo: clinicRepo,
+	}
+}
+
+// Execute retrieves the price overrides of a location
+func (uc *ListServicePricesUseCase) Execute(ctx context.Context, locationID string) ([]*domain.ServicePrice, error) {
+	location, err := uc.clinicRepo.FindLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, errors.New("location not found")
+	}
+
+	prices, err := uc.clinicRepo.FindServicePrices(ctx, location.ID)
+	if err != nil {
+		return nil, err
+	}
+	if prices == nil {
+		prices = []*domain.ServicePrice{}
+	}
+
+	return prices, nil
+}
+
+// findServicePrice returns the current override of a service at a location, or nil
+func findServicePrice(ctx context.Context, clinicRepo repository.ClinicRepository, locationID, serviceID string) (*domain.ServicePrice, error) {
+	prices, err := clinicRepo.FindServicePrices(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, price := range prices {
+		if price.ServiceID == serviceID {
+			return price, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// servicePriceID identifies a price override in the audit log
+func servicePriceID(price *domain.ServicePrice) string {
+	return price.ServiceID + "/" + price.LocationID
+}
@@ -0,0 +1,60 @@
+package location
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// RemoveDoctorUseCase handles detaching a doctor from a location
+type RemoveDoctorUseCase struct {
+	clinicRepo   repository.ClinicRepository
+	userRepo     repository.UserRepository
+	scheduleRepo repository.ScheduleRepository
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
+}
+
+// NewRemoveDoctorUseCase creates a new instance of RemoveDoctorUseCase
+func NewRemoveDoctorUseCase(clinicRepo repository.ClinicRepository, userRepo repository.UserRepository, scheduleRepo repository.ScheduleRepository, txManager repository.TxManager, recorder *audit.Recorder) *RemoveDoctorUseCase {
+	return &RemoveDoctorUseCase{
+		clinicRepo:   clinicRepo,
+		userRepo:     userRepo,
+		scheduleRepo: scheduleRepo,
+		txManager:    txManager,
+		recorder:     recorder,
+	}
+}
+
+// Execute detaches the doctor with the given user ID from a location
+// Doctors with active schedules at the location must move or delete them first
+func (uc *RemoveDoctorUseCase) Execute(ctx context.Context, locationID, userID string) error {
+	doctorID, err := findDoctorID(ctx, uc.userRepo, userID)
+	if err != nil {
+		return err
+	}
+
+	schedules, err := uc.scheduleRepo.FindByDoctor(ctx, doctorID, repository.PageRequest{Sort: repository.SortCreatedAt})
+	if err != nil {
+		return err
+	}
+	for _, schedule := range schedules {
+		if schedule.LocationID == locationID {
+			return errors.New("doctor still has schedules at this location")
+		}
+	}
+
+	doctorLocation := &domain.DoctorLocation{DoctorID: doctorID, LocationID: locationID}
+
+	// Remove the assignment together with its audit entry
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.clinicRepo.RemoveDoctor(ctx, doctorID, locationID); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityDoctorLocation, doctorLocationID(doctorLocation), domain.AuditActionUnassign, doctorLocation, nil)
+	})
+}
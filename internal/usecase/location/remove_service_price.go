@@ -0,0 +1,46 @@
+package location
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// RemoveServicePriceUseCase handles dropping the price override of a service at a location
+type RemoveServicePriceUseCase struct {
+	clinicRepo repository.ClinicRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewRemoveServicePriceUseCase creates a new instance of RemoveServicePriceUseCase
+func NewRemoveServicePriceUseCase(clinicRepo repository.ClinicRepository, txManager repository.TxManager, recorder *audit.Recorder) *RemoveServicePriceUseCase {
+	return &RemoveServicePriceUseCase{
+		clinicRepo: clinicRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute drops the override so the service's own price applies again
+func (uc *RemoveServicePriceUseCase) Execute(ctx context.Context, locationID, serviceID string) error {
+	before, err := findServicePrice(ctx, uc.clinicRepo, locationID, serviceID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return errors.New("service price not found")
+	}
+
+	// Remove the override together with its audit entry
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.clinicRepo.RemoveServicePrice(ctx, serviceID, locationID); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityServicePrice, servicePriceID(before), domain.AuditActionDelete, before, nil)
+	})
+}
@@ -0,0 +1,85 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// SetServicePriceUseCase handles pricing a service differently at one location
+type SetServicePriceUseCase struct {
+	clinicRepo  repository.ClinicRepository
+	serviceRepo repository.ServiceRepository
+	txManager   repository.TxManager
+	recorder    *audit.Recorder
+}
+
+// NewSetServicePriceUseCase creates a new instance of SetServicePriceUseCase
+func NewSetServicePriceUseCase(clinicRepo repository.ClinicRepository, serviceRepo repository.ServiceRepository, txManager repository.TxManager, recorder *audit.Recorder) *SetServicePriceUseCase {
+	return &SetServicePriceUseCase{
+		clinicRepo:  clinicRepo,
+		serviceRepo: serviceRepo,
+		txManager:   txManager,
+		recorder:    recorder,
+	}
+}
+
+// Execute creates or replaces the price of a service at a location
+func (uc *SetServicePriceUseCase) Execute(ctx context.Context, locationID, serviceID string, req SetServicePriceRequest) (*domain.ServicePrice, error) {
+	location, err := uc.clinicRepo.FindLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, errors.New("location not found")
+	}
+
+	service, err := uc.serviceRepo.FindByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if service == nil {
+		return nil, errors.New("service not found")
+	}
+
+	before, err := findServicePrice(ctx, uc.clinicRepo, location.ID, service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	price := &domain.ServicePrice{
+		ServiceID:  service.ID,
+		LocationID: location.ID,
+		Price:      req.Price,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	action := domain.AuditActionCreate
+	if before != nil {
+		price.CreatedAt = before.CreatedAt
+		action = domain.AuditActionUpdate
+	}
+
+	if err := price.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Save to repository together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.clinicRepo.SetServicePrice(ctx, price); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityServicePrice, servicePriceID(price), action, before, price)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
@@ -0,0 +1,81 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// UpdateLocationUseCase handles updating a location
+type UpdateLocationUseCase struct {
+	clinicRepo repository.ClinicRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewUpdateLocationUseCase creates a new instance of UpdateLocationUseCase
+func NewUpdateLocationUseCase(clinicRepo repository.ClinicRepository, txManager repository.TxManager, recorder *audit.Recorder) *UpdateLocationUseCase {
+	return &UpdateLocationUseCase{
+		clinicRepo: clinicRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute updates a location by ID
+// Deactivating a location keeps its schedules and appointments but it takes no new ones
+// expectedVersion is the version the client last saw, zero skips the check
+func (uc *UpdateLocationUseCase) Execute(ctx context.Context, locationID string, req UpdateLocationRequest, expectedVersion int) (*domain.Location, error) {
+	location, err := uc.clinicRepo.FindLocationByID(ctx, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, errors.New("location not found")
+	}
+
+	// Refuse to act on a stale copy when the client sent the version it saw
+	if err := domain.CheckVersion(location.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	before := *location
+
+	// Update fields if provided
+	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
+		location.Name = strings.TrimSpace(*req.Name)
+	}
+
+	if req.Address != nil {
+		location.Address = strings.TrimSpace(*req.Address)
+	}
+
+	if req.Phone != nil {
+		location.Phone = strings.TrimSpace(*req.Phone)
+	}
+
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
+
+	location.UpdatedAt = time.Now()
+
+	// Update in database together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.clinicRepo.UpdateLocation(ctx, location); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityLocation, location.ID, domain.AuditActionUpdate, &before, location)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return location, nil
+}
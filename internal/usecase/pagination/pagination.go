@@ -0,0 +1,174 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+
+	"version-1-0/internal/repository"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Request represents the pagination query parameters of a list endpoint
+type Request struct {
+	Cursor string `json:"cursor"` // next_cursor of the previous page, empty for the first page
+	Limit  int    `json:"limit"`
+	Sort   string `json:"sort"`
+	Order  string `json:"order"` // "asc" or "desc", empty for the default of the sort
+}
+
+// Sorts lists the sort keys a list accepts, each mapped to whether it
+// defaults to descending order
+type Sorts map[string]bool
+
+// Error is returned for pagination parameters that the client got wrong
+// Handlers answer it with 400
+type Error struct {
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// IsError reports whether err comes from invalid pagination parameters
+func IsError(err error) bool {
+	var pageErr *Error
+	return errors.As(err, &pageErr)
+}
+
+// token is the content of an opaque cursor
+// The sort and order travel with the position so a cursor can't be replayed
+// against a differently ordered list
+type token struct {
+	Sort string `json:"s"`
+	Desc bool   `json:"d"`
+	Key  string `json:"k"`
+	ID   string `json:"id"`
+}
+
+// Resolve validates the pagination parameters against the sorts a list
+// accepts and builds the page to ask the repository for
+// Sort and order default to the ones of the cursor, or else to fallback and
+// its default order; the limit defaults to DefaultLimit and is capped at MaxLimit
+func Resolve(req Request, sorts Sorts, fallback string) (repository.PageRequest, error) {
+	page := repository.PageRequest{Limit: req.Limit}
+	if page.Limit <= 0 {
+		page.Limit = DefaultLimit
+	}
+	if page.Limit > MaxLimit {
+		page.Limit = MaxLimit
+	}
+
+	var after *token
+	if req.Cursor != "" {
+		decoded, err := decode(req.Cursor)
+		if err != nil {
+			return page, &Error{Message: "invalid cursor"}
+		}
+		if _, ok := sorts[decoded.Sort]; !ok {
+			return page, &Error{Message: "invalid cursor"}
+		}
+		if repository.IsTimeSort(decoded.Sort) {
+			if _, err := repository.ParseTimeKey(decoded.Key); err != nil {
+				return page, &Error{Message: "invalid cursor"}
+			}
+		}
+		after = decoded
+	}
+
+	page.Sort = req.Sort
+	if page.Sort == "" {
+		page.Sort = fallback
+		if after != nil {
+			page.Sort = after.Sort
+		}
+	}
+	defaultDesc, ok := sorts[page.Sort]
+	if !ok {
+		return page, &Error{Message: "sort must be one of: " + strings.Join(sortNames(sorts), ", ")}
+	}
+
+	switch req.Order {
+	case "":
+		page.Desc = defaultDesc
+		if after != nil {
+			page.Desc = after.Desc
+		}
+	case "asc":
+		page.Desc = false
+	case "desc":
+		page.Desc = true
+	default:
+		return page, &Error{Message: "order must be asc or desc"}
+	}
+
+	if after != nil {
+		if after.Sort != page.Sort || after.Desc != page.Desc {
+			return page, &Error{Message: "cursor does not match sort and order"}
+		}
+		page.After = &repository.Cursor{Key: after.Key, ID: after.ID}
+	}
+
+	return page, nil
+}
+
+// Lookahead returns the page to ask the repository for: one row more than
+// the client wants, so Finish can tell whether another page follows
+func Lookahead(page repository.PageRequest) repository.PageRequest {
+	page.Limit++
+	return page
+}
+
+// Finish trims the lookahead row from a page fetched with Lookahead and
+// returns the cursor of the next page, empty on the last page
+func Finish[T any](page repository.PageRequest, rows []T, position func(T) repository.Cursor) ([]T, string) {
+	if len(rows) <= page.Limit {
+		return rows, ""
+	}
+
+	rows = rows[:page.Limit]
+	last := position(rows[len(rows)-1])
+
+	return rows, encode(token{Sort: page.Sort, Desc: page.Desc, Key: last.Key, ID: last.ID})
+}
+
+// encode turns a cursor token into an opaque URL-safe string
+func encode(t token) string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decode reads back a cursor written by encode
+func decode(cursor string) (*token, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var t token
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	if t.ID == "" {
+		return nil, errors.New("cursor without id")
+	}
+
+	return &t, nil
+}
+
+// sortNames returns the accepted sort keys in a stable order for error messages
+func sortNames(sorts Sorts) []string {
+	names := make([]string, 0, len(sorts))
+	for name := range sorts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,18 @@
+package patient
+
+import "version-1-0/internal/domain"
+
+// FindByDocumentRequest represents a lookup of patients by identity document
+type FindByDocumentRequest struct {
+	DocumentType   string `json:"document_type"` // Optional: DNI, CE, passport...
+	DocumentNumber string `json:"document_number"`
+}
+
+// PatientResponse represents a patient profile together with their account data
+type PatientResponse struct {
+	*domain.Patient
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Phone     string `json:"phone"`
+}
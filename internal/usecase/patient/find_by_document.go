@@ -0,0 +1,59 @@
+package patient
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/repository"
+)
+
+// FindByDocumentUseCase handles looking up patients by their identity document (admin only)
+type FindByDocumentUseCase struct {
+	patientRepo repository.PatientRepository
+	userRepo    repository.UserRepository
+}
+
+// NewFindByDocumentUseCase creates a new instance of FindByDocumentUseCase
+func NewFindByDocumentUseCase(patientRepo repository.PatientRepository, userRepo repository.UserRepository) *FindByDocumentUseCase {
+	return &FindByDocumentUseCase{
+		patientRepo: patientRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// Execute returns the patients whose document matches, newest first
+// Document numbers are stored encrypted, so the match is exact after ignoring
+// case, spaces and punctuation; partial numbers find nothing
+func (uc *FindByDocumentUseCase) Execute(ctx context.Context, req FindByDocumentRequest) ([]*PatientResponse, error) {
+	if strings.TrimSpace(req.DocumentNumber) == "" {
+		return nil, errors.New("document_number is required")
+	}
+
+	patients, err := uc.patientRepo.FindByDocument(ctx, strings.TrimSpace(req.DocumentType), strings.TrimSpace(req.DocumentNumber))
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*PatientResponse, 0, len(patients))
+	for _, p := range patients {
+		// Patients of deleted accounts are left out
+		user, err := uc.userRepo.FindByID(ctx, p.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			continue
+		}
+
+		response = append(response, &PatientResponse{
+			Patient:   p,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+			Phone:     user.Phone,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,119 @@
+package role
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+)
+
+// errRoleNotFound is returned for names that are not a role
+var errRoleNotFound = errors.New("role not found")
+
+// Authorizer decides what each role may do in the clinic of the request
+// It implements middleware.PermissionChecker, and the use cases that let some
+// roles act on records of other users ask it too
+type Authorizer struct {
+	roleRepo repository.RoleRepository
+}
+
+// NewAuthorizer creates a new instance of Authorizer
+func NewAuthorizer(roleRepo repository.RoleRepository) *Authorizer {
+	return &Authorizer{
+		roleRepo: roleRepo,
+	}
+}
+
+// Can reports whether a role has a permission in the clinic ctx is scoped to
+// Admins always have every permission, so no clinic can lock itself out of
+// managing its roles; unknown roles have none. Requests authenticated with
+// an API key have exactly the scopes of the key
+func (a *Authorizer) Can(ctx context.Context, role string, permission domain.Permission) (bool, error) {
+	if role == domain.APIKeyRole {
+		scopes, _ := ctx.Value(requestctx.APIKeyScopesKey).([]domain.Permission)
+		for _, scope := range scopes {
+			if scope == permission && permission.GrantableToAPIKey() {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if domain.UserRole(role) == domain.RoleAdmin {
+		return true, nil
+	}
+	if !domain.IsValidRole(role) {
+		return false, nil
+	}
+
+	definition, _, err := a.definition(ctx, domain.UserRole(role))
+	if err != nil {
+		return false, err
+	}
+
+	return definition.Has(permission), nil
+}
+
+// CheckGrant returns an error naming the first permission the role lacks
+// Roles and API keys are only given permissions the user giving them holds,
+// so nobody can hand out more than they may do themselves
+func (a *Authorizer) CheckGrant(ctx context.Context, role string, permissions []domain.Permission) error {
+	for _, permission := range permissions {
+		can, err := a.Can(ctx, role, permission)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return fmt.Errorf("permission not held: %s", permission)
+		}
+	}
+	return nil
+}
+
+// definition returns the permissions of a role in the clinic ctx is scoped to
+// Roles the clinic never changed get their defaults, reported as stored false
+func (a *Authorizer) definition(ctx context.Context, role domain.UserRole) (*domain.RoleDefinition, bool, error) {
+	if !domain.IsValidRole(string(role)) {
+		return nil, false, errRoleNotFound
+	}
+
+	if role != domain.RoleAdmin {
+		definition, err := a.roleRepo.FindByRole(ctx, role)
+		if err != nil {
+			return nil, false, err
+		}
+		if definition != nil {
+			return definition, true, nil
+		}
+	}
+
+	clinicID, err := repository.ClinicFor(ctx, "")
+	if err != nil {
+		return nil, false, err
+	}
+	return &domain.RoleDefinition{
+		ClinicID:    clinicID,
+		Role:        role,
+		Permissions: domain.DefaultPermissions(role),
+	}, false, nil
+}
+
+// toRoleResponse converts the permissions of a role to its API representation
+func toRoleResponse(definition *domain.RoleDefinition, stored bool) *RoleResponse {
+	response := &RoleResponse{
+		Role:        string(definition.Role),
+		Description: domain.RoleDescription(definition.Role),
+		Permissions: make([]string, len(definition.Permissions)),
+		Default:     !stored,
+	}
+	for i, permission := range definition.Permissions {
+		response.Permissions[i] = string(permission)
+	}
+	if stored {
+		updatedAt := definition.UpdatedAt
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
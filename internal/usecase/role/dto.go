@@ -0,0 +1,25 @@
+package role
+
+import "time"
+
+// RoleResponse represents what a role may do in the clinic
+// Default is true while the clinic keeps the built-in permissions of the role
+type RoleResponse struct {
+	Role        string     `json:"role"`
+	Description string     `json:"description"`
+	Permissions []string   `json:"permissions"`
+	Default     bool       `json:"default"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// UpdateRoleRequest represents the input data for changing the permissions of a role
+// The list replaces the current permissions of the role
+type UpdateRoleRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionResponse represents a permission that can be given to a role
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
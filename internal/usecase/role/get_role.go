@@ -0,0 +1,29 @@
+package role
+
+import (
+	"context"
+
+	"version-1-0/internal/domain"
+)
+
+// GetRoleUseCase handles the business logic for reading the permissions of a role
+type GetRoleUseCase struct {
+	authorizer *Authorizer
+}
+
+// NewGetRoleUseCase creates a new instance of GetRoleUseCase
+func NewGetRoleUseCase(authorizer *Authorizer) *GetRoleUseCase {
+	return &GetRoleUseCase{
+		authorizer: authorizer,
+	}
+}
+
+// Execute returns a role with the permissions it has in the clinic
+func (uc *GetRoleUseCase) Execute(ctx context.Context, role string) (*RoleResponse, error) {
+	definition, stored, err := uc.authorizer.definition(ctx, domain.UserRole(role))
+	if err != nil {
+		return nil, err
+	}
+
+	return toRoleResponse(definition, stored), nil
+}
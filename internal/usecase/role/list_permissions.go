@@ -0,0 +1,24 @@
+package role
+
+import "version-1-0/internal/domain"
+
+// ListPermissionsUseCase handles the business logic for listing the permissions roles can have
+type ListPermissionsUseCase struct{}
+
+// NewListPermissionsUseCase creates a new instance of ListPermissionsUseCase
+func NewListPermissionsUseCase() *ListPermissionsUseCase {
+	return &ListPermissionsUseCase{}
+}
+
+// Execute returns every permission that can be given to a role
+func (uc *ListPermissionsUseCase) Execute() []PermissionResponse {
+	response := make([]PermissionResponse, 0, len(domain.Permissions))
+	for _, info := range domain.Permissions {
+		response = append(response, PermissionResponse{
+			Name:        string(info.Name),
+			Description: info.Description,
+		})
+	}
+
+	return response
+}
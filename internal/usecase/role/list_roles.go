@@ -0,0 +1,34 @@
+package role
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+)
+
+// ListRolesUseCase handles the business logic for listing the roles of the clinic
+type ListRolesUseCase struct {
+	authorizer *Authorizer
+}
+
+// NewListRolesUseCase creates a new instance of ListRolesUseCase
+func NewListRolesUseCase(authorizer *Authorizer) *ListRolesUseCase {
+	return &ListRolesUseCase{
+		authorizer: authorizer,
+	}
+}
+
+// Execute returns every role with the permissions it has in the clinic
+func (uc *ListRolesUseCase) Execute(ctx context.Context) ([]RoleResponse, error) {
+	response := make([]RoleResponse, 0, len(domain.Roles))
+	for _, role := range domain.Roles {
+		definition, stored, err := uc.authorizer.definition(ctx, role)
+		if err != nil {
+			return nil, errors.New("failed to list roles")
+		}
+		response = append(response, *toRoleResponse(definition, stored))
+	}
+
+	return response, nil
+}
@@ -0,0 +1,66 @@
+package role
+
+import (
+	"context"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// ResetRoleUseCase handles the business logic for giving a role back its default permissions
+type ResetRoleUseCase struct {
+	roleRepo   repository.RoleRepository
+	authorizer *Authorizer
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewResetRoleUseCase creates a new instance of ResetRoleUseCase
+func NewResetRoleUseCase(
+	roleRepo repository.RoleRepository,
+	authorizer *Authorizer,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *ResetRoleUseCase {
+	return &ResetRoleUseCase{
+		roleRepo:   roleRepo,
+		authorizer: authorizer,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute forgets the permissions the clinic gave a role, so it has its
+// defaults again; a role that already has them is left as it is. Like
+// UpdateRoleUseCase, callers other than admins must have every default
+func (uc *ResetRoleUseCase) Execute(ctx context.Context, callerRole, role string) (*RoleResponse, error) {
+	var after *domain.RoleDefinition
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		before, stored, err := uc.authorizer.definition(ctx, domain.UserRole(role))
+		if err != nil {
+			return err
+		}
+		if !stored {
+			after = before
+			return nil
+		}
+		if err := uc.authorizer.CheckGrant(ctx, callerRole, domain.DefaultPermissions(before.Role)); err != nil {
+			return err
+		}
+
+		if _, err := uc.roleRepo.Delete(ctx, before.Role); err != nil {
+			return err
+		}
+
+		if after, _, err = uc.authorizer.definition(ctx, before.Role); err != nil {
+			return err
+		}
+		return uc.recorder.Record(ctx, domain.AuditEntityRole, role, domain.AuditActionReset, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRoleResponse(after, false), nil
+}
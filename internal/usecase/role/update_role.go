@@ -0,0 +1,77 @@
+package role
+
+import (
+	"context"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// UpdateRoleUseCase handles the business logic for changing the permissions of a role
+type UpdateRoleUseCase struct {
+	roleRepo   repository.RoleRepository
+	authorizer *Authorizer
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewUpdateRoleUseCase creates a new instance of UpdateRoleUseCase
+func NewUpdateRoleUseCase(
+	roleRepo repository.RoleRepository,
+	authorizer *Authorizer,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *UpdateRoleUseCase {
+	return &UpdateRoleUseCase{
+		roleRepo:   roleRepo,
+		authorizer: authorizer,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute replaces the permissions a role has in the clinic
+// The change applies to the next request of every user with the role; the
+// admin role cannot be changed, and callers other than admins may only save
+// permissions they have themselves
+func (uc *UpdateRoleUseCase) Execute(ctx context.Context, callerRole, role string, req UpdateRoleRequest) (*RoleResponse, error) {
+	definition := &domain.RoleDefinition{
+		Role:        domain.UserRole(role),
+		Permissions: make([]domain.Permission, len(req.Permissions)),
+		UpdatedAt:   time.Now(),
+	}
+	for i, permission := range req.Permissions {
+		definition.Permissions[i] = domain.Permission(permission)
+	}
+	if !domain.IsValidRole(role) {
+		return nil, errRoleNotFound
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+	domain.SortPermissions(definition.Permissions)
+
+	if err := uc.authorizer.CheckGrant(ctx, callerRole, definition.Permissions); err != nil {
+		return nil, err
+	}
+
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		before, _, err := uc.authorizer.definition(ctx, definition.Role)
+		if err != nil {
+			return err
+		}
+
+		if err := uc.roleRepo.Save(ctx, definition); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityRole, role, domain.AuditActionUpdate, before, definition)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toRoleResponse(definition, true), nil
+}
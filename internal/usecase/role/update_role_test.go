@@ -0,0 +1,57 @@
+package role_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
+)
+
+func TestUpdateRoleOnlyGrantsHeldPermissions(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	roleRepo := memory.NewMemoryRoleRepository(store)
+	authorizer := role.NewAuthorizer(roleRepo)
+	uc := role.NewUpdateRoleUseCase(roleRepo, authorizer, memory.NewMemoryTxManager(store), audit.NewRecorder(memory.NewMemoryAuditRepository(store)))
+
+	// Reception manages the roles of the clinic without being admin
+	receptionist := append(domain.DefaultPermissions(domain.RoleReceptionist), domain.PermissionRolesManage)
+	domain.SortPermissions(receptionist)
+	if err := roleRepo.Save(ctx, &domain.RoleDefinition{Role: domain.RoleReceptionist, Permissions: receptionist, UpdatedAt: time.Now()}); err != nil {
+		t.Fatalf("save receptionist: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		callerRole  domain.UserRole
+		role        domain.UserRole
+		permissions []domain.Permission
+		wantErr     string
+	}{
+		{"held permissions", domain.RoleReceptionist, domain.RoleNurse, []domain.Permission{domain.PermissionPatientsRead}, ""},
+		{"permission of another role", domain.RoleReceptionist, domain.RoleNurse, []domain.Permission{domain.PermissionHistoryReadAny}, "permission not held: history:read:any"},
+		{"raising its own role", domain.RoleReceptionist, domain.RoleReceptionist, append(slices.Clone(receptionist), domain.PermissionUsersWrite), "permission not held: users:write"},
+		{"admin", domain.RoleAdmin, domain.RoleNurse, []domain.Permission{domain.PermissionAuditRead, domain.PermissionUsersWrite}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := role.UpdateRoleRequest{}
+			for _, permission := range tt.permissions {
+				req.Permissions = append(req.Permissions, string(permission))
+			}
+
+			_, err := uc.Execute(ctx, string(tt.callerRole), string(tt.role), req)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("Execute error = %v", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Fatalf("Execute error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
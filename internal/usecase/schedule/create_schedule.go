@@ -8,29 +8,41 @@ import (
 	"github.com/google/uuid"
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // CreateScheduleUseCase handles creating doctor schedules
 type CreateScheduleUseCase struct {
 	scheduleRepo repository.ScheduleRepository
 	userRepo     repository.UserRepository
+	clinicRepo   repository.ClinicRepository
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
 }
 
 // NewCreateScheduleUseCase creates a new instance
 func NewCreateScheduleUseCase(
 	scheduleRepo repository.ScheduleRepository,
 	userRepo repository.UserRepository,
+	clinicRepo repository.ClinicRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
 ) *CreateScheduleUseCase {
 	return &CreateScheduleUseCase{
 		scheduleRepo: scheduleRepo,
 		userRepo:     userRepo,
+		clinicRepo:   clinicRepo,
+		txManager:    txManager,
+		recorder:     recorder,
 	}
 }
 
-// Execute creates a new schedule for a doctor
+// Execute creates a new schedule for a doctor at one of their locations
+// locationID may be empty when the doctor works at a single location
 func (uc *CreateScheduleUseCase) Execute(
 	ctx context.Context,
 	userID string,
+	locationID string,
 	dayOfWeek string,
 	startTime string,
 	endTime string,
@@ -54,11 +66,17 @@ func (uc *CreateScheduleUseCase) Execute(
 		return nil, err
 	}
 
+	locationID, err = resolveLocation(ctx, uc.clinicRepo, doctorID, locationID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create schedule entity
 	now := time.Now()
 	schedule := &domain.Schedule{
 		ID:           uuid.New().String(),
 		DoctorID:     doctorID,
+		LocationID:   locationID,
 		DayOfWeek:    dayOfWeek,
 		StartTime:    startTime,
 		EndTime:      endTime,
@@ -73,7 +91,8 @@ func (uc *CreateScheduleUseCase) Execute(
 		return nil, err
 	}
 
-	// Check for overlapping schedules
+	// Check for overlapping schedules, at any location since a doctor
+	// cannot be in two places at once
 	existing, err := uc.scheduleRepo.FindByDoctorAndDay(ctx, doctorID, dayOfWeek)
 	if err != nil {
 		return nil, err
@@ -85,8 +104,15 @@ func (uc *CreateScheduleUseCase) Execute(
 		}
 	}
 
-	// Save
-	if err := uc.scheduleRepo.Create(ctx, schedule); err != nil {
+	// Save together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.scheduleRepo.Create(ctx, schedule); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntitySchedule, schedule.ID, domain.AuditActionCreate, nil, schedule)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -4,20 +4,28 @@ import (
 	"context"
 	"errors"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // DeleteScheduleUseCase handles deleting schedules
 type DeleteScheduleUseCase struct {
 	scheduleRepo repository.ScheduleRepository
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
 }
 
 // NewDeleteScheduleUseCase creates a new instance
 func NewDeleteScheduleUseCase(
 	scheduleRepo repository.ScheduleRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
 ) *DeleteScheduleUseCase {
 	return &DeleteScheduleUseCase{
 		scheduleRepo: scheduleRepo,
+		txManager:    txManager,
+		recorder:     recorder,
 	}
 }
 
@@ -32,6 +40,12 @@ func (uc *DeleteScheduleUseCase) Execute(ctx context.Context, scheduleID string)
 		return errors.New("schedule not found")
 	}
 
-	// Delete
-	return uc.scheduleRepo.Delete(ctx, scheduleID)
+	// Delete together with its audit entry
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.scheduleRepo.Delete(ctx, scheduleID); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntitySchedule, scheduleID, domain.AuditActionDelete, schedule, nil)
+	})
 }
@@ -6,8 +6,23 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/pagination"
 )
 
+// scheduleSorts are the orders schedules can be listed in
+var scheduleSorts = pagination.Sorts{
+	repository.SortDay:       false,
+	repository.SortCreatedAt: true,
+}
+
+// DoctorSchedulesResponse represents one page of a doctor's schedules
+// Sort accepts day (default, monday first then by start time) and created_at
+// NextCursor is empty on the last page
+type DoctorSchedulesResponse struct {
+	Schedules  []*domain.Schedule `json:"schedules"`
+	NextCursor string             `json:"next_cursor"`
+}
+
 // GetDoctorSchedulesUseCase handles retrieving doctor schedules
 type GetDoctorSchedulesUseCase struct {
 	scheduleRepo repository.ScheduleRepository
@@ -25,8 +40,13 @@ func NewGetDoctorSchedulesUseCase(
 	}
 }
 
-// Execute retrieves all schedules for a doctor
-func (uc *GetDoctorSchedulesUseCase) Execute(ctx context.Context, userID string) ([]*domain.Schedule, error) {
+// Execute retrieves one page of active schedules for a doctor
+func (uc *GetDoctorSchedulesUseCase) Execute(ctx context.Context, userID string, req pagination.Request) (*DoctorSchedulesResponse, error) {
+	page, err := pagination.Resolve(req, scheduleSorts, repository.SortDay)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate doctor exists
 	doctor, err := uc.userRepo.FindByID(ctx, userID)
 	if err != nil {
@@ -45,11 +65,18 @@ func (uc *GetDoctorSchedulesUseCase) Execute(ctx context.Context, userID string)
 		return nil, err
 	}
 
-	// Get all schedules
-	schedules, err := uc.scheduleRepo.FindByDoctor(ctx, doctorID)
+	// Get one page of schedules
+	schedules, err := uc.scheduleRepo.FindByDoctor(ctx, doctorID, pagination.Lookahead(page))
 	if err != nil {
 		return nil, err
 	}
 
-	return schedules, nil
+	schedules, nextCursor := pagination.Finish(page, schedules, func(s *domain.Schedule) repository.Cursor {
+		return repository.Cursor{Key: repository.ScheduleSortKey(s, page.Sort), ID: s.ID}
+	})
+
+	return &DoctorSchedulesResponse{
+		Schedules:  schedules,
+		NextCursor: nextCursor,
+	}, nil
 }
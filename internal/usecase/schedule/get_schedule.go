@@ -0,0 +1,34 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// GetScheduleUseCase handles retrieving a single schedule
+type GetScheduleUseCase struct {
+	scheduleRepo repository.ScheduleRepository
+}
+
+// NewGetScheduleUseCase creates a new instance
+func NewGetScheduleUseCase(scheduleRepo repository.ScheduleRepository) *GetScheduleUseCase {
+	return &GetScheduleUseCase{
+		scheduleRepo: scheduleRepo,
+	}
+}
+
+// Execute retrieves a schedule by ID, active or not
+func (uc *GetScheduleUseCase) Execute(ctx context.Context, scheduleID string) (*domain.Schedule, error) {
+	schedule, err := uc.scheduleRepo.FindByID(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, errors.New("schedule not found")
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/repository"
+)
+
+// resolveLocation picks the location a doctor's schedule is placed at
+// An empty locationID means the doctor's only active location; doctors working
+// at several locations must name one, and it must be one they work at
+func resolveLocation(ctx context.Context, clinicRepo repository.ClinicRepository, doctorID, locationID string) (string, error) {
+	locations, err := clinicRepo.FindLocationsByDoctor(ctx, doctorID)
+	if err != nil {
+		return "", err
+	}
+
+	if locationID == "" {
+		active := locations[:0]
+		for _, location := range locations {
+			if location.IsActive {
+				active = append(active, location)
+			}
+		}
+		switch len(active) {
+		case 0:
+			return "", errors.New("doctor does not work at any location")
+		case 1:
+			return active[0].ID, nil
+		default:
+			return "", errors.New("location_id is required when the doctor works at several locations")
+		}
+	}
+
+	for _, location := range locations {
+		if location.ID != locationID {
+			continue
+		}
+		if !location.IsActive {
+			return "", errors.New("location is not active")
+		}
+		return location.ID, nil
+	}
+
+	return "", errors.New("doctor does not work at this location")
+}
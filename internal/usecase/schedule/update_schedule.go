@@ -7,35 +7,49 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // UpdateScheduleUseCase handles updating doctor schedules
 type UpdateScheduleUseCase struct {
 	scheduleRepo repository.ScheduleRepository
 	userRepo     repository.UserRepository
+	clinicRepo   repository.ClinicRepository
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
 }
 
 // NewUpdateScheduleUseCase creates a new instance
 func NewUpdateScheduleUseCase(
 	scheduleRepo repository.ScheduleRepository,
 	userRepo repository.UserRepository,
+	clinicRepo repository.ClinicRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
 ) *UpdateScheduleUseCase {
 	return &UpdateScheduleUseCase{
 		scheduleRepo: scheduleRepo,
 		userRepo:     userRepo,
+		clinicRepo:   clinicRepo,
+		txManager:    txManager,
+		recorder:     recorder,
 	}
 }
 
 // Execute updates an existing schedule
+// An empty locationID keeps the schedule at its current location
+// expectedVersion is the version the client last saw, zero skips the check
 func (uc *UpdateScheduleUseCase) Execute(
 	ctx context.Context,
 	scheduleID string,
 	userID string,
+	locationID string,
 	dayOfWeek string,
 	startTime string,
 	endTime string,
 	slotDuration int,
 	isActive bool,
+	expectedVersion int,
 ) (*domain.Schedule, error) {
 	// Validate doctor exists
 	doctor, err := uc.userRepo.FindByID(ctx, userID)
@@ -69,7 +83,24 @@ func (uc *UpdateScheduleUseCase) Execute(
 		return nil, errors.New("schedule does not belong to this doctor")
 	}
 
+	// Refuse to act on a stale copy when the client sent the version it saw
+	if err := domain.CheckVersion(existingSchedule.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	// Moving the schedule requires the doctor to work at the new location
+	if locationID != "" && locationID != existingSchedule.LocationID {
+		locationID, err = resolveLocation(ctx, uc.clinicRepo, doctorID, locationID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		locationID = existingSchedule.LocationID
+	}
+
 	// Update fields
+	before := *existingSchedule
+	existingSchedule.LocationID = locationID
 	existingSchedule.DayOfWeek = dayOfWeek
 	existingSchedule.StartTime = startTime
 	existingSchedule.EndTime = endTime
@@ -94,8 +125,15 @@ func (uc *UpdateScheduleUseCase) Execute(
 		}
 	}
 
-	// Save
-	if err := uc.scheduleRepo.Update(ctx, existingSchedule); err != nil {
+	// Save together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.scheduleRepo.Update(ctx, existingSchedule); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntitySchedule, existingSchedule.ID, domain.AuditActionUpdate, &before, existingSchedule)
+	})
+	if err != nil {
 		return nil, err
 	}
 
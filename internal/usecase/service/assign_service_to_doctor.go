@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // AssignServiceToDoctorUseCase handles assigning a service to a doctor
@@ -15,6 +16,8 @@ type AssignServiceToDoctorUseCase struct {
 	doctorServiceRepo repository.DoctorServiceRepository
 	serviceRepo       repository.ServiceRepository
 	userRepo          repository.UserRepository
+	txManager         repository.TxManager
+	recorder          *audit.Recorder
 }
 
 // NewAssignServiceToDoctorUseCase creates a new instance
@@ -22,11 +25,15 @@ func NewAssignServiceToDoctorUseCase(
 	doctorServiceRepo repository.DoctorServiceRepository,
 	serviceRepo repository.ServiceRepository,
 	userRepo repository.UserRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
 ) *AssignServiceToDoctorUseCase {
 	return &AssignServiceToDoctorUseCase{
 		doctorServiceRepo: doctorServiceRepo,
 		serviceRepo:       serviceRepo,
 		userRepo:          userRepo,
+		txManager:         txManager,
+		recorder:          recorder,
 	}
 }
 
@@ -105,6 +112,12 @@ func (uc *AssignServiceToDoctorUseCase) Execute(ctx context.Context, userID, ser
 		return err
 	}
 
-	// Save to repository
-	return uc.doctorServiceRepo.Assign(ctx, doctorService)
+	// Save to repository together with its audit entry
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.doctorServiceRepo.Assign(ctx, doctorService); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityDoctorService, doctorService.ID, domain.AuditActionAssign, nil, doctorService)
+	})
 }
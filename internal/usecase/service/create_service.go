@@ -8,17 +8,22 @@ import (
 	"github.com/google/uuid"
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // CreateServiceUseCase handles the creation of a new service
 type CreateServiceUseCase struct {
 	serviceRepo repository.ServiceRepository
+	txManager   repository.TxManager
+	recorder    *audit.Recorder
 }
 
 // NewCreateServiceUseCase creates a new instance of CreateServiceUseCase
-func NewCreateServiceUseCase(serviceRepo repository.ServiceRepository) *CreateServiceUseCase {
+func NewCreateServiceUseCase(serviceRepo repository.ServiceRepository, txManager repository.TxManager, recorder *audit.Recorder) *CreateServiceUseCase {
 	return &CreateServiceUseCase{
 		serviceRepo: serviceRepo,
+		txManager:   txManager,
+		recorder:    recorder,
 	}
 }
 
@@ -55,8 +60,15 @@ func (uc *CreateServiceUseCase) Execute(ctx context.Context, req CreateServiceRe
 		return nil, err
 	}
 
-	// Save to repository
-	if err := uc.serviceRepo.Create(ctx, service); err != nil {
+	// Save to repository together with its audit entry
+	err := uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.serviceRepo.Create(ctx, service); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityService, service.ID, domain.AuditActionCreate, nil, service)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -5,23 +5,31 @@ import (
 	"errors"
 	"strings"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // DeleteServiceUseCase handles business logic for deleting a service
 type DeleteServiceUseCase struct {
 	serviceRepo       repository.ServiceRepository
 	doctorServiceRepo repository.DoctorServiceRepository
+	txManager         repository.TxManager
+	recorder          *audit.Recorder
 }
 
 // NewDeleteServiceUseCase creates a new instance
 func NewDeleteServiceUseCase(
 	serviceRepo repository.ServiceRepository,
 	doctorServiceRepo repository.DoctorServiceRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
 ) *DeleteServiceUseCase {
 	return &DeleteServiceUseCase{
 		serviceRepo:       serviceRepo,
 		doctorServiceRepo: doctorServiceRepo,
+		txManager:         txManager,
+		recorder:          recorder,
 	}
 }
 
@@ -49,10 +57,12 @@ func (uc *DeleteServiceUseCase) Execute(ctx context.Context, serviceID string) e
 		return errors.New("cannot delete service with assigned doctors")
 	}
 
-	// Delete service
-	if err := uc.serviceRepo.Delete(ctx, serviceID); err != nil {
-		return errors.New("failed to delete service")
-	}
+	// Delete service together with its audit entry
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.serviceRepo.Delete(ctx, serviceID); err != nil {
+			return errors.New("failed to delete service")
+		}
 
-	return nil
+		return uc.recorder.Record(ctx, domain.AuditEntityService, serviceID, domain.AuditActionDelete, service, nil)
+	})
 }
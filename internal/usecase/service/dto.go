@@ -1,6 +1,10 @@
 package service
 
-import "time"
+import (
+	"time"
+
+	"version-1-0/internal/usecase/pagination"
+)
 
 // CreateServiceRequest represents the input data for creating a new service
 type CreateServiceRequest struct {
@@ -33,14 +37,16 @@ type UpdateServiceRequest struct {
 
 // ServiceResponse represents a service in responses
 type ServiceResponse struct {
-	ID              string    `json:"id"`
-	Name            string    `json:"name"`
-	Description     string    `json:"description"`
-	DurationMinutes int       `json:"duration_minutes"`
-	Price           float64   `json:"price"`
-	IsActive        bool      `json:"is_active"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	DurationMinutes int        `json:"duration_minutes"`
+	Price           float64    `json:"price"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	Version         int        `json:"version"`
 }
 
 // AssignServiceRequest represents the input for assigning a service to a doctor
@@ -56,3 +62,18 @@ type DoctorWithServicesResponse struct {
 	Email      string            `json:"email"`
 	Services   []ServiceResponse `json:"services"`
 }
+
+// ListServicesRequest represents the input for listing active services
+// Sort accepts name (default) and created_at
+// With a LocationID, prices are the ones charged at that location
+type ListServicesRequest struct {
+	pagination.Request
+	LocationID string
+}
+
+// ListServicesResponse represents one page of services
+// NextCursor is empty on the last page
+type ListServicesResponse struct {
+	Services   []ServiceResponse `json:"services"`
+	NextCursor string            `json:"next_cursor"`
+}
@@ -13,6 +13,7 @@ import (
 type TimeSlot struct {
 	Time      string `json:"time"`       // HH:MM format
 	Available bool   `json:"available"`
+	LocationID string `json:"location_id"` // Location of the schedule block the slot belongs to
 }
 
 // GetAvailableSlotsUseCase calculates available time slots for a doctor-service combination
@@ -39,7 +40,8 @@ func NewGetAvailableSlotsUseCase(
 }
 
 // Execute calculates available slots for a doctor offering a service on a specific date
-func (uc *GetAvailableSlotsUseCase) Execute(ctx context.Context, userID, serviceID string, date time.Time) ([]TimeSlot, error) {
+// A non-empty locationID keeps only the slots of schedules at that location
+func (uc *GetAvailableSlotsUseCase) Execute(ctx context.Context, userID, serviceID, locationID string, date time.Time) ([]TimeSlot, error) {
 	// Validate inputs
 	if userID == "" {
 		return nil, errors.New("doctor ID is required")
@@ -87,6 +89,10 @@ func (uc *GetAvailableSlotsUseCase) Execute(ctx context.Context, userID, service
 	// Generate slots for each schedule block
 	var allSlots []TimeSlot
 	for _, sched := range schedules {
+		if locationID != "" && sched.LocationID != locationID {
+			continue
+		}
+
 		// Parse start and end times
 		start, _ := time.Parse("15:04", sched.StartTime)
 		end, _ := time.Parse("15:04", sched.EndTime)
@@ -101,10 +107,16 @@ func (uc *GetAvailableSlotsUseCase) Execute(ctx context.Context, userID, service
 
 		// Generate slots for this schedule block
 		slots := generateTimeSlotsFromMinutes(startMinutes, endMinutes, service.DurationMinutes)
+		for i := range slots {
+			slots[i].LocationID = sched.LocationID
+		}
 		allSlots = append(allSlots, slots...)
 	}
 
 	slots := allSlots
+	if slots == nil {
+		slots = []TimeSlot{}
+	}
 
 	// Get existing appointments for this doctor on this date
 	startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
@@ -60,6 +60,7 @@ func (uc *GetDoctorsByServiceUseCase) Execute(ctx context.Context, serviceID str
 			Role:      string(doctor.Role),
 			IsActive:  doctor.IsActive,
 			CreatedAt: doctor.CreatedAt,
+			Version:   doctor.Version,
 		}
 	}
 
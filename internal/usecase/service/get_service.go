@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/repository"
+)
+
+// GetServiceUseCase handles retrieving a single service
+type GetServiceUseCase struct {
+	serviceRepo repository.ServiceRepository
+}
+
+// NewGetServiceUseCase creates a new instance
+func NewGetServiceUseCase(serviceRepo repository.ServiceRepository) *GetServiceUseCase {
+	return &GetServiceUseCase{
+		serviceRepo: serviceRepo,
+	}
+}
+
+// Execute retrieves a service by ID, active or not, with its own price
+func (uc *GetServiceUseCase) Execute(ctx context.Context, serviceID string) (*ServiceResponse, error) {
+	// Validate service ID
+	if strings.TrimSpace(serviceID) == "" {
+		return nil, errors.New("service ID is required")
+	}
+
+	svc, err := uc.serviceRepo.FindByID(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if svc == nil {
+		return nil, errors.New("service not found")
+	}
+
+	return &ServiceResponse{
+		ID:              svc.ID,
+		Name:            svc.Name,
+		Description:     svc.Description,
+		DurationMinutes: svc.DurationMinutes,
+		Price:           svc.Price,
+		IsActive:        svc.IsActive,
+		CreatedAt:       svc.CreatedAt,
+		UpdatedAt:       svc.UpdatedAt,
+		Version:         svc.Version,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"version-1-0/internal/repository"
+)
+
+// ListDeletedServicesUseCase handles retrieving soft deleted services
+type ListDeletedServicesUseCase struct {
+	serviceRepo repository.ServiceRepository
+}
+
+// NewListDeletedServicesUseCase creates a new instance of ListDeletedServicesUseCase
+func NewListDeletedServicesUseCase(serviceRepo repository.ServiceRepository) *ListDeletedServicesUseCase {
+	return &ListDeletedServicesUseCase{
+		serviceRepo: serviceRepo,
+	}
+}
+
+// Execute retrieves every soft deleted service that has not been purged yet
+func (uc *ListDeletedServicesUseCase) Execute(ctx context.Context) ([]ServiceResponse, error) {
+	services, err := uc.serviceRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to response DTOs
+	responses := make([]ServiceResponse, len(services))
+	for i, svc := range services {
+		responses[i] = ServiceResponse{
+			ID:              svc.ID,
+			Name:            svc.Name,
+			Description:     svc.Description,
+			DurationMinutes: svc.DurationMinutes,
+			Price:           svc.Price,
+			IsActive:        svc.IsActive,
+			CreatedAt:       svc.CreatedAt,
+			UpdatedAt:       svc.UpdatedAt,
+			Version:         svc.Version,
+			DeletedAt:       svc.DeletedAt,
+		}
+	}
+
+	return responses, nil
+}
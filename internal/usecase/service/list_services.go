@@ -2,44 +2,92 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/pagination"
 )
 
+// serviceSorts are the orders services can be listed in
+var serviceSorts = pagination.Sorts{
+	repository.SortName:      false,
+	repository.SortCreatedAt: true,
+}
+
 // ListServicesUseCase handles retrieving all active services
 type ListServicesUseCase struct {
 	serviceRepo repository.ServiceRepository
+	clinicRepo  repository.ClinicRepository
 }
 
 // NewListServicesUseCase creates a new instance of ListServicesUseCase
-func NewListServicesUseCase(serviceRepo repository.ServiceRepository) *ListServicesUseCase {
+func NewListServicesUseCase(serviceRepo repository.ServiceRepository, clinicRepo repository.ClinicRepository) *ListServicesUseCase {
 	return &ListServicesUseCase{
 		serviceRepo: serviceRepo,
+		clinicRepo:  clinicRepo,
 	}
 }
 
-// Execute retrieves all active services
-func (uc *ListServicesUseCase) Execute(ctx context.Context) ([]ServiceResponse, error) {
+// Execute retrieves one page of active services
+func (uc *ListServicesUseCase) Execute(ctx context.Context, req ListServicesRequest) (*ListServicesResponse, error) {
+	page, err := pagination.Resolve(req.Request, serviceSorts, repository.SortName)
+	if err != nil {
+		return nil, err
+	}
+
 	// Retrieve services from repository
-	services, err := uc.serviceRepo.ListActive(ctx)
+	services, err := uc.serviceRepo.ListActive(ctx, pagination.Lookahead(page))
 	if err != nil {
 		return nil, err
 	}
 
+	services, nextCursor := pagination.Finish(page, services, func(s *domain.Service) repository.Cursor {
+		return repository.Cursor{Key: repository.ServiceSortKey(s, page.Sort), ID: s.ID}
+	})
+
+	// Location overrides replace the service's own price
+	prices := make(map[string]float64)
+	if req.LocationID != "" {
+		location, err := uc.clinicRepo.FindLocationByID(ctx, req.LocationID)
+		if err != nil {
+			return nil, err
+		}
+		if location == nil {
+			return nil, errors.New("location not found")
+		}
+
+		overrides, err := uc.clinicRepo.FindServicePrices(ctx, location.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, override := range overrides {
+			prices[override.ServiceID] = override.Price
+		}
+	}
+
 	// Convert to response DTOs
 	responses := make([]ServiceResponse, len(services))
 	for i, svc := range services {
+		price, ok := prices[svc.ID]
+		if !ok {
+			price = svc.Price
+		}
 		responses[i] = ServiceResponse{
 			ID:              svc.ID,
 			Name:            svc.Name,
 			Description:     svc.Description,
 			DurationMinutes: svc.DurationMinutes,
-			Price:           svc.Price,
+			Price:           price,
 			IsActive:        svc.IsActive,
 			CreatedAt:       svc.CreatedAt,
 			UpdatedAt:       svc.UpdatedAt,
+			Version:         svc.Version,
 		}
 	}
 
-	return responses, nil
+	return &ListServicesResponse{
+		Services:   responses,
+		NextCursor: nextCursor,
+	}, nil
 }
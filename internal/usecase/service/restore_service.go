@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// RestoreServiceUseCase handles business logic for restoring a soft deleted service
+type RestoreServiceUseCase struct {
+	serviceRepo repository.ServiceRepository
+	txManager   repository.TxManager
+	recorder    *audit.Recorder
+}
+
+// NewRestoreServiceUseCase creates a new instance
+func NewRestoreServiceUseCase(serviceRepo repository.ServiceRepository, txManager repository.TxManager, recorder *audit.Recorder) *RestoreServiceUseCase {
+	return &RestoreServiceUseCase{
+		serviceRepo: serviceRepo,
+		txManager:   txManager,
+		recorder:    recorder,
+	}
+}
+
+// Execute restores a soft deleted service by ID
+// Returns "service not found" if the service does not exist or is not deleted
+func (uc *RestoreServiceUseCase) Execute(ctx context.Context, serviceID string) error {
+	// Validate service ID
+	if strings.TrimSpace(serviceID) == "" {
+		return errors.New("service ID is required")
+	}
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.serviceRepo.Restore(ctx, serviceID); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityService, serviceID, domain.AuditActionRestore, nil, nil)
+	})
+}
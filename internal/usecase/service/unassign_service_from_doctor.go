@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // UnassignServiceFromDoctorUseCase handles removing a service from a doctor
@@ -13,6 +15,8 @@ type UnassignServiceFromDoctorUseCase struct {
 	doctorServiceRepo repository.DoctorServiceRepository
 	appointmentRepo   repository.AppointmentRepository
 	userRepo          repository.UserRepository
+	txManager         repository.TxManager
+	recorder          *audit.Recorder
 }
 
 // NewUnassignServiceFromDoctorUseCase creates a new instance
@@ -20,11 +24,15 @@ func NewUnassignServiceFromDoctorUseCase(
 	doctorServiceRepo repository.DoctorServiceRepository,
 	appointmentRepo repository.AppointmentRepository,
 	userRepo repository.UserRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
 ) *UnassignServiceFromDoctorUseCase {
 	return &UnassignServiceFromDoctorUseCase{
 		doctorServiceRepo: doctorServiceRepo,
 		appointmentRepo:   appointmentRepo,
 		userRepo:          userRepo,
+		txManager:         txManager,
+		recorder:          recorder,
 	}
 }
 
@@ -65,6 +73,12 @@ func (uc *UnassignServiceFromDoctorUseCase) Execute(ctx context.Context, userID,
 		return fmt.Errorf("no se puede desasignar el servicio porque el doctor tiene %d citas futuras programadas", futureAppointments)
 	}
 
-	// 3. Remove the assignment
-	return uc.doctorServiceRepo.Remove(ctx, doctorID, serviceID)
+	// 3. Remove the assignment together with its audit entry
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.doctorServiceRepo.Remove(ctx, doctorID, serviceID); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityDoctorService, assignment.ID, domain.AuditActionUnassign, assignment, nil)
+	})
 }
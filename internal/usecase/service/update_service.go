@@ -8,22 +8,28 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
 )
 
 // UpdateServiceUseCase handles business logic for updating a service
 type UpdateServiceUseCase struct {
 	serviceRepo repository.ServiceRepository
+	txManager   repository.TxManager
+	recorder    *audit.Recorder
 }
 
 // NewUpdateServiceUseCase creates a new instance
-func NewUpdateServiceUseCase(serviceRepo repository.ServiceRepository) *UpdateServiceUseCase {
+func NewUpdateServiceUseCase(serviceRepo repository.ServiceRepository, txManager repository.TxManager, recorder *audit.Recorder) *UpdateServiceUseCase {
 	return &UpdateServiceUseCase{
 		serviceRepo: serviceRepo,
+		txManager:   txManager,
+		recorder:    recorder,
 	}
 }
 
 // Execute updates a service by ID
-func (uc *UpdateServiceUseCase) Execute(ctx context.Context, serviceID string, req UpdateServiceRequest) (*domain.Service, error) {
+// expectedVersion is the version the client last saw, zero skips the check
+func (uc *UpdateServiceUseCase) Execute(ctx context.Context, serviceID string, req UpdateServiceRequest, expectedVersion int) (*domain.Service, error) {
 	// Validate service ID
 	if strings.TrimSpace(serviceID) == "" {
 		return nil, errors.New("service ID is required")
@@ -35,6 +41,13 @@ func (uc *UpdateServiceUseCase) Execute(ctx context.Context, serviceID string, r
 		return nil, errors.New("service not found")
 	}
 
+	// Refuse to act on a stale copy when the client sent the version it saw
+	if err := domain.CheckVersion(service.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+
+	before := *service
+
 	// Update fields if provided
 	if req.Name != nil && strings.TrimSpace(*req.Name) != "" {
 		service.Name = *req.Name
@@ -59,9 +72,19 @@ func (uc *UpdateServiceUseCase) Execute(ctx context.Context, serviceID string, r
 	// Update timestamp
 	service.UpdatedAt = time.Now()
 
-	// Update in database
-	if err := uc.serviceRepo.Update(ctx, service); err != nil {
-		return nil, errors.New("failed to update service")
+	// Update in database together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.serviceRepo.Update(ctx, service); err != nil {
+			if errors.Is(err, domain.ErrVersionConflict) {
+				return err
+			}
+			return errors.New("failed to update service")
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityService, service.ID, domain.AuditActionUpdate, &before, service)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return service, nil
@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
+)
+
+// errInvalidInvitation covers unknown, accepted, revoked and expired
+// invitations alike, so the token tells nothing about its invitation
+var errInvalidInvitation = errors.New("invalid or expired invitation")
+
+// AcceptInvitationUseCase handles the business logic for creating a staff account from an invitation
+type AcceptInvitationUseCase struct {
+	invitationRepo repository.InvitationRepository
+	createUser     *CreateUserUseCase
+	recorder       *audit.Recorder
+}
+
+// NewAcceptInvitationUseCase creates a new instance of AcceptInvitationUseCase
+func NewAcceptInvitationUseCase(
+	invitationRepo repository.InvitationRepository,
+	createUser *CreateUserUseCase,
+	recorder *audit.Recorder,
+) *AcceptInvitationUseCase {
+	return &AcceptInvitationUseCase{
+		invitationRepo: invitationRepo,
+		createUser:     createUser,
+		recorder:       recorder,
+	}
+}
+
+// Execute creates the account an invitation was sent for, with the email and
+// role of the invitation and the password chosen by the invitee
+// Each invitation works once; the account and the acceptance are stored together
+func (uc *AcceptInvitationUseCase) Execute(ctx context.Context, req AcceptInvitationRequest) (*CreateUserResponse, error) {
+	if strings.TrimSpace(req.Token) == "" {
+		return nil, errors.New("token is required")
+	}
+
+	invitation, err := uc.invitationRepo.FindByHash(ctx, auth.HashToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if invitation == nil || invitation.Status(now) != domain.InvitationPending {
+		return nil, errInvalidInvitation
+	}
+
+	createReq := CreateUserRequest{
+		Email:             invitation.Email,
+		Password:          req.Password,
+		FirstName:         req.FirstName,
+		LastName:          req.LastName,
+		Phone:             req.Phone,
+		Role:              string(invitation.Role),
+		Specialty:         req.Specialty,
+		ConsultationFee:   req.ConsultationFee,
+		YearsOfExperience: req.YearsOfExperience,
+		Education:         req.Education,
+		Bio:               req.Bio,
+		LocationID:        req.LocationID,
+	}
+
+	return uc.createUser.create(ctx, createReq, func(ctx context.Context, user *domain.User) error {
+		accepted, err := uc.invitationRepo.MarkAccepted(ctx, invitation.ID, user.ID, now)
+		if err != nil {
+			return err
+		}
+		if !accepted {
+			return errInvalidInvitation
+		}
+
+		before := *invitation
+		invitation.AcceptedAt = &now
+		invitation.UserID = user.ID
+		return uc.recorder.Record(ctx, domain.AuditEntityInvitation, invitation.ID, domain.AuditActionAccept, &before, invitation)
+	})
+}
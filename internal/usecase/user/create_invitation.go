@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
+	"version-1-0/pkg/email"
+)
+
+// CreateInvitationUseCase handles the business logic for inviting a staff member
+type CreateInvitationUseCase struct {
+	userRepo       repository.UserRepository
+	invitationRepo repository.InvitationRepository
+	clinicRepo     repository.ClinicRepository
+	emailService   *email.EmailService
+	txManager      repository.TxManager
+	recorder       *audit.Recorder
+	acceptURL      string
+	validFor       time.Duration
+}
+
+// NewCreateInvitationUseCase creates a new instance of CreateInvitationUseCase
+// frontendURL is the base URL of the web app, whose /accept-invitation page
+// receives the token and calls POST /api/invitations/accept
+func NewCreateInvitationUseCase(
+	userRepo repository.UserRepository,
+	invitationRepo repository.InvitationRepository,
+	clinicRepo repository.ClinicRepository,
+	emailService *email.EmailService,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	frontendURL string,
+	validDays int,
+) *CreateInvitationUseCase {
+	return &CreateInvitationUseCase{
+		userRepo:       userRepo,
+		invitationRepo: invitationRepo,
+		clinicRepo:     clinicRepo,
+		emailService:   emailService,
+		txManager:      txManager,
+		recorder:       recorder,
+		acceptURL:      frontendURL + "/accept-invitation",
+		validFor:       time.Duration(validDays) * 24 * time.Hour,
+	}
+}
+
+// Execute emails an invitation to join the clinic with a staff role
+// A new invitation to the same email replaces the open ones sent before
+func (uc *CreateInvitationUseCase) Execute(ctx context.Context, invitedBy string, req CreateInvitationRequest) (*InvitationResponse, error) {
+	emailAddress := strings.TrimSpace(req.Email)
+	if emailAddress == "" {
+		return nil, errors.New("email is required")
+	}
+	if !domain.UserRole(req.Role).IsStaff() {
+		return nil, errors.New("invalid role: invitations are for staff accounts")
+	}
+	// Only admins bring in other admins
+	inviterRole, _ := ctx.Value(requestctx.RoleKey).(string)
+	if !domain.UserRole(inviterRole).CanManage(domain.UserRole(req.Role)) {
+		return nil, errors.New("only administrators can invite administrators")
+	}
+
+	// An email used in any clinic could never accept the invitation
+	taken, err := uc.userRepo.EmailExists(ctx, emailAddress)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, domain.ErrEmailAlreadyExists
+	}
+
+	inviter, err := uc.userRepo.FindByID(ctx, invitedBy)
+	if err != nil {
+		return nil, err
+	}
+	if inviter == nil {
+		return nil, errors.New("user not found")
+	}
+
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	now := time.Now()
+	invitation := domain.Invitation{
+		ID:        uuid.New().String(),
+		Email:     emailAddress,
+		Role:      domain.UserRole(req.Role),
+		TokenHash: auth.HashToken(token),
+		InvitedBy: inviter.ID,
+		ExpiresAt: now.Add(uc.validFor),
+		CreatedAt: now,
+	}
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.invitationRepo.RevokeByEmail(ctx, emailAddress, now); err != nil {
+			return err
+		}
+		if err := uc.invitationRepo.Create(ctx, &invitation); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityInvitation, invitation.ID, domain.AuditActionCreate, nil, &invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if uc.emailService != nil {
+		var branch email.Branch
+		if clinic, _ := uc.clinicRepo.FindClinicByID(ctx, invitation.ClinicID); clinic != nil {
+			branch.Clinic = clinic.Name
+		}
+
+		// Failures are logged by the email service; the admin can invite again
+		go uc.emailService.SendInvitation(
+			branch,
+			invitation.Email,
+			inviter.FullName(),
+			roleLabel(invitation.Role),
+			uc.acceptURL+"?token="+url.QueryEscape(token),
+			fmt.Sprintf("%d días", int(uc.validFor.Hours()/24)),
+		)
+	}
+
+	return toInvitationResponse(&invitation, now), nil
+}
+
+// roleLabel names a staff role in the language of the emails
+func roleLabel(role domain.UserRole) string {
+	switch role {
+	case domain.RoleAdmin:
+		return "administrador"
+	case domain.RoleDoctor:
+		return "médico"
+	case domain.RoleNurse:
+		return "enfermería"
+	case domain.RoleReceptionist:
+		return "recepción"
+	default:
+		return string(role)
+	}
+}
+
+// toInvitationResponse converts an invitation to its response, with its status at the given time
+func toInvitationResponse(invitation *domain.Invitation, now time.Time) *InvitationResponse {
+	return &InvitationResponse{
+		ID:         invitation.ID,
+		Email:      invitation.Email,
+		Role:       string(invitation.Role),
+		Status:     invitation.Status(now),
+		InvitedBy:  invitation.InvitedBy,
+		UserID:     invitation.UserID,
+		ExpiresAt:  invitation.ExpiresAt,
+		CreatedAt:  invitation.CreatedAt,
+		AcceptedAt: invitation.AcceptedAt,
+		RevokedAt:  invitation.RevokedAt,
+	}
+}
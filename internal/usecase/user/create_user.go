@@ -11,14 +11,19 @@ import (
 
 	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
-	"version-1-0/internal/repository/sqlite"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/auth"
 )
 
 // CreateUserUseCase handles the business logic for creating a new user
 type CreateUserUseCase struct {
-	userRepo    repository.UserRepository
-	doctorRepo  repository.DoctorRepository
-	patientRepo repository.PatientRepository
+	userRepo     repository.UserRepository
+	doctorRepo   repository.DoctorRepository
+	patientRepo  repository.PatientRepository
+	clinicRepo   repository.ClinicRepository
+	txManager    repository.TxManager
+	recorder     *audit.Recorder
+	verification *auth.EmailVerification
 }
 
 // NewCreateUserUseCase creates a new instance of CreateUserUseCase
@@ -26,17 +31,36 @@ func NewCreateUserUseCase(
 	userRepo repository.UserRepository,
 	doctorRepo repository.DoctorRepository,
 	patientRepo repository.PatientRepository,
+	clinicRepo repository.ClinicRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	verification *auth.EmailVerification,
 ) *CreateUserUseCase {
 	return &CreateUserUseCase{
-		userRepo:    userRepo,
-		doctorRepo:  doctorRepo,
-		patientRepo: patientRepo,
+		userRepo:     userRepo,
+		doctorRepo:   doctorRepo,
+		patientRepo:  patientRepo,
+		clinicRepo:   clinicRepo,
+		txManager:    txManager,
+		recorder:     recorder,
+		verification: verification,
 	}
 }
 
 // Execute creates a new user with the provided data
+// The account starts with an unverified email and is sent a verification
+// link; without an EmailVerification no link is sent
+// Any role is accepted: the public signup endpoint goes through
+// RegisterPatientUseCase and staff join through invitations
 // Returns the created user information or an error if validation or creation fails
 func (uc *CreateUserUseCase) Execute(ctx context.Context, req CreateUserRequest) (*CreateUserResponse, error) {
+	return uc.create(ctx, req, nil)
+}
+
+// create stores a new user with its profile. Accounts created from an
+// invitation pass invited, which runs in the same unit of work once the user
+// is stored; their email counts as verified since the invitation reached it
+func (uc *CreateUserUseCase) create(ctx context.Context, req CreateUserRequest, invited func(ctx context.Context, user *domain.User) error) (*CreateUserResponse, error) {
 	// Validate email
 	if strings.TrimSpace(req.Email) == "" {
 		return nil, errors.New("email is required")
@@ -59,13 +83,16 @@ func (uc *CreateUserUseCase) Execute(ctx context.Context, req CreateUserRequest)
 
 	// Validate role
 	if !domain.IsValidRole(req.Role) {
-		return nil, errors.New("invalid role: must be admin, doctor, or patient")
+		return nil, errors.New("invalid role: must be admin, doctor, nurse, receptionist, or patient")
 	}
 
-	// Check if email already exists
-	existingUser, err := uc.userRepo.FindByEmail(ctx, req.Email)
-	if err == nil && existingUser != nil {
-		return nil, errors.New("email already exists")
+	// Emails are unique across clinics, so the check ignores the clinic scope
+	taken, err := uc.userRepo.EmailExists(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, domain.ErrEmailAlreadyExists
 	}
 
 	// Hash the password
@@ -89,157 +116,181 @@ func (uc *CreateUserUseCase) Execute(ctx context.Context, req CreateUserRequest)
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
+	if invited != nil {
+		user.EmailVerifiedAt = &now
+	}
 
 	// Validate the user entity
 	if err := user.Validate(); err != nil {
 		return nil, err
 	}
 
-	// If user is admin, use simple creation without transaction
-	if user.Role == domain.RoleAdmin {
-		if err := uc.userRepo.Create(ctx, &user); err != nil {
-			return nil, errors.New("failed to create user")
-		}
-	} else if user.Role == domain.RoleDoctor {
-		// For doctors, use transaction to ensure atomicity
-		// Get DB connection from repository (cast to concrete type)
-		userRepoImpl, ok := uc.userRepo.(*sqlite.SqliteUserRepository)
-		if !ok {
-			return nil, errors.New("failed to get database connection")
-		}
-
-		db := userRepoImpl.GetDB()
-
-		// Begin transaction
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return nil, errors.New("failed to begin transaction")
-		}
-
-		// Ensure rollback on error
-		defer func() {
-			if err != nil {
-				tx.Rollback()
-			}
-		}()
-
-		// Create user within transaction
-		if err = userRepoImpl.CreateWithTx(ctx, tx, &user); err != nil {
-			return nil, errors.New("failed to create user")
-		}
+	// Build the role-specific profile created together with the user
+	var doctor *domain.Doctor
+	var doctorLocation *domain.DoctorLocation
+	var patient *domain.Patient
 
+	switch user.Role {
+	case domain.RoleDoctor:
 		// Generate unique license number using last 6 characters of user ID
 		licenseNumber := "LIC-" + userID[len(userID)-6:]
 
-		doctor := domain.Doctor{
-			ID:                 uuid.New().String(),
-			UserID:             userID,
-			Specialty:          "Medicina General", // Default specialty
-			LicenseNumber:      licenseNumber,
-			YearsOfExperience:  0,
-			Education:          "",
-			Bio:                "",
-			ConsultationFee:    0.0,
-			IsAvailable:        true,
-			CreatedAt:          now,
-			UpdatedAt:          now,
-		}
-
-		// Validate doctor profile
-		if err = doctor.Validate(); err != nil {
-			return nil, errors.New("failed to validate doctor profile: " + err.Error())
-		}
-
-		// Create doctor profile within same transaction
-		doctorRepoImpl, ok := uc.doctorRepo.(*sqlite.SqliteDoctorRepository)
-		if !ok {
-			return nil, errors.New("failed to get doctor repository")
+		specialty := strings.TrimSpace(req.Specialty)
+		if specialty == "" {
+			specialty = "Medicina General" // Default specialty
 		}
 
-		if err = doctorRepoImpl.CreateWithTx(ctx, tx, &doctor); err != nil {
-			return nil, errors.New("failed to create doctor profile")
+		doctor = &domain.Doctor{
+			ID:                uuid.New().String(),
+			UserID:            userID,
+			Specialty:         specialty,
+			LicenseNumber:     licenseNumber,
+			YearsOfExperience: req.YearsOfExperience,
+			Education:         req.Education,
+			Bio:               req.Bio,
+			ConsultationFee:   req.ConsultationFee,
+			IsAvailable:       true,
+			CreatedAt:         now,
+			UpdatedAt:         now,
 		}
 
-		// Commit transaction - if this succeeds, both user and doctor are created atomically
-		if err = tx.Commit(); err != nil {
-			return nil, errors.New("failed to commit transaction")
-		}
-	} else if user.Role == domain.RolePatient {
-		// For patients, use transaction to ensure atomicity
-		userRepoImpl, ok := uc.userRepo.(*sqlite.SqliteUserRepository)
-		if !ok {
-			return nil, errors.New("failed to get database connection")
+		// Validate doctor profile
+		if err := doctor.Validate(); err != nil {
+			return nil, errors.New("failed to validate doctor profile: " + err.Error())
 		}
 
-		db := userRepoImpl.GetDB()
-
-		// Begin transaction
-		tx, err := db.BeginTx(ctx, nil)
+		// Every doctor works at one location at least, so schedules can be placed
+		locationID, err := uc.doctorLocation(ctx, req.LocationID)
 		if err != nil {
-			return nil, errors.New("failed to begin transaction")
+			return nil, err
 		}
-
-		// Ensure rollback on error
-		defer func() {
-			if err != nil {
-				tx.Rollback()
-			}
-		}()
-
-		// Create user within transaction
-		if err = userRepoImpl.CreateWithTx(ctx, tx, &user); err != nil {
-			return nil, errors.New("failed to create user")
+		doctorLocation = &domain.DoctorLocation{
+			DoctorID:   doctor.ID,
+			LocationID: locationID,
+			CreatedAt:  now,
 		}
-
+	case domain.RolePatient:
 		// Create default patient profile
 		// Use a reasonable default birthdate (18 years ago)
 		defaultBirthdate := time.Now().AddDate(-18, 0, 0)
 
-		patient := domain.Patient{
+		patient = &domain.Patient{
 			ID:                    uuid.New().String(),
 			UserID:                userID,
 			Birthdate:             defaultBirthdate,
-			DocumentType:          "DNI",        // Default document type
-			DocumentNumber:        "00000000",   // Placeholder - should be updated later
+			DocumentType:          "DNI",         // Default document type
+			DocumentNumber:        "00000000",    // Placeholder - should be updated later
 			Address:               "Por definir", // Placeholder
 			EmergencyContactName:  "Por definir", // Placeholder
-			EmergencyContactPhone: req.Phone,    // Use patient's phone as default
-			BloodType:             "",           // Optional
-			Allergies:             []string{},   // Empty by default
+			EmergencyContactPhone: req.Phone,     // Use patient's phone as default
+			BloodType:             "",            // Optional
+			Allergies:             []string{},    // Empty by default
 			CreatedAt:             now,
 			UpdatedAt:             now,
 		}
 
 		// Validate patient profile
-		if err = patient.Validate(); err != nil {
+		if err := patient.Validate(); err != nil {
 			return nil, errors.New("failed to validate patient profile: " + err.Error())
 		}
+	}
+
+	// Store the user and its profile in one unit of work so a failed
+	// profile insert never leaves a user without one
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Create(ctx, &user); err != nil {
+			return createUserError(err)
+		}
+
+		if doctor != nil {
+			if err := uc.doctorRepo.Create(ctx, doctor); err != nil {
+				return profileError(true, err)
+			}
+			if err := uc.clinicRepo.AssignDoctor(ctx, doctorLocation); err != nil {
+				return profileError(true, err)
+			}
+		}
 
-		// Create patient profile within same transaction
-		patientRepoImpl, ok := uc.patientRepo.(*sqlite.SqlitePatientRepository)
-		if !ok {
-			return nil, errors.New("failed to get patient repository")
+		if patient != nil {
+			if err := uc.patientRepo.Create(ctx, patient); err != nil {
+				return profileError(false, err)
+			}
 		}
 
-		if err = patientRepoImpl.CreateWithTx(ctx, tx, &patient); err != nil {
-			return nil, errors.New("failed to create patient profile")
+		if err := uc.recorder.Record(ctx, domain.AuditEntityUser, user.ID, domain.AuditActionCreate, nil, &user); err != nil {
+			return err
 		}
 
-		// Commit transaction - if this succeeds, both user and patient are created atomically
-		if err = tx.Commit(); err != nil {
-			return nil, errors.New("failed to commit transaction")
+		if invited != nil {
+			return invited(ctx, &user)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if invited == nil && uc.verification != nil {
+		uc.verification.SendNew(ctx, &user)
 	}
 
 	// Return response without password
 	response := &CreateUserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      string(user.Role),
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Role:          string(user.Role),
+		EmailVerified: user.IsEmailVerified(),
+		CreatedAt:     user.CreatedAt,
 	}
 
 	return response, nil
 }
+
+// doctorLocation returns the location a new doctor is attached to: the given
+// one, which must be active, or else the clinic's first active location
+func (uc *CreateUserUseCase) doctorLocation(ctx context.Context, locationID string) (string, error) {
+	if locationID != "" {
+		location, err := uc.clinicRepo.FindLocationByID(ctx, locationID)
+		if err != nil {
+			return "", err
+		}
+		if location == nil {
+			return "", errors.New("location not found")
+		}
+		if !location.IsActive {
+			return "", errors.New("location is not active")
+		}
+		return location.ID, nil
+	}
+
+	locations, err := uc.clinicRepo.ListLocations(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	if len(locations) == 0 {
+		return "", errors.New("clinic has no active location")
+	}
+	return locations[0].ID, nil
+}
+
+// createUserError hides storage details except for a duplicate email,
+// which can still happen if two requests race past the EmailExists check
+func createUserError(err error) error {
+	if errors.Is(err, domain.ErrEmailAlreadyExists) {
+		return err
+	}
+	return errors.New("failed to create user")
+}
+
+// profileError reports a failed doctor or patient profile insert
+func profileError(isDoctor bool, err error) error {
+	if errors.Is(err, domain.ErrEmailAlreadyExists) || errors.Is(err, domain.ErrLicenseNumberAlreadyExists) {
+		return err
+	}
+	if isDoctor {
+		return errors.New("failed to create doctor profile")
+	}
+	return errors.New("failed to create patient profile")
+}
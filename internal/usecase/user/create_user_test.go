@@ -0,0 +1,149 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/repository/memory"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/user"
+)
+
+// otherClinicID is a second tenant next to the default clinic
+const otherClinicID = "00000000-0000-0000-0000-000000000002"
+
+func newCreateUserUseCase(store *memory.Store) *user.CreateUserUseCase {
+	return user.NewCreateUserUseCase(
+		memory.NewMemoryUserRepository(store),
+		memory.NewMemoryDoctorRepository(store),
+		memory.NewMemoryPatientRepository(store),
+		memory.NewMemoryClinicRepository(store),
+		memory.NewMemoryTxManager(store),
+		audit.NewRecorder(memory.NewMemoryAuditRepository(store)),
+		nil,
+	)
+}
+
+func doctorRequest(email string) user.CreateUserRequest {
+	return user.CreateUserRequest{
+		Email:     email,
+		Password:  "password123",
+		FirstName: "María",
+		LastName:  "Salazar",
+		Role:      string(domain.RoleDoctor),
+	}
+}
+
+func patientRequest(email string) user.CreateUserRequest {
+	return user.CreateUserRequest{
+		Email:     email,
+		Password:  "password123",
+		FirstName: "Sofía",
+		LastName:  "García",
+		Phone:     "+51 999 888 777",
+		Role:      string(domain.RolePatient),
+	}
+}
+
+func TestCreateUserLinksDoctorProfile(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+
+	created, err := newCreateUserUseCase(store).Execute(ctx, doctorRequest("doctor@clinica.test"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	doctorID, err := memory.NewMemoryUserRepository(store).FindDoctorIDByUserID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("FindDoctorIDByUserID: %v", err)
+	}
+	doctor, err := memory.NewMemoryDoctorRepository(store).FindByID(ctx, doctorID)
+	if err != nil || doctor == nil {
+		t.Fatalf("find doctor %s: %v", doctorID, err)
+	}
+	if doctor.ID == created.ID || doctor.UserID != created.ID {
+		t.Errorf("doctor profile = {ID: %s, UserID: %s}, want its own ID linked to user %s", doctor.ID, doctor.UserID, created.ID)
+	}
+}
+
+func TestCreateUserRejectsDuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	uc := newCreateUserUseCase(store)
+
+	if _, err := uc.Execute(ctx, doctorRequest("doctor@clinica.test")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	_, err := uc.Execute(ctx, doctorRequest("doctor@clinica.test"))
+	if !errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Fatalf("duplicate create error = %v, want %v", err, domain.ErrEmailAlreadyExists)
+	}
+
+	// The rejected signup must not leave a second account or profile behind
+	doctors, err := memory.NewMemoryUserRepository(store).CountByRole(ctx, string(domain.RoleDoctor))
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if doctors != 1 {
+		t.Errorf("doctors = %d, want 1", doctors)
+	}
+}
+
+func TestCreateUserRejectsEmailUsedAnywhere(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	uc := newCreateUserUseCase(store)
+
+	existing, err := uc.Execute(ctx, patientRequest("sofia@clinica.test"))
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Emails are unique across the installation, like users.email in the SQL schemas
+	other := repository.WithClinic(ctx, otherClinicID)
+	if _, err := uc.Execute(other, patientRequest("sofia@clinica.test")); !errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Errorf("signup in another clinic error = %v, want %v", err, domain.ErrEmailAlreadyExists)
+	}
+
+	// Deleted accounts keep their email
+	if err := memory.NewMemoryUserRepository(store).Delete(ctx, existing.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := uc.Execute(ctx, patientRequest("sofia@clinica.test")); !errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Errorf("signup with a deleted account's email error = %v, want %v", err, domain.ErrEmailAlreadyExists)
+	}
+}
+
+func TestCreateInvitationRejectsEmailUsedAnywhere(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+
+	if _, err := newCreateUserUseCase(store).Execute(ctx, patientRequest("sofia@clinica.test")); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	invite := user.NewCreateInvitationUseCase(
+		memory.NewMemoryUserRepository(store),
+		memory.NewMemoryInvitationRepository(store),
+		memory.NewMemoryClinicRepository(store),
+		nil,
+		memory.NewMemoryTxManager(store),
+		audit.NewRecorder(memory.NewMemoryAuditRepository(store)),
+		"http://localhost:3000",
+		7,
+	)
+
+	// An admin of another clinic could never see the account, but the
+	// invitation could never be accepted either
+	adminCtx := context.WithValue(repository.WithClinic(ctx, otherClinicID), requestctx.RoleKey, string(domain.RoleAdmin))
+	_, err := invite.Execute(adminCtx, "admin-user", user.CreateInvitationRequest{Email: "sofia@clinica.test", Role: string(domain.RoleDoctor)})
+	if !errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Errorf("invitation error = %v, want %v", err, domain.ErrEmailAlreadyExists)
+	}
+}
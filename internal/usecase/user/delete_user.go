@@ -3,32 +3,59 @@ package user
 import (
 	"context"
 	"errors"
+	"time"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 )
 
-// DeleteUserUseCase handles the business logic for deleting (deactivating) users
+// DeleteUserUseCase handles the business logic for soft deleting users
 type DeleteUserUseCase struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	doctorRepo       repository.DoctorRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	txManager        repository.TxManager
+	recorder         *audit.Recorder
+	authorizer       *role.Authorizer
 }
 
 // NewDeleteUserUseCase creates a new instance of DeleteUserUseCase
-func NewDeleteUserUseCase(userRepo repository.UserRepository) *DeleteUserUseCase {
+func NewDeleteUserUseCase(
+	userRepo repository.UserRepository,
+	doctorRepo repository.DoctorRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+	authorizer *role.Authorizer,
+) *DeleteUserUseCase {
 	return &DeleteUserUseCase{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		doctorRepo:       doctorRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		txManager:        txManager,
+		recorder:         recorder,
+		authorizer:       authorizer,
 	}
 }
 
-// Execute performs a soft delete on a user (marks as inactive)
-// Only administrators can delete users
-// Admins cannot delete their own account to prevent lockout
+// Execute performs a soft delete on a user, together with their doctor profile if they have one
+// The user can be brought back with RestoreUserUseCase until the purge job removes it
+// Their sessions are revoked, so restoring the user does not bring those back
+// Only roles with users:write can delete users
+// Nobody can delete their own account to prevent lockout
 func (uc *DeleteUserUseCase) Execute(ctx context.Context, userID string, authenticatedUserID string, authenticatedUserRole string) error {
-	// Validate that only admins can delete users
-	if authenticatedUserRole != "admin" {
+	// Validate that only roles allowed to manage users can delete them
+	canWrite, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionUsersWrite)
+	if err != nil {
+		return err
+	}
+	if !canWrite {
 		return errors.New("only administrators can delete users")
 	}
 
-	// Prevent admins from deleting their own account (prevents lockout)
+	// Prevent users from deleting their own account (prevents lockout)
 	if userID == authenticatedUserID {
 		return errors.New("cannot delete your own account")
 	}
@@ -41,17 +68,33 @@ func (uc *DeleteUserUseCase) Execute(ctx context.Context, userID string, authent
 	if existingUser == nil {
 		return errors.New("user not found")
 	}
-
-	// Verify that the user is currently active
-	if !existingUser.IsActive {
-		return errors.New("user is already inactive")
+	if !domain.UserRole(authenticatedUserRole).CanManage(existingUser.Role) {
+		return errors.New("only administrators can delete users")
 	}
 
-	// Perform soft delete (marks user as inactive)
-	err = uc.userRepo.Delete(ctx, userID)
-	if err != nil {
-		return err
-	}
+	// Soft delete the user and their doctor profile atomically
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		doctor, err := uc.doctorRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if doctor != nil {
+			if err := uc.doctorRepo.Delete(ctx, doctor.ID); err != nil {
+				return err
+			}
+			if err := uc.recorder.Record(ctx, domain.AuditEntityDoctor, doctor.ID, domain.AuditActionDelete, doctor, nil); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.userRepo.Delete(ctx, userID); err != nil {
+			return err
+		}
+
+		if err := uc.refreshTokenRepo.RevokeUserSessions(ctx, userID, "", time.Now()); err != nil {
+			return err
+		}
 
-	return nil
+		return uc.recorder.Record(ctx, domain.AuditEntityUser, userID, domain.AuditActionDelete, existingUser, nil)
+	})
 }
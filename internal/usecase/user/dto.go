@@ -1,6 +1,10 @@
 package user
 
-import "time"
+import (
+	"time"
+
+	"version-1-0/internal/usecase/pagination"
+)
 
 // CreateUserRequest represents the input data for creating a new user
 type CreateUserRequest struct {
@@ -10,44 +14,57 @@ type CreateUserRequest struct {
 	LastName  string `json:"last_name" validate:"required"`
 	Phone     string `json:"phone" validate:"required"`
 	Role      string `json:"role" validate:"required"`
+
+	// Optional doctor profile, ignored for other roles
+	// Doctors created without it get "Medicina General" and no fee
+	Specialty         string  `json:"specialty,omitempty"`
+	ConsultationFee   float64 `json:"consultation_fee,omitempty"`
+	YearsOfExperience int     `json:"years_of_experience,omitempty"`
+	Education         string  `json:"education,omitempty"`
+	Bio               string  `json:"bio,omitempty"`
+	// Location the doctor works at; defaults to the clinic's first active location
+	LocationID string `json:"location_id,omitempty"`
 }
 
 // CreateUserResponse represents the output data after successfully creating a user
 // Password is intentionally excluded for security reasons
 type CreateUserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Email         string    `json:"email"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	Role          string    `json:"role"`
+	EmailVerified bool      `json:"email_verified"` // New accounts start unverified; a link is emailed to verify them
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // GetUserResponse represents the output data for getting a user by ID
 type GetUserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Phone     string    `json:"phone"`
-	Role      string    `json:"role"`
-	IsActive  bool      `json:"is_active"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	Phone         string     `json:"phone"`
+	Role          string     `json:"role"`
+	IsActive      bool       `json:"is_active"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty"`
+	Version       int        `json:"version"`
 }
 
 // ListUsersRequest represents the input data for listing users with pagination
+// Sort accepts created_at (default, newest first) and name
 type ListUsersRequest struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+	pagination.Request
 }
 
-// ListUsersResponse represents the output data for listing users
+// ListUsersResponse represents one page of users
+// NextCursor is empty on the last page
 type ListUsersResponse struct {
-	Users   []GetUserResponse `json:"users"`
-	Total   int               `json:"total"`
-	Limit   int               `json:"limit"`
-	Offset  int               `json:"offset"`
-	HasMore bool              `json:"has_more"`
+	Users      []GetUserResponse `json:"users"`
+	Limit      int               `json:"limit"`
+	NextCursor string            `json:"next_cursor"`
 }
 
 // UpdateUserRequest represents the input data for updating a user
@@ -68,4 +85,43 @@ type UpdateUserResponse struct {
 	Role      string    `json:"role"`
 	IsActive  bool      `json:"is_active"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
+
+// CreateInvitationRequest represents the input data for inviting a staff member
+type CreateInvitationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required"` // admin, doctor, nurse or receptionist
+}
+
+// InvitationResponse represents a staff invitation; the token is only sent by email
+type InvitationResponse struct {
+	ID         string     `json:"id"`
+	Email      string     `json:"email"`
+	Role       string     `json:"role"`
+	Status     string     `json:"status"` // pending, accepted, revoked or expired
+	InvitedBy  string     `json:"invited_by,omitempty"`
+	UserID     string     `json:"user_id,omitempty"` // Account created from the invitation
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// AcceptInvitationRequest represents the input data for creating an account from an invitation
+// Email and role come from the invitation
+type AcceptInvitationRequest struct {
+	Token     string `json:"token" validate:"required"`
+	Password  string `json:"password" validate:"required,min=8"`
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name" validate:"required"`
+	Phone     string `json:"phone" validate:"required"`
+
+	// Optional doctor profile, ignored for other roles
+	Specialty         string  `json:"specialty,omitempty"`
+	ConsultationFee   float64 `json:"consultation_fee,omitempty"`
+	YearsOfExperience int     `json:"years_of_experience,omitempty"`
+	Education         string  `json:"education,omitempty"`
+	Bio               string  `json:"bio,omitempty"`
+	LocationID        string  `json:"location_id,omitempty"`
 }
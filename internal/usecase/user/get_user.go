@@ -41,14 +41,16 @@ func (uc *GetUserUseCase) Execute(ctx context.Context, id string) (*GetUserRespo
 
 	// Build and return response
 	response := &GetUserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Phone:     user.Phone,
-		Role:      string(user.Role),
-		IsActive:  user.IsActive,
-		CreatedAt: user.CreatedAt,
+		ID:            user.ID,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Phone:         user.Phone,
+		Role:          string(user.Role),
+		IsActive:      user.IsActive,
+		EmailVerified: user.IsEmailVerified(),
+		CreatedAt:     user.CreatedAt,
+		Version:       user.Version,
 	}
 
 	return response, nil
@@ -0,0 +1,47 @@
+package user
+
+import (
+	"context"
+
+	"version-1-0/internal/repository"
+)
+
+// ListDeletedUsersUseCase handles retrieving soft deleted users
+type ListDeletedUsersUseCase struct {
+	userRepo repository.UserRepository
+}
+
+// NewListDeletedUsersUseCase creates a new instance of ListDeletedUsersUseCase
+func NewListDeletedUsersUseCase(userRepo repository.UserRepository) *ListDeletedUsersUseCase {
+	return &ListDeletedUsersUseCase{
+		userRepo: userRepo,
+	}
+}
+
+// Execute retrieves every soft deleted user that has not been purged yet
+func (uc *ListDeletedUsersUseCase) Execute(ctx context.Context) ([]GetUserResponse, error) {
+	users, err := uc.userRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert domain users to response DTOs
+	responses := make([]GetUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = GetUserResponse{
+			ID:            user.ID,
+			Email:         user.Email,
+			FirstName:     user.FirstName,
+			LastName:      user.LastName,
+			Phone:         user.Phone,
+			Role:          string(user.Role),
+			IsActive:      user.IsActive,
+			EmailVerified: user.IsEmailVerified(),
+			CreatedAt:     user.CreatedAt,
+			Version:       user.Version,
+			DeletedAt:     user.DeletedAt,
+		}
+	}
+
+	return responses, nil
+}
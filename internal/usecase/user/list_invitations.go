@@ -0,0 +1,47 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// ListInvitationsUseCase handles the business logic for listing staff invitations
+type ListInvitationsUseCase struct {
+	invitationRepo repository.InvitationRepository
+}
+
+// NewListInvitationsUseCase creates a new instance of ListInvitationsUseCase
+func NewListInvitationsUseCase(invitationRepo repository.InvitationRepository) *ListInvitationsUseCase {
+	return &ListInvitationsUseCase{
+		invitationRepo: invitationRepo,
+	}
+}
+
+// Execute returns the invitations of the clinic, newest first
+// An empty status returns all of them
+func (uc *ListInvitationsUseCase) Execute(ctx context.Context, status string) ([]InvitationResponse, error) {
+	if status != "" && !domain.IsValidInvitationStatus(status) {
+		return nil, errors.New("invalid status: must be pending, accepted, revoked or expired")
+	}
+
+	invitations, err := uc.invitationRepo.List(ctx)
+	if err != nil {
+		return nil, errors.New("failed to list invitations")
+	}
+
+	now := time.Now()
+	response := make([]InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		item := toInvitationResponse(invitation, now)
+		if status != "" && item.Status != status {
+			continue
+		}
+		response = append(response, *item)
+	}
+
+	return response, nil
+}
@@ -3,9 +3,17 @@ package user
 import (
 	"context"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/pagination"
 )
 
+// userSorts are the orders users can be listed in
+var userSorts = pagination.Sorts{
+	repository.SortCreatedAt: true,
+	repository.SortName:      false,
+}
+
 // ListUsersUseCase handles the business logic for listing users with pagination
 type ListUsersUseCase struct {
 	userRepo repository.UserRepository
@@ -18,58 +26,45 @@ func NewListUsersUseCase(userRepo repository.UserRepository) *ListUsersUseCase {
 	}
 }
 
-// Execute retrieves a paginated list of users
-// Validates pagination parameters and applies reasonable defaults and limits
+// Execute retrieves one page of users
+// Validates the cursor and sort options and applies the default and maximum limit
 func (uc *ListUsersUseCase) Execute(ctx context.Context, req ListUsersRequest) (*ListUsersResponse, error) {
-	// Validate and set default limit
-	if req.Limit <= 0 {
-		req.Limit = 20 // default limit
-	}
-
-	// Validate offset
-	if req.Offset < 0 {
-		req.Offset = 0
-	}
-
-	// Enforce maximum limit
-	if req.Limit > 100 {
-		req.Limit = 100 // maximum limit
+	page, err := pagination.Resolve(req.Request, userSorts, repository.SortCreatedAt)
+	if err != nil {
+		return nil, err
 	}
 
 	// Retrieve users from repository
-	users, err := uc.userRepo.List(ctx, req.Limit, req.Offset)
+	users, err := uc.userRepo.List(ctx, pagination.Lookahead(page))
 	if err != nil {
 		return nil, err
 	}
 
+	users, nextCursor := pagination.Finish(page, users, func(u *domain.User) repository.Cursor {
+		return repository.Cursor{Key: repository.UserSortKey(u, page.Sort), ID: u.ID}
+	})
+
 	// Convert domain users to response DTOs
 	userResponses := make([]GetUserResponse, len(users))
 	for i, user := range users {
 		userResponses[i] = GetUserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			Phone:     user.Phone,
-			Role:      string(user.Role),
-			IsActive:  user.IsActive,
-			CreatedAt: user.CreatedAt,
+			ID:            user.ID,
+			Email:         user.Email,
+			FirstName:     user.FirstName,
+			LastName:      user.LastName,
+			Phone:         user.Phone,
+			Role:          string(user.Role),
+			IsActive:      user.IsActive,
+			EmailVerified: user.IsEmailVerified(),
+			CreatedAt:     user.CreatedAt,
+			Version:       user.Version,
 		}
 	}
 
-	// Calculate if there are more users available
-	// If we got exactly the limit requested, there might be more
-	hasMore := len(users) == req.Limit
-
-	// Build response
-	// Note: Total is currently set to len(users) for simplicity
-	// In production, this should be a separate COUNT query to get the actual total
 	response := &ListUsersResponse{
-		Users:   userResponses,
-		Total:   len(users), // TODO: Replace with actual count from database
-		Limit:   req.Limit,
-		Offset:  req.Offset,
-		HasMore: hasMore,
+		Users:      userResponses,
+		Limit:      page.Limit,
+		NextCursor: nextCursor,
 	}
 
 	return response, nil
@@ -0,0 +1,34 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"version-1-0/internal/domain"
+)
+
+// RegisterPatientUseCase handles public signup, which only creates patient accounts
+// Doctors and admins join through invitations, see CreateInvitationUseCase
+type RegisterPatientUseCase struct {
+	createUser *CreateUserUseCase
+}
+
+// NewRegisterPatientUseCase creates a new instance of RegisterPatientUseCase
+func NewRegisterPatientUseCase(createUser *CreateUserUseCase) *RegisterPatientUseCase {
+	return &RegisterPatientUseCase{
+		createUser: createUser,
+	}
+}
+
+// Execute creates a patient account; an empty role means patient and any
+// other role is refused
+func (uc *RegisterPatientUseCase) Execute(ctx context.Context, req CreateUserRequest) (*CreateUserResponse, error) {
+	if req.Role == "" {
+		req.Role = string(domain.RolePatient)
+	}
+	if domain.UserRole(req.Role) != domain.RolePatient {
+		return nil, errors.New("public signup is limited to patient accounts")
+	}
+
+	return uc.createUser.Execute(ctx, req)
+}
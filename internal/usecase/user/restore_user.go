@@ -0,0 +1,54 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// RestoreUserUseCase handles the business logic for restoring soft deleted users
+type RestoreUserUseCase struct {
+	userRepo   repository.UserRepository
+	doctorRepo repository.DoctorRepository
+	txManager  repository.TxManager
+	recorder   *audit.Recorder
+}
+
+// NewRestoreUserUseCase creates a new instance of RestoreUserUseCase
+func NewRestoreUserUseCase(
+	userRepo repository.UserRepository,
+	doctorRepo repository.DoctorRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *RestoreUserUseCase {
+	return &RestoreUserUseCase{
+		userRepo:   userRepo,
+		doctorRepo: doctorRepo,
+		txManager:  txManager,
+		recorder:   recorder,
+	}
+}
+
+// Execute restores a soft deleted user together with their doctor profile
+// Returns "user not found" if the user does not exist or is not deleted
+func (uc *RestoreUserUseCase) Execute(ctx context.Context, userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return errors.New("user ID is required")
+	}
+
+	return uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Restore(ctx, userID); err != nil {
+			return err
+		}
+
+		if err := uc.doctorRepo.RestoreByUserID(ctx, userID); err != nil {
+			return err
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityUser, userID, domain.AuditActionRestore, nil, nil)
+	})
+}
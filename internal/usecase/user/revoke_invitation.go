@@ -0,0 +1,75 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+	"version-1-0/internal/usecase/audit"
+)
+
+// errInvitationNotPending is returned when an invitation was already accepted, revoked or expired
+var errInvitationNotPending = errors.New("invitation is no longer pending")
+
+// RevokeInvitationUseCase handles the business logic for withdrawing a staff invitation
+type RevokeInvitationUseCase struct {
+	invitationRepo repository.InvitationRepository
+	txManager      repository.TxManager
+	recorder       *audit.Recorder
+}
+
+// NewRevokeInvitationUseCase creates a new instance of RevokeInvitationUseCase
+func NewRevokeInvitationUseCase(
+	invitationRepo repository.InvitationRepository,
+	txManager repository.TxManager,
+	recorder *audit.Recorder,
+) *RevokeInvitationUseCase {
+	return &RevokeInvitationUseCase{
+		invitationRepo: invitationRepo,
+		txManager:      txManager,
+		recorder:       recorder,
+	}
+}
+
+// Execute revokes a pending invitation so its link stops working
+// Accounts already created from an invitation are not affected
+func (uc *RevokeInvitationUseCase) Execute(ctx context.Context, invitationID string) (*InvitationResponse, error) {
+	if strings.TrimSpace(invitationID) == "" {
+		return nil, errors.New("invitation ID is required")
+	}
+
+	invitation, err := uc.invitationRepo.FindByID(ctx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if invitation == nil {
+		return nil, errors.New("invitation not found")
+	}
+
+	now := time.Now()
+	if invitation.Status(now) != domain.InvitationPending {
+		return nil, errInvitationNotPending
+	}
+	before := *invitation
+	invitation.RevokedAt = &now
+
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		revoked, err := uc.invitationRepo.Revoke(ctx, invitation.ID, now)
+		if err != nil {
+			return err
+		}
+		if !revoked {
+			return errInvitationNotPending
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityInvitation, invitation.ID, domain.AuditActionRevoke, &before, invitation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toInvitationResponse(invitation, now), nil
+}
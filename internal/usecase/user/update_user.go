@@ -8,26 +8,45 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"version-1-0/internal/domain"
 	"version-1-0/internal/repository"
+	"version-1-0/internal/requestctx"
+	"version-1-0/internal/usecase/audit"
+	"version-1-0/internal/usecase/role"
 )
 
 // UpdateUserUseCase handles the business logic for updating user information
 type UpdateUserUseCase struct {
-	userRepo repository.UserRepository
+	userRepo         repository.UserRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	txManager        repository.TxManager
+	recorder         *audit.Recorder
+	authorizer       *role.Authorizer
 }
 
 // NewUpdateUserUseCase creates a new instance of UpdateUserUseCase
-func NewUpdateUserUseCase(userRepo repository.UserRepository) *UpdateUserUseCase {
+func NewUpdateUserUseCase(userRepo repository.UserRepository, refreshTokenRepo repository.RefreshTokenRepository, txManager repository.TxManager, recorder *audit.Recorder, authorizer *role.Authorizer) *UpdateUserUseCase {
 	return &UpdateUserUseCase{
-		userRepo: userRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		txManager:        txManager,
+		recorder:         recorder,
+		authorizer:       authorizer,
 	}
 }
 
 // Execute updates a user's information with permission validation
-// Only admins can update any user, regular users can only update themselves
-func (uc *UpdateUserUseCase) Execute(ctx context.Context, userID string, authenticatedUserID string, authenticatedUserRole string, req UpdateUserRequest) (*UpdateUserResponse, error) {
-	// Validate permissions: only admins or the user themselves can update
-	if authenticatedUserRole != "admin" && userID != authenticatedUserID {
+// Roles with users:write can update any user, everyone else only themselves
+// A new password ends the user's other sessions; users changing their own
+// password stay logged in on the session they changed it from
+// expectedVersion is the version the client last saw, zero skips the check
+func (uc *UpdateUserUseCase) Execute(ctx context.Context, userID string, authenticatedUserID string, authenticatedUserRole string, req UpdateUserRequest, expectedVersion int) (*UpdateUserResponse, error) {
+	// Validate permissions: only the user themselves or a role allowed to manage users can update
+	canWrite, err := uc.authorizer.Can(ctx, authenticatedUserRole, domain.PermissionUsersWrite)
+	if err != nil {
+		return nil, err
+	}
+	if !canWrite && userID != authenticatedUserID {
 		return nil, errors.New("insufficient permissions to update this user")
 	}
 
@@ -39,6 +58,14 @@ func (uc *UpdateUserUseCase) Execute(ctx context.Context, userID string, authent
 	if existingUser == nil {
 		return nil, errors.New("user not found")
 	}
+	if userID != authenticatedUserID && !domain.UserRole(authenticatedUserRole).CanManage(existingUser.Role) {
+		return nil, errors.New("insufficient permissions to update this user")
+	}
+	// Refuse to act on a stale copy when the client sent the version it saw
+	if err := domain.CheckVersion(existingUser.Version, expectedVersion); err != nil {
+		return nil, err
+	}
+	before := *existingUser
 
 	// Validate and update first name if provided
 	if strings.TrimSpace(req.FirstName) != "" {
@@ -82,8 +109,24 @@ func (uc *UpdateUserUseCase) Execute(ctx context.Context, userID string, authent
 	// Update timestamp
 	existingUser.UpdatedAt = time.Now()
 
-	// Save to database
-	err = uc.userRepo.Update(ctx, existingUser)
+	// Save to database together with its audit entry
+	err = uc.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, existingUser); err != nil {
+			return err
+		}
+
+		if req.Password != "" {
+			keepSessionID := ""
+			if userID == authenticatedUserID {
+				keepSessionID, _ = ctx.Value(requestctx.SessionIDKey).(string)
+			}
+			if err := uc.refreshTokenRepo.RevokeUserSessions(ctx, existingUser.ID, keepSessionID, existingUser.UpdatedAt); err != nil {
+				return err
+			}
+		}
+
+		return uc.recorder.Record(ctx, domain.AuditEntityUser, existingUser.ID, domain.AuditActionUpdate, &before, existingUser)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +141,7 @@ func (uc *UpdateUserUseCase) Execute(ctx context.Context, userID string, authent
 		Role:      string(existingUser.Role),
 		IsActive:  existingUser.IsActive,
 		UpdatedAt: existingUser.UpdatedAt,
+		Version:   existingUser.Version,
 	}
 
 	return response, nil
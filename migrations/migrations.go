@@ -0,0 +1,15 @@
+// Package migrations embeds the versioned SQL schema migrations
+//
+// Each dialect has its own directory with one pair of files per version:
+// NNNN_name.up.sql applies the change and NNNN_name.down.sql reverts it.
+// Both dialects share the same version numbers so a database can be moved
+// between backends without renumbering; a version that only matters to one
+// engine is kept as a no-op file in the other
+package migrations
+
+import "embed"
+
+// FS holds the migration files of every dialect, rooted at the dialect name
+//
+//go:embed sqlite/*.sql postgres/*.sql
+var FS embed.FS
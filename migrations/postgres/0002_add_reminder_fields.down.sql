@@ -0,0 +1,2 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS reminder_1h_sent;
+ALTER TABLE appointments DROP COLUMN IF EXISTS reminder_24h_sent;
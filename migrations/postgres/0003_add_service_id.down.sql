@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_appointments_service_id;
+ALTER TABLE appointments DROP COLUMN IF EXISTS service_id;
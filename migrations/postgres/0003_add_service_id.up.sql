@@ -0,0 +1,2 @@
+ALTER TABLE appointments ADD COLUMN IF NOT EXISTS service_id TEXT REFERENCES services(id);
+CREATE INDEX IF NOT EXISTS idx_appointments_service_id ON appointments(service_id);
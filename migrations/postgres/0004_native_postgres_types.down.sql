@@ -0,0 +1,75 @@
+-- Back to the portable column types of 0001; timestamps are stored as UTC
+
+ALTER TABLE patients DROP CONSTRAINT IF EXISTS patients_user_id_fkey;
+ALTER TABLE doctors DROP CONSTRAINT IF EXISTS doctors_user_id_fkey;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_doctor_id_fkey;
+ALTER TABLE doctor_services
+    DROP CONSTRAINT IF EXISTS doctor_services_doctor_id_fkey,
+    DROP CONSTRAINT IF EXISTS doctor_services_service_id_fkey;
+ALTER TABLE appointments
+    DROP CONSTRAINT IF EXISTS appointments_patient_id_fkey,
+    DROP CONSTRAINT IF EXISTS appointments_doctor_id_fkey,
+    DROP CONSTRAINT IF EXISTS appointments_service_id_fkey;
+
+ALTER TABLE users
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE patients
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN user_id TYPE TEXT USING user_id::text,
+    ALTER COLUMN birthdate TYPE TIMESTAMP USING birthdate::timestamp,
+    ALTER COLUMN allergies DROP DEFAULT,
+    ALTER COLUMN allergies TYPE TEXT USING array_to_string(allergies, ','),
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE doctors
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN user_id TYPE TEXT USING user_id::text,
+    ALTER COLUMN consultation_fee TYPE REAL USING consultation_fee::real,
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE schedules
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN doctor_id TYPE TEXT USING doctor_id::text,
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE services
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN price TYPE REAL USING price::real,
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE doctor_services
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN doctor_id TYPE TEXT USING doctor_id::text,
+    ALTER COLUMN service_id TYPE TEXT USING service_id::text,
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE appointments
+    ALTER COLUMN id TYPE TEXT USING id::text,
+    ALTER COLUMN patient_id TYPE TEXT USING patient_id::text,
+    ALTER COLUMN doctor_id TYPE TEXT USING doctor_id::text,
+    ALTER COLUMN service_id TYPE TEXT USING service_id::text,
+    ALTER COLUMN scheduled_at TYPE TIMESTAMP USING scheduled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN cancelled_at TYPE TIMESTAMP USING cancelled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMP USING updated_at AT TIME ZONE 'UTC';
+
+ALTER TABLE patients ADD CONSTRAINT patients_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE doctors ADD CONSTRAINT doctors_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE schedules ADD CONSTRAINT schedules_doctor_id_fkey
+    FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE;
+ALTER TABLE doctor_services
+    ADD CONSTRAINT doctor_services_doctor_id_fkey
+        FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE,
+    ADD CONSTRAINT doctor_services_service_id_fkey
+        FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_patient_id_fkey
+        FOREIGN KEY (patient_id) REFERENCES patients(id) ON DELETE CASCADE,
+    ADD CONSTRAINT appointments_doctor_id_fkey
+        FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE,
+    ADD CONSTRAINT appointments_service_id_fkey
+        FOREIGN KEY (service_id) REFERENCES services(id);
@@ -0,0 +1,85 @@
+-- Convert the portable TEXT/REAL/TIMESTAMP columns created by 0001 into native
+-- PostgreSQL types: uuid keys, timestamptz, a date birthdate, text[] allergies
+-- and numeric prices
+
+-- Foreign keys must be dropped while both sides change type
+ALTER TABLE patients DROP CONSTRAINT IF EXISTS patients_user_id_fkey;
+ALTER TABLE doctors DROP CONSTRAINT IF EXISTS doctors_user_id_fkey;
+ALTER TABLE schedules DROP CONSTRAINT IF EXISTS schedules_doctor_id_fkey;
+ALTER TABLE doctor_services
+    DROP CONSTRAINT IF EXISTS doctor_services_doctor_id_fkey,
+    DROP CONSTRAINT IF EXISTS doctor_services_service_id_fkey;
+ALTER TABLE appointments
+    DROP CONSTRAINT IF EXISTS appointments_patient_id_fkey,
+    DROP CONSTRAINT IF EXISTS appointments_doctor_id_fkey,
+    DROP CONSTRAINT IF EXISTS appointments_service_id_fkey;
+
+ALTER TABLE users
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE patients
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN user_id TYPE UUID USING user_id::uuid,
+    ALTER COLUMN birthdate TYPE DATE USING birthdate::date,
+    ALTER COLUMN allergies TYPE TEXT[] USING (
+        CASE
+            WHEN allergies IS NULL OR allergies = '' THEN '{}'::text[]
+            WHEN allergies LIKE '{%}' THEN allergies::text[]
+            ELSE string_to_array(allergies, ',')
+        END
+    ),
+    ALTER COLUMN allergies SET DEFAULT '{}',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE doctors
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN user_id TYPE UUID USING user_id::uuid,
+    ALTER COLUMN consultation_fee TYPE NUMERIC(10,2) USING consultation_fee::numeric(10,2),
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE schedules
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN doctor_id TYPE UUID USING doctor_id::uuid,
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE services
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN price TYPE NUMERIC(10,2) USING price::numeric(10,2),
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE doctor_services
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN doctor_id TYPE UUID USING doctor_id::uuid,
+    ALTER COLUMN service_id TYPE UUID USING service_id::uuid,
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+ALTER TABLE appointments
+    ALTER COLUMN id TYPE UUID USING id::uuid,
+    ALTER COLUMN patient_id TYPE UUID USING patient_id::uuid,
+    ALTER COLUMN doctor_id TYPE UUID USING doctor_id::uuid,
+    ALTER COLUMN service_id TYPE UUID USING NULLIF(service_id, '')::uuid,
+    ALTER COLUMN scheduled_at TYPE TIMESTAMPTZ USING scheduled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN cancelled_at TYPE TIMESTAMPTZ USING cancelled_at AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN updated_at TYPE TIMESTAMPTZ USING updated_at AT TIME ZONE 'UTC';
+
+-- Restore foreign keys with the same names and delete rules
+ALTER TABLE patients ADD CONSTRAINT patients_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE doctors ADD CONSTRAINT doctors_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE schedules ADD CONSTRAINT schedules_doctor_id_fkey
+    FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE;
+ALTER TABLE doctor_services
+    ADD CONSTRAINT doctor_services_doctor_id_fkey
+        FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE,
+    ADD CONSTRAINT doctor_services_service_id_fkey
+        FOREIGN KEY (service_id) REFERENCES services(id) ON DELETE CASCADE;
+ALTER TABLE appointments
+    ADD CONSTRAINT appointments_patient_id_fkey
+        FOREIGN KEY (patient_id) REFERENCES patients(id) ON DELETE CASCADE,
+    ADD CONSTRAINT appointments_doctor_id_fkey
+        FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE,
+    ADD CONSTRAINT appointments_service_id_fkey
+        FOREIGN KEY (service_id) REFERENCES services(id);
@@ -0,0 +1,3 @@
+-- btree_gist is left installed: other objects may depend on it
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_overlap;
+DROP FUNCTION IF EXISTS appointment_slot(TIMESTAMPTZ, INTEGER);
@@ -0,0 +1,17 @@
+-- Reject two active appointments of the same doctor whose time ranges overlap
+-- The repositories translate the violation into domain.ErrSlotTaken
+-- Overlapping appointments already in the table must be resolved first
+
+-- Needed to combine equality on doctor_id with range overlap in one GiST index
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- timestamptz + interval is only STABLE because day and month steps depend on
+-- the time zone; adding whole minutes does not, so the wrapper is safely IMMUTABLE
+CREATE OR REPLACE FUNCTION appointment_slot(starts_at TIMESTAMPTZ, minutes INTEGER)
+RETURNS TSTZRANGE
+LANGUAGE sql IMMUTABLE
+AS $$ SELECT tstzrange(starts_at, starts_at + make_interval(mins => minutes), '[)') $$;
+
+ALTER TABLE appointments ADD CONSTRAINT appointments_no_overlap
+    EXCLUDE USING gist (doctor_id WITH =, appointment_slot(scheduled_at, duration) WITH &&)
+    WHERE (status <> 'cancelled');
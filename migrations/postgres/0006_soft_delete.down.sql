@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_services_deleted_at;
+DROP INDEX IF EXISTS idx_doctors_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+
+ALTER TABLE services DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE doctors DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
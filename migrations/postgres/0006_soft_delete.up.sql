@@ -0,0 +1,10 @@
+-- Users, doctors and services are soft deleted so clinical history survives;
+-- rows with deleted_at set are hidden from every query except the admin views
+ALTER TABLE users ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE doctors ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE services ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Only deleted rows are looked up by deleted_at (admin views and the purge job)
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_doctors_deleted_at ON doctors(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_services_deleted_at ON services(deleted_at) WHERE deleted_at IS NOT NULL;
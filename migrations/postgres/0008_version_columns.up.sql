@@ -0,0 +1,6 @@
+-- Optimistic concurrency: every update must name the version it read and bumps
+-- it by one, so a stale write matches no row instead of overwriting newer data
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE services ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE schedules ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE appointments ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS service_prices;
+DROP TABLE IF EXISTS doctor_locations;
+
+ALTER TABLE audit_log DROP COLUMN clinic_id;
+ALTER TABLE appointments DROP COLUMN location_id;
+ALTER TABLE schedules DROP COLUMN location_id;
+ALTER TABLE services DROP COLUMN clinic_id;
+ALTER TABLE users DROP COLUMN clinic_id;
+
+DROP TABLE IF EXISTS locations;
+DROP TABLE IF EXISTS clinics;
@@ -0,0 +1,7 @@
+-- Accounts confirm they own their email through a signed link before
+-- patients can book appointments. Accounts created before verification
+-- existed are considered verified
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN verification_sent_at TIMESTAMPTZ;
+
+UPDATE users SET email_verified_at = created_at;
@@ -0,0 +1,18 @@
+-- Staff invitations, stored as SHA-256 hashes of the emailed token
+-- Public signup only creates patients; doctors and admins join by accepting one
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY,
+    clinic_id UUID NOT NULL REFERENCES clinics(id),
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_clinic_id ON invitations(clinic_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(email);
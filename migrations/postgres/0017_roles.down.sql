@@ -0,0 +1,6 @@
+-- Fails while nurse or receptionist accounts exist; delete or change them first
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_role_check,
+    ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'doctor', 'patient'));
+
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,14 @@
+-- Permission sets clinics give their roles, as space separated permission
+-- names; roles without a row keep the defaults built into the application
+CREATE TABLE IF NOT EXISTS roles (
+    clinic_id UUID NOT NULL REFERENCES clinics(id),
+    role TEXT NOT NULL,
+    permissions TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (clinic_id, role)
+);
+
+-- Nurses and receptionists join the roles users.role accepts
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_role_check,
+    ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'doctor', 'nurse', 'receptionist', 'patient'));
@@ -0,0 +1,15 @@
+-- Links between staff accounts and their identities at the single sign-on
+-- provider, which names each person with its issuer and subject
+-- A person has one account per clinic and an account one identity per provider
+CREATE TABLE IF NOT EXISTS external_identities (
+    id UUID PRIMARY KEY,
+    clinic_id UUID NOT NULL REFERENCES clinics(id),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    last_login_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (clinic_id, issuer, subject),
+    UNIQUE (user_id, issuer)
+);
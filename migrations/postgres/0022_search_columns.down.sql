@@ -0,0 +1,6 @@
+-- unaccent is left installed: other objects may depend on it
+ALTER TABLE services DROP COLUMN IF EXISTS search_name;
+ALTER TABLE doctors DROP COLUMN IF EXISTS search_bio;
+ALTER TABLE doctors DROP COLUMN IF EXISTS search_specialty;
+ALTER TABLE users DROP COLUMN IF EXISTS search_name;
+DROP FUNCTION IF EXISTS search_words(TEXT);
@@ -0,0 +1,25 @@
+-- Folded, indexed copies of the text the doctor search matches: its words in
+-- lowercase without accents, as a tsvector a query word is found in with the
+-- prefix query 'word:*'
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() is only STABLE because its dictionary could be swapped; this
+-- one is fixed, so the wrapper is safely IMMUTABLE and can feed generated columns
+CREATE OR REPLACE FUNCTION search_words(content TEXT)
+RETURNS TSVECTOR
+LANGUAGE sql IMMUTABLE PARALLEL SAFE
+AS $$ SELECT to_tsvector('simple'::regconfig, public.unaccent('public.unaccent'::regdictionary, COALESCE(content, ''))) $$;
+
+ALTER TABLE users ADD COLUMN search_name TSVECTOR
+    GENERATED ALWAYS AS (search_words(first_name || ' ' || last_name)) STORED;
+ALTER TABLE doctors ADD COLUMN search_specialty TSVECTOR
+    GENERATED ALWAYS AS (search_words(specialty)) STORED;
+ALTER TABLE doctors ADD COLUMN search_bio TSVECTOR
+    GENERATED ALWAYS AS (search_words(bio)) STORED;
+ALTER TABLE services ADD COLUMN search_name TSVECTOR
+    GENERATED ALWAYS AS (search_words(name)) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_users_search_name ON users USING gin(search_name);
+CREATE INDEX IF NOT EXISTS idx_doctors_search_specialty ON doctors USING gin(search_specialty);
+CREATE INDEX IF NOT EXISTS idx_doctors_search_bio ON doctors USING gin(search_bio);
+CREATE INDEX IF NOT EXISTS idx_services_search_name ON services USING gin(search_name);
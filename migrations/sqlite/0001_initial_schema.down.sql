@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS appointments;
+DROP TABLE IF EXISTS doctor_services;
+DROP TABLE IF EXISTS services;
+DROP TABLE IF EXISTS schedules;
+DROP TABLE IF EXISTS doctors;
+DROP TABLE IF EXISTS patients;
+DROP TABLE IF EXISTS users;
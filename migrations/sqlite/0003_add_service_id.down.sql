@@ -0,0 +1,37 @@
+-- SQLite cannot drop a column that takes part in a foreign key,
+-- so the table is rebuilt without it
+CREATE TABLE appointments_old (
+    id TEXT PRIMARY KEY,
+    patient_id TEXT NOT NULL,
+    doctor_id TEXT NOT NULL,
+    scheduled_at TIMESTAMP NOT NULL,
+    duration INTEGER NOT NULL,
+    reason TEXT NOT NULL,
+    notes TEXT,
+    status TEXT NOT NULL CHECK(status IN ('pending', 'confirmed', 'cancelled', 'completed')),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    cancelled_at TIMESTAMP,
+    cancellation_reason TEXT,
+    reminder_24h_sent BOOLEAN DEFAULT FALSE,
+    reminder_1h_sent BOOLEAN DEFAULT FALSE,
+    FOREIGN KEY (patient_id) REFERENCES patients(id) ON DELETE CASCADE,
+    FOREIGN KEY (doctor_id) REFERENCES doctors(id) ON DELETE CASCADE
+);
+
+INSERT INTO appointments_old (
+    id, patient_id, doctor_id, scheduled_at, duration, reason, notes, status,
+    created_at, updated_at, cancelled_at, cancellation_reason, reminder_24h_sent, reminder_1h_sent
+)
+SELECT
+    id, patient_id, doctor_id, scheduled_at, duration, reason, notes, status,
+    created_at, updated_at, cancelled_at, cancellation_reason, reminder_24h_sent, reminder_1h_sent
+FROM appointments;
+
+DROP TABLE appointments;
+ALTER TABLE appointments_old RENAME TO appointments;
+
+CREATE INDEX IF NOT EXISTS idx_appointments_patient_id ON appointments(patient_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_doctor_id ON appointments(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_scheduled_at ON appointments(scheduled_at);
+CREATE INDEX IF NOT EXISTS idx_appointments_status ON appointments(status);
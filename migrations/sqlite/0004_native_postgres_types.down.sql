@@ -0,0 +1 @@
+-- PostgreSQL only: nothing to revert on SQLite
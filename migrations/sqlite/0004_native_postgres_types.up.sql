@@ -0,0 +1 @@
+-- PostgreSQL only: SQLite keeps the portable column types created by 0001
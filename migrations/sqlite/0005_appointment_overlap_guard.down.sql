@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS appointments_no_overlap_update;
+DROP TRIGGER IF EXISTS appointments_no_overlap_insert;
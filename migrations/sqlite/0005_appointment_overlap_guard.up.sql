@@ -0,0 +1,32 @@
+-- Reject two active appointments of the same doctor whose time ranges overlap
+-- The repositories translate the violation into domain.ErrSlotTaken
+-- SQLite has no exclusion constraints, so the rule is enforced with triggers
+-- Times are compared as unix seconds to stay exact at slot boundaries
+
+CREATE TRIGGER IF NOT EXISTS appointments_no_overlap_insert
+BEFORE INSERT ON appointments
+WHEN NEW.status <> 'cancelled' AND EXISTS (
+    SELECT 1 FROM appointments a
+    WHERE a.doctor_id = NEW.doctor_id
+    AND a.id <> NEW.id
+    AND a.status <> 'cancelled'
+    AND CAST(strftime('%s', a.scheduled_at) AS INTEGER) < CAST(strftime('%s', NEW.scheduled_at) AS INTEGER) + NEW.duration * 60
+    AND CAST(strftime('%s', a.scheduled_at) AS INTEGER) + a.duration * 60 > CAST(strftime('%s', NEW.scheduled_at) AS INTEGER)
+)
+BEGIN
+    SELECT RAISE(ABORT, 'appointment slot taken');
+END;
+
+CREATE TRIGGER IF NOT EXISTS appointments_no_overlap_update
+BEFORE UPDATE OF doctor_id, scheduled_at, duration, status ON appointments
+WHEN NEW.status <> 'cancelled' AND EXISTS (
+    SELECT 1 FROM appointments a
+    WHERE a.doctor_id = NEW.doctor_id
+    AND a.id <> NEW.id
+    AND a.status <> 'cancelled'
+    AND CAST(strftime('%s', a.scheduled_at) AS INTEGER) < CAST(strftime('%s', NEW.scheduled_at) AS INTEGER) + NEW.duration * 60
+    AND CAST(strftime('%s', a.scheduled_at) AS INTEGER) + a.duration * 60 > CAST(strftime('%s', NEW.scheduled_at) AS INTEGER)
+)
+BEGIN
+    SELECT RAISE(ABORT, 'appointment slot taken');
+END;
@@ -0,0 +1,4 @@
+ALTER TABLE appointments DROP COLUMN version;
+ALTER TABLE schedules DROP COLUMN version;
+ALTER TABLE services DROP COLUMN version;
+ALTER TABLE users DROP COLUMN version;
@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS service_prices;
+DROP TABLE IF EXISTS doctor_locations;
+
+DROP INDEX IF EXISTS idx_audit_log_clinic_id;
+DROP INDEX IF EXISTS idx_appointments_location_id;
+DROP INDEX IF EXISTS idx_schedules_location_id;
+DROP INDEX IF EXISTS idx_services_clinic_id;
+DROP INDEX IF EXISTS idx_users_clinic_id;
+
+ALTER TABLE audit_log DROP COLUMN clinic_id;
+ALTER TABLE appointments DROP COLUMN location_id;
+ALTER TABLE schedules DROP COLUMN location_id;
+ALTER TABLE services DROP COLUMN clinic_id;
+ALTER TABLE users DROP COLUMN clinic_id;
+
+DROP TABLE IF EXISTS locations;
+DROP TABLE IF EXISTS clinics;
@@ -0,0 +1,69 @@
+-- Tenancy: every record belongs to a clinic, either directly (users, services,
+-- locations, audit_log) or through its user, service or location
+-- Existing data moves to a default clinic with a single location
+CREATE TABLE IF NOT EXISTS clinics (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    currency_symbol TEXT NOT NULL DEFAULT 'S/',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS locations (
+    id TEXT PRIMARY KEY,
+    clinic_id TEXT NOT NULL REFERENCES clinics(id),
+    name TEXT NOT NULL,
+    address TEXT NOT NULL DEFAULT '',
+    phone TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE INDEX IF NOT EXISTS idx_locations_clinic_id ON locations(clinic_id);
+
+INSERT INTO clinics (id, name, currency_symbol, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000001', 'Clínica Internacional', 'S/', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+INSERT INTO locations (id, clinic_id, name, created_at, updated_at)
+VALUES ('00000000-0000-0000-0000-000000000001', '00000000-0000-0000-0000-000000000001', 'Sede Principal', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+-- SQLite can't add a REFERENCES column with a non-NULL default while foreign
+-- keys are enforced, so these columns are checked by the repositories only
+ALTER TABLE users ADD COLUMN clinic_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE services ADD COLUMN clinic_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE schedules ADD COLUMN location_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE appointments ADD COLUMN location_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE audit_log ADD COLUMN clinic_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001';
+
+CREATE INDEX IF NOT EXISTS idx_users_clinic_id ON users(clinic_id);
+CREATE INDEX IF NOT EXISTS idx_services_clinic_id ON services(clinic_id);
+CREATE INDEX IF NOT EXISTS idx_schedules_location_id ON schedules(location_id);
+CREATE INDEX IF NOT EXISTS idx_appointments_location_id ON appointments(location_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_clinic_id ON audit_log(clinic_id);
+
+-- Locations each doctor works at; their schedules must be at one of them
+CREATE TABLE IF NOT EXISTS doctor_locations (
+    doctor_id TEXT NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    location_id TEXT NOT NULL REFERENCES locations(id),
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (doctor_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_doctor_locations_location_id ON doctor_locations(location_id);
+
+INSERT INTO doctor_locations (doctor_id, location_id, created_at)
+SELECT id, '00000000-0000-0000-0000-000000000001', CURRENT_TIMESTAMP FROM doctors;
+
+-- Per location price overrides; without one a service costs services.price
+CREATE TABLE IF NOT EXISTS service_prices (
+    service_id TEXT NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    location_id TEXT NOT NULL REFERENCES locations(id),
+    price REAL NOT NULL CHECK(price >= 0),
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (service_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_service_prices_location_id ON service_prices(location_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_patients_document_number_index;
+
+ALTER TABLE patients DROP COLUMN document_number_index;
@@ -0,0 +1,7 @@
+-- Sensitive patient fields and appointment notes are encrypted by the
+-- repositories, so document numbers are looked up through a blind index
+-- Rows written before keep their plaintext and a NULL index until
+-- `clinicctl rotate-keys` encrypts them
+ALTER TABLE patients ADD COLUMN document_number_index TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_patients_document_number_index ON patients(document_number_index);
@@ -0,0 +1,18 @@
+-- Staff invitations, stored as SHA-256 hashes of the emailed token
+-- Public signup only creates patients; doctors and admins join by accepting one
+CREATE TABLE IF NOT EXISTS invitations (
+    id TEXT PRIMARY KEY,
+    clinic_id TEXT NOT NULL REFERENCES clinics(id),
+    email TEXT NOT NULL,
+    role TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    invited_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_clinic_id ON invitations(clinic_id);
+CREATE INDEX IF NOT EXISTS idx_invitations_email ON invitations(email);
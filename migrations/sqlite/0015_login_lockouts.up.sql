@@ -0,0 +1,17 @@
+-- Failed login counters per account and per client IP address
+-- An account or address is locked for a while after too many failures;
+-- account lockouts carry the SHA-256 hash of the emailed unlock token
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    id TEXT PRIMARY KEY,
+    clinic_id TEXT NOT NULL REFERENCES clinics(id),
+    scope TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+    unlock_token_hash TEXT UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (clinic_id, scope, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_lockouts_locked_until ON login_lockouts(locked_until);
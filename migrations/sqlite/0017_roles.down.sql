@@ -0,0 +1,37 @@
+-- Fails while nurse or receptionist accounts exist; delete or change them first
+CREATE TABLE users_new (
+    id TEXT PRIMARY KEY,
+    email TEXT UNIQUE NOT NULL,
+    password_hash TEXT NOT NULL,
+    first_name TEXT NOT NULL,
+    last_name TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    role TEXT NOT NULL CHECK(role IN ('admin', 'doctor', 'patient')),
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    clinic_id TEXT NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001',
+    email_verified_at TIMESTAMP,
+    verification_sent_at TIMESTAMP
+);
+
+INSERT INTO users_new (
+    id, email, password_hash, first_name, last_name, phone, role, is_active,
+    created_at, updated_at, deleted_at, version, clinic_id, email_verified_at, verification_sent_at
+)
+SELECT
+    id, email, password_hash, first_name, last_name, phone, role, is_active,
+    created_at, updated_at, deleted_at, version, clinic_id, email_verified_at, verification_sent_at
+FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_users_clinic_id ON users(clinic_id);
+
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,19 @@
+-- Logins of each user, with the device they came from. A session is active
+-- while it has a refresh token that is neither used, revoked nor expired;
+-- its id is the session_id of those tokens and the sid of its access tokens
+CREATE TABLE IF NOT EXISTS sessions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- Sessions started before this table existed, without their device
+INSERT INTO sessions (id, user_id, created_at, last_seen_at)
+SELECT session_id, user_id, MIN(created_at), MAX(created_at)
+FROM refresh_tokens
+GROUP BY session_id, user_id;
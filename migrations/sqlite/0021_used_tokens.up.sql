@@ -0,0 +1,8 @@
+-- IDs (jti) of signed single-use tokens already used, such as the state of
+-- a single sign-on flow; each is kept until its token expires
+CREATE TABLE IF NOT EXISTS used_tokens (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_used_tokens_expires_at ON used_tokens(expires_at);
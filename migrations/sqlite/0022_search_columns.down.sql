@@ -0,0 +1,4 @@
+ALTER TABLE services DROP COLUMN search_name;
+ALTER TABLE doctors DROP COLUMN search_bio;
+ALTER TABLE doctors DROP COLUMN search_specialty;
+ALTER TABLE users DROP COLUMN search_name;
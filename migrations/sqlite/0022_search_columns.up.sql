@@ -0,0 +1,12 @@
+-- Folded copies of the text the doctor search matches: its words in lowercase
+-- without accents, separated and surrounded by single spaces, so a query word
+-- is found with LIKE '% word%'. The repositories write them together with the
+-- text; search_words() is the same folding, registered by the API's driver
+ALTER TABLE users ADD COLUMN search_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE doctors ADD COLUMN search_specialty TEXT NOT NULL DEFAULT '';
+ALTER TABLE doctors ADD COLUMN search_bio TEXT NOT NULL DEFAULT '';
+ALTER TABLE services ADD COLUMN search_name TEXT NOT NULL DEFAULT '';
+
+UPDATE users SET search_name = search_words(first_name || ' ' || last_name);
+UPDATE doctors SET search_specialty = search_words(specialty), search_bio = search_words(COALESCE(bio, ''));
+UPDATE services SET search_name = search_words(name);
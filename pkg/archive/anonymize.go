@@ -0,0 +1,75 @@
+package archive
+
+import (
+	"fmt"
+	"time"
+
+	"version-1-0/internal/domain"
+)
+
+// anonymizedPasswordHash replaces password hashes in anonymized archives
+// It is not a valid bcrypt hash, so no password matches it and the imported
+// accounts can't log in
+const anonymizedPasswordHash = "!anonymized"
+
+// placeholder is what the API itself stores for patient data nobody filled in yet
+const placeholder = "Por definir"
+
+// roleNames are the first names given to anonymized users
+var roleNames = map[domain.UserRole]string{
+	domain.RoleAdmin:        "Admin",
+	domain.RoleDoctor:       "Doctor",
+	domain.RoleNurse:        "Enfermera",
+	domain.RoleReceptionist: "Recepcionista",
+	domain.RolePatient:      "Paciente",
+}
+
+// Anonymize scrubs personal data in place so an archive can be loaded into a
+// staging environment
+// IDs, roles, dates, prices and statuses are kept, so the data still behaves
+// like the original; names, contact details, documents, free text and
+// clinical details are replaced with numbered placeholders
+func Anonymize(data *Data) {
+	phones := make(map[string]string, len(data.Users))
+
+	for i, user := range data.Users {
+		n := i + 1
+		name, ok := roleNames[user.Role]
+		if !ok {
+			name = "Usuario"
+		}
+
+		user.Email = fmt.Sprintf("user%d@example.invalid", n)
+		user.FirstName = name
+		user.LastName = fmt.Sprintf("%d", n)
+		user.Phone = fmt.Sprintf("9%08d", n)
+		user.PasswordHash = anonymizedPasswordHash
+		phones[user.ID] = user.Phone
+	}
+
+	for i, doctor := range data.Doctors {
+		doctor.LicenseNumber = fmt.Sprintf("CMP-%06d", i+1)
+		doctor.Education = ""
+		doctor.Bio = ""
+	}
+
+	for i, patient := range data.Patients {
+		// Keep the year so age based reports still make sense
+		patient.Birthdate = time.Date(patient.Birthdate.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		patient.DocumentNumber = fmt.Sprintf("%08d", i+1)
+		patient.Address = placeholder
+		patient.EmergencyContactName = placeholder
+		patient.EmergencyContactPhone = phones[patient.UserID]
+		if patient.EmergencyContactPhone == "" {
+			patient.EmergencyContactPhone = placeholder
+		}
+		patient.BloodType = ""
+		patient.Allergies = []string{}
+	}
+
+	for _, appointment := range data.Appointments {
+		appointment.Reason = "Consulta"
+		appointment.Notes = ""
+		appointment.CancellationReason = ""
+	}
+}
@@ -0,0 +1,107 @@
+package archive
+
+import (
+	"encoding/json"
+	"time"
+
+	"version-1-0/internal/domain"
+)
+
+// Format identifies a clinic archive; FormatVersion changes whenever the
+// records change in a way older importers can't read
+// Version 2 added clinics, locations, doctor locations and service prices;
+// version 1 archives are read as belonging to the default clinic and location
+// Version 3 added email verification; users of older archives are read as
+// verified, as migration 0013 did with existing accounts
+const (
+	Format        = "clinica-archive"
+	FormatVersion = 3
+)
+
+// Record types, in the order they are written and imported
+// Every record only references records of an earlier type
+const (
+	TypeHeader         = "header"
+	TypeClinic         = "clinic"
+	TypeLocation       = "location"
+	TypeUser           = "user"
+	TypeService        = "service"
+	TypeDoctor         = "doctor"
+	TypePatient        = "patient"
+	TypeDoctorService  = "doctor_service"
+	TypeDoctorLocation = "doctor_location"
+	TypeServicePrice   = "service_price"
+	TypeSchedule       = "schedule"
+	TypeAppointment    = "appointment"
+)
+
+// recordTypes lists the data record types in dependency order
+var recordTypes = []string{
+	TypeClinic,
+	TypeLocation,
+	TypeUser,
+	TypeService,
+	TypeDoctor,
+	TypePatient,
+	TypeDoctorService,
+	TypeDoctorLocation,
+	TypeServicePrice,
+	TypeSchedule,
+	TypeAppointment,
+}
+
+// Header is the first line of an archive
+// Counts lets the importer notice a truncated or spliced file
+type Header struct {
+	Format     string         `json:"format"`
+	Version    int            `json:"version"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Anonymized bool           `json:"anonymized"`
+	Counts     map[string]int `json:"counts"`
+}
+
+// line is one line of the NDJSON archive: a record type and its data
+type line struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// userRecord is a user as stored in an archive
+// Unlike the API, the archive carries the password hash so accounts keep
+// working after a migration
+type userRecord struct {
+	*domain.User
+	PasswordHash string `json:"password_hash"`
+}
+
+// Data holds every record of an archive
+type Data struct {
+	Clinics         []*domain.Clinic
+	Locations       []*domain.Location
+	Users           []*domain.User
+	Services        []*domain.Service
+	Doctors         []*domain.Doctor
+	Patients        []*domain.Patient
+	DoctorServices  []*domain.DoctorService
+	DoctorLocations []*domain.DoctorLocation
+	ServicePrices   []*domain.ServicePrice
+	Schedules       []*domain.Schedule
+	Appointments    []*domain.Appointment
+}
+
+// Counts returns the number of records of each type
+func (d *Data) Counts() map[string]int {
+	return map[string]int{
+		TypeClinic:         len(d.Clinics),
+		TypeLocation:       len(d.Locations),
+		TypeUser:           len(d.Users),
+		TypeService:        len(d.Services),
+		TypeDoctor:         len(d.Doctors),
+		TypePatient:        len(d.Patients),
+		TypeDoctorService:  len(d.DoctorServices),
+		TypeDoctorLocation: len(d.DoctorLocations),
+		TypeServicePrice:   len(d.ServicePrices),
+		TypeSchedule:       len(d.Schedules),
+		TypeAppointment:    len(d.Appointments),
+	}
+}
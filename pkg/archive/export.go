@@ -0,0 +1,210 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// allRows is the page size that makes the offset based List methods return a whole table
+const allRows = math.MaxInt32
+
+// Exporter reads every record of a clinic through the repositories
+type Exporter struct {
+	userRepo          repository.UserRepository
+	doctorRepo        repository.DoctorRepository
+	patientRepo       repository.PatientRepository
+	serviceRepo       repository.ServiceRepository
+	doctorServiceRepo repository.DoctorServiceRepository
+	scheduleRepo      repository.ScheduleRepository
+	appointmentRepo   repository.AppointmentRepository
+	clinicRepo        repository.ClinicRepository
+}
+
+// NewExporter creates a new instance of Exporter
+func NewExporter(
+	userRepo repository.UserRepository,
+	doctorRepo repository.DoctorRepository,
+	patientRepo repository.PatientRepository,
+	serviceRepo repository.ServiceRepository,
+	doctorServiceRepo repository.DoctorServiceRepository,
+	scheduleRepo repository.ScheduleRepository,
+	appointmentRepo repository.AppointmentRepository,
+	clinicRepo repository.ClinicRepository,
+) *Exporter {
+	return &Exporter{
+		userRepo:          userRepo,
+		doctorRepo:        doctorRepo,
+		patientRepo:       patientRepo,
+		serviceRepo:       serviceRepo,
+		doctorServiceRepo: doctorServiceRepo,
+		scheduleRepo:      scheduleRepo,
+		appointmentRepo:   appointmentRepo,
+		clinicRepo:        clinicRepo,
+	}
+}
+
+// Read loads every record, soft deleted ones included, so an archive
+// restores the clinic exactly as it was
+func (e *Exporter) Read(ctx context.Context) (*Data, error) {
+	var data Data
+	var err error
+
+	if data.Clinics, err = e.clinicRepo.ListClinics(ctx); err != nil {
+		return nil, fmt.Errorf("reading clinics: %w", err)
+	}
+
+	if data.Locations, err = e.clinicRepo.ListLocations(ctx, false); err != nil {
+		return nil, fmt.Errorf("reading locations: %w", err)
+	}
+
+	if data.Users, err = e.userRepo.List(ctx, repository.PageRequest{Sort: repository.SortCreatedAt}); err != nil {
+		return nil, fmt.Errorf("reading users: %w", err)
+	}
+	deletedUsers, err := e.userRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading deleted users: %w", err)
+	}
+	data.Users = append(data.Users, deletedUsers...)
+
+	if data.Services, err = e.serviceRepo.ListAll(ctx); err != nil {
+		return nil, fmt.Errorf("reading services: %w", err)
+	}
+	deletedServices, err := e.serviceRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading deleted services: %w", err)
+	}
+	data.Services = append(data.Services, deletedServices...)
+
+	if data.Doctors, err = e.doctorRepo.List(ctx, allRows, 0); err != nil {
+		return nil, fmt.Errorf("reading doctors: %w", err)
+	}
+	deletedDoctors, err := e.doctorRepo.ListDeleted(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading deleted doctors: %w", err)
+	}
+	data.Doctors = append(data.Doctors, deletedDoctors...)
+
+	if data.Patients, err = e.patientRepo.List(ctx, allRows, 0); err != nil {
+		return nil, fmt.Errorf("reading patients: %w", err)
+	}
+
+	if data.DoctorServices, err = e.doctorServiceRepo.ListAll(ctx); err != nil {
+		return nil, fmt.Errorf("reading doctor services: %w", err)
+	}
+
+	if data.DoctorLocations, err = e.clinicRepo.ListDoctorLocations(ctx); err != nil {
+		return nil, fmt.Errorf("reading doctor locations: %w", err)
+	}
+
+	if data.ServicePrices, err = e.clinicRepo.ListServicePrices(ctx); err != nil {
+		return nil, fmt.Errorf("reading service prices: %w", err)
+	}
+
+	if data.Schedules, err = e.scheduleRepo.ListAll(ctx); err != nil {
+		return nil, fmt.Errorf("reading schedules: %w", err)
+	}
+
+	page := repository.PageRequest{Sort: repository.SortCreatedAt}
+	if data.Appointments, err = e.appointmentRepo.FindAllWithFilters(ctx, repository.AppointmentFilters{}, page); err != nil {
+		return nil, fmt.Errorf("reading appointments: %w", err)
+	}
+	for _, appointment := range data.Appointments {
+		// Names are joined in by the query, they are not part of the appointment
+		appointment.PatientName = ""
+		appointment.DoctorName = ""
+		appointment.ServiceName = ""
+	}
+
+	return &data, nil
+}
+
+// Write serializes data as an NDJSON archive: a header line followed by one
+// line per record, each type after the ones it references
+func Write(w io.Writer, data *Data, anonymized bool) error {
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+
+	writeLine := func(recordType string, value interface{}) error {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		return encoder.Encode(line{Type: recordType, Data: raw})
+	}
+
+	header := Header{
+		Format:     Format,
+		Version:    FormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Anonymized: anonymized,
+		Counts:     data.Counts(),
+	}
+	if err := writeLine(TypeHeader, header); err != nil {
+		return err
+	}
+
+	for _, clinic := range data.Clinics {
+		if err := writeLine(TypeClinic, clinic); err != nil {
+			return err
+		}
+	}
+	for _, location := range data.Locations {
+		if err := writeLine(TypeLocation, location); err != nil {
+			return err
+		}
+	}
+	for _, user := range data.Users {
+		if err := writeLine(TypeUser, userRecord{User: user, PasswordHash: user.PasswordHash}); err != nil {
+			return err
+		}
+	}
+	for _, service := range data.Services {
+		if err := writeLine(TypeService, service); err != nil {
+			return err
+		}
+	}
+	for _, doctor := range data.Doctors {
+		if err := writeLine(TypeDoctor, doctor); err != nil {
+			return err
+		}
+	}
+	for _, patient := range data.Patients {
+		if err := writeLine(TypePatient, patient); err != nil {
+			return err
+		}
+	}
+	for _, doctorService := range data.DoctorServices {
+		if err := writeLine(TypeDoctorService, doctorService); err != nil {
+			return err
+		}
+	}
+	for _, doctorLocation := range data.DoctorLocations {
+		if err := writeLine(TypeDoctorLocation, doctorLocation); err != nil {
+			return err
+		}
+	}
+	for _, price := range data.ServicePrices {
+		if err := writeLine(TypeServicePrice, price); err != nil {
+			return err
+		}
+	}
+	for _, schedule := range data.Schedules {
+		if err := writeLine(TypeSchedule, schedule); err != nil {
+			return err
+		}
+	}
+	for _, appointment := range data.Appointments {
+		if err := writeLine(TypeAppointment, appointment); err != nil {
+			return err
+		}
+	}
+
+	return out.Flush()
+}
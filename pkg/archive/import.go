@@ -0,0 +1,562 @@
+package archive
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"version-1-0/internal/domain"
+	"version-1-0/internal/repository"
+)
+
+// maxLineSize bounds a single archive line; records are far smaller
+const maxLineSize = 16 * 1024 * 1024
+
+// IntegrityError lists everything wrong with an archive
+// Nothing is imported when an archive has integrity problems
+type IntegrityError struct {
+	Problems []string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("archive failed %d integrity check(s): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Read parses an NDJSON archive written by Write
+// It checks the header and that the file holds as many records of each type
+// as the header announces, but not the references between records; see Check
+func Read(r io.Reader) (*Header, *Data, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var header *Header
+	var data Data
+	number := 0
+
+	for scanner.Scan() {
+		number++
+		raw := scanner.Bytes()
+		if len(strings.TrimSpace(string(raw))) == 0 {
+			continue
+		}
+
+		var l line
+		if err := json.Unmarshal(raw, &l); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", number, err)
+		}
+
+		if header == nil {
+			if l.Type != TypeHeader {
+				return nil, nil, fmt.Errorf("line %d: archive must start with a header", number)
+			}
+			header = &Header{}
+			if err := json.Unmarshal(l.Data, header); err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", number, err)
+			}
+			if header.Format != Format {
+				return nil, nil, fmt.Errorf("not a clinic archive (format %q)", header.Format)
+			}
+			if header.Version < 1 || header.Version > FormatVersion {
+				return nil, nil, fmt.Errorf("unsupported archive version %d, this build reads up to %d", header.Version, FormatVersion)
+			}
+			continue
+		}
+
+		if err := data.decode(l); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", number, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if header == nil {
+		return nil, nil, fmt.Errorf("archive is empty")
+	}
+
+	counts := data.Counts()
+	for _, recordType := range recordTypes {
+		if counts[recordType] != header.Counts[recordType] {
+			return nil, nil, fmt.Errorf("archive is incomplete: header announces %d %s record(s), found %d",
+				header.Counts[recordType], recordType, counts[recordType])
+		}
+	}
+
+	if header.Version == 1 {
+		data.upgradeFromV1()
+	}
+	if header.Version < 3 {
+		data.upgradeFromV2()
+	}
+
+	return header, &data, nil
+}
+
+// upgradeFromV1 places the records of an archive written before clinics
+// existed in the default clinic and location, where migration 0009 put the
+// same records in the database, and attaches every doctor to that location
+func (d *Data) upgradeFromV1() {
+	for _, user := range d.Users {
+		user.ClinicID = domain.DefaultClinicID
+	}
+	for _, service := range d.Services {
+		service.ClinicID = domain.DefaultClinicID
+	}
+	for _, schedule := range d.Schedules {
+		schedule.LocationID = domain.DefaultLocationID
+	}
+	for _, appointment := range d.Appointments {
+		appointment.LocationID = domain.DefaultLocationID
+	}
+	for _, doctor := range d.Doctors {
+		d.DoctorLocations = append(d.DoctorLocations, &domain.DoctorLocation{
+			DoctorID:   doctor.ID,
+			LocationID: domain.DefaultLocationID,
+			CreatedAt:  doctor.CreatedAt,
+		})
+	}
+}
+
+// upgradeFromV2 marks the users of an archive written before email
+// verification existed as verified, like migration 0013 did in the database
+func (d *Data) upgradeFromV2() {
+	for _, user := range d.Users {
+		user.EmailVerifiedAt = &user.CreatedAt
+	}
+}
+
+// decode appends the record of one line to data
+func (d *Data) decode(l line) error {
+	switch l.Type {
+	case TypeClinic:
+		return decodeInto(l.Data, &d.Clinics)
+	case TypeLocation:
+		return decodeInto(l.Data, &d.Locations)
+	case TypeUser:
+		record := userRecord{User: &domain.User{}}
+		if err := json.Unmarshal(l.Data, &record); err != nil {
+			return err
+		}
+		record.User.PasswordHash = record.PasswordHash
+		d.Users = append(d.Users, record.User)
+	case TypeService:
+		return decodeInto(l.Data, &d.Services)
+	case TypeDoctor:
+		return decodeInto(l.Data, &d.Doctors)
+	case TypePatient:
+		return decodeInto(l.Data, &d.Patients)
+	case TypeDoctorService:
+		return decodeInto(l.Data, &d.DoctorServices)
+	case TypeDoctorLocation:
+		return decodeInto(l.Data, &d.DoctorLocations)
+	case TypeServicePrice:
+		return decodeInto(l.Data, &d.ServicePrices)
+	case TypeSchedule:
+		return decodeInto(l.Data, &d.Schedules)
+	case TypeAppointment:
+		return decodeInto(l.Data, &d.Appointments)
+	case TypeHeader:
+		return fmt.Errorf("unexpected second header")
+	default:
+		return fmt.Errorf("unknown record type %q", l.Type)
+	}
+	return nil
+}
+
+// decodeInto unmarshals one record and appends it to records
+func decodeInto[T any](raw json.RawMessage, records *[]*T) error {
+	record := new(T)
+	if err := json.Unmarshal(raw, record); err != nil {
+		return err
+	}
+	*records = append(*records, record)
+	return nil
+}
+
+// Check verifies the records of an archive and the references between them:
+// every record must be valid and unique, and every ID it points to must be in
+// the archive as a record of the right kind
+// The default clinic and location exist in every database, so records may
+// point to them even when the archive does not carry them
+func Check(data *Data) error {
+	var problems []string
+	report := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	clinics := map[string]bool{domain.DefaultClinicID: true}
+	seenClinics := make(map[string]bool, len(data.Clinics))
+	for _, clinic := range data.Clinics {
+		if err := clinic.Validate(); err != nil {
+			report("clinic %s: %v", clinic.ID, err)
+		}
+		if seenClinics[clinic.ID] {
+			report("clinic %s: duplicate id", clinic.ID)
+		}
+		clinics[clinic.ID] = true
+		seenClinics[clinic.ID] = true
+	}
+
+	// locationClinics maps each location to the clinic it belongs to
+	locationClinics := map[string]string{domain.DefaultLocationID: domain.DefaultClinicID}
+	seenLocations := make(map[string]bool, len(data.Locations))
+	for _, location := range data.Locations {
+		if err := location.Validate(); err != nil {
+			report("location %s: %v", location.ID, err)
+		}
+		if seenLocations[location.ID] {
+			report("location %s: duplicate id", location.ID)
+		}
+		if !clinics[location.ClinicID] {
+			report("location %s: clinic %s is not in the archive", location.ID, location.ClinicID)
+		}
+		locationClinics[location.ID] = location.ClinicID
+		seenLocations[location.ID] = true
+	}
+
+	users := make(map[string]*domain.User, len(data.Users))
+	emails := make(map[string]bool, len(data.Users))
+	for _, user := range data.Users {
+		if err := user.Validate(); err != nil {
+			report("user %s: %v", user.ID, err)
+		}
+		if users[user.ID] != nil {
+			report("user %s: duplicate id", user.ID)
+		}
+		if emails[user.Email] {
+			report("user %s: duplicate email %s", user.ID, user.Email)
+		}
+		if !clinics[user.ClinicID] {
+			report("user %s: clinic %s is not in the archive", user.ID, user.ClinicID)
+		}
+		users[user.ID] = user
+		emails[user.Email] = true
+	}
+
+	services := make(map[string]bool, len(data.Services))
+	for _, service := range data.Services {
+		if err := service.Validate(); err != nil {
+			report("service %s: %v", service.ID, err)
+		}
+		if services[service.ID] {
+			report("service %s: duplicate id", service.ID)
+		}
+		if !clinics[service.ClinicID] {
+			report("service %s: clinic %s is not in the archive", service.ID, service.ClinicID)
+		}
+		services[service.ID] = true
+	}
+
+	// profiles tracks which users already have a doctor or patient profile
+	profiles := make(map[string]bool, len(data.Doctors)+len(data.Patients))
+
+	doctors := make(map[string]bool, len(data.Doctors))
+	for _, doctor := range data.Doctors {
+		if err := doctor.Validate(); err != nil {
+			report("doctor %s: %v", doctor.ID, err)
+		}
+		if doctors[doctor.ID] {
+			report("doctor %s: duplicate id", doctor.ID)
+		}
+		if user := users[doctor.UserID]; user == nil {
+			report("doctor %s: user %s is not in the archive", doctor.ID, doctor.UserID)
+		} else if user.Role != domain.RoleDoctor {
+			report("doctor %s: user %s has role %s", doctor.ID, doctor.UserID, user.Role)
+		}
+		if profiles[doctor.UserID] {
+			report("doctor %s: user %s already has a profile", doctor.ID, doctor.UserID)
+		}
+		doctors[doctor.ID] = true
+		profiles[doctor.UserID] = true
+	}
+
+	patients := make(map[string]bool, len(data.Patients))
+	for _, patient := range data.Patients {
+		if err := patient.Validate(); err != nil {
+			report("patient %s: %v", patient.ID, err)
+		}
+		if patients[patient.ID] {
+			report("patient %s: duplicate id", patient.ID)
+		}
+		if user := users[patient.UserID]; user == nil {
+			report("patient %s: user %s is not in the archive", patient.ID, patient.UserID)
+		} else if user.Role != domain.RolePatient {
+			report("patient %s: user %s has role %s", patient.ID, patient.UserID, user.Role)
+		}
+		if profiles[patient.UserID] {
+			report("patient %s: user %s already has a profile", patient.ID, patient.UserID)
+		}
+		patients[patient.ID] = true
+		profiles[patient.UserID] = true
+	}
+
+	doctorServices := make(map[string]bool, len(data.DoctorServices))
+	pairs := make(map[string]bool, len(data.DoctorServices))
+	for _, ds := range data.DoctorServices {
+		if err := ds.Validate(); err != nil {
+			report("doctor_service %s: %v", ds.ID, err)
+		}
+		if doctorServices[ds.ID] {
+			report("doctor_service %s: duplicate id", ds.ID)
+		}
+		if !doctors[ds.DoctorID] {
+			report("doctor_service %s: doctor %s is not in the archive", ds.ID, ds.DoctorID)
+		}
+		if !services[ds.ServiceID] {
+			report("doctor_service %s: service %s is not in the archive", ds.ID, ds.ServiceID)
+		}
+		pair := ds.DoctorID + "/" + ds.ServiceID
+		if pairs[pair] {
+			report("doctor_service %s: service %s is assigned to doctor %s twice", ds.ID, ds.ServiceID, ds.DoctorID)
+		}
+		doctorServices[ds.ID] = true
+		pairs[pair] = true
+	}
+
+	doctorLocations := make(map[string]bool, len(data.DoctorLocations))
+	for _, dl := range data.DoctorLocations {
+		if !doctors[dl.DoctorID] {
+			report("doctor_location %s/%s: doctor %s is not in the archive", dl.DoctorID, dl.LocationID, dl.DoctorID)
+		}
+		if _, ok := locationClinics[dl.LocationID]; !ok {
+			report("doctor_location %s/%s: location %s is not in the archive", dl.DoctorID, dl.LocationID, dl.LocationID)
+		}
+		pair := dl.DoctorID + "/" + dl.LocationID
+		if doctorLocations[pair] {
+			report("doctor_location %s: duplicate assignment", pair)
+		}
+		doctorLocations[pair] = true
+	}
+
+	prices := make(map[string]bool, len(data.ServicePrices))
+	for _, price := range data.ServicePrices {
+		pair := price.ServiceID + "/" + price.LocationID
+		if err := price.Validate(); err != nil {
+			report("service_price %s: %v", pair, err)
+		}
+		if !services[price.ServiceID] {
+			report("service_price %s: service %s is not in the archive", pair, price.ServiceID)
+		}
+		if _, ok := locationClinics[price.LocationID]; !ok {
+			report("service_price %s: location %s is not in the archive", pair, price.LocationID)
+		}
+		if prices[pair] {
+			report("service_price %s: duplicate price", pair)
+		}
+		prices[pair] = true
+	}
+
+	schedules := make(map[string]bool, len(data.Schedules))
+	for _, schedule := range data.Schedules {
+		if strings.TrimSpace(schedule.ID) == "" {
+			report("schedule without id for doctor %s", schedule.DoctorID)
+		}
+		if err := schedule.Validate(); err != nil {
+			report("schedule %s: %v", schedule.ID, err)
+		}
+		if schedules[schedule.ID] {
+			report("schedule %s: duplicate id", schedule.ID)
+		}
+		if !doctors[schedule.DoctorID] {
+			report("schedule %s: doctor %s is not in the archive", schedule.ID, schedule.DoctorID)
+		}
+		if _, ok := locationClinics[schedule.LocationID]; !ok {
+			report("schedule %s: location %s is not in the archive", schedule.ID, schedule.LocationID)
+		}
+		schedules[schedule.ID] = true
+	}
+
+	// Appointment.Validate rejects past dates, which an archive is full of,
+	// so only the fields the database needs are checked here
+	appointments := make(map[string]bool, len(data.Appointments))
+	for _, appointment := range data.Appointments {
+		if strings.TrimSpace(appointment.ID) == "" {
+			report("appointment without id for patient %s", appointment.PatientID)
+		}
+		if appointments[appointment.ID] {
+			report("appointment %s: duplicate id", appointment.ID)
+		}
+		if !patients[appointment.PatientID] {
+			report("appointment %s: patient %s is not in the archive", appointment.ID, appointment.PatientID)
+		}
+		if !doctors[appointment.DoctorID] {
+			report("appointment %s: doctor %s is not in the archive", appointment.ID, appointment.DoctorID)
+		}
+		if appointment.ServiceID != "" && !services[appointment.ServiceID] {
+			report("appointment %s: service %s is not in the archive", appointment.ID, appointment.ServiceID)
+		}
+		if _, ok := locationClinics[appointment.LocationID]; !ok {
+			report("appointment %s: location %s is not in the archive", appointment.ID, appointment.LocationID)
+		}
+		if appointment.ScheduledAt.IsZero() || appointment.Duration <= 0 {
+			report("appointment %s: scheduled time and duration are required", appointment.ID)
+		}
+		switch appointment.Status {
+		case domain.StatusPending, domain.StatusConfirmed, domain.StatusCancelled, domain.StatusCompleted:
+		default:
+			report("appointment %s: invalid status %q", appointment.ID, appointment.Status)
+		}
+		appointments[appointment.ID] = true
+	}
+
+	if len(problems) > 0 {
+		return &IntegrityError{Problems: problems}
+	}
+	return nil
+}
+
+// Importer writes the records of an archive through the repositories
+type Importer struct {
+	userRepo          repository.UserRepository
+	doctorRepo        repository.DoctorRepository
+	patientRepo       repository.PatientRepository
+	serviceRepo       repository.ServiceRepository
+	doctorServiceRepo repository.DoctorServiceRepository
+	scheduleRepo      repository.ScheduleRepository
+	appointmentRepo   repository.AppointmentRepository
+	clinicRepo        repository.ClinicRepository
+	txManager         repository.TxManager
+}
+
+// NewImporter creates a new instance of Importer
+func NewImporter(
+	userRepo repository.UserRepository,
+	doctorRepo repository.DoctorRepository,
+	patientRepo repository.PatientRepository,
+	serviceRepo repository.ServiceRepository,
+	doctorServiceRepo repository.DoctorServiceRepository,
+	scheduleRepo repository.ScheduleRepository,
+	appointmentRepo repository.AppointmentRepository,
+	clinicRepo repository.ClinicRepository,
+	txManager repository.TxManager,
+) *Importer {
+	return &Importer{
+		userRepo:          userRepo,
+		doctorRepo:        doctorRepo,
+		patientRepo:       patientRepo,
+		serviceRepo:       serviceRepo,
+		doctorServiceRepo: doctorServiceRepo,
+		scheduleRepo:      scheduleRepo,
+		appointmentRepo:   appointmentRepo,
+		clinicRepo:        clinicRepo,
+		txManager:         txManager,
+	}
+}
+
+// Import checks the archive and writes all of it in a single transaction
+// Any failure, such as an ID or email that already exists in the target
+// database, rolls the whole import back
+// Soft deleted records are created and then deleted again, so their deletion
+// date becomes the time of the import
+// Clinics and locations the target already has, such as the default ones
+// every database starts with, are kept as they are
+func (i *Importer) Import(ctx context.Context, data *Data) error {
+	if err := Check(data); err != nil {
+		return err
+	}
+
+	return i.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for _, clinic := range data.Clinics {
+			existing, err := i.clinicRepo.FindClinicByID(ctx, clinic.ID)
+			if err != nil {
+				return fmt.Errorf("importing clinic %s: %w", clinic.ID, err)
+			}
+			if existing != nil {
+				continue
+			}
+			if err := i.clinicRepo.CreateClinic(ctx, clinic); err != nil {
+				return fmt.Errorf("importing clinic %s: %w", clinic.ID, err)
+			}
+		}
+		for _, location := range data.Locations {
+			existing, err := i.clinicRepo.FindLocationByID(ctx, location.ID)
+			if err != nil {
+				return fmt.Errorf("importing location %s: %w", location.ID, err)
+			}
+			if existing != nil {
+				continue
+			}
+			if err := i.clinicRepo.CreateLocation(ctx, location); err != nil {
+				return fmt.Errorf("importing location %s: %w", location.ID, err)
+			}
+		}
+		for _, record := range data.Users {
+			user := *record
+			user.DeletedAt = nil
+			if err := i.userRepo.Create(ctx, &user); err != nil {
+				return fmt.Errorf("importing user %s: %w", user.ID, err)
+			}
+		}
+		for _, record := range data.Services {
+			service := *record
+			service.DeletedAt = nil
+			if err := i.serviceRepo.Create(ctx, &service); err != nil {
+				return fmt.Errorf("importing service %s: %w", service.ID, err)
+			}
+		}
+		for _, record := range data.Doctors {
+			doctor := *record
+			doctor.DeletedAt = nil
+			if err := i.doctorRepo.Create(ctx, &doctor); err != nil {
+				return fmt.Errorf("importing doctor %s: %w", doctor.ID, err)
+			}
+		}
+		for _, patient := range data.Patients {
+			if err := i.patientRepo.Create(ctx, patient); err != nil {
+				return fmt.Errorf("importing patient %s: %w", patient.ID, err)
+			}
+		}
+		for _, doctorService := range data.DoctorServices {
+			if err := i.doctorServiceRepo.Assign(ctx, doctorService); err != nil {
+				return fmt.Errorf("importing doctor_service %s: %w", doctorService.ID, err)
+			}
+		}
+		for _, doctorLocation := range data.DoctorLocations {
+			if err := i.clinicRepo.AssignDoctor(ctx, doctorLocation); err != nil {
+				return fmt.Errorf("importing doctor_location %s/%s: %w", doctorLocation.DoctorID, doctorLocation.LocationID, err)
+			}
+		}
+		for _, price := range data.ServicePrices {
+			if err := i.clinicRepo.SetServicePrice(ctx, price); err != nil {
+				return fmt.Errorf("importing service_price %s/%s: %w", price.ServiceID, price.LocationID, err)
+			}
+		}
+		for _, schedule := range data.Schedules {
+			if err := i.scheduleRepo.Create(ctx, schedule); err != nil {
+				return fmt.Errorf("importing schedule %s: %w", schedule.ID, err)
+			}
+		}
+		for _, appointment := range data.Appointments {
+			if err := i.appointmentRepo.Create(ctx, appointment); err != nil {
+				return fmt.Errorf("importing appointment %s: %w", appointment.ID, err)
+			}
+		}
+
+		// Restore the soft deletions once nothing else needs the records
+		for _, doctor := range data.Doctors {
+			if doctor.DeletedAt != nil {
+				if err := i.doctorRepo.Delete(ctx, doctor.ID); err != nil {
+					return fmt.Errorf("deleting doctor %s: %w", doctor.ID, err)
+				}
+			}
+		}
+		for _, user := range data.Users {
+			if user.DeletedAt != nil {
+				if err := i.userRepo.Delete(ctx, user.ID); err != nil {
+					return fmt.Errorf("deleting user %s: %w", user.ID, err)
+				}
+			}
+		}
+		for _, service := range data.Services {
+			if service.DeletedAt != nil {
+				if err := i.serviceRepo.Delete(ctx, service.ID); err != nil {
+					return fmt.Errorf("deleting service %s: %w", service.ID, err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
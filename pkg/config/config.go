@@ -1,38 +1,98 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"net/netip"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
+
+	"version-1-0/internal/domain"
+	"version-1-0/pkg/fieldcrypt"
+	"version-1-0/pkg/jwtkeys"
+	"version-1-0/pkg/oidc"
+)
+
+// Supported database drivers
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
 )
 
 // Config holds all application configuration settings
 type Config struct {
-	ServerPort        string
-	DatabaseURL       string // Changed from DatabasePath
-	JWTSecret         string
-	JWTExpirationHrs  int
-	SendGridAPIKey    string
-	SendGridFromEmail string
-	SendGridFromName  string
-	AllowedOrigins    string
+	ServerPort         string
+	DatabaseURL        string // Changed from DatabasePath
+	DatabaseDriver     string // "sqlite" or "postgres", detected from DatabaseURL
+	DatabaseDSN        string // Driver-specific connection string
+	AutoMigrate        bool   // Apply pending migrations at API boot instead of requiring cmd/migrate
+	EncryptionKeys     string // Keyring for the encrypted columns, "id:base64" entries with the primary first
+	BlindIndexKey      string // Base64 key of the blind indexes used to look up encrypted values
+	JWTSecret          string
+	AccessTokenMinutes int // Lifetime of access tokens; clients renew them with their refresh token
+	RefreshTokenDays   int // Lifetime of each refresh token, renewed on every refresh
+	ResetTokenMinutes  int // Lifetime of the emailed password reset links
+	VerifyLinkHours    int // Lifetime of the emailed verification links
+	InvitationDays     int // Lifetime of the staff invitations sent by admins
+	LoginMaxAttempts   int // Failed logins of an account before it is locked
+	LoginIPMaxAttempts int // Failed logins from an IP address before it is locked
+	LoginLockMinutes   int // How long a lockout lasts, and how long failures are remembered
+	SendGridAPIKey     string
+	SendGridFromEmail  string
+	SendGridFromName   string
+	AllowedOrigins     string
+	FrontendURL        string // Base URL of the web app, used to build the links sent by email
+	PurgeRetentionDays int    // Days a soft deleted record is kept before being purged, 0 disables purging
+	DefaultClinicID    string // Clinic of requests that send no X-Clinic-ID header
+
+	TrustedProxies []netip.Prefix // Reverse proxies whose X-Forwarded-For header is believed
+
+	TwoFactorRoles []domain.UserRole // Staff roles that must log in with a TOTP second factor
+
+	JWTKeysDir      string // Directory of the <kid>.pem keys that sign access tokens; empty signs them with JWTSecret
+	JWTSigningKeyID string // Key of JWTKeysDir that signs new access tokens
+
+	OIDCIssuer        string                     // Issuer URL of the single sign-on provider of staff; empty disables it
+	OIDCClientID      string                     // Client ID of the API at the provider
+	OIDCClientSecret  string                     // Empty for a public client, which relies on PKCE alone
+	OIDCRedirectURL   string                     // Page of the web app the provider sends staff back to
+	OIDCScopes        []string                   // Scopes asked for at login; openid is always one of them
+	OIDCRoleClaim     string                     // Claim of the ID token with the groups or roles of the person
+	OIDCRoleMapping   map[string]domain.UserRole // Value of OIDCRoleClaim and the staff role it grants
+	OIDCRequiredRoles []domain.UserRole          // Staff roles that cannot log in with a password
 }
 
 // LoadConfig loads configuration from environment variables and .env file
 // Returns a Config struct with all necessary application settings
 func LoadConfig() *Config {
-	// Try to load .env file (optional)
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
-	}
+	cfg := LoadDatabaseConfig()
 
 	// Read environment variables with default values
 	serverPort := getEnv("SERVER_PORT", "8080")
-	databaseURL := getEnv("DATABASE_URL", "./clinica.db")
 	jwtSecret := getEnv("JWT_SECRET", "")
-	jwtExpHours := getEnvAsInt("JWT_EXPIRATION_HOURS", 24)
+	jwtKeysDir := getEnv("JWT_KEYS_DIR", "")
+	jwtSigningKeyID := getEnv("JWT_SIGNING_KEY_ID", "")
+	accessTokenMinutes := getEnvAsInt("ACCESS_TOKEN_MINUTES", 15)
+	refreshTokenDays := getEnvAsInt("REFRESH_TOKEN_DAYS", 30)
+	resetTokenMinutes := getEnvAsInt("PASSWORD_RESET_MINUTES", 60)
+	verifyLinkHours := getEnvAsInt("EMAIL_VERIFICATION_HOURS", 48)
+	invitationDays := getEnvAsInt("INVITATION_DAYS", 7)
+
+	// Brute-force protection on login
+	loginMaxAttempts := getEnvAsInt("LOGIN_MAX_ATTEMPTS", 5)
+	loginIPMaxAttempts := getEnvAsInt("LOGIN_IP_MAX_ATTEMPTS", 20)
+	loginLockMinutes := getEnvAsInt("LOGIN_LOCKOUT_MINUTES", 15)
+
+	// Two-factor authentication policy; staff of other roles may still opt in
+	twoFactorRoles, err := parseStaffRoles("TWO_FACTOR_REQUIRED_ROLES", getEnv("TWO_FACTOR_REQUIRED_ROLES", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// SendGrid configuration (optional)
 	sendGridAPIKey := getEnv("SENDGRID_API_KEY", "")
@@ -42,21 +102,272 @@ func LoadConfig() *Config {
 	// CORS configuration
 	allowedOrigins := getEnv("ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:8080,http://localhost:8081")
 
+	// Client IP addresses, used by login lockouts and the audit log, come from
+	// X-Forwarded-For only when the request arrives through one of these proxies
+	trustedProxies, err := parseTrustedProxies(getEnv("TRUSTED_PROXIES", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Links in emails point to the web app
+	frontendURL := LoadFrontendURL()
+
+	// Single sign-on of staff through an OpenID Connect provider (optional)
+	oidcIssuer := getEnv("OIDC_ISSUER", "")
+	oidcClientID := getEnv("OIDC_CLIENT_ID", "")
+	oidcClientSecret := getEnv("OIDC_CLIENT_SECRET", "")
+	oidcRedirectURL := getEnv("OIDC_REDIRECT_URL", frontendURL+"/auth/sso/callback")
+	oidcScopes := strings.Fields(getEnv("OIDC_SCOPES", "openid email profile"))
+	oidcRoleClaim := getEnv("OIDC_ROLE_CLAIM", "groups")
+	oidcRoleMapping, err := parseRoleMapping(getEnv("OIDC_ROLE_MAPPING", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	oidcRequiredRoles, err := parseStaffRoles("OIDC_REQUIRED_ROLES", getEnv("OIDC_REQUIRED_ROLES", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Soft delete retention
+	purgeRetentionDays := getEnvAsInt("PURGE_RETENTION_DAYS", 90)
+
+	// Tenancy: the clinic served when a request does not pick one
+	defaultClinicID := getEnv("DEFAULT_CLINIC_ID", domain.DefaultClinicID)
+
 	// Validate required configuration
 	if jwtSecret == "" {
 		log.Fatal("JWT_SECRET is required in environment variables")
 	}
+	if jwtKeysDir != "" && jwtSigningKeyID == "" {
+		log.Fatal("JWT_SIGNING_KEY_ID is required when JWT_KEYS_DIR is set")
+	}
+	if accessTokenMinutes <= 0 || refreshTokenDays <= 0 {
+		log.Fatal("ACCESS_TOKEN_MINUTES and REFRESH_TOKEN_DAYS must be positive")
+	}
+	if resetTokenMinutes <= 0 || verifyLinkHours <= 0 || invitationDays <= 0 {
+		log.Fatal("PASSWORD_RESET_MINUTES, EMAIL_VERIFICATION_HOURS and INVITATION_DAYS must be positive")
+	}
+	if loginMaxAttempts <= 0 || loginIPMaxAttempts <= 0 || loginLockMinutes <= 0 {
+		log.Fatal("LOGIN_MAX_ATTEMPTS, LOGIN_IP_MAX_ATTEMPTS and LOGIN_LOCKOUT_MINUTES must be positive")
+	}
+	if oidcIssuer != "" && (oidcClientID == "" || len(oidcRoleMapping) == 0) {
+		log.Fatal("OIDC_CLIENT_ID and OIDC_ROLE_MAPPING are required when OIDC_ISSUER is set")
+	}
+	if oidcIssuer != "" && !slices.Contains(oidcScopes, "openid") {
+		log.Fatal("OIDC_SCOPES must include openid")
+	}
+	if oidcIssuer == "" && len(oidcRequiredRoles) > 0 {
+		log.Fatal("OIDC_REQUIRED_ROLES requires OIDC_ISSUER")
+	}
+	if os.Getenv("JWT_EXPIRATION_HOURS") != "" {
+		log.Println("JWT_EXPIRATION_HOURS is no longer used: access tokens last ACCESS_TOKEN_MINUTES and are renewed with refresh tokens")
+	}
 
 	// Return configuration
+	cfg.ServerPort = serverPort
+	cfg.JWTSecret = jwtSecret
+	cfg.JWTKeysDir = jwtKeysDir
+	cfg.JWTSigningKeyID = jwtSigningKeyID
+	cfg.AccessTokenMinutes = accessTokenMinutes
+	cfg.RefreshTokenDays = refreshTokenDays
+	cfg.ResetTokenMinutes = resetTokenMinutes
+	cfg.VerifyLinkHours = verifyLinkHours
+	cfg.InvitationDays = invitationDays
+	cfg.LoginMaxAttempts = loginMaxAttempts
+	cfg.LoginIPMaxAttempts = loginIPMaxAttempts
+	cfg.LoginLockMinutes = loginLockMinutes
+	cfg.TwoFactorRoles = twoFactorRoles
+	cfg.OIDCIssuer = oidcIssuer
+	cfg.OIDCClientID = oidcClientID
+	cfg.OIDCClientSecret = oidcClientSecret
+	cfg.OIDCRedirectURL = oidcRedirectURL
+	cfg.OIDCScopes = oidcScopes
+	cfg.OIDCRoleClaim = oidcRoleClaim
+	cfg.OIDCRoleMapping = oidcRoleMapping
+	cfg.OIDCRequiredRoles = oidcRequiredRoles
+	cfg.SendGridAPIKey = sendGridAPIKey
+	cfg.SendGridFromEmail = sendGridFromEmail
+	cfg.SendGridFromName = sendGridFromName
+	cfg.AllowedOrigins = allowedOrigins
+	cfg.TrustedProxies = trustedProxies
+	cfg.FrontendURL = frontendURL
+	cfg.PurgeRetentionDays = purgeRetentionDays
+	cfg.DefaultClinicID = defaultClinicID
+	return cfg
+}
+
+// parseStaffRoles reads the comma separated roles of a setting such as
+// TWO_FACTOR_REQUIRED_ROLES; key names the setting in errors
+// Only staff roles can be listed: patients never use a second factor nor single sign-on
+func parseStaffRoles(key, value string) ([]domain.UserRole, error) {
+	var roles []domain.UserRole
+	for _, name := range strings.Split(value, ",") {
+		role := domain.UserRole(strings.TrimSpace(name))
+		if role == "" {
+			continue
+		}
+		if !role.IsStaff() {
+			return nil, fmt.Errorf("%s: %q is not a staff role", key, role)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+// parseRoleMapping reads the comma separated value:role pairs of OIDC_ROLE_MAPPING
+// A value is split from its role at the last colon, so values may contain colons
+func parseRoleMapping(value string) (map[string]domain.UserRole, error) {
+	mapping := make(map[string]domain.UserRole)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		i := strings.LastIndex(pair, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("OIDC_ROLE_MAPPING: %q is not a value:role pair", pair)
+		}
+		claimValue := strings.TrimSpace(pair[:i])
+		role := domain.UserRole(strings.TrimSpace(pair[i+1:]))
+		if !role.IsStaff() {
+			return nil, fmt.Errorf("OIDC_ROLE_MAPPING: %q is not a staff role", role)
+		}
+		if _, ok := mapping[claimValue]; ok {
+			return nil, fmt.Errorf("OIDC_ROLE_MAPPING: %q is mapped twice", claimValue)
+		}
+		mapping[claimValue] = role
+	}
+	return mapping, nil
+}
+
+// parseTrustedProxies reads the comma separated IP addresses and CIDR networks of TRUSTED_PROXIES
+func parseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var proxies []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR network", entry)
+		}
+		proxies = append(proxies, prefix.Masked())
+	}
+	return proxies, nil
+}
+
+// LoadFrontendURL returns the base URL of the web app, without a trailing slash
+// Links sent by email and printed by clinicctl point to its pages
+func LoadFrontendURL() string {
+	return strings.TrimRight(getEnv("FRONTEND_URL", "http://localhost:5173"), "/")
+}
+
+// LoadDatabaseConfig loads only the database settings from environment variables
+// and .env file, for tools such as cmd/migrate that do not need the API secrets
+func LoadDatabaseConfig() *Config {
+	// Try to load .env file (optional)
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	databaseURL := getEnv("DATABASE_URL", "./clinica.db")
+	autoMigrate := getEnvAsBool("AUTO_MIGRATE", false)
+
+	// Field-level encryption of sensitive patient data
+	encryptionKeys := getEnv("ENCRYPTION_KEYS", "")
+	blindIndexKey := getEnv("BLIND_INDEX_KEY", "")
+
+	// Detect database backend from the URL scheme
+	databaseDriver, databaseDSN, err := parseDatabaseURL(databaseURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return &Config{
-		ServerPort:        serverPort,
-		DatabaseURL:       databaseURL,
-		JWTSecret:         jwtSecret,
-		JWTExpirationHrs:  jwtExpHours,
-		SendGridAPIKey:    sendGridAPIKey,
-		SendGridFromEmail: sendGridFromEmail,
-		SendGridFromName:  sendGridFromName,
-		AllowedOrigins:    allowedOrigins,
+		DatabaseURL:    databaseURL,
+		DatabaseDriver: databaseDriver,
+		DatabaseDSN:    databaseDSN,
+		AutoMigrate:    autoMigrate,
+		EncryptionKeys: encryptionKeys,
+		BlindIndexKey:  blindIndexKey,
+	}
+}
+
+// FieldCipher builds the cipher of the encrypted columns from ENCRYPTION_KEYS
+// and BLIND_INDEX_KEY; only the tools that read or write patient data need it
+func (c *Config) FieldCipher() (*fieldcrypt.Cipher, error) {
+	if c.EncryptionKeys == "" || c.BlindIndexKey == "" {
+		return nil, errors.New("ENCRYPTION_KEYS and BLIND_INDEX_KEY are required (generate keys with: go run ./cmd/clinicctl generate-key)")
+	}
+
+	keys, err := fieldcrypt.ParseKeys(c.EncryptionKeys)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEYS: %w", err)
+	}
+	indexKey, err := fieldcrypt.DecodeKey(c.BlindIndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("BLIND_INDEX_KEY: %w", err)
+	}
+
+	cipher, err := fieldcrypt.New(keys, indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEYS: %w", err)
+	}
+	return cipher, nil
+}
+
+// JWTKeys builds the key set that signs and verifies access tokens
+// Without JWT_KEYS_DIR tokens keep being signed with HS256 and JWT_SECRET
+func (c *Config) JWTKeys() (*jwtkeys.KeySet, error) {
+	if c.JWTKeysDir == "" {
+		return jwtkeys.NewHMAC(c.JWTSecret), nil
+	}
+
+	keys, err := jwtkeys.LoadDir(c.JWTKeysDir, c.JWTSigningKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_KEYS_DIR: %w", err)
+	}
+	return keys, nil
+}
+
+// OIDCProvider returns the single sign-on provider of staff, or nil without OIDC_ISSUER
+func (c *Config) OIDCProvider() *oidc.Provider {
+	if c.OIDCIssuer == "" {
+		return nil
+	}
+
+	return oidc.New(oidc.Config{
+		Issuer:       c.OIDCIssuer,
+		ClientID:     c.OIDCClientID,
+		ClientSecret: c.OIDCClientSecret,
+		RedirectURL:  c.OIDCRedirectURL,
+		Scopes:       c.OIDCScopes,
+	})
+}
+
+// parseDatabaseURL detects the database backend from the URL scheme
+// sqlite://path and sqlite://:memory: select the embedded SQLite backend,
+// postgres:// and postgresql:// select PostgreSQL. A bare path without scheme
+// is treated as a SQLite file for backward compatibility
+func parseDatabaseURL(databaseURL string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(databaseURL, "sqlite://"):
+		path := strings.TrimPrefix(databaseURL, "sqlite://")
+		if path == "" {
+			return "", "", errors.New("DATABASE_URL: sqlite:// requires a file path or :memory:")
+		}
+		return DriverSQLite, path, nil
+	case strings.HasPrefix(databaseURL, "postgres://"), strings.HasPrefix(databaseURL, "postgresql://"):
+		return DriverPostgres, databaseURL, nil
+	case strings.Contains(databaseURL, "://"):
+		return "", "", fmt.Errorf("DATABASE_URL: unsupported scheme in %q (use sqlite:// or postgres://)", databaseURL)
+	default:
+		return DriverSQLite, databaseURL, nil
 	}
 }
 
@@ -83,3 +394,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 
 	return value
 }
+
+// getEnvAsBool retrieves an environment variable as boolean or returns a default value
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
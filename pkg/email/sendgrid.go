@@ -2,6 +2,7 @@ package email
 
 import (
 	"fmt"
+	"html"
 	"log"
 
 	"github.com/sendgrid/sendgrid-go"
@@ -24,9 +25,39 @@ func NewEmailService(apiKey, fromEmail, fromName string) *EmailService {
 	}
 }
 
+// Branch tells patients where their appointment takes place
+// Without a clinic name the historical one is used; without a location the line is left out
+type Branch struct {
+	Clinic   string
+	Location string
+	Address  string
+}
+
+// defaultClinicName is used when an email is sent without a clinic name
+const defaultClinicName = "Clinica Internacional"
+
+// name returns the clinic name shown in subjects and sign-offs
+func (b Branch) name() string {
+	if b.Clinic == "" {
+		return defaultClinicName
+	}
+	return b.Clinic
+}
+
+// item renders the location line of the appointment details, if known
+func (b Branch) item() string {
+	if b.Location == "" {
+		return ""
+	}
+	if b.Address == "" {
+		return fmt.Sprintf("<li>Sede: %s</li>", html.EscapeString(b.Location))
+	}
+	return fmt.Sprintf("<li>Sede: %s (%s)</li>", html.EscapeString(b.Location), html.EscapeString(b.Address))
+}
+
 // SendAppointmentCreated sends email when appointment is created
-func (s *EmailService) SendAppointmentCreated(toEmail, patientName, doctorName, date, time string) error {
-	subject := "Cita Médica Creada - Clinica Internacional"
+func (s *EmailService) SendAppointmentCreated(branch Branch, toEmail, patientName, doctorName, date, time string) error {
+	subject := "Cita Médica Creada - " + branch.name()
 
 	htmlContent := fmt.Sprintf(`
 		<h2>Cita Médica Creada</h2>
@@ -35,20 +66,21 @@ func (s *EmailService) SendAppointmentCreated(toEmail, patientName, doctorName,
 		<p><strong>Detalles:</strong></p>
 		<ul>
 			<li>Doctor: %s</li>
+			%s
 			<li>Fecha: %s</li>
 			<li>Hora: %s</li>
 			<li>Estado: Pendiente de confirmación</li>
 		</ul>
 		<p>El doctor confirmará tu cita pronto.</p>
-		<p>Gracias,<br>Clinica Internacional</p>
-	`, patientName, doctorName, date, time)
+		<p>Gracias,<br>%s</p>
+	`, patientName, doctorName, branch.item(), date, time, branch.name())
 
 	return s.sendEmail(toEmail, subject, htmlContent)
 }
 
 // SendAppointmentConfirmed sends email when appointment is confirmed
-func (s *EmailService) SendAppointmentConfirmed(toEmail, patientName, doctorName, date, time string) error {
-	subject := "Cita Médica Confirmada - Clinica Internacional"
+func (s *EmailService) SendAppointmentConfirmed(branch Branch, toEmail, patientName, doctorName, date, time string) error {
+	subject := "Cita Médica Confirmada - " + branch.name()
 
 	htmlContent := fmt.Sprintf(`
 		<h2>Cita Médica Confirmada</h2>
@@ -57,20 +89,21 @@ func (s *EmailService) SendAppointmentConfirmed(toEmail, patientName, doctorName
 		<p><strong>Detalles:</strong></p>
 		<ul>
 			<li>Doctor: %s</li>
+			%s
 			<li>Fecha: %s</li>
 			<li>Hora: %s</li>
 			<li>Estado: Confirmada</li>
 		</ul>
 		<p>Por favor, llega 15 minutos antes de tu cita.</p>
-		<p>Gracias,<br>Clinica Internacional</p>
-	`, patientName, doctorName, date, time)
+		<p>Gracias,<br>%s</p>
+	`, patientName, doctorName, branch.item(), date, time, branch.name())
 
 	return s.sendEmail(toEmail, subject, htmlContent)
 }
 
 // SendAppointmentCancelled sends email when appointment is cancelled
-func (s *EmailService) SendAppointmentCancelled(toEmail, patientName, doctorName, date, time, reason string) error {
-	subject := "Cita Médica Cancelada - Clinica Internacional"
+func (s *EmailService) SendAppointmentCancelled(branch Branch, toEmail, patientName, doctorName, date, time, reason string) error {
+	subject := "Cita Médica Cancelada - " + branch.name()
 
 	htmlContent := fmt.Sprintf(`
 		<h2>Cita Médica Cancelada</h2>
@@ -79,20 +112,21 @@ func (s *EmailService) SendAppointmentCancelled(toEmail, patientName, doctorName
 		<p><strong>Detalles:</strong></p>
 		<ul>
 			<li>Doctor: %s</li>
+			%s
 			<li>Fecha: %s</li>
 			<li>Hora: %s</li>
 			<li>Motivo: %s</li>
 		</ul>
 		<p>Puedes agendar una nueva cita cuando lo desees.</p>
-		<p>Gracias,<br>Clinica Internacional</p>
-	`, patientName, doctorName, date, time, reason)
+		<p>Gracias,<br>%s</p>
+	`, patientName, doctorName, branch.item(), date, time, reason, branch.name())
 
 	return s.sendEmail(toEmail, subject, htmlContent)
 }
 
 // SendAppointmentCompleted sends email when appointment is completed
-func (s *EmailService) SendAppointmentCompleted(toEmail, patientName, doctorName, date string) error {
-	subject := "Consulta Completada - Clinica Internacional"
+func (s *EmailService) SendAppointmentCompleted(branch Branch, toEmail, patientName, doctorName, date string) error {
+	subject := "Consulta Completada - " + branch.name()
 
 	htmlContent := fmt.Sprintf(`
 		<h2>Consulta Completada</h2>
@@ -101,18 +135,19 @@ func (s *EmailService) SendAppointmentCompleted(toEmail, patientName, doctorName
 		<p><strong>Detalles:</strong></p>
 		<ul>
 			<li>Doctor: %s</li>
+			%s
 			<li>Fecha: %s</li>
 		</ul>
 		<p>Puedes ver el historial médico y las notas del doctor en tu perfil.</p>
-		<p>Gracias por confiar en nosotros,<br>Clinica Internacional</p>
-	`, patientName, doctorName, date)
+		<p>Gracias por confiar en nosotros,<br>%s</p>
+	`, patientName, doctorName, branch.item(), date, branch.name())
 
 	return s.sendEmail(toEmail, subject, htmlContent)
 }
 
 // SendAppointmentReminder sends reminder email for upcoming appointment
-func (s *EmailService) SendAppointmentReminder(toEmail, patientName, doctorName, date, time, hoursAhead string) error {
-	subject := "Recordatorio de Cita Médica - Clinica Internacional"
+func (s *EmailService) SendAppointmentReminder(branch Branch, toEmail, patientName, doctorName, date, time, hoursAhead string) error {
+	subject := "Recordatorio de Cita Médica - " + branch.name()
 
 	htmlContent := fmt.Sprintf(`
 		<h2>Recordatorio de Cita Médica</h2>
@@ -121,6 +156,7 @@ func (s *EmailService) SendAppointmentReminder(toEmail, patientName, doctorName,
 		<p><strong>Detalles:</strong></p>
 		<ul>
 			<li>Doctor: %s</li>
+			%s
 			<li>Fecha: %s</li>
 			<li>Hora: %s</li>
 		</ul>
@@ -130,8 +166,81 @@ func (s *EmailService) SendAppointmentReminder(toEmail, patientName, doctorName,
 			<li>Trae tu documento de identidad</li>
 			<li>Si necesitas cancelar, hazlo con anticipación</li>
 		</ul>
-		<p>Te esperamos,<br>Clinica Internacional</p>
-	`, patientName, hoursAhead, doctorName, date, time)
+		<p>Te esperamos,<br>%s</p>
+	`, patientName, hoursAhead, doctorName, branch.item(), date, time, branch.name())
+
+	return s.sendEmail(toEmail, subject, htmlContent)
+}
+
+// SendPasswordReset sends the link to choose a new password
+// Only the clinic name of the branch is used
+func (s *EmailService) SendPasswordReset(branch Branch, toEmail, userName, resetURL, validFor string) error {
+	subject := "Restablecer Contraseña - " + branch.name()
+
+	htmlContent := fmt.Sprintf(`
+		<h2>Restablecer Contraseña</h2>
+		<p>Hola %s,</p>
+		<p>Recibimos una solicitud para restablecer la contraseña de tu cuenta.</p>
+		<p><a href="%s">Elegir una nueva contraseña</a></p>
+		<p>El enlace vence en %s y solo se puede usar una vez. Al cambiar la contraseña se cerrarán todas tus sesiones abiertas.</p>
+		<p>Si no solicitaste este cambio, ignora este correo; tu contraseña seguirá siendo la misma.</p>
+		<p>Gracias,<br>%s</p>
+	`, userName, html.EscapeString(resetURL), validFor, branch.name())
+
+	return s.sendEmail(toEmail, subject, htmlContent)
+}
+
+// SendEmailVerification sends the link that confirms the user owns the address
+// Only the clinic name of the branch is used
+func (s *EmailService) SendEmailVerification(branch Branch, toEmail, userName, verifyURL, validFor string) error {
+	subject := "Confirma tu Email - " + branch.name()
+
+	htmlContent := fmt.Sprintf(`
+		<h2>Confirma tu Email</h2>
+		<p>Hola %s,</p>
+		<p>Gracias por registrarte. Para poder reservar citas, confirma que este es tu email.</p>
+		<p><a href="%s">Confirmar mi email</a></p>
+		<p>El enlace vence en %s. Si vence, puedes pedir uno nuevo desde tu perfil.</p>
+		<p>Si no creaste una cuenta, ignora este correo.</p>
+		<p>Gracias,<br>%s</p>
+	`, userName, html.EscapeString(verifyURL), validFor, branch.name())
+
+	return s.sendEmail(toEmail, subject, htmlContent)
+}
+
+// SendInvitation sends the link a new staff member uses to create their account
+// Only the clinic name of the branch is used
+func (s *EmailService) SendInvitation(branch Branch, toEmail, inviterName, roleName, acceptURL, validFor string) error {
+	subject := "Invitación al Equipo - " + branch.name()
+
+	htmlContent := fmt.Sprintf(`
+		<h2>Invitación al Equipo</h2>
+		<p>Hola,</p>
+		<p>%s te invitó a unirte a %s como %s.</p>
+		<p><a href="%s">Crear mi cuenta</a></p>
+		<p>El enlace vence en %s y solo se puede usar una vez. Al abrirlo podrás elegir tu contraseña.</p>
+		<p>Si no esperabas esta invitación, ignora este correo.</p>
+		<p>Gracias,<br>%s</p>
+	`, html.EscapeString(inviterName), branch.name(), roleName, html.EscapeString(acceptURL), validFor, branch.name())
+
+	return s.sendEmail(toEmail, subject, htmlContent)
+}
+
+// SendAccountUnlock warns that the account was locked after too many failed
+// logins and sends the link that unlocks it before the lockout ends
+// Only the clinic name of the branch is used
+func (s *EmailService) SendAccountUnlock(branch Branch, toEmail, userName, unlockURL, lockedFor string) error {
+	subject := "Cuenta Bloqueada Temporalmente - " + branch.name()
+
+	htmlContent := fmt.Sprintf(`
+		<h2>Cuenta Bloqueada Temporalmente</h2>
+		<p>Hola %s,</p>
+		<p>Bloqueamos el acceso a tu cuenta durante %s porque hubo demasiados intentos de inicio de sesión con una contraseña incorrecta.</p>
+		<p>Si fuiste tú, puedes desbloquearla ahora:</p>
+		<p><a href="%s">Desbloquear mi cuenta</a></p>
+		<p>Si no fuiste tú, alguien podría estar intentando adivinar tu contraseña. Te recomendamos cambiarla desde la opción "Olvidé mi contraseña".</p>
+		<p>Gracias,<br>%s</p>
+	`, userName, lockedFor, html.EscapeString(unlockURL), branch.name())
 
 	return s.sendEmail(toEmail, subject, htmlContent)
 }
@@ -0,0 +1,307 @@
+// Package fieldcrypt encrypts individual database columns with envelope encryption
+//
+// Every value gets its own random data key. The value is sealed with that data
+// key and the data key is sealed with a master key from the keyring, so rotating
+// the master key only means re-sealing the small data keys. An encrypted value
+// is stored as text:
+//
+//	enc1:<key id>:<sealed data key>:<sealed value>
+//
+// Blind indexes are keyed hashes of a value, stored next to its ciphertext so
+// exact-match lookups work without decrypting every row
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// prefix marks a value written by this package; values without it are
+// plaintext stored before encryption was enabled
+const prefix = "enc1:"
+
+// KeySize is the length in bytes of master, data and blind index keys (AES-256)
+const KeySize = 32
+
+// keyIDPattern keeps key IDs free of the separators used in the stored format
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// encoding is used for the sealed parts of a value; it never produces ':'
+var encoding = base64.RawStdEncoding
+
+// Key is a master key of the keyring
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Cipher encrypts and decrypts column values and computes blind indexes
+// New values are sealed with the primary key; the other keys are only used to
+// open values written before the last rotation
+type Cipher struct {
+	primary  string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// New creates a Cipher from a keyring whose first key is the primary one
+// indexKey keys the blind indexes and is independent from the master keys, so
+// rotating them does not change the indexes
+func New(keys []Key, indexKey []byte) (*Cipher, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+	if len(indexKey) != KeySize {
+		return nil, fmt.Errorf("blind index key must be %d bytes", KeySize)
+	}
+
+	c := &Cipher{
+		primary:  keys[0].ID,
+		keys:     make(map[string]cipher.AEAD, len(keys)),
+		indexKey: indexKey,
+	}
+	for _, key := range keys {
+		if !keyIDPattern.MatchString(key.ID) {
+			return nil, fmt.Errorf("invalid key id %q (use letters, digits, '.', '_' or '-')", key.ID)
+		}
+		if _, ok := c.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		if len(key.Secret) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes", key.ID, KeySize)
+		}
+		aead, err := newAEAD(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+		c.keys[key.ID] = aead
+	}
+
+	return c, nil
+}
+
+// ParseKeys reads a keyring written as "id:base64,id:base64", primary key first
+func ParseKeys(spec string) ([]Key, error) {
+	var keys []Key
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("key %q must be written as id:base64", entry)
+		}
+		decoded, err := DecodeKey(secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys = append(keys, Key{ID: id, Secret: decoded})
+	}
+
+	return keys, nil
+}
+
+// DecodeKey decodes a base64 key and checks its length
+func DecodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("key is not valid base64")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// GenerateKey returns a new random key encoded in base64
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// PrimaryKeyID returns the ID of the key new values are sealed with
+func (c *Cipher) PrimaryKeyID() string {
+	return c.primary
+}
+
+// Encrypt seals a value under a new data key wrapped with the primary key
+// Empty values stay empty so optional columns keep telling "not set" apart
+func (c *Cipher) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	sealed, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return c.wrap(dataKey, sealed)
+}
+
+// Decrypt opens a value written by Encrypt
+// Values without the encryption prefix are returned as they are, so rows
+// written before encryption was enabled stay readable until they are rotated
+func (c *Cipher) Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+
+	dataKey, sealed, err := c.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, sealed)
+	if err != nil {
+		return "", errors.New("encrypted value is corrupt")
+	}
+
+	return string(plaintext), nil
+}
+
+// Rewrap returns value sealed under the primary key
+// The data key is re-wrapped and the sealed value itself is kept; plaintext
+// values are encrypted. Values already under the primary key are returned as is
+func (c *Cipher) Rewrap(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return c.Encrypt(value)
+	}
+	if keyID(value) == c.primary {
+		return value, nil
+	}
+
+	dataKey, sealed, err := c.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+
+	return c.wrap(dataKey, sealed)
+}
+
+// BlindIndex returns a keyed hash of value for exact-match lookups
+// Callers normalize the value first; empty values have no index
+func (c *Cipher) BlindIndex(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, c.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// wrap seals the data key with the primary key and formats the stored value
+func (c *Cipher) wrap(dataKey, sealed []byte) (string, error) {
+	wrapped, err := sealWith(c.keys[c.primary], dataKey, []byte(c.primary))
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + c.primary + ":" + encoding.EncodeToString(wrapped) + ":" + encoding.EncodeToString(sealed), nil
+}
+
+// unwrap parses a stored value and opens its data key
+func (c *Cipher) unwrap(value string) ([]byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("encrypted value is malformed")
+	}
+
+	aead, ok := c.keys[parts[0]]
+	if !ok {
+		return nil, nil, fmt.Errorf("encryption key %q is not in the keyring", parts[0])
+	}
+
+	wrapped, err := encoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, nil, errors.New("encrypted value is malformed")
+	}
+	sealed, err := encoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, errors.New("encrypted value is malformed")
+	}
+
+	dataKey, err := openWith(aead, wrapped, []byte(parts[0]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("data key does not open with key %q", parts[0])
+	}
+
+	return dataKey, sealed, nil
+}
+
+// keyID returns the master key ID of a stored value
+func keyID(value string) string {
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	return id
+}
+
+// seal encrypts plaintext with a data key
+func seal(key, plaintext []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return sealWith(aead, plaintext, nil)
+}
+
+// open decrypts a value sealed with a data key
+func open(key, sealed []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return openWith(aead, sealed, nil)
+}
+
+// sealWith encrypts with AES-GCM and prepends the random nonce
+func sealWith(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// openWith splits the nonce from sealed and decrypts the rest
+func openWith(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
+
+// newAEAD creates an AES-GCM instance for a 32-byte key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
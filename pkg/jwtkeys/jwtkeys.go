@@ -0,0 +1,301 @@
+// Package jwtkeys signs and verifies the access tokens of the API
+//
+// Tokens are signed with a private key, RS256 or EdDSA (Ed25519), and carry its
+// ID in the kid header. Every key of the set verifies tokens and is published as
+// a JSON Web Key Set, so other services can check tokens without being able to
+// mint them. Keys are PEM files named <kid>.pem in one directory: a PRIVATE KEY
+// (PKCS#8) can sign and verify, a PUBLIC KEY only verifies
+//
+// Without a keys directory tokens are signed with HS256 and the shared secret,
+// as before keys existed; nothing is published then
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms, named as in the alg header
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing or verifying
+const minRSABits = 2048
+
+// generatedRSABits is the size of the RSA keys made by GenerateKey
+const generatedRSABits = 3072
+
+// fileSuffix ends the name of every key file; the rest of the name is its kid
+const fileSuffix = ".pem"
+
+// keyIDPattern keeps key IDs usable as file names and in headers
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Key is one key of the set
+// Keys loaded from a public key file have no private part and only verify
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	private   crypto.PrivateKey
+}
+
+// CanSign reports whether the key has its private part
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// KeySet signs tokens with its signing key and verifies them with any of its keys
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	secret  []byte // HS256 key of sets created by NewHMAC
+}
+
+// NewHMAC creates a set that signs and verifies with HS256 and a shared secret
+func NewHMAC(secret string) *KeySet {
+	return &KeySet{secret: []byte(secret)}
+}
+
+// New creates a set from its keys; signingKeyID names the one that signs
+func New(keys []*Key, signingKeyID string) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+
+	s := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, key := range keys {
+		if _, ok := s.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		s.keys[key.ID] = key
+	}
+
+	signing, ok := s.keys[signingKeyID]
+	if !ok {
+		return nil, fmt.Errorf("signing key %q not found", signingKeyID)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("signing key %q is a public key and cannot sign", signingKeyID)
+	}
+	s.signing = signing
+
+	return s, nil
+}
+
+// LoadDir reads every <kid>.pem file of a directory and signs with signingKeyID
+func LoadDir(dir, signingKeyID string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []*Key
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseKey(strings.TrimSuffix(entry.Name(), fileSuffix), data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no %s files in %s", fileSuffix, dir)
+	}
+
+	return New(keys, signingKeyID)
+}
+
+// ParseKey reads a PEM encoded PKCS#8 private key or PKIX public key
+// The algorithm follows from the key type: RSA keys sign RS256, Ed25519 keys EdDSA
+func ParseKey(id string, data []byte) (*Key, error) {
+	if !keyIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid key id %q (use letters, digits, '.', '_' or '-')", id)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key := &Key{ID: id}
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("unsupported private key")
+		}
+		key.private = private
+		key.Public = signer.Public()
+	case "PUBLIC KEY":
+		public, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Public = public
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q (use PRIVATE KEY or PUBLIC KEY)", block.Type)
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		key.Algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		key.Algorithm = AlgorithmEdDSA
+	default:
+		return nil, errors.New("unsupported key type (use RSA or Ed25519)")
+	}
+
+	return key, nil
+}
+
+// GenerateKey returns a new private key in the PEM form LoadDir reads
+func GenerateKey(algorithm string) ([]byte, error) {
+	var private crypto.PrivateKey
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, generatedRSABits)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q (use %s or %s)", algorithm, AlgorithmEdDSA, AlgorithmRS256)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// PublicPEM returns the public part of a key in PEM form, for services that
+// prefer a key file to fetching the key set
+func (k *Key) PublicPEM() ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(k.Public)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// Sign returns the signed form of a token with the given claims
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if s.signing == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(s.signing.Algorithm), claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+// Parse verifies a signed token and returns it with its claims as jwt.MapClaims
+// Tokens must name a key of the set in their kid header and be signed with
+// the algorithm of that key
+func (s *KeySet) Parse(tokenString string) (*jwt.Token, error) {
+	if s.signing == nil {
+		return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			return s.secret, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	}
+
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		if token.Method.Alg() != key.Algorithm {
+			return nil, fmt.Errorf("key %q does not sign %s", kid, token.Method.Alg())
+		}
+		return key.Public, nil
+	}, jwt.WithValidMethods([]string{AlgorithmRS256, AlgorithmEdDSA}))
+}
+
+// JWK is the public part of a key as a JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`   // RSA modulus
+	E   string `json:"e,omitempty"`   // RSA exponent
+	Crv string `json:"crv,omitempty"` // Ed25519 curve
+	X   string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public part of every key, ordered by kid
+// Sets created by NewHMAC have nothing to publish and return no keys
+func (s *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
+
+// SigningKeyID returns the kid of the signing key, or "" for HS256 sets
+func (s *KeySet) SigningKeyID() string {
+	if s.signing == nil {
+		return ""
+	}
+	return s.signing.ID
+}
@@ -0,0 +1,136 @@
+package purge
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"version-1-0/internal/repository"
+)
+
+// PurgeService permanently removes soft deleted records once their retention
+// period is over, refresh and password reset tokens once they expire, sessions
+// once their last refresh token is gone, and failed login counters once they
+// are forgotten
+type PurgeService struct {
+	userRepo         repository.UserRepository
+	serviceRepo      repository.ServiceRepository
+	refreshTokenRepo repository.RefreshTokenRepository
+	sessionRepo      repository.SessionRepository
+	resetTokenRepo   repository.PasswordResetTokenRepository
+	usedTokenRepo    repository.UsedTokenRepository
+	lockoutRepo      repository.LoginLockoutRepository
+	retention        time.Duration
+	lockDuration     time.Duration
+}
+
+// NewPurgeService creates a new purge service
+// Records deleted more than retentionDays ago are removed on each run;
+// lockMinutes is how long failed logins are remembered
+func NewPurgeService(
+	userRepo repository.UserRepository,
+	serviceRepo repository.ServiceRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	sessionRepo repository.SessionRepository,
+	resetTokenRepo repository.PasswordResetTokenRepository,
+	usedTokenRepo repository.UsedTokenRepository,
+	lockoutRepo repository.LoginLockoutRepository,
+	retentionDays int,
+	lockMinutes int,
+) *PurgeService {
+	return &PurgeService{
+		userRepo:         userRepo,
+		serviceRepo:      serviceRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		resetTokenRepo:   resetTokenRepo,
+		usedTokenRepo:    usedTokenRepo,
+		lockoutRepo:      lockoutRepo,
+		retention:        time.Duration(retentionDays) * 24 * time.Hour,
+		lockDuration:     time.Duration(lockMinutes) * time.Minute,
+	}
+}
+
+// Start begins the purge scheduler
+// Runs once at startup and then every 24 hours
+func (s *PurgeService) Start() {
+	log.Printf("Purge service started - removing records deleted more than %d days ago", int(s.retention.Hours()/24))
+
+	// Run immediately on start
+	s.purge()
+
+	// Then run once a day
+	ticker := time.NewTicker(24 * time.Hour)
+
+	go func() {
+		for range ticker.C {
+			s.purge()
+		}
+	}()
+}
+
+// purge removes users and services whose retention period has expired
+// Doctor profiles go away together with their user account
+func (s *PurgeService) purge() {
+	ctx := context.Background()
+	before := time.Now().Add(-s.retention)
+
+	users, err := s.userRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		log.Printf("Error purging deleted users: %v", err)
+	}
+
+	services, err := s.serviceRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		log.Printf("Error purging deleted services: %v", err)
+	}
+
+	if users > 0 || services > 0 {
+		log.Printf("Purged %d deleted users and %d deleted services", users, services)
+	}
+
+	// Expired refresh tokens can no longer be used nor reused, so they need no retention
+	tokens, err := s.refreshTokenRepo.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error purging expired refresh tokens: %v", err)
+	}
+	if tokens > 0 {
+		log.Printf("Purged %d expired refresh tokens", tokens)
+	}
+
+	// A session without refresh tokens can no longer be refreshed; the hour
+	// spares sessions whose first token is being issued right now
+	sessions, err := s.sessionRepo.PurgeEnded(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		log.Printf("Error purging ended sessions: %v", err)
+	}
+	if sessions > 0 {
+		log.Printf("Purged %d ended sessions", sessions)
+	}
+
+	resetTokens, err := s.resetTokenRepo.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error purging expired password reset tokens: %v", err)
+	}
+	if resetTokens > 0 {
+		log.Printf("Purged %d expired password reset tokens", resetTokens)
+	}
+
+	// A used token that expired is refused for its expiration alone
+	usedTokens, err := s.usedTokenRepo.PurgeExpired(ctx, time.Now())
+	if err != nil {
+		log.Printf("Error purging expired used tokens: %v", err)
+	}
+	if usedTokens > 0 {
+		log.Printf("Purged %d expired used tokens", usedTokens)
+	}
+
+	// Counters start over once their last failure is older than a lockout
+	lockouts, err := s.lockoutRepo.PurgeStale(ctx, time.Now().Add(-s.lockDuration))
+	if err != nil {
+		log.Printf("Error purging stale failed login counters: %v", err)
+	}
+	if lockouts > 0 {
+		log.Printf("Purged %d stale failed login counters", lockouts)
+	}
+}
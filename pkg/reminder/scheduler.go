@@ -14,6 +14,7 @@ import (
 type ReminderService struct {
 	appointmentRepo repository.AppointmentRepository
 	userRepo        repository.UserRepository
+	clinicRepo      repository.ClinicRepository
 	emailService    *email.EmailService
 }
 
@@ -21,11 +22,13 @@ type ReminderService struct {
 func NewReminderService(
 	appointmentRepo repository.AppointmentRepository,
 	userRepo repository.UserRepository,
+	clinicRepo repository.ClinicRepository,
 	emailService *email.EmailService,
 ) *ReminderService {
 	return &ReminderService{
 		appointmentRepo: appointmentRepo,
 		userRepo:        userRepo,
+		clinicRepo:      clinicRepo,
 		emailService:    emailService,
 	}
 }
@@ -92,7 +95,7 @@ func (s *ReminderService) send24HourReminders(ctx context.Context, now time.Time
 		}
 
 		// Send reminder email
-		err := s.send24HourReminderEmail(patient, doctor, apt)
+		err := s.send24HourReminderEmail(ctx, patient, doctor, apt)
 		if err != nil {
 			log.Printf("Error sending 24h reminder for appointment %s: %v", apt.ID, err)
 			continue
@@ -109,7 +112,7 @@ func (s *ReminderService) send24HourReminders(ctx context.Context, now time.Time
 }
 
 // send24HourReminderEmail sends the 24-hour reminder email
-func (s *ReminderService) send24HourReminderEmail(patient, doctor *domain.User, apt *domain.Appointment) error {
+func (s *ReminderService) send24HourReminderEmail(ctx context.Context, patient, doctor *domain.User, apt *domain.Appointment) error {
 	if s.emailService == nil {
 		return nil
 	}
@@ -120,6 +123,7 @@ func (s *ReminderService) send24HourReminderEmail(patient, doctor *domain.User,
 	timeStr := apt.ScheduledAt.Format("15:04")
 
 	return s.emailService.SendAppointmentReminder(
+		s.branch(ctx, apt.LocationID),
 		patient.Email,
 		patientName,
 		doctorName,
@@ -129,6 +133,24 @@ func (s *ReminderService) send24HourReminderEmail(patient, doctor *domain.User,
 	)
 }
 
+// branch describes the location of an appointment for the reminder email
+func (s *ReminderService) branch(ctx context.Context, locationID string) email.Branch {
+	var branch email.Branch
+
+	location, _ := s.clinicRepo.FindLocationByID(ctx, locationID)
+	if location == nil {
+		return branch
+	}
+	branch.Location = location.Name
+	branch.Address = location.Address
+
+	if clinic, _ := s.clinicRepo.FindClinicByID(ctx, location.ClinicID); clinic != nil {
+		branch.Clinic = clinic.Name
+	}
+
+	return branch
+}
+
 // findAppointmentsInWindow finds appointments in a time window
 func (s *ReminderService) findAppointmentsInWindow(ctx context.Context, start, end time.Time, status string) ([]*domain.Appointment, error) {
 	return s.appointmentRepo.FindByScheduledAtRange(ctx, start, end, status)
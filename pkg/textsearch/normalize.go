@@ -0,0 +1,81 @@
+package textsearch
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold lowercases text and strips accents so "Cardiología" and "CARDIOLOGIA"
+// compare equal
+// The text is decomposed to Unicode NFD and the combining marks are dropped,
+// which is what PostgreSQL's unaccent does for Spanish
+func Fold(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+// Words folds text and splits it into words
+// Anything that is not a letter or a digit separates words
+func Words(text string) []string {
+	return strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Field is a piece of searchable text with the weight of a match in it
+type Field struct {
+	Text   string
+	Weight int
+}
+
+// Score ranks fields against the words of a query
+// Every query word must start a word of some field, otherwise the score is 0;
+// each query word counts the heaviest field it matches, double when it
+// matches the whole word rather than a prefix
+func Score(query []string, fields []Field) int {
+	folded := make([][]string, len(fields))
+	for i, field := range fields {
+		folded[i] = Words(field.Text)
+	}
+
+	total := 0
+	for _, term := range query {
+		best := 0
+		for i, field := range fields {
+			for _, word := range folded[i] {
+				points := 0
+				if word == term {
+					points = 2 * field.Weight
+				} else if strings.HasPrefix(word, term) {
+					points = field.Weight
+				}
+				if points > best {
+					best = points
+				}
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+
+	return total
+}
+
+// MatchesAll reports whether every query word starts a word of text
+// An empty query matches nothing
+func MatchesAll(query []string, text string) bool {
+	return Score(query, []Field{{Text: text, Weight: 1}}) > 0
+}